@@ -40,6 +40,7 @@ var (
 			subcmdRegenerate,
 			subcmdAuth,
 			subcmdSendMail,
+			subcmdRepoShards,
 		},
 	}
 
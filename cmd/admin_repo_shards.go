@@ -0,0 +1,170 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/urfave/cli"
+)
+
+var (
+	subcmdRepoShards = cli.Command{
+		Name:  "repo-shards",
+		Usage: "Manage the repository shards",
+		Subcommands: []cli.Command{
+			microcmdRepoShardsList,
+			microcmdRepoShardsMove,
+			microcmdRepoShardsRebalance,
+		},
+	}
+
+	microcmdRepoShardsList = cli.Command{
+		Name:   "list",
+		Usage:  "List the repository shards and their usage",
+		Action: runRepoShardsList,
+	}
+
+	microcmdRepoShardsMove = cli.Command{
+		Name:   "move",
+		Usage:  "Move all repositories of an owner to another shard. The repositories must not be used while they are moved.",
+		Action: runRepoShardsMove,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "owner,o",
+				Usage: "Name of the user or organization",
+			},
+			cli.StringFlag{
+				Name:  "shard,s",
+				Usage: "Name of the target shard",
+			},
+		},
+	}
+
+	microcmdRepoShardsRebalance = cli.Command{
+		Name:   "rebalance",
+		Usage:  "Move owners between shards to even out their number of repositories. The moved repositories must not be used while they are moved.",
+		Action: runRepoShardsRebalance,
+		Flags: []cli.Flag{
+			cli.StringSliceFlag{
+				Name:  "shard,s",
+				Usage: "Shards to rebalance between, all shards when not given",
+			},
+			cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Only print the moves to be made",
+			},
+		},
+	}
+)
+
+func runRepoShardsList(_ *cli.Context) error {
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+
+	owners, err := models.GetRepoShardOwners()
+	if err != nil {
+		return err
+	}
+	numOwners := make(map[string]int)
+	numRepos := make(map[string]int)
+	for _, owner := range owners {
+		numOwners[owner.Shard]++
+		numRepos[owner.Shard] += owner.NumRepos
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 5, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "Shard\tRoot\tOwners\tRepositories\n")
+	for _, shard := range models.RepoShardNames() {
+		root, _ := models.RepoShardRootPath(shard)
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\n", shard, root, numOwners[shard], numRepos[shard])
+		delete(numOwners, shard)
+	}
+	w.Flush()
+
+	for shard, count := range numOwners {
+		log.Warn("%d owners are in the unknown repository shard %s", count, shard)
+	}
+	return nil
+}
+
+func runRepoShardsMove(c *cli.Context) error {
+	if !c.IsSet("owner") || !c.IsSet("shard") {
+		return fmt.Errorf("You must provide the owner and the shard to move to")
+	}
+
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+
+	owner, err := models.GetUserByName(c.String("owner"))
+	if err != nil {
+		return err
+	}
+	if err := models.MoveOwnerRepoShard(owner.Name, c.String("shard")); err != nil {
+		return err
+	}
+	fmt.Printf("Moved the repositories of %s to shard %s\n", owner.Name, strings.ToLower(c.String("shard")))
+	return nil
+}
+
+func runRepoShardsRebalance(c *cli.Context) error {
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+
+	shards := models.RepoShardNames()
+	if c.IsSet("shard") {
+		shards = make([]string, 0, len(c.StringSlice("shard")))
+		for _, shard := range c.StringSlice("shard") {
+			shard = strings.ToLower(shard)
+			if _, err := models.RepoShardRootPath(shard); err != nil {
+				return err
+			}
+			shards = append(shards, shard)
+		}
+	}
+	if len(shards) < 2 {
+		return fmt.Errorf("At least two repository shards are needed to rebalance")
+	}
+
+	owners, err := models.GetRepoShardOwners()
+	if err != nil {
+		return err
+	}
+	moves := models.PlanRepoShardRebalance(owners, shards)
+	if len(moves) == 0 {
+		fmt.Println("The repository shards are balanced")
+		return nil
+	}
+
+	for _, move := range moves {
+		if c.Bool("dry-run") {
+			fmt.Printf("Would move %s (%d repositories) from %s to %s\n", move.Owner, move.NumRepos, move.From, move.To)
+			continue
+		}
+		if err := models.MoveOwnerRepoShard(move.Owner, move.To); err != nil {
+			return fmt.Errorf("move %s to %s: %v", move.Owner, move.To, err)
+		}
+		fmt.Printf("Moved %s (%d repositories) from %s to %s\n", move.Owner, move.NumRepos, move.From, move.To)
+	}
+	return nil
+}
@@ -223,6 +223,12 @@ func runDump(ctx *cli.Context) error {
 		if err := addRecursiveExclude(w, "repos", setting.RepoRootPath, []string{absFileName}, verbose); err != nil {
 			fatal("Failed to include repositories: %v", err)
 		}
+		for name, root := range setting.RepoShards {
+			log.Info("Dumping repositories of shard %s... %s", name, root)
+			if err := addRecursiveExclude(w, path.Join("repo-shards", name), root, []string{absFileName}, verbose); err != nil {
+				fatal("Failed to include repositories of shard %s: %v", name, err)
+			}
+		}
 
 		if ctx.IsSet("skip-lfs-data") && ctx.Bool("skip-lfs-data") {
 			log.Info("Skip dumping LFS data")
@@ -315,6 +321,9 @@ func runDump(ctx *cli.Context) error {
 		}
 
 		excludes = append(excludes, setting.RepoRootPath)
+		for _, root := range setting.RepoShards {
+			excludes = append(excludes, root)
+		}
 		excludes = append(excludes, setting.LFS.Path)
 		excludes = append(excludes, setting.Attachment.Path)
 		excludes = append(excludes, setting.LogRootPath)
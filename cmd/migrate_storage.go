@@ -34,12 +34,12 @@ var CmdMigrateStorage = cli.Command{
 		cli.StringFlag{
 			Name:  "storage, s",
 			Value: "",
-			Usage: "New storage type: local (default) or minio",
+			Usage: "New storage type: local (default), local-cas, minio or azureblob",
 		},
 		cli.StringFlag{
 			Name:  "path, p",
 			Value: "",
-			Usage: "New storage placement if store is local (leave blank for default)",
+			Usage: "New storage placement if store is local or local-cas (leave blank for default)",
 		},
 		cli.StringFlag{
 			Name:  "minio-endpoint",
@@ -75,34 +75,96 @@ var CmdMigrateStorage = cli.Command{
 			Name:  "minio-use-ssl",
 			Usage: "Enable SSL for minio",
 		},
+		cli.StringFlag{
+			Name:  "azure-blob-endpoint",
+			Value: "",
+			Usage: "Azure Blob storage endpoint (leave blank for https://<account>.blob.core.windows.net)",
+		},
+		cli.StringFlag{
+			Name:  "azure-blob-account-name",
+			Value: "",
+			Usage: "Azure Blob storage account name",
+		},
+		cli.StringFlag{
+			Name:  "azure-blob-account-key",
+			Value: "",
+			Usage: "Azure Blob storage account key",
+		},
+		cli.StringFlag{
+			Name:  "azure-blob-container",
+			Value: "",
+			Usage: "Azure Blob storage container",
+		},
+		cli.StringFlag{
+			Name:  "azure-blob-base-path",
+			Value: "",
+			Usage: "Azure Blob storage basepath in the container",
+		},
 	},
 }
 
-func migrateAttachments(dstStorage storage.ObjectStorage) error {
+// migrateProgress copies objects to the new storage and counts them. Objects which
+// already exist in the new storage with the same size are skipped, so an interrupted
+// migration can be resumed by running it again.
+type migrateProgress struct {
+	name    string
+	src     storage.ObjectStorage
+	dst     storage.ObjectStorage
+	copied  int64
+	skipped int64
+}
+
+func (m *migrateProgress) copy(p string) error {
+	if p == "" {
+		return nil
+	}
+
+	if dstInfo, err := m.dst.Stat(p); err == nil {
+		if srcInfo, err := m.src.Stat(p); err == nil && srcInfo.Size() == dstInfo.Size() {
+			m.skipped++
+			m.report(false)
+			return nil
+		}
+	}
+
+	if _, err := storage.Copy(m.dst, p, m.src, p); err != nil {
+		return fmt.Errorf("copy %s %s: %v", m.name, p, err)
+	}
+	m.copied++
+	m.report(false)
+	return nil
+}
+
+func (m *migrateProgress) report(done bool) {
+	if done || (m.copied+m.skipped)%100 == 0 {
+		log.Info("Migrating %s: %d copied, %d already present", m.name, m.copied, m.skipped)
+	}
+}
+
+func migrateAttachments(m *migrateProgress) error {
 	return models.IterateAttachment(func(attach *models.Attachment) error {
-		_, err := storage.Copy(dstStorage, attach.RelativePath(), storage.Attachments, attach.RelativePath())
-		return err
+		return m.copy(attach.RelativePath())
 	})
 }
 
-func migrateLFS(dstStorage storage.ObjectStorage) error {
+func migrateLFS(m *migrateProgress) error {
 	return models.IterateLFS(func(mo *models.LFSMetaObject) error {
-		_, err := storage.Copy(dstStorage, mo.RelativePath(), storage.LFS, mo.RelativePath())
-		return err
+		return m.copy(mo.RelativePath())
 	})
 }
 
-func migrateAvatars(dstStorage storage.ObjectStorage) error {
+func migrateAvatars(m *migrateProgress) error {
 	return models.IterateUser(func(user *models.User) error {
-		_, err := storage.Copy(dstStorage, user.CustomAvatarRelativePath(), storage.Avatars, user.CustomAvatarRelativePath())
-		return err
+		if !user.UseCustomAvatar {
+			return nil
+		}
+		return m.copy(user.CustomAvatarRelativePath())
 	})
 }
 
-func migrateRepoAvatars(dstStorage storage.ObjectStorage) error {
+func migrateRepoAvatars(m *migrateProgress) error {
 	return models.IterateRepository(func(repo *models.Repository) error {
-		_, err := storage.Copy(dstStorage, repo.CustomAvatarRelativePath(), storage.RepoAvatars, repo.CustomAvatarRelativePath())
-		return err
+		return m.copy(repo.CustomAvatarRelativePath())
 	})
 }
 
@@ -126,68 +188,75 @@ func runMigrateStorage(ctx *cli.Context) error {
 		return err
 	}
 
-	goCtx := context.Background()
-
 	if err := storage.Init(); err != nil {
 		return err
 	}
 
-	var dstStorage storage.ObjectStorage
-	var err error
-	switch strings.ToLower(ctx.String("storage")) {
+	var cfg interface{}
+	typ := storage.Type(strings.ToLower(ctx.String("storage")))
+	switch typ {
 	case "":
+		typ = storage.LocalStorageType
 		fallthrough
-	case string(storage.LocalStorageType):
+	case storage.LocalStorageType, storage.LocalCASStorageType:
 		p := ctx.String("path")
 		if p == "" {
-			log.Fatal("Path must be given when storage is loal")
+			log.Fatal("Path must be given when storage is local")
 			return nil
 		}
-		dstStorage, err = storage.NewLocalStorage(
-			goCtx,
-			storage.LocalStorageConfig{
-				Path: p,
-			})
-	case string(storage.MinioStorageType):
-		dstStorage, err = storage.NewMinioStorage(
-			goCtx,
-			storage.MinioStorageConfig{
-				Endpoint:        ctx.String("minio-endpoint"),
-				AccessKeyID:     ctx.String("minio-access-key-id"),
-				SecretAccessKey: ctx.String("minio-secret-access-key"),
-				Bucket:          ctx.String("minio-bucket"),
-				Location:        ctx.String("minio-location"),
-				BasePath:        ctx.String("minio-base-path"),
-				UseSSL:          ctx.Bool("minio-use-ssl"),
-			})
+		cfg = storage.LocalStorageConfig{
+			Path: p,
+		}
+	case storage.MinioStorageType:
+		cfg = storage.MinioStorageConfig{
+			Endpoint:        ctx.String("minio-endpoint"),
+			AccessKeyID:     ctx.String("minio-access-key-id"),
+			SecretAccessKey: ctx.String("minio-secret-access-key"),
+			Bucket:          ctx.String("minio-bucket"),
+			Location:        ctx.String("minio-location"),
+			BasePath:        ctx.String("minio-base-path"),
+			UseSSL:          ctx.Bool("minio-use-ssl"),
+		}
+	case storage.AzureBlobStorageType:
+		cfg = storage.AzureBlobStorageConfig{
+			Endpoint:    ctx.String("azure-blob-endpoint"),
+			AccountName: ctx.String("azure-blob-account-name"),
+			AccountKey:  ctx.String("azure-blob-account-key"),
+			Container:   ctx.String("azure-blob-container"),
+			BasePath:    ctx.String("azure-blob-base-path"),
+		}
 	default:
 		return fmt.Errorf("Unsupported storage type: %s", ctx.String("storage"))
 	}
+	dstStorage, err := storage.NewStorage(string(typ), cfg)
 	if err != nil {
 		return err
 	}
 
-	tp := strings.ToLower(ctx.String("type"))
-	switch tp {
+	m := &migrateProgress{
+		name: strings.ToLower(ctx.String("type")),
+		dst:  dstStorage,
+	}
+	switch m.name {
 	case "attachments":
-		if err := migrateAttachments(dstStorage); err != nil {
-			return err
-		}
+		m.src = storage.Attachments
+		err = migrateAttachments(m)
 	case "lfs":
-		if err := migrateLFS(dstStorage); err != nil {
-			return err
-		}
+		m.src = storage.LFS
+		err = migrateLFS(m)
 	case "avatars":
-		if err := migrateAvatars(dstStorage); err != nil {
-			return err
-		}
+		m.src = storage.Avatars
+		err = migrateAvatars(m)
 	case "repo-avatars":
-		if err := migrateRepoAvatars(dstStorage); err != nil {
-			return err
-		}
+		m.src = storage.RepoAvatars
+		err = migrateRepoAvatars(m)
 	default:
 		return fmt.Errorf("Unsupported storage: %s", ctx.String("type"))
 	}
+	m.report(true)
+	if err != nil {
+		return err
+	}
 
 	log.Warn("All files have been copied to the new placement but old files are still on the original placement.")
 
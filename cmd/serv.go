@@ -19,6 +19,7 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/lfstransfer"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/pprof"
 	"code.gitea.io/gitea/modules/private"
@@ -32,6 +33,7 @@ import (
 
 const (
 	lfsAuthenticateVerb = "git-lfs-authenticate"
+	lfsTransferVerb     = "git-lfs-transfer"
 )
 
 // CmdServ represents the available serv sub-command.
@@ -69,6 +71,7 @@ var (
 		"git-upload-archive": models.AccessModeRead,
 		"git-receive-pack":   models.AccessModeWrite,
 		lfsAuthenticateVerb:  models.AccessModeNone,
+		lfsTransferVerb:      models.AccessModeNone,
 	}
 	alphaDashDotPattern = regexp.MustCompile(`[^\w-\.]`)
 )
@@ -164,7 +167,7 @@ func runServ(c *cli.Context) error {
 	}
 
 	var lfsVerb string
-	if verb == lfsAuthenticateVerb {
+	if verb == lfsAuthenticateVerb || verb == lfsTransferVerb {
 		if !setting.LFS.StartServer {
 			return fail("Unknown git command", "LFS authentication request over SSH denied, LFS support is disabled")
 		}
@@ -212,7 +215,7 @@ func runServ(c *cli.Context) error {
 		return fail("Unknown git command", "Unknown git command %s", verb)
 	}
 
-	if verb == lfsAuthenticateVerb {
+	if verb == lfsAuthenticateVerb || verb == lfsTransferVerb {
 		if lfsVerb == "upload" {
 			requestedMode = models.AccessModeWrite
 		} else if lfsVerb == "download" {
@@ -245,24 +248,16 @@ func runServ(c *cli.Context) error {
 	os.Setenv(models.EnvKeyID, fmt.Sprintf("%d", results.KeyID))
 	os.Setenv(models.EnvAppURL, setting.AppURL)
 
+	// Only pass a well-formed wire protocol request from the client on to git
+	if protocol := os.Getenv("GIT_PROTOCOL"); protocol != "" && !git.IsSafeProtocol(protocol) {
+		os.Unsetenv("GIT_PROTOCOL")
+	}
+
 	//LFS token authentication
 	if verb == lfsAuthenticateVerb {
 		url := fmt.Sprintf("%s%s/%s.git/info/lfs", setting.AppURL, url.PathEscape(results.OwnerName), url.PathEscape(results.RepoName))
 
-		now := time.Now()
-		claims := lfs.Claims{
-			StandardClaims: jwt.StandardClaims{
-				ExpiresAt: now.Add(setting.LFS.HTTPAuthExpiry).Unix(),
-				NotBefore: now.Unix(),
-			},
-			RepoID: results.RepoID,
-			Op:     lfsVerb,
-			UserID: results.UserID,
-		}
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-		// Sign and get the complete encoded token as a string using the secret
-		tokenString, err := token.SignedString(setting.LFS.JWTSecretBytes)
+		tokenString, err := getLFSAuthToken(results, lfsVerb)
 		if err != nil {
 			return fail("Internal error", "Failed to sign JWT token: %v", err)
 		}
@@ -281,11 +276,31 @@ func runServ(c *cli.Context) error {
 		return nil
 	}
 
+	// LFS transfer over the SSH connection itself
+	if verb == lfsTransferVerb {
+		tokenString, err := getLFSAuthToken(results, lfsVerb)
+		if err != nil {
+			return fail("Internal error", "Failed to sign JWT token: %v", err)
+		}
+
+		backend := lfstransfer.NewHTTPBackend(ctx, results.OwnerName, results.RepoName, lfsVerb, fmt.Sprintf("Bearer %s", tokenString))
+		if err := lfstransfer.NewProcessor(os.Stdin, os.Stdout, backend, lfsVerb).Serve(); err != nil {
+			return fail("Internal error", "Failed to serve LFS transfer: %v", err)
+		}
+		return nil
+	}
+
 	// Special handle for Windows.
 	if setting.IsWindows {
 		verb = strings.Replace(verb, "-", " ", 1)
 	}
 
+	// Older servers do not resolve the repository shard, their repositories are all
+	// relative to the repository root
+	if results.RepoPath != "" {
+		repoPath = results.RepoPath
+	}
+
 	var gitcmd *exec.Cmd
 	verbs := strings.Split(verb, " ")
 	if len(verbs) == 2 {
@@ -311,3 +326,21 @@ func runServ(c *cli.Context) error {
 
 	return nil
 }
+
+// getLFSAuthToken signs a JWT token which grants the LFS operation on the repository to the user
+func getLFSAuthToken(results *private.ServCommandResults, op string) (string, error) {
+	now := time.Now()
+	claims := lfs.Claims{
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: now.Add(setting.LFS.HTTPAuthExpiry).Unix(),
+			NotBefore: now.Unix(),
+		},
+		RepoID: results.RepoID,
+		Op:     op,
+		UserID: results.UserID,
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	// Sign and get the complete encoded token as a string using the secret
+	return token.SignedString(setting.LFS.JWTSecretBytes)
+}
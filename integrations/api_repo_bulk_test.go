@@ -0,0 +1,165 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// waitBulkRepoTask polls a bulk repository task until it is done
+func waitBulkRepoTask(t *testing.T, session *TestSession, link string) *api.BulkRepoTask {
+	var apiTask api.BulkRepoTask
+	for i := 0; i < 50; i++ {
+		resp := session.MakeRequest(t, NewRequest(t, "GET", link), http.StatusOK)
+		DecodeJSON(t, resp, &apiTask)
+		if apiTask.Status == "finished" || apiTask.Status == "failed" {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return &apiTask
+}
+
+func TestAPIAdminBulkRepo(t *testing.T) {
+	onGiteaRun(t, func(*testing.T, *url.URL) {
+		session := loginUser(t, "user1")
+		token := getTokenForLoggedInUser(t, session)
+
+		req := NewRequestWithJSON(t, "POST", "/api/v1/admin/repos/bulk?token="+token, &api.BulkRepoOption{
+			Action: api.BulkRepoVisibility,
+			Repos:  []string{"user2/repo1"},
+		})
+		session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+		req = NewRequestWithJSON(t, "POST", "/api/v1/admin/repos/bulk?token="+token, &api.BulkRepoOption{
+			Action: api.BulkRepoArchive,
+			Repos:  []string{"user2/repo1", "user2/repo1", "user2/doesnotexist", "user3/repo3"},
+		})
+		resp := session.MakeRequest(t, req, http.StatusAccepted)
+		var apiTask api.BulkRepoTask
+		DecodeJSON(t, resp, &apiTask)
+		assert.EqualValues(t, api.BulkRepoArchive, apiTask.Action)
+		assert.EqualValues(t, 3, apiTask.Total)
+
+		link := fmt.Sprintf("/api/v1/admin/repos/bulk/%d?token=%s", apiTask.ID, token)
+		result := waitBulkRepoTask(t, session, link)
+		assert.Equal(t, "finished", result.Status)
+		assert.Equal(t, []*api.BulkRepoResult{
+			{Repo: "user2/repo1", Success: true},
+			{Repo: "user2/doesnotexist", Message: "repository does not exist"},
+			{Repo: "user3/repo3", Success: true},
+		}, result.Results)
+		assert.True(t, db.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository).IsArchived)
+		assert.True(t, db.AssertExistsAndLoadBean(t, &models.Repository{ID: 3}).(*models.Repository).IsArchived)
+
+		// a regular user may not use the admin endpoints
+		session = loginUser(t, "user2")
+		token = getTokenForLoggedInUser(t, session)
+		req = NewRequestWithJSON(t, "POST", "/api/v1/admin/repos/bulk?token="+token, &api.BulkRepoOption{
+			Action: api.BulkRepoUnarchive,
+			Repos:  []string{"user2/repo1"},
+		})
+		session.MakeRequest(t, req, http.StatusForbidden)
+	})
+}
+
+func TestAPIOrgBulkRepo(t *testing.T) {
+	onGiteaRun(t, func(*testing.T, *url.URL) {
+		// user2 owns the organization user3
+		session := loginUser(t, "user2")
+		token := getTokenForLoggedInUser(t, session)
+
+		// repositories of other owners are rejected
+		req := NewRequestWithJSON(t, "POST", "/api/v1/orgs/user3/repos/bulk?token="+token, &api.BulkRepoOption{
+			Action: api.BulkRepoAddTeam,
+			Repos:  []string{"user3/repo3", "user2/repo1"},
+			Team:   "team12Creators",
+		})
+		session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+		req = NewRequestWithJSON(t, "POST", "/api/v1/orgs/user3/repos/bulk?token="+token, &api.BulkRepoOption{
+			Action: api.BulkRepoAddTeam,
+			Repos:  []string{"user3/repo3", "user3/repo5"},
+			Team:   "team12Creators",
+		})
+		resp := session.MakeRequest(t, req, http.StatusAccepted)
+		var apiTask api.BulkRepoTask
+		DecodeJSON(t, resp, &apiTask)
+
+		result := waitBulkRepoTask(t, session, fmt.Sprintf("/api/v1/orgs/user3/repos/bulk/%d?token=%s", apiTask.ID, token))
+		assert.Equal(t, "finished", result.Status)
+		if assert.Len(t, result.Results, 2) {
+			assert.True(t, result.Results[0].Success)
+			assert.True(t, result.Results[1].Success)
+		}
+		db.AssertExistsAndLoadBean(t, &models.TeamRepo{TeamID: 12, RepoID: 3})
+		db.AssertExistsAndLoadBean(t, &models.TeamRepo{TeamID: 12, RepoID: 5})
+
+		req = NewRequest(t, "GET", "/api/v1/orgs/user3/repos/bulk?token="+token)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		var apiTasks []*api.BulkRepoTask
+		DecodeJSON(t, resp, &apiTasks)
+		assert.Len(t, apiTasks, 1)
+
+		// users who are no owners are rejected
+		session = loginUser(t, "user4")
+		req = NewRequest(t, "GET", "/api/v1/orgs/user3/repos/bulk?token="+getTokenForLoggedInUser(t, session))
+		session.MakeRequest(t, req, http.StatusForbidden)
+
+		// the task is not visible through another organization
+		session = loginUser(t, "user1")
+		token = getTokenForLoggedInUser(t, session)
+		req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/orgs/user6/repos/bulk/%d?token=%s", apiTask.ID, token))
+		session.MakeRequest(t, req, http.StatusNotFound)
+		req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/admin/repos/bulk/%d?token=%s", apiTask.ID, token))
+		session.MakeRequest(t, req, http.StatusOK)
+	})
+}
+
+func TestAdminBulkRepo(t *testing.T) {
+	onGiteaRun(t, func(*testing.T, *url.URL) {
+		session := loginUser(t, "user1")
+
+		req := NewRequest(t, "GET", "/admin/repos/bulk")
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		csrf := NewHTMLParser(t, resp.Body).GetCSRF()
+
+		// invalid options render the form again with the error
+		req = NewRequestWithValues(t, "POST", "/admin/repos/bulk", map[string]string{
+			"_csrf":  csrf,
+			"action": "transfer",
+			"repos":  "user2/repo1",
+		})
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		assert.Contains(t, NewHTMLParser(t, resp.Body).Find(".ui.negative.message").Text(), "the new owner does not exist")
+
+		req = NewRequestWithValues(t, "POST", "/admin/repos/bulk", map[string]string{
+			"_csrf":      csrf,
+			"action":     "visibility",
+			"repos":      "user2/repo1\nuser2/repo2",
+			"visibility": "public",
+		})
+		resp = session.MakeRequest(t, req, http.StatusFound)
+		link := resp.Header().Get("Location")
+		assert.Regexp(t, `^/admin/repos/bulk/\d+$`, link)
+
+		waitBulkRepoTask(t, session, "/api/v1"+link+"?token="+getTokenForLoggedInUser(t, session))
+		assert.False(t, db.AssertExistsAndLoadBean(t, &models.Repository{ID: 2}).(*models.Repository).IsPrivate)
+
+		req = NewRequest(t, "GET", link)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		assert.EqualValues(t, 2, NewHTMLParser(t, resp.Body).Find("tbody tr").Length())
+	})
+}
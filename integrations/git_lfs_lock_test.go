@@ -0,0 +1,103 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitLFSLockEnforcement(t *testing.T) {
+	onGiteaRun(t, testGitLFSLockEnforcement)
+}
+
+func testGitLFSLockEnforcement(t *testing.T, u *url.URL) {
+	if !setting.LFS.StartServer {
+		t.Skip()
+		return
+	}
+
+	ownerCtx := NewAPITestContext(t, "user2", "repo-lfs-lock")
+	t.Run("CreateRepo", doAPICreateRepository(ownerCtx, false))
+	t.Run("AddCollaborator", doAPIAddCollaborator(ownerCtx, "user4", models.AccessModeWrite))
+
+	repo := db.AssertExistsAndLoadBean(t, &models.Repository{OwnerName: "user2", Name: "repo-lfs-lock"}).(*models.Repository)
+	owner := db.AssertExistsAndLoadBean(t, &models.User{Name: "user2"}).(*models.User)
+	collaborator := db.AssertExistsAndLoadBean(t, &models.User{Name: "user4"}).(*models.User)
+
+	ownerLock, err := models.CreateLFSLock(&models.LFSLock{Owner: owner, Repo: repo, Path: "README.md"})
+	assert.NoError(t, err)
+	_, err = models.CreateLFSLock(&models.LFSLock{Owner: collaborator, Repo: repo, Path: "assets/model.bin"})
+	assert.NoError(t, err)
+
+	commitFile := func(t *testing.T, dstPath, name, content string) {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dstPath, name)), os.ModePerm))
+		assert.NoError(t, os.WriteFile(filepath.Join(dstPath, name), []byte(content), 0644))
+		assert.NoError(t, git.AddChanges(dstPath, true))
+		signature := git.Signature{
+			Email: "user4@example.com",
+			Name:  "User Four",
+			When:  time.Now(),
+		}
+		assert.NoError(t, git.CommitChanges(dstPath, git.CommitChangesOptions{
+			Committer: &signature,
+			Author:    &signature,
+			Message:   "Change " + name,
+		}))
+	}
+
+	t.Run("Collaborator", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+		dstPath, err := os.MkdirTemp("", "repo-lfs-lock")
+		assert.NoError(t, err)
+		defer util.RemoveAll(dstPath)
+
+		cloneURL, _ := url.Parse(u.String())
+		cloneURL.Path = ownerCtx.GitPath()
+		cloneURL.User = url.UserPassword("user4", userPassword)
+		t.Run("Clone", doGitClone(dstPath, cloneURL))
+
+		// files locked by the pusher and unlocked files can be changed
+		commitFile(t, dstPath, "assets/model.bin", "model")
+		commitFile(t, dstPath, "unlocked.txt", "unlocked")
+		t.Run("PushOwnLock", doGitPushTestRepository(dstPath, "origin", "master"))
+
+		// the lock of another user is enforced on existing and new branches
+		commitFile(t, dstPath, "README.md", "changed")
+		t.Run("PushLockedFile", doGitPushTestRepositoryFail(dstPath, "origin", "master"))
+		t.Run("PushLockedFileNewBranch", doGitPushTestRepositoryFail(dstPath, "origin", "master:locked-branch"))
+
+		_, err = models.DeleteLFSLockByID(ownerLock.ID, owner, false)
+		assert.NoError(t, err)
+		t.Run("PushAfterUnlock", doGitPushTestRepository(dstPath, "origin", "master"))
+	})
+
+	t.Run("RepositoryAdmin", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+		dstPath, err := os.MkdirTemp("", "repo-lfs-lock")
+		assert.NoError(t, err)
+		defer util.RemoveAll(dstPath)
+
+		cloneURL, _ := url.Parse(u.String())
+		cloneURL.Path = ownerCtx.GitPath()
+		cloneURL.User = url.UserPassword("user2", userPassword)
+		t.Run("Clone", doGitClone(dstPath, cloneURL))
+
+		// administrators of the repository may push over the locks of others
+		commitFile(t, dstPath, "assets/model.bin", "changed model")
+		t.Run("PushOverLock", doGitPushTestRepository(dstPath, "origin", "master"))
+	})
+}
@@ -0,0 +1,154 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitProtocol(t *testing.T) {
+	onGiteaRun(t, testGitProtocol)
+}
+
+func testGitProtocol(t *testing.T, u *url.URL) {
+	if git.CheckGitVersionAtLeast("2.22") != nil {
+		t.Skip("git protocol v2 with partial clone requires git >= 2.22")
+		return
+	}
+
+	ctx := NewAPITestContext(t, "user2", "repo1")
+
+	t.Run("HTTP", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+		httpURL := *u
+		httpURL.Path = ctx.GitPath()
+		httpURL.User = url.UserPassword(ctx.Username, userPassword)
+
+		t.Run("AdvertiseV2", doGitProtocolV2Advertise(&httpURL))
+		t.Run("LsRefs", doGitProtocolV2LsRefs(&httpURL))
+		t.Run("ShallowClone", doGitProtocolShallowClone(&httpURL))
+		t.Run("PartialCloneBlobNone", doGitProtocolPartialClone(&httpURL, "blob:none"))
+		t.Run("PartialCloneTreeZero", doGitProtocolPartialClone(&httpURL, "tree:0"))
+	})
+
+	t.Run("SSH", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+		withKeyFile(t, "my-testing-key", func(keyFile string) {
+			t.Run("CreateUserKey", doAPICreateUserKey(ctx, "test-key", keyFile))
+
+			sshURL := createSSHUrl(ctx.GitPath(), u)
+
+			t.Run("ShallowClone", doGitProtocolShallowClone(sshURL))
+			t.Run("PartialCloneBlobNone", doGitProtocolPartialClone(sshURL, "blob:none"))
+			t.Run("PartialCloneTreeZero", doGitProtocolPartialClone(sshURL, "tree:0"))
+		})
+	})
+}
+
+func gitProtocolPacketLine(str string) string {
+	return fmt.Sprintf("%04x%s", len(str)+4, str)
+}
+
+func doGitProtocolV2Advertise(u *url.URL) func(*testing.T) {
+	return func(t *testing.T) {
+		req, err := http.NewRequest("GET", u.String()+"/info/refs?service=git-upload-pack", nil)
+		assert.NoError(t, err)
+		req.Header.Set("Git-Protocol", "version=2")
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.Contains(t, string(body), "version 2")
+		assert.Contains(t, string(body), "ls-refs")
+		assert.Contains(t, string(body), "fetch=")
+		assert.Contains(t, string(body), "filter")
+	}
+}
+
+func doGitProtocolV2LsRefs(u *url.URL) func(*testing.T) {
+	return func(t *testing.T) {
+		var buf bytes.Buffer
+		buf.WriteString(gitProtocolPacketLine("command=ls-refs\n"))
+		buf.WriteString("0001")
+		buf.WriteString(gitProtocolPacketLine("symrefs\n"))
+		buf.WriteString(gitProtocolPacketLine("ref-prefix refs/heads/\n"))
+		buf.WriteString("0000")
+
+		req, err := http.NewRequest("POST", u.String()+"/git-upload-pack", &buf)
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/x-git-upload-pack-request")
+		req.Header.Set("Git-Protocol", "version=2")
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.Contains(t, string(body), "refs/heads/master")
+		assert.True(t, strings.HasSuffix(string(body), "0000"))
+	}
+}
+
+func doGitProtocolShallowClone(u *url.URL) func(*testing.T) {
+	return func(t *testing.T) {
+		dstPath, err := os.MkdirTemp("", "repo1-shallow")
+		assert.NoError(t, err)
+		defer util.RemoveAll(dstPath)
+
+		assert.NoError(t, git.CloneWithArgs(context.Background(), u.String(), dstPath, append(allowLFSFilters(), "-c", "protocol.version=2"), git.CloneRepoOptions{
+			Depth: 1,
+		}))
+		assertFileExist(t, filepath.Join(dstPath, "README.md"))
+
+		stdout, err := git.NewCommand("rev-parse", "--is-shallow-repository").RunInDir(dstPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "true", strings.TrimSpace(stdout))
+
+		stdout, err = git.NewCommand("rev-list", "--count", "HEAD").RunInDir(dstPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "1", strings.TrimSpace(stdout))
+	}
+}
+
+func doGitProtocolPartialClone(u *url.URL, filter string) func(*testing.T) {
+	return func(t *testing.T) {
+		dstPath, err := os.MkdirTemp("", "repo1-partial")
+		assert.NoError(t, err)
+		defer util.RemoveAll(dstPath)
+
+		assert.NoError(t, git.CloneWithArgs(context.Background(), u.String(), dstPath, append(allowLFSFilters(), "-c", "protocol.version=2"), git.CloneRepoOptions{
+			Filter: filter,
+		}))
+		// the checkout has to lazily fetch the missing objects from the server
+		assertFileExist(t, filepath.Join(dstPath, "README.md"))
+
+		// git only records the filter when the server accepted it
+		stdout, err := git.NewCommand("config", "remote.origin.partialclonefilter").RunInDir(dstPath)
+		assert.NoError(t, err)
+		assert.Equal(t, filter, strings.TrimSpace(stdout))
+
+		stdout, err = git.NewCommand("config", "remote.origin.promisor").RunInDir(dstPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "true", strings.TrimSpace(stdout))
+	}
+}
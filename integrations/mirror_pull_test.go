@@ -6,6 +6,7 @@ package integrations
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 
 	"code.gitea.io/gitea/models"
@@ -13,6 +14,7 @@ import (
 	"code.gitea.io/gitea/modules/git"
 	migration "code.gitea.io/gitea/modules/migrations/base"
 	"code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/structs"
 	mirror_service "code.gitea.io/gitea/services/mirror"
 	release_service "code.gitea.io/gitea/services/release"
 
@@ -93,3 +95,54 @@ func TestMirrorPull(t *testing.T) {
 	assert.NoError(t, err)
 	assert.EqualValues(t, initCount, count)
 }
+
+func TestMirrorPullMetadataWithoutCredentials(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	user := db.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	repo := db.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	opts := migration.MigrateOptions{
+		RepoName:  "test_mirror_metadata",
+		Mirror:    true,
+		CloneAddr: models.RepoPath(user.Name, repo.Name),
+	}
+
+	mirrorRepo, err := repository.CreateRepository(user, user, models.CreateRepoOptions{
+		Name:     opts.RepoName,
+		IsMirror: opts.Mirror,
+		Status:   models.RepositoryBeingMigrated,
+	})
+	assert.NoError(t, err)
+	ctx := context.Background()
+	mirrorRepo, err = repository.MigrateRepositoryGitData(ctx, user, mirrorRepo, opts)
+	assert.NoError(t, err)
+	mirrorRepo.OriginalServiceType = structs.GithubService
+	assert.NoError(t, models.UpdateRepositoryCols(mirrorRepo, "original_service_type"))
+
+	// the migration did not choose to sync the metadata, so it deleted the credentials
+	payload, err := json.Marshal(opts)
+	assert.NoError(t, err)
+	assert.NoError(t, models.CreateTask(&models.Task{
+		DoerID:         user.ID,
+		OwnerID:        user.ID,
+		RepoID:         mirrorRepo.ID,
+		Type:           structs.TaskTypeMigrateRepo,
+		Status:         structs.TaskStatusFinished,
+		PayloadContent: string(payload),
+	}))
+	canSync, err := mirror_service.CanSyncMetadata(mirrorRepo)
+	assert.NoError(t, err)
+	assert.False(t, canSync)
+
+	// a mirror syncing its metadata anyway reports the failure once
+	assert.NoError(t, mirrorRepo.GetMirror())
+	mirrorRepo.Mirror.SyncMetadata = true
+	assert.NoError(t, models.UpdateMirror(mirrorRepo.Mirror))
+	notices := models.CountNotices()
+	for i := 0; i < 2; i++ {
+		assert.True(t, mirror_service.SyncPullMirror(ctx, mirrorRepo.ID))
+		mirror := db.AssertExistsAndLoadBean(t, &models.Mirror{RepoID: mirrorRepo.ID}).(*models.Mirror)
+		assert.Contains(t, mirror.LastError, "sync issues and releases")
+	}
+	assert.EqualValues(t, notices+1, models.CountNotices())
+}
@@ -46,7 +46,7 @@ func testMirrorPush(t *testing.T, u *url.URL) {
 	assert.NoError(t, err)
 	assert.Len(t, mirrors, 1)
 
-	ok := mirror_service.SyncPushMirror(context.Background(), mirrors[0].ID)
+	ok := mirror_service.SyncPushMirror(context.Background(), mirrors[0].ID, models.PushMirrorSyncTriggerManual)
 	assert.True(t, ok)
 
 	srcGitRepo, err := git.OpenRepository(srcRepo.RepoPath())
@@ -0,0 +1,197 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	org_service "code.gitea.io/gitea/services/org"
+	repo_service "code.gitea.io/gitea/services/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIOrgTrash(t *testing.T) {
+	onGiteaRun(t, func(*testing.T, *url.URL) {
+		defer func(period time.Duration) {
+			setting.Repository.TrashPeriod = period
+		}(setting.Repository.TrashPeriod)
+		setting.Repository.TrashPeriod = 24 * time.Hour
+
+		session := loginUser(t, "user5")
+		token := getTokenForLoggedInUser(t, session)
+
+		req := NewRequestf(t, "DELETE", "/api/v1/orgs/user6?token=%s", token)
+		session.MakeRequest(t, req, http.StatusNoContent)
+		req = NewRequestf(t, "GET", "/api/v1/orgs/user6?token=%s", token)
+		session.MakeRequest(t, req, http.StatusNotFound)
+		req = NewRequestf(t, "GET", "/api/v1/teams/3?token=%s", token)
+		session.MakeRequest(t, req, http.StatusNotFound)
+
+		req = NewRequestf(t, "GET", "/api/v1/user/orgs/deleted?token=%s", token)
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		var deleted []*api.DeletedOrganization
+		DecodeJSON(t, resp, &deleted)
+		if assert.Len(t, deleted, 1) {
+			assert.Equal(t, "user6", deleted[0].Organization.UserName)
+			assert.Equal(t, "user5", deleted[0].DeletedBy.UserName)
+			assert.Equal(t, 24*time.Hour, deleted[0].Purge.Sub(deleted[0].Deleted))
+		}
+
+		// only owners and site administrators may restore an organization
+		otherSession := loginUser(t, "user4")
+		otherToken := getTokenForLoggedInUser(t, otherSession)
+		req = NewRequestf(t, "POST", "/api/v1/orgs/user6/restore?token=%s", otherToken)
+		otherSession.MakeRequest(t, req, http.StatusNotFound)
+
+		req = NewRequestf(t, "POST", "/api/v1/orgs/user6/restore?token=%s", token)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		var apiOrg api.Organization
+		DecodeJSON(t, resp, &apiOrg)
+		assert.EqualValues(t, 6, apiOrg.ID)
+		req = NewRequestf(t, "GET", "/api/v1/orgs/user6?token=%s", token)
+		session.MakeRequest(t, req, http.StatusOK)
+		req = NewRequestf(t, "POST", "/api/v1/orgs/user6/restore?token=%s", token)
+		session.MakeRequest(t, req, http.StatusNotFound)
+
+		// an organization with repositories outside of the trash is not deleted
+		ownerSession := loginUser(t, "user2")
+		ownerToken := getTokenForLoggedInUser(t, ownerSession)
+		req = NewRequestf(t, "DELETE", "/api/v1/orgs/user3?token=%s", ownerToken)
+		ownerSession.MakeRequest(t, req, http.StatusConflict)
+
+		adminSession := loginUser(t, "user1")
+		adminToken := getTokenForLoggedInUser(t, adminSession)
+		req = NewRequestf(t, "DELETE", "/api/v1/orgs/user6?token=%s", token)
+		session.MakeRequest(t, req, http.StatusNoContent)
+		req = NewRequestf(t, "GET", "/api/v1/admin/orgs/deleted?token=%s", adminToken)
+		resp = adminSession.MakeRequest(t, req, http.StatusOK)
+		DecodeJSON(t, resp, &deleted)
+		assert.Len(t, deleted, 1)
+
+		// only site administrators may empty the trash
+		req = NewRequestf(t, "DELETE", "/api/v1/admin/orgs/deleted?token=%s", token)
+		session.MakeRequest(t, req, http.StatusForbidden)
+		req = NewRequestf(t, "DELETE", "/api/v1/admin/orgs/deleted?token=%s", adminToken)
+		adminSession.MakeRequest(t, req, http.StatusNoContent)
+		db.AssertNotExistsBean(t, &models.User{ID: 6})
+	})
+}
+
+func TestPurgeExpiredOrganizations(t *testing.T) {
+	defer prepareTestEnv(t)()
+	defer func(period time.Duration) {
+		setting.Repository.TrashPeriod = period
+	}(setting.Repository.TrashPeriod)
+	setting.Repository.TrashPeriod = 24 * time.Hour
+
+	doer := db.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	org := db.AssertExistsAndLoadBean(t, &models.User{ID: 3}).(*models.User)
+	repo := db.AssertExistsAndLoadBean(t, &models.Repository{ID: 3}).(*models.Repository)
+	repos := make([]*models.Repository, 0, org.NumRepos)
+	assert.NoError(t, db.GetEngine(db.DefaultContext).Where("owner_id = ?", org.ID).Find(&repos))
+	for _, repo := range repos {
+		assert.NoError(t, repo_service.DeleteRepository(doer, repo))
+	}
+	assert.NoError(t, org_service.DeleteOrganization(doer, org))
+
+	// the repositories of an organization in the trash are restored after it
+	err := repo_service.RestoreRepository(repo)
+	assert.True(t, models.IsErrOrgInTrash(err), "unexpected error: %v", err)
+
+	// the trash period has not expired yet
+	assert.NoError(t, org_service.PurgeExpiredOrganizations(context.Background()))
+	db.AssertExistsAndLoadBean(t, &models.User{ID: 3})
+
+	// nothing is purged once the trash is disabled
+	setting.Repository.TrashPeriod = 0
+	assert.NoError(t, org_service.PurgeExpiredOrganizations(context.Background()))
+	db.AssertExistsAndLoadBean(t, &models.User{ID: 3})
+
+	// until a site administrator empties the trash, which purges its repositories too
+	assert.Error(t, org_service.EmptyTrash(context.Background(), doer))
+	db.AssertExistsAndLoadBean(t, &models.User{ID: 3})
+	admin := db.AssertExistsAndLoadBean(t, &models.User{ID: 1}).(*models.User)
+	assert.NoError(t, org_service.EmptyTrash(context.Background(), admin))
+	db.AssertNotExistsBean(t, &models.User{ID: 3})
+	db.AssertNotExistsBean(t, &models.Repository{OwnerID: 3})
+	db.AssertExistsAndLoadBean(t, &models.AuditEvent{OwnerID: 3, Action: models.AuditOrgPurge})
+	db.AssertExistsAndLoadBean(t, &models.AuditEvent{RepoID: 3, Action: models.AuditRepoPurge})
+}
+
+func TestOrgTrash(t *testing.T) {
+	onGiteaRun(t, func(*testing.T, *url.URL) {
+		defer func(period time.Duration) {
+			setting.Repository.TrashPeriod = period
+		}(setting.Repository.TrashPeriod)
+		setting.Repository.TrashPeriod = 24 * time.Hour
+
+		session := loginUser(t, "user5")
+		req := NewRequestWithValues(t, "POST", "/org/user6/settings/delete", map[string]string{
+			"_csrf":    GetCSRF(t, session, "/org/user6/settings/delete"),
+			"org_name": "user6",
+		})
+		session.MakeRequest(t, req, http.StatusFound)
+		session.MakeRequest(t, NewRequest(t, "GET", "/user6"), http.StatusNotFound)
+		session.MakeRequest(t, NewRequest(t, "GET", "/org/user6/settings"), http.StatusNotFound)
+
+		// no repositories can be created in an organization in the trash
+		req = NewRequestWithValues(t, "POST", "/repo/create", map[string]string{
+			"_csrf":     GetCSRF(t, session, "/repo/create"),
+			"uid":       "6",
+			"repo_name": "repo-in-trash",
+		})
+		session.MakeRequest(t, req, http.StatusNotFound)
+		db.AssertNotExistsBean(t, &models.Repository{OwnerID: 6})
+
+		req = NewRequest(t, "GET", "/user/settings/organization/trash")
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		assert.EqualValues(t, 1, htmlDoc.Find(`tbody input[name="id"][value="6"]`).Length())
+
+		// a member who is not an owner does not find it
+		otherSession := loginUser(t, "user4")
+		req = NewRequestWithValues(t, "POST", "/user/settings/organization/trash/restore", map[string]string{
+			"_csrf": GetCSRF(t, otherSession, "/user/settings/organization/trash"),
+			"id":    "6",
+		})
+		otherSession.MakeRequest(t, req, http.StatusNotFound)
+
+		req = NewRequestWithValues(t, "POST", "/user/settings/organization/trash/restore", map[string]string{
+			"_csrf": htmlDoc.GetCSRF(),
+			"id":    "6",
+		})
+		session.MakeRequest(t, req, http.StatusFound)
+		session.MakeRequest(t, NewRequest(t, "GET", "/user6"), http.StatusOK)
+
+		// site administrators see all organizations in the trash
+		req = NewRequestWithValues(t, "POST", "/org/user6/settings/delete", map[string]string{
+			"_csrf":    GetCSRF(t, session, "/org/user6/settings/delete"),
+			"org_name": "user6",
+		})
+		session.MakeRequest(t, req, http.StatusFound)
+		adminSession := loginUser(t, "user1")
+		req = NewRequest(t, "GET", "/admin/orgs/trash")
+		resp = adminSession.MakeRequest(t, req, http.StatusOK)
+		htmlDoc = NewHTMLParser(t, resp.Body)
+		assert.EqualValues(t, 1, htmlDoc.Find(`tbody input[name="id"][value="6"]`).Length())
+
+		req = NewRequestWithValues(t, "POST", "/admin/orgs/trash/purge", map[string]string{
+			"_csrf": htmlDoc.GetCSRF(),
+			"id":    "6",
+		})
+		resp = adminSession.MakeRequest(t, req, http.StatusOK)
+		assert.Contains(t, resp.Body.String(), "/admin/orgs/trash")
+		db.AssertNotExistsBean(t, &models.User{ID: 6})
+	})
+}
@@ -8,10 +8,13 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/git"
+	api "code.gitea.io/gitea/modules/structs"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -73,3 +76,93 @@ func TestRepoForkToOrg(t *testing.T) {
 	_, exists := htmlDoc.doc.Find("a.ui.button[href^=\"/repo/fork/\"]").Attr("href")
 	assert.False(t, exists, "Forking should not be allowed anymore")
 }
+
+func TestRepoForkMergeUpstream(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user1")
+		testRepoFork(t, session, "user2", "repo1", "user1", "repo1")
+		token := getTokenForLoggedInUser(t, session)
+
+		user1 := db.AssertExistsAndLoadBean(t, &models.User{Name: "user1"}).(*models.User)
+		user2 := db.AssertExistsAndLoadBean(t, &models.User{Name: "user2"}).(*models.User)
+		repo := db.AssertExistsAndLoadBean(t, &models.Repository{OwnerID: user2.ID, Name: "repo1"}).(*models.Repository)
+		fork := db.AssertExistsAndLoadBean(t, &models.Repository{OwnerID: user1.ID, Name: "repo1"}).(*models.Repository)
+
+		// The fork is behind once the upstream branch moves on
+		resp, err := createFileInBranch(user2, repo, "upstream.md", "master", "upstream")
+		assert.NoError(t, err)
+		upstreamCommitID := resp.Commit.SHA
+
+		req := NewRequest(t, "GET", "/user1/repo1")
+		htmlDoc := NewHTMLParser(t, session.MakeRequest(t, req, http.StatusOK).Body)
+		assert.Contains(t, htmlDoc.doc.Find("#upstream-divergence").Text(), "0 commit(s) ahead and 1 commit(s) behind")
+		style, exists := htmlDoc.doc.Find("#upstream-divergence button").Attr("value")
+		assert.True(t, exists)
+		assert.EqualValues(t, "fast-forward", style)
+
+		mergeUpstream := func(style string, status int) *api.MergeUpstreamResult {
+			req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user1/repo1/merge-upstream?token="+token, &api.MergeUpstreamOption{
+				Branch: "master",
+				Style:  style,
+			})
+			resp := session.MakeRequest(t, req, status)
+			if status != http.StatusOK {
+				return nil
+			}
+			var result api.MergeUpstreamResult
+			DecodeJSON(t, resp, &result)
+			return &result
+		}
+
+		result := mergeUpstream("", http.StatusOK)
+		assert.EqualValues(t, "fast-forward", result.Style)
+		assert.EqualValues(t, "user2/repo1:master", result.Upstream)
+		assert.EqualValues(t, upstreamCommitID, result.Commit)
+
+		result = mergeUpstream("", http.StatusOK)
+		assert.Empty(t, result.Style)
+
+		req = NewRequest(t, "GET", "/user1/repo1")
+		htmlDoc = NewHTMLParser(t, session.MakeRequest(t, req, http.StatusOK).Body)
+		assert.Contains(t, htmlDoc.doc.Find("#upstream-divergence").Text(), "This branch is even with")
+		assert.EqualValues(t, 0, htmlDoc.doc.Find("#upstream-divergence button").Length())
+
+		// A diverged branch cannot be fast-forwarded but can be merged from the home page
+		_, err = createFileInBranch(user1, fork, "fork.md", "master", "fork")
+		assert.NoError(t, err)
+		_, err = createFileInBranch(user2, repo, "upstream2.md", "master", "upstream")
+		assert.NoError(t, err)
+		mergeUpstream("fast-forward", http.StatusConflict)
+
+		req = NewRequest(t, "GET", "/user1/repo1")
+		htmlDoc = NewHTMLParser(t, session.MakeRequest(t, req, http.StatusOK).Body)
+		assert.Contains(t, htmlDoc.doc.Find("#upstream-divergence").Text(), "1 commit(s) ahead and 1 commit(s) behind")
+		req = NewRequestWithValues(t, "POST", "/user1/repo1/branches/merge-upstream", map[string]string{
+			"_csrf":  htmlDoc.GetCSRF(),
+			"branch": "master",
+			"style":  "merge",
+		})
+		session.MakeRequest(t, req, http.StatusFound)
+
+		req = NewRequest(t, "GET", "/user1/repo1")
+		htmlDoc = NewHTMLParser(t, session.MakeRequest(t, req, http.StatusOK).Body)
+		assert.Contains(t, htmlDoc.doc.Find("#upstream-divergence").Text(), "2 commit(s) ahead and 0 commit(s) behind")
+
+		// Rebasing puts the commits of the fork on top of the upstream branch
+		resp, err = createFileInBranch(user2, repo, "upstream3.md", "master", "upstream")
+		assert.NoError(t, err)
+		upstreamCommitID = resp.Commit.SHA
+		result = mergeUpstream("rebase", http.StatusOK)
+		assert.EqualValues(t, "rebase", result.Style)
+
+		gitRepo, err := git.OpenRepository(fork.RepoPath())
+		assert.NoError(t, err)
+		defer gitRepo.Close()
+		commit, err := gitRepo.GetBranchCommit("master")
+		assert.NoError(t, err)
+		assert.EqualValues(t, result.Commit, commit.ID.String())
+		isAncestor, err := commit.HasPreviousCommit(git.MustIDFromString(upstreamCommitID))
+		assert.NoError(t, err)
+		assert.True(t, isAncestor)
+	})
+}
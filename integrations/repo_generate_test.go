@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
 	"code.gitea.io/gitea/models"
@@ -66,3 +68,84 @@ func TestRepoGenerateToOrg(t *testing.T) {
 	session := loginUser(t, "user2")
 	testRepoGenerate(t, session, "user27", "template1", "user2", "generated2")
 }
+
+func TestRepoGenerateWithVariablesAndSync(t *testing.T) {
+	onGiteaRun(t, testRepoGenerateWithVariablesAndSync)
+}
+
+func testRepoGenerateWithVariablesAndSync(t *testing.T, u *url.URL) {
+	templateOwner := db.AssertExistsAndLoadBean(t, &models.User{Name: "user27"}).(*models.User)
+	templateRepo := db.AssertExistsAndLoadBean(t, &models.Repository{OwnerID: templateOwner.ID, Name: "template1"}).(*models.Repository)
+	for treePath, content := range map[string]string{
+		".gitea/template":      "**.md\n",
+		".gitea/template.yaml": "variables:\n  - name: SERVICE\n    required: true\n  - name: DOCKER\n    type: bool\nfiles:\n  - glob: \"docker/**\"\n    if: DOCKER\n",
+		"${SERVICE}/README.md": "Service $SERVICE in $REPO_NAME\n",
+		"docker/Dockerfile":    "FROM scratch\n",
+	} {
+		_, err := createFileInBranch(templateOwner, templateRepo, treePath, templateRepo.DefaultBranch, content)
+		assert.NoError(t, err)
+	}
+
+	session := loginUser(t, "user1")
+	generateOwner := db.AssertExistsAndLoadBean(t, &models.User{Name: "user1"}).(*models.User)
+
+	req := NewRequestf(t, "GET", "/repo/create?template_id=%d", templateRepo.ID)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 1, htmlDoc.doc.Find("input[name=\"template_variable_SERVICE\"]").Length())
+
+	values := map[string]string{
+		"_csrf":                     htmlDoc.GetCSRF(),
+		"uid":                       fmt.Sprintf("%d", generateOwner.ID),
+		"repo_name":                 "generated-vars",
+		"repo_template":             fmt.Sprintf("%d", templateRepo.ID),
+		"git_content":               "true",
+		"template_variable_DOCKER":  "false",
+		"template_variable_SERVICE": "",
+	}
+	req = NewRequestWithValues(t, "POST", "/repo/create", values)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	assert.Contains(t, resp.Body.String(), "SERVICE is required")
+
+	values["template_variable_SERVICE"] = "api"
+	req = NewRequestWithValues(t, "POST", "/repo/create", values)
+	session.MakeRequest(t, req, http.StatusFound)
+
+	req = NewRequest(t, "GET", "/user1/generated-vars/raw/branch/master/api/README.md")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, "Service api in generated-vars\n", resp.Body.String())
+	req = NewRequest(t, "GET", "/user1/generated-vars/raw/branch/master/docker/Dockerfile")
+	session.MakeRequest(t, req, http.StatusNotFound)
+	req = NewRequest(t, "GET", "/user1/generated-vars/raw/branch/master/.gitea/template.yaml")
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	// Sync a change of the template into the generated repository
+	_, err := createFileInBranch(templateOwner, templateRepo, "${SERVICE}/CHANGELOG.md", templateRepo.DefaultBranch, "Changes of $SERVICE\n")
+	assert.NoError(t, err)
+
+	req = NewRequest(t, "GET", "/user1/generated-vars/settings")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	req = NewRequestWithValues(t, "POST", "/user1/generated-vars/settings", map[string]string{
+		"_csrf":  htmlDoc.GetCSRF(),
+		"action": "template-sync",
+	})
+	resp = session.MakeRequest(t, req, http.StatusFound)
+	assert.Equal(t, "/user1/generated-vars/pulls/1", resp.Header().Get("Location"))
+
+	generateRepo := db.AssertExistsAndLoadBean(t, &models.Repository{OwnerID: generateOwner.ID, Name: "generated-vars"}).(*models.Repository)
+	pr := db.AssertExistsAndLoadBean(t, &models.PullRequest{BaseRepoID: generateRepo.ID, Index: 1}).(*models.PullRequest)
+	assert.True(t, strings.HasPrefix(pr.HeadBranch, "template-sync/"))
+
+	req = NewRequestf(t, "GET", "/user1/generated-vars/raw/branch/%s/api/CHANGELOG.md", pr.HeadBranch)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, "Changes of api\n", resp.Body.String())
+
+	// A second sync is refused while the pull request is open
+	req = NewRequestWithValues(t, "POST", "/user1/generated-vars/settings", map[string]string{
+		"_csrf":  htmlDoc.GetCSRF(),
+		"action": "template-sync",
+	})
+	resp = session.MakeRequest(t, req, http.StatusFound)
+	assert.Equal(t, "/user1/generated-vars/settings", resp.Header().Get("Location"))
+}
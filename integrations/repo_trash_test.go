@@ -0,0 +1,243 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	issue_service "code.gitea.io/gitea/services/issue"
+	repo_service "code.gitea.io/gitea/services/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRepoTrash(t *testing.T) {
+	onGiteaRun(t, func(*testing.T, *url.URL) {
+		defer func(period time.Duration) {
+			setting.Repository.TrashPeriod = period
+		}(setting.Repository.TrashPeriod)
+		setting.Repository.TrashPeriod = 24 * time.Hour
+
+		session := loginUser(t, "user2")
+		token := getTokenForLoggedInUser(t, session)
+
+		req := NewRequestf(t, "DELETE", "/api/v1/repos/user2/repo1?token=%s", token)
+		session.MakeRequest(t, req, http.StatusNoContent)
+		req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1?token=%s", token)
+		session.MakeRequest(t, req, http.StatusNotFound)
+		req = NewRequestf(t, "GET", "/api/v1/repositories/1?token=%s", token)
+		session.MakeRequest(t, req, http.StatusNotFound)
+
+		req = NewRequestf(t, "GET", "/api/v1/user/repos/deleted?token=%s", token)
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		var deleted []*api.DeletedRepository
+		DecodeJSON(t, resp, &deleted)
+		if assert.Len(t, deleted, 1) {
+			assert.Equal(t, "user2/repo1", deleted[0].Repository.FullName)
+			assert.Equal(t, "user2", deleted[0].DeletedBy.UserName)
+			assert.Equal(t, 24*time.Hour, deleted[0].Purge.Sub(deleted[0].Deleted))
+		}
+
+		// only owners and site administrators may restore a repository
+		otherSession := loginUser(t, "user4")
+		otherToken := getTokenForLoggedInUser(t, otherSession)
+		req = NewRequestf(t, "POST", "/api/v1/repos/user2/repo1/restore?token=%s", otherToken)
+		otherSession.MakeRequest(t, req, http.StatusNotFound)
+
+		req = NewRequestf(t, "POST", "/api/v1/repos/user2/repo1/restore?token=%s", token)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		var apiRepo api.Repository
+		DecodeJSON(t, resp, &apiRepo)
+		assert.EqualValues(t, 1, apiRepo.ID)
+		req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1?token=%s", token)
+		session.MakeRequest(t, req, http.StatusOK)
+		req = NewRequestf(t, "POST", "/api/v1/repos/user2/repo1/restore?token=%s", token)
+		session.MakeRequest(t, req, http.StatusNotFound)
+
+		// an organization owner manages the trash of the organization
+		req = NewRequestf(t, "DELETE", "/api/v1/repos/user3/repo3?token=%s", token)
+		session.MakeRequest(t, req, http.StatusNoContent)
+		req = NewRequestf(t, "GET", "/api/v1/orgs/user3/repos/deleted?token=%s", token)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		DecodeJSON(t, resp, &deleted)
+		if assert.Len(t, deleted, 1) {
+			assert.Equal(t, "user3/repo3", deleted[0].Repository.FullName)
+		}
+
+		adminSession := loginUser(t, "user1")
+		adminToken := getTokenForLoggedInUser(t, adminSession)
+		req = NewRequestf(t, "GET", "/api/v1/admin/repos/deleted?token=%s", adminToken)
+		resp = adminSession.MakeRequest(t, req, http.StatusOK)
+		DecodeJSON(t, resp, &deleted)
+		assert.Len(t, deleted, 1)
+		req = NewRequestf(t, "DELETE", "/api/v1/repos/user3/repo3/purge?token=%s", adminToken)
+		adminSession.MakeRequest(t, req, http.StatusNoContent)
+		db.AssertNotExistsBean(t, &models.Repository{ID: 3})
+
+		// only site administrators may empty the trash
+		req = NewRequestf(t, "DELETE", "/api/v1/repos/user2/repo2?token=%s", token)
+		session.MakeRequest(t, req, http.StatusNoContent)
+		req = NewRequestf(t, "DELETE", "/api/v1/admin/repos/deleted?token=%s", token)
+		session.MakeRequest(t, req, http.StatusForbidden)
+		req = NewRequestf(t, "DELETE", "/api/v1/admin/repos/deleted?token=%s", adminToken)
+		adminSession.MakeRequest(t, req, http.StatusNoContent)
+		db.AssertNotExistsBean(t, &models.Repository{ID: 2})
+	})
+}
+
+func TestPurgeExpiredRepositories(t *testing.T) {
+	defer prepareTestEnv(t)()
+	defer func(period time.Duration) {
+		setting.Repository.TrashPeriod = period
+	}(setting.Repository.TrashPeriod)
+	setting.Repository.TrashPeriod = 24 * time.Hour
+
+	doer := db.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	repo := db.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	assert.NoError(t, repo_service.DeleteRepository(doer, repo))
+
+	// the trash period has not expired yet
+	assert.NoError(t, repo_service.PurgeExpiredRepositories(context.Background()))
+	db.AssertExistsAndLoadBean(t, &models.Repository{ID: 1})
+
+	// nothing is purged once the trash is disabled
+	setting.Repository.TrashPeriod = 0
+	assert.NoError(t, repo_service.PurgeExpiredRepositories(context.Background()))
+	db.AssertExistsAndLoadBean(t, &models.Repository{ID: 1})
+
+	// until a site administrator empties the trash
+	assert.Error(t, repo_service.EmptyTrash(context.Background(), doer))
+	db.AssertExistsAndLoadBean(t, &models.Repository{ID: 1})
+	admin := db.AssertExistsAndLoadBean(t, &models.User{ID: 1}).(*models.User)
+	assert.NoError(t, repo_service.EmptyTrash(context.Background(), admin))
+	db.AssertNotExistsBean(t, &models.Repository{ID: 1})
+	db.AssertExistsAndLoadBean(t, &models.AuditEvent{RepoID: 1, Action: models.AuditRepoPurge})
+}
+
+func TestRepoTrashPulls(t *testing.T) {
+	defer prepareTestEnv(t)()
+	defer func(period time.Duration) {
+		setting.Repository.TrashPeriod = period
+	}(setting.Repository.TrashPeriod)
+	setting.Repository.TrashPeriod = 24 * time.Hour
+
+	// the pull request of the fork user13/repo11 into user12/repo10 is closed when the fork goes to the trash
+	doer := db.AssertExistsAndLoadBean(t, &models.User{ID: 13}).(*models.User)
+	fork := db.AssertExistsAndLoadBean(t, &models.Repository{ID: 11}).(*models.Repository)
+	assert.NoError(t, repo_service.DeleteRepository(doer, fork))
+	issue := db.AssertExistsAndLoadBean(t, &models.Issue{ID: 8}).(*models.Issue)
+	assert.True(t, issue.IsClosed)
+
+	// and cannot be reopened while the fork is in the trash
+	assert.NoError(t, issue.LoadRepo())
+	err := issue_service.ChangeStatus(issue, doer, false)
+	assert.True(t, models.IsErrPullHeadRepoInTrash(err), "unexpected error: %v", err)
+
+	// it stays closed when the fork is restored, but can be reopened again
+	fork, err = models.GetDeletedRepositoryByID(11)
+	assert.NoError(t, err)
+	assert.NoError(t, repo_service.RestoreRepository(fork))
+	issue = db.AssertExistsAndLoadBean(t, &models.Issue{ID: 8}).(*models.Issue)
+	assert.True(t, issue.IsClosed)
+	assert.NoError(t, issue.LoadRepo())
+	assert.NoError(t, issue_service.ChangeStatus(issue, doer, false))
+}
+
+func TestRepoTrash(t *testing.T) {
+	onGiteaRun(t, func(*testing.T, *url.URL) {
+		defer func(period time.Duration) {
+			setting.Repository.TrashPeriod = period
+		}(setting.Repository.TrashPeriod)
+		setting.Repository.TrashPeriod = 24 * time.Hour
+
+		session := loginUser(t, "user2")
+		req := NewRequestWithValues(t, "POST", "/user2/repo1/settings", map[string]string{
+			"_csrf":     GetCSRF(t, session, "/user2/repo1/settings"),
+			"action":    "delete",
+			"repo_name": "repo1",
+		})
+		session.MakeRequest(t, req, http.StatusFound)
+		session.MakeRequest(t, NewRequest(t, "GET", "/user2/repo1"), http.StatusNotFound)
+
+		// a repository in the trash is not found by its id either
+		session.MakeRequest(t, NewRequest(t, "GET", "/repo/fork/1"), http.StatusNotFound)
+		otherSession := loginUser(t, "user4")
+		req = NewRequestWithValues(t, "POST", "/repo/fork/1", map[string]string{
+			"_csrf":     GetCSRF(t, otherSession, "/user/settings"),
+			"uid":       "4",
+			"repo_name": "repo1",
+		})
+		otherSession.MakeRequest(t, req, http.StatusNotFound)
+		db.AssertNotExistsBean(t, &models.Repository{OwnerID: 4, ForkID: 1})
+
+		req = NewRequest(t, "GET", "/user/settings/repos/trash")
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		assert.EqualValues(t, 1, htmlDoc.Find(`tbody input[name="id"][value="1"]`).Length())
+
+		req = NewRequestWithValues(t, "POST", "/user/settings/repos/trash/restore", map[string]string{
+			"_csrf": htmlDoc.GetCSRF(),
+			"id":    "1",
+		})
+		session.MakeRequest(t, req, http.StatusFound)
+		session.MakeRequest(t, NewRequest(t, "GET", "/user2/repo1"), http.StatusOK)
+
+		// the trash of an organization is managed in its settings
+		req = NewRequestWithValues(t, "POST", "/user3/repo3/settings", map[string]string{
+			"_csrf":     GetCSRF(t, session, "/user3/repo3/settings"),
+			"action":    "delete",
+			"repo_name": "repo3",
+		})
+		session.MakeRequest(t, req, http.StatusFound)
+
+		req = NewRequest(t, "GET", "/org/user3/settings/trash")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc = NewHTMLParser(t, resp.Body)
+		assert.EqualValues(t, 1, htmlDoc.Find(`tbody input[name="id"][value="3"]`).Length())
+
+		// a repository of another owner is not found in this trash
+		req = NewRequestWithValues(t, "POST", "/user/settings/repos/trash/purge", map[string]string{
+			"_csrf": htmlDoc.GetCSRF(),
+			"id":    "3",
+		})
+		session.MakeRequest(t, req, http.StatusNotFound)
+
+		req = NewRequestWithValues(t, "POST", "/org/user3/settings/trash/purge", map[string]string{
+			"_csrf": htmlDoc.GetCSRF(),
+			"id":    "3",
+		})
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		assert.Contains(t, resp.Body.String(), "/user3/settings/trash")
+		db.AssertNotExistsBean(t, &models.Repository{ID: 3})
+
+		// site administrators see the trash of all owners
+		adminSession := loginUser(t, "user1")
+		req = NewRequest(t, "GET", "/admin/repos/trash")
+		resp = adminSession.MakeRequest(t, req, http.StatusOK)
+		assert.EqualValues(t, 0, NewHTMLParser(t, resp.Body).Find(`tbody input[name="id"]`).Length())
+
+		// and can empty it
+		doer := db.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+		repo := db.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+		assert.NoError(t, repo_service.DeleteRepository(doer, repo))
+		req = NewRequest(t, "GET", "/admin/repos/trash")
+		resp = adminSession.MakeRequest(t, req, http.StatusOK)
+		htmlDoc = NewHTMLParser(t, resp.Body)
+		assert.EqualValues(t, 1, htmlDoc.Find(`[data-modal-id="empty-trash-modal"]`).Length())
+		req = NewRequestWithValues(t, "POST", "/admin/repos/trash/empty", map[string]string{
+			"_csrf": htmlDoc.GetCSRF(),
+		})
+		adminSession.MakeRequest(t, req, http.StatusOK)
+		db.AssertNotExistsBean(t, &models.Repository{ID: 1})
+	})
+}
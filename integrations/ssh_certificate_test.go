@@ -0,0 +1,218 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func TestSSHCertificate(t *testing.T) {
+	onGiteaRun(t, testSSHCertificate)
+}
+
+func testSSHCertificate(t *testing.T, u *url.URL) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen is required to issue test certificates")
+		return
+	}
+
+	tmpDir, err := os.MkdirTemp("", "ssh-certificate")
+	assert.NoError(t, err)
+	defer util.RemoveAll(tmpDir)
+	assert.NoError(t, os.Chmod(tmpDir, 0700))
+
+	caFile := filepath.Join(tmpDir, "ca")
+	keyFile := filepath.Join(tmpDir, "user-key")
+	assert.NoError(t, exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", caFile).Run())
+	assert.NoError(t, exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", keyFile).Run())
+
+	caPub, err := os.ReadFile(caFile + ".pub")
+	assert.NoError(t, err)
+	caKey, _, _, _, err := gossh.ParseAuthorizedKey(caPub)
+	assert.NoError(t, err)
+
+	oldTrustedUserCAKeysParsed := setting.SSH.TrustedUserCAKeysParsed
+	setting.SSH.TrustedUserCAKeysParsed = []gossh.PublicKey{caKey}
+	defer func() {
+		setting.SSH.TrustedUserCAKeysParsed = oldTrustedUserCAKeysParsed
+	}()
+
+	user := db.AssertExistsAndLoadBean(t, &models.User{Name: "user2"}).(*models.User)
+	_, err = models.AddPrincipalKey(user.ID, "user2-principal", 0)
+	assert.NoError(t, err)
+
+	// ssh picks up <key>-cert.pub automatically
+	signCertificate := func(t *testing.T, principal, validity string, options ...string) {
+		args := []string{"-q", "-s", caFile, "-I", "gitea-test", "-z", "42", "-n", principal, "-V", validity}
+		for _, option := range options {
+			args = append(args, "-O", option)
+		}
+		assert.NoError(t, exec.Command("ssh-keygen", append(args, keyFile+".pub")...).Run())
+	}
+
+	os.Setenv("GIT_SSH_COMMAND",
+		"ssh -o UserKnownHostsFile=/dev/null -o StrictHostKeyChecking=no -o IdentitiesOnly=yes -i \""+keyFile+"\"")
+	os.Setenv("GIT_SSH_VARIANT", "ssh")
+
+	sshURL := createSSHUrl("user2/repo1.git", u)
+
+	t.Run("ValidCertificate", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+		signCertificate(t, "user2-principal", "-5m:+1h")
+		dstPath, err := os.MkdirTemp("", "repo1-certificate")
+		assert.NoError(t, err)
+		defer util.RemoveAll(dstPath)
+		t.Run("Clone", doGitClone(dstPath, sshURL))
+	})
+
+	t.Run("UnknownPrincipal", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+		signCertificate(t, "unknown-principal", "-5m:+1h")
+		t.Run("Clone", doGitCloneFail(sshURL))
+	})
+
+	t.Run("ExpiredCertificate", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+		signCertificate(t, "user2-principal", "-2h:-1h")
+		t.Run("Clone", doGitCloneFail(sshURL))
+	})
+
+	t.Run("NotYetValidCertificate", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+		signCertificate(t, "user2-principal", "+1h:+2h")
+		t.Run("Clone", doGitCloneFail(sshURL))
+	})
+
+	t.Run("SourceAddressMismatch", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+		signCertificate(t, "user2-principal", "-5m:+1h", "source-address=192.0.2.1/32")
+		t.Run("Clone", doGitCloneFail(sshURL))
+	})
+
+	t.Run("UnsupportedCriticalOption", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+		signCertificate(t, "user2-principal", "-5m:+1h", "verify-required")
+		t.Run("Clone", doGitCloneFail(sshURL))
+	})
+
+	t.Run("ForceCommand", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+		signCertificate(t, "user2-principal", "-5m:+1h", "force-command=git-upload-pack 'user2/repo2.git'")
+		dstPath, err := os.MkdirTemp("", "repo1-certificate")
+		assert.NoError(t, err)
+		defer util.RemoveAll(dstPath)
+		// the certificate forces the clone of repo2 whatever the client asks for
+		assert.NoError(t, git.CloneWithArgs(context.Background(), sshURL.String(), dstPath, allowLFSFilters(), git.CloneRepoOptions{}))
+		assertFileExist(t, filepath.Join(dstPath, "Home.md"))
+	})
+
+	t.Run("ForceCommandAfterQueryingAnotherKey", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+		signCertificate(t, "user2-principal", "-5m:+1h", "force-command=git-upload-pack 'user2/repo2.git'")
+		certSigner := loadCertificateSigner(t, keyFile)
+
+		// a registered key of another user, which anyone can query as public keys are published
+		otherSigner := generateSigner(t)
+		_, err := models.AddPublicKey(5, "user5-key", strings.TrimSpace(string(gossh.MarshalAuthorizedKey(otherSigner.PublicKey()))), 0)
+		assert.NoError(t, err)
+		// an unknown key fails to authenticate without closing the connection
+		unknownSigner := generateSigner(t)
+
+		// query the certificate and the other key before authenticating with the certificate
+		client, err := gossh.Dial("tcp", sshURL.Host, &gossh.ClientConfig{
+			User: "git",
+			Auth: []gossh.AuthMethod{gossh.PublicKeys(
+				&switchingSigner{query: certSigner, sign: unknownSigner},
+				&switchingSigner{query: otherSigner, sign: certSigner},
+			)},
+			HostKeyCallback: gossh.InsecureIgnoreHostKey(),
+		})
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer client.Close()
+		session, err := client.NewSession()
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer session.Close()
+
+		// the certificate still forces the upload of repo2, which the other user cannot read,
+		// and the flush packet ends it after the refs are advertised
+		session.Stdin = strings.NewReader("0000")
+		out, err := session.Output("git-upload-pack 'user2/not-found.git'")
+		assert.NoError(t, err)
+		assert.Contains(t, string(out), "refs/heads/master")
+	})
+
+	t.Run("UntrustedAuthority", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+		signCertificate(t, "user2-principal", "-5m:+1h")
+		setting.SSH.TrustedUserCAKeysParsed = nil
+		defer func() {
+			setting.SSH.TrustedUserCAKeysParsed = []gossh.PublicKey{caKey}
+		}()
+		t.Run("Clone", doGitCloneFail(sshURL))
+	})
+}
+
+func loadCertificateSigner(t *testing.T, keyFile string) gossh.Signer {
+	privateKey, err := os.ReadFile(keyFile)
+	assert.NoError(t, err)
+	keySigner, err := gossh.ParsePrivateKey(privateKey)
+	assert.NoError(t, err)
+	certPub, err := os.ReadFile(keyFile + "-cert.pub")
+	assert.NoError(t, err)
+	cert, _, _, _, err := gossh.ParseAuthorizedKey(certPub)
+	assert.NoError(t, err)
+	certSigner, err := gossh.NewCertSigner(cert.(*gossh.Certificate), keySigner)
+	assert.NoError(t, err)
+	return certSigner
+}
+
+func generateSigner(t *testing.T) gossh.Signer {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	signer, err := gossh.NewSignerFromKey(privateKey)
+	assert.NoError(t, err)
+	return signer
+}
+
+// switchingSigner makes the client query the server for one key, then authenticate with another.
+// The client asks for the public key of a signer once to query it and once to authenticate.
+type switchingSigner struct {
+	query, sign gossh.Signer
+	queried     bool
+}
+
+func (s *switchingSigner) PublicKey() gossh.PublicKey {
+	if !s.queried {
+		s.queried = true
+		return s.query.PublicKey()
+	}
+	return s.sign.PublicKey()
+}
+
+func (s *switchingSigner) Sign(rand io.Reader, data []byte) (*gossh.Signature, error) {
+	return s.sign.Sign(rand, data)
+}
@@ -377,6 +377,9 @@ func activityQueryCondition(opts GetFeedsOptions) (builder.Cond, error) {
 
 	cond = cond.And(builder.Eq{"user_id": opts.RequestedUser.ID})
 
+	// the actions of repositories in the trash are hidden with them, also from site administrators
+	cond = cond.And(builder.NotIn("repo_id", builder.Select("id").From("repository").Where(builder.Neq{"deleted_unix": 0})))
+
 	if opts.OnlyPerformedBy {
 		cond = cond.And(builder.Eq{"act_user_id": opts.RequestedUser.ID})
 	}
@@ -23,6 +23,8 @@ const (
 	NoticeRepository NoticeType = iota + 1
 	// NoticeTask type
 	NoticeTask
+	// NoticeWebhook type
+	NoticeWebhook
 )
 
 // Notice represents a system notice for admin.
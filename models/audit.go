@@ -0,0 +1,283 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// AuditAction represents the kind of a security relevant action
+type AuditAction string
+
+// Possible audit actions
+const (
+	AuditUserSignIn                 AuditAction = "user_signin"
+	AuditUserSignInFailed           AuditAction = "user_signin_failed"
+	AuditUserTwoFactorEnable        AuditAction = "user_2fa_enable"
+	AuditUserTwoFactorDisable       AuditAction = "user_2fa_disable"
+	AuditUserTwoFactorRegenerate    AuditAction = "user_2fa_regenerate"
+	AuditUserSecurityKeyAdd         AuditAction = "user_security_key_add"
+	AuditUserSecurityKeyRemove      AuditAction = "user_security_key_remove"
+	AuditUserAccessTokenCreate      AuditAction = "user_access_token_create"
+	AuditUserAccessTokenDelete      AuditAction = "user_access_token_delete"
+	AuditAdminUserCreate            AuditAction = "admin_user_create"
+	AuditAdminUserEdit              AuditAction = "admin_user_edit"
+	AuditAdminUserDelete            AuditAction = "admin_user_delete"
+	AuditOrgDelete                  AuditAction = "org_delete"
+	AuditOrgRestore                 AuditAction = "org_restore"
+	AuditOrgPurge                   AuditAction = "org_purge"
+	AuditOrgTeamEdit                AuditAction = "org_team_edit"
+	AuditOrgTeamMemberAdd           AuditAction = "org_team_member_add"
+	AuditOrgTeamMemberRemove        AuditAction = "org_team_member_remove"
+	AuditRepoCollaboratorAdd        AuditAction = "repo_collaborator_add"
+	AuditRepoCollaboratorEdit       AuditAction = "repo_collaborator_edit"
+	AuditRepoCollaboratorRemove     AuditAction = "repo_collaborator_remove"
+	AuditRepoTeamAdd                AuditAction = "repo_team_add"
+	AuditRepoTeamRemove             AuditAction = "repo_team_remove"
+	AuditRepoBranchProtectionAdd    AuditAction = "repo_branch_protection_add"
+	AuditRepoBranchProtectionEdit   AuditAction = "repo_branch_protection_edit"
+	AuditRepoBranchProtectionRemove AuditAction = "repo_branch_protection_remove"
+	AuditRepoDelete                 AuditAction = "repo_delete"
+	AuditRepoRestore                AuditAction = "repo_restore"
+	AuditRepoPurge                  AuditAction = "repo_purge"
+	AuditRepoTransfer               AuditAction = "repo_transfer"
+)
+
+// AuditActions lists all audit actions in the order they are offered as filters
+var AuditActions = []AuditAction{
+	AuditUserSignIn,
+	AuditUserSignInFailed,
+	AuditUserTwoFactorEnable,
+	AuditUserTwoFactorDisable,
+	AuditUserTwoFactorRegenerate,
+	AuditUserSecurityKeyAdd,
+	AuditUserSecurityKeyRemove,
+	AuditUserAccessTokenCreate,
+	AuditUserAccessTokenDelete,
+	AuditAdminUserCreate,
+	AuditAdminUserEdit,
+	AuditAdminUserDelete,
+	AuditOrgDelete,
+	AuditOrgRestore,
+	AuditOrgPurge,
+	AuditOrgTeamEdit,
+	AuditOrgTeamMemberAdd,
+	AuditOrgTeamMemberRemove,
+	AuditRepoCollaboratorAdd,
+	AuditRepoCollaboratorEdit,
+	AuditRepoCollaboratorRemove,
+	AuditRepoTeamAdd,
+	AuditRepoTeamRemove,
+	AuditRepoBranchProtectionAdd,
+	AuditRepoBranchProtectionEdit,
+	AuditRepoBranchProtectionRemove,
+	AuditRepoDelete,
+	AuditRepoRestore,
+	AuditRepoPurge,
+	AuditRepoTransfer,
+}
+
+// IsValid returns whether the action is a known audit action
+func (a AuditAction) IsValid() bool {
+	for _, action := range AuditActions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// AuditEvent represents a security relevant action. Every event carries an HMAC, keyed
+// with the SECRET_KEY, over its content and the hash of the preceding event, so modified
+// or removed events break the chain and are detected by VerifyAuditEvents. The hash of
+// the preceding event is unique, so concurrent writers cannot fork the chain.
+type AuditEvent struct {
+	ID          int64       `xorm:"pk autoincr"`
+	Action      AuditAction `xorm:"VARCHAR(50) INDEX NOT NULL"`
+	DoerID      int64       `xorm:"INDEX"`
+	DoerName    string
+	OwnerID     int64 `xorm:"INDEX"` // user or organization the event belongs to
+	RepoID      int64 `xorm:"INDEX"`
+	Target      string
+	Description string             `xorm:"TEXT"`
+	IPAddress   string             `xorm:"VARCHAR(64)"`
+	UserAgent   string             `xorm:"TEXT"`
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL"`
+	PrevHash    string             `xorm:"VARCHAR(64) UNIQUE NOT NULL"`
+	Hash        string             `xorm:"VARCHAR(64) NOT NULL"`
+}
+
+func init() {
+	db.RegisterModel(new(AuditEvent))
+}
+
+// TrStr returns the translation key of the action
+func (e *AuditEvent) TrStr() string {
+	return "audit.action." + string(e.Action)
+}
+
+// computeHash returns the HMAC of the event chained to the hash of the preceding event
+func (e *AuditEvent) computeHash(prevHash string) string {
+	h := hmac.New(sha256.New, []byte(setting.SecretKey))
+	for _, field := range []string{
+		prevHash,
+		strconv.FormatInt(e.ID, 10),
+		string(e.Action),
+		strconv.FormatInt(e.DoerID, 10),
+		e.DoerName,
+		strconv.FormatInt(e.OwnerID, 10),
+		strconv.FormatInt(e.RepoID, 10),
+		e.Target,
+		e.Description,
+		e.IPAddress,
+		e.UserAgent,
+		strconv.FormatInt(int64(e.CreatedUnix), 10),
+	} {
+		_, _ = h.Write([]byte(field))
+		_, _ = h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// maxAuditEventRetries is how often CreateAuditEvent retries to link an event to the
+// chain when other events are appended concurrently
+const maxAuditEventRetries = 10
+
+// CreateAuditEvent stores a new audit event and links it to the hash chain
+func CreateAuditEvent(e *AuditEvent) error {
+	if e.CreatedUnix == 0 {
+		e.CreatedUnix = timeutil.TimeStampNow()
+	}
+
+	for i := 0; ; i++ {
+		prevHash, err := createAuditEvent(e)
+		if err == nil {
+			return nil
+		}
+
+		// The unique prev_hash refuses the event if another one has been linked to the
+		// same predecessor in the meantime, in which case the event is linked to that one.
+		e.ID = 0
+		if i == maxAuditEventRetries {
+			return err
+		}
+		if has, err2 := db.GetEngine(db.DefaultContext).Where("prev_hash = ?", prevHash).Exist(new(AuditEvent)); err2 != nil || !has {
+			return err
+		}
+	}
+}
+
+func createAuditEvent(e *AuditEvent) (string, error) {
+	sess := db.NewSession(db.DefaultContext)
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return "", err
+	}
+
+	prev := new(AuditEvent)
+	if _, err := sess.Desc("id").Get(prev); err != nil {
+		return "", err
+	}
+
+	e.PrevHash = prev.Hash
+	e.Hash = ""
+	if _, err := sess.Insert(e); err != nil {
+		return prev.Hash, err
+	}
+
+	// the ID is part of the hash, so it can only be computed after the insert
+	e.Hash = e.computeHash(prev.Hash)
+	if _, err := sess.ID(e.ID).Cols("hash").Update(e); err != nil {
+		return prev.Hash, err
+	}
+	return prev.Hash, sess.Commit()
+}
+
+// FindAuditEventsOptions represent the filters for audit events. If an ID is 0 it will be ignored.
+type FindAuditEventsOptions struct {
+	db.ListOptions
+	Actions    []AuditAction
+	DoerID     int64
+	OwnerID    int64
+	RepoID     int64
+	SinceUnix  int64
+	BeforeUnix int64
+}
+
+func (opts *FindAuditEventsOptions) toCond() builder.Cond {
+	cond := builder.NewCond()
+	if len(opts.Actions) > 0 {
+		cond = cond.And(builder.In("action", opts.Actions))
+	}
+	if opts.DoerID != 0 {
+		cond = cond.And(builder.Eq{"doer_id": opts.DoerID})
+	}
+	if opts.OwnerID != 0 {
+		cond = cond.And(builder.Eq{"owner_id": opts.OwnerID})
+	}
+	if opts.RepoID != 0 {
+		cond = cond.And(builder.Eq{"repo_id": opts.RepoID})
+	}
+	if opts.SinceUnix != 0 {
+		cond = cond.And(builder.Gte{"created_unix": opts.SinceUnix})
+	}
+	if opts.BeforeUnix != 0 {
+		cond = cond.And(builder.Lt{"created_unix": opts.BeforeUnix})
+	}
+	return cond
+}
+
+// FindAuditEvents returns the audit events matching the given options, newest first
+func FindAuditEvents(opts *FindAuditEventsOptions) ([]*AuditEvent, error) {
+	sess := db.GetEngine(db.DefaultContext).Where(opts.toCond()).Desc("id")
+	if opts.Page != 0 {
+		sess = db.SetSessionPagination(sess, opts)
+	}
+	events := make([]*AuditEvent, 0, opts.PageSize)
+	return events, sess.Find(&events)
+}
+
+// CountAuditEvents returns the number of audit events matching the given options
+func CountAuditEvents(opts *FindAuditEventsOptions) (int64, error) {
+	return db.GetEngine(db.DefaultContext).Where(opts.toCond()).Count(new(AuditEvent))
+}
+
+// VerifyAuditEvents walks the hash chain of all audit events and returns the ID of
+// the first event whose hash doesn't match its content, or 0 if the chain is intact.
+func VerifyAuditEvents() (int64, error) {
+	const batchSize = 100
+
+	var prevHash string
+	var lastID int64
+	for {
+		events := make([]*AuditEvent, 0, batchSize)
+		if err := db.GetEngine(db.DefaultContext).
+			Where("id > ?", lastID).
+			Asc("id").
+			Limit(batchSize).
+			Find(&events); err != nil {
+			return 0, err
+		}
+		for _, e := range events {
+			if e.Hash != e.computeHash(prevHash) {
+				return e.ID, nil
+			}
+			prevHash = e.Hash
+			lastID = e.ID
+		}
+		if len(events) < batchSize {
+			return 0, nil
+		}
+	}
+}
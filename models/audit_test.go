@@ -0,0 +1,173 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"sync"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/setting"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditEvent_TrStr(t *testing.T) {
+	e := &AuditEvent{Action: AuditRepoDelete}
+	assert.Equal(t, "audit.action.repo_delete", e.TrStr())
+}
+
+func TestCreateAuditEvent(t *testing.T) {
+	assert.NoError(t, db.PrepareTestDatabase())
+
+	first := &AuditEvent{Action: AuditUserSignIn, DoerID: 2, DoerName: "user2", OwnerID: 2, Target: "user2", IPAddress: "127.0.0.1"}
+	assert.NoError(t, CreateAuditEvent(first))
+	assert.NotZero(t, first.CreatedUnix)
+	assert.Len(t, first.Hash, 64)
+
+	second := &AuditEvent{Action: AuditRepoCollaboratorAdd, DoerID: 2, DoerName: "user2", OwnerID: 2, RepoID: 1, Target: "user4"}
+	assert.NoError(t, CreateAuditEvent(second))
+	assert.Equal(t, first.Hash, second.PrevHash)
+	assert.Equal(t, second.computeHash(first.Hash), second.Hash)
+
+	// the chain cannot be forked
+	_, err := db.GetEngine(db.DefaultContext).Insert(&AuditEvent{Action: AuditUserSignIn, PrevHash: first.Hash})
+	assert.Error(t, err)
+
+	e := db.AssertExistsAndLoadBean(t, &AuditEvent{ID: second.ID}).(*AuditEvent)
+	assert.Equal(t, second.Hash, e.Hash)
+
+	brokenID, err := VerifyAuditEvents()
+	assert.NoError(t, err)
+	assert.Zero(t, brokenID)
+}
+
+func TestCreateAuditEvent_Concurrent(t *testing.T) {
+	assert.NoError(t, db.PrepareTestDatabase())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, CreateAuditEvent(&AuditEvent{Action: AuditUserSignIn, Target: "user2"}))
+		}()
+	}
+	wg.Wait()
+
+	count, err := CountAuditEvents(&FindAuditEventsOptions{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 5, count)
+
+	brokenID, err := VerifyAuditEvents()
+	assert.NoError(t, err)
+	assert.Zero(t, brokenID)
+}
+
+func TestVerifyAuditEvents_SecretKey(t *testing.T) {
+	assert.NoError(t, db.PrepareTestDatabase())
+	defer func(secretKey string) {
+		setting.SecretKey = secretKey
+	}(setting.SecretKey)
+
+	setting.SecretKey = "first secret"
+	e := &AuditEvent{Action: AuditUserSignIn, Target: "user2"}
+	assert.NoError(t, CreateAuditEvent(e))
+
+	// the hashes cannot be recomputed without the secret
+	setting.SecretKey = "second secret"
+	brokenID, err := VerifyAuditEvents()
+	assert.NoError(t, err)
+	assert.Equal(t, e.ID, brokenID)
+}
+
+func TestVerifyAuditEvents(t *testing.T) {
+	assert.NoError(t, db.PrepareTestDatabase())
+
+	events := make([]*AuditEvent, 3)
+	for i := range events {
+		events[i] = &AuditEvent{Action: AuditUserSignInFailed, Target: "user2", Description: "wrong password"}
+		assert.NoError(t, CreateAuditEvent(events[i]))
+	}
+
+	// modify the content of an event without updating its hash
+	events[1].Description = "nothing happened"
+	_, err := db.GetEngine(db.DefaultContext).ID(events[1].ID).Cols("description").Update(events[1])
+	assert.NoError(t, err)
+
+	brokenID, err := VerifyAuditEvents()
+	assert.NoError(t, err)
+	assert.Equal(t, events[1].ID, brokenID)
+
+	// removing an event breaks the chain at its successor
+	assert.NoError(t, db.PrepareTestDatabase())
+	for i := range events {
+		events[i] = &AuditEvent{Action: AuditUserSignInFailed, Target: "user2"}
+		assert.NoError(t, CreateAuditEvent(events[i]))
+	}
+	_, err = db.GetEngine(db.DefaultContext).ID(events[1].ID).Delete(new(AuditEvent))
+	assert.NoError(t, err)
+
+	brokenID, err = VerifyAuditEvents()
+	assert.NoError(t, err)
+	assert.Equal(t, events[2].ID, brokenID)
+}
+
+func TestFindAuditEvents(t *testing.T) {
+	assert.NoError(t, db.PrepareTestDatabase())
+
+	for _, e := range []*AuditEvent{
+		{Action: AuditUserSignIn, DoerID: 2, OwnerID: 2, CreatedUnix: 100},
+		{Action: AuditRepoDelete, DoerID: 2, OwnerID: 3, RepoID: 5, CreatedUnix: 200},
+		{Action: AuditRepoCollaboratorAdd, DoerID: 1, OwnerID: 3, RepoID: 5, CreatedUnix: 300},
+		{Action: AuditOrgTeamEdit, DoerID: 1, OwnerID: 3, CreatedUnix: 400},
+	} {
+		assert.NoError(t, CreateAuditEvent(e))
+	}
+
+	kases := []struct {
+		opts    FindAuditEventsOptions
+		actions []AuditAction
+	}{
+		{
+			opts:    FindAuditEventsOptions{},
+			actions: []AuditAction{AuditOrgTeamEdit, AuditRepoCollaboratorAdd, AuditRepoDelete, AuditUserSignIn},
+		},
+		{
+			opts:    FindAuditEventsOptions{OwnerID: 3},
+			actions: []AuditAction{AuditOrgTeamEdit, AuditRepoCollaboratorAdd, AuditRepoDelete},
+		},
+		{
+			opts:    FindAuditEventsOptions{RepoID: 5, DoerID: 1},
+			actions: []AuditAction{AuditRepoCollaboratorAdd},
+		},
+		{
+			opts:    FindAuditEventsOptions{Actions: []AuditAction{AuditUserSignIn, AuditOrgTeamEdit}},
+			actions: []AuditAction{AuditOrgTeamEdit, AuditUserSignIn},
+		},
+		{
+			opts:    FindAuditEventsOptions{SinceUnix: 200, BeforeUnix: 400},
+			actions: []AuditAction{AuditRepoCollaboratorAdd, AuditRepoDelete},
+		},
+		{
+			opts:    FindAuditEventsOptions{ListOptions: db.ListOptions{Page: 2, PageSize: 3}},
+			actions: []AuditAction{AuditUserSignIn},
+		},
+	}
+	for _, kase := range kases {
+		events, err := FindAuditEvents(&kase.opts)
+		assert.NoError(t, err)
+		actions := make([]AuditAction, len(events))
+		for i, e := range events {
+			actions[i] = e.Action
+		}
+		assert.Equal(t, kase.actions, actions)
+
+		if kase.opts.Page == 0 {
+			count, err := CountAuditEvents(&kase.opts)
+			assert.NoError(t, err)
+			assert.EqualValues(t, len(kase.actions), count)
+		}
+	}
+}
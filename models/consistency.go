@@ -71,7 +71,9 @@ func assertCount(t *testing.T, bean interface{}, expected int) {
 }
 
 func (user *User) checkForConsistency(t *testing.T) {
-	assertCount(t, &Repository{OwnerID: user.ID}, user.NumRepos)
+	actual := getCount(t, db.GetEngine(db.DefaultContext).Where("deleted_unix=0"), &Repository{OwnerID: user.ID})
+	assert.EqualValues(t, user.NumRepos, actual,
+		"Unexpected number of repositories for user %+v", user)
 	assertCount(t, &Star{UID: user.ID}, user.NumStars)
 	assertCount(t, &OrgUser{OrgID: user.ID}, user.NumMembers)
 	assertCount(t, &Team{OrgID: user.ID}, user.NumTeams)
@@ -87,12 +89,14 @@ func (repo *Repository) checkForConsistency(t *testing.T) {
 	assert.Equal(t, repo.LowerName, strings.ToLower(repo.Name), "repo: %+v", repo)
 	assertCount(t, &Star{RepoID: repo.ID}, repo.NumStars)
 	assertCount(t, &Milestone{RepoID: repo.ID}, repo.NumMilestones)
-	assertCount(t, &Repository{ForkID: repo.ID}, repo.NumForks)
+	actual := getCount(t, db.GetEngine(db.DefaultContext).Where("deleted_unix=0"), &Repository{ForkID: repo.ID})
+	assert.EqualValues(t, repo.NumForks, actual,
+		"Unexpected number of forks for repo %+v", repo)
 	if repo.IsFork {
 		db.AssertExistsAndLoadBean(t, &Repository{ID: repo.ForkID})
 	}
 
-	actual := getCount(t, db.GetEngine(db.DefaultContext).Where("Mode<>?", RepoWatchModeDont), &Watch{RepoID: repo.ID})
+	actual = getCount(t, db.GetEngine(db.DefaultContext).Where("Mode<>?", RepoWatchModeDont), &Watch{RepoID: repo.ID})
 	assert.EqualValues(t, repo.NumWatches, actual,
 		"Unexpected number of watches for repo %+v", repo)
 
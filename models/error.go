@@ -683,6 +683,22 @@ func (err ErrOrgNotExist) Error() string {
 	return fmt.Sprintf("org does not exist [id: %d, name: %s]", err.ID, err.Name)
 }
 
+// ErrOrgInTrash is used to restore a repository of an organization that is in the trash
+type ErrOrgInTrash struct {
+	ID   int64
+	Name string
+}
+
+// IsErrOrgInTrash checks if an error is a ErrOrgInTrash.
+func IsErrOrgInTrash(err error) bool {
+	_, ok := err.(ErrOrgInTrash)
+	return ok
+}
+
+func (err ErrOrgInTrash) Error() string {
+	return fmt.Sprintf("org is in the trash [id: %d, name: %s]", err.ID, err.Name)
+}
+
 // ErrLastOrgOwner represents a "LastOrgOwner" kind of error.
 type ErrLastOrgOwner struct {
 	UID int64
@@ -867,6 +883,54 @@ func (err ErrRepoFilesAlreadyExist) Error() string {
 	return fmt.Sprintf("repository files already exist [uname: %s, name: %s]", err.Uname, err.Name)
 }
 
+// ErrTemplateVariableInvalid represents a "TemplateVariableInvalid" kind of error.
+type ErrTemplateVariableInvalid struct {
+	Name   string
+	Reason string
+}
+
+// IsErrTemplateVariableInvalid checks if an error is a ErrTemplateVariableInvalid.
+func IsErrTemplateVariableInvalid(err error) bool {
+	_, ok := err.(ErrTemplateVariableInvalid)
+	return ok
+}
+
+func (err ErrTemplateVariableInvalid) Error() string {
+	return fmt.Sprintf("template variable %s %s", err.Name, err.Reason)
+}
+
+// ErrTemplateSyncConflict represents a "TemplateSyncConflict" kind of error.
+type ErrTemplateSyncConflict struct {
+	RepoID   int64
+	CommitID string
+}
+
+// IsErrTemplateSyncConflict checks if an error is a ErrTemplateSyncConflict.
+func IsErrTemplateSyncConflict(err error) bool {
+	_, ok := err.(ErrTemplateSyncConflict)
+	return ok
+}
+
+func (err ErrTemplateSyncConflict) Error() string {
+	return fmt.Sprintf("template changes conflict with the repository [repo_id: %d, template_commit: %s]", err.RepoID, err.CommitID)
+}
+
+// ErrBulkRepoOptionInvalid represents a "BulkRepoOptionInvalid" kind of error.
+type ErrBulkRepoOptionInvalid struct {
+	Action string
+	Reason string
+}
+
+// IsErrBulkRepoOptionInvalid checks if an error is a ErrBulkRepoOptionInvalid.
+func IsErrBulkRepoOptionInvalid(err error) bool {
+	_, ok := err.(ErrBulkRepoOptionInvalid)
+	return ok
+}
+
+func (err ErrBulkRepoOptionInvalid) Error() string {
+	return fmt.Sprintf("invalid bulk repository operation [action: %s]: %s", err.Action, err.Reason)
+}
+
 // ErrForkAlreadyExist represents a "ForkAlreadyExist" kind of error.
 type ErrForkAlreadyExist struct {
 	Uname    string
@@ -1331,6 +1395,22 @@ func (err ErrWebhookNotExist) Error() string {
 	return fmt.Sprintf("webhook does not exist [id: %d]", err.ID)
 }
 
+// ErrHookTaskNotExist represents a "HookTaskNotExist" kind of error.
+type ErrHookTaskNotExist struct {
+	ID     int64
+	HookID int64
+}
+
+// IsErrHookTaskNotExist checks if an error is a ErrHookTaskNotExist.
+func IsErrHookTaskNotExist(err error) bool {
+	_, ok := err.(ErrHookTaskNotExist)
+	return ok
+}
+
+func (err ErrHookTaskNotExist) Error() string {
+	return fmt.Sprintf("hook task does not exist [id: %d, hook_id: %d]", err.ID, err.HookID)
+}
+
 // .___
 // |   | ______ ________ __   ____
 // |   |/  ___//  ___/  |  \_/ __ \
@@ -1435,6 +1515,21 @@ func (err ErrPullWasClosed) Error() string {
 	return fmt.Sprintf("Pull request [%d] %d was already closed", err.ID, err.Index)
 }
 
+// ErrPullHeadRepoInTrash is used to reopen a pull request whose head repository is in the trash
+type ErrPullHeadRepoInTrash struct {
+	ID int64
+}
+
+// IsErrPullHeadRepoInTrash checks if an error is a ErrPullHeadRepoInTrash.
+func IsErrPullHeadRepoInTrash(err error) bool {
+	_, ok := err.(ErrPullHeadRepoInTrash)
+	return ok
+}
+
+func (err ErrPullHeadRepoInTrash) Error() string {
+	return fmt.Sprintf("the head repository of pull request [%d] is in the trash", err.ID)
+}
+
 // ErrForbiddenIssueReaction is used when a forbidden reaction was try to created
 type ErrForbiddenIssueReaction struct {
 	Reaction string
@@ -1605,6 +1700,24 @@ func (err ErrRebaseConflicts) Error() string {
 	return fmt.Sprintf("Rebase Error: %v: Whilst Rebasing: %s\n%s\n%s", err.Err, err.CommitSHA, err.StdErr, err.StdOut)
 }
 
+// ErrForkBranchDiverged represents an error if a branch of a fork cannot be fast-forwarded
+// to its upstream branch because it has commits of its own
+type ErrForkBranchDiverged struct {
+	RepoID int64
+	Branch string
+	Ahead  int
+}
+
+// IsErrForkBranchDiverged checks if an error is a ErrForkBranchDiverged.
+func IsErrForkBranchDiverged(err error) bool {
+	_, ok := err.(ErrForkBranchDiverged)
+	return ok
+}
+
+func (err ErrForkBranchDiverged) Error() string {
+	return fmt.Sprintf("fork branch has diverged from upstream [repo_id: %d, branch: %s, ahead: %d]", err.RepoID, err.Branch, err.Ahead)
+}
+
 // ErrPullRequestHasMerged represents a "PullRequestHasMerged"-error
 type ErrPullRequestHasMerged struct {
 	ID         int64
@@ -0,0 +1,122 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+)
+
+// Foreign reference types of the issues and pull requests of migrated repositories.
+// Issues imported from exports use a type naming the export, e.g. "jira:PROJ".
+const (
+	ForeignTypeIssues       = "issues"
+	ForeignTypePullRequests = "pull_requests"
+)
+
+// ForeignReference maps an issue or pull request migrated from another
+// service to its local index, so that later syncs update it in place.
+type ForeignReference struct {
+	ID           int64  `xorm:"pk autoincr"`
+	RepoID       int64  `xorm:"UNIQUE(repo_foreign_type) INDEX(repo_local)"`
+	LocalIndex   int64  `xorm:"INDEX(repo_local)"`
+	ForeignIndex int64  `xorm:"UNIQUE(repo_foreign_type)"`
+	Type         string `xorm:"VARCHAR(255) UNIQUE(repo_foreign_type)"`
+}
+
+func init() {
+	db.RegisterModel(new(ForeignReference))
+}
+
+// ErrForeignReferenceNotExist represents a "ForeignReferenceNotExist" kind of error.
+type ErrForeignReferenceNotExist struct {
+	RepoID       int64
+	ForeignIndex int64
+	Type         string
+}
+
+// IsErrForeignReferenceNotExist checks if an error is a ErrForeignReferenceNotExist.
+func IsErrForeignReferenceNotExist(err error) bool {
+	_, ok := err.(ErrForeignReferenceNotExist)
+	return ok
+}
+
+func (err ErrForeignReferenceNotExist) Error() string {
+	return fmt.Sprintf("foreign reference does not exist [repo_id: %d, foreign_index: %d, type: %s]", err.RepoID, err.ForeignIndex, err.Type)
+}
+
+// GetForeignReference returns the foreign reference of the given type pointing to foreignIndex in the repository
+func GetForeignReference(repoID, foreignIndex int64, tp string) (*ForeignReference, error) {
+	ref := &ForeignReference{
+		RepoID:       repoID,
+		ForeignIndex: foreignIndex,
+		Type:         tp,
+	}
+	has, err := db.GetEngine(db.DefaultContext).Get(ref)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrForeignReferenceNotExist{repoID, foreignIndex, tp}
+	}
+	return ref, nil
+}
+
+// InsertForeignReferences inserts the given foreign references
+func InsertForeignReferences(refs ...*ForeignReference) error {
+	if len(refs) == 0 {
+		return nil
+	}
+	_, err := db.GetEngine(db.DefaultContext).Insert(refs)
+	return err
+}
+
+// InitForeignReferences records foreign references for a repository migrated before they existed.
+// Such repositories kept the indexes of the original service, so each issue and pull request
+// references the foreign index equal to its own.
+func InitForeignReferences(repoID int64) error {
+	sess := db.NewSession(db.DefaultContext)
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if has, err := sess.Where("repo_id = ?", repoID).
+		In("type", ForeignTypeIssues, ForeignTypePullRequests).
+		Exist(new(ForeignReference)); err != nil || has {
+		return err
+	}
+
+	issues := make([]*Issue, 0, 10)
+	if err := sess.Cols("`index`", "is_pull").Where("repo_id=?", repoID).Find(&issues); err != nil {
+		return err
+	}
+
+	refs := make([]*ForeignReference, 0, len(issues))
+	for _, issue := range issues {
+		tp := ForeignTypeIssues
+		if issue.IsPull {
+			tp = ForeignTypePullRequests
+		}
+		refs = append(refs, &ForeignReference{
+			RepoID:       repoID,
+			LocalIndex:   issue.Index,
+			ForeignIndex: issue.Index,
+			Type:         tp,
+		})
+	}
+
+	batchSize := db.MaxBatchInsertSize(new(ForeignReference))
+	for len(refs) > 0 {
+		if len(refs) < batchSize {
+			batchSize = len(refs)
+		}
+		if _, err := sess.Insert(refs[:batchSize]); err != nil {
+			return err
+		}
+		refs = refs[batchSize:]
+	}
+	return sess.Commit()
+}
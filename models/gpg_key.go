@@ -99,6 +99,43 @@ func GetGPGKeysByKeyID(keyID string) ([]*GPGKey, error) {
 	return keys, db.GetEngine(db.DefaultContext).Where("key_id=?", keyID).Find(&keys)
 }
 
+// ListGPGEncryptionKeys returns the unexpired keys of the user which are able to encrypt messages
+// and belong to the given activated email address
+func ListGPGEncryptionKeys(uid int64, email string) ([]*GPGKey, error) {
+	keys, err := listGPGKeys(db.GetEngine(db.DefaultContext), uid, db.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	now := timeutil.TimeStampNow()
+	canEncrypt := func(key *GPGKey) bool {
+		return key.CanEncryptComms && (key.ExpiredUnix == 0 || key.ExpiredUnix > now)
+	}
+
+	encryptionKeys := make([]*GPGKey, 0, len(keys))
+	for _, key := range keys {
+		if key.ExpiredUnix != 0 && key.ExpiredUnix <= now {
+			continue
+		}
+
+		matches := false
+		for _, e := range key.Emails {
+			matches = matches || e.IsActivated && strings.EqualFold(e.Email, email)
+		}
+		if !matches {
+			continue
+		}
+		usable := canEncrypt(key)
+		for _, subKey := range key.SubsKey {
+			usable = usable || canEncrypt(subKey)
+		}
+		if usable {
+			encryptionKeys = append(encryptionKeys, key)
+		}
+	}
+	return encryptionKeys, nil
+}
+
 // GPGKeyToEntity retrieve the imported key and the traducted entity
 func GPGKeyToEntity(k *GPGKey) (*openpgp.Entity, error) {
 	impKey, err := GetGPGImportByKeyID(k.KeyID)
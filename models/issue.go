@@ -608,6 +608,16 @@ func (issue *Issue) changeStatus(e db.Engine, doer *User, isClosed, isMergePull
 }
 
 func (issue *Issue) doChangeStatus(e db.Engine, doer *User, isMergePull bool) (*Comment, error) {
+	// a pull request from a repository in the trash stays closed until the repository is restored
+	if !issue.IsClosed && issue.IsPull {
+		inTrash, err := isPullHeadRepoInTrash(e, issue.ID)
+		if err != nil {
+			return nil, err
+		} else if inTrash {
+			return nil, ErrPullHeadRepoInTrash{issue.ID}
+		}
+	}
+
 	// Check for open dependencies
 	if issue.IsClosed && issue.Repo.isDependenciesEnabled(e) {
 		// only check if dependencies are enabled and we're about to close an issue, otherwise reopening an issue would fail when there are unsatisfied dependencies
@@ -87,6 +87,9 @@ type FindTrackedTimesOptions struct {
 // toCond will convert each condition into a xorm-Cond
 func (opts *FindTrackedTimesOptions) toCond() builder.Cond {
 	cond := builder.NewCond().And(builder.Eq{"tracked_time.deleted": false})
+	// the times of issues in repositories in the trash are hidden with them
+	cond = cond.And(builder.NotIn("tracked_time.issue_id", builder.Select("id").From("issue").
+		Where(builder.In("repo_id", builder.Select("id").From("repository").Where(builder.Neq{"deleted_unix": 0})))))
 	if opts.IssueID != 0 {
 		cond = cond.And(builder.Eq{"issue_id": opts.IssueID})
 	}
@@ -199,3 +199,30 @@ func IterateLFS(f func(mo *LFSMetaObject) error) error {
 		}
 	}
 }
+
+// IterateLFSMetaObjectsForRepo iterates the LFSMetaObjects of a repository which were created before olderThan.
+// The objects are fetched in batches ordered by id so f may remove the object it is called with.
+func IterateLFSMetaObjectsForRepo(repoID int64, olderThan timeutil.TimeStamp, f func(mo *LFSMetaObject) error) error {
+	var lastID int64
+	const batchSize = 100
+	for {
+		mos := make([]*LFSMetaObject, 0, batchSize)
+		if err := db.GetEngine(db.DefaultContext).
+			Where("repository_id = ? AND id > ? AND created_unix < ?", repoID, lastID, olderThan).
+			Asc("id").
+			Limit(batchSize).
+			Find(&mos); err != nil {
+			return err
+		}
+		if len(mos) == 0 {
+			return nil
+		}
+		lastID = mos[len(mos)-1].ID
+
+		for _, mo := range mos {
+			if err := f(mo); err != nil {
+				return err
+			}
+		}
+	}
+}
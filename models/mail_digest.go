@@ -0,0 +1,63 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// MailDigestEntry is a notification buffered for the next email digest of a user
+type MailDigestEntry struct {
+	ID          int64 `xorm:"pk autoincr"`
+	UserID      int64 `xorm:"INDEX NOT NULL"`
+	RepoID      int64 `xorm:"NOT NULL"`
+	IssueID     int64 `xorm:"NOT NULL"`
+	CommentID   int64
+	DoerID      int64
+	ActionType  ActionType
+	Content     string             `xorm:"TEXT"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created INDEX"`
+}
+
+func init() {
+	db.RegisterModel(new(MailDigestEntry))
+}
+
+// AddMailDigestEntry buffers a notification for the next digest of the user
+func AddMailDigestEntry(entry *MailDigestEntry) error {
+	_, err := db.GetEngine(db.DefaultContext).Insert(entry)
+	return err
+}
+
+// GetMailDigestEntries returns the buffered notifications of the user in order of creation
+func GetMailDigestEntries(userID int64) ([]*MailDigestEntry, error) {
+	entries := make([]*MailDigestEntry, 0, 10)
+	return entries, db.GetEngine(db.DefaultContext).
+		Where("user_id = ?", userID).
+		Asc("id").
+		Find(&entries)
+}
+
+// DeleteMailDigestEntries removes the buffered notifications of the user up to and including maxID
+func DeleteMailDigestEntries(userID, maxID int64) error {
+	_, err := db.GetEngine(db.DefaultContext).
+		Where("user_id = ? AND id <= ?", userID, maxID).
+		Delete(new(MailDigestEntry))
+	return err
+}
+
+// GetMailDigestUserIDs returns the users with buffered notifications who chose one of the digest preferences
+func GetMailDigestUserIDs(preferences ...string) ([]int64, error) {
+	userIDs := make([]int64, 0, 10)
+	return userIDs, db.GetEngine(db.DefaultContext).
+		Table("mail_digest_entry").
+		Distinct("mail_digest_entry.user_id").
+		Join("INNER", "`user`", "`user`.id = mail_digest_entry.user_id").
+		Where(builder.In("`user`.email_notifications_digest", preferences)).
+		Find(&userIDs)
+}
@@ -220,6 +220,111 @@ func InsertReleases(rels ...*Release) error {
 	return sess.Commit()
 }
 
+// UpdateMigratedIssues updates issues synced again from their original service
+// and keeps the issue counters of the repository, labels and milestones in step.
+func UpdateMigratedIssues(issues ...*Issue) error {
+	sess := db.NewSession(db.DefaultContext)
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	for _, issue := range issues {
+		if err := updateMigratedIssue(sess, issue); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
+
+func updateMigratedIssue(sess *xorm.Session, issue *Issue) error {
+	var oldMilestoneID int64
+	if _, err := sess.Table("issue").Select("milestone_id").Where("id=?", issue.ID).Get(&oldMilestoneID); err != nil {
+		return err
+	}
+	labelIDs := make([]int64, 0, len(issue.Labels))
+	if err := sess.Table("issue_label").Select("label_id").Where("issue_id=?", issue.ID).Find(&labelIDs); err != nil {
+		return err
+	}
+
+	if _, err := sess.ID(issue.ID).NoAutoTime().
+		Cols("name", "content", "ref", "is_closed", "is_locked", "milestone_id", "closed_unix", "updated_unix").
+		Update(issue); err != nil {
+		return err
+	}
+
+	if _, err := sess.Delete(&IssueLabel{IssueID: issue.ID}); err != nil {
+		return err
+	}
+	issueLabels := make([]IssueLabel, 0, len(issue.Labels))
+	for _, label := range issue.Labels {
+		issueLabels = append(issueLabels, IssueLabel{
+			IssueID: issue.ID,
+			LabelID: label.ID,
+		})
+		labelIDs = append(labelIDs, label.ID)
+	}
+	if len(issueLabels) > 0 {
+		if _, err := sess.Insert(issueLabels); err != nil {
+			return err
+		}
+	}
+
+	if err := issue.updateClosedNum(sess); err != nil {
+		return err
+	}
+	for _, labelID := range labelIDs {
+		if err := updateLabelCols(sess, &Label{ID: labelID}, "num_issues", "num_closed_issues"); err != nil {
+			return err
+		}
+	}
+	for _, milestoneID := range []int64{oldMilestoneID, issue.MilestoneID} {
+		if milestoneID > 0 {
+			if err := updateMilestoneCounters(sess, milestoneID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// UpdateMigratedPullRequests updates pull requests synced again from their original service
+func UpdateMigratedPullRequests(prs ...*PullRequest) error {
+	sess := db.NewSession(db.DefaultContext)
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	for _, pr := range prs {
+		if err := updateMigratedIssue(sess, pr.Issue); err != nil {
+			return err
+		}
+		if _, err := sess.ID(pr.ID).NoAutoTime().
+			Cols("base_branch", "merge_base", "has_merged", "merged_unix", "merged_commit_id", "merger_id").
+			Update(pr); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
+
+// UpdateMigratedComments updates the content of comments synced again from their original service
+func UpdateMigratedComments(comments ...*Comment) error {
+	sess := db.NewSession(db.DefaultContext)
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	for _, comment := range comments {
+		if _, err := sess.ID(comment.ID).NoAutoTime().Cols("content", "updated_unix").Update(comment); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
+
 func migratedIssueCond(tp structs.GitServiceType) builder.Cond {
 	return builder.In("issue_id",
 		builder.Select("issue.id").
@@ -356,6 +356,26 @@ var migrations = []Migration{
 	NewMigration("Add table app_state", addTableAppState),
 	// v201 -> v202
 	NewMigration("Drop table remote_version (if exists)", dropTableRemoteVersion),
+	// v202 -> v203
+	NewMigration("Add email notification digests", addMailDigest),
+	// v203 -> v204
+	NewMigration("Add encrypt_email_notifications to user", addEncryptEmailNotificationsToUser),
+	// v204 -> v205
+	NewMigration("Add webhook delivery retries", addWebhookDeliveryRetries),
+	// v205 -> v206
+	NewMigration("Add audit event table", addAuditEventTable),
+	// v206 -> v207
+	NewMigration("Add repository shard table", addRepoShardTable),
+	// v207 -> v208
+	NewMigration("Add foreign reference table and mirror metadata sync", addForeignReferenceAndMirrorSyncMetadata),
+	// v208 -> v209
+	NewMigration("Add push mirror filters, sync on commit, SSH keys and sync history", addPushMirrorFiltersAndSyncHistory),
+	// v209 -> v210
+	NewMigration("Add mirror sync status and webhook secret", addMirrorSyncStatusAndWebhookSecret),
+	// v210 -> v211
+	NewMigration("Add table to record how repositories were generated from templates", addRepoGenerationTable),
+	// v211 -> v212
+	NewMigration("Add columns to keep deleted repositories and organizations in the trash", addTrashColumns),
 }
 
 // GetCurrentDBVersion returns the current db version
@@ -0,0 +1,37 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addMailDigest(x *xorm.Engine) error {
+	type User struct {
+		EmailNotificationsDigest     string `xorm:"VARCHAR(20) NOT NULL DEFAULT 'immediate'"`
+		EmailDigestImmediateMentions bool   `xorm:"NOT NULL DEFAULT true"`
+	}
+
+	type MailDigestEntry struct {
+		ID          int64 `xorm:"pk autoincr"`
+		UserID      int64 `xorm:"INDEX NOT NULL"`
+		RepoID      int64 `xorm:"NOT NULL"`
+		IssueID     int64 `xorm:"NOT NULL"`
+		CommentID   int64
+		DoerID      int64
+		ActionType  int
+		Content     string             `xorm:"TEXT"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created INDEX"`
+	}
+
+	if err := x.Sync2(new(User)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return x.Sync2(new(MailDigestEntry))
+}
@@ -0,0 +1,27 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addWebhookDeliveryRetries(x *xorm.Engine) error {
+	type Webhook struct {
+		FailureCount int `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	type HookTask struct {
+		Attempts      int                `xorm:"NOT NULL DEFAULT 0"`
+		NextRetryUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(Webhook)); err != nil {
+		return err
+	}
+	return x.Sync2(new(HookTask))
+}
@@ -0,0 +1,31 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addAuditEventTable(x *xorm.Engine) error {
+	type AuditEvent struct {
+		ID          int64  `xorm:"pk autoincr"`
+		Action      string `xorm:"VARCHAR(50) INDEX NOT NULL"`
+		DoerID      int64  `xorm:"INDEX"`
+		DoerName    string
+		OwnerID     int64 `xorm:"INDEX"`
+		RepoID      int64 `xorm:"INDEX"`
+		Target      string
+		Description string             `xorm:"TEXT"`
+		IPAddress   string             `xorm:"VARCHAR(64)"`
+		UserAgent   string             `xorm:"TEXT"`
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL"`
+		PrevHash    string             `xorm:"VARCHAR(64) UNIQUE NOT NULL"`
+		Hash        string             `xorm:"VARCHAR(64) NOT NULL"`
+	}
+
+	return x.Sync2(new(AuditEvent))
+}
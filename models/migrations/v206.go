@@ -0,0 +1,22 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addRepoShardTable(x *xorm.Engine) error {
+	type RepoShard struct {
+		ID          int64              `xorm:"pk autoincr"`
+		OwnerName   string             `xorm:"UNIQUE NOT NULL"`
+		Shard       string             `xorm:"INDEX NOT NULL"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	return x.Sync2(new(RepoShard))
+}
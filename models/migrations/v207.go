@@ -0,0 +1,33 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addForeignReferenceAndMirrorSyncMetadata(x *xorm.Engine) error {
+	type ForeignReference struct {
+		ID           int64  `xorm:"pk autoincr"`
+		RepoID       int64  `xorm:"UNIQUE(repo_foreign_type) INDEX(repo_local)"`
+		LocalIndex   int64  `xorm:"INDEX(repo_local)"`
+		ForeignIndex int64  `xorm:"UNIQUE(repo_foreign_type)"`
+		Type         string `xorm:"VARCHAR(255) UNIQUE(repo_foreign_type)"`
+	}
+
+	type Mirror struct {
+		SyncMetadata       bool               `xorm:"NOT NULL DEFAULT false"`
+		MetadataSyncedUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(ForeignReference)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return x.Sync2(new(Mirror))
+}
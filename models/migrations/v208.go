@@ -0,0 +1,66 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addPushMirrorFiltersAndSyncHistory(x *xorm.Engine) error {
+	type PushMirror struct {
+		ID             int64              `xorm:"pk autoincr"`
+		BranchFilter   string             `xorm:"TEXT"`
+		TagFilter      string             `xorm:"TEXT"`
+		SyncOnCommit   bool               `xorm:"NOT NULL DEFAULT false"`
+		PublicKey      string             `xorm:"TEXT"`
+		PrivateKey     string             `xorm:"TEXT"`
+		LastUpdateUnix timeutil.TimeStamp `xorm:"INDEX last_update"`
+		LastError      string             `xorm:"text"`
+	}
+
+	type PushMirrorSync struct {
+		ID           int64  `xorm:"pk autoincr"`
+		PushMirrorID int64  `xorm:"INDEX"`
+		Trigger      int    `xorm:"NOT NULL DEFAULT 0"`
+		Error        string `xorm:"TEXT"`
+		StartedUnix  timeutil.TimeStamp
+		FinishedUnix timeutil.TimeStamp
+	}
+
+	if err := x.Sync2(new(PushMirror), new(PushMirrorSync)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	// keep the last sync of every mirror as the start of its history
+	mirrors := make([]*PushMirror, 0, 10)
+	if err := sess.Where("last_update > 0").Find(&mirrors); err != nil {
+		return err
+	}
+	for _, m := range mirrors {
+		if _, err := sess.Insert(&PushMirrorSync{
+			PushMirrorID: m.ID,
+			Error:        m.LastError,
+			StartedUnix:  m.LastUpdateUnix,
+			FinishedUnix: m.LastUpdateUnix,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := dropTableColumns(sess, "push_mirror", "last_error"); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
@@ -0,0 +1,32 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"time"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addMirrorSyncStatusAndWebhookSecret(x *xorm.Engine) error {
+	type MirrorSyncRef struct {
+		RefName     string
+		OldCommitID string
+		NewCommitID string
+	}
+
+	type Mirror struct {
+		LastSyncUnix     timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+		LastSyncDuration time.Duration      `xorm:"NOT NULL DEFAULT 0"`
+		LastError        string             `xorm:"TEXT"`
+		LastSyncRefs     []*MirrorSyncRef   `xorm:"TEXT JSON"`
+		NumLastSyncRefs  int                `xorm:"NOT NULL DEFAULT 0"`
+		WebhookSecret    string             `xorm:"TEXT"`
+	}
+
+	return x.Sync2(new(Mirror))
+}
@@ -0,0 +1,32 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addRepoGenerationTable(x *xorm.Engine) error {
+	type TemplateVariableValue struct {
+		Name  string
+		Value string
+	}
+
+	type RepoGeneration struct {
+		ID               int64                    `xorm:"pk autoincr"`
+		RepoID           int64                    `xorm:"UNIQUE"`
+		TemplateID       int64                    `xorm:"INDEX"`
+		TemplateCommitID string                   `xorm:"VARCHAR(40)"`
+		Variables        []*TemplateVariableValue `xorm:"TEXT JSON"`
+		SyncCommitID     string                   `xorm:"VARCHAR(40)"`
+		SyncPullID       int64
+		CreatedUnix      timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix      timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	return x.Sync2(new(RepoGeneration))
+}
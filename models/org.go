@@ -175,6 +175,9 @@ func CreateOrganization(org, owner *User) (err error) {
 	if _, err = sess.Insert(org); err != nil {
 		return fmt.Errorf("insert organization: %v", err)
 	}
+	if err = newOwnerRepoShard(sess, org.Name); err != nil {
+		return fmt.Errorf("newOwnerRepoShard: %v", err)
+	}
 	if err = org.generateRandomAvatar(sess); err != nil {
 		return fmt.Errorf("generate random avatar: %v", err)
 	}
@@ -238,7 +241,7 @@ func GetOrgByName(name string) (*User, error) {
 		LowerName: strings.ToLower(name),
 		Type:      UserTypeOrganization,
 	}
-	has, err := db.GetEngine(db.DefaultContext).Get(u)
+	has, err := db.GetEngine(db.DefaultContext).Where("deleted_unix = 0").Get(u)
 	if err != nil {
 		return nil, err
 	} else if !has {
@@ -251,6 +254,7 @@ func GetOrgByName(name string) (*User, error) {
 func CountOrganizations() int64 {
 	count, _ := db.GetEngine(db.DefaultContext).
 		Where("type=1").
+		And("deleted_unix = 0").
 		Count(new(User))
 	return count
 }
@@ -304,11 +308,17 @@ func deleteOrg(e *xorm.Session, u *User) error {
 	// FIXME: system notice
 	// Note: There are something just cannot be roll back,
 	//	so just keep error logs of those operations.
-	path := UserPath(u.Name)
+	path, err := userPath(e, u.Name)
+	if err != nil {
+		return err
+	}
 
 	if err := util.RemoveAll(path); err != nil {
 		return fmt.Errorf("Failed to RemoveAll %s: %v", path, err)
 	}
+	if err := deleteOwnerRepoShard(e, u.Name); err != nil {
+		return fmt.Errorf("deleteOwnerRepoShard: %v", err)
+	}
 
 	if len(u.Avatar) > 0 {
 		avatarPath := u.CustomAvatarRelativePath()
@@ -426,6 +436,7 @@ func getOrgsByUserID(sess *xorm.Session, userID int64, showAll bool) ([]*User, e
 	}
 	return orgs, sess.
 		And("`org_user`.uid=?", userID).
+		And("`user`.deleted_unix = 0").
 		Join("INNER", "`org_user`", "`org_user`.org_id=`user`.id").
 		Asc("`user`.name").
 		Find(&orgs)
@@ -478,6 +489,7 @@ func GetUserOrgsList(user *User) ([]*MinimalOrg, error) {
 			From("repository").
 			Where(accessibleRepositoryCondition(user)), "`repository`.repo_owner_id = `team`.org_id").
 		Where("`team_user`.uid = ?", user.ID).
+		And("`user`.deleted_unix = 0").
 		GroupBy(groupByStr)
 
 	type OrgCount struct {
@@ -509,6 +521,7 @@ func getOwnedOrgsByUserID(sess *xorm.Session, userID int64) ([]*User, error) {
 		Join("INNER", "`team`", "`team`.id=`team_user`.team_id").
 		Where("`team_user`.uid=?", userID).
 		And("`team`.authorize=?", AccessModeOwner).
+		And("`user`.deleted_unix = 0").
 		Asc("`user`.name").
 		Find(&orgs)
 }
@@ -569,7 +582,7 @@ func GetOrgsCanCreateRepoByUserID(userID int64) ([]*User, error) {
 	return orgs, db.GetEngine(db.DefaultContext).Where(builder.In("id", builder.Select("`user`.id").From("`user`").
 		Join("INNER", "`team_user`", "`team_user`.org_id = `user`.id").
 		Join("INNER", "`team`", "`team`.id = `team_user`.team_id").
-		Where(builder.Eq{"`team_user`.uid": userID}).
+		Where(builder.Eq{"`team_user`.uid": userID, "`user`.deleted_unix": 0}).
 		And(builder.Eq{"`team`.authorize": AccessModeOwner}.Or(builder.Eq{"`team`.can_create_org_repo": true})))).
 		Asc("`user`.name").
 		Find(&orgs)
@@ -580,7 +593,8 @@ func GetOrgUsersByUserID(uid int64, opts *SearchOrganizationsOptions) ([]*OrgUse
 	ous := make([]*OrgUser, 0, 10)
 	sess := db.GetEngine(db.DefaultContext).
 		Join("LEFT", "`user`", "`org_user`.org_id=`user`.id").
-		Where("`org_user`.uid=?", uid)
+		Where("`org_user`.uid=?", uid).
+		And("`user`.deleted_unix = 0")
 	if !opts.All {
 		// Only show public organizations
 		sess.And("is_public=?", true)
@@ -907,7 +921,7 @@ func (env *accessibleReposEnv) cond() builder.Cond {
 	if env.keyword != "" {
 		cond = cond.And(builder.Like{"`repository`.lower_name", strings.ToLower(env.keyword)})
 	}
-	return cond
+	return builder.And(builder.Eq{"`repository`.deleted_unix": 0}, cond)
 }
 
 func (env *accessibleReposEnv) CountRepos() (int64, error) {
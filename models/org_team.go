@@ -166,6 +166,7 @@ func (t *Team) getRepositories(e db.Engine) error {
 	}
 	return e.Join("INNER", "team_repo", "repository.id = team_repo.repo_id").
 		Where("team_repo.team_id=?", t.ID).
+		And("repository.deleted_unix = 0").
 		OrderBy("repository.name").
 		Find(&t.Repos)
 }
@@ -0,0 +1,156 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// IsDeleted returns true if the organization is in the trash
+func (u *User) IsDeleted() bool {
+	return u.DeletedUnix != 0
+}
+
+// PurgeUnix returns when an organization in the trash is deleted permanently
+func (u *User) PurgeUnix() timeutil.TimeStamp {
+	return u.DeletedUnix.AddDuration(setting.Repository.TrashPeriod)
+}
+
+// TrashOrganization moves an organization to the trash. It is hidden like a deleted
+// organization, but its name stays taken and it can be restored with its teams and
+// members until it is deleted permanently. All repositories of the organization must
+// have been deleted or moved to the trash before. Pending transfers of repositories
+// to the organization are cancelled.
+func TrashOrganization(doer, org *User) error {
+	sess := db.NewSession(db.DefaultContext)
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if err := checkOrgReposInTrash(sess, org); err != nil {
+		return err
+	}
+
+	org.DeletedUnix = timeutil.TimeStampNow()
+	org.DeletedByID = doer.ID
+	if cnt, err := sess.ID(org.ID).Where("type = ?", UserTypeOrganization).And("deleted_unix = 0").
+		Cols("deleted_unix", "deleted_by_id").NoAutoTime().Update(org); err != nil {
+		return err
+	} else if cnt != 1 {
+		return ErrOrgNotExist{org.ID, org.Name}
+	}
+
+	transfers := make([]*RepoTransfer, 0, 5)
+	if err := sess.Where("recipient_id = ?", org.ID).Find(&transfers); err != nil {
+		return err
+	}
+	for _, transfer := range transfers {
+		if _, err := sess.ID(transfer.RepoID).Cols("status").NoAutoTime().Update(&Repository{Status: RepositoryReady}); err != nil {
+			return err
+		}
+		if err := deleteRepositoryTransfer(sess, transfer.RepoID); err != nil {
+			return err
+		}
+	}
+
+	return sess.Commit()
+}
+
+func checkOrgReposInTrash(e db.Engine, org *User) error {
+	if count, err := e.Where("owner_id = ?", org.ID).And("deleted_unix = 0").Count(new(Repository)); err != nil {
+		return err
+	} else if count > 0 {
+		return ErrUserOwnRepos{UID: org.ID}
+	}
+	return nil
+}
+
+// CheckOrgReposInTrash returns ErrUserOwnRepos unless all repositories of the organization are in the trash
+func CheckOrgReposInTrash(org *User) error {
+	return checkOrgReposInTrash(db.GetEngine(db.DefaultContext), org)
+}
+
+// RestoreOrganization takes an organization out of the trash
+func RestoreOrganization(org *User) error {
+	org.DeletedUnix = 0
+	org.DeletedByID = 0
+	if cnt, err := db.GetEngine(db.DefaultContext).ID(org.ID).Where("type = ?", UserTypeOrganization).And("deleted_unix <> 0").
+		Cols("deleted_unix", "deleted_by_id").NoAutoTime().Update(org); err != nil {
+		return err
+	} else if cnt != 1 {
+		return ErrOrgNotExist{org.ID, org.Name}
+	}
+	return nil
+}
+
+// GetDeletedOrgByName returns the organization in the trash with the given name
+func GetDeletedOrgByName(name string) (*User, error) {
+	org := new(User)
+	has, err := db.GetEngine(db.DefaultContext).
+		Where("lower_name = ?", strings.ToLower(name)).
+		And("type = ?", UserTypeOrganization).
+		And("deleted_unix <> 0").
+		Get(org)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrOrgNotExist{0, name}
+	}
+	return org, nil
+}
+
+// GetDeletedOrgByID returns the organization in the trash with the given id
+func GetDeletedOrgByID(id int64) (*User, error) {
+	org := new(User)
+	has, err := db.GetEngine(db.DefaultContext).ID(id).
+		Where("type = ?", UserTypeOrganization).
+		And("deleted_unix <> 0").
+		Get(org)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrOrgNotExist{id, ""}
+	}
+	return org, nil
+}
+
+// FindDeletedOrgsOptions represents the options to find organizations in the trash
+type FindDeletedOrgsOptions struct {
+	db.ListOptions
+	// OwnerID restricts the organizations to those owned by this user
+	OwnerID       int64
+	DeletedBefore timeutil.TimeStamp
+}
+
+func (opts *FindDeletedOrgsOptions) toCond() builder.Cond {
+	cond := builder.Eq{"type": UserTypeOrganization}.And(builder.Neq{"deleted_unix": 0})
+	if opts.OwnerID != 0 {
+		cond = cond.And(builder.In("id", builder.Select("team_user.org_id").From("team_user").
+			InnerJoin("team", "team.id = team_user.team_id").
+			Where(builder.Eq{"team_user.uid": opts.OwnerID, "team.authorize": AccessModeOwner})))
+	}
+	if opts.DeletedBefore != 0 {
+		cond = cond.And(builder.Lt{"deleted_unix": opts.DeletedBefore})
+	}
+	return cond
+}
+
+// FindDeletedOrgs returns the organizations in the trash, the most recently deleted first
+func FindDeletedOrgs(opts *FindDeletedOrgsOptions) ([]*User, int64, error) {
+	sess := db.GetEngine(db.DefaultContext).Where(opts.toCond()).Desc("deleted_unix")
+	if opts.Page != 0 {
+		sess = db.SetSessionPagination(sess, opts)
+	}
+	orgs := make([]*User, 0, opts.PageSize)
+	count, err := sess.FindAndCount(&orgs)
+	return orgs, count, err
+}
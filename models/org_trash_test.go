@@ -0,0 +1,91 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrashAndRestoreOrganization(t *testing.T) {
+	assert.NoError(t, db.PrepareTestDatabase())
+
+	doer := db.AssertExistsAndLoadBean(t, &User{ID: 5}).(*User)
+	org := db.AssertExistsAndLoadBean(t, &User{ID: 6, Type: UserTypeOrganization}).(*User)
+
+	// a pending transfer to the organization is cancelled
+	repo := db.AssertExistsAndLoadBean(t, &Repository{ID: 2}).(*Repository)
+	repo.Status = RepositoryPendingTransfer
+	assert.NoError(t, UpdateRepositoryCols(repo, "status"))
+	_, err := db.GetEngine(db.DefaultContext).Insert(&RepoTransfer{DoerID: repo.OwnerID, RecipientID: org.ID, RepoID: repo.ID})
+	assert.NoError(t, err)
+
+	assert.NoError(t, TrashOrganization(doer, org))
+	assert.True(t, org.IsDeleted())
+	assert.EqualValues(t, doer.ID, org.DeletedByID)
+	assert.True(t, IsErrOrgNotExist(TrashOrganization(doer, org)))
+	db.AssertNotExistsBean(t, &RepoTransfer{RepoID: repo.ID})
+	db.AssertExistsAndLoadBean(t, &Repository{ID: repo.ID, Status: RepositoryReady})
+
+	_, err = GetOrgByName(org.Name)
+	assert.True(t, IsErrOrgNotExist(err))
+	_, err = GetUserByName(org.Name)
+	assert.True(t, IsErrUserNotExist(err))
+	exist, err := IsUserExist(0, org.Name)
+	assert.NoError(t, err)
+	assert.True(t, exist, "the name of an organization in the trash stays taken")
+	orgs, err := GetOrgsByUserID(doer.ID, true)
+	assert.NoError(t, err)
+	for _, o := range orgs {
+		assert.NotEqual(t, org.ID, o.ID)
+	}
+
+	deleted, err := GetDeletedOrgByName(org.Name)
+	assert.NoError(t, err)
+	assert.EqualValues(t, org.ID, deleted.ID)
+
+	orgs, count, err := FindDeletedOrgs(&FindDeletedOrgsOptions{OwnerID: doer.ID})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	if assert.Len(t, orgs, 1) {
+		assert.EqualValues(t, org.ID, orgs[0].ID)
+	}
+	// a member who is not an owner does not find it
+	_, count, err = FindDeletedOrgs(&FindDeletedOrgsOptions{OwnerID: 28})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, count)
+	_, count, err = FindDeletedOrgs(&FindDeletedOrgsOptions{DeletedBefore: org.DeletedUnix})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, count)
+
+	assert.NoError(t, RestoreOrganization(org))
+	assert.False(t, org.IsDeleted())
+	assert.True(t, IsErrOrgNotExist(RestoreOrganization(org)))
+	_, err = GetOrgByName(org.Name)
+	assert.NoError(t, err)
+}
+
+func TestTrashOrganizationWithRepositories(t *testing.T) {
+	assert.NoError(t, db.PrepareTestDatabase())
+
+	doer := db.AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	org := db.AssertExistsAndLoadBean(t, &User{ID: 3, Type: UserTypeOrganization}).(*User)
+
+	assert.True(t, IsErrUserOwnRepos(TrashOrganization(doer, org)))
+	assert.False(t, org.IsDeleted())
+	db.AssertExistsAndLoadBean(t, &User{ID: org.ID, DeletedUnix: 0})
+
+	// the organization can be moved to the trash once all its repositories are there
+	repos := make([]*Repository, 0, org.NumRepos)
+	assert.NoError(t, db.GetEngine(db.DefaultContext).Where("owner_id = ?", org.ID).Find(&repos))
+	for _, repo := range repos {
+		assert.NoError(t, TrashRepository(doer, repo))
+	}
+	assert.NoError(t, CheckOrgReposInTrash(org))
+	assert.NoError(t, TrashOrganization(doer, org))
+}
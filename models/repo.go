@@ -242,6 +242,10 @@ type Repository struct {
 
 	TrustModel TrustModelType
 
+	// DeletedUnix is set while the repository is in the trash
+	DeletedUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+	DeletedByID int64              `xorm:"NOT NULL DEFAULT 0"`
+
 	// Avatar: ID(10-20)-md5(32) - must fit into 64 symbols
 	Avatar string `xorm:"VARCHAR(64)"`
 
@@ -694,7 +698,24 @@ func (repo *Repository) getTemplateRepo(e db.Engine) (err error) {
 
 // RepoPath returns the repository path
 func (repo *Repository) RepoPath() string {
-	return RepoPath(repo.OwnerName, repo.Name)
+	if len(setting.RepoShards) == 0 {
+		return RepoPath(repo.OwnerName, repo.Name)
+	}
+
+	// the owner caches its repository shard
+	if err := repo.GetOwner(); err != nil {
+		log.Error("RepoPath: unable to get the owner of %s/%s: %v", repo.OwnerName, repo.Name, err)
+		return filepath.Join(unavailableRepoShardRoot, strings.ToLower(repo.OwnerName), strings.ToLower(repo.Name)+".git")
+	}
+	if repo.Owner.LowerName != strings.ToLower(repo.OwnerName) {
+		return RepoPath(repo.OwnerName, repo.Name)
+	}
+	root, err := repo.Owner.getRepoShardRoot(db.GetEngine(db.DefaultContext))
+	if err != nil {
+		log.Error("RepoPath: %v", err)
+		root = unavailableRepoShardRoot
+	}
+	return filepath.Join(root, repo.Owner.LowerName, strings.ToLower(repo.Name)+".git")
 }
 
 // GitConfigPath returns the path to a repository's git config/ directory
@@ -1501,6 +1522,11 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		return err
 	}
 
+	if _, err := sess.In("push_mirror_id", builder.Select("id").From("push_mirror").Where(builder.Eq{"repo_id": repoID})).
+		Delete(&PushMirrorSync{}); err != nil {
+		return err
+	}
+
 	if err := deleteBeans(sess,
 		&Access{RepoID: repo.ID},
 		&Action{RepoID: repo.ID},
@@ -1508,6 +1534,7 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		&Comment{RefRepoID: repoID},
 		&CommitStatus{RepoID: repoID},
 		&DeletedBranch{RepoID: repoID},
+		&ForeignReference{RepoID: repoID},
 		&HookTask{RepoID: repoID},
 		&LFSLock{RepoID: repoID},
 		&LanguageStat{RepoID: repoID},
@@ -1519,6 +1546,7 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		&PullRequest{BaseRepoID: repoID},
 		&PushMirror{RepoID: repoID},
 		&Release{RepoID: repoID},
+		&RepoGeneration{RepoID: repoID},
 		&RepoIndexerStatus{RepoID: repoID},
 		&RepoRedirect{RedirectRepoID: repoID},
 		&RepoUnit{RepoID: repoID},
@@ -1546,14 +1574,17 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		return err
 	}
 
-	if repo.IsFork {
-		if _, err := sess.Exec("UPDATE `repository` SET num_forks=num_forks-1 WHERE id=?", repo.ForkID); err != nil {
-			return fmt.Errorf("decrease fork count: %v", err)
+	// the counters no longer include a repository in the trash
+	if !repo.IsDeleted() {
+		if repo.IsFork {
+			if _, err := sess.Exec("UPDATE `repository` SET num_forks=num_forks-1 WHERE id=?", repo.ForkID); err != nil {
+				return fmt.Errorf("decrease fork count: %v", err)
+			}
 		}
-	}
 
-	if _, err := sess.Exec("UPDATE `user` SET num_repos=num_repos-1 WHERE id=?", uid); err != nil {
-		return err
+		if _, err := sess.Exec("UPDATE `user` SET num_repos=num_repos-1 WHERE id=?", uid); err != nil {
+			return err
+		}
 	}
 
 	if len(repo.Topics) > 0 {
@@ -1614,10 +1645,9 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		return err
 	}
 
-	if repo.NumForks > 0 {
-		if _, err = sess.Exec("UPDATE `repository` SET fork_id=0,is_fork=? WHERE fork_id=?", false, repo.ID); err != nil {
-			log.Error("reset 'fork_id' and 'is_fork': %v", err)
-		}
+	// NumForks does not count forks in the trash, so always detach the forks
+	if _, err = sess.Exec("UPDATE `repository` SET fork_id=0,is_fork=? WHERE fork_id=?", false, repo.ID); err != nil {
+		log.Error("reset 'fork_id' and 'is_fork': %v", err)
 	}
 
 	// Get all attachments with both issue_id and release_id are zero
@@ -1702,6 +1732,7 @@ func getRepositoryByOwnerAndName(e db.Engine, ownerName, repoName string) (*Repo
 		Join("INNER", "`user`", "`user`.id = repository.owner_id").
 		Where("repository.lower_name = ?", strings.ToLower(repoName)).
 		And("`user`.lower_name = ?", strings.ToLower(ownerName)).
+		And("repository.deleted_unix = 0").
 		Get(&repo)
 	if err != nil {
 		return nil, err
@@ -1717,7 +1748,7 @@ func GetRepositoryByName(ownerID int64, name string) (*Repository, error) {
 		OwnerID:   ownerID,
 		LowerName: strings.ToLower(name),
 	}
-	has, err := db.GetEngine(db.DefaultContext).Get(repo)
+	has, err := db.GetEngine(db.DefaultContext).Where("deleted_unix = 0").Get(repo)
 	if err != nil {
 		return nil, err
 	} else if !has {
@@ -1728,7 +1759,7 @@ func GetRepositoryByName(ownerID int64, name string) (*Repository, error) {
 
 func getRepositoryByID(e db.Engine, id int64) (*Repository, error) {
 	repo := new(Repository)
-	has, err := e.ID(id).Get(repo)
+	has, err := e.ID(id).Where("deleted_unix = 0").Get(repo)
 	if err != nil {
 		return nil, err
 	} else if !has {
@@ -1737,7 +1768,7 @@ func getRepositoryByID(e db.Engine, id int64) (*Repository, error) {
 	return repo, nil
 }
 
-// GetRepositoryByID returns the repository by given id if exists.
+// GetRepositoryByID returns the repository by given id if exists and is not in the trash.
 func GetRepositoryByID(id int64) (*Repository, error) {
 	return getRepositoryByID(db.GetEngine(db.DefaultContext), id)
 }
@@ -1769,6 +1800,10 @@ func GetUserRepositories(opts *SearchRepoOptions) ([]*Repository, int64, error)
 		cond = cond.And(builder.In("lower_name", opts.LowerNames))
 	}
 
+	if !opts.IncludeDeleted {
+		cond = cond.And(builder.Eq{"deleted_unix": 0})
+	}
+
 	sess := db.NewSession(db.DefaultContext)
 	defer sess.Close()
 
@@ -1788,6 +1823,7 @@ func GetUserMirrorRepositories(userID int64) ([]*Repository, error) {
 	return repos, db.GetEngine(db.DefaultContext).
 		Where("owner_id = ?", userID).
 		And("is_mirror = ?", true).
+		And("deleted_unix = 0").
 		Find(&repos)
 }
 
@@ -1796,14 +1832,14 @@ func getRepositoryCount(e db.Engine, u *User) (int64, error) {
 }
 
 func getPublicRepositoryCount(e db.Engine, u *User) (int64, error) {
-	return e.Where("is_private = ?", false).Count(&Repository{OwnerID: u.ID})
+	return e.Where("is_private = ?", false).And("deleted_unix = 0").Count(&Repository{OwnerID: u.ID})
 }
 
 func getPrivateRepositoryCount(e db.Engine, u *User) (int64, error) {
-	return e.Where("is_private = ?", true).Count(&Repository{OwnerID: u.ID})
+	return e.Where("is_private = ?", true).And("deleted_unix = 0").Count(&Repository{OwnerID: u.ID})
 }
 
-// GetRepositoryCount returns the total number of repositories of user.
+// GetRepositoryCount returns the total number of repositories of user, including those in the trash.
 func GetRepositoryCount(u *User) (int64, error) {
 	return getRepositoryCount(db.GetEngine(db.DefaultContext), u)
 }
@@ -1916,8 +1952,8 @@ func CheckRepoStats(ctx context.Context) error {
 		},
 		// User.NumRepos
 		{
-			"SELECT `user`.id FROM `user` WHERE `user`.num_repos!=(SELECT COUNT(*) FROM `repository` WHERE owner_id=`user`.id)",
-			"UPDATE `user` SET num_repos=(SELECT COUNT(*) FROM `repository` WHERE owner_id=?) WHERE id=?",
+			"SELECT `user`.id FROM `user` WHERE `user`.num_repos!=(SELECT COUNT(*) FROM `repository` WHERE owner_id=`user`.id AND deleted_unix=0)",
+			"UPDATE `user` SET num_repos=(SELECT COUNT(*) FROM `repository` WHERE owner_id=? AND deleted_unix=0) WHERE id=?",
 			"user count 'num_repos'",
 		},
 		// Issue.NumComments
@@ -1985,7 +2021,7 @@ func CheckRepoStats(ctx context.Context) error {
 
 	// FIXME: use checker when stop supporting old fork repo format.
 	// ***** START: Repository.NumForks *****
-	results, err = db.GetEngine(db.DefaultContext).Query("SELECT repo.id FROM `repository` repo WHERE repo.num_forks!=(SELECT COUNT(*) FROM `repository` WHERE fork_id=repo.id)")
+	results, err = db.GetEngine(db.DefaultContext).Query("SELECT repo.id FROM `repository` repo WHERE repo.num_forks!=(SELECT COUNT(*) FROM `repository` WHERE fork_id=repo.id AND deleted_unix=0)")
 	if err != nil {
 		log.Error("Select repository count 'num_forks': %v", err)
 	} else {
@@ -2005,7 +2041,7 @@ func CheckRepoStats(ctx context.Context) error {
 				continue
 			}
 
-			rawResult, err := db.GetEngine(db.DefaultContext).Query("SELECT COUNT(*) FROM `repository` WHERE fork_id=?", repo.ID)
+			rawResult, err := db.GetEngine(db.DefaultContext).Query("SELECT COUNT(*) FROM `repository` WHERE fork_id=? AND deleted_unix=0", repo.ID)
 			if err != nil {
 				log.Error("Select count of forks[%d]: %v", repo.ID, err)
 				continue
@@ -2040,7 +2076,7 @@ func (repo *Repository) SetArchiveRepoState(isArchived bool) (err error) {
 func HasForkedRepo(ownerID, repoID int64) (*Repository, bool) {
 	repo := new(Repository)
 	has, _ := db.GetEngine(db.DefaultContext).
-		Where("owner_id=? AND fork_id=?", ownerID, repoID).
+		Where("owner_id=? AND fork_id=? AND deleted_unix=0", ownerID, repoID).
 		Get(repo)
 	return repo, has
 }
@@ -2067,18 +2103,18 @@ func CopyLFS(ctx context.Context, newRepo, oldRepo *Repository) error {
 func (repo *Repository) GetForks(listOptions db.ListOptions) ([]*Repository, error) {
 	if listOptions.Page == 0 {
 		forks := make([]*Repository, 0, repo.NumForks)
-		return forks, db.GetEngine(db.DefaultContext).Find(&forks, &Repository{ForkID: repo.ID})
+		return forks, db.GetEngine(db.DefaultContext).Where("deleted_unix = 0").Find(&forks, &Repository{ForkID: repo.ID})
 	}
 
 	sess := db.GetPaginatedSession(&listOptions)
 	forks := make([]*Repository, 0, listOptions.PageSize)
-	return forks, sess.Find(&forks, &Repository{ForkID: repo.ID})
+	return forks, sess.Where("deleted_unix = 0").Find(&forks, &Repository{ForkID: repo.ID})
 }
 
 // GetUserFork return user forked repository from this repository, if not forked return nil
 func (repo *Repository) GetUserFork(userID int64) (*Repository, error) {
 	var forkedRepo Repository
-	has, err := db.GetEngine(db.DefaultContext).Where("fork_id = ?", repo.ID).And("owner_id = ?", userID).Get(&forkedRepo)
+	has, err := db.GetEngine(db.DefaultContext).Where("fork_id = ?", repo.ID).And("owner_id = ?", userID).And("deleted_unix = 0").Get(&forkedRepo)
 	if err != nil {
 		return nil, err
 	}
@@ -130,6 +130,11 @@ func (repo *Repository) getCollaboration(e db.Engine, uid int64) (*Collaboration
 	return collaboration, err
 }
 
+// GetCollaboration returns the collaboration of the user with the repository, or nil if the user is not a collaborator
+func (repo *Repository) GetCollaboration(uid int64) (*Collaboration, error) {
+	return repo.getCollaboration(db.GetEngine(db.DefaultContext), uid)
+}
+
 func (repo *Repository) isCollaborator(e db.Engine, userID int64) (bool, error) {
 	return e.Get(&Collaboration{RepoID: repo.ID, UserID: userID})
 }
@@ -30,11 +30,18 @@ type GenerateRepoOptions struct {
 	Webhooks    bool
 	Avatar      bool
 	IssueLabels bool
+
+	ProtectedBranches bool
+	Teams             bool
+
+	// Variables holds the values entered for the variables declared by the template
+	Variables map[string]string
 }
 
 // IsValid checks whether at least one option is chosen for generation
 func (gro GenerateRepoOptions) IsValid() bool {
-	return gro.GitContent || gro.Topics || gro.GitHooks || gro.Webhooks || gro.Avatar || gro.IssueLabels // or other items as they are added
+	return gro.GitContent || gro.Topics || gro.GitHooks || gro.Webhooks || gro.Avatar || gro.IssueLabels ||
+		gro.ProtectedBranches || gro.Teams // or other items as they are added
 }
 
 // GiteaTemplate holds information about a .gitea/template file
@@ -169,3 +176,54 @@ func GenerateIssueLabels(ctx context.Context, templateRepo, generateRepo *Reposi
 	}
 	return nil
 }
+
+// GenerateProtectedBranches generates the branch protection rules of a template repository.
+// User and team whitelists are only kept if both repositories have the same owner.
+func GenerateProtectedBranches(ctx context.Context, templateRepo, generateRepo *Repository) error {
+	e := db.GetEngine(ctx)
+	protectedBranches := make([]*ProtectedBranch, 0, 5)
+	if err := e.Find(&protectedBranches, &ProtectedBranch{RepoID: templateRepo.ID}); err != nil {
+		return err
+	}
+
+	for _, protectedBranch := range protectedBranches {
+		protectedBranch.ID = 0
+		protectedBranch.RepoID = generateRepo.ID
+		if templateRepo.OwnerID != generateRepo.OwnerID {
+			protectedBranch.WhitelistUserIDs = []int64{}
+			protectedBranch.WhitelistTeamIDs = []int64{}
+			protectedBranch.MergeWhitelistUserIDs = []int64{}
+			protectedBranch.MergeWhitelistTeamIDs = []int64{}
+			protectedBranch.ApprovalsWhitelistUserIDs = []int64{}
+			protectedBranch.ApprovalsWhitelistTeamIDs = []int64{}
+		}
+		if _, err := e.Insert(protectedBranch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GenerateTeams gives the teams with access to a template repository access to the
+// generated repository, if both repositories belong to the same organization
+func GenerateTeams(ctx context.Context, templateRepo, generateRepo *Repository) error {
+	if templateRepo.OwnerID != generateRepo.OwnerID {
+		return nil
+	}
+
+	e := db.GetEngine(ctx)
+	teams, err := templateRepo.getRepoTeams(e)
+	if err != nil {
+		return err
+	}
+
+	for _, team := range teams {
+		if team.hasRepository(e, generateRepo.ID) {
+			continue
+		}
+		if err := team.addRepository(e, generateRepo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -181,6 +181,8 @@ type SearchRepoOptions struct {
 	HasMilestones util.OptionalBool
 	// LowerNames represents valid lower names to restrict to
 	LowerNames []string
+	// include repositories in the trash
+	IncludeDeleted bool
 }
 
 // SearchOrderBy is used to sort the result
@@ -212,6 +214,10 @@ const (
 func SearchRepositoryCondition(opts *SearchRepoOptions) builder.Cond {
 	cond := builder.NewCond()
 
+	if !opts.IncludeDeleted {
+		cond = cond.And(builder.Eq{"deleted_unix": 0})
+	}
+
 	if opts.Private {
 		if opts.Actor != nil && !opts.Actor.IsAdmin && opts.Actor.ID != opts.OwnerID {
 			// OK we're in the context of a User
@@ -474,7 +480,8 @@ func accessibleRepositoryCondition(user *User) builder.Cond {
 						Where(builder.Eq{"`org_user`.uid": user.ID}))))
 	}
 
-	return cond
+	// repositories in the trash are only listed explicitly
+	return builder.And(builder.Eq{"`repository`.deleted_unix": 0}, cond)
 }
 
 // SearchRepositoryByName takes keyword and part of repository name to search,
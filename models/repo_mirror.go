@@ -12,6 +12,7 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/timeutil"
 
+	"xorm.io/builder"
 	"xorm.io/xorm"
 )
 
@@ -35,9 +36,36 @@ type Mirror struct {
 	LFS         bool   `xorm:"lfs_enabled NOT NULL DEFAULT false"`
 	LFSEndpoint string `xorm:"lfs_endpoint TEXT"`
 
+	// SyncMetadata enables syncing issues, pull requests and releases from the original service
+	SyncMetadata       bool               `xorm:"NOT NULL DEFAULT false"`
+	MetadataSyncedUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+
+	// LastSyncUnix is the end of the last sync whether it failed or not, unlike UpdatedUnix
+	LastSyncUnix     timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+	LastSyncDuration time.Duration      `xorm:"NOT NULL DEFAULT 0"`
+	LastError        string             `xorm:"TEXT"`
+	// LastSyncRefs are the refs updated by the last sync, up to MirrorSyncRefsLimit of NumLastSyncRefs
+	LastSyncRefs    []*MirrorSyncRef `xorm:"TEXT JSON"`
+	NumLastSyncRefs int              `xorm:"NOT NULL DEFAULT 0"`
+
+	// WebhookSecret is the secret the remote signs its push webhooks with to trigger a sync,
+	// stored encrypted with the secret key. Webhooks are refused without it.
+	WebhookSecret string `xorm:"TEXT"`
+
 	Address string `xorm:"-"`
 }
 
+// MirrorSyncRefsLimit is the maximum number of updated refs kept of a sync
+const MirrorSyncRefsLimit = 100
+
+// MirrorSyncRef represents a ref updated by the sync of a mirror,
+// the commit id is empty for a created or deleted ref
+type MirrorSyncRef struct {
+	RefName     string
+	OldCommitID string
+	NewCommitID string
+}
+
 func init() {
 	db.RegisterModel(new(Mirror))
 }
@@ -98,6 +126,11 @@ func GetMirrorByRepoID(repoID int64) (*Mirror, error) {
 	return getMirrorByRepoID(db.GetEngine(db.DefaultContext), repoID)
 }
 
+// HasWebhook returns true if the mirror can be synced by webhooks of its remote
+func (m *Mirror) HasWebhook() bool {
+	return m.WebhookSecret != ""
+}
+
 func updateMirror(e db.Engine, m *Mirror) error {
 	_, err := e.ID(m.ID).AllCols().Update(m)
 	return err
@@ -108,6 +141,20 @@ func UpdateMirror(m *Mirror) error {
 	return updateMirror(db.GetEngine(db.DefaultContext), m)
 }
 
+// UpdateMirrorSyncStatus updates the status of the last sync of the mirror
+func UpdateMirrorSyncStatus(m *Mirror) error {
+	_, err := db.GetEngine(db.DefaultContext).ID(m.ID).
+		Cols("last_sync_unix", "last_sync_duration", "last_error", "last_sync_refs", "num_last_sync_refs").
+		Update(m)
+	return err
+}
+
+// UpdateMirrorWebhookSecret updates the webhook secret of the mirror
+func UpdateMirrorWebhookSecret(m *Mirror) error {
+	_, err := db.GetEngine(db.DefaultContext).ID(m.ID).Cols("webhook_secret").Update(m)
+	return err
+}
+
 // DeleteMirrorByRepoID deletes a mirror by repoID
 func DeleteMirrorByRepoID(repoID int64) error {
 	_, err := db.GetEngine(db.DefaultContext).Delete(&Mirror{RepoID: repoID})
@@ -119,6 +166,7 @@ func MirrorsIterate(f func(idx int, bean interface{}) error) error {
 	return db.GetEngine(db.DefaultContext).
 		Where("next_update_unix<=?", time.Now().Unix()).
 		And("next_update_unix!=0").
+		NotIn("repo_id", builder.Select("id").From("repository").Where(builder.Neq{"deleted_unix": 0})).
 		Iterate(new(Mirror), f)
 }
 
@@ -0,0 +1,45 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateMirrorSyncStatus(t *testing.T) {
+	assert.NoError(t, db.PrepareTestDatabase())
+
+	m := &Mirror{RepoID: 1, Interval: time.Hour}
+	assert.NoError(t, InsertMirror(m))
+
+	m.LastSyncUnix = timeutil.TimeStampNow()
+	m.LastSyncDuration = 1500 * time.Millisecond
+	m.LastError = "fatal: repository not found"
+	m.LastSyncRefs = []*MirrorSyncRef{{RefName: "refs/heads/main", OldCommitID: "65f1bf2", NewCommitID: "4a357436"}}
+	m.NumLastSyncRefs = 1
+	m.WebhookSecret = "secret"
+	assert.NoError(t, UpdateMirrorSyncStatus(m))
+
+	loaded, err := GetMirrorByRepoID(1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, m.LastSyncUnix, loaded.LastSyncUnix)
+	assert.EqualValues(t, m.LastSyncDuration, loaded.LastSyncDuration)
+	assert.EqualValues(t, m.LastError, loaded.LastError)
+	assert.EqualValues(t, m.LastSyncRefs, loaded.LastSyncRefs)
+	assert.EqualValues(t, 1, loaded.NumLastSyncRefs)
+	// only the sync status is updated
+	assert.False(t, loaded.HasWebhook())
+
+	assert.NoError(t, UpdateMirrorWebhookSecret(m))
+	loaded, err = GetMirrorByRepoID(1)
+	assert.NoError(t, err)
+	assert.True(t, loaded.HasWebhook())
+}
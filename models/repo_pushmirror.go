@@ -6,12 +6,15 @@ package models
 
 import (
 	"errors"
+	"strings"
 	"time"
 
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/timeutil"
 
+	"github.com/gobwas/glob"
+	"xorm.io/builder"
 	"xorm.io/xorm"
 )
 
@@ -27,14 +30,71 @@ type PushMirror struct {
 	Repo       *Repository `xorm:"-"`
 	RemoteName string
 
+	// BranchFilter and TagFilter are comma separated glob patterns of the refs to push, empty pushes all
+	BranchFilter string `xorm:"TEXT"`
+	TagFilter    string `xorm:"TEXT"`
+	SyncOnCommit bool   `xorm:"NOT NULL DEFAULT false"`
+
+	// PublicKey and PrivateKey are the deploy key of a mirror pushing over SSH,
+	// the private key is stored encrypted with the secret key
+	PublicKey  string `xorm:"TEXT"`
+	PrivateKey string `xorm:"TEXT"`
+
 	Interval       time.Duration
 	CreatedUnix    timeutil.TimeStamp `xorm:"created"`
 	LastUpdateUnix timeutil.TimeStamp `xorm:"INDEX last_update"`
-	LastError      string             `xorm:"text"`
+
+	Syncs []*PushMirrorSync `xorm:"-"`
+}
+
+// PushMirrorSyncTrigger represents what started the sync of a push mirror
+type PushMirrorSyncTrigger int
+
+// enumerate all push mirror sync triggers
+const (
+	PushMirrorSyncTriggerSchedule PushMirrorSyncTrigger = iota // 0
+	PushMirrorSyncTriggerManual                                // 1
+	PushMirrorSyncTriggerCommit                                // 2
+)
+
+// String returns the name of the trigger
+func (t PushMirrorSyncTrigger) String() string {
+	switch t {
+	case PushMirrorSyncTriggerManual:
+		return "manual"
+	case PushMirrorSyncTriggerCommit:
+		return "commit"
+	default:
+		return "schedule"
+	}
+}
+
+// pushMirrorSyncHistoryLimit is the number of syncs kept for each push mirror
+const pushMirrorSyncHistoryLimit = 10
+
+// PushMirrorSync represents a sync of a push mirror
+type PushMirrorSync struct {
+	ID           int64                 `xorm:"pk autoincr"`
+	PushMirrorID int64                 `xorm:"INDEX"`
+	Trigger      PushMirrorSyncTrigger `xorm:"NOT NULL DEFAULT 0"`
+	Error        string                `xorm:"TEXT"`
+	StartedUnix  timeutil.TimeStamp
+	FinishedUnix timeutil.TimeStamp
 }
 
 func init() {
 	db.RegisterModel(new(PushMirror))
+	db.RegisterModel(new(PushMirrorSync))
+}
+
+// IsSuccess returns true if the sync did not fail
+func (s *PushMirrorSync) IsSuccess() bool {
+	return s.Error == ""
+}
+
+// Duration returns how long the sync took
+func (s *PushMirrorSync) Duration() time.Duration {
+	return time.Duration(s.FinishedUnix-s.StartedUnix) * time.Second
 }
 
 // AfterLoad is invoked from XORM after setting the values of all fields of this object.
@@ -60,6 +120,70 @@ func (m *PushMirror) GetRemoteName() string {
 	return m.RemoteName
 }
 
+// IsSSH returns true if the mirror pushes over SSH with its own deploy key
+func (m *PushMirror) IsSSH() bool {
+	return m.PrivateKey != ""
+}
+
+// HasFilters returns true if the mirror pushes only some of the branches or tags
+func (m *PushMirror) HasFilters() bool {
+	return m.BranchFilter != "" || m.TagFilter != ""
+}
+
+// MatchRef returns true if the branch or tag of the full ref name is pushed by the mirror
+func (m *PushMirror) MatchRef(refName string) bool {
+	if strings.HasPrefix(refName, "refs/heads/") {
+		return matchPushMirrorFilter(m.BranchFilter, strings.TrimPrefix(refName, "refs/heads/"))
+	} else if strings.HasPrefix(refName, "refs/tags/") {
+		return matchPushMirrorFilter(m.TagFilter, strings.TrimPrefix(refName, "refs/tags/"))
+	}
+	return false
+}
+
+func matchPushMirrorFilter(filter, name string) bool {
+	if filter == "" {
+		return true
+	}
+	for _, pattern := range strings.Split(filter, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if g, err := glob.Compile(pattern, '/'); err != nil {
+			log.Warn("Invalid push mirror filter pattern %q: %v", pattern, err)
+		} else if g.Match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePushMirrorFilter returns an error if a pattern of the comma separated filter is invalid
+func ValidatePushMirrorFilter(filter string) error {
+	for _, pattern := range strings.Split(filter, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			if _, err := glob.Compile(pattern, '/'); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// LoadSyncs loads the latest syncs of the push mirror
+func (m *PushMirror) LoadSyncs() (err error) {
+	m.Syncs, err = GetPushMirrorSyncs(m.ID)
+	return err
+}
+
+// LastSync returns the latest sync of the push mirror, the syncs must have been loaded
+func (m *PushMirror) LastSync() *PushMirrorSync {
+	if len(m.Syncs) == 0 {
+		return nil
+	}
+	return m.Syncs[0]
+}
+
 // InsertPushMirror inserts a push-mirror to database
 func InsertPushMirror(m *PushMirror) error {
 	_, err := db.GetEngine(db.DefaultContext).Insert(m)
@@ -74,14 +198,35 @@ func UpdatePushMirror(m *PushMirror) error {
 
 // DeletePushMirrorByID deletes a push-mirrors by ID
 func DeletePushMirrorByID(ID int64) error {
-	_, err := db.GetEngine(db.DefaultContext).ID(ID).Delete(&PushMirror{})
-	return err
+	sess := db.NewSession(db.DefaultContext)
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	if _, err := sess.Delete(&PushMirrorSync{PushMirrorID: ID}); err != nil {
+		return err
+	}
+	if _, err := sess.ID(ID).Delete(&PushMirror{}); err != nil {
+		return err
+	}
+	return sess.Commit()
 }
 
 // DeletePushMirrorsByRepoID deletes all push-mirrors by repoID
 func DeletePushMirrorsByRepoID(repoID int64) error {
-	_, err := db.GetEngine(db.DefaultContext).Delete(&PushMirror{RepoID: repoID})
-	return err
+	sess := db.NewSession(db.DefaultContext)
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	if _, err := sess.In("push_mirror_id", builder.Select("id").From("push_mirror").Where(builder.Eq{"repo_id": repoID})).
+		Delete(&PushMirrorSync{}); err != nil {
+		return err
+	}
+	if _, err := sess.Delete(&PushMirror{RepoID: repoID}); err != nil {
+		return err
+	}
+	return sess.Commit()
 }
 
 // GetPushMirrorByID returns push-mirror information.
@@ -102,10 +247,49 @@ func GetPushMirrorsByRepoID(repoID int64) ([]*PushMirror, error) {
 	return mirrors, db.GetEngine(db.DefaultContext).Where("repo_id=?", repoID).Find(&mirrors)
 }
 
+// GetPushMirrorsSyncOnCommit returns the push-mirrors of a repository which are synced after every push
+func GetPushMirrorsSyncOnCommit(repoID int64) ([]*PushMirror, error) {
+	mirrors := make([]*PushMirror, 0, 10)
+	return mirrors, db.GetEngine(db.DefaultContext).Where("repo_id=? AND sync_on_commit=?", repoID, true).Find(&mirrors)
+}
+
+// InsertPushMirrorSync records a sync of a push-mirror and removes the syncs beyond the history limit
+func InsertPushMirrorSync(s *PushMirrorSync) error {
+	sess := db.NewSession(db.DefaultContext)
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	if _, err := sess.Insert(s); err != nil {
+		return err
+	}
+
+	var ids []int64
+	if err := sess.Table("push_mirror_sync").Where("push_mirror_id=?", s.PushMirrorID).
+		Desc("id").Limit(pushMirrorSyncHistoryLimit).Cols("id").Find(&ids); err != nil {
+		return err
+	}
+	if len(ids) == pushMirrorSyncHistoryLimit {
+		if _, err := sess.Where("push_mirror_id=? AND id<?", s.PushMirrorID, ids[len(ids)-1]).
+			Delete(&PushMirrorSync{}); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
+
+// GetPushMirrorSyncs returns the latest syncs of a push-mirror, newest first
+func GetPushMirrorSyncs(mirrorID int64) ([]*PushMirrorSync, error) {
+	syncs := make([]*PushMirrorSync, 0, pushMirrorSyncHistoryLimit)
+	return syncs, db.GetEngine(db.DefaultContext).Where("push_mirror_id=?", mirrorID).
+		Desc("id").Limit(pushMirrorSyncHistoryLimit).Find(&syncs)
+}
+
 // PushMirrorsIterate iterates all push-mirror repositories.
 func PushMirrorsIterate(f func(idx int, bean interface{}) error) error {
 	return db.GetEngine(db.DefaultContext).
 		Where("last_update + (`interval` / ?) <= ?", time.Second, time.Now().Unix()).
 		And("`interval` != 0").
+		NotIn("repo_id", builder.Select("id").From("repository").Where(builder.Neq{"deleted_unix": 0})).
 		Iterate(new(PushMirror), f)
 }
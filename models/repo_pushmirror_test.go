@@ -48,3 +48,52 @@ func TestPushMirrorsIterate(t *testing.T) {
 		return nil
 	})
 }
+
+func TestPushMirrorMatchRef(t *testing.T) {
+	m := &PushMirror{}
+	assert.True(t, m.MatchRef("refs/heads/feature/x"))
+	assert.True(t, m.MatchRef("refs/tags/v1.0"))
+	assert.False(t, m.MatchRef("refs/pull/1/head"))
+
+	m = &PushMirror{BranchFilter: "main, release/*", TagFilter: "v*"}
+	assert.True(t, m.HasFilters())
+	assert.True(t, m.MatchRef("refs/heads/main"))
+	assert.True(t, m.MatchRef("refs/heads/release/1.0"))
+	assert.False(t, m.MatchRef("refs/heads/release/1.0/fix"))
+	assert.False(t, m.MatchRef("refs/heads/feature"))
+	assert.True(t, m.MatchRef("refs/tags/v1.0"))
+	assert.False(t, m.MatchRef("refs/tags/nightly"))
+
+	assert.NoError(t, ValidatePushMirrorFilter("main, release/*"))
+	assert.Error(t, ValidatePushMirrorFilter("release/[a"))
+}
+
+func TestPushMirrorSyncHistory(t *testing.T) {
+	assert.NoError(t, db.PrepareTestDatabase())
+
+	m := &PushMirror{RepoID: 1, RemoteName: "test-history"}
+	assert.NoError(t, InsertPushMirror(m))
+
+	for i := 0; i < pushMirrorSyncHistoryLimit+2; i++ {
+		s := &PushMirrorSync{
+			PushMirrorID: m.ID,
+			Trigger:      PushMirrorSyncTriggerCommit,
+			StartedUnix:  timeutil.TimeStamp(100 + i),
+			FinishedUnix: timeutil.TimeStamp(102 + i),
+		}
+		if i == pushMirrorSyncHistoryLimit+1 {
+			s.Error = "failed"
+		}
+		assert.NoError(t, InsertPushMirrorSync(s))
+	}
+
+	assert.NoError(t, m.LoadSyncs())
+	assert.Len(t, m.Syncs, pushMirrorSyncHistoryLimit)
+	last := m.LastSync()
+	assert.False(t, last.IsSuccess())
+	assert.EqualValues(t, 2*time.Second, last.Duration())
+	assert.EqualValues(t, pushMirrorSyncHistoryLimit, db.GetCount(t, &PushMirrorSync{PushMirrorID: m.ID}))
+
+	assert.NoError(t, DeletePushMirrorsByRepoID(1))
+	db.AssertNotExistsBean(t, &PushMirrorSync{PushMirrorID: m.ID})
+}
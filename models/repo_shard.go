@@ -0,0 +1,330 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// RepoShard records the repository shard holding the repositories of an owner.
+// Owners without a record keep their repositories in the default shard.
+type RepoShard struct {
+	ID          int64              `xorm:"pk autoincr"`
+	OwnerName   string             `xorm:"UNIQUE NOT NULL"`
+	Shard       string             `xorm:"INDEX NOT NULL"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+func init() {
+	db.RegisterModel(new(RepoShard))
+}
+
+// ErrRepoShardNotExist represents a "RepoShardNotExist" kind of error.
+type ErrRepoShardNotExist struct {
+	Name string
+}
+
+// IsErrRepoShardNotExist checks if an error is a ErrRepoShardNotExist.
+func IsErrRepoShardNotExist(err error) bool {
+	_, ok := err.(ErrRepoShardNotExist)
+	return ok
+}
+
+func (err ErrRepoShardNotExist) Error() string {
+	return fmt.Sprintf("repository shard does not exist [name: %s]", err.Name)
+}
+
+// RepoShardNames returns the names of all configured repository shards, the default shard first
+func RepoShardNames() []string {
+	names := make([]string, 0, len(setting.RepoShards)+1)
+	for name := range setting.RepoShards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append([]string{setting.DefaultRepoShard}, names...)
+}
+
+// RepoShardRootPath returns the root path of the named repository shard
+func RepoShardRootPath(shard string) (string, error) {
+	if shard == "" || shard == setting.DefaultRepoShard {
+		return setting.RepoRootPath, nil
+	}
+	root, ok := setting.RepoShards[shard]
+	if !ok {
+		return "", ErrRepoShardNotExist{shard}
+	}
+	return root, nil
+}
+
+func getOwnerRepoShard(e db.Engine, ownerName string) (string, error) {
+	// Without shards there is nothing to look up
+	if len(setting.RepoShards) == 0 {
+		return setting.DefaultRepoShard, nil
+	}
+
+	shard := new(RepoShard)
+	has, err := e.Where("owner_name=?", strings.ToLower(ownerName)).Get(shard)
+	if err != nil {
+		return "", err
+	} else if !has {
+		return setting.DefaultRepoShard, nil
+	}
+	return shard.Shard, nil
+}
+
+// GetOwnerRepoShard returns the name of the repository shard holding the repositories of the owner
+func GetOwnerRepoShard(ownerName string) (string, error) {
+	return getOwnerRepoShard(db.GetEngine(db.DefaultContext), ownerName)
+}
+
+// unavailableRepoShardRoot is used as the root path of owners whose shard cannot be
+// resolved. Nothing can be created below the null device, so their repositories can
+// neither be read nor written instead of ending up in the wrong shard.
+var unavailableRepoShardRoot = filepath.Join(os.DevNull, "unavailable-repo-shard")
+
+func getOwnerRepoShardRoot(e db.Engine, ownerName string) (string, error) {
+	shard, err := getOwnerRepoShard(e, ownerName)
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve the repository shard of %s: %v", ownerName, err)
+	}
+	return RepoShardRootPath(shard)
+}
+
+func userPath(e db.Engine, userName string) (string, error) {
+	root, err := getOwnerRepoShardRoot(e, userName)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, strings.ToLower(userName)), nil
+}
+
+// getRepoShardRoot returns the root path of the repository shard holding the
+// repositories of the user, which is only looked up once per user
+func (u *User) getRepoShardRoot(e db.Engine) (string, error) {
+	if u.repoShardRoot == "" {
+		root, err := getOwnerRepoShardRoot(e, u.Name)
+		if err != nil {
+			return "", err
+		}
+		u.repoShardRoot = root
+	}
+	return u.repoShardRoot, nil
+}
+
+func setOwnerRepoShard(e db.Engine, ownerName, shard string) error {
+	lowerName := strings.ToLower(ownerName)
+	if _, err := e.Where("owner_name=?", lowerName).Delete(new(RepoShard)); err != nil {
+		return err
+	}
+	if shard == setting.DefaultRepoShard {
+		return nil
+	}
+	_, err := e.Insert(&RepoShard{
+		OwnerName: lowerName,
+		Shard:     shard,
+	})
+	return err
+}
+
+// newOwnerRepoShard places the repositories of a newly created owner in the configured default shard
+func newOwnerRepoShard(e db.Engine, ownerName string) error {
+	return setOwnerRepoShard(e, ownerName, setting.Repository.DefaultShard)
+}
+
+func renameOwnerRepoShard(e db.Engine, oldOwnerName, newOwnerName string) error {
+	_, err := e.Where("owner_name=?", strings.ToLower(oldOwnerName)).
+		Cols("owner_name").
+		Update(&RepoShard{OwnerName: strings.ToLower(newOwnerName)})
+	return err
+}
+
+func deleteOwnerRepoShard(e db.Engine, ownerName string) error {
+	_, err := e.Where("owner_name=?", strings.ToLower(ownerName)).Delete(new(RepoShard))
+	return err
+}
+
+// MoveOwnerRepoShard moves all repositories of the owner to the named shard.
+// The repositories must not be written to while they are moved.
+func MoveOwnerRepoShard(ownerName, shard string) error {
+	shard = strings.ToLower(shard)
+	dstRoot, err := RepoShardRootPath(shard)
+	if err != nil {
+		return err
+	}
+	current, err := GetOwnerRepoShard(ownerName)
+	if err != nil {
+		return err
+	} else if current == shard {
+		return nil
+	}
+	srcRoot, err := RepoShardRootPath(current)
+	if err != nil {
+		return err
+	}
+
+	lowerName := strings.ToLower(ownerName)
+	src := filepath.Join(srcRoot, lowerName)
+	dst := filepath.Join(dstRoot, lowerName)
+
+	if isExist, err := util.IsExist(dst); err != nil {
+		return err
+	} else if isExist {
+		return fmt.Errorf("target directory %s already exists", dst)
+	}
+
+	moved := false
+	if isExist, err := util.IsExist(src); err != nil {
+		return err
+	} else if isExist {
+		if err := os.MkdirAll(dstRoot, os.ModePerm); err != nil {
+			return fmt.Errorf("Failed to create dir %s: %v", dstRoot, err)
+		}
+		if err := moveRepoDir(src, dst); err != nil {
+			return fmt.Errorf("move %s to %s: %v", src, dst, err)
+		}
+		moved = true
+	}
+
+	if err := setOwnerRepoShard(db.GetEngine(db.DefaultContext), ownerName, shard); err != nil {
+		if moved {
+			if err2 := moveRepoDir(dst, src); err2 != nil {
+				log.Critical("Unable to move %s back to %s after failing to change the repository shard of %s: %v", dst, src, ownerName, err2)
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// moveRepoDir moves a directory of repositories, copying it when the shards are on
+// different file systems
+func moveRepoDir(src, dst string) error {
+	err := util.Rename(src, dst)
+	if err == nil || !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	if err := util.CopyDir(src, dst); err != nil {
+		_ = util.RemoveAll(dst)
+		return err
+	}
+	return util.RemoveAll(src)
+}
+
+// RepoShardOwner is an owner of repositories and the shard holding them
+type RepoShardOwner struct {
+	Name     string
+	NumRepos int
+	Shard    string
+}
+
+// GetRepoShardOwners returns all owners of repositories with the shards holding them
+func GetRepoShardOwners() ([]*RepoShardOwner, error) {
+	users := make([]*User, 0, 50)
+	if err := db.GetEngine(db.DefaultContext).
+		Where("num_repos > 0").
+		Cols("lower_name", "num_repos").
+		Asc("id").
+		Find(&users); err != nil {
+		return nil, err
+	}
+
+	shards := make([]*RepoShard, 0, 50)
+	if err := db.GetEngine(db.DefaultContext).Find(&shards); err != nil {
+		return nil, err
+	}
+	shardOf := make(map[string]string, len(shards))
+	for _, shard := range shards {
+		shardOf[shard.OwnerName] = shard.Shard
+	}
+
+	owners := make([]*RepoShardOwner, 0, len(users))
+	for _, u := range users {
+		shard, ok := shardOf[u.LowerName]
+		if !ok {
+			shard = setting.DefaultRepoShard
+		}
+		owners = append(owners, &RepoShardOwner{
+			Name:     u.LowerName,
+			NumRepos: u.NumRepos,
+			Shard:    shard,
+		})
+	}
+	return owners, nil
+}
+
+// RepoShardMove is a move of the repositories of an owner between shards
+type RepoShardMove struct {
+	Owner    string
+	NumRepos int
+	From     string
+	To       string
+}
+
+// PlanRepoShardRebalance plans the moves which even out the number of repositories
+// held by the given shards. Each move takes an owner from the fullest shard to the
+// emptiest one, as long as this narrows the gap between them.
+func PlanRepoShardRebalance(owners []*RepoShardOwner, shards []string) []*RepoShardMove {
+	numRepos := make(map[string]int, len(shards))
+	byShard := make(map[string][]*RepoShardOwner, len(shards))
+	for _, shard := range shards {
+		numRepos[shard] = 0
+	}
+	for _, owner := range owners {
+		if _, ok := numRepos[owner.Shard]; !ok {
+			continue
+		}
+		numRepos[owner.Shard] += owner.NumRepos
+		byShard[owner.Shard] = append(byShard[owner.Shard], owner)
+	}
+
+	moves := make([]*RepoShardMove, 0, 10)
+	for {
+		fullest, emptiest := shards[0], shards[0]
+		for _, shard := range shards {
+			if numRepos[shard] > numRepos[fullest] {
+				fullest = shard
+			}
+			if numRepos[shard] < numRepos[emptiest] {
+				emptiest = shard
+			}
+		}
+		gap := numRepos[fullest] - numRepos[emptiest]
+
+		// The largest owner which still narrows the gap when moved
+		var pick *RepoShardOwner
+		pickIdx := -1
+		for i, owner := range byShard[fullest] {
+			if owner.NumRepos > 0 && owner.NumRepos < gap && (pick == nil || owner.NumRepos > pick.NumRepos) {
+				pick, pickIdx = owner, i
+			}
+		}
+		if pick == nil {
+			return moves
+		}
+
+		byShard[fullest] = append(byShard[fullest][:pickIdx], byShard[fullest][pickIdx+1:]...)
+		byShard[emptiest] = append(byShard[emptiest], pick)
+		numRepos[fullest] -= pick.NumRepos
+		numRepos[emptiest] += pick.NumRepos
+		moves = append(moves, &RepoShardMove{
+			Owner:    pick.Name,
+			NumRepos: pick.NumRepos,
+			From:     fullest,
+			To:       emptiest,
+		})
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoveOwnerRepoShard(t *testing.T) {
+	assert.NoError(t, db.PrepareTestDatabase())
+
+	shardRoot, err := os.MkdirTemp("", "repo-shard")
+	assert.NoError(t, err)
+	defer util.RemoveAll(shardRoot)
+	defer func() {
+		setting.RepoShards = map[string]string{}
+	}()
+	setting.RepoShards = map[string]string{"second": shardRoot}
+
+	repo := db.AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	oldPath := repo.RepoPath()
+	assert.Equal(t, filepath.Join(setting.RepoRootPath, "user2", "repo1.git"), oldPath)
+
+	shard, err := GetOwnerRepoShard("user2")
+	assert.NoError(t, err)
+	assert.Equal(t, setting.DefaultRepoShard, shard)
+
+	assert.True(t, IsErrRepoShardNotExist(MoveOwnerRepoShard("user2", "missing")))
+
+	assert.NoError(t, MoveOwnerRepoShard("User2", "second"))
+	shard, err = GetOwnerRepoShard("user2")
+	assert.NoError(t, err)
+	assert.Equal(t, "second", shard)
+	db.AssertExistsAndLoadBean(t, &RepoShard{OwnerName: "user2", Shard: "second"})
+
+	// the owner of the loaded repository still caches the old shard
+	assert.Equal(t, oldPath, repo.RepoPath())
+	repo = db.AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	newPath := repo.RepoPath()
+	assert.Equal(t, filepath.Join(shardRoot, "user2", "repo1.git"), newPath)
+	isDir, err := util.IsDir(newPath)
+	assert.NoError(t, err)
+	assert.True(t, isDir)
+	isExist, err := util.IsExist(oldPath)
+	assert.NoError(t, err)
+	assert.False(t, isExist)
+
+	// moving back to the default shard removes the mapping
+	assert.NoError(t, MoveOwnerRepoShard("user2", setting.DefaultRepoShard))
+	db.AssertNotExistsBean(t, &RepoShard{OwnerName: "user2"})
+	repo = db.AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	assert.Equal(t, oldPath, repo.RepoPath())
+	isDir, err = util.IsDir(oldPath)
+	assert.NoError(t, err)
+	assert.True(t, isDir)
+}
+
+func TestNewOwnerRepoShard(t *testing.T) {
+	assert.NoError(t, db.PrepareTestDatabase())
+
+	defer func() {
+		setting.RepoShards = map[string]string{}
+		setting.Repository.DefaultShard = setting.DefaultRepoShard
+	}()
+	setting.RepoShards = map[string]string{"second": filepath.Join(os.TempDir(), "second")}
+	setting.Repository.DefaultShard = "second"
+
+	u := &User{Name: "sharded", Email: "sharded@example.com", Passwd: "password"}
+	assert.NoError(t, CreateUser(u))
+	assert.Equal(t, filepath.Join(os.TempDir(), "second", "sharded"), UserPath("Sharded"))
+
+	assert.NoError(t, ChangeUserName(u, "resharded"))
+	db.AssertExistsAndLoadBean(t, &RepoShard{OwnerName: "resharded", Shard: "second"})
+	db.AssertNotExistsBean(t, &RepoShard{OwnerName: "sharded"})
+}
+
+func TestUserPath_UnknownShard(t *testing.T) {
+	assert.NoError(t, db.PrepareTestDatabase())
+
+	defer func() {
+		setting.RepoShards = map[string]string{}
+	}()
+	setting.RepoShards = map[string]string{"second": filepath.Join(os.TempDir(), "second")}
+	_, err := db.GetEngine(db.DefaultContext).Insert(&RepoShard{OwnerName: "user2", Shard: "removed"})
+	assert.NoError(t, err)
+
+	_, err = userPath(db.GetEngine(db.DefaultContext), "user2")
+	assert.True(t, IsErrRepoShardNotExist(err))
+
+	// the repositories are neither looked for nor created in the default shard
+	path := UserPath("User2")
+	assert.Equal(t, filepath.Join(unavailableRepoShardRoot, "user2"), path)
+	assert.Error(t, os.MkdirAll(path, os.ModePerm))
+
+	repo := db.AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	assert.Equal(t, filepath.Join(unavailableRepoShardRoot, "user2", "repo1.git"), repo.RepoPath())
+
+	user := db.AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	assert.True(t, IsErrRepoShardNotExist(ChangeUserName(user, "user2-renamed")))
+}
+
+func TestPlanRepoShardRebalance(t *testing.T) {
+	owners := []*RepoShardOwner{
+		{Name: "a", NumRepos: 10, Shard: "default"},
+		{Name: "b", NumRepos: 6, Shard: "default"},
+		{Name: "c", NumRepos: 3, Shard: "default"},
+		{Name: "d", NumRepos: 1, Shard: "default"},
+		{Name: "e", NumRepos: 2, Shard: "second"},
+		{Name: "f", NumRepos: 5, Shard: "unknown"},
+	}
+
+	moves := PlanRepoShardRebalance(owners, []string{"default", "second"})
+	if assert.Len(t, moves, 1) {
+		assert.Equal(t, RepoShardMove{Owner: "a", NumRepos: 10, From: "default", To: "second"}, *moves[0])
+	}
+
+	moves = PlanRepoShardRebalance(owners, []string{"default", "second", "third"})
+	if assert.Len(t, moves, 2) {
+		assert.Equal(t, RepoShardMove{Owner: "a", NumRepos: 10, From: "default", To: "third"}, *moves[0])
+		assert.Equal(t, RepoShardMove{Owner: "b", NumRepos: 6, From: "default", To: "second"}, *moves[1])
+	}
+
+	assert.Empty(t, PlanRepoShardRebalance(owners[:1], []string{"default", "second"}))
+}
@@ -0,0 +1,361 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/gobwas/glob"
+	"gopkg.in/yaml.v2"
+)
+
+// TemplateVariableType represents the kind of value a template variable holds
+type TemplateVariableType string
+
+// enumerates all template variable types
+const (
+	TemplateVariableString TemplateVariableType = "string"
+	TemplateVariableBool   TemplateVariableType = "bool"
+	TemplateVariableChoice TemplateVariableType = "choice"
+)
+
+var templateVariableNamePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// TemplateVariable is a variable declared by a template repository whose value
+// is entered when a repository is generated from the template
+type TemplateVariable struct {
+	Name     string               `yaml:"name"`
+	Prompt   string               `yaml:"prompt"`
+	Default  string               `yaml:"default"`
+	Type     TemplateVariableType `yaml:"type"`
+	Options  []string             `yaml:"options"`
+	Required bool                 `yaml:"required"`
+	Pattern  string               `yaml:"pattern"`
+
+	pattern *regexp.Regexp
+}
+
+// IsBool returns true if the variable is a boolean
+func (v *TemplateVariable) IsBool() bool {
+	return v.Type == TemplateVariableBool
+}
+
+// IsChoice returns true if the variable must be one of its options
+func (v *TemplateVariable) IsChoice() bool {
+	return v.Type == TemplateVariableChoice
+}
+
+// DisplayPrompt returns the prompt of the variable or its name if it has none
+func (v *TemplateVariable) DisplayPrompt() string {
+	if v.Prompt != "" {
+		return v.Prompt
+	}
+	return v.Name
+}
+
+func (v *TemplateVariable) init() (err error) {
+	if !templateVariableNamePattern.MatchString(v.Name) {
+		return fmt.Errorf("invalid variable name %q: must be upper case letters, digits and underscores", v.Name)
+	}
+	if strings.HasPrefix(v.Name, "REPO_") || strings.HasPrefix(v.Name, "TEMPLATE_") {
+		return fmt.Errorf("invalid variable name %q: REPO_ and TEMPLATE_ are reserved prefixes", v.Name)
+	}
+
+	switch v.Type {
+	case "":
+		v.Type = TemplateVariableString
+	case TemplateVariableString, TemplateVariableBool:
+	case TemplateVariableChoice:
+		if len(v.Options) == 0 {
+			return fmt.Errorf("variable %s: a choice needs options", v.Name)
+		}
+	default:
+		return fmt.Errorf("variable %s: unknown type %q", v.Name, v.Type)
+	}
+
+	if v.Pattern != "" {
+		if v.pattern, err = regexp.Compile("^(?:" + v.Pattern + ")$"); err != nil {
+			return fmt.Errorf("variable %s: invalid pattern: %v", v.Name, err)
+		}
+	}
+
+	if v.Default != "" {
+		if reason := v.check(v.Default); reason != "" {
+			return fmt.Errorf("variable %s: default %s", v.Name, reason)
+		}
+	}
+	return nil
+}
+
+// check returns the reason why value is not acceptable or an empty string if it is
+func (v *TemplateVariable) check(value string) string {
+	if value == "" {
+		if v.Required {
+			return "is required"
+		}
+		return ""
+	}
+
+	switch v.Type {
+	case TemplateVariableBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return "must be true or false"
+		}
+	case TemplateVariableChoice:
+		found := false
+		for _, option := range v.Options {
+			if option == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "must be one of " + strings.Join(v.Options, ", ")
+		}
+	}
+
+	if v.pattern != nil && !v.pattern.MatchString(value) {
+		return "must match " + v.Pattern
+	}
+	return ""
+}
+
+// templateCondition is a parsed "if" of a template file rule. It is either
+// "NAME", "!NAME", "NAME == value" or "NAME != value".
+type templateCondition struct {
+	Name   string
+	Negate bool
+	Op     string
+	Value  string
+}
+
+func parseTemplateCondition(s string) (*templateCondition, error) {
+	cond := &templateCondition{}
+	expr := strings.TrimSpace(s)
+	for _, op := range []string{"==", "!="} {
+		if idx := strings.Index(expr, op); idx >= 0 {
+			cond.Name = strings.TrimSpace(expr[:idx])
+			cond.Op = op
+			cond.Value = strings.Trim(strings.TrimSpace(expr[idx+len(op):]), `"'`)
+			break
+		}
+	}
+	if cond.Op == "" {
+		if strings.HasPrefix(expr, "!") {
+			cond.Negate = true
+			expr = strings.TrimSpace(expr[1:])
+		}
+		cond.Name = expr
+	}
+
+	if !templateVariableNamePattern.MatchString(cond.Name) {
+		return nil, fmt.Errorf("invalid condition %q", s)
+	}
+	return cond, nil
+}
+
+func (cond *templateCondition) eval(variables map[string]string) bool {
+	value := variables[cond.Name]
+	switch cond.Op {
+	case "==":
+		return value == cond.Value
+	case "!=":
+		return value != cond.Value
+	}
+
+	isTrue := value != ""
+	if b, err := strconv.ParseBool(value); err == nil {
+		isTrue = b
+	}
+	return isTrue != cond.Negate
+}
+
+// TemplateFileRule includes the files and directories matching Glob only if
+// the condition If holds for the entered variables
+type TemplateFileRule struct {
+	Glob string `yaml:"glob"`
+	If   string `yaml:"if"`
+
+	glob glob.Glob
+	cond *templateCondition
+}
+
+// TemplateConfig holds the variables and file rules declared in a
+// .gitea/template.yaml file
+type TemplateConfig struct {
+	Path      string              `yaml:"-"`
+	Variables []*TemplateVariable `yaml:"variables"`
+	Files     []*TemplateFileRule `yaml:"files"`
+}
+
+// ParseTemplateConfig parses and validates the content of a .gitea/template.yaml file
+func ParseTemplateConfig(content []byte) (*TemplateConfig, error) {
+	config := &TemplateConfig{}
+	if err := yaml.Unmarshal(content, config); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(config.Variables))
+	for _, v := range config.Variables {
+		if err := v.init(); err != nil {
+			return nil, err
+		}
+		if seen[v.Name] {
+			return nil, fmt.Errorf("variable %s is declared twice", v.Name)
+		}
+		seen[v.Name] = true
+	}
+
+	for _, rule := range config.Files {
+		var err error
+		if rule.glob, err = glob.Compile(rule.Glob, '/'); err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %v", rule.Glob, err)
+		}
+		if rule.cond, err = parseTemplateCondition(rule.If); err != nil {
+			return nil, err
+		}
+		if !seen[rule.cond.Name] {
+			return nil, fmt.Errorf("condition %q uses the undeclared variable %s", rule.If, rule.cond.Name)
+		}
+	}
+	return config, nil
+}
+
+// Variable returns the declared variable with the given name or nil
+func (config *TemplateConfig) Variable(name string) *TemplateVariable {
+	if config == nil {
+		return nil
+	}
+	for _, v := range config.Variables {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// ResolveVariables validates the entered values against the declared variables,
+// applies defaults and returns the value of every declared variable
+func (config *TemplateConfig) ResolveVariables(values map[string]string) (map[string]string, error) {
+	for name := range values {
+		if config.Variable(name) == nil {
+			return nil, ErrTemplateVariableInvalid{Name: name, Reason: "is not declared by the template"}
+		}
+	}
+
+	resolved := make(map[string]string)
+	if config == nil {
+		return resolved, nil
+	}
+	for _, v := range config.Variables {
+		value := strings.TrimSpace(values[v.Name])
+		if value == "" {
+			value = v.Default
+		}
+		if reason := v.check(value); reason != "" {
+			return nil, ErrTemplateVariableInvalid{Name: v.Name, Reason: reason}
+		}
+		if v.IsBool() {
+			b, _ := strconv.ParseBool(value)
+			value = strconv.FormatBool(b)
+		}
+		resolved[v.Name] = value
+	}
+	return resolved, nil
+}
+
+// IsIncluded returns false if a file rule matching path excludes it for the given variables
+func (config *TemplateConfig) IsIncluded(path string, variables map[string]string) bool {
+	if config == nil {
+		return true
+	}
+	for _, rule := range config.Files {
+		if rule.glob.Match(path) && !rule.cond.eval(variables) {
+			return false
+		}
+	}
+	return true
+}
+
+// TemplateVariableValue is the value a variable had when a repository was generated
+type TemplateVariableValue struct {
+	Name  string
+	Value string
+}
+
+// RepoGeneration records the template commit and the variables a repository
+// was generated with, so that later template changes can be synced into it
+type RepoGeneration struct {
+	ID               int64                    `xorm:"pk autoincr"`
+	RepoID           int64                    `xorm:"UNIQUE"`
+	TemplateID       int64                    `xorm:"INDEX"`
+	TemplateCommitID string                   `xorm:"VARCHAR(40)"`
+	Variables        []*TemplateVariableValue `xorm:"TEXT JSON"`
+
+	// SyncCommitID is the template commit proposed by the sync pull request SyncPullID
+	SyncCommitID string `xorm:"VARCHAR(40)"`
+	SyncPullID   int64
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+func init() {
+	db.RegisterModel(new(RepoGeneration))
+}
+
+// VariablesMap returns the recorded variables as a map
+func (g *RepoGeneration) VariablesMap() map[string]string {
+	variables := make(map[string]string, len(g.Variables))
+	for _, v := range g.Variables {
+		variables[v.Name] = v.Value
+	}
+	return variables
+}
+
+// InsertRepoGeneration records how a repository was generated
+func InsertRepoGeneration(ctx context.Context, repoID, templateID int64, templateCommitID string, variables map[string]string) error {
+	g := &RepoGeneration{
+		RepoID:           repoID,
+		TemplateID:       templateID,
+		TemplateCommitID: templateCommitID,
+		Variables:        make([]*TemplateVariableValue, 0, len(variables)),
+	}
+	for name, value := range variables {
+		g.Variables = append(g.Variables, &TemplateVariableValue{Name: name, Value: value})
+	}
+	sort.Slice(g.Variables, func(i, j int) bool {
+		return g.Variables[i].Name < g.Variables[j].Name
+	})
+	_, err := db.GetEngine(ctx).Insert(g)
+	return err
+}
+
+// GetRepoGeneration returns how the repository was generated, or nil if it
+// was not generated with git content from a template
+func GetRepoGeneration(repoID int64) (*RepoGeneration, error) {
+	g := new(RepoGeneration)
+	has, err := db.GetEngine(db.DefaultContext).Where("repo_id = ?", repoID).Get(g)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return g, nil
+}
+
+// UpdateRepoGenerationCols updates the given columns of a repository generation
+func UpdateRepoGenerationCols(g *RepoGeneration, cols ...string) error {
+	_, err := db.GetEngine(db.DefaultContext).ID(g.ID).Cols(cols...).Update(g)
+	return err
+}
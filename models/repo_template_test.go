@@ -0,0 +1,124 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testTemplateConfig = `
+variables:
+  - name: SERVICE
+    prompt: Service name
+    required: true
+    pattern: "[a-z]+"
+  - name: DATABASE
+    type: choice
+    options: [postgres, mysql]
+    default: postgres
+  - name: DOCKER
+    type: bool
+    default: "true"
+files:
+  - glob: "docker/**"
+    if: DOCKER
+  - glob: "mysql/**"
+    if: DATABASE == mysql
+  - glob: "NO_DOCKER.md"
+    if: "!DOCKER"
+`
+
+func TestParseTemplateConfig(t *testing.T) {
+	config, err := ParseTemplateConfig([]byte(testTemplateConfig))
+	assert.NoError(t, err)
+	assert.Len(t, config.Variables, 3)
+	assert.Equal(t, TemplateVariableString, config.Variable("SERVICE").Type)
+	assert.True(t, config.Variable("DOCKER").IsBool())
+	assert.Nil(t, config.Variable("MISSING"))
+
+	for _, invalid := range []string{
+		"variables:\n  - name: lower\n",
+		"variables:\n  - name: REPO_NAME\n",
+		"variables:\n  - name: A\n  - name: A\n",
+		"variables:\n  - name: A\n    type: number\n",
+		"variables:\n  - name: A\n    type: choice\n",
+		"variables:\n  - name: A\n    pattern: \"[0-9]+\"\n    default: abc\n",
+		"files:\n  - glob: \"**\"\n    if: UNDECLARED\n",
+	} {
+		_, err := ParseTemplateConfig([]byte(invalid))
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestTemplateConfigResolveVariables(t *testing.T) {
+	config, err := ParseTemplateConfig([]byte(testTemplateConfig))
+	assert.NoError(t, err)
+
+	variables, err := config.ResolveVariables(map[string]string{"SERVICE": "api", "DOCKER": "0"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"SERVICE": "api", "DATABASE": "postgres", "DOCKER": "false"}, variables)
+
+	for _, invalid := range []map[string]string{
+		{},
+		{"SERVICE": "API"},
+		{"SERVICE": "api", "DATABASE": "oracle"},
+		{"SERVICE": "api", "DOCKER": "maybe"},
+		{"SERVICE": "api", "UNDECLARED": "x"},
+	} {
+		_, err := config.ResolveVariables(invalid)
+		assert.True(t, IsErrTemplateVariableInvalid(err), "%v", invalid)
+	}
+
+	var noConfig *TemplateConfig
+	variables, err = noConfig.ResolveVariables(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, variables)
+	_, err = noConfig.ResolveVariables(map[string]string{"SERVICE": "api"})
+	assert.True(t, IsErrTemplateVariableInvalid(err))
+}
+
+func TestTemplateConfigIsIncluded(t *testing.T) {
+	config, err := ParseTemplateConfig([]byte(testTemplateConfig))
+	assert.NoError(t, err)
+
+	variables := map[string]string{"SERVICE": "api", "DATABASE": "postgres", "DOCKER": "true"}
+	assert.True(t, config.IsIncluded("docker/Dockerfile", variables))
+	assert.False(t, config.IsIncluded("mysql/schema.sql", variables))
+	assert.False(t, config.IsIncluded("NO_DOCKER.md", variables))
+	assert.True(t, config.IsIncluded("main.go", variables))
+
+	variables = map[string]string{"SERVICE": "api", "DATABASE": "mysql", "DOCKER": "false"}
+	assert.False(t, config.IsIncluded("docker/Dockerfile", variables))
+	assert.True(t, config.IsIncluded("mysql/schema.sql", variables))
+	assert.True(t, config.IsIncluded("NO_DOCKER.md", variables))
+}
+
+func TestRepoGeneration(t *testing.T) {
+	assert.NoError(t, db.PrepareTestDatabase())
+
+	assert.NoError(t, InsertRepoGeneration(db.DefaultContext, 2, 1, "65f1bf27bc3bf70f64657658635e66094edbcb4d",
+		map[string]string{"B": "2", "A": "1"}))
+
+	g, err := GetRepoGeneration(2)
+	assert.NoError(t, err)
+	assert.NotNil(t, g)
+	assert.EqualValues(t, 1, g.TemplateID)
+	assert.Equal(t, "A", g.Variables[0].Name)
+	assert.Equal(t, map[string]string{"A": "1", "B": "2"}, g.VariablesMap())
+
+	g.SyncPullID = 3
+	assert.NoError(t, UpdateRepoGenerationCols(g, "sync_pull_id"))
+	g, err = GetRepoGeneration(2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, g.SyncPullID)
+
+	g, err = GetRepoGeneration(3)
+	assert.NoError(t, err)
+	assert.Nil(t, g)
+}
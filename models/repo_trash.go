@@ -0,0 +1,175 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// IsDeleted returns true if the repository is in the trash
+func (repo *Repository) IsDeleted() bool {
+	return repo.DeletedUnix != 0
+}
+
+// PurgeUnix returns when a repository in the trash is deleted permanently
+func (repo *Repository) PurgeUnix() timeutil.TimeStamp {
+	return repo.DeletedUnix.AddDuration(setting.Repository.TrashPeriod)
+}
+
+// TrashRepository moves a repository to the trash. It is hidden like a deleted repository,
+// but its name stays taken and it can be restored until it is deleted permanently.
+// A pending transfer of the repository is cancelled.
+func TrashRepository(doer *User, repo *Repository) error {
+	sess := db.NewSession(db.DefaultContext)
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	repo.DeletedUnix = timeutil.TimeStampNow()
+	repo.DeletedByID = doer.ID
+	if cnt, err := sess.ID(repo.ID).Where("deleted_unix = 0").Cols("deleted_unix", "deleted_by_id").NoAutoTime().Update(repo); err != nil {
+		return err
+	} else if cnt != 1 {
+		return ErrRepoNotExist{repo.ID, repo.OwnerID, "", repo.Name}
+	}
+
+	if repo.Status == RepositoryPendingTransfer {
+		repo.Status = RepositoryReady
+		if err := updateRepositoryCols(sess, repo, "status"); err != nil {
+			return err
+		}
+		if err := deleteRepositoryTransfer(sess, repo.ID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := sess.Exec("UPDATE `user` SET num_repos=num_repos-1 WHERE id=?", repo.OwnerID); err != nil {
+		return err
+	}
+	if repo.IsFork {
+		if _, err := sess.Exec("UPDATE `repository` SET num_forks=num_forks-1 WHERE id=?", repo.ForkID); err != nil {
+			return err
+		}
+	}
+
+	return sess.Commit()
+}
+
+// RestoreRepository takes a repository out of the trash
+func RestoreRepository(repo *Repository) error {
+	sess := db.NewSession(db.DefaultContext)
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	repo.DeletedUnix = 0
+	repo.DeletedByID = 0
+	if cnt, err := sess.ID(repo.ID).Where("deleted_unix <> 0").Cols("deleted_unix", "deleted_by_id").NoAutoTime().Update(repo); err != nil {
+		return err
+	} else if cnt != 1 {
+		return ErrRepoNotExist{repo.ID, repo.OwnerID, "", repo.Name}
+	}
+
+	if _, err := sess.Exec("UPDATE `user` SET num_repos=num_repos+1 WHERE id=?", repo.OwnerID); err != nil {
+		return err
+	}
+	if repo.IsFork {
+		if _, err := sess.Exec("UPDATE `repository` SET num_forks=num_forks+1 WHERE id=?", repo.ForkID); err != nil {
+			return err
+		}
+	}
+
+	return sess.Commit()
+}
+
+// GetDeletedRepositoryByOwnerAndName returns the repository in the trash with the given owner and name
+func GetDeletedRepositoryByOwnerAndName(ownerName, repoName string) (*Repository, error) {
+	var repo Repository
+	has, err := db.GetEngine(db.DefaultContext).Table("repository").Select("repository.*").
+		Join("INNER", "`user`", "`user`.id = repository.owner_id").
+		Where("repository.lower_name = ?", strings.ToLower(repoName)).
+		And("`user`.lower_name = ?", strings.ToLower(ownerName)).
+		And("repository.deleted_unix <> 0").
+		Get(&repo)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrRepoNotExist{0, 0, ownerName, repoName}
+	}
+	return &repo, nil
+}
+
+// isPullHeadRepoInTrash returns true if the head repository of the pull request of the issue is in the trash
+func isPullHeadRepoInTrash(e db.Engine, issueID int64) (bool, error) {
+	return e.Table("pull_request").
+		Join("INNER", "repository", "repository.id = pull_request.head_repo_id").
+		Where("pull_request.issue_id = ?", issueID).
+		And("repository.deleted_unix <> 0").
+		Exist()
+}
+
+// getRepositoryByIDIncludingDeleted returns the repository with the given id, whether it is in the trash or not
+func getRepositoryByIDIncludingDeleted(e db.Engine, id int64) (*Repository, error) {
+	repo := new(Repository)
+	has, err := e.ID(id).Get(repo)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrRepoNotExist{id, 0, "", ""}
+	}
+	return repo, nil
+}
+
+// GetDeletedRepositoryByID returns the repository in the trash with the given id
+func GetDeletedRepositoryByID(id int64) (*Repository, error) {
+	repo := new(Repository)
+	has, err := db.GetEngine(db.DefaultContext).ID(id).Where("deleted_unix <> 0").Get(repo)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrRepoNotExist{id, 0, "", ""}
+	}
+	return repo, nil
+}
+
+// FindDeletedReposOptions represents the options to find repositories in the trash
+type FindDeletedReposOptions struct {
+	db.ListOptions
+	OwnerID       int64
+	DeletedBefore timeutil.TimeStamp
+}
+
+func (opts *FindDeletedReposOptions) toCond() builder.Cond {
+	cond := builder.Neq{"deleted_unix": 0}.And()
+	if opts.OwnerID != 0 {
+		cond = cond.And(builder.Eq{"owner_id": opts.OwnerID})
+	}
+	if opts.DeletedBefore != 0 {
+		cond = cond.And(builder.Lt{"deleted_unix": opts.DeletedBefore})
+	}
+	return cond
+}
+
+// FindDeletedRepositories returns the repositories in the trash, the most recently deleted first
+func FindDeletedRepositories(opts *FindDeletedReposOptions) (RepositoryList, int64, error) {
+	sess := db.GetEngine(db.DefaultContext).Where(opts.toCond()).Desc("deleted_unix")
+	if opts.Page != 0 {
+		sess = db.SetSessionPagination(sess, opts)
+	}
+	repos := make(RepositoryList, 0, opts.PageSize)
+	count, err := sess.FindAndCount(&repos)
+	if err != nil {
+		return nil, 0, err
+	}
+	return repos, count, repos.loadAttributes(db.GetEngine(db.DefaultContext))
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrashAndRestoreRepository(t *testing.T) {
+	assert.NoError(t, db.PrepareTestDatabase())
+
+	doer := db.AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	repo := db.AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	owner := db.AssertExistsAndLoadBean(t, &User{ID: repo.OwnerID}).(*User)
+
+	assert.NoError(t, TrashRepository(doer, repo))
+	assert.True(t, repo.IsDeleted())
+	assert.EqualValues(t, doer.ID, repo.DeletedByID)
+	assert.True(t, IsErrRepoNotExist(TrashRepository(doer, repo)))
+
+	_, err := GetRepositoryByOwnerAndName(owner.Name, repo.Name)
+	assert.True(t, IsErrRepoNotExist(err))
+	_, err = GetRepositoryByName(owner.ID, repo.Name)
+	assert.True(t, IsErrRepoNotExist(err))
+	exist, err := IsRepositoryExist(owner, repo.Name)
+	assert.NoError(t, err)
+	assert.True(t, exist, "the name of a repository in the trash stays taken")
+
+	deleted, err := GetDeletedRepositoryByOwnerAndName(owner.Name, repo.Name)
+	assert.NoError(t, err)
+	assert.EqualValues(t, repo.ID, deleted.ID)
+
+	repos, count, err := FindDeletedRepositories(&FindDeletedReposOptions{OwnerID: owner.ID})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	if assert.Len(t, repos, 1) {
+		assert.EqualValues(t, repo.ID, repos[0].ID)
+	}
+	_, count, err = FindDeletedRepositories(&FindDeletedReposOptions{DeletedBefore: repo.DeletedUnix})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, count)
+
+	db.AssertExistsAndLoadBean(t, &User{ID: owner.ID, NumRepos: owner.NumRepos - 1})
+	CheckConsistencyFor(t, &User{})
+
+	assert.NoError(t, RestoreRepository(repo))
+	assert.False(t, repo.IsDeleted())
+	assert.True(t, IsErrRepoNotExist(RestoreRepository(repo)))
+
+	_, err = GetRepositoryByOwnerAndName(owner.Name, repo.Name)
+	assert.NoError(t, err)
+	db.AssertExistsAndLoadBean(t, &User{ID: owner.ID, NumRepos: owner.NumRepos})
+	CheckConsistencyFor(t, &User{})
+}
+
+func TestDeleteTrashedRepository(t *testing.T) {
+	assert.NoError(t, db.PrepareTestDatabase())
+
+	doer := db.AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	repo := db.AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+
+	assert.NoError(t, TrashRepository(doer, repo))
+	assert.NoError(t, DeleteRepository(doer, repo.OwnerID, repo.ID))
+	db.AssertNotExistsBean(t, &Repository{ID: repo.ID})
+	CheckConsistencyFor(t, &User{})
+
+	_, count, err := FindDeletedRepositories(&FindDeletedReposOptions{DeletedBefore: timeutil.TimeStampNow() + 1})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, count)
+}
@@ -233,7 +233,8 @@ func deleteDeployKey(sess db.Engine, doer *User, id int64) error {
 
 	// Check if user has access to delete this key.
 	if !doer.IsAdmin {
-		repo, err := getRepositoryByID(sess, key.RepoID)
+		// the key is also deleted when its repository is purged from the trash
+		repo, err := getRepositoryByIDIncludingDeleted(sess, key.RepoID)
 		if err != nil {
 			return fmt.Errorf("GetRepositoryByID: %v", err)
 		}
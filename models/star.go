@@ -96,6 +96,7 @@ func (u *User) GetStarredRepos(private bool, page, pageSize int, orderBy string)
 	sess := db.GetEngine(db.DefaultContext).
 		Join("INNER", "star", "star.repo_id = repository.id").
 		Where("star.uid = ?", u.ID).
+		And("repository.deleted_unix = 0").
 		OrderBy(orderBy)
 
 	if !private {
@@ -124,7 +125,8 @@ func (u *User) GetStarredRepos(private bool, page, pageSize int, orderBy string)
 func (u *User) GetStarredRepoCount(private bool) (int64, error) {
 	sess := db.GetEngine(db.DefaultContext).
 		Join("INNER", "star", "star.repo_id = repository.id").
-		Where("star.uid = ?", u.ID)
+		Where("star.uid = ?", u.ID).
+		And("repository.deleted_unix = 0")
 
 	if !private {
 		sess = sess.And("is_private = ?", false)
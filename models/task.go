@@ -237,6 +237,7 @@ func FinishMigrateTask(task *Task) error {
 	task.EndTime = timeutil.TimeStampNow()
 
 	// delete credentials when we're done, they're a liability.
+	// Mirrors syncing metadata keep them encrypted as every sync needs them again.
 	conf, err := task.MigrateConfig()
 	if err != nil {
 		return err
@@ -244,9 +245,11 @@ func FinishMigrateTask(task *Task) error {
 	conf.AuthPassword = ""
 	conf.AuthToken = ""
 	conf.CloneAddr = util.NewStringURLSanitizer(conf.CloneAddr, true).Replace(conf.CloneAddr)
-	conf.AuthPasswordEncrypted = ""
-	conf.AuthTokenEncrypted = ""
-	conf.CloneAddrEncrypted = ""
+	if !conf.Mirror || !conf.MirrorMetadata {
+		conf.AuthPasswordEncrypted = ""
+		conf.AuthTokenEncrypted = ""
+		conf.CloneAddrEncrypted = ""
+	}
 	confBytes, err := json.Marshal(conf)
 	if err != nil {
 		return err
@@ -264,3 +267,108 @@ func FinishMigrateTask(task *Task) error {
 
 	return sess.Commit()
 }
+
+// BulkRepoPayload holds the options of a bulk repository task and the results
+// of the repositories it has been applied to so far
+type BulkRepoPayload struct {
+	Options structs.BulkRepoOption
+	Results []*structs.BulkRepoResult
+}
+
+// BulkRepoPayload returns the options and results of a bulk repository task
+func (task *Task) BulkRepoPayload() (*BulkRepoPayload, error) {
+	if task.Type != structs.TaskTypeBulkRepo {
+		return nil, fmt.Errorf("Task type is %s, not Bulk Repository Operation", task.Type.Name())
+	}
+	payload := &BulkRepoPayload{}
+	if err := json.Unmarshal([]byte(task.PayloadContent), payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// SetBulkRepoPayload stores the options and results of a bulk repository task in its payload
+func (task *Task) SetBulkRepoPayload(payload *BulkRepoPayload) error {
+	content, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	task.PayloadContent = string(content)
+	return nil
+}
+
+// GetBulkRepoTaskByID returns a bulk repository task. If ownerID is not zero
+// the task must have been started for that organization.
+func GetBulkRepoTaskByID(id, ownerID int64) (*Task, error) {
+	task := Task{
+		ID:      id,
+		OwnerID: ownerID,
+		Type:    structs.TaskTypeBulkRepo,
+	}
+	has, err := db.GetEngine(db.DefaultContext).Get(&task)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrTaskDoesNotExist{id, 0, task.Type}
+	}
+	return &task, nil
+}
+
+// FindBulkRepoTasks returns the bulk repository tasks started for an organization,
+// or all of them if ownerID is zero, newest first
+func FindBulkRepoTasks(ownerID int64, listOptions db.ListOptions) ([]*Task, int64, error) {
+	cond := builder.NewCond().And(builder.Eq{"type": structs.TaskTypeBulkRepo})
+	if ownerID != 0 {
+		cond = cond.And(builder.Eq{"owner_id": ownerID})
+	}
+
+	sess := db.GetEngine(db.DefaultContext).Where(cond).Desc("id")
+	if listOptions.Page != 0 {
+		sess = db.SetSessionPagination(sess, &listOptions)
+	}
+	tasks := make([]*Task, 0, listOptions.PageSize)
+	count, err := sess.FindAndCount(&tasks)
+	return tasks, count, err
+}
+
+// IssueImportPayload holds the uploaded export and the mapping of an issue import task
+type IssueImportPayload struct {
+	Format         string
+	UploadUUID     string
+	FileName       string
+	NumIssues      int
+	ClosedStatuses []string
+	LabelFields    []string
+}
+
+// IssueImportPayload returns the export and mapping of an issue import task
+func (task *Task) IssueImportPayload() (*IssueImportPayload, error) {
+	if task.Type != structs.TaskTypeIssueImport {
+		return nil, fmt.Errorf("Task type is %s, not Issue Import", task.Type.Name())
+	}
+	payload := &IssueImportPayload{}
+	if err := json.Unmarshal([]byte(task.PayloadContent), payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// SetIssueImportPayload stores the export and mapping of an issue import task in its payload
+func (task *Task) SetIssueImportPayload(payload *IssueImportPayload) error {
+	content, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	task.PayloadContent = string(content)
+	return nil
+}
+
+// FindIssueImportTasks returns the latest issue import tasks of a repository, newest first
+func FindIssueImportTasks(repoID int64, limit int) ([]*Task, error) {
+	tasks := make([]*Task, 0, limit)
+	return tasks, db.GetEngine(db.DefaultContext).
+		Where("repo_id = ? AND type = ?", repoID, structs.TaskTypeIssueImport).
+		Desc("id").
+		Limit(limit).
+		Find(&tasks)
+}
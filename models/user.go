@@ -75,6 +75,15 @@ const (
 	EmailNotificationsDisabled = "disabled"
 )
 
+const (
+	// EmailDigestImmediate indicates that the user would like to receive every email notification as it happens
+	EmailDigestImmediate = "immediate"
+	// EmailDigestDaily indicates that the user would like to receive the email notifications once a day
+	EmailDigestDaily = "daily"
+	// EmailDigestWeekly indicates that the user would like to receive the email notifications once a week
+	EmailDigestWeekly = "weekly"
+)
+
 var (
 	// ErrEmailNotActivated e-mail address has not been activated error
 	ErrEmailNotActivated = errors.New("E-mail address has not been activated")
@@ -102,8 +111,13 @@ type User struct {
 	Email                        string `xorm:"NOT NULL"`
 	KeepEmailPrivate             bool
 	EmailNotificationsPreference string `xorm:"VARCHAR(20) NOT NULL DEFAULT 'enabled'"`
-	Passwd                       string `xorm:"NOT NULL"`
-	PasswdHashAlgo               string `xorm:"NOT NULL DEFAULT 'argon2'"`
+	EmailNotificationsDigest     string `xorm:"VARCHAR(20) NOT NULL DEFAULT 'immediate'"`
+	// EmailDigestImmediateMentions keeps sending mentions and review requests immediately when a digest is chosen
+	EmailDigestImmediateMentions bool `xorm:"NOT NULL DEFAULT true"`
+	// EncryptEmailNotifications encrypts the emails to the GPG keys of the user
+	EncryptEmailNotifications bool   `xorm:"NOT NULL DEFAULT false"`
+	Passwd                    string `xorm:"NOT NULL"`
+	PasswdHashAlgo            string `xorm:"NOT NULL DEFAULT 'argon2'"`
 
 	// MustChangePassword is an attribute that determines if a user
 	// is to change his/her password after registration.
@@ -166,11 +180,16 @@ type User struct {
 	MembersIsPublic           map[int64]bool      `xorm:"-"`
 	Visibility                structs.VisibleType `xorm:"NOT NULL DEFAULT 0"`
 	RepoAdminChangeTeamAccess bool                `xorm:"NOT NULL DEFAULT false"`
+	DeletedUnix               timeutil.TimeStamp  `xorm:"INDEX NOT NULL DEFAULT 0"`
+	DeletedByID               int64               `xorm:"NOT NULL DEFAULT 0"`
 
 	// Preferences
 	DiffViewStyle       string `xorm:"NOT NULL DEFAULT ''"`
 	Theme               string `xorm:"NOT NULL DEFAULT ''"`
 	KeepActivityPrivate bool   `xorm:"NOT NULL DEFAULT false"`
+
+	// repoShardRoot caches the root path of the repository shard of the user
+	repoShardRoot string
 }
 
 func init() {
@@ -569,7 +588,7 @@ func (u *User) GetRepositoryIDs(units ...UnitType) ([]int64, error) {
 		sess = sess.In("repo_unit.type", units)
 	}
 
-	return ids, sess.Where("owner_id = ?", u.ID).Find(&ids)
+	return ids, sess.Where("owner_id = ?", u.ID).And("repository.deleted_unix = 0").Find(&ids)
 }
 
 // GetActiveRepositoryIDs returns non-archived repositories IDs where user owned and has unittypes
@@ -584,7 +603,7 @@ func (u *User) GetActiveRepositoryIDs(units ...UnitType) ([]int64, error) {
 		sess = sess.In("repo_unit.type", units)
 	}
 
-	sess.Where(builder.Eq{"is_archived": false})
+	sess.Where(builder.Eq{"is_archived": false, "`repository`.deleted_unix": 0})
 
 	return ids, sess.Where("owner_id = ?", u.ID).GroupBy("repository.id").Find(&ids)
 }
@@ -599,6 +618,7 @@ func (u *User) GetOrgRepositoryIDs(units ...UnitType) ([]int64, error) {
 		Join("INNER", "team_user", "repository.owner_id = team_user.org_id").
 		Join("INNER", "team_repo", "(? != ? and repository.is_private != ?) OR (team_user.team_id = team_repo.team_id AND repository.id = team_repo.repo_id)", true, u.IsRestricted, true).
 		Where("team_user.uid = ?", u.ID).
+		And("repository.deleted_unix = 0").
 		GroupBy("repository.id").Find(&ids); err != nil {
 		return nil, err
 	}
@@ -620,7 +640,7 @@ func (u *User) GetActiveOrgRepositoryIDs(units ...UnitType) ([]int64, error) {
 		Join("INNER", "team_user", "repository.owner_id = team_user.org_id").
 		Join("INNER", "team_repo", "(? != ? and repository.is_private != ?) OR (team_user.team_id = team_repo.team_id AND repository.id = team_repo.repo_id)", true, u.IsRestricted, true).
 		Where("team_user.uid = ?", u.ID).
-		Where(builder.Eq{"is_archived": false}).
+		Where(builder.Eq{"is_archived": false, "`repository`.deleted_unix": 0}).
 		GroupBy("repository.id").Find(&ids); err != nil {
 		return nil, err
 	}
@@ -739,6 +759,35 @@ func (u *User) SetEmailNotifications(set string) error {
 	return nil
 }
 
+// EmailDigest returns the User's email notification delivery preference
+func (u *User) EmailDigest() string {
+	if u.EmailNotificationsDigest == "" {
+		return EmailDigestImmediate
+	}
+	return u.EmailNotificationsDigest
+}
+
+// SetEmailDigest sets the user's email notification delivery preference
+func (u *User) SetEmailDigest(digest string, immediateMentions bool) error {
+	u.EmailNotificationsDigest = digest
+	u.EmailDigestImmediateMentions = immediateMentions
+	if err := UpdateUserCols(u, "email_notifications_digest", "email_digest_immediate_mentions"); err != nil {
+		log.Error("SetEmailDigest: %v", err)
+		return err
+	}
+	return nil
+}
+
+// SetEncryptEmailNotifications sets whether the emails to the user are encrypted to their GPG keys
+func (u *User) SetEncryptEmailNotifications(encrypt bool) error {
+	u.EncryptEmailNotifications = encrypt
+	if err := UpdateUserCols(u, "encrypt_email_notifications"); err != nil {
+		log.Error("SetEncryptEmailNotifications: %v", err)
+		return err
+	}
+	return nil
+}
+
 func isUserExist(e db.Engine, uid int64, name string) (bool, error) {
 	if len(name) == 0 {
 		return false, nil
@@ -881,6 +930,8 @@ func CreateUser(u *User, overwriteDefault ...*CreateUserOverwriteOptions) (err e
 	u.Visibility = setting.Service.DefaultUserVisibilityMode
 	u.AllowCreateOrganization = setting.Service.DefaultAllowCreateOrganization && !setting.Admin.DisableRegularOrgCreation
 	u.EmailNotificationsPreference = setting.Admin.DefaultEmailNotification
+	u.EmailNotificationsDigest = EmailDigestImmediate
+	u.EmailDigestImmediateMentions = true
 	u.MaxRepoCreation = -1
 	u.Theme = setting.UI.DefaultTheme
 
@@ -936,6 +987,10 @@ func CreateUser(u *User, overwriteDefault ...*CreateUserOverwriteOptions) (err e
 		return err
 	}
 
+	if err = newOwnerRepoShard(sess, u.Name); err != nil {
+		return err
+	}
+
 	// insert email address
 	if _, err := sess.Insert(&EmailAddress{
 		UID:         u.ID,
@@ -1039,8 +1094,21 @@ func ChangeUserName(u *User, newUserName string) (err error) {
 		return fmt.Errorf("Change repo owner name: %v", err)
 	}
 
+	// The repositories stay in the same shard
+	oldUserPath, err := userPath(sess, oldUserName)
+	if err != nil {
+		return err
+	}
+	if err = renameOwnerRepoShard(sess, oldUserName, newUserName); err != nil {
+		return fmt.Errorf("Change repository shard owner name: %v", err)
+	}
+	newUserPath, err := userPath(sess, newUserName)
+	if err != nil {
+		return err
+	}
+
 	// Do not fail if directory does not exist
-	if err = util.Rename(UserPath(oldUserName), UserPath(newUserName)); err != nil && !os.IsNotExist(err) {
+	if err = util.Rename(oldUserPath, newUserPath); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("Rename user directory: %v", err)
 	}
 
@@ -1049,7 +1117,7 @@ func ChangeUserName(u *User, newUserName string) (err error) {
 	}
 
 	if err = sess.Commit(); err != nil {
-		if err2 := util.Rename(UserPath(newUserName), UserPath(oldUserName)); err2 != nil && !os.IsNotExist(err2) {
+		if err2 := util.Rename(newUserPath, oldUserPath); err2 != nil && !os.IsNotExist(err2) {
 			log.Critical("Unable to rollback directory change during failed username change from: %s to: %s. DB Error: %v. Filesystem Error: %v", oldUserName, newUserName, err, err2)
 			return fmt.Errorf("failed to rollback directory change during failed username change from: %s to: %s. DB Error: %w. Filesystem Error: %v", oldUserName, newUserName, err, err2)
 		}
@@ -1216,6 +1284,7 @@ func deleteUser(e db.Engine, u *User) error {
 		&TeamUser{UID: u.ID},
 		&Collaboration{UserID: u.ID},
 		&Stopwatch{UserID: u.ID},
+		&MailDigestEntry{UserID: u.ID},
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
@@ -1294,12 +1363,18 @@ func deleteUser(e db.Engine, u *User) error {
 
 	// Note: There are something just cannot be roll back,
 	//	so just keep error logs of those operations.
-	path := UserPath(u.Name)
+	path, err := userPath(e, u.Name)
+	if err != nil {
+		return err
+	}
 	if err = util.RemoveAll(path); err != nil {
 		err = fmt.Errorf("Failed to RemoveAll %s: %v", path, err)
 		_ = createNotice(e, NoticeTask, fmt.Sprintf("delete user '%s': %v", u.Name, err))
 		return err
 	}
+	if err = deleteOwnerRepoShard(e, u.Name); err != nil {
+		return fmt.Errorf("deleteOwnerRepoShard: %v", err)
+	}
 
 	if len(u.Avatar) > 0 {
 		avatarPath := u.CustomAvatarRelativePath()
@@ -1375,7 +1450,12 @@ func DeleteInactiveUsers(ctx context.Context, olderThan time.Duration) (err erro
 
 // UserPath returns the path absolute path of user repositories.
 func UserPath(userName string) string {
-	return filepath.Join(setting.RepoRootPath, strings.ToLower(userName))
+	path, err := userPath(db.GetEngine(db.DefaultContext), userName)
+	if err != nil {
+		log.Error("UserPath: %v", err)
+		return filepath.Join(unavailableRepoShardRoot, strings.ToLower(userName))
+	}
+	return path
 }
 
 func getUserByID(e db.Engine, id int64) (*User, error) {
@@ -1404,7 +1484,7 @@ func getUserByName(e db.Engine, name string) (*User, error) {
 		return nil, ErrUserNotExist{0, name, 0}
 	}
 	u := &User{LowerName: strings.ToLower(name)}
-	has, err := e.Get(u)
+	has, err := e.Where("deleted_unix = 0").Get(u)
 	if err != nil {
 		return nil, err
 	} else if !has {
@@ -1619,7 +1699,7 @@ type SearchUserOptions struct {
 }
 
 func (opts *SearchUserOptions) toSearchQueryBase() (sess *xorm.Session) {
-	var cond builder.Cond = builder.Eq{"type": opts.Type}
+	var cond builder.Cond = builder.Eq{"type": opts.Type, "deleted_unix": 0}
 	if len(opts.Keyword) > 0 {
 		lowerKeyword := strings.ToLower(opts.Keyword)
 		keywordCond := builder.Or(
@@ -1728,6 +1808,7 @@ func SearchUsers(opts *SearchUserOptions) (users []*User, _ int64, _ error) {
 // GetStarredRepos returns the repos starred by a particular user
 func GetStarredRepos(userID int64, private bool, listOptions db.ListOptions) ([]*Repository, error) {
 	sess := db.GetEngine(db.DefaultContext).Where("star.uid=?", userID).
+		And("`repository`.deleted_unix = 0").
 		Join("LEFT", "star", "`repository`.id=`star`.repo_id")
 	if !private {
 		sess = sess.And("is_private=?", false)
@@ -1748,6 +1829,7 @@ func GetStarredRepos(userID int64, private bool, listOptions db.ListOptions) ([]
 func GetWatchedRepos(userID int64, private bool, listOptions db.ListOptions) ([]*Repository, int64, error) {
 	sess := db.GetEngine(db.DefaultContext).Where("watch.user_id=?", userID).
 		And("`watch`.mode<>?", RepoWatchModeDont).
+		And("`repository`.deleted_unix = 0").
 		Join("LEFT", "watch", "`repository`.id=`watch`.repo_id")
 	if !private {
 		sess = sess.And("is_private=?", false)
@@ -100,6 +100,16 @@ type HookEvents struct {
 	PullRequestSync      bool `json:"pull_request_sync"`
 	Repository           bool `json:"repository"`
 	Release              bool `json:"release"`
+	Wiki                 bool `json:"wiki"`
+	Status               bool `json:"status"`
+	Member               bool `json:"member"`
+	BranchProtection     bool `json:"branch_protection"`
+	Star                 bool `json:"star"`
+
+	// instance events, only available to system webhooks
+	User         bool `json:"user"`
+	Organization bool `json:"organization"`
+	LoginFailed  bool `json:"login_failed"`
 }
 
 // HookEvent represents events that will delivery hook.
@@ -127,6 +137,10 @@ const (
 	FEISHU     HookType = "feishu"
 	MATRIX     HookType = "matrix"
 	WECHATWORK HookType = "wechatwork"
+	MATTERMOST HookType = "mattermost"
+	ROCKETCHAT HookType = "rocketchat"
+	NTFY       HookType = "ntfy"
+	CUSTOM     HookType = "custom"
 )
 
 // HookStatus is the status of a web hook
@@ -155,6 +169,7 @@ type Webhook struct {
 	Type            HookType   `xorm:"VARCHAR(16) 'type'"`
 	Meta            string     `xorm:"TEXT"` // store hook-specific attributes
 	LastStatus      HookStatus // Last delivery status
+	FailureCount    int        `xorm:"NOT NULL DEFAULT 0"` // Consecutive failed delivery attempts
 
 	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
@@ -305,12 +320,60 @@ func (w *Webhook) HasRepositoryEvent() bool {
 		(w.ChooseEvents && w.HookEvents.Repository)
 }
 
+// HasWikiEvent returns if hook enabled wiki event.
+func (w *Webhook) HasWikiEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.Wiki)
+}
+
+// HasStatusEvent returns if hook enabled commit status event.
+func (w *Webhook) HasStatusEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.Status)
+}
+
+// HasMemberEvent returns if hook enabled member event.
+func (w *Webhook) HasMemberEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.Member)
+}
+
+// HasBranchProtectionEvent returns if hook enabled branch protection event.
+func (w *Webhook) HasBranchProtectionEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.BranchProtection)
+}
+
+// HasStarEvent returns if hook enabled star event.
+func (w *Webhook) HasStarEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.Star)
+}
+
+// HasUserEvent returns if hook enabled user event.
+func (w *Webhook) HasUserEvent() bool {
+	return w.IsSystemWebhook && (w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.User))
+}
+
+// HasOrganizationEvent returns if hook enabled organization event.
+func (w *Webhook) HasOrganizationEvent() bool {
+	return w.IsSystemWebhook && (w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.Organization))
+}
+
+// HasLoginFailedEvent returns if hook enabled login failed event.
+func (w *Webhook) HasLoginFailedEvent() bool {
+	return w.IsSystemWebhook && (w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.LoginFailed))
+}
+
 // EventCheckers returns event checkers
 func (w *Webhook) EventCheckers() []struct {
 	Has  func() bool
 	Type HookEventType
 } {
-	return []struct {
+	checkers := []struct {
 		Has  func() bool
 		Type HookEventType
 	}{
@@ -334,7 +397,23 @@ func (w *Webhook) EventCheckers() []struct {
 		{w.HasPullRequestSyncEvent, HookEventPullRequestSync},
 		{w.HasRepositoryEvent, HookEventRepository},
 		{w.HasReleaseEvent, HookEventRelease},
+		{w.HasWikiEvent, HookEventWiki},
+		{w.HasStatusEvent, HookEventStatus},
+		{w.HasMemberEvent, HookEventMember},
+		{w.HasBranchProtectionEvent, HookEventBranchProtection},
+		{w.HasStarEvent, HookEventStar},
+	}
+	if w.IsSystemWebhook {
+		checkers = append(checkers, []struct {
+			Has  func() bool
+			Type HookEventType
+		}{
+			{w.HasUserEvent, HookEventUser},
+			{w.HasOrganizationEvent, HookEventOrganization},
+			{w.HasLoginFailedEvent, HookEventLoginFailed},
+		}...)
 	}
+	return checkers
 }
 
 // EventsArray returns an array of hook events
@@ -401,6 +480,7 @@ type ListWebhookOptions struct {
 	RepoID   int64
 	OrgID    int64
 	IsActive util.OptionalBool
+	IsSystem util.OptionalBool
 }
 
 func (opts *ListWebhookOptions) toCond() builder.Cond {
@@ -414,6 +494,12 @@ func (opts *ListWebhookOptions) toCond() builder.Cond {
 	if !opts.IsActive.IsNone() {
 		cond = cond.And(builder.Eq{"webhook.is_active": opts.IsActive.IsTrue()})
 	}
+	if !opts.IsSystem.IsNone() {
+		cond = cond.And(builder.Eq{"webhook.is_system_webhook": opts.IsSystem.IsTrue()})
+		if opts.IsSystem.IsTrue() {
+			cond = cond.And(builder.Eq{"webhook.repo_id": 0, "webhook.org_id": 0})
+		}
+	}
 	return cond
 }
 
@@ -486,9 +572,18 @@ func UpdateWebhook(w *Webhook) error {
 	return err
 }
 
-// UpdateWebhookLastStatus updates last status of webhook.
+// UpdateWebhookLastStatus updates last status and consecutive failure count of webhook.
 func UpdateWebhookLastStatus(w *Webhook) error {
-	_, err := db.GetEngine(db.DefaultContext).ID(w.ID).Cols("last_status").Update(w)
+	_, err := db.GetEngine(db.DefaultContext).ID(w.ID).Cols("last_status", "failure_count").Update(w)
+	return err
+}
+
+// DeactivateWebhook deactivates the webhook and resets its failure count,
+// so that it starts from scratch once it is activated again.
+func DeactivateWebhook(w *Webhook) error {
+	w.IsActive = false
+	w.FailureCount = 0
+	_, err := db.GetEngine(db.DefaultContext).ID(w.ID).Cols("is_active", "failure_count").Update(w)
 	return err
 }
 
@@ -601,6 +696,14 @@ const (
 	HookEventPullRequestSync           HookEventType = "pull_request_sync"
 	HookEventRepository                HookEventType = "repository"
 	HookEventRelease                   HookEventType = "release"
+	HookEventWiki                      HookEventType = "wiki"
+	HookEventStatus                    HookEventType = "status"
+	HookEventMember                    HookEventType = "member"
+	HookEventBranchProtection          HookEventType = "branch_protection"
+	HookEventStar                      HookEventType = "star"
+	HookEventUser                      HookEventType = "user"
+	HookEventOrganization              HookEventType = "organization"
+	HookEventLoginFailed               HookEventType = "login_failed"
 )
 
 // Event returns the HookEventType as an event string
@@ -631,6 +734,22 @@ func (h HookEventType) Event() string {
 		return "repository"
 	case HookEventRelease:
 		return "release"
+	case HookEventWiki:
+		return "wiki"
+	case HookEventStatus:
+		return "status"
+	case HookEventMember:
+		return "member"
+	case HookEventBranchProtection:
+		return "branch_protection"
+	case HookEventStar:
+		return "star"
+	case HookEventUser:
+		return "user"
+	case HookEventOrganization:
+		return "organization"
+	case HookEventLoginFailed:
+		return "login_failed"
 	}
 	return ""
 }
@@ -662,6 +781,10 @@ type HookTask struct {
 	Delivered       int64
 	DeliveredString string `xorm:"-"`
 
+	// Retry info.
+	Attempts      int                `xorm:"NOT NULL DEFAULT 0"`
+	NextRetryUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+
 	// History info.
 	IsSucceed       bool
 	RequestContent  string        `xorm:"TEXT"`
@@ -720,6 +843,47 @@ func HookTasks(hookID int64, page int) ([]*HookTask, error) {
 		Find(&tasks)
 }
 
+// ListHookTasks returns a paginated list of hook tasks of a webhook, newest first.
+func ListHookTasks(hookID int64, opts db.ListOptions) ([]*HookTask, error) {
+	sess := db.GetEngine(db.DefaultContext).Where("hook_id=?", hookID).Desc("id")
+	if opts.Page != 0 {
+		sess = db.SetSessionPagination(sess, &opts)
+		tasks := make([]*HookTask, 0, opts.PageSize)
+		return tasks, sess.Find(&tasks)
+	}
+	tasks := make([]*HookTask, 0, 10)
+	return tasks, sess.Find(&tasks)
+}
+
+// CountHookTasks returns the number of hook tasks of a webhook.
+func CountHookTasks(hookID int64) (int64, error) {
+	return db.GetEngine(db.DefaultContext).Where("hook_id=?", hookID).Count(new(HookTask))
+}
+
+// GetHookTaskByID returns the hook task by given ID.
+func GetHookTaskByID(id int64) (*HookTask, error) {
+	t := new(HookTask)
+	has, err := db.GetEngine(db.DefaultContext).ID(id).Get(t)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrHookTaskNotExist{ID: id}
+	}
+	return t, nil
+}
+
+// GetHookTaskByHookID returns the hook task of the given webhook by its ID.
+func GetHookTaskByHookID(hookID, id int64) (*HookTask, error) {
+	t := &HookTask{ID: id, HookID: hookID}
+	has, err := db.GetEngine(db.DefaultContext).Get(t)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrHookTaskNotExist{ID: id, HookID: hookID}
+	}
+	return t, nil
+}
+
 // CreateHookTask creates a new hook task,
 // it handles conversion from Payload to PayloadContent.
 func CreateHookTask(t *HookTask) error {
@@ -743,24 +907,52 @@ func UpdateHookTask(t *HookTask) error {
 	return err
 }
 
-// FindUndeliveredHookTasks represents find the undelivered hook tasks
+// FindUndeliveredHookTasks represents find the undelivered hook tasks,
+// tasks waiting for a retry are not included.
 func FindUndeliveredHookTasks() ([]*HookTask, error) {
 	tasks := make([]*HookTask, 0, 10)
-	if err := db.GetEngine(db.DefaultContext).Where("is_delivered=?", false).Find(&tasks); err != nil {
+	if err := db.GetEngine(db.DefaultContext).Where("is_delivered=? AND next_retry_unix=0", false).Find(&tasks); err != nil {
 		return nil, err
 	}
 	return tasks, nil
 }
 
-// FindRepoUndeliveredHookTasks represents find the undelivered hook tasks of one repository
+// FindRepoUndeliveredHookTasks represents find the undelivered hook tasks of one repository,
+// tasks waiting for a retry are not included.
 func FindRepoUndeliveredHookTasks(repoID int64) ([]*HookTask, error) {
 	tasks := make([]*HookTask, 0, 5)
-	if err := db.GetEngine(db.DefaultContext).Where("repo_id=? AND is_delivered=?", repoID, false).Find(&tasks); err != nil {
+	if err := db.GetEngine(db.DefaultContext).Where("repo_id=? AND is_delivered=? AND next_retry_unix=0", repoID, false).Find(&tasks); err != nil {
 		return nil, err
 	}
 	return tasks, nil
 }
 
+// FindDueHookTaskRetries returns the IDs of the hook tasks whose retry is due at the given time
+func FindDueHookTaskRetries(now timeutil.TimeStamp) ([]int64, error) {
+	ids := make([]int64, 0, 10)
+	return ids, db.GetEngine(db.DefaultContext).Table("hook_task").
+		Where("is_delivered=? AND next_retry_unix>0 AND next_retry_unix<=?", false, now).
+		Cols("id").
+		Find(&ids)
+}
+
+// ClaimHookTaskRetry postpones a due retry of the hook task to the given lease time.
+// It returns false if the retry has been claimed or delivered by someone else already.
+func ClaimHookTaskRetry(t *HookTask, lease timeutil.TimeStamp) (bool, error) {
+	n, err := db.GetEngine(db.DefaultContext).
+		Where("id=? AND is_delivered=? AND next_retry_unix=?", t.ID, false, t.NextRetryUnix).
+		Cols("next_retry_unix").
+		Update(&HookTask{NextRetryUnix: lease})
+	if err != nil {
+		return false, err
+	}
+	if n == 0 {
+		return false, nil
+	}
+	t.NextRetryUnix = lease
+	return true, nil
+}
+
 // CleanupHookTaskTable deletes rows from hook_task as needed.
 func CleanupHookTaskTable(ctx context.Context, cleanupType HookTaskCleanupType, olderThan time.Duration, numberToKeep int) error {
 	log.Trace("Doing: CleanupHookTaskTable")
@@ -12,6 +12,7 @@ import (
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/modules/json"
 	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
 
 	"github.com/stretchr/testify/assert"
@@ -71,6 +72,7 @@ func TestWebhook_EventsArray(t *testing.T) {
 		"pull_request", "pull_request_assign", "pull_request_label", "pull_request_milestone",
 		"pull_request_comment", "pull_request_review_approved", "pull_request_review_rejected",
 		"pull_request_review_comment", "pull_request_sync", "repository", "release",
+		"wiki", "status", "member", "branch_protection", "star",
 	},
 		(&Webhook{
 			HookEvent: &HookEvent{SendEverything: true},
@@ -82,6 +84,25 @@ func TestWebhook_EventsArray(t *testing.T) {
 			HookEvent: &HookEvent{PushOnly: true},
 		}).EventsArray(),
 	)
+
+	// instance events are only offered by system webhooks
+	assert.Equal(t, []string{"user", "organization", "login_failed"},
+		(&Webhook{
+			IsSystemWebhook: true,
+			HookEvent: &HookEvent{
+				ChooseEvents: true,
+				HookEvents:   HookEvents{User: true, Organization: true, LoginFailed: true},
+			},
+		}).EventsArray(),
+	)
+	assert.Empty(t,
+		(&Webhook{
+			HookEvent: &HookEvent{
+				ChooseEvents: true,
+				HookEvents:   HookEvents{User: true, Organization: true, LoginFailed: true},
+			},
+		}).EventsArray(),
+	)
 }
 
 func TestCreateWebhook(t *testing.T) {
@@ -118,6 +139,23 @@ func TestGetWebhookByOrgID(t *testing.T) {
 	assert.True(t, IsErrWebhookNotExist(err))
 }
 
+func TestListSystemWebhooks(t *testing.T) {
+	assert.NoError(t, db.PrepareTestDatabase())
+	system := &Webhook{URL: "www.example.com/system", ContentType: ContentTypeJSON, IsSystemWebhook: true, IsActive: true, Events: `{"send_everything":true}`}
+	assert.NoError(t, CreateWebhook(system))
+	assert.NoError(t, CreateWebhook(&Webhook{URL: "www.example.com/default", ContentType: ContentTypeJSON, IsActive: true, Events: `{"send_everything":true}`}))
+
+	hooks, err := ListWebhooksByOpts(&ListWebhookOptions{IsSystem: util.OptionalBoolTrue})
+	assert.NoError(t, err)
+	if assert.Len(t, hooks, 1) {
+		assert.Equal(t, system.ID, hooks[0].ID)
+	}
+
+	count, err := CountWebhooksByOpts(&ListWebhookOptions{IsSystem: util.OptionalBoolTrue})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+}
+
 func TestGetActiveWebhooksByRepoID(t *testing.T) {
 	assert.NoError(t, db.PrepareTestDatabase())
 	hooks, err := ListWebhooksByOpts(&ListWebhookOptions{RepoID: 1, IsActive: util.OptionalBoolTrue})
@@ -227,6 +265,68 @@ func TestUpdateHookTask(t *testing.T) {
 	db.AssertExistsAndLoadBean(t, hook)
 }
 
+func TestFindDueHookTaskRetries(t *testing.T) {
+	assert.NoError(t, db.PrepareTestDatabase())
+	now := timeutil.TimeStampNow()
+
+	due := &HookTask{RepoID: 3, HookID: 3, Payloader: &api.PushPayload{}, Attempts: 1, NextRetryUnix: now - 10}
+	assert.NoError(t, CreateHookTask(due))
+	later := &HookTask{RepoID: 3, HookID: 3, Payloader: &api.PushPayload{}, Attempts: 1, NextRetryUnix: now + 3600}
+	assert.NoError(t, CreateHookTask(later))
+	fresh := &HookTask{RepoID: 3, HookID: 3, Payloader: &api.PushPayload{}}
+	assert.NoError(t, CreateHookTask(fresh))
+
+	ids, err := FindDueHookTaskRetries(now)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{due.ID}, ids)
+
+	// tasks waiting for a retry are not delivered with the new tasks of the repository
+	tasks, err := FindRepoUndeliveredHookTasks(3)
+	assert.NoError(t, err)
+	if assert.Len(t, tasks, 1) {
+		assert.Equal(t, fresh.ID, tasks[0].ID)
+	}
+
+	claimed, err := ClaimHookTaskRetry(due, now+60)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	assert.EqualValues(t, now+60, due.NextRetryUnix)
+
+	// the retry has been claimed already
+	stale := &HookTask{ID: due.ID, NextRetryUnix: now - 10}
+	claimed, err = ClaimHookTaskRetry(stale, now+60)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+
+	ids, err = FindDueHookTaskRetries(now)
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+}
+
+func TestGetHookTaskByHookID(t *testing.T) {
+	assert.NoError(t, db.PrepareTestDatabase())
+
+	task, err := GetHookTaskByHookID(1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "uuid1", task.UUID)
+
+	_, err = GetHookTaskByHookID(2, 1)
+	assert.True(t, IsErrHookTaskNotExist(err))
+}
+
+func TestDeactivateWebhook(t *testing.T) {
+	assert.NoError(t, db.PrepareTestDatabase())
+	hook := db.AssertExistsAndLoadBean(t, &Webhook{ID: 1}).(*Webhook)
+	assert.True(t, hook.IsActive)
+	hook.FailureCount = 5
+	assert.NoError(t, UpdateWebhookLastStatus(hook))
+
+	assert.NoError(t, DeactivateWebhook(hook))
+	hook = db.AssertExistsAndLoadBean(t, &Webhook{ID: 1}).(*Webhook)
+	assert.False(t, hook.IsActive)
+	assert.Zero(t, hook.FailureCount)
+}
+
 func TestCleanupHookTaskTable_PerWebhook_DeletesDelivered(t *testing.T) {
 	assert.NoError(t, db.PrepareTestDatabase())
 	hookTask := &HookTask{
@@ -0,0 +1,28 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToAuditEvent converts models.AuditEvent to api.AuditEvent
+func ToAuditEvent(e *models.AuditEvent) *api.AuditEvent {
+	return &api.AuditEvent{
+		ID:          e.ID,
+		Action:      string(e.Action),
+		DoerID:      e.DoerID,
+		DoerName:    e.DoerName,
+		OwnerID:     e.OwnerID,
+		RepoID:      e.RepoID,
+		Target:      e.Target,
+		Description: e.Description,
+		IPAddress:   e.IPAddress,
+		UserAgent:   e.UserAgent,
+		Created:     e.CreatedUnix.AsTime(),
+		Hash:        e.Hash,
+	}
+}
@@ -230,12 +230,33 @@ func ToHook(repoLink string, w *models.Webhook) *api.Hook {
 		"url":          w.URL,
 		"content_type": w.ContentType.Name(),
 	}
-	if w.Type == models.SLACK {
+	switch w.Type {
+	case models.SLACK:
 		s := webhook.GetSlackHook(w)
 		config["channel"] = s.Channel
 		config["username"] = s.Username
 		config["icon_url"] = s.IconURL
 		config["color"] = s.Color
+	case models.MATTERMOST:
+		s := webhook.GetMattermostHook(w)
+		config["channel"] = s.Channel
+		config["username"] = s.Username
+		config["icon_url"] = s.IconURL
+	case models.ROCKETCHAT:
+		s := webhook.GetRocketChatHook(w)
+		config["channel"] = s.Channel
+		config["username"] = s.Username
+		config["icon_url"] = s.IconURL
+	case models.NTFY:
+		s := webhook.GetNtfyHook(w)
+		config["topic"] = s.Topic
+		config["priority"] = strconv.Itoa(s.Priority)
+	case models.CUSTOM:
+		s := webhook.GetCustomHook(w)
+		config["http_method"] = w.HTTPMethod
+		config["payload_template"] = s.PayloadTemplate
+		config["payload_content_type"] = s.ContentType
+		config["headers"] = s.Headers
 	}
 
 	return &api.Hook{
@@ -250,6 +271,41 @@ func ToHook(repoLink string, w *models.Webhook) *api.Hook {
 	}
 }
 
+// ToHookDelivery convert models.HookTask to api.HookDelivery
+func ToHookDelivery(t *models.HookTask) *api.HookDelivery {
+	delivery := &api.HookDelivery{
+		ID:        t.ID,
+		UUID:      t.UUID,
+		Event:     string(t.EventType),
+		Payload:   t.PayloadContent,
+		Delivered: t.IsDelivered,
+		Succeeded: t.IsSucceed,
+		Attempts:  t.Attempts,
+	}
+	if t.Delivered > 0 {
+		delivered := time.Unix(0, t.Delivered)
+		delivery.DeliveredAt = &delivered
+	}
+	if !t.IsDelivered && t.NextRetryUnix > 0 {
+		delivery.NextRetryAt = t.NextRetryUnix.AsTimePtr()
+	}
+	if t.RequestInfo != nil {
+		delivery.Request = &api.HookDeliveryRequest{
+			URL:     t.RequestInfo.URL,
+			Method:  t.RequestInfo.HTTPMethod,
+			Headers: t.RequestInfo.Headers,
+		}
+	}
+	if t.ResponseInfo != nil {
+		delivery.Response = &api.HookDeliveryResponse{
+			Status:  t.ResponseInfo.Status,
+			Headers: t.ResponseInfo.Headers,
+			Body:    t.ResponseInfo.Body,
+		}
+	}
+	return delivery
+}
+
 // ToGitHook convert git.Hook to api.GitHook
 func ToGitHook(h *git.Hook) *api.GitHook {
 	return &api.GitHook{
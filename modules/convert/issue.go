@@ -146,7 +146,10 @@ func ToStopWatches(sws []*models.Stopwatch) (api.StopWatches, error) {
 		repo, ok = repoCache[issue.RepoID]
 		if !ok {
 			repo, err = models.GetRepositoryByID(issue.RepoID)
-			if err != nil {
+			if models.IsErrRepoNotExist(err) {
+				// the repository of the issue is in the trash
+				continue
+			} else if err != nil {
 				return nil, err
 			}
 		}
@@ -0,0 +1,45 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"time"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ToMirrorSyncStatus convert a models.Mirror to api.MirrorSyncStatus
+func ToMirrorSyncStatus(m *models.Mirror) *api.MirrorSyncStatus {
+	status := &api.MirrorSyncStatus{
+		Interval:         m.Interval.String(),
+		LastSync:         optionalTime(m.LastSyncUnix),
+		LastSyncDuration: m.LastSyncDuration.Seconds(),
+		LastSyncSuccess:  m.LastSyncUnix > 0 && m.LastError == "",
+		LastError:        m.LastError,
+		LastUpdate:       optionalTime(m.UpdatedUnix),
+		UpdatedRefs:      make([]*api.MirrorSyncRef, 0, len(m.LastSyncRefs)),
+		NumUpdatedRefs:   m.NumLastSyncRefs,
+		NextSync:         optionalTime(m.NextUpdateUnix),
+		WebhookEnabled:   m.HasWebhook(),
+	}
+	for _, ref := range m.LastSyncRefs {
+		status.UpdatedRefs = append(status.UpdatedRefs, &api.MirrorSyncRef{
+			Ref:         ref.RefName,
+			OldCommitID: ref.OldCommitID,
+			NewCommitID: ref.NewCommitID,
+		})
+	}
+	return status
+}
+
+// optionalTime returns nil for a zero time stamp
+func optionalTime(t timeutil.TimeStamp) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return t.AsTimePtr()
+}
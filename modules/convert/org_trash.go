@@ -0,0 +1,28 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToDeletedOrg converts an organization in the trash to api.DeletedOrganization
+func ToDeletedOrg(org, doer *models.User) (*api.DeletedOrganization, error) {
+	deletedBy, err := models.GetUserByID(org.DeletedByID)
+	if err != nil {
+		if !models.IsErrUserNotExist(err) {
+			return nil, err
+		}
+		deletedBy = models.NewGhostUser()
+	}
+
+	return &api.DeletedOrganization{
+		Organization: ToOrganization(org),
+		DeletedBy:    ToUser(deletedBy, doer),
+		Deleted:      org.DeletedUnix.AsTime(),
+		Purge:        org.PurgeUnix().AsTime(),
+	}, nil
+}
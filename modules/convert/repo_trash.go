@@ -0,0 +1,31 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToDeletedRepo converts a Repository in the trash to api.DeletedRepository
+func ToDeletedRepo(repo *models.Repository, doer *models.User) (*api.DeletedRepository, error) {
+	deletedBy, err := models.GetUserByID(repo.DeletedByID)
+	if err != nil {
+		if !models.IsErrUserNotExist(err) {
+			return nil, err
+		}
+		deletedBy = models.NewGhostUser()
+	}
+	if err := repo.GetOwner(); err != nil {
+		return nil, err
+	}
+
+	return &api.DeletedRepository{
+		Repository: ToRepo(repo, models.AccessModeOwner),
+		DeletedBy:  ToUser(deletedBy, doer),
+		Deleted:    repo.DeletedUnix.AsTime(),
+		Purge:      repo.PurgeUnix().AsTime(),
+	}, nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToBulkRepoTask convert a bulk repository models.Task to api.BulkRepoTask
+func ToBulkRepoTask(t *models.Task, doer *models.User) (*api.BulkRepoTask, error) {
+	payload, err := t.BulkRepoPayload()
+	if err != nil {
+		return nil, err
+	}
+	if err := t.LoadDoer(); err != nil {
+		if !models.IsErrUserNotExist(err) {
+			return nil, err
+		}
+		t.Doer = models.NewGhostUser()
+	}
+
+	results := payload.Results
+	if results == nil {
+		results = []*api.BulkRepoResult{}
+	}
+	return &api.BulkRepoTask{
+		ID:       t.ID,
+		Doer:     ToUser(t.Doer, doer),
+		Action:   payload.Options.Action,
+		Status:   t.Status.Name(),
+		Message:  t.Message,
+		Total:    len(payload.Options.Repos),
+		Results:  results,
+		NewOwner: payload.Options.NewOwner,
+		Private:  payload.Options.Private,
+		Team:     payload.Options.Team,
+		Created:  t.Created.AsTime(),
+		Started:  optionalTime(t.StartTime),
+		Finished: optionalTime(t.EndTime),
+	}, nil
+}
@@ -35,6 +35,14 @@ func ToGitServiceType(value string) structs.GitServiceType {
 		return structs.GogsService
 	case "onedev":
 		return structs.OneDevService
+	case "bitbucket":
+		return structs.BitbucketService
+	case "gitbucket":
+		return structs.GitBucketService
+	case "gitee":
+		return structs.GiteeService
+	case "codebase":
+		return structs.CodebaseService
 	default:
 		return structs.PlainGitService
 	}
@@ -5,13 +5,14 @@
 package convert
 
 import (
+	"net/url"
+	"strings"
 	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/git"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/util"
-	wiki_service "code.gitea.io/gitea/services/wiki"
 )
 
 // ToWikiCommit convert a git commit into a WikiCommit
@@ -50,7 +51,8 @@ func ToWikiCommitList(commits []*git.Commit, total int64) *api.WikiCommitList {
 
 // ToWikiPageMetaData converts meta information to a WikiPageMetaData
 func ToWikiPageMetaData(title string, lastCommit *git.Commit, repo *models.Repository) *api.WikiPageMetaData {
-	suburl := wiki_service.NameToSubURL(title)
+	// same as wiki_service.NameToSubURL, the wiki service notifies through this package and cannot be imported
+	suburl := url.QueryEscape(strings.ReplaceAll(title, " ", "-"))
 	return &api.WikiPageMetaData{
 		Title:      title,
 		HTMLURL:    util.URLJoin(repo.HTMLURL(), "wiki", suburl),
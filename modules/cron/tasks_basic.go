@@ -13,7 +13,10 @@ import (
 	repository_service "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/services/auth"
+	"code.gitea.io/gitea/services/mailer"
 	mirror_service "code.gitea.io/gitea/services/mirror"
+	org_service "code.gitea.io/gitea/services/org"
+	repo_service "code.gitea.io/gitea/services/repository"
 )
 
 func registerUpdateMirrorTask() {
@@ -126,6 +129,43 @@ func registerCleanupHookTaskTable() {
 	})
 }
 
+func registerSendMailDigests() {
+	RegisterTaskFatal("send_daily_mail_digest", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: false,
+		Schedule:   "@midnight",
+	}, func(ctx context.Context, _ *models.User, _ Config) error {
+		return mailer.SendMailDigests(ctx, models.EmailDigestDaily)
+	})
+	RegisterTaskFatal("send_weekly_mail_digest", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: false,
+		Schedule:   "@weekly",
+	}, func(ctx context.Context, _ *models.User, _ Config) error {
+		return mailer.SendMailDigests(ctx, models.EmailDigestWeekly)
+	})
+}
+
+func registerPurgeDeletedRepositories() {
+	RegisterTaskFatal("purge_deleted_repositories", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: false,
+		Schedule:   "@every 1h",
+	}, func(ctx context.Context, _ *models.User, _ Config) error {
+		return repo_service.PurgeExpiredRepositories(ctx)
+	})
+}
+
+func registerPurgeDeletedOrganizations() {
+	RegisterTaskFatal("purge_deleted_organizations", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: false,
+		Schedule:   "@every 1h",
+	}, func(ctx context.Context, _ *models.User, _ Config) error {
+		return org_service.PurgeExpiredOrganizations(ctx)
+	})
+}
+
 func initBasicTasks() {
 	registerUpdateMirrorTask()
 	registerRepoHealthCheck()
@@ -137,4 +177,7 @@ func initBasicTasks() {
 		registerUpdateMigrationPosterID()
 	}
 	registerCleanupHookTaskTable()
+	registerSendMailDigests()
+	registerPurgeDeletedRepositories()
+	registerPurgeDeletedOrganizations()
 }
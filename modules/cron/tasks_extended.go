@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
 	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/updatechecker"
@@ -132,6 +133,45 @@ func registerDeleteOldActions() {
 	})
 }
 
+func registerGarbageCollectLFS() {
+	if !setting.LFS.StartServer {
+		return
+	}
+	type GarbageCollectLFSConfig struct {
+		OlderThanConfig
+		DryRun bool
+	}
+	RegisterTaskFatal("gc_lfs", &GarbageCollectLFSConfig{
+		OlderThanConfig: OlderThanConfig{
+			BaseConfig: BaseConfig{
+				Enabled:    false,
+				RunAtStart: false,
+				Schedule:   "@every 24h",
+			},
+			OlderThan: setting.LFS.GCGracePeriod,
+		},
+		DryRun: false,
+	}, func(ctx context.Context, _ *models.User, config Config) error {
+		gcLFSConfig := config.(*GarbageCollectLFSConfig)
+		result, err := repo_module.GarbageCollectLFSMetaObjects(ctx, repo_module.GarbageCollectLFSMetaObjectsOptions{
+			Logger:    log.GetLogger(log.DEFAULT),
+			AutoFix:   !gcLFSConfig.DryRun,
+			OlderThan: gcLFSConfig.OlderThan,
+		})
+		if err != nil {
+			return err
+		}
+		if gcLFSConfig.DryRun {
+			log.Info("LFS garbage collection (dry run): %d of %d LFS objects in %d repositories are unreferenced (%d bytes)",
+				result.NumUnreferenced, result.NumChecked, result.NumRepos, result.SizeUnreferenced)
+		} else {
+			log.Info("LFS garbage collection: removed %d of %d LFS objects in %d repositories, deleted %d files from the storage",
+				result.NumRemoved, result.NumChecked, result.NumRepos, result.NumContentDeleted)
+		}
+		return nil
+	})
+}
+
 func registerUpdateGiteaChecker() {
 	type UpdateCheckerConfig struct {
 		BaseConfig
@@ -161,5 +201,6 @@ func initExtendedTasks() {
 	registerDeleteMissingRepositories()
 	registerRemoveRandomAvatars()
 	registerDeleteOldActions()
+	registerGarbageCollectLFS()
 	registerUpdateGiteaChecker()
 }
@@ -0,0 +1,37 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package doctor
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+)
+
+func checkAuditLog(logger log.Logger, autofix bool) error {
+	brokenID, err := models.VerifyAuditEvents()
+	if err != nil {
+		logger.Critical("Error: %v whilst verifying the audit log", err)
+		return err
+	}
+	if brokenID > 0 {
+		// a tampered audit log must not be "fixed" by rehashing it
+		logger.Critical("The audit log has been modified: the hash of event %d does not match", brokenID)
+		return fmt.Errorf("audit log hash chain broken at event %d", brokenID)
+	}
+	logger.Info("The audit log is intact")
+	return nil
+}
+
+func init() {
+	Register(&Check{
+		Title:     "Check the integrity of the audit log",
+		Name:      "check-audit-log",
+		IsDefault: false,
+		Run:       checkAuditLog,
+		Priority:  7,
+	})
+}
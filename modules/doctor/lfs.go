@@ -0,0 +1,59 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package doctor
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+)
+
+func garbageCollectLFSCheck(logger log.Logger, autofix bool) error {
+	if !setting.LFS.StartServer {
+		logger.Info("LFS support is disabled")
+		return nil
+	}
+
+	if err := storage.Init(); err != nil {
+		logger.Error("storage.Init failed: %v", err)
+		return err
+	}
+
+	result, err := repository.GarbageCollectLFSMetaObjects(context.Background(), repository.GarbageCollectLFSMetaObjectsOptions{
+		Logger:    logger,
+		AutoFix:   autofix,
+		OlderThan: setting.LFS.GCGracePeriod,
+	})
+	if err != nil {
+		logger.Error("GarbageCollectLFSMetaObjects failed: %v", err)
+		return err
+	}
+
+	if autofix {
+		logger.Info("Checked %d LFS objects in %d repositories, %d unreferenced objects removed, %d files deleted from the storage.",
+			result.NumChecked, result.NumRepos, result.NumRemoved, result.NumContentDeleted)
+	} else if result.NumUnreferenced > 0 {
+		logger.Warn("Checked %d LFS objects in %d repositories, %d unreferenced objects (%d bytes) need to be removed.",
+			result.NumChecked, result.NumRepos, result.NumUnreferenced, result.SizeUnreferenced)
+	} else {
+		logger.Info("Checked %d LFS objects in %d repositories, no unreferenced objects found.", result.NumChecked, result.NumRepos)
+	}
+	return nil
+}
+
+func init() {
+	Register(&Check{
+		Title:                      "Garbage collect unreferenced LFS objects",
+		Name:                       "gc-lfs",
+		IsDefault:                  false,
+		Run:                        garbageCollectLFSCheck,
+		AbortIfFailed:              false,
+		SkipDatabaseInitialization: false,
+		Priority:                   7,
+	})
+}
@@ -78,6 +78,10 @@ func checkConfigurationFiles(logger log.Logger, autofix bool) error {
 		{"Log Root Path", setting.LogRootPath, true, true, true},
 	}
 
+	for name, root := range setting.RepoShards {
+		configurationFiles = append(configurationFiles, configurationFile{"Repository Shard " + name + " Root Path", root, true, true, true})
+	}
+
 	if options.IsDynamic() {
 		configurationFiles = append(configurationFiles, configurationFile{"Static File Root Path", setting.StaticRootPath, true, true, false})
 	}
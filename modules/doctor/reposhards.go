@@ -0,0 +1,61 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package doctor
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
+)
+
+func checkRepoShards(logger log.Logger, autofix bool) error {
+	owners, err := models.GetRepoShardOwners()
+	if err != nil {
+		logger.Critical("Error: %v whilst listing the owners of repository shards", err)
+		return err
+	}
+
+	shards := models.RepoShardNames()
+	numProblems := 0
+	for _, owner := range owners {
+		if _, err := models.RepoShardRootPath(owner.Shard); err != nil {
+			// the configuration has to be fixed, moving the repositories would lose them
+			logger.Critical("The repositories of %s are in the unconfigured shard %s", owner.Name, owner.Shard)
+			numProblems++
+			continue
+		}
+		for _, shard := range shards {
+			if shard == owner.Shard {
+				continue
+			}
+			root, _ := models.RepoShardRootPath(shard)
+			if isExist, err := util.IsExist(filepath.Join(root, owner.Name)); err != nil {
+				logger.Critical("Error: %v whilst checking the repository shard %s of %s", err, shard, owner.Name)
+				return err
+			} else if isExist {
+				logger.Warn("The shard %s holds a directory for %s whose repositories are in the shard %s", shard, owner.Name, owner.Shard)
+				numProblems++
+			}
+		}
+	}
+	if numProblems > 0 {
+		return fmt.Errorf("%d problems with repository shards found", numProblems)
+	}
+	logger.Info("All repositories are in their repository shards")
+	return nil
+}
+
+func init() {
+	Register(&Check{
+		Title:     "Check that repositories are in their repository shards",
+		Name:      "check-repo-shards",
+		IsDefault: false,
+		Run:       checkRepoShards,
+		Priority:  7,
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"os/exec"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -186,6 +187,17 @@ func Init(ctx context.Context) error {
 		}
 	}
 
+	if CheckGitVersionAtLeast("2.22") == nil {
+		// allow partial clones (e.g. --filter=blob:none or --filter=tree:0) and the lazy fetches they need
+		allowPartialClone := strconv.FormatBool(!setting.Git.DisablePartialClone)
+		if err := checkAndSetConfig("uploadpack.allowfilter", allowPartialClone, true); err != nil {
+			return err
+		}
+		if err := checkAndSetConfig("uploadpack.allowAnySHA1InWant", allowPartialClone, true); err != nil {
+			return err
+		}
+	}
+
 	if CheckGitVersionAtLeast("2.29") == nil {
 		// set support for AGit flow
 		if err := checkAndAddConfig("receive.procReceiveRefs", "refs/for"); err != nil {
@@ -0,0 +1,16 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import "regexp"
+
+// one or more key=value pairs separated by colons
+var safeProtocolPattern = regexp.MustCompile(`^[0-9a-zA-Z]+=[0-9a-zA-Z]+(:[0-9a-zA-Z]+=[0-9a-zA-Z]+)*$`)
+
+// IsSafeProtocol returns true if the value of GIT_PROTOCOL or the Git-Protocol header
+// given by a client may be passed on to git
+func IsSafeProtocol(protocol string) bool {
+	return safeProtocolPattern.MatchString(protocol)
+}
@@ -0,0 +1,20 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSafeProtocol(t *testing.T) {
+	assert.True(t, IsSafeProtocol("version=2"))
+	assert.True(t, IsSafeProtocol("version=2:foo=bar"))
+	assert.False(t, IsSafeProtocol(""))
+	assert.False(t, IsSafeProtocol("version=2 --upload-pack=evil"))
+	assert.False(t, IsSafeProtocol("version=2\nGIT_DIR=/tmp"))
+	assert.False(t, IsSafeProtocol("version"))
+}
@@ -8,11 +8,8 @@
 package lfs
 
 import (
-	"bufio"
 	"context"
 	"io"
-	"strconv"
-	"strings"
 	"sync"
 
 	"code.gitea.io/gitea/modules/git"
@@ -58,56 +55,3 @@ func SearchPointerBlobs(ctx context.Context, repo *git.Repository, pointerChan c
 	close(pointerChan)
 	close(errChan)
 }
-
-func createPointerResultsFromCatFileBatch(ctx context.Context, catFileBatchReader *io.PipeReader, wg *sync.WaitGroup, pointerChan chan<- PointerBlob) {
-	defer wg.Done()
-	defer catFileBatchReader.Close()
-
-	bufferedReader := bufio.NewReader(catFileBatchReader)
-	buf := make([]byte, 1025)
-
-loop:
-	for {
-		select {
-		case <-ctx.Done():
-			break loop
-		default:
-		}
-
-		// File descriptor line: sha
-		sha, err := bufferedReader.ReadString(' ')
-		if err != nil {
-			_ = catFileBatchReader.CloseWithError(err)
-			break
-		}
-		sha = strings.TrimSpace(sha)
-		// Throw away the blob
-		if _, err := bufferedReader.ReadString(' '); err != nil {
-			_ = catFileBatchReader.CloseWithError(err)
-			break
-		}
-		sizeStr, err := bufferedReader.ReadString('\n')
-		if err != nil {
-			_ = catFileBatchReader.CloseWithError(err)
-			break
-		}
-		size, err := strconv.Atoi(sizeStr[:len(sizeStr)-1])
-		if err != nil {
-			_ = catFileBatchReader.CloseWithError(err)
-			break
-		}
-		pointerBuf := buf[:size+1]
-		if _, err := io.ReadFull(bufferedReader, pointerBuf); err != nil {
-			_ = catFileBatchReader.CloseWithError(err)
-			break
-		}
-		pointerBuf = pointerBuf[:size]
-		// Now we need to check if the pointerBuf is an LFS pointer
-		pointer, _ := ReadPointerFromBuffer(pointerBuf)
-		if !pointer.IsValid() {
-			continue
-		}
-
-		pointerChan <- PointerBlob{Hash: sha, Pointer: pointer}
-	}
-}
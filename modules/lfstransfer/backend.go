@@ -0,0 +1,255 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfstransfer
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"code.gitea.io/gitea/modules/json"
+	lfs_module "code.gitea.io/gitea/modules/lfs"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// httpBackend implements Backend on top of the LFS HTTP API of the running Gitea server,
+// so that the permission checks, content store and locks of services/lfs are shared with HTTP clients
+type httpBackend struct {
+	ctx           context.Context
+	client        *http.Client
+	baseURL       string
+	authorization string
+	operation     string
+}
+
+// NewHTTPBackend creates a Backend for the repository which authenticates with the given LFS token
+func NewHTTPBackend(ctx context.Context, ownerName, repoName, operation, authorization string) Backend {
+	transport := &http.Transport{
+		Proxy: nil,
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true,
+			ServerName:         setting.Domain,
+		},
+	}
+	if setting.Protocol == setting.UnixSocket {
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", setting.HTTPAddr)
+		}
+	}
+
+	return &httpBackend{
+		ctx:           ctx,
+		client:        &http.Client{Transport: transport},
+		baseURL:       setting.LocalURL + url.PathEscape(ownerName) + "/" + url.PathEscape(repoName) + ".git/info/lfs",
+		authorization: authorization,
+		operation:     operation,
+	}
+}
+
+func (b *httpBackend) do(method, path string, body io.Reader, size int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(b.ctx, method, b.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	req.Header.Set("Authorization", b.authorization)
+	// the size of downloads is taken from the content length, so it must not be compressed
+	req.Header.Set("Accept-Encoding", "identity")
+	req.Header.Set("Accept", lfs_module.MediaType)
+	req.Header.Set("Content-Type", lfs_module.MediaType)
+	return b.client.Do(req)
+}
+
+// doJSON sends v as JSON and decodes the response into result if the response has the expected status code
+func (b *httpBackend) doJSON(method, path string, v, result interface{}, expectedStatus int) (*api.LFSLockError, error) {
+	var body io.Reader
+	var size int64
+	if v != nil {
+		bs, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(bs)
+		size = int64(len(bs))
+	}
+
+	resp, err := b.do(method, path, body, size)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expectedStatus {
+		return responseError(resp)
+	}
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// responseError converts an error response of the LFS API into a StatusError
+func responseError(resp *http.Response) (*api.LFSLockError, error) {
+	// object and lock errors share the message field
+	var lockErr api.LFSLockError
+	_ = json.NewDecoder(resp.Body).Decode(&lockErr)
+
+	code := resp.StatusCode
+	if code == http.StatusUnauthorized {
+		code = http.StatusForbidden
+	}
+	return &lockErr, NewStatusError(code, lockErr.Message)
+}
+
+// Batch implements Backend
+func (b *httpBackend) Batch(pointers []lfs_module.Pointer, refname string) ([]BatchItem, error) {
+	br := &lfs_module.BatchRequest{
+		Operation: b.operation,
+		Transfers: []string{"basic"},
+		Objects:   pointers,
+	}
+	if refname != "" {
+		br.Ref = &lfs_module.Reference{Name: refname}
+	}
+
+	var resp lfs_module.BatchResponse
+	if _, err := b.doJSON("POST", "/objects/batch", br, &resp, http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	items := make([]BatchItem, 0, len(resp.Objects))
+	for _, obj := range resp.Objects {
+		item := BatchItem{Pointer: obj.Pointer, Action: ActionNoop}
+		if obj.Error != nil {
+			// a missing object can't be downloaded, every other error fails the whole batch
+			if b.operation == OperationDownload && obj.Error.Code == http.StatusNotFound {
+				items = append(items, item)
+				continue
+			}
+			return nil, NewStatusError(obj.Error.Code, obj.Error.Message)
+		}
+		if _, ok := obj.Actions["upload"]; ok {
+			item.Action = ActionUpload
+		} else if _, ok := obj.Actions["download"]; ok {
+			item.Action = ActionDownload
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Upload implements Backend
+func (b *httpBackend) Upload(p lfs_module.Pointer, content io.Reader) error {
+	resp, err := b.do("PUT", "/objects/"+url.PathEscape(p.Oid)+"/"+strconv.FormatInt(p.Size, 10), content, p.Size)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		_, err := responseError(resp)
+		return err
+	}
+	return nil
+}
+
+// Verify implements Backend
+func (b *httpBackend) Verify(p lfs_module.Pointer) error {
+	_, err := b.doJSON("POST", "/verify", p, nil, http.StatusOK)
+	return err
+}
+
+// Download implements Backend
+func (b *httpBackend) Download(oid string) (io.ReadCloser, int64, error) {
+	resp, err := b.do("GET", "/objects/"+url.PathEscape(oid), nil, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		_, err := responseError(resp)
+		return nil, 0, err
+	}
+	if resp.ContentLength < 0 {
+		resp.Body.Close()
+		return nil, 0, fmt.Errorf("missing content length for LFS object %s", oid)
+	}
+	return resp.Body, resp.ContentLength, nil
+}
+
+// CreateLock implements Backend
+func (b *httpBackend) CreateLock(path, refname string) (*api.LFSLock, error) {
+	var resp api.LFSLockResponse
+	lockErr, err := b.doJSON("POST", "/locks", &api.LFSLockRequest{Path: path}, &resp, http.StatusCreated)
+	if err != nil {
+		if lockErr != nil {
+			return lockErr.Lock, err
+		}
+		return nil, err
+	}
+	return resp.Lock, nil
+}
+
+func listLocksQuery(opts ListLocksOptions) string {
+	query := url.Values{}
+	if opts.ID != "" {
+		query.Set("id", opts.ID)
+	}
+	if opts.Path != "" {
+		query.Set("path", opts.Path)
+	}
+	if opts.Cursor != "" {
+		query.Set("cursor", opts.Cursor)
+	}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Refname != "" {
+		query.Set("refspec", opts.Refname)
+	}
+	if len(query) == 0 {
+		return ""
+	}
+	return "?" + query.Encode()
+}
+
+// ListLocks implements Backend
+func (b *httpBackend) ListLocks(opts ListLocksOptions) (*api.LFSLockList, error) {
+	var resp api.LFSLockList
+	if _, err := b.doJSON("GET", "/locks"+listLocksQuery(opts), nil, &resp, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// VerifyLocks implements Backend
+func (b *httpBackend) VerifyLocks(opts ListLocksOptions) (*api.LFSLockListVerify, error) {
+	var resp api.LFSLockListVerify
+	if _, err := b.doJSON("POST", "/locks/verify"+listLocksQuery(opts), struct{}{}, &resp, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Unlock implements Backend
+func (b *httpBackend) Unlock(id string, force bool, refname string) (*api.LFSLock, error) {
+	var resp api.LFSLockResponse
+	if _, err := b.doJSON("POST", "/locks/"+url.PathEscape(id)+"/unlock", &api.LFSLockDeleteRequest{Force: force}, &resp, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return resp.Lock, nil
+}
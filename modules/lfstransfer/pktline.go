@@ -0,0 +1,169 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfstransfer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const (
+	// maxPktLineData is the largest payload a single pkt-line can carry
+	maxPktLineData = 65516
+
+	flushPkt = "0000"
+	delimPkt = "0001"
+)
+
+// pktLineType is the type of a pkt-line
+type pktLineType int
+
+const (
+	pktLineData pktLineType = iota
+	pktLineFlush
+	pktLineDelim
+)
+
+// errUnexpectedPktLine is returned when a flush or delim packet is read where data was expected
+var errUnexpectedPktLine = errors.New("unexpected pkt-line")
+
+// pktLineReader reads git pkt-lines
+type pktLineReader struct {
+	rd *bufio.Reader
+}
+
+func newPktLineReader(rd io.Reader) *pktLineReader {
+	return &pktLineReader{rd: bufio.NewReader(rd)}
+}
+
+// ReadPacket reads the next pkt-line and returns its type and payload
+func (r *pktLineReader) ReadPacket() (pktLineType, []byte, error) {
+	lengthBytes := make([]byte, 4)
+	if _, err := io.ReadFull(r.rd, lengthBytes); err != nil {
+		return pktLineData, nil, err
+	}
+
+	length, err := strconv.ParseUint(string(lengthBytes), 16, 16)
+	if err != nil {
+		return pktLineData, nil, fmt.Errorf("invalid pkt-line length %q: %v", lengthBytes, err)
+	}
+
+	switch {
+	case length == 0:
+		return pktLineFlush, nil, nil
+	case length == 1:
+		return pktLineDelim, nil, nil
+	case length < 4 || length > maxPktLineData+4:
+		return pktLineData, nil, fmt.Errorf("invalid pkt-line length %d", length)
+	}
+
+	data := make([]byte, length-4)
+	if _, err := io.ReadFull(r.rd, data); err != nil {
+		return pktLineData, nil, err
+	}
+	return pktLineData, data, nil
+}
+
+// ReadLine reads a text pkt-line and strips its trailing newline
+func (r *pktLineReader) ReadLine() (pktLineType, string, error) {
+	typ, data, err := r.ReadPacket()
+	if err != nil || typ != pktLineData {
+		return typ, "", err
+	}
+	if len(data) > 0 && data[len(data)-1] == '\n' {
+		data = data[:len(data)-1]
+	}
+	return typ, string(data), nil
+}
+
+// dataReader reads the payload of data pkt-lines until the terminating flush packet
+type dataReader struct {
+	r   *pktLineReader
+	buf []byte
+	eof bool
+}
+
+func (d *dataReader) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		if d.eof {
+			return 0, io.EOF
+		}
+		typ, data, err := d.r.ReadPacket()
+		if err != nil {
+			return 0, err
+		}
+		switch typ {
+		case pktLineFlush:
+			d.eof = true
+		case pktLineDelim:
+			return 0, errUnexpectedPktLine
+		default:
+			d.buf = data
+		}
+	}
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
+}
+
+// pktLineWriter writes git pkt-lines
+type pktLineWriter struct {
+	wr io.Writer
+}
+
+func newPktLineWriter(wr io.Writer) *pktLineWriter {
+	return &pktLineWriter{wr: wr}
+}
+
+// WritePacket writes data as a single pkt-line
+func (w *pktLineWriter) WritePacket(data []byte) error {
+	if len(data) > maxPktLineData {
+		return fmt.Errorf("pkt-line payload too large: %d", len(data))
+	}
+	if _, err := fmt.Fprintf(w.wr, "%04x", len(data)+4); err != nil {
+		return err
+	}
+	_, err := w.wr.Write(data)
+	return err
+}
+
+// WriteLine writes a text pkt-line terminated by a newline
+func (w *pktLineWriter) WriteLine(line string) error {
+	return w.WritePacket([]byte(line + "\n"))
+}
+
+// WriteFlush writes a flush packet
+func (w *pktLineWriter) WriteFlush() error {
+	_, err := io.WriteString(w.wr, flushPkt)
+	return err
+}
+
+// WriteDelim writes a delimiter packet
+func (w *pktLineWriter) WriteDelim() error {
+	_, err := io.WriteString(w.wr, delimPkt)
+	return err
+}
+
+// WriteData copies rd as a sequence of data pkt-lines
+func (w *pktLineWriter) WriteData(rd io.Reader) error {
+	buf := make([]byte, maxPktLineData)
+	for {
+		n, err := rd.Read(buf)
+		if n > 0 {
+			if err := w.WritePacket(buf[:n]); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,542 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package lfstransfer implements the server side of the Git LFS SSH transfer protocol (git-lfs-transfer).
+// https://github.com/git-lfs/git-lfs/blob/main/docs/proposals/ssh_adapter.md
+package lfstransfer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	lfs_module "code.gitea.io/gitea/modules/lfs"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+const (
+	// OperationUpload is the operation used by git-lfs to push objects
+	OperationUpload = "upload"
+	// OperationDownload is the operation used by git-lfs to fetch objects
+	OperationDownload = "download"
+
+	// ActionUpload tells the client to upload the object
+	ActionUpload = "upload"
+	// ActionDownload tells the client to download the object
+	ActionDownload = "download"
+	// ActionNoop tells the client there is nothing to transfer for the object
+	ActionNoop = "noop"
+)
+
+// BatchItem is the action the client has to take for a pointer
+type BatchItem struct {
+	lfs_module.Pointer
+	Action string
+}
+
+// ListLocksOptions are the filters for listing locks
+type ListLocksOptions struct {
+	ID      string
+	Path    string
+	Cursor  string
+	Limit   int
+	Refname string
+}
+
+// Backend provides the object storage and locks the transfer protocol operates on
+type Backend interface {
+	// Batch returns the action the client has to take for each pointer
+	Batch(pointers []lfs_module.Pointer, refname string) ([]BatchItem, error)
+	// Upload stores the content of the object
+	Upload(p lfs_module.Pointer, content io.Reader) error
+	// Verify checks the object has been stored
+	Verify(p lfs_module.Pointer) error
+	// Download returns the content of the object and its size
+	Download(oid string) (io.ReadCloser, int64, error)
+
+	// CreateLock locks the path, on conflict it returns the existing lock together with the error
+	CreateLock(path, refname string) (*api.LFSLock, error)
+	// ListLocks lists the locks of the repository
+	ListLocks(opts ListLocksOptions) (*api.LFSLockList, error)
+	// VerifyLocks lists the locks of the repository split by their owner
+	VerifyLocks(opts ListLocksOptions) (*api.LFSLockListVerify, error)
+	// Unlock removes the lock
+	Unlock(id string, force bool, refname string) (*api.LFSLock, error)
+}
+
+// StatusError is an error which is reported to the client with the given status code
+type StatusError struct {
+	Code    int
+	Message string
+}
+
+func (err *StatusError) Error() string {
+	return fmt.Sprintf("status %d: %s", err.Code, err.Message)
+}
+
+// NewStatusError creates a StatusError using the status text as default message
+func NewStatusError(code int, message string) *StatusError {
+	if message == "" {
+		message = http.StatusText(code)
+	}
+	return &StatusError{Code: code, Message: message}
+}
+
+// Processor serves the transfer protocol of a single connection
+type Processor struct {
+	r         *pktLineReader
+	w         *pktLineWriter
+	backend   Backend
+	operation string
+}
+
+// NewProcessor creates a new Processor for the given operation
+func NewProcessor(r io.Reader, w io.Writer, backend Backend, operation string) *Processor {
+	return &Processor{
+		r:         newPktLineReader(r),
+		w:         newPktLineWriter(w),
+		backend:   backend,
+		operation: operation,
+	}
+}
+
+// request is a command sent by the client
+type request struct {
+	Command string
+	Args    map[string]string
+	// HasData is true if the arguments are followed by a data section
+	HasData bool
+}
+
+// Serve performs the version negotiation and handles commands until the client quits
+func (p *Processor) Serve() error {
+	if p.operation != OperationUpload && p.operation != OperationDownload {
+		return fmt.Errorf("unknown operation %q", p.operation)
+	}
+
+	if err := p.negotiateVersion(); err != nil {
+		return err
+	}
+
+	for {
+		req, err := p.readRequest()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		log.Trace("git-lfs-transfer: %s %v", req.Command, req.Args)
+
+		command := req.Command
+		var arg string
+		if i := strings.IndexByte(command, ' '); i >= 0 {
+			command, arg = command[:i], command[i+1:]
+		}
+
+		switch command {
+		case "batch":
+			err = p.batch(req)
+		case "put-object":
+			err = p.putObject(req, arg)
+		case "verify-object":
+			err = p.verifyObject(req, arg)
+		case "get-object":
+			err = p.getObject(req, arg)
+		case "lock":
+			err = p.lock(req)
+		case "list-lock":
+			err = p.listLock(req)
+		case "unlock":
+			err = p.unlock(req, arg)
+		case "quit":
+			if err := p.skipData(req); err != nil {
+				return err
+			}
+			return p.writeStatus(http.StatusOK, nil, nil)
+		default:
+			if err := p.skipData(req); err != nil {
+				return err
+			}
+			err = p.writeError(NewStatusError(http.StatusBadRequest, "unknown command "+command))
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (p *Processor) negotiateVersion() error {
+	if err := p.w.WriteLine("version=1"); err != nil {
+		return err
+	}
+	if err := p.w.WriteLine("locking"); err != nil {
+		return err
+	}
+	if err := p.w.WriteFlush(); err != nil {
+		return err
+	}
+
+	req, err := p.readRequest()
+	if err != nil {
+		return err
+	}
+	if err := p.skipData(req); err != nil {
+		return err
+	}
+	if req.Command != "version 1" {
+		if err := p.writeError(NewStatusError(http.StatusBadRequest, "unsupported version "+strings.TrimPrefix(req.Command, "version "))); err != nil {
+			return err
+		}
+		return fmt.Errorf("unsupported version request %q", req.Command)
+	}
+	return p.writeStatus(http.StatusOK, nil, nil)
+}
+
+// readRequest reads the command and its arguments up to the data section or the end of the request
+func (p *Processor) readRequest() (*request, error) {
+	typ, command, err := p.r.ReadLine()
+	if err != nil {
+		return nil, err
+	}
+	if typ != pktLineData {
+		return nil, errUnexpectedPktLine
+	}
+
+	req := &request{
+		Command: command,
+		Args:    make(map[string]string),
+	}
+	for {
+		typ, line, err := p.r.ReadLine()
+		if err != nil {
+			return nil, err
+		}
+		switch typ {
+		case pktLineFlush:
+			return req, nil
+		case pktLineDelim:
+			req.HasData = true
+			return req, nil
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) == 2 {
+			req.Args[parts[0]] = parts[1]
+		} else {
+			req.Args[parts[0]] = ""
+		}
+	}
+}
+
+// readDataLines reads the text lines of the data section
+func (p *Processor) readDataLines(req *request) ([]string, error) {
+	if !req.HasData {
+		return nil, nil
+	}
+	var lines []string
+	for {
+		typ, line, err := p.r.ReadLine()
+		if err != nil {
+			return nil, err
+		}
+		switch typ {
+		case pktLineFlush:
+			return lines, nil
+		case pktLineDelim:
+			return nil, errUnexpectedPktLine
+		}
+		lines = append(lines, line)
+	}
+}
+
+// skipData discards an unused data section so the next request can be read
+func (p *Processor) skipData(req *request) error {
+	if !req.HasData {
+		return nil
+	}
+	_, err := io.Copy(io.Discard, &dataReader{r: p.r})
+	return err
+}
+
+func (p *Processor) writeStatus(code int, args []string, data []string) error {
+	if err := p.w.WriteLine("status " + strconv.Itoa(code)); err != nil {
+		return err
+	}
+	for _, arg := range args {
+		if err := p.w.WriteLine(arg); err != nil {
+			return err
+		}
+	}
+	if data != nil {
+		if err := p.w.WriteDelim(); err != nil {
+			return err
+		}
+		for _, line := range data {
+			if err := p.w.WriteLine(line); err != nil {
+				return err
+			}
+		}
+	}
+	return p.w.WriteFlush()
+}
+
+// writeError reports the error to the client, errors other than StatusError are hidden from the client
+func (p *Processor) writeError(err error) error {
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		log.Error("git-lfs-transfer: %v", err)
+		statusErr = NewStatusError(http.StatusInternalServerError, "")
+	}
+	return p.writeStatus(statusErr.Code, nil, []string{statusErr.Message})
+}
+
+func (p *Processor) requireOperation(operation string) error {
+	if p.operation != operation {
+		return NewStatusError(http.StatusForbidden, fmt.Sprintf("not allowed during %s operation", p.operation))
+	}
+	return nil
+}
+
+func (p *Processor) batch(req *request) error {
+	lines, err := p.readDataLines(req)
+	if err != nil {
+		return err
+	}
+
+	if algo, ok := req.Args["hash-algo"]; ok && algo != "sha256" {
+		return p.writeError(NewStatusError(http.StatusConflict, "unsupported hash algorithm "+algo))
+	}
+
+	pointers := make([]lfs_module.Pointer, 0, len(lines))
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return p.writeError(NewStatusError(http.StatusBadRequest, "invalid object "+line))
+		}
+		size, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return p.writeError(NewStatusError(http.StatusBadRequest, "invalid object size "+fields[1]))
+		}
+		pointer := lfs_module.Pointer{Oid: fields[0], Size: size}
+		if !pointer.IsValid() {
+			return p.writeError(NewStatusError(http.StatusBadRequest, "invalid object "+line))
+		}
+		pointers = append(pointers, pointer)
+	}
+
+	items, err := p.backend.Batch(pointers, req.Args["refname"])
+	if err != nil {
+		return p.writeError(err)
+	}
+
+	data := make([]string, 0, len(items))
+	for _, item := range items {
+		data = append(data, fmt.Sprintf("%s %d %s", item.Oid, item.Size, item.Action))
+	}
+	return p.writeStatus(http.StatusOK, nil, data)
+}
+
+func parseSize(req *request) (int64, error) {
+	size, err := strconv.ParseInt(req.Args["size"], 10, 64)
+	if err != nil || size < 0 {
+		return 0, NewStatusError(http.StatusBadRequest, "invalid size "+req.Args["size"])
+	}
+	return size, nil
+}
+
+func (p *Processor) putObject(req *request, oid string) error {
+	if !req.HasData {
+		return p.writeError(NewStatusError(http.StatusBadRequest, "missing object data"))
+	}
+
+	dr := &dataReader{r: p.r}
+	err := p.requireOperation(OperationUpload)
+	if err == nil {
+		var size int64
+		if size, err = parseSize(req); err == nil {
+			err = p.backend.Upload(lfs_module.Pointer{Oid: oid, Size: size}, dr)
+		}
+	}
+
+	// always consume the rest of the object so that the next request can be read
+	if _, copyErr := io.Copy(io.Discard, dr); copyErr != nil {
+		return copyErr
+	}
+	if err != nil {
+		return p.writeError(err)
+	}
+	return p.writeStatus(http.StatusOK, nil, nil)
+}
+
+func (p *Processor) verifyObject(req *request, oid string) error {
+	if err := p.skipData(req); err != nil {
+		return err
+	}
+	if err := p.requireOperation(OperationUpload); err != nil {
+		return p.writeError(err)
+	}
+	size, err := parseSize(req)
+	if err != nil {
+		return p.writeError(err)
+	}
+	if err := p.backend.Verify(lfs_module.Pointer{Oid: oid, Size: size}); err != nil {
+		return p.writeError(err)
+	}
+	return p.writeStatus(http.StatusOK, nil, nil)
+}
+
+func (p *Processor) getObject(req *request, oid string) error {
+	if err := p.skipData(req); err != nil {
+		return err
+	}
+	if err := p.requireOperation(OperationDownload); err != nil {
+		return p.writeError(err)
+	}
+	if !(lfs_module.Pointer{Oid: oid}).IsValid() {
+		return p.writeError(NewStatusError(http.StatusBadRequest, "invalid object "+oid))
+	}
+
+	content, size, err := p.backend.Download(oid)
+	if err != nil {
+		return p.writeError(err)
+	}
+	defer content.Close()
+
+	if err := p.w.WriteLine("status " + strconv.Itoa(http.StatusOK)); err != nil {
+		return err
+	}
+	if err := p.w.WriteLine("size=" + strconv.FormatInt(size, 10)); err != nil {
+		return err
+	}
+	if err := p.w.WriteDelim(); err != nil {
+		return err
+	}
+	if err := p.w.WriteData(content); err != nil {
+		return err
+	}
+	return p.w.WriteFlush()
+}
+
+func lockArgs(lock *api.LFSLock) []string {
+	args := []string{
+		"id=" + lock.ID,
+		"path=" + lock.Path,
+		"locked-at=" + lock.LockedAt.UTC().Format(time.RFC3339),
+	}
+	if lock.Owner != nil {
+		args = append(args, "ownername="+lock.Owner.Name)
+	}
+	return args
+}
+
+func lockData(lock *api.LFSLock, owner string) []string {
+	data := []string{
+		"lock " + lock.ID,
+		"path " + lock.ID + " " + lock.Path,
+		"locked-at " + lock.ID + " " + lock.LockedAt.UTC().Format(time.RFC3339),
+	}
+	if lock.Owner != nil {
+		data = append(data, "ownername "+lock.ID+" "+lock.Owner.Name)
+	}
+	if owner != "" {
+		data = append(data, "owner "+lock.ID+" "+owner)
+	}
+	return data
+}
+
+func (p *Processor) lock(req *request) error {
+	if err := p.skipData(req); err != nil {
+		return err
+	}
+	if err := p.requireOperation(OperationUpload); err != nil {
+		return p.writeError(err)
+	}
+	path, ok := req.Args["path"]
+	if !ok || path == "" {
+		return p.writeError(NewStatusError(http.StatusBadRequest, "missing path"))
+	}
+
+	lock, err := p.backend.CreateLock(path, req.Args["refname"])
+	if err != nil {
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.Code == http.StatusConflict && lock != nil {
+			return p.writeStatus(http.StatusConflict, lockArgs(lock), []string{statusErr.Message})
+		}
+		return p.writeError(err)
+	}
+	return p.writeStatus(http.StatusCreated, lockArgs(lock), nil)
+}
+
+func (p *Processor) listLock(req *request) error {
+	if err := p.skipData(req); err != nil {
+		return err
+	}
+
+	opts := ListLocksOptions{
+		ID:      req.Args["id"],
+		Path:    req.Args["path"],
+		Cursor:  req.Args["cursor"],
+		Refname: req.Args["refname"],
+	}
+	if limit, ok := req.Args["limit"]; ok {
+		var err error
+		if opts.Limit, err = strconv.Atoi(limit); err != nil || opts.Limit < 0 {
+			return p.writeError(NewStatusError(http.StatusBadRequest, "invalid limit "+limit))
+		}
+	}
+
+	var args []string
+	data := []string{}
+	if p.operation == OperationUpload && opts.ID == "" && opts.Path == "" {
+		// during a push git-lfs needs to know which locks are its own
+		list, err := p.backend.VerifyLocks(opts)
+		if err != nil {
+			return p.writeError(err)
+		}
+		for _, lock := range list.Ours {
+			data = append(data, lockData(lock, "ours")...)
+		}
+		for _, lock := range list.Theirs {
+			data = append(data, lockData(lock, "theirs")...)
+		}
+		if list.Next != "" {
+			args = append(args, "next-cursor="+list.Next)
+		}
+	} else {
+		list, err := p.backend.ListLocks(opts)
+		if err != nil {
+			return p.writeError(err)
+		}
+		for _, lock := range list.Locks {
+			data = append(data, lockData(lock, "")...)
+		}
+		if list.Next != "" {
+			args = append(args, "next-cursor="+list.Next)
+		}
+	}
+	return p.writeStatus(http.StatusOK, args, data)
+}
+
+func (p *Processor) unlock(req *request, id string) error {
+	if err := p.skipData(req); err != nil {
+		return err
+	}
+	if err := p.requireOperation(OperationUpload); err != nil {
+		return p.writeError(err)
+	}
+	if id == "" {
+		return p.writeError(NewStatusError(http.StatusBadRequest, "missing lock id"))
+	}
+
+	lock, err := p.backend.Unlock(id, req.Args["force"] == "true", req.Args["refname"])
+	if err != nil {
+		return p.writeError(err)
+	}
+	return p.writeStatus(http.StatusOK, lockArgs(lock), nil)
+}
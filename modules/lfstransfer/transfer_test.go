@@ -0,0 +1,214 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfstransfer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	lfs_module "code.gitea.io/gitea/modules/lfs"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryBackend struct {
+	objects map[string][]byte
+	locks   []*api.LFSLock
+}
+
+func (b *memoryBackend) Batch(pointers []lfs_module.Pointer, refname string) ([]BatchItem, error) {
+	items := make([]BatchItem, 0, len(pointers))
+	for _, p := range pointers {
+		action := ActionUpload
+		if _, ok := b.objects[p.Oid]; ok {
+			action = ActionNoop
+		}
+		items = append(items, BatchItem{Pointer: p, Action: action})
+	}
+	return items, nil
+}
+
+func (b *memoryBackend) Upload(p lfs_module.Pointer, content io.Reader) error {
+	bs, err := io.ReadAll(content)
+	if err != nil {
+		return err
+	}
+	if int64(len(bs)) != p.Size {
+		return NewStatusError(http.StatusUnprocessableEntity, lfs_module.ErrSizeMismatch.Error())
+	}
+	b.objects[p.Oid] = bs
+	return nil
+}
+
+func (b *memoryBackend) Verify(p lfs_module.Pointer) error {
+	if bs, ok := b.objects[p.Oid]; !ok || int64(len(bs)) != p.Size {
+		return NewStatusError(http.StatusNotFound, "")
+	}
+	return nil
+}
+
+func (b *memoryBackend) Download(oid string) (io.ReadCloser, int64, error) {
+	bs, ok := b.objects[oid]
+	if !ok {
+		return nil, 0, NewStatusError(http.StatusNotFound, "")
+	}
+	return io.NopCloser(bytes.NewReader(bs)), int64(len(bs)), nil
+}
+
+func (b *memoryBackend) CreateLock(path, refname string) (*api.LFSLock, error) {
+	for _, lock := range b.locks {
+		if lock.Path == path {
+			return lock, NewStatusError(http.StatusConflict, "already created lock")
+		}
+	}
+	lock := &api.LFSLock{
+		ID:       strconv.Itoa(len(b.locks) + 1),
+		Path:     path,
+		LockedAt: time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC),
+		Owner:    &api.LFSLockOwner{Name: "user2"},
+	}
+	b.locks = append(b.locks, lock)
+	return lock, nil
+}
+
+func (b *memoryBackend) ListLocks(opts ListLocksOptions) (*api.LFSLockList, error) {
+	return &api.LFSLockList{Locks: b.locks}, nil
+}
+
+func (b *memoryBackend) VerifyLocks(opts ListLocksOptions) (*api.LFSLockListVerify, error) {
+	return &api.LFSLockListVerify{Ours: b.locks}, nil
+}
+
+func (b *memoryBackend) Unlock(id string, force bool, refname string) (*api.LFSLock, error) {
+	for i, lock := range b.locks {
+		if lock.ID == id {
+			b.locks = append(b.locks[:i], b.locks[i+1:]...)
+			return lock, nil
+		}
+	}
+	return nil, NewStatusError(http.StatusNotFound, "")
+}
+
+func pktLine(s string) string {
+	return fmt.Sprintf("%04x%s", len(s)+4, s)
+}
+
+func pktLines(lines ...string) string {
+	var sb strings.Builder
+	for _, line := range lines {
+		switch line {
+		case flushPkt, delimPkt:
+			sb.WriteString(line)
+		default:
+			sb.WriteString(pktLine(line + "\n"))
+		}
+	}
+	return sb.String()
+}
+
+func serve(t *testing.T, backend Backend, operation, input string) string {
+	var out bytes.Buffer
+	assert.NoError(t, NewProcessor(strings.NewReader(input), &out, backend, operation).Serve())
+	return out.String()
+}
+
+const (
+	testOid     = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	testContent = "hello"
+)
+
+func TestProcessorUpload(t *testing.T) {
+	backend := &memoryBackend{objects: map[string][]byte{}}
+
+	input := pktLines("version 1", flushPkt) +
+		pktLines("batch", "hash-algo=sha256", "transfer=ssh", delimPkt, testOid+" 5", flushPkt) +
+		pktLines("put-object "+testOid, "size=5", delimPkt) + pktLine(testContent) + flushPkt +
+		pktLines("verify-object "+testOid, "size=5", flushPkt) +
+		pktLines("quit", flushPkt)
+
+	expected := pktLines("version=1", "locking", flushPkt) +
+		pktLines("status 200", flushPkt) +
+		pktLines("status 200", delimPkt, testOid+" 5 upload", flushPkt) +
+		pktLines("status 200", flushPkt) +
+		pktLines("status 200", flushPkt) +
+		pktLines("status 200", flushPkt)
+
+	assert.Equal(t, expected, serve(t, backend, OperationUpload, input))
+	assert.Equal(t, []byte(testContent), backend.objects[testOid])
+}
+
+func TestProcessorUploadSizeMismatch(t *testing.T) {
+	backend := &memoryBackend{objects: map[string][]byte{}}
+
+	input := pktLines("version 1", flushPkt) +
+		pktLines("put-object "+testOid, "size=4", delimPkt) + pktLine(testContent) + flushPkt +
+		pktLines("quit", flushPkt)
+
+	expected := pktLines("version=1", "locking", flushPkt) +
+		pktLines("status 200", flushPkt) +
+		pktLines("status 422", delimPkt, lfs_module.ErrSizeMismatch.Error(), flushPkt) +
+		pktLines("status 200", flushPkt)
+
+	assert.Equal(t, expected, serve(t, backend, OperationUpload, input))
+	assert.Empty(t, backend.objects)
+}
+
+func TestProcessorDownload(t *testing.T) {
+	backend := &memoryBackend{objects: map[string][]byte{testOid: []byte(testContent)}}
+
+	input := pktLines("version 1", flushPkt) +
+		pktLines("get-object "+testOid, flushPkt) +
+		pktLines("put-object "+testOid, "size=5", delimPkt) + pktLine(testContent) + flushPkt +
+		pktLines("quit", flushPkt)
+
+	expected := pktLines("version=1", "locking", flushPkt) +
+		pktLines("status 200", flushPkt) +
+		pktLines("status 200", "size=5", delimPkt) + pktLine(testContent) + flushPkt +
+		pktLines("status 403", delimPkt, "not allowed during download operation", flushPkt) +
+		pktLines("status 200", flushPkt)
+
+	assert.Equal(t, expected, serve(t, backend, OperationDownload, input))
+}
+
+func TestProcessorLocks(t *testing.T) {
+	backend := &memoryBackend{objects: map[string][]byte{}}
+
+	input := pktLines("version 1", flushPkt) +
+		pktLines("lock", "path=assets/model.bin", "refname=refs/heads/master", flushPkt) +
+		pktLines("lock", "path=assets/model.bin", flushPkt) +
+		pktLines("list-lock", "limit=100", flushPkt) +
+		pktLines("unlock 1", "force=true", flushPkt) +
+		pktLines("quit", flushPkt)
+
+	lockArgs := []string{"id=1", "path=assets/model.bin", "locked-at=2021-10-01T12:00:00Z", "ownername=user2"}
+	expected := pktLines("version=1", "locking", flushPkt) +
+		pktLines("status 200", flushPkt) +
+		pktLines(append(append([]string{"status 201"}, lockArgs...), flushPkt)...) +
+		pktLines(append(append([]string{"status 409"}, lockArgs...), delimPkt, "already created lock", flushPkt)...) +
+		pktLines("status 200", delimPkt, "lock 1", "path 1 assets/model.bin", "locked-at 1 2021-10-01T12:00:00Z", "ownername 1 user2", "owner 1 ours", flushPkt) +
+		pktLines(append(append([]string{"status 200"}, lockArgs...), flushPkt)...) +
+		pktLines("status 200", flushPkt)
+
+	assert.Equal(t, expected, serve(t, backend, OperationUpload, input))
+	assert.Empty(t, backend.locks)
+}
+
+func TestProcessorUnsupportedVersion(t *testing.T) {
+	input := pktLines("version 2", flushPkt)
+
+	expected := pktLines("version=1", "locking", flushPkt) +
+		pktLines("status 400", delimPkt, "unsupported version 2", flushPkt)
+
+	var out bytes.Buffer
+	assert.Error(t, NewProcessor(strings.NewReader(input), &out, &memoryBackend{}, OperationUpload).Serve())
+	assert.Equal(t, expected, out.String())
+}
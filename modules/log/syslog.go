@@ -0,0 +1,91 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package log
+
+import (
+	"fmt"
+	"log/syslog"
+	"strings"
+
+	"code.gitea.io/gitea/modules/json"
+)
+
+var syslogFacilities = map[string]syslog.Priority{
+	"kern":     syslog.LOG_KERN,
+	"user":     syslog.LOG_USER,
+	"mail":     syslog.LOG_MAIL,
+	"daemon":   syslog.LOG_DAEMON,
+	"auth":     syslog.LOG_AUTH,
+	"syslog":   syslog.LOG_SYSLOG,
+	"authpriv": syslog.LOG_AUTHPRIV,
+	"local0":   syslog.LOG_LOCAL0,
+	"local1":   syslog.LOG_LOCAL1,
+	"local2":   syslog.LOG_LOCAL2,
+	"local3":   syslog.LOG_LOCAL3,
+	"local4":   syslog.LOG_LOCAL4,
+	"local5":   syslog.LOG_LOCAL5,
+	"local6":   syslog.LOG_LOCAL6,
+	"local7":   syslog.LOG_LOCAL7,
+}
+
+// SyslogLogger implements LoggerProvider.
+// It writes messages to the local or a remote syslog daemon.
+type SyslogLogger struct {
+	WriterLogger
+	Net      string `json:"net"`
+	Addr     string `json:"addr"`
+	Tag      string `json:"tag"`
+	Facility string `json:"facility"`
+}
+
+// NewSyslog creates new SyslogLogger returning as LoggerProvider.
+func NewSyslog() LoggerProvider {
+	s := new(SyslogLogger)
+	s.Level = TRACE
+	return s
+}
+
+// Init inits the syslog writer with json config.
+// An empty net and addr connect to the local syslog daemon.
+func (log *SyslogLogger) Init(jsonconfig string) error {
+	err := json.Unmarshal([]byte(jsonconfig), log)
+	if err != nil {
+		return fmt.Errorf("Unable to parse JSON: %v", err)
+	}
+	facility, ok := syslogFacilities[strings.ToLower(log.Facility)]
+	if !ok {
+		if log.Facility != "" {
+			return fmt.Errorf("Unknown syslog facility: %s", log.Facility)
+		}
+		facility = syslog.LOG_LOCAL0
+	}
+	w, err := syslog.Dial(log.Net, log.Addr, facility|syslog.LOG_INFO, log.Tag)
+	if err != nil {
+		return fmt.Errorf("Unable to connect to syslog: %v", err)
+	}
+	log.NewWriterLogger(w, log.Level)
+	return nil
+}
+
+// Flush does nothing for this implementation
+func (log *SyslogLogger) Flush() {
+}
+
+// GetName returns the default name for this implementation
+func (log *SyslogLogger) GetName() string {
+	return "syslog"
+}
+
+// ReleaseReopen does nothing for this implementation
+func (log *SyslogLogger) ReleaseReopen() error {
+	return nil
+}
+
+func init() {
+	Register("syslog", NewSyslog)
+}
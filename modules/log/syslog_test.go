@@ -0,0 +1,51 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package log
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyslogLogger(t *testing.T) {
+	l, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	logger := NewSyslog()
+	err = logger.Init(fmt.Sprintf(`{"level":"info","flags":-1,"net":"udp","addr":"%s","tag":"gitea","facility":"auth"}`, l.LocalAddr().String()))
+	assert.NoError(t, err)
+	defer logger.Close()
+	assert.Equal(t, INFO, logger.GetLevel())
+
+	event := Event{
+		level: INFO,
+		msg:   "TEST MSG",
+		time:  time.Now(),
+	}
+	assert.NoError(t, logger.LogEvent(&event))
+
+	buf := make([]byte, 1024)
+	assert.NoError(t, l.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, _, err := l.ReadFrom(buf)
+	assert.NoError(t, err)
+	msg := string(buf[:n])
+	// <38> is facility auth (4) * 8 + severity info (6)
+	assert.True(t, strings.HasPrefix(msg, "<38>"), msg)
+	assert.Contains(t, msg, "gitea[")
+	assert.True(t, strings.HasSuffix(msg, "TEST MSG\n"), msg)
+
+	assert.Error(t, NewSyslog().Init(`{"facility":"unknown"}`))
+}
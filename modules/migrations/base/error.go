@@ -13,8 +13,11 @@ type ErrNotSupported struct {
 
 // IsErrNotSupported checks if an error is an ErrNotSupported
 func IsErrNotSupported(err error) bool {
-	_, ok := err.(ErrNotSupported)
-	return ok
+	switch err.(type) {
+	case ErrNotSupported, *ErrNotSupported:
+		return true
+	}
+	return false
 }
 
 // Error return error message
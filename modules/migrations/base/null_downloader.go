@@ -7,6 +7,7 @@ package base
 import (
 	"context"
 	"net/url"
+	"time"
 )
 
 // NullDownloader implements a blank downloader
@@ -50,6 +51,11 @@ func (n NullDownloader) GetIssues(page, perPage int) ([]*Issue, bool, error) {
 	return nil, false, &ErrNotSupported{Entity: "Issues"}
 }
 
+// GetNewIssues returns issues updated after the given time according start and limit
+func (n NullDownloader) GetNewIssues(page, perPage int, updatedAfter time.Time) ([]*Issue, bool, error) {
+	return nil, false, &ErrNotSupported{Entity: "NewIssues"}
+}
+
 // GetComments returns comments according the options
 func (n NullDownloader) GetComments(GetCommentOptions) ([]*Comment, bool, error) {
 	return nil, false, &ErrNotSupported{Entity: "Comments"}
@@ -60,6 +66,11 @@ func (n NullDownloader) GetPullRequests(page, perPage int) ([]*PullRequest, bool
 	return nil, false, &ErrNotSupported{Entity: "PullRequests"}
 }
 
+// GetNewPullRequests returns pull requests updated after the given time according page and perPage
+func (n NullDownloader) GetNewPullRequests(page, perPage int, updatedAfter time.Time) ([]*PullRequest, bool, error) {
+	return nil, false, &ErrNotSupported{Entity: "NewPullRequests"}
+}
+
 // GetReviews returns pull requests review
 func (n NullDownloader) GetReviews(pullRequestContext IssueContext) ([]*Review, error) {
 	return nil, &ErrNotSupported{Entity: "Reviews"}
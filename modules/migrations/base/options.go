@@ -39,4 +39,5 @@ type MigrateOptions struct {
 	ReleaseAssets   bool
 	MigrateToRepoID int64
 	MirrorInterval  string `json:"mirror_interval"`
+	MirrorMetadata  bool   `json:"mirror_metadata"`
 }
@@ -0,0 +1,882 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations/base"
+	"code.gitea.io/gitea/modules/proxy"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/structs"
+)
+
+var (
+	_ base.Downloader        = &BitbucketDownloader{}
+	_ base.DownloaderFactory = &BitbucketDownloaderFactory{}
+)
+
+// bitbucketCloudAPIURL is the API of bitbucket.org, every other host is taken for a Bitbucket Server
+const bitbucketCloudAPIURL = "https://api.bitbucket.org/2.0/"
+
+func init() {
+	RegisterDownloaderFactory(&BitbucketDownloaderFactory{})
+}
+
+// BitbucketDownloaderFactory defines a bitbucket downloader factory
+type BitbucketDownloaderFactory struct {
+}
+
+// New returns a Downloader related to this factory according MigrateOptions
+func (f *BitbucketDownloaderFactory) New(ctx context.Context, opts base.MigrateOptions) (base.Downloader, error) {
+	u, err := url.Parse(opts.CloneAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if strings.EqualFold(u.Hostname(), "bitbucket.org") {
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("invalid path: %s", u.Path)
+		}
+		workspace, repoSlug := fields[0], strings.TrimSuffix(fields[1], ".git")
+
+		log.Trace("Create bitbucket downloader. Workspace: %s RepoSlug: %s", workspace, repoSlug)
+
+		return NewBitbucketDownloader(ctx, bitbucketCloudAPIURL, opts.AuthUsername, opts.AuthPassword, opts.AuthToken, workspace, repoSlug), nil
+	}
+
+	// Bitbucket Server, maybe below a context path:
+	// <base>/scm/<project>/<repo>.git, <base>/projects/<project>/repos/<repo>/... or <base>/users/<user>/repos/<repo>/...
+	for i := range fields {
+		var project, repoSlug string
+		switch strings.ToLower(fields[i]) {
+		case "scm":
+			if len(fields) >= i+3 {
+				project, repoSlug = fields[i+1], strings.TrimSuffix(fields[i+2], ".git")
+			}
+		case "projects", "users":
+			if len(fields) >= i+4 && fields[i+2] == "repos" {
+				project, repoSlug = fields[i+1], fields[i+3]
+				if strings.ToLower(fields[i]) == "users" {
+					project = "~" + project
+				}
+			}
+		}
+		if project == "" {
+			continue
+		}
+
+		u.Path = strings.Join(fields[:i], "/")
+		u.User = nil
+		u.RawQuery = ""
+		u.Fragment = ""
+
+		log.Trace("Create bitbucket server downloader. BaseURL: %s Project: %s RepoSlug: %s", u, project, repoSlug)
+
+		return NewBitbucketServerDownloader(ctx, u.String(), opts.AuthUsername, opts.AuthPassword, opts.AuthToken, project, repoSlug), nil
+	}
+	return nil, fmt.Errorf("invalid path: %s", u.Path)
+}
+
+// GitServiceType returns the type of git service
+func (f *BitbucketDownloaderFactory) GitServiceType() structs.GitServiceType {
+	return structs.BitbucketService
+}
+
+// bitbucketClient calls the REST APIs of Bitbucket Cloud and Bitbucket Server
+type bitbucketClient struct {
+	ctx      context.Context
+	client   *http.Client
+	baseURL  *url.URL
+	userName string
+	password string
+	token    string
+}
+
+func newBitbucketClient(ctx context.Context, baseURL, userName, password, token string) *bitbucketClient {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/") + "/")
+	if err != nil {
+		log.Error("Invalid bitbucket url %s: %v", baseURL, err)
+		u = &url.URL{}
+	}
+	c := &bitbucketClient{
+		ctx:      ctx,
+		baseURL:  u,
+		userName: userName,
+		password: password,
+		token:    token,
+	}
+	c.client = &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: setting.Migrations.SkipTLSVerify},
+			Proxy:           proxy.Proxy(),
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			// downloads are redirected to other hosts, which must not get the credentials
+			if !c.isSameOrigin(req.URL) {
+				req.Header.Del("Authorization")
+			}
+			return nil
+		},
+	}
+	return c
+}
+
+// isSameOrigin returns whether the url has the scheme and host of the base URL
+func (c *bitbucketClient) isSameOrigin(u *url.URL) bool {
+	return strings.EqualFold(u.Scheme, c.baseURL.Scheme) && strings.EqualFold(u.Host, c.baseURL.Host)
+}
+
+// do gets the url, with the credentials of the client only if it has the origin of the base URL
+func (c *bitbucketClient) do(u *url.URL) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(c.ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.isSameOrigin(u) {
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		} else if c.userName != "" || c.password != "" {
+			req.SetBasicAuth(c.userName, c.password)
+		}
+	}
+	req.Header.Set("Accept", "application/json")
+	return c.client.Do(req)
+}
+
+// bitbucketAPIError is returned when the API answers with an error status
+type bitbucketAPIError struct {
+	StatusCode int
+	URL        string
+}
+
+func (err *bitbucketAPIError) Error() string {
+	return fmt.Sprintf("bitbucket api %s returned %d", err.URL, err.StatusCode)
+}
+
+func isBitbucketNotFound(err error) bool {
+	apiErr, ok := err.(*bitbucketAPIError)
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+// callAPI gets the endpoint, relative to the base URL unless it is absolute, and decodes the JSON result.
+// Absolute endpoints, e.g. the links to the next page, must have the origin of the base URL.
+func (c *bitbucketClient) callAPI(endpoint string, parameter url.Values, result interface{}) error {
+	u, err := c.baseURL.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+	if !c.isSameOrigin(u) {
+		return fmt.Errorf("refusing to call bitbucket api %s outside of %s", u.Redacted(), c.baseURL.Redacted())
+	}
+	if parameter != nil {
+		query := u.Query()
+		for k, v := range parameter {
+			query[k] = v
+		}
+		u.RawQuery = query.Encode()
+	}
+
+	resp, err := c.do(u)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &bitbucketAPIError{StatusCode: resp.StatusCode, URL: u.String()}
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// download returns a function downloading the file at rawURL, with the credentials of the client
+// if it has the origin of the base URL
+func (c *bitbucketClient) download(rawURL string) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.do(u)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, &bitbucketAPIError{StatusCode: resp.StatusCode, URL: rawURL}
+		}
+		return resp.Body, nil
+	}
+}
+
+// formatBitbucketCloneURL adds the credentials to a clone url; tokens are accepted as the password of x-token-auth
+func formatBitbucketCloneURL(opts base.MigrateOptions, remoteAddr string) (string, error) {
+	if len(opts.AuthToken) == 0 && len(opts.AuthUsername) == 0 {
+		return remoteAddr, nil
+	}
+	u, err := url.Parse(remoteAddr)
+	if err != nil {
+		return "", err
+	}
+	if len(opts.AuthToken) > 0 {
+		userName := opts.AuthUsername
+		if userName == "" {
+			userName = "x-token-auth"
+		}
+		u.User = url.UserPassword(userName, opts.AuthToken)
+	} else {
+		u.User = url.UserPassword(opts.AuthUsername, opts.AuthPassword)
+	}
+	return u.String(), nil
+}
+
+type bitbucketIssueContext struct {
+	foreignID     int64
+	localID       int64
+	IsPullRequest bool
+}
+
+func (c bitbucketIssueContext) LocalID() int64 {
+	return c.localID
+}
+
+func (c bitbucketIssueContext) ForeignID() int64 {
+	return c.foreignID
+}
+
+type bitbucketUser struct {
+	DisplayName string `json:"display_name"`
+	Nickname    string `json:"nickname"`
+	AccountID   string `json:"account_id"`
+}
+
+// Name returns the name to show for the user, Bitbucket Cloud does not expose user names any more
+func (u *bitbucketUser) Name() string {
+	if u == nil {
+		return "Unknown"
+	}
+	if u.Nickname != "" {
+		return u.Nickname
+	}
+	return u.DisplayName
+}
+
+type bitbucketContent struct {
+	Raw string `json:"raw"`
+}
+
+type bitbucketCommit struct {
+	Hash string `json:"hash"`
+}
+
+type bitbucketRef struct {
+	Branch struct {
+		Name string `json:"name"`
+	} `json:"branch"`
+	Commit     *bitbucketCommit `json:"commit"`
+	Repository *struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+type bitbucketComment struct {
+	ID        int64            `json:"id"`
+	Content   bitbucketContent `json:"content"`
+	User      *bitbucketUser   `json:"user"`
+	CreatedOn time.Time        `json:"created_on"`
+	UpdatedOn time.Time        `json:"updated_on"`
+	Deleted   bool             `json:"deleted"`
+	Inline    *struct {
+		Path string `json:"path"`
+		From *int   `json:"from"`
+		To   *int   `json:"to"`
+	} `json:"inline"`
+	Parent *struct {
+		ID int64 `json:"id"`
+	} `json:"parent"`
+}
+
+// bitbucketIssueKinds and bitbucketIssuePriorities are migrated as labels, Bitbucket Cloud has no labels
+var (
+	bitbucketIssueKinds = []*base.Label{
+		{Name: "kind/bug", Color: "ee0701"},
+		{Name: "kind/enhancement", Color: "84b6eb"},
+		{Name: "kind/proposal", Color: "cc317c"},
+		{Name: "kind/task", Color: "0e8a16"},
+	}
+	bitbucketIssuePriorities = []*base.Label{
+		{Name: "priority/trivial", Color: "c2e0c6"},
+		{Name: "priority/minor", Color: "fef2c0"},
+		{Name: "priority/major", Color: "fbca04"},
+		{Name: "priority/critical", Color: "e99695"},
+		{Name: "priority/blocker", Color: "b60205"},
+	}
+)
+
+// BitbucketDownloader implements a Downloader interface to get repository information
+// from Bitbucket Cloud
+type BitbucketDownloader struct {
+	base.NullDownloader
+	client        *bitbucketClient
+	repoPath      string
+	maxPerPage    int
+	maxIssueIndex int64
+	commits       map[string]string
+}
+
+// NewBitbucketDownloader creates a Bitbucket Cloud downloader for the repository workspace/repoSlug
+func NewBitbucketDownloader(ctx context.Context, baseURL, userName, password, token, workspace, repoSlug string) *BitbucketDownloader {
+	return &BitbucketDownloader{
+		client:     newBitbucketClient(ctx, baseURL, userName, password, token),
+		repoPath:   fmt.Sprintf("repositories/%s/%s", url.PathEscape(workspace), url.PathEscape(repoSlug)),
+		maxPerPage: 50,
+		commits:    make(map[string]string),
+	}
+}
+
+// SetContext set context
+func (d *BitbucketDownloader) SetContext(ctx context.Context) {
+	d.client.ctx = ctx
+}
+
+// FormatCloneURL add authentication into remote URLs
+func (d *BitbucketDownloader) FormatCloneURL(opts base.MigrateOptions, remoteAddr string) (string, error) {
+	return formatBitbucketCloneURL(opts, remoteAddr)
+}
+
+// getPage gets one page of a paginated endpoint and returns the url of the next page,
+// which is empty on the last page
+func (d *BitbucketDownloader) getPage(endpoint string, parameter url.Values, values interface{}) (string, error) {
+	result := struct {
+		Values interface{} `json:"values"`
+		Next   string      `json:"next"`
+	}{Values: values}
+	if err := d.client.callAPI(endpoint, parameter, &result); err != nil {
+		return "", err
+	}
+	return result.Next, nil
+}
+
+// fullCommitHash resolves the abbreviated commit hashes Bitbucket returns for pull requests
+func (d *BitbucketDownloader) fullCommitHash(commit *bitbucketCommit) string {
+	if commit == nil || commit.Hash == "" {
+		return ""
+	}
+	if len(commit.Hash) == 40 {
+		return commit.Hash
+	}
+	if hash, ok := d.commits[commit.Hash]; ok {
+		return hash
+	}
+
+	var full bitbucketCommit
+	if err := d.client.callAPI(d.repoPath+"/commit/"+url.PathEscape(commit.Hash), nil, &full); err != nil {
+		log.Warn("Unable to resolve bitbucket commit %s: %v", commit.Hash, err)
+		full.Hash = commit.Hash
+	}
+	d.commits[commit.Hash] = full.Hash
+	return full.Hash
+}
+
+// GetRepoInfo returns repository information
+func (d *BitbucketDownloader) GetRepoInfo() (*base.Repository, error) {
+	var repo struct {
+		Name        string `json:"name"`
+		FullName    string `json:"full_name"`
+		Description string `json:"description"`
+		IsPrivate   bool   `json:"is_private"`
+		MainBranch  *struct {
+			Name string `json:"name"`
+		} `json:"mainbranch"`
+		Links struct {
+			HTML struct {
+				Href string `json:"href"`
+			} `json:"html"`
+			Clone []struct {
+				Name string `json:"name"`
+				Href string `json:"href"`
+			} `json:"clone"`
+		} `json:"links"`
+	}
+	if err := d.client.callAPI(d.repoPath, nil, &repo); err != nil {
+		return nil, err
+	}
+
+	var cloneURL string
+	for _, clone := range repo.Links.Clone {
+		if clone.Name == "https" {
+			u, err := url.Parse(clone.Href)
+			if err != nil {
+				return nil, err
+			}
+			// the clone url contains the name of the authenticated user
+			u.User = nil
+			cloneURL = u.String()
+		}
+	}
+
+	var defaultBranch string
+	if repo.MainBranch != nil {
+		defaultBranch = repo.MainBranch.Name
+	}
+
+	owner := repo.FullName
+	if i := strings.IndexByte(owner, '/'); i >= 0 {
+		owner = owner[:i]
+	}
+
+	return &base.Repository{
+		Name:          repo.Name,
+		Owner:         owner,
+		IsPrivate:     repo.IsPrivate,
+		Description:   repo.Description,
+		CloneURL:      cloneURL,
+		OriginalURL:   repo.Links.HTML.Href,
+		DefaultBranch: defaultBranch,
+	}, nil
+}
+
+// GetMilestones returns milestones
+func (d *BitbucketDownloader) GetMilestones() ([]*base.Milestone, error) {
+	milestones := make([]*base.Milestone, 0, 10)
+	// the next link carries all parameters
+	for next := d.repoPath + "/milestones"; next != ""; {
+		rawMilestones := make([]struct {
+			Name string `json:"name"`
+		}, 0, 10)
+		var err error
+		if next, err = d.getPage(next, nil, &rawMilestones); err != nil {
+			// the issue tracker is disabled
+			if isBitbucketNotFound(err) {
+				break
+			}
+			return nil, err
+		}
+		for _, milestone := range rawMilestones {
+			milestones = append(milestones, &base.Milestone{
+				Title: milestone.Name,
+				State: "open",
+			})
+		}
+	}
+	return milestones, nil
+}
+
+// GetLabels returns the issue kinds and priorities as labels
+func (d *BitbucketDownloader) GetLabels() ([]*base.Label, error) {
+	labels := make([]*base.Label, 0, len(bitbucketIssueKinds)+len(bitbucketIssuePriorities))
+	labels = append(labels, bitbucketIssueKinds...)
+	return append(labels, bitbucketIssuePriorities...), nil
+}
+
+func findBitbucketLabel(labels []*base.Label, name string) *base.Label {
+	for _, label := range labels {
+		if label.Name == name {
+			return label
+		}
+	}
+	return nil
+}
+
+// GetReleases returns the downloads of the repository as assets of a draft release,
+// Bitbucket Cloud has no releases and its tags are migrated with the git data
+func (d *BitbucketDownloader) GetReleases() ([]*base.Release, error) {
+	assets := make([]*base.ReleaseAsset, 0, 10)
+	for next := d.repoPath + "/downloads"; next != ""; {
+		rawDownloads := make([]struct {
+			Name      string         `json:"name"`
+			Size      int            `json:"size"`
+			Downloads int            `json:"downloads"`
+			CreatedOn time.Time      `json:"created_on"`
+			User      *bitbucketUser `json:"user"`
+			Links     struct {
+				Self struct {
+					Href string `json:"href"`
+				} `json:"self"`
+			} `json:"links"`
+		}, 0, 10)
+		var err error
+		if next, err = d.getPage(next, nil, &rawDownloads); err != nil {
+			return nil, err
+		}
+		for i := range rawDownloads {
+			download := rawDownloads[i]
+			assets = append(assets, &base.ReleaseAsset{
+				ID:            int64(len(assets) + 1),
+				Name:          download.Name,
+				Size:          &download.Size,
+				DownloadCount: &download.Downloads,
+				Created:       download.CreatedOn,
+				Updated:       download.CreatedOn,
+				DownloadFunc:  d.client.download(download.Links.Self.Href),
+			})
+		}
+	}
+	if len(assets) == 0 {
+		return []*base.Release{}, nil
+	}
+
+	created := assets[0].Created
+	for _, asset := range assets {
+		if asset.Created.Before(created) {
+			created = asset.Created
+		}
+	}
+	return []*base.Release{
+		{
+			TagName: "downloads",
+			Name:    "Downloads",
+			Body:    "Files from the downloads section of the Bitbucket repository.",
+			Draft:   true,
+			Assets:  assets,
+			Created: created,
+		},
+	}, nil
+}
+
+// GetIssues returns issues according start and limit
+func (d *BitbucketDownloader) GetIssues(page, perPage int) ([]*base.Issue, bool, error) {
+	if perPage > d.maxPerPage {
+		perPage = d.maxPerPage
+	}
+
+	var result struct {
+		Values []struct {
+			ID        int64            `json:"id"`
+			Title     string           `json:"title"`
+			Content   bitbucketContent `json:"content"`
+			State     string           `json:"state"`
+			Kind      string           `json:"kind"`
+			Priority  string           `json:"priority"`
+			Reporter  *bitbucketUser   `json:"reporter"`
+			Assignee  *bitbucketUser   `json:"assignee"`
+			CreatedOn time.Time        `json:"created_on"`
+			UpdatedOn time.Time        `json:"updated_on"`
+			Milestone *struct {
+				Name string `json:"name"`
+			} `json:"milestone"`
+		} `json:"values"`
+		Next string `json:"next"`
+	}
+	err := d.client.callAPI(d.repoPath+"/issues", url.Values{
+		"sort":    {"id"},
+		"page":    {strconv.Itoa(page)},
+		"pagelen": {strconv.Itoa(perPage)},
+	}, &result)
+	if err != nil {
+		// the issue tracker is disabled
+		if isBitbucketNotFound(err) {
+			return []*base.Issue{}, true, nil
+		}
+		return nil, false, err
+	}
+
+	issues := make([]*base.Issue, 0, len(result.Values))
+	for _, issue := range result.Values {
+		labels := make([]*base.Label, 0, 2)
+		if label := findBitbucketLabel(bitbucketIssueKinds, "kind/"+issue.Kind); label != nil {
+			labels = append(labels, label)
+		}
+		if label := findBitbucketLabel(bitbucketIssuePriorities, "priority/"+issue.Priority); label != nil {
+			labels = append(labels, label)
+		}
+
+		state := "open"
+		var closed *time.Time
+		switch issue.State {
+		case "resolved", "invalid", "duplicate", "wontfix", "closed":
+			state = "closed"
+			closedTime := issue.UpdatedOn
+			closed = &closedTime
+		}
+
+		var milestone string
+		if issue.Milestone != nil {
+			milestone = issue.Milestone.Name
+		}
+		var assignees []string
+		if issue.Assignee != nil {
+			assignees = []string{issue.Assignee.Name()}
+		}
+
+		issues = append(issues, &base.Issue{
+			Number:     issue.ID,
+			Title:      issue.Title,
+			Content:    issue.Content.Raw,
+			PosterName: issue.Reporter.Name(),
+			Milestone:  milestone,
+			State:      state,
+			Created:    issue.CreatedOn,
+			Updated:    issue.UpdatedOn,
+			Closed:     closed,
+			Labels:     labels,
+			Assignees:  assignees,
+			Context: bitbucketIssueContext{
+				foreignID: issue.ID,
+				localID:   issue.ID,
+			},
+		})
+
+		if d.maxIssueIndex < issue.ID {
+			d.maxIssueIndex = issue.ID
+		}
+	}
+
+	return issues, result.Next == "", nil
+}
+
+func (d *BitbucketDownloader) getComments(context bitbucketIssueContext) ([]*bitbucketComment, error) {
+	endpoint := fmt.Sprintf("%s/issues/%d/comments", d.repoPath, context.ForeignID())
+	if context.IsPullRequest {
+		endpoint = fmt.Sprintf("%s/pullrequests/%d/comments", d.repoPath, context.ForeignID())
+	}
+
+	comments := make([]*bitbucketComment, 0, 10)
+	// the next link carries all parameters
+	for next, parameter := endpoint, (url.Values{"sort": {"id"}}); next != ""; parameter = nil {
+		page := make([]*bitbucketComment, 0, 10)
+		var err error
+		if next, err = d.getPage(next, parameter, &page); err != nil {
+			return nil, err
+		}
+		comments = append(comments, page...)
+	}
+	return comments, nil
+}
+
+// GetComments returns comments according issueNumber
+func (d *BitbucketDownloader) GetComments(opts base.GetCommentOptions) ([]*base.Comment, bool, error) {
+	context, ok := opts.Context.(bitbucketIssueContext)
+	if !ok {
+		return nil, false, fmt.Errorf("unexpected comment context: %+v", opts.Context)
+	}
+
+	rawComments, err := d.getComments(context)
+	if err != nil {
+		return nil, false, err
+	}
+
+	comments := make([]*base.Comment, 0, len(rawComments))
+	for _, comment := range rawComments {
+		// state changes of issues are comments without content, inline comments are part of reviews
+		if comment.Deleted || comment.Content.Raw == "" || comment.Inline != nil {
+			continue
+		}
+		comments = append(comments, &base.Comment{
+			IssueIndex: context.LocalID(),
+			PosterName: comment.User.Name(),
+			Content:    comment.Content.Raw,
+			Created:    comment.CreatedOn,
+			Updated:    comment.UpdatedOn,
+		})
+	}
+	return comments, true, nil
+}
+
+// GetPullRequests returns pull requests according page and perPage
+func (d *BitbucketDownloader) GetPullRequests(page, perPage int) ([]*base.PullRequest, bool, error) {
+	if perPage > d.maxPerPage {
+		perPage = d.maxPerPage
+	}
+
+	var result struct {
+		Values []struct {
+			ID          int64            `json:"id"`
+			Title       string           `json:"title"`
+			Description string           `json:"description"`
+			State       string           `json:"state"`
+			Author      *bitbucketUser   `json:"author"`
+			CreatedOn   time.Time        `json:"created_on"`
+			UpdatedOn   time.Time        `json:"updated_on"`
+			Source      bitbucketRef     `json:"source"`
+			Destination bitbucketRef     `json:"destination"`
+			MergeCommit *bitbucketCommit `json:"merge_commit"`
+		} `json:"values"`
+		Next string `json:"next"`
+	}
+	err := d.client.callAPI(d.repoPath+"/pullrequests", url.Values{
+		"state":   {"OPEN", "MERGED", "DECLINED", "SUPERSEDED"},
+		"sort":    {"id"},
+		"page":    {strconv.Itoa(page)},
+		"pagelen": {strconv.Itoa(perPage)},
+	}, &result)
+	if err != nil {
+		return nil, false, err
+	}
+
+	pullRequests := make([]*base.PullRequest, 0, len(result.Values))
+	for _, pr := range result.Values {
+		state := "open"
+		merged := false
+		var closed, mergedTime *time.Time
+		if pr.State != "OPEN" {
+			state = "closed"
+			closedTime := pr.UpdatedOn
+			closed = &closedTime
+			if pr.State == "MERGED" {
+				merged = true
+				mergedTime = &closedTime
+			}
+		}
+
+		head := d.pullRequestBranch(pr.Source)
+		number := pr.ID + d.maxIssueIndex
+		pullRequests = append(pullRequests, &base.PullRequest{
+			Number:         number,
+			Title:          pr.Title,
+			PosterName:     pr.Author.Name(),
+			Content:        pr.Description,
+			State:          state,
+			Created:        pr.CreatedOn,
+			Updated:        pr.UpdatedOn,
+			Closed:         closed,
+			Merged:         merged,
+			MergedTime:     mergedTime,
+			MergeCommitSHA: d.fullCommitHash(pr.MergeCommit),
+			Head:           head,
+			Base:           d.pullRequestBranch(pr.Destination),
+			Context: bitbucketIssueContext{
+				foreignID:     pr.ID,
+				localID:       number,
+				IsPullRequest: true,
+			},
+		})
+	}
+
+	return pullRequests, result.Next == "", nil
+}
+
+func (d *BitbucketDownloader) pullRequestBranch(ref bitbucketRef) base.PullRequestBranch {
+	branch := base.PullRequestBranch{
+		Ref: ref.Branch.Name,
+		SHA: d.fullCommitHash(ref.Commit),
+	}
+	if ref.Repository != nil {
+		if fields := strings.SplitN(ref.Repository.FullName, "/", 2); len(fields) == 2 {
+			branch.OwnerName, branch.RepoName = fields[0], fields[1]
+			branch.CloneURL = fmt.Sprintf("https://bitbucket.org/%s.git", ref.Repository.FullName)
+		}
+	}
+	return branch
+}
+
+// GetReviews returns the approvals of the pull request and its inline comments as reviews
+func (d *BitbucketDownloader) GetReviews(context base.IssueContext) ([]*base.Review, error) {
+	bbContext, ok := context.(bitbucketIssueContext)
+	if !ok {
+		return nil, fmt.Errorf("unexpected review context: %+v", context)
+	}
+
+	var pr struct {
+		Source       bitbucketRef `json:"source"`
+		Participants []struct {
+			User           *bitbucketUser `json:"user"`
+			Approved       bool           `json:"approved"`
+			State          string         `json:"state"`
+			ParticipatedOn *time.Time     `json:"participated_on"`
+		} `json:"participants"`
+	}
+	if err := d.client.callAPI(fmt.Sprintf("%s/pullrequests/%d", d.repoPath, bbContext.ForeignID()), nil, &pr); err != nil {
+		return nil, err
+	}
+	commitID := d.fullCommitHash(pr.Source.Commit)
+
+	reviews := make([]*base.Review, 0, len(pr.Participants))
+	for _, participant := range pr.Participants {
+		state := ""
+		switch {
+		case participant.State == "changes_requested":
+			state = base.ReviewStateChangesRequested
+		case participant.Approved || participant.State == "approved":
+			state = base.ReviewStateApproved
+		default:
+			continue
+		}
+		review := &base.Review{
+			IssueIndex:   bbContext.LocalID(),
+			ReviewerName: participant.User.Name(),
+			CommitID:     commitID,
+			State:        state,
+		}
+		if participant.ParticipatedOn != nil {
+			review.CreatedAt = *participant.ParticipatedOn
+		}
+		reviews = append(reviews, review)
+	}
+
+	rawComments, err := d.getComments(bbContext)
+	if err != nil {
+		return nil, err
+	}
+
+	// inline comments of each user become one review
+	commentReviews := make(map[string]*base.Review)
+	for _, comment := range rawComments {
+		if comment.Deleted || comment.Inline == nil {
+			continue
+		}
+		name := comment.User.Name()
+		review, ok := commentReviews[name]
+		if !ok {
+			review = &base.Review{
+				IssueIndex:   bbContext.LocalID(),
+				ReviewerName: name,
+				CommitID:     commitID,
+				CreatedAt:    comment.CreatedOn,
+				State:        base.ReviewStateCommented,
+			}
+			commentReviews[name] = review
+			reviews = append(reviews, review)
+		}
+
+		line := 0
+		if comment.Inline.To != nil {
+			line = *comment.Inline.To
+		} else if comment.Inline.From != nil {
+			// lines of the old file are negative
+			line = -*comment.Inline.From
+		}
+		var inReplyTo int64
+		if comment.Parent != nil {
+			inReplyTo = comment.Parent.ID
+		}
+		review.Comments = append(review.Comments, &base.ReviewComment{
+			ID:        comment.ID,
+			InReplyTo: inReplyTo,
+			Content:   comment.Content.Raw,
+			TreePath:  comment.Inline.Path,
+			Line:      line,
+			CommitID:  commitID,
+			CreatedAt: comment.CreatedOn,
+			UpdatedAt: comment.UpdatedOn,
+		})
+	}
+
+	sort.SliceStable(reviews, func(i, j int) bool {
+		return reviews[i].CreatedAt.Before(reviews[j].CreatedAt)
+	})
+	return reviews, nil
+}
+
+// GetTopics return repository topics, Bitbucket has none
+func (d *BitbucketDownloader) GetTopics() ([]string, error) {
+	return []string{}, nil
+}
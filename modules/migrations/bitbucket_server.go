@@ -0,0 +1,446 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/migrations/base"
+)
+
+var (
+	_ base.Downloader = &BitbucketServerDownloader{}
+)
+
+// BitbucketServerDownloader implements a Downloader interface to get repository information
+// from a Bitbucket Server or Data Center instance
+type BitbucketServerDownloader struct {
+	base.NullDownloader
+	client     *bitbucketClient
+	repoPath   string
+	maxPerPage int
+}
+
+// NewBitbucketServerDownloader creates a Bitbucket Server downloader for the repository project/repoSlug
+func NewBitbucketServerDownloader(ctx context.Context, baseURL, userName, password, token, project, repoSlug string) *BitbucketServerDownloader {
+	return &BitbucketServerDownloader{
+		client:     newBitbucketClient(ctx, strings.TrimSuffix(baseURL, "/")+"/rest/api/1.0", userName, password, token),
+		repoPath:   fmt.Sprintf("projects/%s/repos/%s", url.PathEscape(project), url.PathEscape(repoSlug)),
+		maxPerPage: 100,
+	}
+}
+
+// SetContext set context
+func (d *BitbucketServerDownloader) SetContext(ctx context.Context) {
+	d.client.ctx = ctx
+}
+
+// FormatCloneURL add authentication into remote URLs
+func (d *BitbucketServerDownloader) FormatCloneURL(opts base.MigrateOptions, remoteAddr string) (string, error) {
+	return formatBitbucketCloneURL(opts, remoteAddr)
+}
+
+type bitbucketServerUser struct {
+	Name         string `json:"name"`
+	EmailAddress string `json:"emailAddress"`
+	DisplayName  string `json:"displayName"`
+	ID           int64  `json:"id"`
+}
+
+type bitbucketServerRef struct {
+	DisplayID    string `json:"displayId"`
+	LatestCommit string `json:"latestCommit"`
+	Repository   struct {
+		Slug    string `json:"slug"`
+		Project struct {
+			Key string `json:"key"`
+		} `json:"project"`
+		Links struct {
+			Clone []struct {
+				Name string `json:"name"`
+				Href string `json:"href"`
+			} `json:"clone"`
+		} `json:"links"`
+	} `json:"repository"`
+}
+
+type bitbucketServerComment struct {
+	ID          int64                     `json:"id"`
+	Text        string                    `json:"text"`
+	Author      bitbucketServerUser       `json:"author"`
+	CreatedDate int64                     `json:"createdDate"`
+	UpdatedDate int64                     `json:"updatedDate"`
+	Comments    []*bitbucketServerComment `json:"comments"`
+}
+
+type bitbucketServerActivity struct {
+	ID            int64                   `json:"id"`
+	CreatedDate   int64                   `json:"createdDate"`
+	User          bitbucketServerUser     `json:"user"`
+	Action        string                  `json:"action"`
+	CommentAction string                  `json:"commentAction"`
+	Comment       *bitbucketServerComment `json:"comment"`
+	CommentAnchor *struct {
+		FromHash string `json:"fromHash"`
+		ToHash   string `json:"toHash"`
+		Line     int    `json:"line"`
+		LineType string `json:"lineType"`
+		FileType string `json:"fileType"`
+		Path     string `json:"path"`
+	} `json:"commentAnchor"`
+}
+
+// bitbucketServerTime converts the milliseconds since the epoch used by Bitbucket Server
+func bitbucketServerTime(millis int64) time.Time {
+	return time.Unix(0, millis*int64(time.Millisecond)).UTC()
+}
+
+// getPage gets one page of a paginated endpoint and returns whether it was the last one
+func (d *BitbucketServerDownloader) getPage(endpoint string, parameter url.Values, start, limit int, values interface{}) (bool, int, error) {
+	if parameter == nil {
+		parameter = url.Values{}
+	}
+	parameter.Set("start", strconv.Itoa(start))
+	parameter.Set("limit", strconv.Itoa(limit))
+
+	result := struct {
+		Values        interface{} `json:"values"`
+		IsLastPage    bool        `json:"isLastPage"`
+		NextPageStart int         `json:"nextPageStart"`
+	}{Values: values}
+	if err := d.client.callAPI(endpoint, parameter, &result); err != nil {
+		return false, 0, err
+	}
+	return result.IsLastPage, result.NextPageStart, nil
+}
+
+// GetRepoInfo returns repository information
+func (d *BitbucketServerDownloader) GetRepoInfo() (*base.Repository, error) {
+	var repo struct {
+		Slug        string `json:"slug"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Public      bool   `json:"public"`
+		Project     struct {
+			Key string `json:"key"`
+		} `json:"project"`
+		Links struct {
+			Clone []struct {
+				Name string `json:"name"`
+				Href string `json:"href"`
+			} `json:"clone"`
+			Self []struct {
+				Href string `json:"href"`
+			} `json:"self"`
+		} `json:"links"`
+	}
+	if err := d.client.callAPI(d.repoPath, nil, &repo); err != nil {
+		return nil, err
+	}
+
+	var cloneURL string
+	for _, clone := range repo.Links.Clone {
+		if clone.Name == "http" || clone.Name == "https" {
+			u, err := url.Parse(clone.Href)
+			if err != nil {
+				return nil, err
+			}
+			// the clone url contains the name of the authenticated user
+			u.User = nil
+			cloneURL = u.String()
+		}
+	}
+	var originalURL string
+	if len(repo.Links.Self) > 0 {
+		originalURL = repo.Links.Self[0].Href
+	}
+
+	var defaultBranch struct {
+		DisplayID string `json:"displayId"`
+	}
+	// empty repositories have no default branch
+	if err := d.client.callAPI(d.repoPath+"/default-branch", nil, &defaultBranch); err != nil && !isBitbucketNotFound(err) {
+		return nil, err
+	}
+
+	return &base.Repository{
+		Name:          repo.Name,
+		Owner:         repo.Project.Key,
+		IsPrivate:     !repo.Public,
+		Description:   repo.Description,
+		CloneURL:      cloneURL,
+		OriginalURL:   originalURL,
+		DefaultBranch: defaultBranch.DisplayID,
+	}, nil
+}
+
+// GetTopics return repository topics, Bitbucket Server has none
+func (d *BitbucketServerDownloader) GetTopics() ([]string, error) {
+	return []string{}, nil
+}
+
+// GetMilestones returns milestones, Bitbucket Server has none
+func (d *BitbucketServerDownloader) GetMilestones() ([]*base.Milestone, error) {
+	return []*base.Milestone{}, nil
+}
+
+// GetLabels returns labels, Bitbucket Server has none
+func (d *BitbucketServerDownloader) GetLabels() ([]*base.Label, error) {
+	return []*base.Label{}, nil
+}
+
+// GetReleases returns releases, Bitbucket Server has none and its tags are migrated with the git data
+func (d *BitbucketServerDownloader) GetReleases() ([]*base.Release, error) {
+	return []*base.Release{}, nil
+}
+
+// GetIssues returns issues, Bitbucket Server has no issue tracker
+func (d *BitbucketServerDownloader) GetIssues(page, perPage int) ([]*base.Issue, bool, error) {
+	return []*base.Issue{}, true, nil
+}
+
+// GetPullRequests returns pull requests according page and perPage
+func (d *BitbucketServerDownloader) GetPullRequests(page, perPage int) ([]*base.PullRequest, bool, error) {
+	if perPage > d.maxPerPage {
+		perPage = d.maxPerPage
+	}
+
+	rawPullRequests := make([]struct {
+		ID          int64              `json:"id"`
+		Title       string             `json:"title"`
+		Description string             `json:"description"`
+		State       string             `json:"state"`
+		Locked      bool               `json:"locked"`
+		CreatedDate int64              `json:"createdDate"`
+		UpdatedDate int64              `json:"updatedDate"`
+		ClosedDate  int64              `json:"closedDate"`
+		FromRef     bitbucketServerRef `json:"fromRef"`
+		ToRef       bitbucketServerRef `json:"toRef"`
+		Author      struct {
+			User bitbucketServerUser `json:"user"`
+		} `json:"author"`
+		Reviewers []struct {
+			User bitbucketServerUser `json:"user"`
+		} `json:"reviewers"`
+		Properties struct {
+			MergeCommit *struct {
+				ID string `json:"id"`
+			} `json:"mergeCommit"`
+		} `json:"properties"`
+	}, 0, perPage)
+	isLast, _, err := d.getPage(d.repoPath+"/pull-requests", url.Values{
+		"state": {"ALL"},
+		"order": {"OLDEST"},
+	}, (page-1)*perPage, perPage, &rawPullRequests)
+	if err != nil {
+		return nil, false, err
+	}
+
+	pullRequests := make([]*base.PullRequest, 0, len(rawPullRequests))
+	for _, pr := range rawPullRequests {
+		state := "open"
+		merged := false
+		var closed, mergedTime *time.Time
+		if pr.State != "OPEN" {
+			state = "closed"
+			closedDate := pr.ClosedDate
+			if closedDate == 0 {
+				closedDate = pr.UpdatedDate
+			}
+			closedTime := bitbucketServerTime(closedDate)
+			closed = &closedTime
+			if pr.State == "MERGED" {
+				merged = true
+				mergedTime = &closedTime
+			}
+		}
+
+		var mergeCommitSHA string
+		if pr.Properties.MergeCommit != nil {
+			mergeCommitSHA = pr.Properties.MergeCommit.ID
+		}
+
+		pullRequests = append(pullRequests, &base.PullRequest{
+			Number:         pr.ID,
+			Title:          pr.Title,
+			PosterName:     pr.Author.User.Name,
+			PosterEmail:    pr.Author.User.EmailAddress,
+			Content:        pr.Description,
+			State:          state,
+			IsLocked:       pr.Locked,
+			Created:        bitbucketServerTime(pr.CreatedDate),
+			Updated:        bitbucketServerTime(pr.UpdatedDate),
+			Closed:         closed,
+			Merged:         merged,
+			MergedTime:     mergedTime,
+			MergeCommitSHA: mergeCommitSHA,
+			Head:           pullRequestServerBranch(pr.FromRef),
+			Base:           pullRequestServerBranch(pr.ToRef),
+			Context: bitbucketIssueContext{
+				foreignID:     pr.ID,
+				localID:       pr.ID,
+				IsPullRequest: true,
+			},
+		})
+	}
+
+	return pullRequests, isLast, nil
+}
+
+func pullRequestServerBranch(ref bitbucketServerRef) base.PullRequestBranch {
+	branch := base.PullRequestBranch{
+		Ref:       ref.DisplayID,
+		SHA:       ref.LatestCommit,
+		OwnerName: ref.Repository.Project.Key,
+		RepoName:  ref.Repository.Slug,
+	}
+	for _, clone := range ref.Repository.Links.Clone {
+		if clone.Name == "http" || clone.Name == "https" {
+			if u, err := url.Parse(clone.Href); err == nil {
+				u.User = nil
+				branch.CloneURL = u.String()
+			}
+		}
+	}
+	return branch
+}
+
+// getActivities returns all activities of the pull request, oldest first
+func (d *BitbucketServerDownloader) getActivities(context bitbucketIssueContext) ([]*bitbucketServerActivity, error) {
+	endpoint := fmt.Sprintf("%s/pull-requests/%d/activities", d.repoPath, context.ForeignID())
+
+	activities := make([]*bitbucketServerActivity, 0, 10)
+	for start := 0; ; {
+		page := make([]*bitbucketServerActivity, 0, d.maxPerPage)
+		isLast, next, err := d.getPage(endpoint, nil, start, d.maxPerPage, &page)
+		if err != nil {
+			return nil, err
+		}
+		activities = append(activities, page...)
+		if isLast || len(page) == 0 {
+			break
+		}
+		start = next
+	}
+
+	// activities are returned newest first
+	sort.SliceStable(activities, func(i, j int) bool {
+		return activities[i].CreatedDate < activities[j].CreatedDate
+	})
+	return activities, nil
+}
+
+// flattenBitbucketServerComments returns the comment and all its replies, calling fn with each and its parent id
+func flattenBitbucketServerComments(comment *bitbucketServerComment, parentID int64, fn func(comment *bitbucketServerComment, parentID int64)) {
+	fn(comment, parentID)
+	for _, reply := range comment.Comments {
+		flattenBitbucketServerComments(reply, comment.ID, fn)
+	}
+}
+
+// GetComments returns comments according issueNumber
+func (d *BitbucketServerDownloader) GetComments(opts base.GetCommentOptions) ([]*base.Comment, bool, error) {
+	context, ok := opts.Context.(bitbucketIssueContext)
+	if !ok {
+		return nil, false, fmt.Errorf("unexpected comment context: %+v", opts.Context)
+	}
+
+	activities, err := d.getActivities(context)
+	if err != nil {
+		return nil, false, err
+	}
+
+	comments := make([]*base.Comment, 0, len(activities))
+	for _, activity := range activities {
+		// inline comments are part of reviews
+		if activity.Action != "COMMENTED" || activity.CommentAction != "ADDED" || activity.Comment == nil || activity.CommentAnchor != nil {
+			continue
+		}
+		flattenBitbucketServerComments(activity.Comment, 0, func(comment *bitbucketServerComment, _ int64) {
+			comments = append(comments, &base.Comment{
+				IssueIndex:  context.LocalID(),
+				PosterName:  comment.Author.Name,
+				PosterEmail: comment.Author.EmailAddress,
+				Content:     comment.Text,
+				Created:     bitbucketServerTime(comment.CreatedDate),
+				Updated:     bitbucketServerTime(comment.UpdatedDate),
+			})
+		})
+	}
+	sort.SliceStable(comments, func(i, j int) bool {
+		return comments[i].Created.Before(comments[j].Created)
+	})
+	return comments, true, nil
+}
+
+// GetReviews returns the approvals of the pull request and its inline comments as reviews
+func (d *BitbucketServerDownloader) GetReviews(context base.IssueContext) ([]*base.Review, error) {
+	bbContext, ok := context.(bitbucketIssueContext)
+	if !ok {
+		return nil, fmt.Errorf("unexpected review context: %+v", context)
+	}
+
+	activities, err := d.getActivities(bbContext)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews := make([]*base.Review, 0, 10)
+	for _, activity := range activities {
+		switch activity.Action {
+		case "APPROVED", "REVIEWED":
+			state := base.ReviewStateApproved
+			if activity.Action == "REVIEWED" {
+				// REVIEWED is recorded when a reviewer marks the pull request as needing work
+				state = base.ReviewStateChangesRequested
+			}
+			reviews = append(reviews, &base.Review{
+				IssueIndex:   bbContext.LocalID(),
+				ReviewerName: activity.User.Name,
+				CreatedAt:    bitbucketServerTime(activity.CreatedDate),
+				State:        state,
+			})
+		case "COMMENTED":
+			anchor := activity.CommentAnchor
+			if activity.CommentAction != "ADDED" || activity.Comment == nil || anchor == nil || anchor.Path == "" {
+				continue
+			}
+
+			line := anchor.Line
+			if anchor.FileType == "FROM" || anchor.LineType == "REMOVED" {
+				// lines of the old file are negative
+				line = -line
+			}
+
+			review := &base.Review{
+				IssueIndex:   bbContext.LocalID(),
+				ReviewerName: activity.User.Name,
+				CommitID:     anchor.ToHash,
+				CreatedAt:    bitbucketServerTime(activity.CreatedDate),
+				State:        base.ReviewStateCommented,
+			}
+			flattenBitbucketServerComments(activity.Comment, 0, func(comment *bitbucketServerComment, parentID int64) {
+				review.Comments = append(review.Comments, &base.ReviewComment{
+					ID:        comment.ID,
+					InReplyTo: parentID,
+					Content:   comment.Text,
+					TreePath:  anchor.Path,
+					Line:      line,
+					CommitID:  anchor.ToHash,
+					CreatedAt: bitbucketServerTime(comment.CreatedDate),
+					UpdatedAt: bitbucketServerTime(comment.UpdatedDate),
+				})
+			})
+			reviews = append(reviews, review)
+		}
+	}
+	return reviews, nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/migrations/base"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitbucketServerDownloadRepo(t *testing.T) {
+	server := newRecordedTestServer(t, "bitbucket_server")
+	downloader := NewBitbucketServerDownloader(context.Background(), server.URL, "", "", "", "TEST", "test_repo")
+
+	repo, err := downloader.GetRepoInfo()
+	assert.NoError(t, err)
+	assertRepositoryEqual(t, &base.Repository{
+		Name:          "test_repo",
+		Owner:         "TEST",
+		IsPrivate:     true,
+		Description:   "Test repository for testing migration from Bitbucket Server to gitea",
+		CloneURL:      "https://bitbucket.example.com/scm/test/test_repo.git",
+		OriginalURL:   "https://bitbucket.example.com/projects/TEST/repos/test_repo/browse",
+		DefaultBranch: "master",
+	}, repo)
+
+	issues, isEnd, err := downloader.GetIssues(1, 50)
+	assert.NoError(t, err)
+	assert.True(t, isEnd)
+	assert.Empty(t, issues)
+
+	prs, isEnd, err := downloader.GetPullRequests(1, 1)
+	assert.NoError(t, err)
+	assert.False(t, isEnd)
+	merged := time.Unix(1631354400, 0)
+	assertPullRequestsEqual(t, []*base.PullRequest{
+		{
+			Number:         1,
+			Title:          "Add a feature",
+			Content:        "The feature from my fork",
+			PosterName:     "second",
+			PosterEmail:    "second@example.com",
+			State:          "closed",
+			Created:        time.Unix(1631268000, 0),
+			Updated:        merged,
+			Closed:         &merged,
+			Merged:         true,
+			MergedTime:     &merged,
+			MergeCommitSHA: "0b1c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c",
+			Head: base.PullRequestBranch{
+				CloneURL:  "https://bitbucket.example.com/scm/~second/test_repo.git",
+				Ref:       "feature",
+				SHA:       "3f5a2e4c1b6d7e8f9a0b1c2d3e4f5a6b7c8d9e0f",
+				OwnerName: "~SECOND",
+				RepoName:  "test_repo",
+			},
+			Base: base.PullRequestBranch{
+				CloneURL:  "https://bitbucket.example.com/scm/test/test_repo.git",
+				Ref:       "master",
+				SHA:       "8d2e3f4a5b6c7d8e9f0a1b2c3d4e5f6a7b8c9d0e",
+				OwnerName: "TEST",
+				RepoName:  "test_repo",
+			},
+		},
+	}, prs)
+
+	morePRs, isEnd, err := downloader.GetPullRequests(2, 1)
+	assert.NoError(t, err)
+	assert.True(t, isEnd)
+	if assert.Len(t, morePRs, 1) {
+		assert.EqualValues(t, 2, morePRs[0].Number)
+		assert.Equal(t, "open", morePRs[0].State)
+		assert.Nil(t, morePRs[0].Closed)
+	}
+
+	comments, _, err := downloader.GetComments(base.GetCommentOptions{Context: prs[0].Context})
+	assert.NoError(t, err)
+	assertCommentsEqual(t, []*base.Comment{
+		{
+			IssueIndex:  1,
+			PosterName:  "tester",
+			PosterEmail: "tester@example.com",
+			Content:     "Looks good to me",
+			Created:     time.Unix(1631271600, 0),
+			Updated:     time.Unix(1631271600, 0),
+		},
+		{
+			IssueIndex:  1,
+			PosterName:  "second",
+			PosterEmail: "second@example.com",
+			Content:     "Thanks",
+			Created:     time.Unix(1631280000, 0),
+			Updated:     time.Unix(1631280000, 0),
+		},
+	}, comments)
+
+	reviews, err := downloader.GetReviews(prs[0].Context)
+	assert.NoError(t, err)
+	headSHA := "3f5a2e4c1b6d7e8f9a0b1c2d3e4f5a6b7c8d9e0f"
+	assertReviewsEqual(t, []*base.Review{
+		{
+			IssueIndex:   1,
+			ReviewerName: "tester",
+			CommitID:     headSHA,
+			CreatedAt:    time.Unix(1631277000, 0),
+			State:        base.ReviewStateCommented,
+			Comments: []*base.ReviewComment{
+				{
+					ID:        302,
+					Content:   "Please rename this",
+					TreePath:  "README.md",
+					Line:      3,
+					CommitID:  headSHA,
+					CreatedAt: time.Unix(1631277000, 0),
+					UpdatedAt: time.Unix(1631277000, 0),
+				},
+				{
+					ID:        303,
+					InReplyTo: 302,
+					Content:   "Renamed",
+					TreePath:  "README.md",
+					Line:      3,
+					CommitID:  headSHA,
+					CreatedAt: time.Unix(1631278800, 0),
+					UpdatedAt: time.Unix(1631278800, 0),
+				},
+			},
+		},
+		{
+			IssueIndex:   1,
+			ReviewerName: "tester",
+			CreatedAt:    time.Unix(1631350000, 0),
+			State:        base.ReviewStateChangesRequested,
+		},
+		{
+			IssueIndex:   1,
+			ReviewerName: "tester",
+			CreatedAt:    time.Unix(1631350800, 0),
+			State:        base.ReviewStateApproved,
+		},
+	}, reviews)
+}
@@ -0,0 +1,659 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations/base"
+	"code.gitea.io/gitea/modules/proxy"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/structs"
+)
+
+var (
+	_ base.Downloader        = &CodebaseDownloader{}
+	_ base.DownloaderFactory = &CodebaseDownloaderFactory{}
+)
+
+// codebaseAPIURL is the API of codebasehq.com
+const codebaseAPIURL = "https://api3.codebasehq.com"
+
+func init() {
+	RegisterDownloaderFactory(&CodebaseDownloaderFactory{})
+}
+
+// CodebaseDownloaderFactory defines a downloader factory
+type CodebaseDownloaderFactory struct {
+}
+
+// New returns a downloader related to this factory according MigrateOptions
+func (f *CodebaseDownloaderFactory) New(ctx context.Context, opts base.MigrateOptions) (base.Downloader, error) {
+	u, err := url.Parse(opts.CloneAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	// https://<account>.codebasehq.com/projects/<project>/repositories/<repo>/... or
+	// https://<account>.codebasehq.com/<project>/<repo>.git
+	var project, repoName string
+	fields := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(fields) >= 4 && fields[0] == "projects" && fields[2] == "repositories" {
+		project, repoName = fields[1], fields[3]
+	} else if len(fields) == 2 {
+		project, repoName = fields[0], strings.TrimSuffix(fields[1], ".git")
+	} else {
+		return nil, fmt.Errorf("invalid path: %s", u.Path)
+	}
+
+	// API users are given as <account>/<user>
+	userName := opts.AuthUsername
+	if userName != "" && !strings.Contains(userName, "/") {
+		account := strings.SplitN(u.Hostname(), ".", 2)[0]
+		userName = account + "/" + userName
+	}
+
+	log.Trace("Create codebase downloader. Project: %s RepoName: %s", project, repoName)
+
+	return NewCodebaseDownloader(ctx, codebaseAPIURL, userName, opts.AuthPassword, project, repoName), nil
+}
+
+// GitServiceType returns the type of git service
+func (f *CodebaseDownloaderFactory) GitServiceType() structs.GitServiceType {
+	return structs.CodebaseService
+}
+
+type codebaseUser struct {
+	ID        int64  `xml:"id"`
+	Username  string `xml:"username"`
+	FirstName string `xml:"first-name"`
+	LastName  string `xml:"last-name"`
+	Email     string `xml:"email-address"`
+}
+
+// codebaseIssueContext keeps the notes of a ticket, they hold its description and comments
+type codebaseIssueContext struct {
+	foreignID     int64
+	localID       int64
+	IsPullRequest bool
+	Comments      []*base.Comment
+}
+
+func (c codebaseIssueContext) LocalID() int64 {
+	return c.localID
+}
+
+func (c codebaseIssueContext) ForeignID() int64 {
+	return c.foreignID
+}
+
+// CodebaseDownloader implements a Downloader interface to get repository information
+// from Codebase via its XML API
+type CodebaseDownloader struct {
+	base.NullDownloader
+	ctx           context.Context
+	client        *http.Client
+	baseURL       *url.URL
+	userName      string
+	password      string
+	project       string
+	repoName      string
+	maxIssueIndex int64
+	userMap       map[int64]*codebaseUser
+	commitMap     map[string]string
+}
+
+// NewCodebaseDownloader creates a codebase downloader
+func NewCodebaseDownloader(ctx context.Context, baseURL, userName, password, project, repoName string) *CodebaseDownloader {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/") + "/")
+	if err != nil {
+		log.Error("Invalid codebase url %s: %v", baseURL, err)
+		u = &url.URL{}
+	}
+	return &CodebaseDownloader{
+		ctx: ctx,
+		client: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: setting.Migrations.SkipTLSVerify},
+				Proxy:           proxy.Proxy(),
+			},
+		},
+		baseURL:   u,
+		userName:  userName,
+		password:  password,
+		project:   project,
+		repoName:  repoName,
+		commitMap: make(map[string]string),
+	}
+}
+
+// SetContext set context
+func (d *CodebaseDownloader) SetContext(ctx context.Context) {
+	d.ctx = ctx
+}
+
+// codebaseNotFoundError is returned for missing resources, Codebase also answers so for pages past the last one
+type codebaseNotFoundError struct {
+	endpoint string
+}
+
+func (err codebaseNotFoundError) Error() string {
+	return fmt.Sprintf("codebase api %s not found", err.endpoint)
+}
+
+func isCodebaseNotFound(err error) bool {
+	_, ok := err.(codebaseNotFoundError)
+	return ok
+}
+
+func (d *CodebaseDownloader) callAPI(endpoint string, parameter url.Values, result interface{}) error {
+	u, err := d.baseURL.Parse(strings.TrimPrefix(endpoint, "/"))
+	if err != nil {
+		return err
+	}
+	if parameter != nil {
+		u.RawQuery = parameter.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(d.ctx, "GET", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(d.userName, d.password)
+	req.Header.Set("Accept", "application/xml")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return xml.NewDecoder(resp.Body).Decode(result)
+	case http.StatusNotFound:
+		return codebaseNotFoundError{endpoint}
+	default:
+		return fmt.Errorf("codebase api %s returned %s", endpoint, resp.Status)
+	}
+}
+
+// GetRepoInfo returns repository information
+func (d *CodebaseDownloader) GetRepoInfo() (*base.Repository, error) {
+	var repo struct {
+		Name          string `xml:"name"`
+		Permalink     string `xml:"permalink"`
+		Description   string `xml:"description"`
+		DefaultBranch string `xml:"default-branch"`
+		CloneURL      string `xml:"clone-url"`
+	}
+	if err := d.callAPI(fmt.Sprintf("/%s/%s", d.project, d.repoName), nil, &repo); err != nil {
+		return nil, err
+	}
+
+	cloneURL := repo.CloneURL
+	// the clone url of the API is the ssh one: git@codebasehq.com:<account>/<project>/<repo>.git
+	if strings.HasPrefix(cloneURL, "git@codebasehq.com:") {
+		if fields := strings.Split(strings.TrimPrefix(cloneURL, "git@codebasehq.com:"), "/"); len(fields) == 3 {
+			cloneURL = fmt.Sprintf("https://%s.codebasehq.com/%s/%s", fields[0], fields[1], fields[2])
+		}
+	}
+	originalURL := strings.TrimSuffix(cloneURL, ".git")
+	if u, err := url.Parse(cloneURL); err == nil {
+		originalURL = fmt.Sprintf("%s://%s/projects/%s/repositories/%s", u.Scheme, u.Host, d.project, repo.Permalink)
+	}
+
+	return &base.Repository{
+		Name:          repo.Name,
+		Owner:         d.project,
+		IsPrivate:     true,
+		Description:   repo.Description,
+		CloneURL:      cloneURL,
+		OriginalURL:   originalURL,
+		DefaultBranch: repo.DefaultBranch,
+	}, nil
+}
+
+// GetTopics return repository topics, Codebase has none
+func (d *CodebaseDownloader) GetTopics() ([]string, error) {
+	return []string{}, nil
+}
+
+// GetMilestones returns milestones
+func (d *CodebaseDownloader) GetMilestones() ([]*base.Milestone, error) {
+	var rawMilestones struct {
+		Milestones []struct {
+			Name        string    `xml:"name"`
+			Description string    `xml:"description"`
+			Deadline    string    `xml:"deadline"`
+			Status      string    `xml:"status"`
+			CreatedAt   time.Time `xml:"created-at"`
+			UpdatedAt   time.Time `xml:"updated-at"`
+		} `xml:"ticketing-milestone"`
+	}
+	if err := d.callAPI(fmt.Sprintf("/%s/milestones", d.project), nil, &rawMilestones); err != nil {
+		if isCodebaseNotFound(err) {
+			return []*base.Milestone{}, nil
+		}
+		return nil, err
+	}
+
+	milestones := make([]*base.Milestone, 0, len(rawMilestones.Milestones))
+	for i := range rawMilestones.Milestones {
+		milestone := rawMilestones.Milestones[i]
+		var deadline *time.Time
+		if t, err := time.Parse("2006-01-02", milestone.Deadline); err == nil {
+			deadline = &t
+		}
+		state := "open"
+		var closed *time.Time
+		// active milestones are open, completed and cancelled ones closed
+		if milestone.Status != "active" {
+			state = "closed"
+			closed = &milestone.UpdatedAt
+		}
+		milestones = append(milestones, &base.Milestone{
+			Title:       milestone.Name,
+			Description: milestone.Description,
+			Deadline:    deadline,
+			State:       state,
+			Created:     milestone.CreatedAt,
+			Updated:     &milestone.UpdatedAt,
+			Closed:      closed,
+		})
+	}
+	return milestones, nil
+}
+
+// codebaseTicketProperty is a status, priority, category or type of tickets
+type codebaseTicketProperty struct {
+	Name          string `xml:"name"`
+	Colour        string `xml:"colour"`
+	TreatAsClosed bool   `xml:"treat-as-closed"`
+}
+
+// codebaseLabelColors are the colours Codebase offers for priorities and statuses
+var codebaseLabelColors = map[string]string{
+	"blue":   "0052cc",
+	"green":  "0e8a16",
+	"grey":   "bfbfbf",
+	"orange": "ff8c00",
+	"purple": "5319e7",
+	"red":    "b60205",
+	"yellow": "fbca04",
+}
+
+func codebaseLabel(kind string, property codebaseTicketProperty) *base.Label {
+	color, ok := codebaseLabelColors[strings.ToLower(property.Colour)]
+	if !ok {
+		color = "c2e0c6"
+	}
+	return &base.Label{
+		Name:  kind + "/" + property.Name,
+		Color: color,
+	}
+}
+
+// GetLabels returns the ticket statuses, priorities, categories and types as labels
+func (d *CodebaseDownloader) GetLabels() ([]*base.Label, error) {
+	labels := make([]*base.Label, 0, 20)
+	for _, kind := range []string{"status", "priority", "category", "type"} {
+		var rawProperties struct {
+			Properties []codebaseTicketProperty `xml:",any"`
+		}
+		endpoint := fmt.Sprintf("/%s/tickets/%s", d.project, map[string]string{
+			"status":   "statuses",
+			"priority": "priorities",
+			"category": "categories",
+			"type":     "types",
+		}[kind])
+		if err := d.callAPI(endpoint, nil, &rawProperties); err != nil {
+			if isCodebaseNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		for _, property := range rawProperties.Properties {
+			labels = append(labels, codebaseLabel(kind, property))
+		}
+	}
+	return labels, nil
+}
+
+// GetReleases returns releases, Codebase has none and its tags are migrated with the git data
+func (d *CodebaseDownloader) GetReleases() ([]*base.Release, error) {
+	return []*base.Release{}, nil
+}
+
+// tryGetUser returns the user with the given id, users which can not be found are named after their id
+func (d *CodebaseDownloader) tryGetUser(userID int64) *codebaseUser {
+	if d.userMap == nil {
+		d.userMap = make(map[int64]*codebaseUser)
+
+		var rawUsers struct {
+			Users []*codebaseUser `xml:"user"`
+		}
+		if err := d.callAPI("/users", nil, &rawUsers); err != nil {
+			log.Warn("Unable to list the codebase users: %v", err)
+		}
+		for _, user := range rawUsers.Users {
+			d.userMap[user.ID] = user
+		}
+	}
+
+	user, ok := d.userMap[userID]
+	if !ok {
+		user = &codebaseUser{
+			ID:       userID,
+			Username: fmt.Sprintf("User %d", userID),
+		}
+		d.userMap[userID] = user
+	}
+	return user
+}
+
+// GetIssues returns all tickets, Codebase pages them by 20
+func (d *CodebaseDownloader) GetIssues(page, perPage int) ([]*base.Issue, bool, error) {
+	var rawTickets struct {
+		Tickets []struct {
+			TicketID  int64                  `xml:"ticket-id"`
+			Summary   string                 `xml:"summary"`
+			Reporter  string                 `xml:"reporter"`
+			Assignee  string                 `xml:"assignee"`
+			Status    codebaseTicketProperty `xml:"status"`
+			Priority  codebaseTicketProperty `xml:"priority"`
+			Category  codebaseTicketProperty `xml:"category"`
+			Type      codebaseTicketProperty `xml:"type"`
+			Milestone struct {
+				Name string `xml:"name"`
+			} `xml:"milestone"`
+			CreatedAt time.Time `xml:"created-at"`
+			UpdatedAt time.Time `xml:"updated-at"`
+		} `xml:"ticket"`
+	}
+	err := d.callAPI(fmt.Sprintf("/%s/tickets", d.project), url.Values{
+		"query": {"sort:id order:asc status:all"},
+		"page":  {strconv.Itoa(page)},
+	}, &rawTickets)
+	if err != nil {
+		if isCodebaseNotFound(err) {
+			return []*base.Issue{}, true, nil
+		}
+		return nil, false, err
+	}
+
+	issues := make([]*base.Issue, 0, len(rawTickets.Tickets))
+	for _, ticket := range rawTickets.Tickets {
+		notes, err := d.getTicketNotes(ticket.TicketID)
+		if err != nil {
+			return nil, false, err
+		}
+		// the first note holds the description of the ticket
+		var content string
+		if len(notes) > 0 {
+			content = notes[0].Content
+			notes = notes[1:]
+		}
+
+		labels := make([]*base.Label, 0, 4)
+		for kind, property := range map[string]codebaseTicketProperty{
+			"status":   ticket.Status,
+			"priority": ticket.Priority,
+			"category": ticket.Category,
+			"type":     ticket.Type,
+		} {
+			if property.Name != "" {
+				labels = append(labels, codebaseLabel(kind, property))
+			}
+		}
+		sort.Slice(labels, func(i, j int) bool {
+			return labels[i].Name < labels[j].Name
+		})
+
+		state := "open"
+		var closed *time.Time
+		if ticket.Status.TreatAsClosed {
+			state = "closed"
+			updated := ticket.UpdatedAt
+			closed = &updated
+		}
+
+		var assignees []string
+		if ticket.Assignee != "" {
+			assignees = []string{ticket.Assignee}
+		}
+
+		issues = append(issues, &base.Issue{
+			Number:     ticket.TicketID,
+			Title:      ticket.Summary,
+			Content:    content,
+			PosterName: ticket.Reporter,
+			Milestone:  ticket.Milestone.Name,
+			State:      state,
+			Created:    ticket.CreatedAt,
+			Updated:    ticket.UpdatedAt,
+			Closed:     closed,
+			Labels:     labels,
+			Assignees:  assignees,
+			Context: codebaseIssueContext{
+				foreignID: ticket.TicketID,
+				localID:   ticket.TicketID,
+				Comments:  notes,
+			},
+		})
+
+		if d.maxIssueIndex < ticket.TicketID {
+			d.maxIssueIndex = ticket.TicketID
+		}
+	}
+
+	return issues, len(rawTickets.Tickets) < 20, nil
+}
+
+// getTicketNotes returns the notes of a ticket with content, notes only changing the ticket are left out
+func (d *CodebaseDownloader) getTicketNotes(ticketID int64) ([]*base.Comment, error) {
+	var rawNotes struct {
+		Notes []struct {
+			Content   string    `xml:"content"`
+			UserID    int64     `xml:"user-id"`
+			CreatedAt time.Time `xml:"created-at"`
+			UpdatedAt time.Time `xml:"updated-at"`
+		} `xml:"ticket-note"`
+	}
+	if err := d.callAPI(fmt.Sprintf("/%s/tickets/%d/notes", d.project, ticketID), nil, &rawNotes); err != nil {
+		return nil, err
+	}
+
+	notes := make([]*base.Comment, 0, len(rawNotes.Notes))
+	for _, note := range rawNotes.Notes {
+		if strings.TrimSpace(note.Content) == "" {
+			continue
+		}
+		poster := d.tryGetUser(note.UserID)
+		notes = append(notes, &base.Comment{
+			IssueIndex:  ticketID,
+			PosterName:  poster.Username,
+			PosterEmail: poster.Email,
+			Content:     note.Content,
+			Created:     note.CreatedAt,
+			Updated:     note.UpdatedAt,
+		})
+	}
+	return notes, nil
+}
+
+// GetComments returns comments according issueNumber
+func (d *CodebaseDownloader) GetComments(opts base.GetCommentOptions) ([]*base.Comment, bool, error) {
+	context, ok := opts.Context.(codebaseIssueContext)
+	if !ok {
+		return nil, false, fmt.Errorf("unexpected comment context: %+v", opts.Context)
+	}
+	return context.Comments, true, nil
+}
+
+// getHeadCommit returns the commit at the head of the ref, empty if the ref is gone
+func (d *CodebaseDownloader) getHeadCommit(ref string) string {
+	commitRef, ok := d.commitMap[ref]
+	if ok {
+		return commitRef
+	}
+
+	var rawCommits struct {
+		Commits []struct {
+			Ref string `xml:"ref"`
+		} `xml:"commit"`
+	}
+	if err := d.callAPI(fmt.Sprintf("/%s/%s/commits/%s", d.project, d.repoName, url.PathEscape(ref)), nil, &rawCommits); err != nil {
+		log.Warn("Unable to get the head commit of %s: %v", ref, err)
+	} else if len(rawCommits.Commits) > 0 {
+		commitRef = rawCommits.Commits[0].Ref
+	}
+	d.commitMap[ref] = commitRef
+	return commitRef
+}
+
+// GetPullRequests returns the merge requests according page and perPage
+func (d *CodebaseDownloader) GetPullRequests(page, perPage int) ([]*base.PullRequest, bool, error) {
+	var rawMergeRequests struct {
+		MergeRequests []struct {
+			ID int64 `xml:"id"`
+		} `xml:"merge-request"`
+	}
+	err := d.callAPI(fmt.Sprintf("/%s/%s/merge_requests", d.project, d.repoName), url.Values{
+		"query": {"status:all"},
+		"page":  {strconv.Itoa(page)},
+	}, &rawMergeRequests)
+	if err != nil {
+		if isCodebaseNotFound(err) {
+			return []*base.PullRequest{}, true, nil
+		}
+		return nil, false, err
+	}
+
+	pullRequests := make([]*base.PullRequest, 0, len(rawMergeRequests.MergeRequests))
+	for _, mr := range rawMergeRequests.MergeRequests {
+		var rawMergeRequest struct {
+			ID        int64     `xml:"id"`
+			SourceRef string    `xml:"source-ref"`
+			TargetRef string    `xml:"target-ref"`
+			Subject   string    `xml:"subject"`
+			Status    string    `xml:"status"`
+			UserID    int64     `xml:"user-id"`
+			CreatedAt time.Time `xml:"created-at"`
+			UpdatedAt time.Time `xml:"updated-at"`
+			Comments  []struct {
+				Content   string    `xml:"content"`
+				Action    string    `xml:"action"`
+				UserID    int64     `xml:"user-id"`
+				CreatedAt time.Time `xml:"created-at"`
+				UpdatedAt time.Time `xml:"updated-at"`
+			} `xml:"comments>comment"`
+		}
+		if err := d.callAPI(fmt.Sprintf("/%s/%s/merge_requests/%d", d.project, d.repoName, mr.ID), nil, &rawMergeRequest); err != nil {
+			return nil, false, err
+		}
+
+		number := rawMergeRequest.ID + d.maxIssueIndex
+
+		state := "open"
+		merged := false
+		var closed, mergedTime *time.Time
+		comments := make([]*base.Comment, 0, len(rawMergeRequest.Comments))
+		var content string
+		for i, comment := range rawMergeRequest.Comments {
+			// the first comment holds the description of the merge request
+			if i == 0 && comment.Action == "new" {
+				content = comment.Content
+				continue
+			}
+			switch comment.Action {
+			case "merging", "merge":
+				merged = true
+				created := comment.CreatedAt
+				mergedTime = &created
+			case "rejecting", "reject", "deleting", "delete":
+				created := comment.CreatedAt
+				closed = &created
+			case "reopening", "reopen":
+				closed = nil
+			}
+			if strings.TrimSpace(comment.Content) == "" {
+				continue
+			}
+			poster := d.tryGetUser(comment.UserID)
+			comments = append(comments, &base.Comment{
+				IssueIndex:  number,
+				PosterName:  poster.Username,
+				PosterEmail: poster.Email,
+				Content:     comment.Content,
+				Created:     comment.CreatedAt,
+				Updated:     comment.UpdatedAt,
+			})
+		}
+		switch rawMergeRequest.Status {
+		case "merged":
+			merged = true
+			state = "closed"
+			if mergedTime == nil {
+				updated := rawMergeRequest.UpdatedAt
+				mergedTime = &updated
+			}
+			closed = mergedTime
+		case "rejected", "deleted":
+			state = "closed"
+			if closed == nil {
+				updated := rawMergeRequest.UpdatedAt
+				closed = &updated
+			}
+		default:
+			closed = nil
+		}
+
+		poster := d.tryGetUser(rawMergeRequest.UserID)
+		pullRequests = append(pullRequests, &base.PullRequest{
+			Number:      number,
+			Title:       rawMergeRequest.Subject,
+			Content:     content,
+			PosterName:  poster.Username,
+			PosterEmail: poster.Email,
+			State:       state,
+			Created:     rawMergeRequest.CreatedAt,
+			Updated:     rawMergeRequest.UpdatedAt,
+			Closed:      closed,
+			Merged:      merged,
+			MergedTime:  mergedTime,
+			Head: base.PullRequestBranch{
+				Ref:       rawMergeRequest.SourceRef,
+				SHA:       d.getHeadCommit(rawMergeRequest.SourceRef),
+				OwnerName: d.project,
+				RepoName:  d.repoName,
+			},
+			Base: base.PullRequestBranch{
+				Ref:       rawMergeRequest.TargetRef,
+				SHA:       d.getHeadCommit(rawMergeRequest.TargetRef),
+				OwnerName: d.project,
+				RepoName:  d.repoName,
+			},
+			Context: codebaseIssueContext{
+				foreignID:     rawMergeRequest.ID,
+				localID:       number,
+				IsPullRequest: true,
+				Comments:      comments,
+			},
+		})
+	}
+
+	return pullRequests, len(rawMergeRequests.MergeRequests) < 20, nil
+}
@@ -0,0 +1,180 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/migrations/base"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodebaseDownloaderFactory(t *testing.T) {
+	factory := &CodebaseDownloaderFactory{}
+	for _, cloneAddr := range []string{
+		"https://gitea-test.codebasehq.com/projects/test-project/repositories/test_repo/tree/master",
+		"https://gitea-test.codebasehq.com/test-project/test_repo.git",
+	} {
+		downloader, err := factory.New(context.Background(), base.MigrateOptions{
+			CloneAddr:    cloneAddr,
+			AuthUsername: "tester",
+			AuthPassword: "api-key",
+		})
+		assert.NoError(t, err)
+		if codebase, ok := downloader.(*CodebaseDownloader); assert.True(t, ok, cloneAddr) {
+			assert.Equal(t, codebaseAPIURL+"/", codebase.baseURL.String())
+			assert.Equal(t, "gitea-test/tester", codebase.userName)
+			assert.Equal(t, "test-project", codebase.project)
+			assert.Equal(t, "test_repo", codebase.repoName)
+		}
+	}
+
+	_, err := factory.New(context.Background(), base.MigrateOptions{CloneAddr: "https://gitea-test.codebasehq.com/test-project"})
+	assert.Error(t, err)
+}
+
+func TestCodebaseDownloadRepo(t *testing.T) {
+	server := newRecordedTestServer(t, "codebase")
+	downloader := NewCodebaseDownloader(context.Background(), server.URL, "gitea-test/tester", "api-key", "test-project", "test_repo")
+
+	repo, err := downloader.GetRepoInfo()
+	assert.NoError(t, err)
+	assertRepositoryEqual(t, &base.Repository{
+		Name:          "Test Repo",
+		Owner:         "test-project",
+		IsPrivate:     true,
+		Description:   "Test repository for testing migration from Codebase to gitea",
+		CloneURL:      "https://gitea-test.codebasehq.com/test-project/test_repo.git",
+		OriginalURL:   "https://gitea-test.codebasehq.com/projects/test-project/repositories/test_repo",
+		DefaultBranch: "master",
+	}, repo)
+
+	milestones, err := downloader.GetMilestones()
+	assert.NoError(t, err)
+	assertMilestonesEqual(t, []*base.Milestone{
+		{
+			Title:       "Version 1",
+			Description: "The first version",
+			Deadline:    timePtr(time.Date(2021, 9, 30, 0, 0, 0, 0, time.UTC)),
+			State:       "closed",
+			Created:     time.Date(2021, 9, 1, 10, 0, 0, 0, time.UTC),
+			Updated:     timePtr(time.Date(2021, 10, 1, 10, 0, 0, 0, time.UTC)),
+			Closed:      timePtr(time.Date(2021, 10, 1, 10, 0, 0, 0, time.UTC)),
+		},
+		{
+			Title:   "Version 2",
+			State:   "open",
+			Created: time.Date(2021, 9, 2, 10, 0, 0, 0, time.UTC),
+			Updated: timePtr(time.Date(2021, 9, 2, 10, 0, 0, 0, time.UTC)),
+		},
+	}, milestones)
+
+	labels, err := downloader.GetLabels()
+	assert.NoError(t, err)
+	assertLabelsEqual(t, []*base.Label{
+		{Name: "status/New", Color: "0e8a16"},
+		{Name: "status/Completed", Color: "bfbfbf"},
+		{Name: "priority/High", Color: "b60205"},
+		{Name: "type/Bug", Color: "c2e0c6"},
+	}, labels)
+
+	releases, err := downloader.GetReleases()
+	assert.NoError(t, err)
+	assert.Empty(t, releases)
+
+	issues, isEnd, err := downloader.GetIssues(1, 20)
+	assert.NoError(t, err)
+	assert.True(t, isEnd)
+	assertIssuesEqual(t, []*base.Issue{
+		{
+			Number:     1,
+			Title:      "Bug in the parser",
+			Content:    "The parser fails",
+			PosterName: "gitea-test",
+			Milestone:  "Version 1",
+			State:      "closed",
+			Created:    time.Date(2021, 9, 3, 10, 0, 0, 0, time.UTC),
+			Updated:    time.Date(2021, 9, 5, 10, 0, 0, 0, time.UTC),
+			Closed:     timePtr(time.Date(2021, 9, 5, 10, 0, 0, 0, time.UTC)),
+			Labels: []*base.Label{
+				{Name: "priority/High", Color: "b60205"},
+				{Name: "status/Completed", Color: "bfbfbf"},
+				{Name: "type/Bug", Color: "c2e0c6"},
+			},
+			Assignees: []string{"second"},
+		},
+		{
+			Number:     2,
+			Title:      "Add a feature",
+			Content:    "Please add it",
+			PosterName: "second",
+			State:      "open",
+			Created:    time.Date(2021, 9, 6, 10, 0, 0, 0, time.UTC),
+			Updated:    time.Date(2021, 9, 6, 10, 0, 0, 0, time.UTC),
+			Labels:     []*base.Label{{Name: "status/New", Color: "0e8a16"}},
+		},
+	}, issues)
+
+	comments, _, err := downloader.GetComments(base.GetCommentOptions{Context: issues[0].Context})
+	assert.NoError(t, err)
+	assertCommentsEqual(t, []*base.Comment{
+		{
+			IssueIndex:  1,
+			PosterName:  "second",
+			PosterEmail: "second@example.com",
+			Content:     "Fixed in master",
+			Created:     time.Date(2021, 9, 5, 10, 0, 0, 0, time.UTC),
+			Updated:     time.Date(2021, 9, 5, 10, 0, 0, 0, time.UTC),
+		},
+	}, comments)
+
+	prs, isEnd, err := downloader.GetPullRequests(1, 20)
+	assert.NoError(t, err)
+	assert.True(t, isEnd)
+	merged := time.Date(2021, 9, 5, 10, 0, 0, 0, time.UTC)
+	assertPullRequestsEqual(t, []*base.PullRequest{
+		{
+			Number:      3,
+			Title:       "Fix the parser",
+			Content:     "Fixes the parser",
+			PosterName:  "second",
+			PosterEmail: "second@example.com",
+			State:       "closed",
+			Created:     time.Date(2021, 9, 4, 10, 0, 0, 0, time.UTC),
+			Updated:     time.Date(2021, 9, 5, 12, 0, 0, 0, time.UTC),
+			Closed:      &merged,
+			Merged:      true,
+			MergedTime:  &merged,
+			Head: base.PullRequestBranch{
+				Ref:       "feature",
+				SHA:       "4d1c6a3b2e5f7d8c9b0a1f2e3d4c5b6a7f8e9d0c",
+				OwnerName: "test-project",
+				RepoName:  "test_repo",
+			},
+			Base: base.PullRequestBranch{
+				Ref:       "master",
+				SHA:       "9f8e7d6c5b4a3f2e1d0c9b8a7f6e5d4c3b2a1f0e",
+				OwnerName: "test-project",
+				RepoName:  "test_repo",
+			},
+		},
+	}, prs)
+
+	comments, _, err = downloader.GetComments(base.GetCommentOptions{Context: prs[0].Context})
+	assert.NoError(t, err)
+	assertCommentsEqual(t, []*base.Comment{
+		{
+			IssueIndex:  3,
+			PosterName:  "gitea-test",
+			PosterEmail: "test@example.com",
+			Content:     "Looks good",
+			Created:     time.Date(2021, 9, 5, 9, 0, 0, 0, time.UTC),
+			Updated:     time.Date(2021, 9, 5, 9, 0, 0, 0, time.UTC),
+		},
+	}, comments)
+}
@@ -0,0 +1,95 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations/base"
+	"code.gitea.io/gitea/modules/structs"
+)
+
+var (
+	_ base.Downloader        = &GitBucketDownloader{}
+	_ base.DownloaderFactory = &GitBucketDownloaderFactory{}
+)
+
+func init() {
+	RegisterDownloaderFactory(&GitBucketDownloaderFactory{})
+}
+
+// GitBucketDownloaderFactory defines a GitBucket downloader factory
+type GitBucketDownloaderFactory struct {
+}
+
+// New returns a Downloader related to this factory according MigrateOptions
+func (f *GitBucketDownloaderFactory) New(ctx context.Context, opts base.MigrateOptions) (base.Downloader, error) {
+	u, err := url.Parse(opts.CloneAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	// GitBucket may run below a context path, the owner and the repository are the last two fields:
+	// <base>/<owner>/<repo>.git or <base>/git/<owner>/<repo>.git
+	fields := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("invalid path: %s", u.Path)
+	}
+	oldOwner := fields[len(fields)-2]
+	oldName := strings.TrimSuffix(fields[len(fields)-1], ".git")
+	contextPath := fields[:len(fields)-2]
+	if len(contextPath) > 0 && contextPath[len(contextPath)-1] == "git" {
+		contextPath = contextPath[:len(contextPath)-1]
+	}
+
+	baseURL := u.Scheme + "://" + u.Host
+	if len(contextPath) > 0 {
+		baseURL += "/" + strings.Join(contextPath, "/")
+	}
+
+	log.Trace("Create GitBucket downloader. BaseURL: %s RepoOwner: %s RepoName: %s", baseURL, oldOwner, oldName)
+
+	return NewGitBucketDownloader(ctx, baseURL, opts.AuthUsername, opts.AuthPassword, opts.AuthToken, oldOwner, oldName), nil
+}
+
+// GitServiceType returns the type of git service
+func (f *GitBucketDownloaderFactory) GitServiceType() structs.GitServiceType {
+	return structs.GitBucketService
+}
+
+// GitBucketDownloader implements a Downloader interface to get repository information
+// from GitBucket, whose API is a subset of the GitHub API v3
+type GitBucketDownloader struct {
+	*GithubDownloaderV3
+}
+
+// NewGitBucketDownloader creates a GitBucket downloader
+func NewGitBucketDownloader(ctx context.Context, baseURL, userName, password, token, repoOwner, repoName string) *GitBucketDownloader {
+	githubDownloader := NewGithubDownloaderV3(ctx, baseURL, userName, password, token, repoOwner, repoName)
+	githubDownloader.SkipReactions = true
+	return &GitBucketDownloader{
+		githubDownloader,
+	}
+}
+
+// SupportGetRepoComments return true if it supports get repo comments,
+// GitBucket only lists comments per issue
+func (g *GitBucketDownloader) SupportGetRepoComments() bool {
+	return false
+}
+
+// GetTopics return repository topics, GitBucket has none
+func (g *GitBucketDownloader) GetTopics() ([]string, error) {
+	return []string{}, nil
+}
+
+// GetReviews returns pull requests review, GitBucket has no reviews
+func (g *GitBucketDownloader) GetReviews(context base.IssueContext) ([]*base.Review, error) {
+	return nil, &base.ErrNotSupported{Entity: "Reviews"}
+}
@@ -0,0 +1,142 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/migrations/base"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitBucketDownloaderFactory(t *testing.T) {
+	factory := &GitBucketDownloaderFactory{}
+	for cloneAddr, expected := range map[string]string{
+		"https://gitbucket.example.com/gitea-test/test_repo.git":     "https://gitbucket.example.com/api/v3/",
+		"https://gitbucket.example.com/git/gitea-test/test_repo.git": "https://gitbucket.example.com/api/v3/",
+		"https://example.com/gitbucket/git/gitea-test/test_repo.git": "https://example.com/gitbucket/api/v3/",
+		"https://example.com/gitbucket/gitea-test/test_repo":         "https://example.com/gitbucket/api/v3/",
+	} {
+		downloader, err := factory.New(context.Background(), base.MigrateOptions{CloneAddr: cloneAddr})
+		assert.NoError(t, err)
+		if gitbucket, ok := downloader.(*GitBucketDownloader); assert.True(t, ok, cloneAddr) {
+			assert.Equal(t, expected, gitbucket.getClient().BaseURL.String(), cloneAddr)
+			assert.Equal(t, "gitea-test", gitbucket.repoOwner)
+			assert.Equal(t, "test_repo", gitbucket.repoName)
+		}
+	}
+}
+
+func TestGitBucketDownloadRepo(t *testing.T) {
+	server := newRecordedTestServer(t, "gitbucket")
+	downloader := NewGitBucketDownloader(context.Background(), server.URL, "", "", "", "gitea-test", "test_repo")
+
+	repo, err := downloader.GetRepoInfo()
+	assert.NoError(t, err)
+	assertRepositoryEqual(t, &base.Repository{
+		Name:          "test_repo",
+		Owner:         "gitea-test",
+		Description:   "Test repository for testing migration from GitBucket to gitea",
+		CloneURL:      server.URL + "/git/gitea-test/test_repo.git",
+		OriginalURL:   server.URL + "/gitea-test/test_repo",
+		DefaultBranch: "master",
+	}, repo)
+
+	topics, err := downloader.GetTopics()
+	assert.NoError(t, err)
+	assert.Empty(t, topics)
+
+	releases, err := downloader.GetReleases()
+	assert.NoError(t, err)
+	size := 12
+	assertReleasesEqual(t, []*base.Release{
+		{
+			TagName:        "v1.0.0",
+			Name:           "First release",
+			Body:           "The first release",
+			PublisherName:  "gitea-test",
+			PublisherEmail: "test@example.com",
+			Created:        time.Date(2021, 9, 30, 10, 0, 0, 0, time.UTC),
+			Published:      time.Date(2021, 9, 30, 10, 0, 0, 0, time.UTC),
+			Assets: []*base.ReleaseAsset{
+				{
+					Name: "hello.txt",
+					Size: &size,
+				},
+			},
+		},
+	}, releases)
+	rc, err := releases[0].Assets[0].DownloadFunc()
+	assert.NoError(t, err)
+	content, err := io.ReadAll(rc)
+	rc.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"hello\": \"world\"}\n", string(content))
+
+	_, err = downloader.GetReviews(base.BasicIssueContext(1))
+	assert.True(t, base.IsErrNotSupported(err))
+}
+
+func TestGitBucketDownloadAssetCredentials(t *testing.T) {
+	var otherAuth []string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		otherAuth = append(otherAuth, r.URL.Path+" "+r.Header.Get("Authorization"))
+		fmt.Fprint(w, "hello")
+	}))
+	defer other.Close()
+
+	var apiAuth []string
+	var api *httptest.Server
+	api = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiAuth = append(apiAuth, r.URL.Path+" "+r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/api/v3/repos/gitea-test/test_repo/releases":
+			fmt.Fprintf(w, `[{"tag_name": "v1.0.0", "assets": [
+				{"name": "same-origin.txt", "browser_download_url": "%[1]s/gitea-test/test_repo/releases/v1.0.0/assets/same-origin.txt"},
+				{"name": "redirected.txt", "browser_download_url": "%[1]s/gitea-test/test_repo/releases/v1.0.0/assets/redirected.txt"},
+				{"name": "other-host.txt", "browser_download_url": "%[2]s/other-host.txt"}
+			]}]`, api.URL, other.URL)
+		case "/gitea-test/test_repo/releases/v1.0.0/assets/same-origin.txt":
+			fmt.Fprint(w, "hello")
+		case "/gitea-test/test_repo/releases/v1.0.0/assets/redirected.txt":
+			http.Redirect(w, r, other.URL+"/redirected.txt", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer api.Close()
+
+	downloader := NewGitBucketDownloader(context.Background(), api.URL, "", "", "token", "gitea-test", "test_repo")
+	releases, err := downloader.GetReleases()
+	assert.NoError(t, err)
+	if !assert.Len(t, releases, 1) || !assert.Len(t, releases[0].Assets, 3) {
+		return
+	}
+
+	// assets of other hosts and redirects to other hosts are downloaded without credentials
+	for _, asset := range releases[0].Assets {
+		rc, err := asset.DownloadFunc()
+		if assert.NoError(t, err, asset.Name) {
+			content, err := io.ReadAll(rc)
+			rc.Close()
+			assert.NoError(t, err)
+			assert.Equal(t, "hello", string(content), asset.Name)
+		}
+	}
+	assert.Equal(t, []string{
+		"/api/v3/repos/gitea-test/test_repo/releases Bearer token",
+		"/api/v3/rate_limit Bearer token",
+		"/gitea-test/test_repo/releases/v1.0.0/assets/same-origin.txt Bearer token",
+		"/gitea-test/test_repo/releases/v1.0.0/assets/redirected.txt Bearer token",
+	}, apiAuth)
+	assert.Equal(t, []string{"/redirected.txt ", "/other-host.txt "}, otherAuth)
+}
@@ -50,6 +50,8 @@ type GiteaLocalUploader struct {
 	userMap        map[int64]int64 // external user id mapping to user id
 	prCache        map[int64]*models.PullRequest
 	gitServiceType structs.GitServiceType
+	// issueForeignType is the foreign reference type of the issues, if not ForeignTypeIssues
+	issueForeignType string
 }
 
 // NewGiteaLocalUploader creates an gitea Uploader via gitea API v1
@@ -123,6 +125,7 @@ func (g *GiteaLocalUploader) CreateRepo(repo *base.Repository, opts base.Migrate
 		Wiki:           opts.Wiki,
 		Releases:       opts.Releases, // if didn't get releases, then sync them from tags
 		MirrorInterval: opts.MirrorInterval,
+		MirrorMetadata: opts.MirrorMetadata,
 	})
 
 	g.repo = r
@@ -451,8 +454,18 @@ func (g *GiteaLocalUploader) CreateIssues(issues ...*base.Issue) error {
 			return err
 		}
 
-		for _, is := range iss {
+		refs := make([]*models.ForeignReference, 0, len(iss))
+		for i, is := range iss {
 			g.issues.Store(is.Index, is)
+			refs = append(refs, &models.ForeignReference{
+				RepoID:       g.repo.ID,
+				LocalIndex:   is.Index,
+				ForeignIndex: foreignIndex(issues[i].Number, issues[i].Context),
+				Type:         g.foreignType(false),
+			})
+		}
+		if err := models.InsertForeignReferences(refs...); err != nil {
+			return err
 		}
 	}
 
@@ -583,11 +596,37 @@ func (g *GiteaLocalUploader) CreatePullRequests(prs ...*base.PullRequest) error
 	if err := models.InsertPullRequests(gprs...); err != nil {
 		return err
 	}
-	for _, pr := range gprs {
+	refs := make([]*models.ForeignReference, 0, len(gprs))
+	for i, pr := range gprs {
 		g.issues.Store(pr.Issue.Index, pr.Issue)
 		pull.AddToTaskQueue(pr)
+		refs = append(refs, &models.ForeignReference{
+			RepoID:       g.repo.ID,
+			LocalIndex:   pr.Index,
+			ForeignIndex: foreignIndex(prs[i].Number, prs[i].Context),
+			Type:         g.foreignType(true),
+		})
 	}
-	return nil
+	return models.InsertForeignReferences(refs...)
+}
+
+// foreignType returns the foreign reference type of the issues or pull requests
+func (g *GiteaLocalUploader) foreignType(isPull bool) string {
+	if isPull {
+		return models.ForeignTypePullRequests
+	}
+	if g.issueForeignType != "" {
+		return g.issueForeignType
+	}
+	return models.ForeignTypeIssues
+}
+
+// foreignIndex returns the index of an issue or pull request on the original service
+func foreignIndex(number int64, context base.IssueContext) int64 {
+	if context != nil {
+		return context.ForeignID()
+	}
+	return number
 }
 
 func (g *GiteaLocalUploader) newPullRequest(pr *base.PullRequest) (*models.PullRequest, error) {
@@ -0,0 +1,472 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations/base"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/services/pull"
+)
+
+// newGiteaLocalSyncUploader creates an uploader to sync an already migrated repository
+func newGiteaLocalSyncUploader(ctx context.Context, doer *models.User, repo *models.Repository) (*GiteaLocalUploader, error) {
+	if err := models.InitForeignReferences(repo.ID); err != nil {
+		return nil, err
+	}
+	if err := models.RecalculateIssueIndexForRepo(repo.ID); err != nil {
+		return nil, err
+	}
+	return newGiteaLocalRepoUploader(ctx, doer, repo, repo.OriginalServiceType)
+}
+
+// newGiteaLocalRepoUploader creates an uploader adding to the labels, milestones, issues
+// and pull requests of an existing repository
+func newGiteaLocalRepoUploader(ctx context.Context, doer *models.User, repo *models.Repository, gitServiceType api.GitServiceType) (*GiteaLocalUploader, error) {
+	g := NewGiteaLocalUploader(ctx, doer, repo.OwnerName, repo.Name)
+	g.repo = repo
+	g.gitServiceType = gitServiceType
+
+	labels, err := models.GetLabelsByRepoID(repo.ID, "", db.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, label := range labels {
+		g.labels.Store(label.Name, label)
+	}
+
+	milestones, _, err := models.GetMilestones(models.GetMilestonesOption{
+		RepoID: repo.ID,
+		State:  api.StateAll,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, milestone := range milestones {
+		g.milestones.Store(milestone.Name, milestone.ID)
+	}
+
+	g.gitRepo, err = git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// getUserID returns the id of the local user linked to the given user of the original service
+func (g *GiteaLocalUploader) getUserID(externalID int64) int64 {
+	userid, ok := g.userMap[externalID]
+	tp := g.gitServiceType.Name()
+	if !ok && tp != "" {
+		var err error
+		userid, err = models.GetUserIDByExternalUserID(tp, fmt.Sprintf("%v", externalID))
+		if err != nil {
+			log.Error("GetUserIDByExternalUserID: %v", err)
+		}
+		if userid > 0 {
+			g.userMap[externalID] = userid
+		}
+	}
+	return userid
+}
+
+// migratedAuthorKey identifies the author of a migrated comment or review, whether or not
+// the author was linked to a local user
+func migratedAuthorKey(created timeutil.TimeStamp, posterID int64, originalAuthor string, originalAuthorID int64) string {
+	if originalAuthor != "" {
+		return fmt.Sprintf("%d/o%d", created, originalAuthorID)
+	}
+	return fmt.Sprintf("%d/u%d", created, posterID)
+}
+
+func (g *GiteaLocalUploader) loadIssue(index int64) (*models.Issue, error) {
+	if issue, ok := g.issues.Load(index); ok {
+		return issue.(*models.Issue), nil
+	}
+	issue, err := models.GetIssueByIndex(g.repo.ID, index)
+	if err != nil {
+		return nil, err
+	}
+	g.issues.Store(index, issue)
+	return issue, nil
+}
+
+// getSyncedIssue returns the local issue or pull request migrated before from the given one
+// of the original service, or nil if it has not been migrated yet
+func (g *GiteaLocalUploader) getSyncedIssue(number int64, context base.IssueContext, isPull bool) (*models.Issue, error) {
+	ref, err := models.GetForeignReference(g.repo.ID, foreignIndex(number, context), g.foreignType(isPull))
+	if err != nil {
+		if models.IsErrForeignReferenceNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	issue, err := models.GetIssueByIndex(g.repo.ID, ref.LocalIndex)
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return issue, nil
+}
+
+// newIssueIndex returns the index for a new issue or pull request, keeping the index of the
+// original service unless it is already taken locally or reserved for another new one
+func (g *GiteaLocalUploader) newIssueIndex(number int64, reserved map[int64]bool) (int64, error) {
+	for index := number; ; {
+		if !reserved[index] {
+			_, err := models.GetIssueByIndex(g.repo.ID, index)
+			if models.IsErrIssueNotExist(err) {
+				reserved[index] = true
+				return index, nil
+			} else if err != nil {
+				return 0, err
+			}
+		}
+		var err error
+		if index, err = db.GetNextResourceIndex("issue_index", g.repo.ID); err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (g *GiteaLocalUploader) updateSyncedIssue(issue *models.Issue, title, content, state, milestone string, isLocked bool, labels []*base.Label, updated time.Time, closed *time.Time) {
+	issue.Title = title
+	issue.Content = content
+	issue.IsClosed = state == "closed"
+	issue.IsLocked = isLocked
+
+	issue.MilestoneID = 0
+	if milestone != "" {
+		if id, ok := g.milestones.Load(milestone); ok {
+			issue.MilestoneID = id.(int64)
+		}
+	}
+
+	issue.Labels = make([]*models.Label, 0, len(labels))
+	for _, label := range labels {
+		if lb, ok := g.labels.Load(label.Name); ok {
+			issue.Labels = append(issue.Labels, lb.(*models.Label))
+		}
+	}
+
+	if !updated.IsZero() {
+		issue.UpdatedUnix = timeutil.TimeStamp(updated.Unix())
+	}
+	if !issue.IsClosed {
+		issue.ClosedUnix = 0
+	} else if closed != nil {
+		issue.ClosedUnix = timeutil.TimeStamp(closed.Unix())
+	}
+}
+
+// UpdateMilestones updates the milestones migrated before and creates the new ones
+func (g *GiteaLocalUploader) UpdateMilestones(milestones ...*base.Milestone) error {
+	var newMilestones = make([]*base.Milestone, 0, len(milestones))
+	for _, milestone := range milestones {
+		id, ok := g.milestones.Load(milestone.Title)
+		if !ok {
+			newMilestones = append(newMilestones, milestone)
+			continue
+		}
+
+		ms, err := models.GetMilestoneByRepoID(g.repo.ID, id.(int64))
+		if err != nil {
+			return err
+		}
+		oldIsClosed := ms.IsClosed
+		ms.Content = milestone.Description
+		ms.IsClosed = milestone.State == "closed"
+		if milestone.Deadline != nil {
+			ms.DeadlineUnix = timeutil.TimeStamp(milestone.Deadline.Unix())
+		}
+		if err := models.UpdateMilestone(ms, oldIsClosed); err != nil {
+			return err
+		}
+	}
+
+	if len(newMilestones) == 0 {
+		return nil
+	}
+	return g.CreateMilestones(newMilestones...)
+}
+
+// UpdateLabels updates the labels migrated before and creates the new ones
+func (g *GiteaLocalUploader) UpdateLabels(labels ...*base.Label) error {
+	var newLabels = make([]*base.Label, 0, len(labels))
+	for _, label := range labels {
+		lb, ok := g.labels.Load(label.Name)
+		if !ok {
+			newLabels = append(newLabels, label)
+			continue
+		}
+
+		l := lb.(*models.Label)
+		color := fmt.Sprintf("#%s", label.Color)
+		if l.Color == color && l.Description == label.Description {
+			continue
+		}
+		l.Color = color
+		l.Description = label.Description
+		if err := models.UpdateLabel(l); err != nil {
+			return err
+		}
+	}
+
+	if len(newLabels) == 0 {
+		return nil
+	}
+	return g.CreateLabels(newLabels...)
+}
+
+// UpdateReleases updates the releases migrated before and creates the new ones
+func (g *GiteaLocalUploader) UpdateReleases(releases ...*base.Release) error {
+	var newReleases = make([]*base.Release, 0, len(releases))
+	for _, release := range releases {
+		rel, err := models.GetRelease(g.repo.ID, release.TagName)
+		if err != nil {
+			if models.IsErrReleaseNotExist(err) {
+				newReleases = append(newReleases, release)
+				continue
+			}
+			return err
+		}
+
+		// the release may only have been a tag synced with the git data so far
+		rel.IsTag = false
+		rel.Title = release.Name
+		rel.Note = release.Body
+		rel.IsDraft = release.Draft
+		rel.IsPrerelease = release.Prerelease
+		if err := models.UpdateRelease(db.DefaultContext, rel); err != nil {
+			return err
+		}
+	}
+
+	if len(newReleases) == 0 {
+		return nil
+	}
+	return g.CreateReleases(newReleases...)
+}
+
+// UpdateIssues updates the issues migrated before and creates the new ones.
+// The number of every issue is changed to its local index.
+func (g *GiteaLocalUploader) UpdateIssues(issues ...*base.Issue) error {
+	var (
+		newIssues = make([]*base.Issue, 0, len(issues))
+		updated   = make([]*models.Issue, 0, len(issues))
+		reserved  = make(map[int64]bool)
+	)
+	for _, issue := range issues {
+		is, err := g.getSyncedIssue(issue.Number, issue.Context, false)
+		if err != nil {
+			return err
+		}
+		if is == nil {
+			if issue.Number, err = g.newIssueIndex(issue.Number, reserved); err != nil {
+				return err
+			}
+			newIssues = append(newIssues, issue)
+			continue
+		}
+
+		issue.Number = is.Index
+		is.Ref = issue.Ref
+		g.updateSyncedIssue(is, issue.Title, issue.Content, issue.State, issue.Milestone, issue.IsLocked, issue.Labels, issue.Updated, issue.Closed)
+		g.issues.Store(is.Index, is)
+		updated = append(updated, is)
+	}
+
+	if len(updated) > 0 {
+		if err := models.UpdateMigratedIssues(updated...); err != nil {
+			return err
+		}
+	}
+	if len(newIssues) == 0 {
+		return nil
+	}
+	return g.CreateIssues(newIssues...)
+}
+
+// UpdateComments updates the comments migrated before and creates the new ones.
+// Comments are matched by their issue, author and creation time.
+func (g *GiteaLocalUploader) UpdateComments(comments ...*base.Comment) error {
+	var (
+		newComments = make([]*base.Comment, 0, len(comments))
+		updated     = make([]*models.Comment, 0, len(comments))
+		existing    = make(map[int64]map[string]*models.Comment)
+	)
+	for _, comment := range comments {
+		issue, err := g.loadIssue(comment.IssueIndex)
+		if err != nil {
+			return err
+		}
+
+		known, ok := existing[issue.ID]
+		if !ok {
+			cms, err := models.FindComments(&models.FindCommentsOptions{
+				IssueID: issue.ID,
+				Type:    models.CommentTypeComment,
+			})
+			if err != nil {
+				return err
+			}
+			known = make(map[string]*models.Comment, len(cms))
+			for _, cm := range cms {
+				known[migratedAuthorKey(cm.CreatedUnix, cm.PosterID, cm.OriginalAuthor, cm.OriginalAuthorID)] = cm
+			}
+			existing[issue.ID] = known
+		}
+
+		created := issue.CreatedUnix
+		if !comment.Created.IsZero() {
+			created = timeutil.TimeStamp(comment.Created.Unix())
+		}
+		var key string
+		if userid := g.getUserID(comment.PosterID); userid > 0 {
+			key = migratedAuthorKey(created, userid, "", 0)
+		} else {
+			key = migratedAuthorKey(created, 0, comment.PosterName, comment.PosterID)
+		}
+
+		cm, ok := known[key]
+		if !ok {
+			newComments = append(newComments, comment)
+			continue
+		}
+		if cm.Content == comment.Content {
+			continue
+		}
+		cm.Content = comment.Content
+		if !comment.Updated.IsZero() {
+			cm.UpdatedUnix = timeutil.TimeStamp(comment.Updated.Unix())
+		}
+		updated = append(updated, cm)
+	}
+
+	if len(updated) > 0 {
+		if err := models.UpdateMigratedComments(updated...); err != nil {
+			return err
+		}
+	}
+	if len(newComments) == 0 {
+		return nil
+	}
+	return g.CreateComments(newComments...)
+}
+
+// UpdatePullRequests updates the pull requests migrated before and creates the new ones.
+// The number of every pull request is changed to its local index.
+func (g *GiteaLocalUploader) UpdatePullRequests(prs ...*base.PullRequest) error {
+	var (
+		newPRs   = make([]*base.PullRequest, 0, len(prs))
+		updated  = make([]*models.PullRequest, 0, len(prs))
+		reserved = make(map[int64]bool)
+	)
+	for _, pr := range prs {
+		issue, err := g.getSyncedIssue(pr.Number, pr.Context, true)
+		if err != nil {
+			return err
+		}
+		if issue == nil {
+			if pr.Number, err = g.newIssueIndex(pr.Number, reserved); err != nil {
+				return err
+			}
+			newPRs = append(newPRs, pr)
+			continue
+		}
+
+		pr.Number = issue.Index
+		g.updateSyncedIssue(issue, pr.Title, pr.Content, pr.State, pr.Milestone, pr.IsLocked, pr.Labels, pr.Updated, pr.Closed)
+		g.issues.Store(issue.Index, issue)
+
+		gpr, err := models.GetPullRequestByIssueIDWithNoAttributes(issue.ID)
+		if err != nil {
+			return err
+		}
+		gpr.Issue = issue
+		gpr.BaseBranch = pr.Base.Ref
+		if pr.Merged && !gpr.HasMerged {
+			gpr.HasMerged = true
+			if pr.MergedTime != nil {
+				gpr.MergedUnix = timeutil.TimeStamp(pr.MergedTime.Unix())
+			}
+			gpr.MergedCommitID = pr.MergeCommitSHA
+			gpr.MergerID = g.doer.ID
+		}
+		updated = append(updated, gpr)
+	}
+
+	if len(updated) > 0 {
+		if err := models.UpdateMigratedPullRequests(updated...); err != nil {
+			return err
+		}
+		for _, pr := range updated {
+			if !pr.Issue.IsClosed {
+				pull.AddToTaskQueue(pr)
+			}
+		}
+	}
+	if len(newPRs) == 0 {
+		return nil
+	}
+	return g.CreatePullRequests(newPRs...)
+}
+
+// UpdateReviews creates the reviews not migrated before.
+// Reviews are matched by their pull request, reviewer and creation time.
+func (g *GiteaLocalUploader) UpdateReviews(reviews ...*base.Review) error {
+	var (
+		newReviews = make([]*base.Review, 0, len(reviews))
+		existing   = make(map[int64]map[string]bool)
+	)
+	for _, review := range reviews {
+		issue, err := g.loadIssue(review.IssueIndex)
+		if err != nil {
+			return err
+		}
+
+		known, ok := existing[issue.ID]
+		if !ok {
+			rvs, err := models.FindReviews(models.FindReviewOptions{IssueID: issue.ID})
+			if err != nil {
+				return err
+			}
+			known = make(map[string]bool, len(rvs))
+			for _, rv := range rvs {
+				known[migratedAuthorKey(rv.CreatedUnix, rv.ReviewerID, rv.OriginalAuthor, rv.OriginalAuthorID)] = true
+			}
+			existing[issue.ID] = known
+		}
+
+		created := issue.CreatedUnix
+		if !review.CreatedAt.IsZero() {
+			created = timeutil.TimeStamp(review.CreatedAt.Unix())
+		}
+		var key string
+		if userid := g.getUserID(review.ReviewerID); userid > 0 {
+			key = migratedAuthorKey(created, userid, "", 0)
+		} else {
+			key = migratedAuthorKey(created, 0, review.ReviewerName, review.ReviewerID)
+		}
+		if !known[key] {
+			newReviews = append(newReviews, review)
+		}
+	}
+
+	if len(newReviews) == 0 {
+		return nil
+	}
+	return g.CreateReviews(newReviews...)
+}
@@ -0,0 +1,609 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations/base"
+	"code.gitea.io/gitea/modules/proxy"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/structs"
+)
+
+var (
+	_ base.Downloader        = &GiteeDownloader{}
+	_ base.DownloaderFactory = &GiteeDownloaderFactory{}
+)
+
+func init() {
+	RegisterDownloaderFactory(&GiteeDownloaderFactory{})
+}
+
+// GiteeDownloaderFactory defines a gitee downloader factory
+type GiteeDownloaderFactory struct {
+}
+
+// New returns a Downloader related to this factory according MigrateOptions
+func (f *GiteeDownloaderFactory) New(ctx context.Context, opts base.MigrateOptions) (base.Downloader, error) {
+	u, err := url.Parse(opts.CloneAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("invalid path: %s", u.Path)
+	}
+	oldOwner := fields[0]
+	oldName := strings.TrimSuffix(fields[1], ".git")
+
+	baseURL := u.Scheme + "://" + u.Host + "/api/v5"
+
+	log.Trace("Create gitee downloader. BaseURL: %s RepoOwner: %s RepoName: %s", baseURL, oldOwner, oldName)
+
+	return NewGiteeDownloader(ctx, baseURL, opts.AuthToken, oldOwner, oldName), nil
+}
+
+// GitServiceType returns the type of git service
+func (f *GiteeDownloaderFactory) GitServiceType() structs.GitServiceType {
+	return structs.GiteeService
+}
+
+type giteeUser struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type giteeLabel struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type giteeMilestone struct {
+	Title string `json:"title"`
+}
+
+type giteeComment struct {
+	ID          int64     `json:"id"`
+	Body        string    `json:"body"`
+	User        giteeUser `json:"user"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	CommentType string    `json:"comment_type"`
+	Path        string    `json:"path"`
+}
+
+type giteeBranch struct {
+	Ref  string    `json:"ref"`
+	Sha  string    `json:"sha"`
+	User giteeUser `json:"user"`
+	Repo *struct {
+		Path      string `json:"path"`
+		HTMLURL   string `json:"html_url"`
+		Namespace struct {
+			Path string `json:"path"`
+		} `json:"namespace"`
+	} `json:"repo"`
+}
+
+type giteeIssueContext struct {
+	// number is the identifier of issues on gitee, like I3ZRKW
+	number        string
+	foreignID     int64
+	localID       int64
+	IsPullRequest bool
+}
+
+func (c giteeIssueContext) LocalID() int64 {
+	return c.localID
+}
+
+func (c giteeIssueContext) ForeignID() int64 {
+	return c.foreignID
+}
+
+// GiteeDownloader implements a Downloader interface to get repository information
+// from gitee via API v5
+type GiteeDownloader struct {
+	base.NullDownloader
+	ctx           context.Context
+	client        *http.Client
+	baseURL       *url.URL
+	token         string
+	repoOwner     string
+	repoName      string
+	maxPerPage    int
+	maxIssueIndex int64
+}
+
+// NewGiteeDownloader creates a gitee downloader
+func NewGiteeDownloader(ctx context.Context, baseURL, token, repoOwner, repoName string) *GiteeDownloader {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/") + "/")
+	if err != nil {
+		log.Error("Invalid gitee url %s: %v", baseURL, err)
+		u = &url.URL{}
+	}
+	return &GiteeDownloader{
+		ctx: ctx,
+		client: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: setting.Migrations.SkipTLSVerify},
+				Proxy:           proxy.Proxy(),
+			},
+		},
+		baseURL:    u,
+		token:      token,
+		repoOwner:  repoOwner,
+		repoName:   repoName,
+		maxPerPage: 100,
+	}
+}
+
+// SetContext set context
+func (d *GiteeDownloader) SetContext(ctx context.Context) {
+	d.ctx = ctx
+}
+
+func (d *GiteeDownloader) callAPI(endpoint string, parameter url.Values, result interface{}) error {
+	u, err := d.baseURL.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+	query := u.Query()
+	for k, v := range parameter {
+		query[k] = v
+	}
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(d.ctx, "GET", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	// the token is not passed in the url, which ends up in the errors of failed requests
+	if d.token != "" {
+		req.Header.Set("Authorization", "token "+d.token)
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("gitee api %s returned %s", endpoint, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+func (d *GiteeDownloader) repoPath() string {
+	return fmt.Sprintf("repos/%s/%s", url.PathEscape(d.repoOwner), url.PathEscape(d.repoName))
+}
+
+func pageParameter(page, perPage int, parameter url.Values) url.Values {
+	if parameter == nil {
+		parameter = url.Values{}
+	}
+	parameter.Set("page", strconv.Itoa(page))
+	parameter.Set("per_page", strconv.Itoa(perPage))
+	return parameter
+}
+
+func convertGiteeLabels(giteeLabels []*giteeLabel) []*base.Label {
+	labels := make([]*base.Label, 0, len(giteeLabels))
+	for _, label := range giteeLabels {
+		labels = append(labels, &base.Label{
+			Name:  label.Name,
+			Color: strings.TrimPrefix(label.Color, "#"),
+		})
+	}
+	return labels
+}
+
+// GetRepoInfo returns repository information
+func (d *GiteeDownloader) GetRepoInfo() (*base.Repository, error) {
+	var repo struct {
+		Name          string `json:"name"`
+		Description   string `json:"description"`
+		Private       bool   `json:"private"`
+		HTMLURL       string `json:"html_url"`
+		DefaultBranch string `json:"default_branch"`
+		Namespace     struct {
+			Path string `json:"path"`
+		} `json:"namespace"`
+	}
+	if err := d.callAPI(d.repoPath(), nil, &repo); err != nil {
+		return nil, err
+	}
+
+	return &base.Repository{
+		Name:          repo.Name,
+		Owner:         repo.Namespace.Path,
+		IsPrivate:     repo.Private,
+		Description:   repo.Description,
+		CloneURL:      repo.HTMLURL + ".git",
+		OriginalURL:   repo.HTMLURL,
+		DefaultBranch: repo.DefaultBranch,
+	}, nil
+}
+
+// GetTopics return repository topics
+func (d *GiteeDownloader) GetTopics() ([]string, error) {
+	var repo struct {
+		ProjectLabels []struct {
+			Name string `json:"name"`
+		} `json:"project_labels"`
+	}
+	if err := d.callAPI(d.repoPath(), nil, &repo); err != nil {
+		return nil, err
+	}
+	topics := make([]string, 0, len(repo.ProjectLabels))
+	for _, label := range repo.ProjectLabels {
+		topics = append(topics, label.Name)
+	}
+	return topics, nil
+}
+
+// GetMilestones returns milestones
+func (d *GiteeDownloader) GetMilestones() ([]*base.Milestone, error) {
+	milestones := make([]*base.Milestone, 0, d.maxPerPage)
+	for page := 1; ; page++ {
+		rawMilestones := make([]struct {
+			Title       string     `json:"title"`
+			Description string     `json:"description"`
+			State       string     `json:"state"`
+			DueOn       *time.Time `json:"due_on"`
+			CreatedAt   time.Time  `json:"created_at"`
+			UpdatedAt   time.Time  `json:"updated_at"`
+		}, 0, d.maxPerPage)
+		if err := d.callAPI(d.repoPath()+"/milestones", pageParameter(page, d.maxPerPage, url.Values{"state": {"all"}}), &rawMilestones); err != nil {
+			return nil, err
+		}
+
+		for i := range rawMilestones {
+			milestone := rawMilestones[i]
+			var closed *time.Time
+			if milestone.State == "closed" {
+				closed = &milestone.UpdatedAt
+			}
+			milestones = append(milestones, &base.Milestone{
+				Title:       milestone.Title,
+				Description: milestone.Description,
+				Deadline:    milestone.DueOn,
+				State:       milestone.State,
+				Created:     milestone.CreatedAt,
+				Updated:     &milestone.UpdatedAt,
+				Closed:      closed,
+			})
+		}
+		if len(rawMilestones) < d.maxPerPage {
+			return milestones, nil
+		}
+	}
+}
+
+// GetLabels returns labels
+func (d *GiteeDownloader) GetLabels() ([]*base.Label, error) {
+	labels := make([]*base.Label, 0, d.maxPerPage)
+	for page := 1; ; page++ {
+		rawLabels := make([]*giteeLabel, 0, d.maxPerPage)
+		if err := d.callAPI(d.repoPath()+"/labels", pageParameter(page, d.maxPerPage, nil), &rawLabels); err != nil {
+			return nil, err
+		}
+		labels = append(labels, convertGiteeLabels(rawLabels)...)
+		if len(rawLabels) < d.maxPerPage {
+			return labels, nil
+		}
+	}
+}
+
+// GetReleases returns releases
+func (d *GiteeDownloader) GetReleases() ([]*base.Release, error) {
+	releases := make([]*base.Release, 0, d.maxPerPage)
+	for page := 1; ; page++ {
+		rawReleases := make([]struct {
+			ID              int64     `json:"id"`
+			TagName         string    `json:"tag_name"`
+			TargetCommitish string    `json:"target_commitish"`
+			Prerelease      bool      `json:"prerelease"`
+			Name            string    `json:"name"`
+			Body            string    `json:"body"`
+			Author          giteeUser `json:"author"`
+			CreatedAt       time.Time `json:"created_at"`
+			Assets          []struct {
+				Name               string `json:"name"`
+				BrowserDownloadURL string `json:"browser_download_url"`
+			} `json:"assets"`
+		}, 0, d.maxPerPage)
+		if err := d.callAPI(d.repoPath()+"/releases", pageParameter(page, d.maxPerPage, url.Values{"direction": {"asc"}}), &rawReleases); err != nil {
+			return nil, err
+		}
+
+		for _, rel := range rawReleases {
+			release := &base.Release{
+				TagName:         rel.TagName,
+				TargetCommitish: rel.TargetCommitish,
+				Name:            rel.Name,
+				Body:            rel.Body,
+				Prerelease:      rel.Prerelease,
+				PublisherID:     rel.Author.ID,
+				PublisherName:   rel.Author.Login,
+				PublisherEmail:  rel.Author.Email,
+				Created:         rel.CreatedAt,
+				Published:       rel.CreatedAt,
+			}
+			for i, asset := range rel.Assets {
+				// the source code archives are listed as assets without a name
+				if asset.Name == "" {
+					continue
+				}
+				release.Assets = append(release.Assets, &base.ReleaseAsset{
+					ID:           int64(i + 1),
+					Name:         asset.Name,
+					Created:      rel.CreatedAt,
+					Updated:      rel.CreatedAt,
+					DownloadFunc: d.download(asset.BrowserDownloadURL),
+				})
+			}
+			releases = append(releases, release)
+		}
+		if len(rawReleases) < d.maxPerPage {
+			return releases, nil
+		}
+	}
+}
+
+// download returns a function downloading an attachment of gitee
+func (d *GiteeDownloader) download(downloadURL string) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		req, err := http.NewRequestWithContext(d.ctx, "GET", downloadURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := d.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("download %s: %s", downloadURL, resp.Status)
+		}
+		return resp.Body, nil
+	}
+}
+
+// GetIssues returns issues according start and limit.
+// Gitee identifies issues by random strings, they are numbered in the order of their creation.
+func (d *GiteeDownloader) GetIssues(page, perPage int) ([]*base.Issue, bool, error) {
+	if perPage > d.maxPerPage {
+		perPage = d.maxPerPage
+	}
+
+	rawIssues := make([]struct {
+		Number     string          `json:"number"`
+		Title      string          `json:"title"`
+		Body       string          `json:"body"`
+		State      string          `json:"state"`
+		User       giteeUser       `json:"user"`
+		Labels     []*giteeLabel   `json:"labels"`
+		Assignee   *giteeUser      `json:"assignee"`
+		Milestone  *giteeMilestone `json:"milestone"`
+		CreatedAt  time.Time       `json:"created_at"`
+		UpdatedAt  time.Time       `json:"updated_at"`
+		FinishedAt *time.Time      `json:"finished_at"`
+	}, 0, perPage)
+	if err := d.callAPI(d.repoPath()+"/issues", pageParameter(page, perPage, url.Values{
+		"state":     {"all"},
+		"sort":      {"created"},
+		"direction": {"asc"},
+	}), &rawIssues); err != nil {
+		return nil, false, err
+	}
+
+	issues := make([]*base.Issue, 0, len(rawIssues))
+	for i, issue := range rawIssues {
+		number := int64((page-1)*perPage + i + 1)
+
+		state := "open"
+		var closed *time.Time
+		// progressing issues are open, rejected ones closed
+		if issue.State == "closed" || issue.State == "rejected" {
+			state = "closed"
+			closed = issue.FinishedAt
+			if closed == nil {
+				updated := issue.UpdatedAt
+				closed = &updated
+			}
+		}
+
+		var milestone string
+		if issue.Milestone != nil {
+			milestone = issue.Milestone.Title
+		}
+		var assignees []string
+		if issue.Assignee != nil {
+			assignees = []string{issue.Assignee.Login}
+		}
+
+		issues = append(issues, &base.Issue{
+			Number:      number,
+			Title:       issue.Title,
+			Content:     issue.Body,
+			PosterID:    issue.User.ID,
+			PosterName:  issue.User.Login,
+			PosterEmail: issue.User.Email,
+			Milestone:   milestone,
+			State:       state,
+			Created:     issue.CreatedAt,
+			Updated:     issue.UpdatedAt,
+			Closed:      closed,
+			Labels:      convertGiteeLabels(issue.Labels),
+			Assignees:   assignees,
+			Context: giteeIssueContext{
+				number:    issue.Number,
+				foreignID: number,
+				localID:   number,
+			},
+		})
+
+		if d.maxIssueIndex < number {
+			d.maxIssueIndex = number
+		}
+	}
+
+	return issues, len(rawIssues) < perPage, nil
+}
+
+// GetComments returns comments according issueNumber
+func (d *GiteeDownloader) GetComments(opts base.GetCommentOptions) ([]*base.Comment, bool, error) {
+	context, ok := opts.Context.(giteeIssueContext)
+	if !ok {
+		return nil, false, fmt.Errorf("unexpected comment context: %+v", opts.Context)
+	}
+
+	endpoint := fmt.Sprintf("%s/issues/%s/comments", d.repoPath(), url.PathEscape(context.number))
+	if context.IsPullRequest {
+		endpoint = fmt.Sprintf("%s/pulls/%s/comments", d.repoPath(), context.number)
+	}
+
+	comments := make([]*base.Comment, 0, d.maxPerPage)
+	for page := 1; ; page++ {
+		rawComments := make([]*giteeComment, 0, d.maxPerPage)
+		if err := d.callAPI(endpoint, pageParameter(page, d.maxPerPage, url.Values{"order": {"asc"}}), &rawComments); err != nil {
+			return nil, false, err
+		}
+
+		for _, comment := range rawComments {
+			content := comment.Body
+			// gitee gives no lines for code comments, they are kept as comments naming the file
+			if comment.CommentType == "diff_comment" && comment.Path != "" {
+				content = fmt.Sprintf("`%s`\n\n%s", comment.Path, comment.Body)
+			}
+			comments = append(comments, &base.Comment{
+				IssueIndex:  context.LocalID(),
+				PosterID:    comment.User.ID,
+				PosterName:  comment.User.Login,
+				PosterEmail: comment.User.Email,
+				Content:     content,
+				Created:     comment.CreatedAt,
+				Updated:     comment.UpdatedAt,
+			})
+		}
+		if len(rawComments) < d.maxPerPage {
+			return comments, true, nil
+		}
+	}
+}
+
+// GetPullRequests returns pull requests according page and perPage
+func (d *GiteeDownloader) GetPullRequests(page, perPage int) ([]*base.PullRequest, bool, error) {
+	if perPage > d.maxPerPage {
+		perPage = d.maxPerPage
+	}
+
+	rawPullRequests := make([]struct {
+		Number         int64           `json:"number"`
+		Title          string          `json:"title"`
+		Body           string          `json:"body"`
+		State          string          `json:"state"`
+		Locked         bool            `json:"locked"`
+		User           giteeUser       `json:"user"`
+		Labels         []*giteeLabel   `json:"labels"`
+		Assignees      []giteeUser     `json:"assignees"`
+		Milestone      *giteeMilestone `json:"milestone"`
+		Head           giteeBranch     `json:"head"`
+		Base           giteeBranch     `json:"base"`
+		MergeCommitSHA string          `json:"merge_commit_sha"`
+		CreatedAt      time.Time       `json:"created_at"`
+		UpdatedAt      time.Time       `json:"updated_at"`
+		ClosedAt       *time.Time      `json:"closed_at"`
+		MergedAt       *time.Time      `json:"merged_at"`
+	}, 0, perPage)
+	if err := d.callAPI(d.repoPath()+"/pulls", pageParameter(page, perPage, url.Values{
+		"state":     {"all"},
+		"sort":      {"created"},
+		"direction": {"asc"},
+	}), &rawPullRequests); err != nil {
+		return nil, false, err
+	}
+
+	pullRequests := make([]*base.PullRequest, 0, len(rawPullRequests))
+	for _, pr := range rawPullRequests {
+		state := "open"
+		if pr.State != "open" {
+			state = "closed"
+		}
+		closed := pr.ClosedAt
+		if closed == nil {
+			closed = pr.MergedAt
+		}
+
+		var milestone string
+		if pr.Milestone != nil {
+			milestone = pr.Milestone.Title
+		}
+		assignees := make([]string, 0, len(pr.Assignees))
+		for _, assignee := range pr.Assignees {
+			assignees = append(assignees, assignee.Login)
+		}
+
+		number := pr.Number + d.maxIssueIndex
+		pullRequests = append(pullRequests, &base.PullRequest{
+			Number:         number,
+			Title:          pr.Title,
+			Content:        pr.Body,
+			PosterID:       pr.User.ID,
+			PosterName:     pr.User.Login,
+			PosterEmail:    pr.User.Email,
+			Milestone:      milestone,
+			State:          state,
+			IsLocked:       pr.Locked,
+			Created:        pr.CreatedAt,
+			Updated:        pr.UpdatedAt,
+			Closed:         closed,
+			Labels:         convertGiteeLabels(pr.Labels),
+			Assignees:      assignees,
+			Merged:         pr.MergedAt != nil,
+			MergedTime:     pr.MergedAt,
+			MergeCommitSHA: pr.MergeCommitSHA,
+			Head:           convertGiteeBranch(pr.Head),
+			Base:           convertGiteeBranch(pr.Base),
+			Context: giteeIssueContext{
+				number:        strconv.FormatInt(pr.Number, 10),
+				foreignID:     pr.Number,
+				localID:       number,
+				IsPullRequest: true,
+			},
+		})
+	}
+
+	return pullRequests, len(rawPullRequests) < perPage, nil
+}
+
+func convertGiteeBranch(branch giteeBranch) base.PullRequestBranch {
+	prBranch := base.PullRequestBranch{
+		Ref:       branch.Ref,
+		SHA:       branch.Sha,
+		OwnerName: branch.User.Login,
+	}
+	if branch.Repo != nil {
+		prBranch.OwnerName = branch.Repo.Namespace.Path
+		prBranch.RepoName = branch.Repo.Path
+		prBranch.CloneURL = branch.Repo.HTMLURL + ".git"
+	}
+	return prBranch
+}
@@ -0,0 +1,242 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/migrations/base"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGiteeDownloaderFactory(t *testing.T) {
+	downloader, err := (&GiteeDownloaderFactory{}).New(context.Background(), base.MigrateOptions{
+		CloneAddr: "https://gitee.com/gitea-test/test_repo.git",
+		AuthToken: "token",
+	})
+	assert.NoError(t, err)
+	if gitee, ok := downloader.(*GiteeDownloader); assert.True(t, ok) {
+		assert.Equal(t, "https://gitee.com/api/v5/", gitee.baseURL.String())
+		assert.Equal(t, "gitea-test", gitee.repoOwner)
+		assert.Equal(t, "test_repo", gitee.repoName)
+		assert.Equal(t, "token", gitee.token)
+	}
+}
+
+func TestGiteeDownloadRepo(t *testing.T) {
+	server := newRecordedTestServer(t, "gitee")
+	downloader := NewGiteeDownloader(context.Background(), server.URL+"/api/v5", "", "gitea-test", "test_repo")
+	cst := time.FixedZone("CST", 8*60*60)
+
+	repo, err := downloader.GetRepoInfo()
+	assert.NoError(t, err)
+	assertRepositoryEqual(t, &base.Repository{
+		Name:          "test_repo",
+		Owner:         "gitea-test",
+		Description:   "Test repository for testing migration from gitee to gitea",
+		CloneURL:      "https://gitee.com/gitea-test/test_repo.git",
+		OriginalURL:   "https://gitee.com/gitea-test/test_repo",
+		DefaultBranch: "master",
+	}, repo)
+
+	topics, err := downloader.GetTopics()
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"gitea", "migration"}, topics)
+
+	milestones, err := downloader.GetMilestones()
+	assert.NoError(t, err)
+	assertMilestonesEqual(t, []*base.Milestone{
+		{
+			Title:       "1.0.0",
+			Description: "First release",
+			Deadline:    timePtr(time.Date(2021, 9, 30, 0, 0, 0, 0, cst)),
+			State:       "closed",
+			Created:     time.Date(2021, 9, 1, 10, 0, 0, 0, cst),
+			Updated:     timePtr(time.Date(2021, 10, 1, 10, 0, 0, 0, cst)),
+			Closed:      timePtr(time.Date(2021, 10, 1, 10, 0, 0, 0, cst)),
+		},
+		{
+			Title:   "1.1.0",
+			State:   "open",
+			Created: time.Date(2021, 9, 2, 10, 0, 0, 0, cst),
+			Updated: timePtr(time.Date(2021, 9, 2, 10, 0, 0, 0, cst)),
+		},
+	}, milestones)
+
+	labels, err := downloader.GetLabels()
+	assert.NoError(t, err)
+	assertLabelsEqual(t, []*base.Label{
+		{Name: "bug", Color: "d73a4a"},
+		{Name: "enhancement", Color: "a2eeef"},
+	}, labels)
+
+	releases, err := downloader.GetReleases()
+	assert.NoError(t, err)
+	assertReleasesEqual(t, []*base.Release{
+		{
+			TagName:         "v1.0.0",
+			TargetCommitish: "master",
+			Name:            "First release",
+			Body:            "The first release",
+			PublisherID:     1001,
+			PublisherName:   "gitea-test",
+			PublisherEmail:  "test@example.com",
+			Created:         time.Date(2021, 9, 30, 10, 0, 0, 0, cst),
+			Published:       time.Date(2021, 9, 30, 10, 0, 0, 0, cst),
+			Assets: []*base.ReleaseAsset{
+				{
+					ID:      1,
+					Name:    "test_repo.zip",
+					Created: time.Date(2021, 9, 30, 10, 0, 0, 0, cst),
+					Updated: time.Date(2021, 9, 30, 10, 0, 0, 0, cst),
+				},
+			},
+		},
+	}, releases)
+	rc, err := releases[0].Assets[0].DownloadFunc()
+	assert.NoError(t, err)
+	content, err := io.ReadAll(rc)
+	rc.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"content\": \"asset\"}\n", string(content))
+
+	issues, isEnd, err := downloader.GetIssues(1, 10)
+	assert.NoError(t, err)
+	assert.True(t, isEnd)
+	assertIssuesEqual(t, []*base.Issue{
+		{
+			Number:      1,
+			Title:       "Bug in the parser",
+			Content:     "The parser fails",
+			PosterID:    1001,
+			PosterName:  "gitea-test",
+			PosterEmail: "test@example.com",
+			Milestone:   "1.0.0",
+			State:       "closed",
+			Created:     time.Date(2021, 9, 3, 10, 0, 0, 0, cst),
+			Updated:     time.Date(2021, 9, 5, 10, 0, 0, 0, cst),
+			Closed:      timePtr(time.Date(2021, 9, 4, 10, 0, 0, 0, cst)),
+			Labels:      []*base.Label{{Name: "bug", Color: "d73a4a"}},
+			Assignees:   []string{"second"},
+		},
+		{
+			Number:     2,
+			Title:      "Add a feature",
+			Content:    "Please add it",
+			PosterID:   1002,
+			PosterName: "second",
+			State:      "open",
+			Created:    time.Date(2021, 9, 6, 10, 0, 0, 0, cst),
+			Updated:    time.Date(2021, 9, 6, 10, 0, 0, 0, cst),
+			Labels:     []*base.Label{},
+		},
+	}, issues)
+
+	comments, _, err := downloader.GetComments(base.GetCommentOptions{Context: issues[0].Context})
+	assert.NoError(t, err)
+	assertCommentsEqual(t, []*base.Comment{
+		{
+			IssueIndex: 1,
+			PosterID:   1002,
+			PosterName: "second",
+			Content:    "Confirmed",
+			Created:    time.Date(2021, 9, 3, 11, 0, 0, 0, cst),
+			Updated:    time.Date(2021, 9, 3, 11, 0, 0, 0, cst),
+		},
+	}, comments)
+
+	prs, isEnd, err := downloader.GetPullRequests(1, 10)
+	assert.NoError(t, err)
+	assert.True(t, isEnd)
+	merged := time.Date(2021, 9, 4, 10, 0, 0, 0, cst)
+	assertPullRequestsEqual(t, []*base.PullRequest{
+		{
+			Number:         3,
+			Title:          "Fix the parser",
+			Content:        "Fixes the parser",
+			PosterID:       1002,
+			PosterName:     "second",
+			Milestone:      "1.0.0",
+			State:          "closed",
+			Created:        time.Date(2021, 9, 3, 12, 0, 0, 0, cst),
+			Updated:        merged,
+			Closed:         &merged,
+			Labels:         []*base.Label{{Name: "bug", Color: "d73a4a"}},
+			Assignees:      []string{"gitea-test"},
+			Merged:         true,
+			MergedTime:     &merged,
+			MergeCommitSHA: "1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b",
+			Head: base.PullRequestBranch{
+				CloneURL:  "https://gitee.com/second/test_repo.git",
+				Ref:       "fix",
+				SHA:       "4d1c6a3b2e5f7d8c9b0a1f2e3d4c5b6a7f8e9d0c",
+				OwnerName: "second",
+				RepoName:  "test_repo",
+			},
+			Base: base.PullRequestBranch{
+				CloneURL:  "https://gitee.com/gitea-test/test_repo.git",
+				Ref:       "master",
+				SHA:       "9f8e7d6c5b4a3f2e1d0c9b8a7f6e5d4c3b2a1f0e",
+				OwnerName: "gitea-test",
+				RepoName:  "test_repo",
+			},
+		},
+	}, prs)
+
+	comments, _, err = downloader.GetComments(base.GetCommentOptions{Context: prs[0].Context})
+	assert.NoError(t, err)
+	assertCommentsEqual(t, []*base.Comment{
+		{
+			IssueIndex:  3,
+			PosterID:    1001,
+			PosterName:  "gitea-test",
+			PosterEmail: "test@example.com",
+			Content:     "Looks good",
+			Created:     time.Date(2021, 9, 3, 13, 0, 0, 0, cst),
+			Updated:     time.Date(2021, 9, 3, 13, 0, 0, 0, cst),
+		},
+		{
+			IssueIndex:  3,
+			PosterID:    1001,
+			PosterName:  "gitea-test",
+			PosterEmail: "test@example.com",
+			Content:     "`parser.go`\n\nTypo here",
+			Created:     time.Date(2021, 9, 3, 13, 5, 0, 0, cst),
+			Updated:     time.Date(2021, 9, 3, 13, 5, 0, 0, cst),
+		},
+	}, comments)
+
+	_, err = downloader.GetReviews(prs[0].Context)
+	assert.True(t, base.IsErrNotSupported(err))
+}
+
+func TestGiteeTokenNotInURL(t *testing.T) {
+	var auth, rawQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		rawQuery = r.URL.RawQuery
+		fmt.Fprint(w, `{"name": "test_repo"}`)
+	}))
+	downloader := NewGiteeDownloader(context.Background(), server.URL+"/api/v5", "secret-token", "gitea-test", "test_repo")
+
+	_, err := downloader.GetRepoInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "token secret-token", auth)
+	assert.Empty(t, rawQuery)
+
+	// the errors of failed requests do not reveal the token
+	server.Close()
+	_, err = downloader.GetRepoInfo()
+	if assert.Error(t, err) {
+		assert.NotContains(t, err.Error(), "secret-token")
+	}
+}
@@ -8,6 +8,7 @@ package migrations
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -77,6 +78,10 @@ type GithubDownloaderV3 struct {
 	rates        []*github.Rate
 	curClientIdx int
 	maxPerPage   int
+	// httpClient downloads assets from other hosts, without the credentials of the clients
+	httpClient *http.Client
+	// SkipReactions is set for GitHub compatible APIs without reactions
+	SkipReactions bool
 }
 
 // NewGithubDownloaderV3 creates a github Downloader via github v3 API
@@ -88,6 +93,19 @@ func NewGithubDownloaderV3(ctx context.Context, baseURL, userName, password, tok
 		repoOwner:  repoOwner,
 		repoName:   repoName,
 		maxPerPage: 100,
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: setting.Migrations.SkipTLSVerify},
+				Proxy:           proxy.Proxy(),
+			},
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= 10 {
+					return errors.New("stopped after 10 redirects")
+				}
+				req.Header.Del("Authorization")
+				return nil
+			},
+		},
 	}
 
 	if token != "" {
@@ -315,24 +333,22 @@ func (g *GithubDownloaderV3) convertGithubRelease(rel *github.RepositoryRelease)
 		r.Published = rel.PublishedAt.Time
 	}
 
-	httpClient := &http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: setting.Migrations.SkipTLSVerify},
-			Proxy:           proxy.Proxy(),
-		},
-	}
-
 	for _, asset := range rel.Assets {
-		var assetID = *asset.ID // Don't optimize this, for closure we need a local variable
+		var assetID = asset.GetID() // Don't optimize this, for closure we need a local variable
+		var browserDownloadURL = asset.GetBrowserDownloadURL()
 		r.Assets = append(r.Assets, &base.ReleaseAsset{
-			ID:            asset.GetID(),
+			ID:            assetID,
 			Name:          asset.GetName(),
 			ContentType:   asset.ContentType,
 			Size:          asset.Size,
 			DownloadCount: asset.DownloadCount,
-			Created:       asset.CreatedAt.Time,
-			Updated:       asset.UpdatedAt.Time,
+			Created:       asset.GetCreatedAt().Time,
+			Updated:       asset.GetUpdatedAt().Time,
 			DownloadFunc: func() (io.ReadCloser, error) {
+				// GitHub compatible APIs may only give a download url
+				if assetID == 0 {
+					return g.downloadAsset(browserDownloadURL)
+				}
 				g.waitAndPickClient()
 				asset, redirectURL, err := g.getClient().Repositories.DownloadReleaseAsset(g.ctx, g.repoOwner, g.repoName, assetID, nil)
 				if err != nil {
@@ -348,7 +364,7 @@ func (g *GithubDownloaderV3) convertGithubRelease(rel *github.RepositoryRelease)
 						if err != nil {
 							return nil, err
 						}
-						resp, err := httpClient.Do(req)
+						resp, err := g.httpClient.Do(req)
 						err1 := g.RefreshRate()
 						if err1 != nil {
 							log.Error("g.getClient().RateLimits: %s", err1)
@@ -367,6 +383,59 @@ func (g *GithubDownloaderV3) convertGithubRelease(rel *github.RepositoryRelease)
 	return r
 }
 
+// isSameOrigin returns whether the url has the scheme and host of the API
+func (g *GithubDownloaderV3) isSameOrigin(u *url.URL) bool {
+	baseURL := g.getClient().BaseURL
+	return strings.EqualFold(u.Scheme, baseURL.Scheme) && strings.EqualFold(u.Host, baseURL.Host)
+}
+
+// downloadAsset downloads a release asset, with the credentials of the current client only from
+// the origin of the API. Other hosts, including those it redirects to, do not get the credentials.
+func (g *GithubDownloaderV3) downloadAsset(downloadURL string) (io.ReadCloser, error) {
+	u, err := url.Parse(downloadURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp *http.Response
+	if g.isSameOrigin(u) {
+		g.waitAndPickClient()
+		// the transport of the client adds the credentials to every request, so it must not follow redirects
+		client := g.getClient().Client()
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+		if resp, err = g.get(client, u); err != nil {
+			return nil, err
+		}
+		if resp.StatusCode >= 300 && resp.StatusCode < 400 {
+			resp.Body.Close()
+			if u, err = resp.Location(); err != nil {
+				return nil, err
+			}
+			resp = nil
+		}
+	}
+	if resp == nil {
+		if resp, err = g.get(g.httpClient, u); err != nil {
+			return nil, err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("download %s: %s", u.Redacted(), resp.Status)
+	}
+	return resp.Body, nil
+}
+
+func (g *GithubDownloaderV3) get(client *http.Client, u *url.URL) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(g.ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return client.Do(req)
+}
+
 // GetReleases returns releases
 func (g *GithubDownloaderV3) GetReleases() ([]*base.Release, error) {
 	var perPage = g.maxPerPage
@@ -395,6 +464,15 @@ func (g *GithubDownloaderV3) GetReleases() ([]*base.Release, error) {
 
 // GetIssues returns issues according start and limit
 func (g *GithubDownloaderV3) GetIssues(page, perPage int) ([]*base.Issue, bool, error) {
+	return g.getIssues(page, perPage, time.Time{})
+}
+
+// GetNewIssues returns issues updated after the given time according start and limit
+func (g *GithubDownloaderV3) GetNewIssues(page, perPage int, updatedAfter time.Time) ([]*base.Issue, bool, error) {
+	return g.getIssues(page, perPage, updatedAfter)
+}
+
+func (g *GithubDownloaderV3) getIssues(page, perPage int, updatedAfter time.Time) ([]*base.Issue, bool, error) {
 	if perPage > g.maxPerPage {
 		perPage = g.maxPerPage
 	}
@@ -402,6 +480,7 @@ func (g *GithubDownloaderV3) GetIssues(page, perPage int) ([]*base.Issue, bool,
 		Sort:      "created",
 		Direction: "asc",
 		State:     "all",
+		Since:     updatedAfter,
 		ListOptions: github.ListOptions{
 			PerPage: perPage,
 			Page:    page,
@@ -428,7 +507,7 @@ func (g *GithubDownloaderV3) GetIssues(page, perPage int) ([]*base.Issue, bool,
 
 		// get reactions
 		var reactions []*base.Reaction
-		for i := 1; ; i++ {
+		for i := 1; !g.SkipReactions; i++ {
 			g.waitAndPickClient()
 			res, resp, err := g.getClient().Reactions.ListIssueReactions(g.ctx, g.repoOwner, g.repoName, issue.GetNumber(), &github.ListOptions{
 				Page:    i,
@@ -516,7 +595,7 @@ func (g *GithubDownloaderV3) getComments(issueContext base.IssueContext) ([]*bas
 		for _, comment := range comments {
 			// get reactions
 			var reactions []*base.Reaction
-			for i := 1; ; i++ {
+			for i := 1; !g.SkipReactions; i++ {
 				g.waitAndPickClient()
 				res, resp, err := g.getClient().Reactions.ListIssueCommentReactions(g.ctx, g.repoOwner, g.repoName, comment.GetID(), &github.ListOptions{
 					Page:    i,
@@ -588,7 +667,7 @@ func (g *GithubDownloaderV3) GetAllComments(page, perPage int) ([]*base.Comment,
 	for _, comment := range comments {
 		// get reactions
 		var reactions []*base.Reaction
-		for i := 1; ; i++ {
+		for i := 1; !g.SkipReactions; i++ {
 			g.waitAndPickClient()
 			res, resp, err := g.getClient().Reactions.ListIssueCommentReactions(g.ctx, g.repoOwner, g.repoName, comment.GetID(), &github.ListOptions{
 				Page:    i,
@@ -628,6 +707,15 @@ func (g *GithubDownloaderV3) GetAllComments(page, perPage int) ([]*base.Comment,
 
 // GetPullRequests returns pull requests according page and perPage
 func (g *GithubDownloaderV3) GetPullRequests(page, perPage int) ([]*base.PullRequest, bool, error) {
+	return g.getPullRequests(page, perPage, time.Time{})
+}
+
+// GetNewPullRequests returns pull requests updated after the given time according page and perPage
+func (g *GithubDownloaderV3) GetNewPullRequests(page, perPage int, updatedAfter time.Time) ([]*base.PullRequest, bool, error) {
+	return g.getPullRequests(page, perPage, updatedAfter)
+}
+
+func (g *GithubDownloaderV3) getPullRequests(page, perPage int, updatedAfter time.Time) ([]*base.PullRequest, bool, error) {
 	if perPage > g.maxPerPage {
 		perPage = g.maxPerPage
 	}
@@ -640,6 +728,11 @@ func (g *GithubDownloaderV3) GetPullRequests(page, perPage int) ([]*base.PullReq
 			Page:    page,
 		},
 	}
+	if !updatedAfter.IsZero() {
+		// pull requests can not be filtered by their update time, the recently updated ones are listed first instead
+		opt.Sort = "updated"
+		opt.Direction = "desc"
+	}
 	var allPRs = make([]*base.PullRequest, 0, perPage)
 	g.waitAndPickClient()
 	prs, resp, err := g.getClient().PullRequests.List(g.ctx, g.repoOwner, g.repoName, opt)
@@ -649,6 +742,10 @@ func (g *GithubDownloaderV3) GetPullRequests(page, perPage int) ([]*base.PullReq
 	log.Trace("Request get pull requests %d/%d, but in fact get %d", perPage, page, len(prs))
 	g.setRate(&resp.Rate)
 	for _, pr := range prs {
+		if !updatedAfter.IsZero() && !pr.GetUpdatedAt().After(updatedAfter) {
+			return allPRs, true, nil
+		}
+
 		var labels = make([]*base.Label, 0, len(pr.Labels))
 		for _, l := range pr.Labels {
 			labels = append(labels, convertGithubLabel(l))
@@ -656,7 +753,7 @@ func (g *GithubDownloaderV3) GetPullRequests(page, perPage int) ([]*base.PullReq
 
 		// get reactions
 		var reactions []*base.Reaction
-		for i := 1; ; i++ {
+		for i := 1; !g.SkipReactions; i++ {
 			g.waitAndPickClient()
 			res, resp, err := g.getClient().Reactions.ListIssueReactions(g.ctx, g.repoOwner, g.repoName, pr.GetNumber(), &github.ListOptions{
 				Page:    i,
@@ -737,7 +834,7 @@ func (g *GithubDownloaderV3) convertGithubReviewComments(cs []*github.PullReques
 	for _, c := range cs {
 		// get reactions
 		var reactions []*base.Reaction
-		for i := 1; ; i++ {
+		for i := 1; !g.SkipReactions; i++ {
 			g.waitAndPickClient()
 			res, resp, err := g.getClient().Reactions.ListPullRequestCommentReactions(g.ctx, g.repoOwner, g.repoName, c.GetID(), &github.ListOptions{
 				Page:    i,
@@ -378,6 +378,15 @@ func (c gitlabIssueContext) ForeignID() int64 {
 // GetIssues returns issues according start and limit
 //   Note: issue label description and colors are not supported by the go-gitlab library at this time
 func (g *GitlabDownloader) GetIssues(page, perPage int) ([]*base.Issue, bool, error) {
+	return g.getIssues(page, perPage, time.Time{})
+}
+
+// GetNewIssues returns issues updated after the given time according start and limit
+func (g *GitlabDownloader) GetNewIssues(page, perPage int, updatedAfter time.Time) ([]*base.Issue, bool, error) {
+	return g.getIssues(page, perPage, updatedAfter)
+}
+
+func (g *GitlabDownloader) getIssues(page, perPage int, updatedAfter time.Time) ([]*base.Issue, bool, error) {
 	state := "all"
 	sort := "asc"
 
@@ -393,6 +402,9 @@ func (g *GitlabDownloader) GetIssues(page, perPage int) ([]*base.Issue, bool, er
 			Page:    page,
 		},
 	}
+	if !updatedAfter.IsZero() {
+		opt.UpdatedAfter = &updatedAfter
+	}
 
 	var allIssues = make([]*base.Issue, 0, perPage)
 
@@ -528,6 +540,15 @@ func (g *GitlabDownloader) GetComments(opts base.GetCommentOptions) ([]*base.Com
 
 // GetPullRequests returns pull requests according page and perPage
 func (g *GitlabDownloader) GetPullRequests(page, perPage int) ([]*base.PullRequest, bool, error) {
+	return g.getPullRequests(page, perPage, time.Time{})
+}
+
+// GetNewPullRequests returns pull requests updated after the given time according page and perPage
+func (g *GitlabDownloader) GetNewPullRequests(page, perPage int, updatedAfter time.Time) ([]*base.PullRequest, bool, error) {
+	return g.getPullRequests(page, perPage, updatedAfter)
+}
+
+func (g *GitlabDownloader) getPullRequests(page, perPage int, updatedAfter time.Time) ([]*base.PullRequest, bool, error) {
 	if perPage > g.maxPerPage {
 		perPage = g.maxPerPage
 	}
@@ -538,6 +559,9 @@ func (g *GitlabDownloader) GetPullRequests(page, perPage int) ([]*base.PullReque
 			Page:    page,
 		},
 	}
+	if !updatedAfter.IsZero() {
+		opt.UpdatedAfter = &updatedAfter
+	}
 
 	var allPRs = make([]*base.PullRequest, 0, perPage)
 
@@ -0,0 +1,631 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/csv"
+	"code.gitea.io/gitea/modules/migrations/base"
+	"code.gitea.io/gitea/modules/structs"
+)
+
+var (
+	_ base.Downloader = &IssueImportDownloader{}
+)
+
+// Issue export formats which could be imported
+const (
+	IssueImportJiraXML  = "jira_xml"
+	IssueImportJiraJSON = "jira_json"
+	IssueImportCSV      = "csv"
+)
+
+// IssueImportFormats are all the supported issue export formats
+var IssueImportFormats = []string{IssueImportJiraXML, IssueImportJiraJSON, IssueImportCSV}
+
+// IssueImportOptions maps the statuses and fields of an issue export to the issues of a repository
+type IssueImportOptions struct {
+	// ClosedStatuses are the statuses of closed issues, issues with any other status stay open
+	ClosedStatuses []string
+	// LabelFields are the fields whose values are added to the issues as "<field>: <value>" labels
+	LabelFields []string
+}
+
+// importedIssue is an issue read from an issue export
+type importedIssue struct {
+	Number    int64
+	Project   string // the project of the issue key, if the export has issue keys
+	ForeignID int64
+	Title     string
+	Content   string
+	Status    string
+	Done      bool // whether the exporting service considers the status as done
+	Reporter  string
+	Email     string
+	Created   time.Time
+	Updated   time.Time
+	Resolved  *time.Time
+	Labels    []string
+	Milestone string
+	Fields    map[string][]string
+	Comments  []*base.Comment
+}
+
+func (issue *importedIssue) addField(name string, values ...string) {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		if issue.Fields == nil {
+			issue.Fields = make(map[string][]string)
+		}
+		issue.Fields[name] = append(issue.Fields[name], value)
+	}
+}
+
+// IssueImport holds the issues and milestones read from an issue export
+type IssueImport struct {
+	// Source names the export, so that issues imported before from the same source are updated
+	Source     string
+	Issues     []*importedIssue
+	Milestones []*base.Milestone
+}
+
+// ParseIssueImport reads an issue export of the given format from the file with the given name
+func ParseIssueImport(format, name string, r io.Reader) (*IssueImport, error) {
+	var (
+		imp *IssueImport
+		err error
+	)
+	switch format {
+	case IssueImportJiraXML:
+		imp, err = parseJiraXML(r)
+	case IssueImportJiraJSON:
+		imp, err = parseJiraJSON(r)
+	case IssueImportCSV:
+		imp, err = parseIssueCSV(r)
+	default:
+		return nil, fmt.Errorf("unknown issue export format: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(imp.Issues) == 0 {
+		return nil, errors.New("the issue export contains no issues")
+	}
+	imp.addMissingMilestones()
+	imp.Source = imp.source(name)
+	return imp, nil
+}
+
+// maxIssueImportSourceLen is the maximum length of a source, which is stored as foreign reference type
+const maxIssueImportSourceLen = 255
+
+// source returns "jira:<projects>" for exports with issue keys, otherwise "csv:<file name>"
+func (imp *IssueImport) source(name string) string {
+	projects := make(map[string]bool)
+	for _, issue := range imp.Issues {
+		if issue.Project != "" {
+			projects[issue.Project] = true
+		}
+	}
+
+	source := "csv:" + strings.ToLower(path.Base(strings.ReplaceAll(name, "\\", "/")))
+	if len(projects) > 0 {
+		source = "jira:" + strings.Join(sortedKeys(projects), ",")
+	}
+	if len(source) > maxIssueImportSourceLen {
+		sum := sha256.Sum256([]byte(source))
+		source = source[:strings.IndexByte(source, ':')+1] + hex.EncodeToString(sum[:])
+	}
+	return source
+}
+
+// addMissingMilestones adds the milestones only known by name from the issues
+func (imp *IssueImport) addMissingMilestones() {
+	known := make(map[string]bool, len(imp.Milestones))
+	for _, milestone := range imp.Milestones {
+		known[milestone.Title] = true
+	}
+	for _, issue := range imp.Issues {
+		if issue.Milestone != "" && !known[issue.Milestone] {
+			known[issue.Milestone] = true
+			imp.Milestones = append(imp.Milestones, &base.Milestone{
+				Title: issue.Milestone,
+				State: "open",
+			})
+		}
+	}
+}
+
+// Statuses returns the sorted statuses of the issues
+func (imp *IssueImport) Statuses() []string {
+	statuses := make(map[string]bool)
+	for _, issue := range imp.Issues {
+		if issue.Status != "" {
+			statuses[issue.Status] = true
+		}
+	}
+	return sortedKeys(statuses)
+}
+
+// DoneStatuses returns the sorted statuses which the exporting service considers as done
+func (imp *IssueImport) DoneStatuses() []string {
+	statuses := make(map[string]bool)
+	for _, issue := range imp.Issues {
+		if issue.Status != "" && issue.Done {
+			statuses[issue.Status] = true
+		}
+	}
+	return sortedKeys(statuses)
+}
+
+// Fields returns the sorted names of the fields which could be mapped to labels
+func (imp *IssueImport) Fields() []string {
+	fields := make(map[string]bool)
+	for _, issue := range imp.Issues {
+		for name := range issue.Fields {
+			fields[name] = true
+		}
+	}
+	return sortedKeys(fields)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ImportIssues imports the milestones, labels, issues and comments of an issue export into an existing repository.
+// Issues imported before from the same export are updated in place.
+func ImportIssues(ctx context.Context, doer *models.User, repo *models.Repository, imp *IssueImport, opts IssueImportOptions) error {
+	// users of the export are not linked to any external login
+	uploader, err := newGiteaLocalRepoUploader(ctx, doer, repo, structs.NotMigrated)
+	if err != nil {
+		return err
+	}
+	defer uploader.Close()
+	uploader.issueForeignType = imp.Source
+
+	return syncRepository(NewIssueImportDownloader(imp, opts), uploader, base.MigrateOptions{
+		Milestones: true,
+		Labels:     true,
+		Issues:     true,
+		Comments:   true,
+	}, time.Time{})
+}
+
+// issueImportContext maps an imported issue to its id on the exporting service
+type issueImportContext struct {
+	foreignID int64
+	localID   int64
+}
+
+func (c issueImportContext) LocalID() int64 {
+	return c.localID
+}
+
+func (c issueImportContext) ForeignID() int64 {
+	return c.foreignID
+}
+
+// IssueImportDownloader implements a Downloader interface to get the issues of an issue export
+type IssueImportDownloader struct {
+	base.NullDownloader
+	imp            *IssueImport
+	closedStatuses map[string]bool
+	labelFields    map[string]bool
+}
+
+// NewIssueImportDownloader creates a downloader of the issues of an issue export mapped according to opts
+func NewIssueImportDownloader(imp *IssueImport, opts IssueImportOptions) *IssueImportDownloader {
+	d := &IssueImportDownloader{
+		imp:            imp,
+		closedStatuses: make(map[string]bool, len(opts.ClosedStatuses)),
+		labelFields:    make(map[string]bool, len(opts.LabelFields)),
+	}
+	for _, status := range opts.ClosedStatuses {
+		d.closedStatuses[status] = true
+	}
+	for _, field := range opts.LabelFields {
+		d.labelFields[field] = true
+	}
+	return d
+}
+
+// GetMilestones returns milestones
+func (d *IssueImportDownloader) GetMilestones() ([]*base.Milestone, error) {
+	return d.imp.Milestones, nil
+}
+
+// issueLabels returns the names of the labels of an issue, including the mapped fields
+func (d *IssueImportDownloader) issueLabels(issue *importedIssue) []string {
+	labels := make([]string, 0, len(issue.Labels))
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			labels = append(labels, name)
+		}
+	}
+	for _, label := range issue.Labels {
+		add(label)
+	}
+	fieldLabels := make([]string, 0, len(issue.Fields))
+	for name, values := range issue.Fields {
+		if !d.labelFields[name] {
+			continue
+		}
+		for _, value := range values {
+			fieldLabels = append(fieldLabels, name+": "+value)
+		}
+	}
+	sort.Strings(fieldLabels)
+	for _, label := range fieldLabels {
+		add(label)
+	}
+	return labels
+}
+
+// GetLabels returns labels
+func (d *IssueImportDownloader) GetLabels() ([]*base.Label, error) {
+	names := make(map[string]bool)
+	for _, issue := range d.imp.Issues {
+		for _, name := range d.issueLabels(issue) {
+			names[name] = true
+		}
+	}
+
+	labels := make([]*base.Label, 0, len(names))
+	for _, name := range sortedKeys(names) {
+		labels = append(labels, &base.Label{
+			Name:  name,
+			Color: importLabelColor(name),
+		})
+	}
+	return labels, nil
+}
+
+// GetIssues returns issues according start and limit
+func (d *IssueImportDownloader) GetIssues(page, perPage int) ([]*base.Issue, bool, error) {
+	start := (page - 1) * perPage
+	if start >= len(d.imp.Issues) {
+		return []*base.Issue{}, true, nil
+	}
+	end := start + perPage
+	if end > len(d.imp.Issues) {
+		end = len(d.imp.Issues)
+	}
+
+	issues := make([]*base.Issue, 0, end-start)
+	for _, issue := range d.imp.Issues[start:end] {
+		state := "open"
+		var closed *time.Time
+		if d.closedStatuses[issue.Status] {
+			state = "closed"
+			closed = issue.Resolved
+			if closed == nil && !issue.Updated.IsZero() {
+				closed = &issue.Updated
+			}
+		}
+
+		names := d.issueLabels(issue)
+		labels := make([]*base.Label, 0, len(names))
+		for _, name := range names {
+			labels = append(labels, &base.Label{Name: name})
+		}
+
+		issues = append(issues, &base.Issue{
+			Number:      issue.Number,
+			PosterID:    importUserID(issue.Reporter),
+			PosterName:  issue.Reporter,
+			PosterEmail: issue.Email,
+			Title:       issue.Title,
+			Content:     issue.Content,
+			Milestone:   issue.Milestone,
+			State:       state,
+			Created:     issue.Created,
+			Updated:     issue.Updated,
+			Closed:      closed,
+			Labels:      labels,
+			Context: issueImportContext{
+				foreignID: issue.ForeignID,
+				localID:   issue.Number,
+			},
+		})
+	}
+	return issues, end == len(d.imp.Issues), nil
+}
+
+// GetComments returns comments according the options
+func (d *IssueImportDownloader) GetComments(opts base.GetCommentOptions) ([]*base.Comment, bool, error) {
+	for _, issue := range d.imp.Issues {
+		if issue.ForeignID == opts.Context.ForeignID() {
+			return issue.Comments, true, nil
+		}
+	}
+	return []*base.Comment{}, true, nil
+}
+
+// importUserID returns a stable id for a user of an issue export, which only names its users
+func importUserID(name string) int64 {
+	if name == "" {
+		return 0
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	return int64(h.Sum32())
+}
+
+var importLabelColors = []string{"e11d21", "eb6420", "fbca04", "009800", "006b75", "207de5", "0052cc", "5319e7"}
+
+// importLabelColor returns a stable color for a label of an issue export
+func importLabelColor(name string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	return importLabelColors[h.Sum32()%uint32(len(importLabelColors))]
+}
+
+var (
+	issueKeyNumberRegexp  = regexp.MustCompile(`(\d+)$`)
+	issueKeyProjectRegexp = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9_]*)-\d+$`)
+)
+
+// issueKeyProject returns the project of an issue key like PROJ-123
+func issueKeyProject(key string) string {
+	m := issueKeyProjectRegexp.FindStringSubmatch(strings.TrimSpace(key))
+	if m == nil {
+		return ""
+	}
+	return strings.ToUpper(m[1])
+}
+
+// issueKeyNumber returns the number of an issue key like PROJ-123
+func issueKeyNumber(key string) int64 {
+	m := issueKeyNumberRegexp.FindStringSubmatch(strings.TrimSpace(key))
+	if m == nil {
+		return 0
+	}
+	number, _ := strconv.ParseInt(m[1], 10, 64)
+	return number
+}
+
+var importTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05.000-0700",
+	"Mon, 2 Jan 2006 15:04:05 -0700", // Jira XML
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"02/Jan/06 3:04 PM", // Jira CSV
+	"02/Jan/06",
+	"01/02/2006 15:04",
+	"01/02/2006",
+}
+
+// parseImportTime parses a date of an issue export in any of the known layouts
+func parseImportTime(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range importTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// isDoneStatus returns whether a status of a generic export usually means the issue is done
+func isDoneStatus(status string) bool {
+	switch strings.ToLower(status) {
+	case "closed", "done", "resolved", "fixed", "complete", "completed", "won't fix", "wontfix", "duplicate", "invalid":
+		return true
+	}
+	return false
+}
+
+// issueCSVColumns maps the lowercased headers of a csv export to the issue attributes they hold.
+// The headers of Jira csv exports are included.
+var issueCSVColumns = map[string]string{
+	"id":              "id",
+	"issue id":        "id",
+	"number":          "id",
+	"#":               "id",
+	"key":             "key",
+	"issue key":       "key",
+	"title":           "title",
+	"summary":         "title",
+	"subject":         "title",
+	"description":     "content",
+	"body":            "content",
+	"content":         "content",
+	"status":          "status",
+	"state":           "status",
+	"reporter":        "reporter",
+	"author":          "reporter",
+	"creator":         "reporter",
+	"created by":      "reporter",
+	"created":         "created",
+	"created at":      "created",
+	"created_at":      "created",
+	"updated":         "updated",
+	"updated at":      "updated",
+	"updated_at":      "updated",
+	"resolved":        "resolved",
+	"closed":          "resolved",
+	"closed at":       "resolved",
+	"closed_at":       "resolved",
+	"labels":          "labels",
+	"label":           "labels",
+	"tags":            "labels",
+	"milestone":       "milestone",
+	"fix version/s":   "milestone",
+	"fix versions":    "milestone",
+	"fix version":     "milestone",
+	"comment":         "comment",
+	"comments":        "comment",
+	"status category": "category",
+}
+
+var customFieldHeaderRegexp = regexp.MustCompile(`^Custom field \((.+)\)$`)
+
+// parseIssueCSV reads a csv file with a header row, one issue per row. Columns which are not
+// known issue attributes are read as fields. A header may be repeated for several values.
+func parseIssueCSV(r io.Reader) (*IssueImport, error) {
+	rd, err := csv.CreateReaderAndDetermineDelimiter(nil, r)
+	if err != nil {
+		return nil, err
+	}
+	rd.FieldsPerRecord = -1
+
+	headers, err := rd.Read()
+	if err != nil {
+		if err == io.EOF {
+			return &IssueImport{}, nil
+		}
+		return nil, err
+	}
+	columns := make([]string, len(headers))
+	fields := make([]string, len(headers))
+	for i, header := range headers {
+		header = strings.TrimSpace(strings.TrimPrefix(header, "\ufeff"))
+		if column, ok := issueCSVColumns[strings.ToLower(header)]; ok {
+			columns[i] = column
+		} else if m := customFieldHeaderRegexp.FindStringSubmatch(header); m != nil {
+			fields[i] = m[1]
+		} else {
+			fields[i] = header
+		}
+	}
+
+	imp := &IssueImport{}
+	for row := 1; ; row++ {
+		record, err := rd.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		issue := &importedIssue{}
+		var category string
+		for i, value := range record {
+			if i >= len(columns) {
+				break
+			}
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+			switch columns[i] {
+			case "id":
+				issue.ForeignID, _ = strconv.ParseInt(value, 10, 64)
+			case "key":
+				issue.Number = issueKeyNumber(value)
+				issue.Project = issueKeyProject(value)
+			case "title":
+				issue.Title = value
+			case "content":
+				issue.Content = value
+			case "status":
+				issue.Status = value
+			case "category":
+				category = value
+			case "reporter":
+				issue.Reporter = value
+			case "created":
+				issue.Created, _ = parseImportTime(value)
+			case "updated":
+				issue.Updated, _ = parseImportTime(value)
+			case "resolved":
+				if t, ok := parseImportTime(value); ok {
+					issue.Resolved = &t
+				}
+			case "labels":
+				for _, label := range strings.Split(value, ",") {
+					if label = strings.TrimSpace(label); label != "" {
+						issue.Labels = append(issue.Labels, label)
+					}
+				}
+			case "milestone":
+				if issue.Milestone == "" {
+					issue.Milestone = value
+				}
+			case "comment":
+				issue.Comments = append(issue.Comments, parseCSVComment(value))
+			default:
+				if fields[i] != "" {
+					issue.addField(fields[i], value)
+				}
+			}
+		}
+
+		if issue.Title == "" {
+			return nil, fmt.Errorf("row %d has no title", row)
+		}
+		if issue.Number == 0 {
+			issue.Number = issue.ForeignID
+		}
+		if issue.Number == 0 {
+			issue.Number = int64(row)
+		}
+		if issue.ForeignID == 0 {
+			issue.ForeignID = issue.Number
+		}
+		for i, comment := range issue.Comments {
+			// comments without a date are kept in order and told apart by later imports
+			if comment.Created.IsZero() && !issue.Created.IsZero() {
+				comment.Created = issue.Created.Add(time.Duration(i+1) * time.Second)
+			}
+		}
+		if category != "" {
+			issue.Done = strings.EqualFold(category, "done")
+		} else {
+			issue.Done = isDoneStatus(issue.Status)
+		}
+		imp.Issues = append(imp.Issues, issue)
+	}
+	return imp, nil
+}
+
+// parseCSVComment reads a comment of a csv export, which Jira exports as "<created>;<author>;<text>"
+func parseCSVComment(value string) *base.Comment {
+	if parts := strings.SplitN(value, ";", 3); len(parts) == 3 {
+		if created, ok := parseImportTime(parts[0]); ok {
+			author := strings.TrimSpace(parts[1])
+			return &base.Comment{
+				PosterID:   importUserID(author),
+				PosterName: author,
+				Created:    created,
+				Updated:    created,
+				Content:    strings.TrimSpace(parts[2]),
+			}
+		}
+	}
+	return &base.Comment{
+		Content: value,
+	}
+}
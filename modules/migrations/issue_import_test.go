@@ -0,0 +1,216 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/migrations/base"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func parseIssueImportTestdata(t *testing.T, format, name string) *IssueImport {
+	f, err := os.Open(filepath.Join("testdata", "issue_import", name))
+	assert.NoError(t, err)
+	defer f.Close()
+
+	imp, err := ParseIssueImport(format, name, f)
+	assert.NoError(t, err)
+	return imp
+}
+
+func assertJiraIssueImport(t *testing.T, imp *IssueImport) {
+	assert.EqualValues(t, []string{"Done", "In Progress"}, imp.Statuses())
+	assert.EqualValues(t, []string{"Done"}, imp.DoneStatuses())
+
+	downloader := NewIssueImportDownloader(imp, IssueImportOptions{
+		ClosedStatuses: []string{"Done"},
+		LabelFields:    []string{"Type"},
+	})
+
+	labels, err := downloader.GetLabels()
+	assert.NoError(t, err)
+	var labelNames []string
+	for _, label := range labels {
+		labelNames = append(labelNames, label.Name)
+		assert.Len(t, label.Color, 6)
+	}
+	assert.EqualValues(t, []string{"Type: Bug", "Type: Story", "backend", "security"}, labelNames)
+
+	issues, isEnd, err := downloader.GetIssues(1, 10)
+	assert.NoError(t, err)
+	assert.True(t, isEnd)
+	if !assert.Len(t, issues, 2) {
+		return
+	}
+
+	closed := time.Date(2021, 10, 6, 11, 0, 0, 0, time.UTC)
+	assert.EqualValues(t, 1, issues[0].Number)
+	assert.EqualValues(t, 10001, issues[0].Context.ForeignID())
+	assert.EqualValues(t, "Login fails with an expired session", issues[0].Title)
+	assert.EqualValues(t, "asmith", issues[0].PosterName)
+	assert.EqualValues(t, importUserID("asmith"), issues[0].PosterID)
+	assert.EqualValues(t, "closed", issues[0].State)
+	assert.EqualValues(t, "1.0", issues[0].Milestone)
+	assert.True(t, closed.Equal(*issues[0].Closed))
+	assert.True(t, time.Date(2021, 10, 4, 10, 0, 0, 0, time.UTC).Equal(issues[0].Created))
+	assertLabelNames(t, issues[0].Labels, "backend", "security", "Type: Bug")
+
+	assert.EqualValues(t, 2, issues[1].Number)
+	assert.EqualValues(t, "open", issues[1].State)
+	assert.Nil(t, issues[1].Closed)
+	assertLabelNames(t, issues[1].Labels, "Type: Story")
+
+	comments, _, err := downloader.GetComments(base.GetCommentOptions{Context: issues[0].Context})
+	assert.NoError(t, err)
+	if assert.Len(t, comments, 1) {
+		assert.EqualValues(t, "jdoe", comments[0].PosterName)
+		assert.True(t, time.Date(2021, 10, 5, 10, 0, 0, 0, time.UTC).Equal(comments[0].Created))
+	}
+	comments, _, err = downloader.GetComments(base.GetCommentOptions{Context: issues[1].Context})
+	assert.NoError(t, err)
+	assert.Empty(t, comments)
+}
+
+func assertLabelNames(t *testing.T, labels []*base.Label, names ...string) {
+	var actual []string
+	for _, label := range labels {
+		actual = append(actual, label.Name)
+	}
+	assert.EqualValues(t, names, actual)
+}
+
+func TestParseJiraXML(t *testing.T) {
+	imp := parseIssueImportTestdata(t, IssueImportJiraXML, "jira.xml")
+	assertJiraIssueImport(t, imp)
+	assert.EqualValues(t, "jira:PROJ", imp.Source)
+
+	assert.EqualValues(t, []string{"Component", "Priority", "Resolution", "Team", "Type"}, imp.Fields())
+	assert.EqualValues(t, "<p>The login form reports an error.</p>", imp.Issues[0].Content)
+	assert.EqualValues(t, "<p>Reproduced.</p>", imp.Issues[0].Comments[0].Content)
+	if assert.Len(t, imp.Milestones, 1) {
+		assert.EqualValues(t, "1.0", imp.Milestones[0].Title)
+		assert.EqualValues(t, "open", imp.Milestones[0].State)
+	}
+}
+
+func TestParseJiraJSON(t *testing.T) {
+	imp := parseIssueImportTestdata(t, IssueImportJiraJSON, "jira.json")
+	assertJiraIssueImport(t, imp)
+	assert.EqualValues(t, "jira:PROJ", imp.Source)
+
+	assert.EqualValues(t, []string{"Component", "Priority", "Resolution", "Team", "Type"}, imp.Fields())
+	assert.EqualValues(t, []string{"Frontend", "Design"}, imp.Issues[1].Fields["Team"])
+	assert.EqualValues(t, "alice@example.com", imp.Issues[0].Email)
+	assert.EqualValues(t, "John Doe", imp.Issues[1].Reporter)
+	assert.EqualValues(t, "Many users asked for it.\n\nFollow the OS setting.", imp.Issues[1].Content)
+	if assert.Len(t, imp.Milestones, 1) {
+		assert.EqualValues(t, "1.0", imp.Milestones[0].Title)
+		assert.EqualValues(t, "First release", imp.Milestones[0].Description)
+		assert.EqualValues(t, "closed", imp.Milestones[0].State)
+		assert.True(t, time.Date(2021, 10, 31, 0, 0, 0, 0, time.UTC).Equal(*imp.Milestones[0].Deadline))
+	}
+}
+
+func TestParseIssueCSV(t *testing.T) {
+	imp := parseIssueImportTestdata(t, IssueImportCSV, "jira.csv")
+	assert.EqualValues(t, "jira:PROJ", imp.Source)
+	assert.EqualValues(t, []string{"Team"}, imp.Fields())
+	if assert.Len(t, imp.Issues, 2) {
+		issue := imp.Issues[0]
+		assert.EqualValues(t, 1, issue.Number)
+		assert.EqualValues(t, 10001, issue.ForeignID)
+		assert.EqualValues(t, "asmith", issue.Reporter)
+		assert.EqualValues(t, []string{"backend", "security"}, issue.Labels)
+		assert.EqualValues(t, "1.0", issue.Milestone)
+		assert.True(t, issue.Done)
+		assert.True(t, time.Date(2021, 10, 6, 11, 0, 0, 0, time.UTC).Equal(*issue.Resolved))
+		if assert.Len(t, issue.Comments, 1) {
+			assert.EqualValues(t, "jdoe", issue.Comments[0].PosterName)
+			assert.EqualValues(t, "Reproduced.", issue.Comments[0].Content)
+		}
+		assert.EqualValues(t, "Add a dark theme, finally", imp.Issues[1].Title)
+		assert.False(t, imp.Issues[1].Done)
+	}
+
+	imp = parseIssueImportTestdata(t, IssueImportCSV, "issues.csv")
+	assert.EqualValues(t, "csv:issues.csv", imp.Source)
+	assert.EqualValues(t, []string{"closed", "open"}, imp.Statuses())
+	assert.EqualValues(t, []string{"closed"}, imp.DoneStatuses())
+	assert.EqualValues(t, []string{"priority"}, imp.Fields())
+	if assert.Len(t, imp.Issues, 2) {
+		assert.EqualValues(t, 1, imp.Issues[0].Number)
+		assert.EqualValues(t, "The app crashes.\nEvery time.", imp.Issues[0].Content)
+		assert.EqualValues(t, []string{"bug", "crash"}, imp.Issues[0].Labels)
+		assert.EqualValues(t, 2, imp.Issues[1].Number)
+	}
+
+	_, err := ParseIssueImport(IssueImportCSV, "empty.csv", strings.NewReader("title,state\n"))
+	assert.Error(t, err)
+}
+
+func TestImportIssues(t *testing.T) {
+	assert.NoError(t, db.PrepareTestDatabase())
+
+	user := db.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	repo := db.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	opts := IssueImportOptions{
+		ClosedStatuses: []string{"Done"},
+		LabelFields:    []string{"Team"},
+	}
+
+	imp := parseIssueImportTestdata(t, IssueImportJiraXML, "jira.xml")
+	assert.NoError(t, ImportIssues(context.Background(), user, repo, imp, opts))
+
+	// the numbers of the imported issues are already taken by local issues
+	ref, err := models.GetForeignReference(repo.ID, 10001, "jira:PROJ")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 6, ref.LocalIndex)
+	issue := db.AssertExistsAndLoadBean(t, &models.Issue{RepoID: repo.ID, Index: 6}).(*models.Issue)
+	assert.EqualValues(t, "Login fails with an expired session", issue.Title)
+	assert.EqualValues(t, "asmith", issue.OriginalAuthor)
+	assert.True(t, issue.IsClosed)
+	assert.NoError(t, issue.LoadLabels())
+	var labels []string
+	for _, label := range issue.Labels {
+		labels = append(labels, label.Name)
+	}
+	assert.ElementsMatch(t, []string{"backend", "security", "Team: Platform"}, labels)
+
+	milestone := db.AssertExistsAndLoadBean(t, &models.Milestone{RepoID: repo.ID, Name: "1.0"}).(*models.Milestone)
+	assert.EqualValues(t, milestone.ID, issue.MilestoneID)
+
+	db.AssertExistsAndLoadBean(t, &models.Comment{IssueID: issue.ID, OriginalAuthor: "jdoe", Content: "<p>Reproduced.</p>"})
+
+	second := db.AssertExistsAndLoadBean(t, &models.Issue{RepoID: repo.ID, Index: 7}).(*models.Issue)
+	assert.EqualValues(t, "Add a dark theme", second.Title)
+	assert.False(t, second.IsClosed)
+
+	// importing the export again updates the imported issues instead of duplicating them
+	imp.Issues[1].Title = "Add a dark theme again"
+	assert.NoError(t, ImportIssues(context.Background(), user, repo, imp, opts))
+	second = db.AssertExistsAndLoadBean(t, &models.Issue{RepoID: repo.ID, Index: 7}).(*models.Issue)
+	assert.EqualValues(t, "Add a dark theme again", second.Title)
+	db.AssertNotExistsBean(t, &models.Issue{RepoID: repo.ID, Index: 8})
+	assert.EqualValues(t, 1, db.GetCount(t, &models.Comment{IssueID: issue.ID, OriginalAuthor: "jdoe"}))
+
+	// issues of another source with the same foreign IDs are imported as new issues
+	other := parseIssueImportTestdata(t, IssueImportJiraXML, "jira.xml")
+	other.Source = "csv:other.csv"
+	assert.NoError(t, ImportIssues(context.Background(), user, repo, other, opts))
+	db.AssertExistsAndLoadBean(t, &models.Issue{RepoID: repo.ID, Index: 8})
+	second = db.AssertExistsAndLoadBean(t, &models.Issue{RepoID: repo.ID, Index: 7}).(*models.Issue)
+	assert.EqualValues(t, "Add a dark theme again", second.Title)
+	_, err = models.GetForeignReference(repo.ID, 10001, models.ForeignTypeIssues)
+	assert.True(t, models.IsErrForeignReferenceNotExist(err))
+}
@@ -0,0 +1,373 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/migrations/base"
+)
+
+type jiraXMLUser struct {
+	Username string `xml:"username,attr"`
+	Name     string `xml:",chardata"`
+}
+
+type jiraXMLItem struct {
+	Key struct {
+		ID    int64  `xml:"id,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"key"`
+	Summary        string `xml:"summary"`
+	Description    string `xml:"description"`
+	Type           string `xml:"type"`
+	Priority       string `xml:"priority"`
+	Status         string `xml:"status"`
+	StatusCategory struct {
+		Key string `xml:"key,attr"`
+	} `xml:"statusCategory"`
+	Resolution struct {
+		ID    int64  `xml:"id,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"resolution"`
+	Reporter    jiraXMLUser `xml:"reporter"`
+	Labels      []string    `xml:"labels>label"`
+	Created     string      `xml:"created"`
+	Updated     string      `xml:"updated"`
+	Resolved    string      `xml:"resolved"`
+	FixVersions []string    `xml:"fixVersion"`
+	Components  []string    `xml:"component"`
+	Comments    []struct {
+		Author  string `xml:"author,attr"`
+		Created string `xml:"created,attr"`
+		Body    string `xml:",chardata"`
+	} `xml:"comments>comment"`
+	CustomFields []struct {
+		Name   string   `xml:"customfieldname"`
+		Values []string `xml:"customfieldvalues>customfieldvalue"`
+	} `xml:"customfields>customfield"`
+}
+
+// parseJiraXML reads the XML (RSS) export of a Jira issue search
+func parseJiraXML(r io.Reader) (*IssueImport, error) {
+	var rss struct {
+		Items []*jiraXMLItem `xml:"channel>item"`
+	}
+	if err := xml.NewDecoder(r).Decode(&rss); err != nil {
+		return nil, err
+	}
+
+	imp := &IssueImport{}
+	for _, item := range rss.Items {
+		issue := &importedIssue{
+			Number:    issueKeyNumber(item.Key.Value),
+			Project:   issueKeyProject(item.Key.Value),
+			ForeignID: item.Key.ID,
+			Title:     item.Summary,
+			Content:   strings.TrimSpace(item.Description),
+			Status:    item.Status,
+			Done:      item.StatusCategory.Key == "done",
+			Reporter:  item.Reporter.Username,
+			Labels:    item.Labels,
+		}
+		if issue.ForeignID == 0 {
+			issue.ForeignID = issue.Number
+		}
+		if issue.Reporter == "" {
+			issue.Reporter = item.Reporter.Name
+		}
+		issue.Created, _ = parseImportTime(item.Created)
+		issue.Updated, _ = parseImportTime(item.Updated)
+		if resolved, ok := parseImportTime(item.Resolved); ok {
+			issue.Resolved = &resolved
+		}
+		if len(item.FixVersions) > 0 {
+			issue.Milestone = item.FixVersions[0]
+		}
+
+		issue.addField("Type", item.Type)
+		issue.addField("Priority", item.Priority)
+		issue.addField("Component", item.Components...)
+		if item.Resolution.ID > 0 {
+			issue.addField("Resolution", item.Resolution.Value)
+		}
+		for _, field := range item.CustomFields {
+			issue.addField(field.Name, field.Values...)
+		}
+
+		for _, comment := range item.Comments {
+			created, _ := parseImportTime(comment.Created)
+			issue.Comments = append(issue.Comments, &base.Comment{
+				PosterID:   importUserID(comment.Author),
+				PosterName: comment.Author,
+				Created:    created,
+				Updated:    created,
+				Content:    strings.TrimSpace(comment.Body),
+			})
+		}
+		imp.Issues = append(imp.Issues, issue)
+	}
+	return imp, nil
+}
+
+type jiraJSONUser struct {
+	Name         string `json:"name"`
+	AccountID    string `json:"accountId"`
+	DisplayName  string `json:"displayName"`
+	EmailAddress string `json:"emailAddress"`
+}
+
+// login returns the login name of a Jira Server user, or the display name on Jira Cloud
+func (u *jiraJSONUser) login() string {
+	if u == nil {
+		return ""
+	}
+	if u.Name != "" {
+		return u.Name
+	}
+	return u.DisplayName
+}
+
+type jiraJSONNamed struct {
+	Name string `json:"name"`
+}
+
+type jiraJSONVersion struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Released    bool   `json:"released"`
+	ReleaseDate string `json:"releaseDate"`
+}
+
+type jiraJSONIssue struct {
+	ID     string         `json:"id"`
+	Key    string         `json:"key"`
+	Fields jiraJSONFields `json:"fields"`
+}
+
+type jiraJSONFields struct {
+	Summary     string      `json:"summary"`
+	Description interface{} `json:"description"`
+	Status      *struct {
+		Name           string `json:"name"`
+		StatusCategory struct {
+			Key string `json:"key"`
+		} `json:"statusCategory"`
+	} `json:"status"`
+	IssueType      *jiraJSONNamed     `json:"issuetype"`
+	Priority       *jiraJSONNamed     `json:"priority"`
+	Resolution     *jiraJSONNamed     `json:"resolution"`
+	Components     []jiraJSONNamed    `json:"components"`
+	Labels         []string           `json:"labels"`
+	FixVersions    []*jiraJSONVersion `json:"fixVersions"`
+	Reporter       *jiraJSONUser      `json:"reporter"`
+	Created        string             `json:"created"`
+	Updated        string             `json:"updated"`
+	ResolutionDate string             `json:"resolutiondate"`
+	Comment        *struct {
+		Comments []struct {
+			Author  *jiraJSONUser `json:"author"`
+			Body    interface{}   `json:"body"`
+			Created string        `json:"created"`
+			Updated string        `json:"updated"`
+		} `json:"comments"`
+	} `json:"comment"`
+}
+
+// parseJiraJSON reads the JSON result of the Jira issue search API, preferably requested with expand=names
+// so that custom fields are known by their names. A plain array of issues is accepted too.
+func parseJiraJSON(r io.Reader) (*IssueImport, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Issues []*jiraJSONIssue  `json:"issues"`
+		Names  map[string]string `json:"names"`
+	}
+	// custom fields are decoded apart as their ids differ between Jira instances
+	var custom struct {
+		Issues []struct {
+			Fields map[string]interface{} `json:"fields"`
+		} `json:"issues"`
+	}
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "[") {
+		if err = json.Unmarshal(data, &result.Issues); err == nil {
+			err = json.Unmarshal(data, &custom.Issues)
+		}
+	} else {
+		if err = json.Unmarshal(data, &result); err == nil {
+			err = json.Unmarshal(data, &custom)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	imp := &IssueImport{}
+	versions := make(map[string]bool)
+	for i, item := range result.Issues {
+		fields := item.Fields
+		issue := &importedIssue{
+			Number:   issueKeyNumber(item.Key),
+			Project:  issueKeyProject(item.Key),
+			Title:    fields.Summary,
+			Content:  jiraJSONText(fields.Description),
+			Reporter: fields.Reporter.login(),
+			Labels:   fields.Labels,
+		}
+		issue.ForeignID, _ = strconv.ParseInt(item.ID, 10, 64)
+		if issue.ForeignID == 0 {
+			issue.ForeignID = issue.Number
+		}
+		if fields.Reporter != nil {
+			issue.Email = fields.Reporter.EmailAddress
+		}
+		if fields.Status != nil {
+			issue.Status = fields.Status.Name
+			issue.Done = fields.Status.StatusCategory.Key == "done"
+		}
+		issue.Created, _ = parseImportTime(fields.Created)
+		issue.Updated, _ = parseImportTime(fields.Updated)
+		if resolved, ok := parseImportTime(fields.ResolutionDate); ok {
+			issue.Resolved = &resolved
+		}
+
+		for i, version := range fields.FixVersions {
+			if i == 0 {
+				issue.Milestone = version.Name
+			}
+			if versions[version.Name] {
+				continue
+			}
+			versions[version.Name] = true
+			milestone := &base.Milestone{
+				Title:       version.Name,
+				Description: version.Description,
+				State:       "open",
+			}
+			if version.Released {
+				milestone.State = "closed"
+			}
+			if deadline, ok := parseImportTime(version.ReleaseDate); ok {
+				milestone.Deadline = &deadline
+				if version.Released {
+					milestone.Closed = &deadline
+				}
+			}
+			imp.Milestones = append(imp.Milestones, milestone)
+		}
+
+		if fields.IssueType != nil {
+			issue.addField("Type", fields.IssueType.Name)
+		}
+		if fields.Priority != nil {
+			issue.addField("Priority", fields.Priority.Name)
+		}
+		for _, component := range fields.Components {
+			issue.addField("Component", component.Name)
+		}
+		if fields.Resolution != nil {
+			issue.addField("Resolution", fields.Resolution.Name)
+		}
+		for id, value := range custom.Issues[i].Fields {
+			if !strings.HasPrefix(id, "customfield_") {
+				continue
+			}
+			name := result.Names[id]
+			if name == "" {
+				name = id
+			}
+			issue.addField(name, jiraJSONFieldValues(value)...)
+		}
+
+		if fields.Comment != nil {
+			for _, comment := range fields.Comment.Comments {
+				author := comment.Author.login()
+				created, _ := parseImportTime(comment.Created)
+				updated, _ := parseImportTime(comment.Updated)
+				issue.Comments = append(issue.Comments, &base.Comment{
+					PosterID:   importUserID(author),
+					PosterName: author,
+					Created:    created,
+					Updated:    updated,
+					Content:    jiraJSONText(comment.Body),
+				})
+			}
+		}
+		imp.Issues = append(imp.Issues, issue)
+	}
+	return imp, nil
+}
+
+// jiraJSONFieldValues returns the values of a custom field, which may be a plain value,
+// an option, a user or a list of them
+func jiraJSONFieldValues(value interface{}) []string {
+	var values []string
+	var collect func(v interface{})
+	collect = func(v interface{}) {
+		switch v := v.(type) {
+		case string:
+			values = append(values, v)
+		case float64:
+			values = append(values, strconv.FormatFloat(v, 'f', -1, 64))
+		case bool:
+			values = append(values, strconv.FormatBool(v))
+		case []interface{}:
+			for _, e := range v {
+				collect(e)
+			}
+		case map[string]interface{}:
+			for _, key := range []string{"value", "name", "displayName"} {
+				if s, ok := v[key].(string); ok {
+					values = append(values, s)
+					return
+				}
+			}
+		}
+	}
+	collect(value)
+	return values
+}
+
+// jiraJSONText returns a text which is either wiki markup of Jira Server or a document of the
+// Atlassian Document Format used by Jira Cloud, whose text is extracted paragraph by paragraph
+func jiraJSONText(value interface{}) string {
+	if text, ok := value.(string); ok {
+		return strings.TrimSpace(text)
+	}
+
+	var sb strings.Builder
+	var write func(node map[string]interface{})
+	write = func(node map[string]interface{}) {
+		tp, _ := node["type"].(string)
+		switch tp {
+		case "text":
+			text, _ := node["text"].(string)
+			sb.WriteString(text)
+		case "hardBreak":
+			sb.WriteString("\n")
+		}
+		children, _ := node["content"].([]interface{})
+		for _, child := range children {
+			if child, ok := child.(map[string]interface{}); ok {
+				write(child)
+			}
+		}
+		switch tp {
+		case "paragraph", "heading", "codeBlock", "listItem", "blockquote":
+			sb.WriteString("\n\n")
+		}
+	}
+	if doc, ok := value.(map[string]interface{}); ok {
+		write(doc)
+	}
+	return strings.TrimSpace(sb.String())
+}
@@ -6,7 +6,11 @@
 package migrations
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -20,6 +24,32 @@ func TestMain(m *testing.M) {
 	db.MainTest(m, filepath.Join("..", ".."))
 }
 
+// newRecordedTestServer serves the API responses recorded below testdata/<name>.
+// A response is stored in <path>.json or <path>.xml, later pages in <path>.page<n>.json or <path>.start<n>.json.
+func newRecordedTestServer(t *testing.T, name string) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := filepath.Join("testdata", name, filepath.FromSlash(r.URL.Path))
+		if page := r.URL.Query().Get("page"); page != "" && page != "1" {
+			p += ".page" + page
+		}
+		if start := r.URL.Query().Get("start"); start != "" && start != "0" {
+			p += ".start" + start
+		}
+		for _, ext := range []string{".json", ".xml"} {
+			data, err := os.ReadFile(p + ext)
+			if err != nil {
+				continue
+			}
+			_, _ = w.Write([]byte(strings.ReplaceAll(string(data), "{{SERVER}}", server.URL)))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
 func timePtr(t time.Time) *time.Time {
 	return &t
 }
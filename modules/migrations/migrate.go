@@ -72,6 +72,27 @@ func IsMigrateURLAllowed(remoteURL string, doer *models.User) error {
 		return &models.ErrInvalidCloneAddr{Host: u.Host, IsProtocolInvalid: true, IsPermissionDenied: true, IsURLError: true}
 	}
 
+	return isRemoteHostAllowed(u)
+}
+
+// IsPushMirrorURLAllowed checks if the remote address of a push mirror is allowed, which may also be an SSH URL
+func IsPushMirrorURLAllowed(remoteURL string, doer *models.User) error {
+	u, err := url.Parse(remoteURL)
+	if err != nil {
+		return &models.ErrInvalidCloneAddr{IsURLError: true}
+	}
+	if u.Scheme != "ssh" {
+		return IsMigrateURLAllowed(remoteURL, doer)
+	}
+	if u.Host == "" || u.User != nil && u.User.String() != u.User.Username() {
+		return &models.ErrInvalidCloneAddr{Host: u.Host, IsURLError: true}
+	}
+	return isRemoteHostAllowed(u)
+}
+
+// isRemoteHostAllowed checks the host of a remote address against the allowed and blocked domains
+// and, unless allowed, local networks
+func isRemoteHostAllowed(u *url.URL) error {
 	host := strings.ToLower(u.Host)
 	if len(setting.Migrations.AllowedDomains) > 0 {
 		if !allowList.Match(host) {
@@ -0,0 +1,243 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations/base"
+)
+
+// SyncRepository syncs the issues, pull requests, comments, reviews, labels, milestones and releases
+// of a repository migrated as a mirror with the changes made on the original service since lastSynced.
+// Records migrated before are updated in place, new ones are created.
+func SyncRepository(ctx context.Context, doer *models.User, repo *models.Repository, opts base.MigrateOptions, lastSynced time.Time) error {
+	if err := IsMigrateURLAllowed(opts.CloneAddr, doer); err != nil {
+		return err
+	}
+	downloader, err := newDownloader(ctx, repo.OwnerName, opts)
+	if err != nil {
+		return err
+	}
+
+	uploader, err := newGiteaLocalSyncUploader(ctx, doer, repo)
+	if err != nil {
+		return err
+	}
+	defer uploader.Close()
+
+	return syncRepository(downloader, uploader, opts, lastSynced)
+}
+
+// getUpdatedIssues returns the issues updated after the given time, filtering the full
+// listing if the downloader cannot list them by itself
+func getUpdatedIssues(downloader base.Downloader, page, perPage int, updatedAfter time.Time) ([]*base.Issue, bool, error) {
+	issues, isEnd, err := downloader.GetNewIssues(page, perPage, updatedAfter)
+	if !base.IsErrNotSupported(err) {
+		return issues, isEnd, err
+	}
+
+	issues, isEnd, err = downloader.GetIssues(page, perPage)
+	if err != nil {
+		return nil, false, err
+	}
+	updated := issues[:0]
+	for _, issue := range issues {
+		if issue.Updated.IsZero() || issue.Updated.After(updatedAfter) {
+			updated = append(updated, issue)
+		}
+	}
+	return updated, isEnd, nil
+}
+
+// getUpdatedPullRequests returns the pull requests updated after the given time, filtering the
+// full listing if the downloader cannot list them by itself
+func getUpdatedPullRequests(downloader base.Downloader, page, perPage int, updatedAfter time.Time) ([]*base.PullRequest, bool, error) {
+	prs, isEnd, err := downloader.GetNewPullRequests(page, perPage, updatedAfter)
+	if !base.IsErrNotSupported(err) {
+		return prs, isEnd, err
+	}
+
+	prs, isEnd, err = downloader.GetPullRequests(page, perPage)
+	if err != nil {
+		return nil, false, err
+	}
+	updated := prs[:0]
+	for _, pr := range prs {
+		if pr.Updated.IsZero() || pr.Updated.After(updatedAfter) {
+			updated = append(updated, pr)
+		}
+	}
+	return updated, isEnd, nil
+}
+
+func getIssueComments(downloader base.Downloader, number int64, context base.IssueContext) ([]*base.Comment, error) {
+	comments, _, err := downloader.GetComments(base.GetCommentOptions{
+		Context: context,
+	})
+	if err != nil {
+		if !base.IsErrNotSupported(err) {
+			return nil, err
+		}
+		log.Warn("syncing comments is not supported, ignored")
+	}
+	// the issue may have got another local index than on the original service
+	for _, comment := range comments {
+		comment.IssueIndex = number
+	}
+	return comments, nil
+}
+
+func syncRepository(downloader base.Downloader, uploader *GiteaLocalUploader, opts base.MigrateOptions, lastSynced time.Time) error {
+	log.Trace("syncing topics")
+	topics, err := downloader.GetTopics()
+	if err != nil {
+		if !base.IsErrNotSupported(err) {
+			return err
+		}
+		log.Warn("syncing topics is not supported, ignored")
+	} else if err = uploader.CreateTopics(topics...); err != nil {
+		return err
+	}
+
+	if opts.Milestones {
+		log.Trace("syncing milestones")
+		milestones, err := downloader.GetMilestones()
+		if err != nil {
+			if !base.IsErrNotSupported(err) {
+				return err
+			}
+			log.Warn("syncing milestones is not supported, ignored")
+		}
+		if err = uploader.UpdateMilestones(milestones...); err != nil {
+			return err
+		}
+	}
+
+	if opts.Labels {
+		log.Trace("syncing labels")
+		labels, err := downloader.GetLabels()
+		if err != nil {
+			if !base.IsErrNotSupported(err) {
+				return err
+			}
+			log.Warn("syncing labels is not supported, ignored")
+		}
+		if err = uploader.UpdateLabels(labels...); err != nil {
+			return err
+		}
+	}
+
+	if opts.Releases {
+		log.Trace("syncing releases")
+		releases, err := downloader.GetReleases()
+		if err != nil {
+			if !base.IsErrNotSupported(err) {
+				return err
+			}
+			log.Warn("syncing releases is not supported, ignored")
+		}
+		if err = uploader.UpdateReleases(releases...); err != nil {
+			return err
+		}
+	}
+
+	if opts.Issues {
+		log.Trace("syncing issues and comments")
+		var issueBatchSize = uploader.MaxBatchInsertSize("issue")
+		for i := 1; ; i++ {
+			issues, isEnd, err := getUpdatedIssues(downloader, i, issueBatchSize, lastSynced)
+			if err != nil {
+				if !base.IsErrNotSupported(err) {
+					return err
+				}
+				log.Warn("syncing issues is not supported, ignored")
+				break
+			}
+
+			if err := uploader.UpdateIssues(issues...); err != nil {
+				return err
+			}
+
+			if opts.Comments {
+				var allComments = make([]*base.Comment, 0, len(issues))
+				for _, issue := range issues {
+					comments, err := getIssueComments(downloader, issue.Number, issue.Context)
+					if err != nil {
+						return err
+					}
+					allComments = append(allComments, comments...)
+				}
+				if err := uploader.UpdateComments(allComments...); err != nil {
+					return err
+				}
+			}
+
+			if isEnd {
+				break
+			}
+		}
+	}
+
+	if opts.PullRequests {
+		log.Trace("syncing pull requests, comments and reviews")
+		var prBatchSize = uploader.MaxBatchInsertSize("pullrequest")
+		for i := 1; ; i++ {
+			prs, isEnd, err := getUpdatedPullRequests(downloader, i, prBatchSize, lastSynced)
+			if err != nil {
+				if !base.IsErrNotSupported(err) {
+					return err
+				}
+				log.Warn("syncing pull requests is not supported, ignored")
+				break
+			}
+
+			if err := uploader.UpdatePullRequests(prs...); err != nil {
+				return err
+			}
+
+			if opts.Comments {
+				var (
+					allComments = make([]*base.Comment, 0, len(prs))
+					allReviews  = make([]*base.Review, 0, len(prs))
+				)
+				for _, pr := range prs {
+					comments, err := getIssueComments(downloader, pr.Number, pr.Context)
+					if err != nil {
+						return err
+					}
+					allComments = append(allComments, comments...)
+
+					reviews, err := downloader.GetReviews(pr.Context)
+					if err != nil {
+						if !base.IsErrNotSupported(err) {
+							return err
+						}
+						continue
+					}
+					for _, review := range reviews {
+						review.IssueIndex = pr.Number
+					}
+					allReviews = append(allReviews, reviews...)
+				}
+				if err := uploader.UpdateComments(allComments...); err != nil {
+					return err
+				}
+				if err := uploader.UpdateReviews(allReviews...); err != nil {
+					return err
+				}
+			}
+
+			if isEnd {
+				break
+			}
+		}
+	}
+
+	return models.RecalculateIssueIndexForRepo(uploader.repo.ID)
+}
@@ -0,0 +1,167 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/migrations/base"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type syncTestDownloader struct {
+	base.NullDownloader
+	milestones []*base.Milestone
+	labels     []*base.Label
+	issues     []*base.Issue
+	comments   map[int64][]*base.Comment
+}
+
+func (d *syncTestDownloader) GetMilestones() ([]*base.Milestone, error) {
+	return d.milestones, nil
+}
+
+func (d *syncTestDownloader) GetLabels() ([]*base.Label, error) {
+	return d.labels, nil
+}
+
+func (d *syncTestDownloader) GetIssues(page, perPage int) ([]*base.Issue, bool, error) {
+	return d.issues, true, nil
+}
+
+func (d *syncTestDownloader) GetComments(opts base.GetCommentOptions) ([]*base.Comment, bool, error) {
+	return d.comments[opts.Context.ForeignID()], true, nil
+}
+
+func TestSyncRepository(t *testing.T) {
+	assert.NoError(t, db.PrepareTestDatabase())
+
+	user := db.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	repo := db.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	lastSynced := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	updated := lastSynced.Add(time.Hour)
+
+	downloader := &syncTestDownloader{
+		milestones: []*base.Milestone{
+			{Title: "milestone1", Description: "content1", State: "closed"},
+		},
+		labels: []*base.Label{
+			{Name: "label1", Color: "123456"},
+			{Name: "label3", Color: "654321"},
+		},
+		issues: []*base.Issue{
+			{
+				Number:  1,
+				Title:   "issue1 renamed",
+				Content: "content for the first issue",
+				State:   "closed",
+				Created: time.Unix(946684800, 0),
+				Updated: updated,
+				Closed:  &updated,
+				Labels:  []*base.Label{{Name: "label3"}},
+				Context: base.BasicIssueContext(1),
+			},
+			{
+				// not updated since the last sync
+				Number:  3,
+				Title:   "an unchanged pull request",
+				Created: time.Unix(946684820, 0),
+				Updated: lastSynced.Add(-time.Hour),
+				Context: base.BasicIssueContext(3),
+			},
+			{
+				// the index is taken locally by another issue
+				Number:     4,
+				Title:      "a new issue",
+				PosterID:   100,
+				PosterName: "remote",
+				State:      "open",
+				Created:    updated,
+				Updated:    updated,
+				Context:    base.BasicIssueContext(50),
+			},
+		},
+		comments: map[int64][]*base.Comment{
+			1: {
+				{
+					PosterID:   100,
+					PosterName: "remote",
+					Content:    "first version",
+					Created:    updated,
+					Updated:    updated,
+				},
+			},
+		},
+	}
+
+	sync := func() {
+		uploader, err := newGiteaLocalSyncUploader(context.Background(), user, repo)
+		assert.NoError(t, err)
+		defer uploader.Close()
+		assert.NoError(t, syncRepository(downloader, uploader, base.MigrateOptions{
+			Milestones: true,
+			Labels:     true,
+			Issues:     true,
+			Comments:   true,
+		}, lastSynced))
+	}
+	sync()
+
+	milestone := db.AssertExistsAndLoadBean(t, &models.Milestone{ID: 1}).(*models.Milestone)
+	assert.True(t, milestone.IsClosed)
+
+	label1 := db.AssertExistsAndLoadBean(t, &models.Label{ID: 1}).(*models.Label)
+	assert.EqualValues(t, "#123456", label1.Color)
+	label3 := db.AssertExistsAndLoadBean(t, &models.Label{RepoID: repo.ID, Name: "label3"}).(*models.Label)
+	assert.EqualValues(t, 1, label3.NumIssues)
+	assert.EqualValues(t, 1, label3.NumClosedIssues)
+
+	issue1 := db.AssertExistsAndLoadBean(t, &models.Issue{ID: 1}).(*models.Issue)
+	assert.EqualValues(t, "issue1 renamed", issue1.Title)
+	assert.True(t, issue1.IsClosed)
+	assert.NoError(t, issue1.LoadLabels())
+	if assert.Len(t, issue1.Labels, 1) {
+		assert.EqualValues(t, label3.ID, issue1.Labels[0].ID)
+	}
+
+	pull := db.AssertExistsAndLoadBean(t, &models.Issue{ID: 3}).(*models.Issue)
+	assert.EqualValues(t, "issue3", pull.Title)
+
+	ref, err := models.GetForeignReference(repo.ID, 50, models.ForeignTypeIssues)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 6, ref.LocalIndex)
+	newIssue := db.AssertExistsAndLoadBean(t, &models.Issue{RepoID: repo.ID, Index: 6}).(*models.Issue)
+	assert.EqualValues(t, "a new issue", newIssue.Title)
+	assert.EqualValues(t, "remote", newIssue.OriginalAuthor)
+
+	// a second sync updates the synced records instead of duplicating them
+	downloader.issues[2].Title = "a new issue renamed"
+	downloader.comments[1][0].Content = "second version"
+	sync()
+
+	newIssue = db.AssertExistsAndLoadBean(t, &models.Issue{RepoID: repo.ID, Index: 6}).(*models.Issue)
+	assert.EqualValues(t, "a new issue renamed", newIssue.Title)
+	db.AssertNotExistsBean(t, &models.Issue{RepoID: repo.ID, Index: 7})
+
+	comments, err := models.FindComments(&models.FindCommentsOptions{
+		IssueID: issue1.ID,
+		Type:    models.CommentTypeComment,
+	})
+	assert.NoError(t, err)
+	var synced []*models.Comment
+	for _, comment := range comments {
+		if comment.OriginalAuthorID == 100 {
+			synced = append(synced, comment)
+		}
+	}
+	if assert.Len(t, synced, 1) {
+		assert.EqualValues(t, "second version", synced[0].Content)
+	}
+}
@@ -59,4 +59,31 @@ type Notifier interface {
 	NotifySyncDeleteRef(doer *models.User, repo *models.Repository, refType, refFullName string)
 
 	NotifyRepoPendingTransfer(doer, newOwner *models.User, repo *models.Repository)
+
+	NotifyNewWikiPage(doer *models.User, repo *models.Repository, page, comment string)
+	NotifyEditWikiPage(doer *models.User, repo *models.Repository, page, comment string)
+	NotifyDeleteWikiPage(doer *models.User, repo *models.Repository, page string)
+
+	NotifyCreateCommitStatus(doer *models.User, repo *models.Repository, sha string, status *models.CommitStatus)
+
+	NotifyAddCollaborator(doer *models.User, repo *models.Repository, collaborator *models.User)
+	NotifyChangeCollaboratorAccessMode(doer *models.User, repo *models.Repository, collaborator *models.User, mode models.AccessMode)
+	NotifyRemoveCollaborator(doer *models.User, repo *models.Repository, collaborator *models.User)
+	NotifyAddTeamRepository(doer *models.User, team *models.Team, repo *models.Repository)
+	NotifyRemoveTeamRepository(doer *models.User, team *models.Team, repo *models.Repository)
+
+	NotifyCreateBranchProtection(doer *models.User, repo *models.Repository, rule *models.ProtectedBranch)
+	NotifyUpdateBranchProtection(doer *models.User, repo *models.Repository, rule *models.ProtectedBranch)
+	NotifyDeleteBranchProtection(doer *models.User, repo *models.Repository, rule *models.ProtectedBranch)
+
+	NotifyChangeRepositoryVisibility(doer *models.User, repo *models.Repository)
+	NotifyChangeRepositoryArchiveState(doer *models.User, repo *models.Repository)
+
+	NotifyStarRepository(doer *models.User, repo *models.Repository, star bool)
+
+	NotifyCreateUser(doer, u *models.User)
+	NotifyDeleteUser(doer, u *models.User)
+	NotifyCreateOrganization(doer, org *models.User)
+	NotifyDeleteOrganization(doer, org *models.User)
+	NotifyLoginFailed(loginName, remoteAddr, reason string)
 }
@@ -170,3 +170,83 @@ func (*NullNotifier) NotifySyncDeleteRef(doer *models.User, repo *models.Reposit
 // NotifyRepoPendingTransfer places a place holder function
 func (*NullNotifier) NotifyRepoPendingTransfer(doer, newOwner *models.User, repo *models.Repository) {
 }
+
+// NotifyNewWikiPage places a place holder function
+func (*NullNotifier) NotifyNewWikiPage(doer *models.User, repo *models.Repository, page, comment string) {
+}
+
+// NotifyEditWikiPage places a place holder function
+func (*NullNotifier) NotifyEditWikiPage(doer *models.User, repo *models.Repository, page, comment string) {
+}
+
+// NotifyDeleteWikiPage places a place holder function
+func (*NullNotifier) NotifyDeleteWikiPage(doer *models.User, repo *models.Repository, page string) {
+}
+
+// NotifyCreateCommitStatus places a place holder function
+func (*NullNotifier) NotifyCreateCommitStatus(doer *models.User, repo *models.Repository, sha string, status *models.CommitStatus) {
+}
+
+// NotifyAddCollaborator places a place holder function
+func (*NullNotifier) NotifyAddCollaborator(doer *models.User, repo *models.Repository, collaborator *models.User) {
+}
+
+// NotifyChangeCollaboratorAccessMode places a place holder function
+func (*NullNotifier) NotifyChangeCollaboratorAccessMode(doer *models.User, repo *models.Repository, collaborator *models.User, mode models.AccessMode) {
+}
+
+// NotifyRemoveCollaborator places a place holder function
+func (*NullNotifier) NotifyRemoveCollaborator(doer *models.User, repo *models.Repository, collaborator *models.User) {
+}
+
+// NotifyAddTeamRepository places a place holder function
+func (*NullNotifier) NotifyAddTeamRepository(doer *models.User, team *models.Team, repo *models.Repository) {
+}
+
+// NotifyRemoveTeamRepository places a place holder function
+func (*NullNotifier) NotifyRemoveTeamRepository(doer *models.User, team *models.Team, repo *models.Repository) {
+}
+
+// NotifyCreateBranchProtection places a place holder function
+func (*NullNotifier) NotifyCreateBranchProtection(doer *models.User, repo *models.Repository, rule *models.ProtectedBranch) {
+}
+
+// NotifyUpdateBranchProtection places a place holder function
+func (*NullNotifier) NotifyUpdateBranchProtection(doer *models.User, repo *models.Repository, rule *models.ProtectedBranch) {
+}
+
+// NotifyDeleteBranchProtection places a place holder function
+func (*NullNotifier) NotifyDeleteBranchProtection(doer *models.User, repo *models.Repository, rule *models.ProtectedBranch) {
+}
+
+// NotifyChangeRepositoryVisibility places a place holder function
+func (*NullNotifier) NotifyChangeRepositoryVisibility(doer *models.User, repo *models.Repository) {
+}
+
+// NotifyChangeRepositoryArchiveState places a place holder function
+func (*NullNotifier) NotifyChangeRepositoryArchiveState(doer *models.User, repo *models.Repository) {
+}
+
+// NotifyStarRepository places a place holder function
+func (*NullNotifier) NotifyStarRepository(doer *models.User, repo *models.Repository, star bool) {
+}
+
+// NotifyCreateUser places a place holder function
+func (*NullNotifier) NotifyCreateUser(doer, u *models.User) {
+}
+
+// NotifyDeleteUser places a place holder function
+func (*NullNotifier) NotifyDeleteUser(doer, u *models.User) {
+}
+
+// NotifyCreateOrganization places a place holder function
+func (*NullNotifier) NotifyCreateOrganization(doer, org *models.User) {
+}
+
+// NotifyDeleteOrganization places a place holder function
+func (*NullNotifier) NotifyDeleteOrganization(doer, org *models.User) {
+}
+
+// NotifyLoginFailed places a place holder function
+func (*NullNotifier) NotifyLoginFailed(loginName, remoteAddr, reason string) {
+}
@@ -297,3 +297,143 @@ func NotifyRepoPendingTransfer(doer, newOwner *models.User, repo *models.Reposit
 		notifier.NotifyRepoPendingTransfer(doer, newOwner, repo)
 	}
 }
+
+// NotifyNewWikiPage notifies wiki page creation to notifiers
+func NotifyNewWikiPage(doer *models.User, repo *models.Repository, page, comment string) {
+	for _, notifier := range notifiers {
+		notifier.NotifyNewWikiPage(doer, repo, page, comment)
+	}
+}
+
+// NotifyEditWikiPage notifies wiki page edit to notifiers
+func NotifyEditWikiPage(doer *models.User, repo *models.Repository, page, comment string) {
+	for _, notifier := range notifiers {
+		notifier.NotifyEditWikiPage(doer, repo, page, comment)
+	}
+}
+
+// NotifyDeleteWikiPage notifies wiki page deletion to notifiers
+func NotifyDeleteWikiPage(doer *models.User, repo *models.Repository, page string) {
+	for _, notifier := range notifiers {
+		notifier.NotifyDeleteWikiPage(doer, repo, page)
+	}
+}
+
+// NotifyCreateCommitStatus notifies commit status creation to notifiers
+func NotifyCreateCommitStatus(doer *models.User, repo *models.Repository, sha string, status *models.CommitStatus) {
+	for _, notifier := range notifiers {
+		notifier.NotifyCreateCommitStatus(doer, repo, sha, status)
+	}
+}
+
+// NotifyAddCollaborator notifies new collaborator of a repository to notifiers
+func NotifyAddCollaborator(doer *models.User, repo *models.Repository, collaborator *models.User) {
+	for _, notifier := range notifiers {
+		notifier.NotifyAddCollaborator(doer, repo, collaborator)
+	}
+}
+
+// NotifyChangeCollaboratorAccessMode notifies access mode change of a collaborator to notifiers
+func NotifyChangeCollaboratorAccessMode(doer *models.User, repo *models.Repository, collaborator *models.User, mode models.AccessMode) {
+	for _, notifier := range notifiers {
+		notifier.NotifyChangeCollaboratorAccessMode(doer, repo, collaborator, mode)
+	}
+}
+
+// NotifyRemoveCollaborator notifies removal of a collaborator to notifiers
+func NotifyRemoveCollaborator(doer *models.User, repo *models.Repository, collaborator *models.User) {
+	for _, notifier := range notifiers {
+		notifier.NotifyRemoveCollaborator(doer, repo, collaborator)
+	}
+}
+
+// NotifyAddTeamRepository notifies team access granted to a repository to notifiers
+func NotifyAddTeamRepository(doer *models.User, team *models.Team, repo *models.Repository) {
+	for _, notifier := range notifiers {
+		notifier.NotifyAddTeamRepository(doer, team, repo)
+	}
+}
+
+// NotifyRemoveTeamRepository notifies team access revoked from a repository to notifiers
+func NotifyRemoveTeamRepository(doer *models.User, team *models.Team, repo *models.Repository) {
+	for _, notifier := range notifiers {
+		notifier.NotifyRemoveTeamRepository(doer, team, repo)
+	}
+}
+
+// NotifyCreateBranchProtection notifies branch protection rule creation to notifiers
+func NotifyCreateBranchProtection(doer *models.User, repo *models.Repository, rule *models.ProtectedBranch) {
+	for _, notifier := range notifiers {
+		notifier.NotifyCreateBranchProtection(doer, repo, rule)
+	}
+}
+
+// NotifyUpdateBranchProtection notifies branch protection rule update to notifiers
+func NotifyUpdateBranchProtection(doer *models.User, repo *models.Repository, rule *models.ProtectedBranch) {
+	for _, notifier := range notifiers {
+		notifier.NotifyUpdateBranchProtection(doer, repo, rule)
+	}
+}
+
+// NotifyDeleteBranchProtection notifies branch protection rule deletion to notifiers
+func NotifyDeleteBranchProtection(doer *models.User, repo *models.Repository, rule *models.ProtectedBranch) {
+	for _, notifier := range notifiers {
+		notifier.NotifyDeleteBranchProtection(doer, repo, rule)
+	}
+}
+
+// NotifyChangeRepositoryVisibility notifies repository visibility change to notifiers
+func NotifyChangeRepositoryVisibility(doer *models.User, repo *models.Repository) {
+	for _, notifier := range notifiers {
+		notifier.NotifyChangeRepositoryVisibility(doer, repo)
+	}
+}
+
+// NotifyChangeRepositoryArchiveState notifies repository archival or unarchival to notifiers
+func NotifyChangeRepositoryArchiveState(doer *models.User, repo *models.Repository) {
+	for _, notifier := range notifiers {
+		notifier.NotifyChangeRepositoryArchiveState(doer, repo)
+	}
+}
+
+// NotifyStarRepository notifies repository star or unstar to notifiers
+func NotifyStarRepository(doer *models.User, repo *models.Repository, star bool) {
+	for _, notifier := range notifiers {
+		notifier.NotifyStarRepository(doer, repo, star)
+	}
+}
+
+// NotifyCreateUser notifies user creation to notifiers
+func NotifyCreateUser(doer, u *models.User) {
+	for _, notifier := range notifiers {
+		notifier.NotifyCreateUser(doer, u)
+	}
+}
+
+// NotifyDeleteUser notifies user deletion to notifiers
+func NotifyDeleteUser(doer, u *models.User) {
+	for _, notifier := range notifiers {
+		notifier.NotifyDeleteUser(doer, u)
+	}
+}
+
+// NotifyCreateOrganization notifies organization creation to notifiers
+func NotifyCreateOrganization(doer, org *models.User) {
+	for _, notifier := range notifiers {
+		notifier.NotifyCreateOrganization(doer, org)
+	}
+}
+
+// NotifyDeleteOrganization notifies organization deletion to notifiers
+func NotifyDeleteOrganization(doer, org *models.User) {
+	for _, notifier := range notifiers {
+		notifier.NotifyDeleteOrganization(doer, org)
+	}
+}
+
+// NotifyLoginFailed notifies a failed sign in attempt to notifiers
+func NotifyLoginFailed(loginName, remoteAddr, reason string) {
+	for _, notifier := range notifiers {
+		notifier.NotifyLoginFailed(loginName, remoteAddr, reason)
+	}
+}
@@ -819,3 +819,219 @@ func (m *webhookNotifier) NotifySyncCreateRef(pusher *models.User, repo *models.
 func (m *webhookNotifier) NotifySyncDeleteRef(pusher *models.User, repo *models.Repository, refType, refFullName string) {
 	m.NotifyDeleteRef(pusher, repo, refType, refFullName)
 }
+
+func sendWikiHook(doer *models.User, repo *models.Repository, action api.HookWikiAction, page, comment string) {
+	mode, _ := models.AccessLevel(doer, repo)
+	if err := webhook_services.PrepareWebhooks(repo, models.HookEventWiki, &api.WikiPayload{
+		Action:     action,
+		Repository: convert.ToRepo(repo, mode),
+		Sender:     convert.ToUser(doer, nil),
+		Page:       page,
+		Comment:    comment,
+	}); err != nil {
+		log.Error("PrepareWebhooks [repo_id: %d]: %v", repo.ID, err)
+	}
+}
+
+func (m *webhookNotifier) NotifyNewWikiPage(doer *models.User, repo *models.Repository, page, comment string) {
+	sendWikiHook(doer, repo, api.HookWikiCreated, page, comment)
+}
+
+func (m *webhookNotifier) NotifyEditWikiPage(doer *models.User, repo *models.Repository, page, comment string) {
+	sendWikiHook(doer, repo, api.HookWikiEdited, page, comment)
+}
+
+func (m *webhookNotifier) NotifyDeleteWikiPage(doer *models.User, repo *models.Repository, page string) {
+	sendWikiHook(doer, repo, api.HookWikiDeleted, page, "")
+}
+
+func (m *webhookNotifier) NotifyCreateCommitStatus(doer *models.User, repo *models.Repository, sha string, status *models.CommitStatus) {
+	mode, _ := models.AccessLevel(doer, repo)
+	if err := webhook_services.PrepareWebhooks(repo, models.HookEventStatus, &api.CommitStatusPayload{
+		SHA:         sha,
+		State:       status.State,
+		Context:     status.Context,
+		Description: status.Description,
+		TargetURL:   status.TargetURL,
+		Repository:  convert.ToRepo(repo, mode),
+		Sender:      convert.ToUser(doer, nil),
+	}); err != nil {
+		log.Error("PrepareWebhooks [repo_id: %d]: %v", repo.ID, err)
+	}
+}
+
+func sendMemberHook(doer *models.User, repo *models.Repository, payload *api.MemberPayload) {
+	mode, _ := models.AccessLevel(doer, repo)
+	payload.Repository = convert.ToRepo(repo, mode)
+	payload.Sender = convert.ToUser(doer, nil)
+	if err := webhook_services.PrepareWebhooks(repo, models.HookEventMember, payload); err != nil {
+		log.Error("PrepareWebhooks [repo_id: %d]: %v", repo.ID, err)
+	}
+}
+
+func (m *webhookNotifier) NotifyAddCollaborator(doer *models.User, repo *models.Repository, collaborator *models.User) {
+	mode, _ := models.AccessLevel(collaborator, repo)
+	sendMemberHook(doer, repo, &api.MemberPayload{
+		Action:     api.HookMemberAdded,
+		Member:     convert.ToUser(collaborator, nil),
+		Permission: mode.String(),
+	})
+}
+
+func (m *webhookNotifier) NotifyChangeCollaboratorAccessMode(doer *models.User, repo *models.Repository, collaborator *models.User, mode models.AccessMode) {
+	sendMemberHook(doer, repo, &api.MemberPayload{
+		Action:     api.HookMemberEdited,
+		Member:     convert.ToUser(collaborator, nil),
+		Permission: mode.String(),
+	})
+}
+
+func (m *webhookNotifier) NotifyRemoveCollaborator(doer *models.User, repo *models.Repository, collaborator *models.User) {
+	sendMemberHook(doer, repo, &api.MemberPayload{
+		Action: api.HookMemberRemoved,
+		Member: convert.ToUser(collaborator, nil),
+	})
+}
+
+func (m *webhookNotifier) NotifyAddTeamRepository(doer *models.User, team *models.Team, repo *models.Repository) {
+	sendMemberHook(doer, repo, &api.MemberPayload{
+		Action:     api.HookMemberAdded,
+		Team:       convert.ToTeam(team),
+		Permission: team.Authorize.String(),
+	})
+}
+
+func (m *webhookNotifier) NotifyRemoveTeamRepository(doer *models.User, team *models.Team, repo *models.Repository) {
+	sendMemberHook(doer, repo, &api.MemberPayload{
+		Action: api.HookMemberRemoved,
+		Team:   convert.ToTeam(team),
+	})
+}
+
+func sendBranchProtectionHook(doer *models.User, repo *models.Repository, rule *models.ProtectedBranch, action api.HookBranchProtectionAction) {
+	mode, _ := models.AccessLevel(doer, repo)
+	if err := webhook_services.PrepareWebhooks(repo, models.HookEventBranchProtection, &api.BranchProtectionPayload{
+		Action:     action,
+		Rule:       convert.ToBranchProtection(rule),
+		Repository: convert.ToRepo(repo, mode),
+		Sender:     convert.ToUser(doer, nil),
+	}); err != nil {
+		log.Error("PrepareWebhooks [repo_id: %d]: %v", repo.ID, err)
+	}
+}
+
+func (m *webhookNotifier) NotifyCreateBranchProtection(doer *models.User, repo *models.Repository, rule *models.ProtectedBranch) {
+	sendBranchProtectionHook(doer, repo, rule, api.HookBranchProtectionCreated)
+}
+
+func (m *webhookNotifier) NotifyUpdateBranchProtection(doer *models.User, repo *models.Repository, rule *models.ProtectedBranch) {
+	sendBranchProtectionHook(doer, repo, rule, api.HookBranchProtectionEdited)
+}
+
+func (m *webhookNotifier) NotifyDeleteBranchProtection(doer *models.User, repo *models.Repository, rule *models.ProtectedBranch) {
+	sendBranchProtectionHook(doer, repo, rule, api.HookBranchProtectionDeleted)
+}
+
+func sendRepositoryHook(doer *models.User, repo *models.Repository, action api.HookRepoAction) {
+	if err := webhook_services.PrepareWebhooks(repo, models.HookEventRepository, &api.RepositoryPayload{
+		Action:       action,
+		Repository:   convert.ToRepo(repo, models.AccessModeOwner),
+		Organization: convert.ToUser(repo.MustOwner(), nil),
+		Sender:       convert.ToUser(doer, nil),
+	}); err != nil {
+		log.Error("PrepareWebhooks [repo_id: %d]: %v", repo.ID, err)
+	}
+}
+
+func (m *webhookNotifier) NotifyChangeRepositoryVisibility(doer *models.User, repo *models.Repository) {
+	if repo.IsPrivate {
+		sendRepositoryHook(doer, repo, api.HookRepoPrivatized)
+	} else {
+		sendRepositoryHook(doer, repo, api.HookRepoPublicized)
+	}
+}
+
+func (m *webhookNotifier) NotifyChangeRepositoryArchiveState(doer *models.User, repo *models.Repository) {
+	if repo.IsArchived {
+		sendRepositoryHook(doer, repo, api.HookRepoArchived)
+	} else {
+		sendRepositoryHook(doer, repo, api.HookRepoUnarchived)
+	}
+}
+
+func (m *webhookNotifier) NotifyStarRepository(doer *models.User, repo *models.Repository, star bool) {
+	action := api.HookStarCreated
+	if !star {
+		action = api.HookStarDeleted
+	}
+	mode, _ := models.AccessLevel(doer, repo)
+	if err := webhook_services.PrepareWebhooks(repo, models.HookEventStar, &api.StarPayload{
+		Action:     action,
+		Repository: convert.ToRepo(repo, mode),
+		Sender:     convert.ToUser(doer, nil),
+	}); err != nil {
+		log.Error("PrepareWebhooks [repo_id: %d]: %v", repo.ID, err)
+	}
+}
+
+func (m *webhookNotifier) NotifyTransferRepository(doer *models.User, repo *models.Repository, oldOwnerName string) {
+	if err := webhook_services.PrepareWebhooks(repo, models.HookEventRepository, &api.RepositoryPayload{
+		Action:        api.HookRepoTransferred,
+		Repository:    convert.ToRepo(repo, models.AccessModeOwner),
+		Organization:  convert.ToUser(repo.MustOwner(), nil),
+		Sender:        convert.ToUser(doer, nil),
+		PreviousOwner: oldOwnerName,
+	}); err != nil {
+		log.Error("PrepareWebhooks [repo_id: %d]: %v", repo.ID, err)
+	}
+}
+
+func sendUserHook(doer, u *models.User, action api.HookUserAction) {
+	if doer == nil {
+		// users synchronized from an authentication source have no doer
+		doer = u
+	}
+	if err := webhook_services.PrepareSystemWebhooks(models.HookEventUser, &api.UserPayload{
+		Action: action,
+		User:   convert.ToUser(u, doer),
+		Sender: convert.ToUser(doer, nil),
+	}); err != nil {
+		log.Error("PrepareSystemWebhooks [user_id: %d]: %v", u.ID, err)
+	}
+}
+
+func (m *webhookNotifier) NotifyCreateUser(doer, u *models.User) {
+	sendUserHook(doer, u, api.HookUserCreated)
+}
+
+func (m *webhookNotifier) NotifyDeleteUser(doer, u *models.User) {
+	sendUserHook(doer, u, api.HookUserDeleted)
+}
+
+func sendOrganizationHook(doer, org *models.User, action api.HookOrganizationAction) {
+	if err := webhook_services.PrepareSystemWebhooks(models.HookEventOrganization, &api.OrganizationPayload{
+		Action:       action,
+		Organization: convert.ToOrganization(org),
+		Sender:       convert.ToUser(doer, nil),
+	}); err != nil {
+		log.Error("PrepareSystemWebhooks [org_id: %d]: %v", org.ID, err)
+	}
+}
+
+func (m *webhookNotifier) NotifyCreateOrganization(doer, org *models.User) {
+	sendOrganizationHook(doer, org, api.HookOrganizationCreated)
+}
+
+func (m *webhookNotifier) NotifyDeleteOrganization(doer, org *models.User) {
+	sendOrganizationHook(doer, org, api.HookOrganizationDeleted)
+}
+
+func (m *webhookNotifier) NotifyLoginFailed(loginName, remoteAddr, reason string) {
+	if err := webhook_services.PrepareSystemWebhooks(models.HookEventLoginFailed, &api.LoginFailedPayload{
+		LoginName:     loginName,
+		RemoteAddress: remoteAddr,
+		Reason:        reason,
+	}); err != nil {
+		log.Error("PrepareSystemWebhooks [login_failed]: %v", err)
+	}
+}
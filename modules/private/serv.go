@@ -53,6 +53,8 @@ type ServCommandResults struct {
 	OwnerName   string
 	RepoName    string
 	RepoID      int64
+	// RepoPath is the absolute path of the repository in its shard
+	RepoPath string
 }
 
 // ErrServCommand is an error returned from ServCommmand.
@@ -153,142 +153,163 @@ func ListUnadoptedRepositories(query string, opts *db.ListOptions) ([]string, in
 
 	count := 0
 
-	// We're going to iterate by pagesize.
-	root := filepath.Join(setting.RepoRootPath)
-	if err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+	for _, shard := range models.RepoShardNames() {
+		root, err := models.RepoShardRootPath(shard)
 		if err != nil {
-			return err
+			return nil, 0, err
 		}
-		if !info.IsDir() || path == root {
-			return nil
+		if exist, err := util.IsExist(root); err != nil {
+			return nil, 0, err
+		} else if !exist {
+			continue
 		}
 
-		if !strings.ContainsRune(path[len(root)+1:], filepath.Separator) {
-			// Got a new user
+		// We're going to iterate by pagesize.
+		if err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() || path == root {
+				return nil
+			}
 
-			// Clean up old repoNamesToCheck
-			if len(repoNamesToCheck) > 0 {
-				repos, _, err := models.GetUserRepositories(&models.SearchRepoOptions{
-					Actor:   ctxUser,
-					Private: true,
-					ListOptions: db.ListOptions{
-						Page:     1,
-						PageSize: opts.PageSize,
-					}, LowerNames: repoNamesToCheck})
-				if err != nil {
-					return err
-				}
-				for _, name := range repoNamesToCheck {
-					found := false
-				repoLoopCatchup:
-					for i, repo := range repos {
-						if repo.LowerName == name {
-							found = true
-							repos = append(repos[:i], repos[i+1:]...)
-							break repoLoopCatchup
-						}
+			if !strings.ContainsRune(path[len(root)+1:], filepath.Separator) {
+				// Got a new user
+
+				// Clean up old repoNamesToCheck
+				if len(repoNamesToCheck) > 0 {
+					repos, _, err := models.GetUserRepositories(&models.SearchRepoOptions{
+						Actor:   ctxUser,
+						Private: true,
+						ListOptions: db.ListOptions{
+							Page:     1,
+							PageSize: opts.PageSize,
+						}, LowerNames: repoNamesToCheck, IncludeDeleted: true})
+					if err != nil {
+						return err
 					}
-					if !found {
-						if count >= start && count < end {
-							repoNames = append(repoNames, fmt.Sprintf("%s/%s", ctxUser.Name, name))
+					for _, name := range repoNamesToCheck {
+						found := false
+					repoLoopCatchup:
+						for i, repo := range repos {
+							if repo.LowerName == name {
+								found = true
+								repos = append(repos[:i], repos[i+1:]...)
+								break repoLoopCatchup
+							}
+						}
+						if !found {
+							if count >= start && count < end {
+								repoNames = append(repoNames, fmt.Sprintf("%s/%s", ctxUser.Name, name))
+							}
+							count++
 						}
-						count++
 					}
+					repoNamesToCheck = repoNamesToCheck[:0]
 				}
-				repoNamesToCheck = repoNamesToCheck[:0]
-			}
-
-			if !globUser.Match(info.Name()) {
-				return filepath.SkipDir
-			}
 
-			ctxUser, err = models.GetUserByName(info.Name())
-			if err != nil {
-				if models.IsErrUserNotExist(err) {
-					log.Debug("Missing user: %s", info.Name())
+				if !globUser.Match(info.Name()) {
 					return filepath.SkipDir
 				}
-				return err
-			}
-			return nil
-		}
-
-		name := info.Name()
 
-		if !strings.HasSuffix(name, ".git") {
-			return filepath.SkipDir
-		}
-		name = name[:len(name)-4]
-		if models.IsUsableRepoName(name) != nil || strings.ToLower(name) != name || !globRepo.Match(name) {
-			return filepath.SkipDir
-		}
-		if count < end {
-			repoNamesToCheck = append(repoNamesToCheck, name)
-			if len(repoNamesToCheck) >= opts.PageSize {
-				repos, _, err := models.GetUserRepositories(&models.SearchRepoOptions{
-					Actor:   ctxUser,
-					Private: true,
-					ListOptions: db.ListOptions{
-						Page:     1,
-						PageSize: opts.PageSize,
-					}, LowerNames: repoNamesToCheck})
+				ctxUser, err = models.GetUserByName(info.Name())
 				if err != nil {
+					if models.IsErrUserNotExist(err) {
+						log.Debug("Missing user: %s", info.Name())
+						return filepath.SkipDir
+					}
 					return err
 				}
-				for _, name := range repoNamesToCheck {
-					found := false
-				repoLoop:
-					for i, repo := range repos {
-						if repo.LowerName == name {
-							found = true
-							repos = append(repos[:i], repos[i+1:]...)
-							break repoLoop
-						}
+
+				// Repositories left behind in a shard the owner has been moved away from
+				// are not adoptable
+				if ownerShard, err := models.GetOwnerRepoShard(ctxUser.Name); err != nil {
+					return err
+				} else if ownerShard != shard {
+					log.Debug("Skipping %s in repository shard %s: owner is in shard %s", info.Name(), shard, ownerShard)
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			name := info.Name()
+
+			if !strings.HasSuffix(name, ".git") {
+				return filepath.SkipDir
+			}
+			name = name[:len(name)-4]
+			if models.IsUsableRepoName(name) != nil || strings.ToLower(name) != name || !globRepo.Match(name) {
+				return filepath.SkipDir
+			}
+			if count < end {
+				repoNamesToCheck = append(repoNamesToCheck, name)
+				if len(repoNamesToCheck) >= opts.PageSize {
+					repos, _, err := models.GetUserRepositories(&models.SearchRepoOptions{
+						Actor:   ctxUser,
+						Private: true,
+						ListOptions: db.ListOptions{
+							Page:     1,
+							PageSize: opts.PageSize,
+						}, LowerNames: repoNamesToCheck, IncludeDeleted: true})
+					if err != nil {
+						return err
 					}
-					if !found {
-						if count >= start && count < end {
-							repoNames = append(repoNames, fmt.Sprintf("%s/%s", ctxUser.Name, name))
+					for _, name := range repoNamesToCheck {
+						found := false
+					repoLoop:
+						for i, repo := range repos {
+							if repo.LowerName == name {
+								found = true
+								repos = append(repos[:i], repos[i+1:]...)
+								break repoLoop
+							}
+						}
+						if !found {
+							if count >= start && count < end {
+								repoNames = append(repoNames, fmt.Sprintf("%s/%s", ctxUser.Name, name))
+							}
+							count++
 						}
-						count++
 					}
+					repoNamesToCheck = repoNamesToCheck[:0]
 				}
-				repoNamesToCheck = repoNamesToCheck[:0]
+				return filepath.SkipDir
 			}
+			count++
 			return filepath.SkipDir
-		}
-		count++
-		return filepath.SkipDir
-	}); err != nil {
-		return nil, 0, err
-	}
-
-	if len(repoNamesToCheck) > 0 {
-		repos, _, err := models.GetUserRepositories(&models.SearchRepoOptions{
-			Actor:   ctxUser,
-			Private: true,
-			ListOptions: db.ListOptions{
-				Page:     1,
-				PageSize: opts.PageSize,
-			}, LowerNames: repoNamesToCheck})
-		if err != nil {
+		}); err != nil {
 			return nil, 0, err
 		}
-		for _, name := range repoNamesToCheck {
-			found := false
-		repoLoop:
-			for i, repo := range repos {
-				if repo.LowerName == name {
-					found = true
-					repos = append(repos[:i], repos[i+1:]...)
-					break repoLoop
-				}
+
+		if len(repoNamesToCheck) > 0 {
+			repos, _, err := models.GetUserRepositories(&models.SearchRepoOptions{
+				Actor:   ctxUser,
+				Private: true,
+				ListOptions: db.ListOptions{
+					Page:     1,
+					PageSize: opts.PageSize,
+				}, LowerNames: repoNamesToCheck, IncludeDeleted: true})
+			if err != nil {
+				return nil, 0, err
 			}
-			if !found {
-				if count >= start && count < end {
-					repoNames = append(repoNames, fmt.Sprintf("%s/%s", ctxUser.Name, name))
+			for _, name := range repoNamesToCheck {
+				found := false
+			repoLoop:
+				for i, repo := range repos {
+					if repo.LowerName == name {
+						found = true
+						repos = append(repos[:i], repos[i+1:]...)
+						break repoLoop
+					}
+				}
+				if !found {
+					if count >= start && count < end {
+						repoNames = append(repoNames, fmt.Sprintf("%s/%s", ctxUser.Name, name))
+					}
+					count++
 				}
-				count++
 			}
+			repoNamesToCheck = repoNamesToCheck[:0]
 		}
 	}
 	return repoNames, count, nil
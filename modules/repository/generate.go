@@ -7,9 +7,11 @@ package repository
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
@@ -18,6 +20,7 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/util"
 
+	"github.com/gobwas/glob"
 	"github.com/huandu/xstrings"
 )
 
@@ -44,7 +47,7 @@ var defaultTransformers = []transformer{
 	{Name: "TITLE", Transform: strings.Title},
 }
 
-func generateExpansion(src string, templateRepo, generateRepo *models.Repository) string {
+func generateExpansions(templateRepo, generateRepo *models.Repository, variables map[string]string) map[string]string {
 	expansions := []expansion{
 		{Name: "REPO_NAME", Value: generateRepo.Name, Transformers: defaultTransformers},
 		{Name: "TEMPLATE_NAME", Value: templateRepo.Name, Transformers: defaultTransformers},
@@ -59,6 +62,9 @@ func generateExpansion(src string, templateRepo, generateRepo *models.Repository
 		{Name: "REPO_SSH_URL", Value: generateRepo.CloneLink().SSH, Transformers: nil},
 		{Name: "TEMPLATE_SSH_URL", Value: templateRepo.CloneLink().SSH, Transformers: nil},
 	}
+	for name, value := range variables {
+		expansions = append(expansions, expansion{Name: name, Value: value, Transformers: defaultTransformers})
+	}
 
 	var expansionMap = make(map[string]string)
 	for _, e := range expansions {
@@ -67,7 +73,10 @@ func generateExpansion(src string, templateRepo, generateRepo *models.Repository
 			expansionMap[fmt.Sprintf("%s_%s", e.Name, tr.Name)] = tr.Transform(e.Value)
 		}
 	}
+	return expansionMap
+}
 
+func generateExpansion(src string, expansionMap map[string]string) string {
 	return os.Expand(src, func(key string) string {
 		if expansion, ok := expansionMap[key]; ok {
 			return expansion
@@ -76,6 +85,25 @@ func generateExpansion(src string, templateRepo, generateRepo *models.Repository
 	})
 }
 
+var namePlaceholderPattern = regexp.MustCompile(`\$\{([A-Za-z0-9_]+)\}`)
+
+// generateNameExpansion expands the ${NAME} placeholders of a file or directory name.
+// Unknown placeholders are kept and separators are not allowed in the result.
+func generateNameExpansion(name string, expansionMap map[string]string) (string, error) {
+	expanded := namePlaceholderPattern.ReplaceAllStringFunc(name, func(placeholder string) string {
+		if expansion, ok := expansionMap[placeholder[2:len(placeholder)-1]]; ok {
+			return expansion
+		}
+		return placeholder
+	})
+
+	if expanded == "" || expanded == "." || expanded == ".." || strings.EqualFold(expanded, ".git") ||
+		strings.ContainsAny(expanded, "/\\") {
+		return "", fmt.Errorf("%q is expanded to the invalid name %q", name, expanded)
+	}
+	return expanded, nil
+}
+
 func checkGiteaTemplate(tmpDir string) (*models.GiteaTemplate, error) {
 	gtPath := filepath.Join(tmpDir, ".gitea", "template")
 	if _, err := os.Stat(gtPath); os.IsNotExist(err) {
@@ -97,95 +125,251 @@ func checkGiteaTemplate(tmpDir string) (*models.GiteaTemplate, error) {
 	return gt, nil
 }
 
-func generateRepoCommit(repo, templateRepo, generateRepo *models.Repository, tmpDir string) error {
-	commitTimeStr := time.Now().Format(time.RFC3339)
-	authorSig := repo.Owner.NewGitSig()
+// templateConfigPaths are the paths a template repository may declare its variables in
+var templateConfigPaths = []string{".gitea/template.yaml", ".gitea/template.yml"}
+
+func checkTemplateConfig(tmpDir string) (*models.TemplateConfig, error) {
+	for _, configPath := range templateConfigPaths {
+		fullPath := filepath.Join(tmpDir, filepath.FromSlash(configPath))
+		content, err := os.ReadFile(fullPath)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
 
-	// Because this may call hooks we should pass in the environment
-	env := append(os.Environ(),
-		"GIT_AUTHOR_NAME="+authorSig.Name,
-		"GIT_AUTHOR_EMAIL="+authorSig.Email,
-		"GIT_AUTHOR_DATE="+commitTimeStr,
-		"GIT_COMMITTER_NAME="+authorSig.Name,
-		"GIT_COMMITTER_EMAIL="+authorSig.Email,
-		"GIT_COMMITTER_DATE="+commitTimeStr,
-	)
+		config, err := models.ParseTemplateConfig(content)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", configPath, err)
+		}
+		config.Path = fullPath
+		return config, nil
+	}
+	return nil, nil
+}
 
-	// Clone to temporary path and do the init commit.
+// GetTemplateConfig returns the variables and file rules declared on the default
+// branch of a template repository, or nil if it declares none
+func GetTemplateConfig(templateRepo *models.Repository) (*models.TemplateConfig, error) {
+	if templateRepo.IsEmpty {
+		return nil, nil
+	}
+
+	gitRepo, err := git.OpenRepository(templateRepo.RepoPath())
+	if err != nil {
+		return nil, err
+	}
+	defer gitRepo.Close()
+
+	commit, err := gitRepo.GetBranchCommit(templateRepo.DefaultBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, configPath := range templateConfigPaths {
+		entry, err := commit.GetTreeEntryByPath(configPath)
+		if git.IsErrNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		reader, err := entry.Blob().DataAsync()
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		config, err := models.ParseTemplateConfig(content)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", configPath, err)
+		}
+		return config, nil
+	}
+	return nil, nil
+}
+
+// GenerateTemplateContent checks out the template repository at commitID, or at the head
+// of its default branch if commitID is empty, into tmpDir without its git directory and
+// applies the template: files excluded by the template config are removed, the files
+// matching the .gitea/template globs are expanded and then the names are expanded.
+// It returns the ID of the template commit that was checked out.
+func GenerateTemplateContent(templateRepo, generateRepo *models.Repository, commitID string, variables map[string]string, tmpDir string) (string, error) {
 	templateRepoPath := templateRepo.RepoPath()
-	if err := git.Clone(templateRepoPath, tmpDir, git.CloneRepoOptions{
-		Depth:  1,
-		Branch: templateRepo.DefaultBranch,
-	}); err != nil {
-		return fmt.Errorf("git clone: %v", err)
+	if commitID == "" {
+		if err := git.Clone(templateRepoPath, tmpDir, git.CloneRepoOptions{
+			Depth:  1,
+			Branch: templateRepo.DefaultBranch,
+		}); err != nil {
+			return "", fmt.Errorf("git clone: %v", err)
+		}
+	} else {
+		if err := git.Clone(templateRepoPath, tmpDir, git.CloneRepoOptions{
+			NoCheckout: true,
+		}); err != nil {
+			return "", fmt.Errorf("git clone: %v", err)
+		}
+		if _, err := git.NewCommand("checkout", "--quiet", "--detach", commitID).RunInDir(tmpDir); err != nil {
+			return "", fmt.Errorf("git checkout %s: %v", commitID, err)
+		}
+	}
+
+	stdout, err := git.NewCommand("rev-parse", "HEAD").RunInDir(tmpDir)
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse: %v", err)
 	}
+	commitID = strings.TrimSpace(stdout)
 
 	if err := util.RemoveAll(path.Join(tmpDir, ".git")); err != nil {
-		return fmt.Errorf("remove git dir: %v", err)
+		return "", fmt.Errorf("remove git dir: %v", err)
 	}
 
 	// Variable expansion
 	gt, err := checkGiteaTemplate(tmpDir)
 	if err != nil {
-		return fmt.Errorf("checkGiteaTemplate: %v", err)
+		return "", fmt.Errorf("checkGiteaTemplate: %v", err)
+	}
+
+	config, err := checkTemplateConfig(tmpDir)
+	if err != nil {
+		return "", fmt.Errorf("checkTemplateConfig: %v", err)
+	}
+
+	if gt == nil && config == nil {
+		return commitID, nil
 	}
 
 	if gt != nil {
 		if err := util.Remove(gt.Path); err != nil {
-			return fmt.Errorf("remove .giteatemplate: %v", err)
+			return "", fmt.Errorf("remove .giteatemplate: %v", err)
+		}
+	}
+	if config != nil {
+		if err := util.Remove(config.Path); err != nil {
+			return "", fmt.Errorf("remove template config: %v", err)
+		}
+	}
+
+	expansionMap := generateExpansions(templateRepo, generateRepo, variables)
+	var globs []glob.Glob
+	if gt != nil {
+		globs = gt.Globs()
+	}
+
+	// Names are expanded after the walk, deepest first, so that the walked paths stay valid
+	var toRename []string
+	tmpDirSlash := strings.TrimSuffix(filepath.ToSlash(tmpDir), "/") + "/"
+	if err := filepath.Walk(tmpDirSlash, func(path string, info os.FileInfo, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
 		}
 
-		// Avoid walking tree if there are no globs
-		if len(gt.Globs()) > 0 {
-			tmpDirSlash := strings.TrimSuffix(filepath.ToSlash(tmpDir), "/") + "/"
-			if err := filepath.Walk(tmpDirSlash, func(path string, info os.FileInfo, walkErr error) error {
-				if walkErr != nil {
-					return walkErr
-				}
+		base := strings.TrimPrefix(filepath.ToSlash(path), tmpDirSlash)
+		if base == "" {
+			return nil
+		}
 
-				if info.IsDir() {
-					return nil
+		if !config.IsIncluded(base, variables) {
+			if err := util.RemoveAll(path); err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if namePlaceholderPattern.MatchString(info.Name()) {
+			toRename = append(toRename, path)
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		for _, g := range globs {
+			if g.Match(base) {
+				content, err := os.ReadFile(path)
+				if err != nil {
+					return err
 				}
 
-				base := strings.TrimPrefix(filepath.ToSlash(path), tmpDirSlash)
-				for _, g := range gt.Globs() {
-					if g.Match(base) {
-						content, err := os.ReadFile(path)
-						if err != nil {
-							return err
-						}
-
-						if err := os.WriteFile(path,
-							[]byte(generateExpansion(string(content), templateRepo, generateRepo)),
-							0644); err != nil {
-							return err
-						}
-						break
-					}
+				if err := os.WriteFile(path,
+					[]byte(generateExpansion(string(content), expansionMap)),
+					0644); err != nil {
+					return err
 				}
-				return nil
-			}); err != nil {
-				return err
+				break
 			}
 		}
+		return nil
+	}); err != nil {
+		return "", err
+	}
+
+	for i := len(toRename) - 1; i >= 0; i-- {
+		dir, name := filepath.Split(toRename[i])
+		expanded, err := generateNameExpansion(name, expansionMap)
+		if err != nil {
+			return "", err
+		}
+		if expanded == name {
+			continue
+		}
+		target := filepath.Join(dir, expanded)
+		if exist, err := util.IsExist(target); err != nil {
+			return "", err
+		} else if exist {
+			return "", fmt.Errorf("%q is expanded to %q which already exists", name, expanded)
+		}
+		if err := os.Rename(toRename[i], target); err != nil {
+			return "", err
+		}
+	}
+
+	return commitID, nil
+}
+
+func generateRepoCommit(repo, templateRepo, generateRepo *models.Repository, variables map[string]string, tmpDir string) (string, error) {
+	commitTimeStr := time.Now().Format(time.RFC3339)
+	authorSig := repo.Owner.NewGitSig()
+
+	// Because this may call hooks we should pass in the environment
+	env := append(os.Environ(),
+		"GIT_AUTHOR_NAME="+authorSig.Name,
+		"GIT_AUTHOR_EMAIL="+authorSig.Email,
+		"GIT_AUTHOR_DATE="+commitTimeStr,
+		"GIT_COMMITTER_NAME="+authorSig.Name,
+		"GIT_COMMITTER_EMAIL="+authorSig.Email,
+		"GIT_COMMITTER_DATE="+commitTimeStr,
+	)
+
+	// Clone to temporary path and do the init commit.
+	templateCommitID, err := GenerateTemplateContent(templateRepo, generateRepo, "", variables, tmpDir)
+	if err != nil {
+		return "", err
 	}
 
 	if err := git.InitRepository(tmpDir, false); err != nil {
-		return err
+		return "", err
 	}
 
 	repoPath := repo.RepoPath()
 	if stdout, err := git.NewCommand("remote", "add", "origin", repoPath).
-		SetDescription(fmt.Sprintf("generateRepoCommit (git remote add): %s to %s", templateRepoPath, tmpDir)).
+		SetDescription(fmt.Sprintf("generateRepoCommit (git remote add): %s to %s", templateRepo.RepoPath(), tmpDir)).
 		RunInDirWithEnv(tmpDir, env); err != nil {
 		log.Error("Unable to add %v as remote origin to temporary repo to %s: stdout %s\nError: %v", repo, tmpDir, stdout, err)
-		return fmt.Errorf("git remote add: %v", err)
+		return "", fmt.Errorf("git remote add: %v", err)
 	}
 
-	return initRepoCommit(tmpDir, repo, repo.Owner, templateRepo.DefaultBranch)
+	return templateCommitID, initRepoCommit(tmpDir, repo, repo.Owner, templateRepo.DefaultBranch)
 }
 
-func generateGitContent(ctx context.Context, repo, templateRepo, generateRepo *models.Repository) (err error) {
+func generateGitContent(ctx context.Context, repo, templateRepo, generateRepo *models.Repository, variables map[string]string) (err error) {
 	tmpDir, err := os.MkdirTemp(os.TempDir(), "gitea-"+repo.Name)
 	if err != nil {
 		return fmt.Errorf("Failed to create temp dir for repository %s: %v", repo.RepoPath(), err)
@@ -197,10 +381,15 @@ func generateGitContent(ctx context.Context, repo, templateRepo, generateRepo *m
 		}
 	}()
 
-	if err = generateRepoCommit(repo, templateRepo, generateRepo, tmpDir); err != nil {
+	templateCommitID, err := generateRepoCommit(repo, templateRepo, generateRepo, variables, tmpDir)
+	if err != nil {
 		return fmt.Errorf("generateRepoCommit: %v", err)
 	}
 
+	if err = models.InsertRepoGeneration(ctx, repo.ID, templateRepo.ID, templateCommitID, variables); err != nil {
+		return fmt.Errorf("insertRepoGeneration: %v", err)
+	}
+
 	// re-fetch repo
 	if repo, err = models.GetRepositoryByIDCtx(ctx, repo.ID); err != nil {
 		return fmt.Errorf("getRepositoryByID: %v", err)
@@ -222,9 +411,10 @@ func generateGitContent(ctx context.Context, repo, templateRepo, generateRepo *m
 	return nil
 }
 
-// GenerateGitContent generates git content from a template repository
-func GenerateGitContent(ctx context.Context, templateRepo, generateRepo *models.Repository) error {
-	if err := generateGitContent(ctx, generateRepo, templateRepo, generateRepo); err != nil {
+// GenerateGitContent generates git content from a template repository using the
+// resolved values of the variables declared by the template
+func GenerateGitContent(ctx context.Context, templateRepo, generateRepo *models.Repository, variables map[string]string) error {
+	if err := generateGitContent(ctx, generateRepo, templateRepo, generateRepo, variables); err != nil {
 		return err
 	}
 
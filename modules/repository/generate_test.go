@@ -0,0 +1,37 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateNameExpansion(t *testing.T) {
+	expansions := map[string]string{
+		"SERVICE":       "api",
+		"SERVICE_UPPER": "API",
+		"DOTS":          "..",
+		"SLASH":         "a/b",
+		"GIT":           ".GIT",
+	}
+
+	for name, expected := range map[string]string{
+		"${SERVICE}":            "api",
+		"cmd-${SERVICE_UPPER}":  "cmd-API",
+		"${SERVICE}_${UNKNOWN}": "api_${UNKNOWN}",
+		"$SERVICE.go":           "$SERVICE.go",
+	} {
+		expanded, err := generateNameExpansion(name, expansions)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, expanded)
+	}
+
+	for _, name := range []string{"${DOTS}", "${SLASH}", "${GIT}"} {
+		_, err := generateNameExpansion(name, expansions)
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/lfs"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// GarbageCollectLFSMetaObjectsOptions provides options for GarbageCollectLFSMetaObjects function
+type GarbageCollectLFSMetaObjectsOptions struct {
+	Logger    log.Logger
+	AutoFix   bool
+	OlderThan time.Duration
+}
+
+// GarbageCollectLFSMetaObjectsResult reports what GarbageCollectLFSMetaObjects found and removed
+type GarbageCollectLFSMetaObjectsResult struct {
+	NumRepos          int
+	NumChecked        int
+	NumUnreferenced   int
+	NumRemoved        int
+	NumContentDeleted int
+	SizeUnreferenced  int64
+}
+
+// GarbageCollectLFSMetaObjects removes the LFSMetaObjects which are no longer referenced by the reachable
+// history of their repository. Objects younger than OlderThan are kept as they may belong to a push in progress.
+// The content is only deleted from the LFS storage if no other repository references the same object.
+// If AutoFix is false nothing is changed and the unreferenced objects are only reported.
+func GarbageCollectLFSMetaObjects(ctx context.Context, opts GarbageCollectLFSMetaObjectsOptions) (*GarbageCollectLFSMetaObjectsResult, error) {
+	log.Trace("Doing: GarbageCollectLFSMetaObjects")
+
+	result := &GarbageCollectLFSMetaObjectsResult{}
+	olderThan := timeutil.TimeStamp(time.Now().Add(-opts.OlderThan).Unix())
+
+	if err := db.Iterate(
+		db.DefaultContext,
+		new(models.Repository),
+		builder.In("id", builder.Select("repository_id").From("lfs_meta_object")),
+		func(idx int, bean interface{}) error {
+			repo := bean.(*models.Repository)
+			select {
+			case <-ctx.Done():
+				return models.ErrCancelledf("before LFS garbage collection of %s", repo.FullName())
+			default:
+			}
+			result.NumRepos++
+			if err := garbageCollectLFSMetaObjectsForRepo(ctx, repo, olderThan, opts, result); err != nil {
+				// never delete anything of a repository whose history could not be scanned completely
+				log.Warn("Failed to garbage collect LFS objects of repository (%s): %v", repo.FullName(), err)
+				if err = models.CreateRepositoryNotice("Failed to garbage collect LFS objects of repository (%s): %v", repo.FullName(), err); err != nil {
+					log.Error("CreateRepositoryNotice: %v", err)
+				}
+			}
+			return nil
+		},
+	); err != nil {
+		log.Trace("Error: GarbageCollectLFSMetaObjects: %v", err)
+		return result, err
+	}
+
+	log.Trace("Finished: GarbageCollectLFSMetaObjects")
+	return result, nil
+}
+
+func garbageCollectLFSMetaObjectsForRepo(ctx context.Context, repo *models.Repository, olderThan timeutil.TimeStamp, opts GarbageCollectLFSMetaObjectsOptions, result *GarbageCollectLFSMetaObjectsResult) error {
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		return fmt.Errorf("OpenRepository: %v", err)
+	}
+	defer gitRepo.Close()
+
+	referenced, err := referencedLFSObjects(ctx, gitRepo)
+	if err != nil {
+		return err
+	}
+
+	contentStore := lfs.NewContentStore()
+	return models.IterateLFSMetaObjectsForRepo(repo.ID, olderThan, func(mo *models.LFSMetaObject) error {
+		result.NumChecked++
+		if _, ok := referenced[mo.Oid]; ok {
+			return nil
+		}
+		result.NumUnreferenced++
+		result.SizeUnreferenced += mo.Size
+
+		if !opts.AutoFix {
+			if opts.Logger != nil {
+				opts.Logger.Info("LFS object %s (%d bytes) of repository %s is unreferenced", mo.Oid, mo.Size, repo.FullName())
+			}
+			return nil
+		}
+
+		count, err := repo.RemoveLFSMetaObjectByOid(mo.Oid)
+		if err != nil {
+			return fmt.Errorf("RemoveLFSMetaObjectByOid[%s]: %v", mo.Oid, err)
+		}
+		result.NumRemoved++
+		if count > 0 {
+			return nil
+		}
+		if err := contentStore.Delete(mo.RelativePath()); err != nil {
+			log.Error("Unable to delete LFS object %s from the storage: %v", mo.Oid, err)
+			return nil
+		}
+		result.NumContentDeleted++
+		return nil
+	})
+}
+
+// referencedLFSObjects returns the oids of all LFS pointers in the reachable history of the repository
+func referencedLFSObjects(ctx context.Context, gitRepo *git.Repository) (map[string]struct{}, error) {
+	pointerChan := make(chan lfs.PointerBlob)
+	errChan := make(chan error, 1)
+	go lfs.SearchReachablePointerBlobs(ctx, gitRepo, pointerChan, errChan)
+
+	referenced := make(map[string]struct{})
+	for pointerBlob := range pointerChan {
+		referenced[pointerBlob.Oid] = struct{}{}
+	}
+
+	if err, has := <-errChan; has {
+		return nil, err
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	return referenced, nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/lfs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func storeLFSObject(t *testing.T, repo *models.Repository, content string, created time.Time) lfs.Pointer {
+	p, err := lfs.GeneratePointer(strings.NewReader(content))
+	assert.NoError(t, err)
+	assert.NoError(t, lfs.NewContentStore().Put(p, strings.NewReader(content)))
+
+	mo, err := models.NewLFSMetaObject(&models.LFSMetaObject{Pointer: p, RepositoryID: repo.ID})
+	assert.NoError(t, err)
+	_, err = db.GetEngine(db.DefaultContext).Exec("UPDATE lfs_meta_object SET created_unix = ? WHERE id = ?", created.Unix(), mo.ID)
+	assert.NoError(t, err)
+	return p
+}
+
+// commitLFSPointer adds a commit with the pointer file to a new branch of the bare repository
+func commitLFSPointer(t *testing.T, repoPath string, p lfs.Pointer) {
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	err := git.NewCommand("hash-object", "-w", "--stdin").
+		RunInDirFullPipeline(repoPath, stdout, stderr, strings.NewReader(p.StringContent()))
+	assert.NoError(t, err, stderr.String())
+	blobID := strings.TrimSpace(stdout.String())
+
+	stdout.Reset()
+	err = git.NewCommand("mktree").
+		RunInDirFullPipeline(repoPath, stdout, stderr, strings.NewReader("100644 blob "+blobID+"\tobject.bin\n"))
+	assert.NoError(t, err, stderr.String())
+	treeID := strings.TrimSpace(stdout.String())
+
+	env := append(os.Environ(),
+		"GIT_AUTHOR_NAME=Gitea", "GIT_AUTHOR_EMAIL=gitea@example.com",
+		"GIT_COMMITTER_NAME=Gitea", "GIT_COMMITTER_EMAIL=gitea@example.com")
+	commitID, err := git.NewCommand("commit-tree", treeID, "-m", "add LFS object").RunInDirWithEnv(repoPath, env)
+	assert.NoError(t, err)
+
+	_, err = git.NewCommand("update-ref", "refs/heads/lfs", strings.TrimSpace(commitID)).RunInDir(repoPath)
+	assert.NoError(t, err)
+}
+
+func lfsContentExists(t *testing.T, p lfs.Pointer) bool {
+	exist, err := lfs.NewContentStore().Exists(p)
+	assert.NoError(t, err)
+	return exist
+}
+
+func TestGarbageCollectLFSMetaObjects(t *testing.T) {
+	assert.NoError(t, db.PrepareTestDatabase())
+
+	repo := db.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	otherRepo := db.AssertExistsAndLoadBean(t, &models.Repository{ID: 2}).(*models.Repository)
+	old := time.Now().Add(-30 * 24 * time.Hour)
+
+	referenced := storeLFSObject(t, repo, "referenced", old)
+	unreferenced := storeLFSObject(t, repo, "unreferenced", old)
+	recent := storeLFSObject(t, repo, "recently uploaded", time.Now())
+	shared := storeLFSObject(t, repo, "shared", old)
+	storeLFSObject(t, otherRepo, "shared", time.Now())
+	commitLFSPointer(t, repo.RepoPath(), referenced)
+
+	opts := GarbageCollectLFSMetaObjectsOptions{OlderThan: 7 * 24 * time.Hour}
+
+	// without AutoFix the unreferenced objects are only reported
+	result, err := GarbageCollectLFSMetaObjects(context.Background(), opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.NumRepos)
+	assert.Equal(t, 3, result.NumChecked)
+	assert.Equal(t, 2, result.NumUnreferenced)
+	assert.EqualValues(t, unreferenced.Size+shared.Size, result.SizeUnreferenced)
+	assert.Zero(t, result.NumRemoved)
+	db.AssertExistsAndLoadBean(t, &models.LFSMetaObject{Pointer: lfs.Pointer{Oid: unreferenced.Oid}, RepositoryID: repo.ID})
+	assert.True(t, lfsContentExists(t, unreferenced))
+
+	opts.AutoFix = true
+	result, err = GarbageCollectLFSMetaObjects(context.Background(), opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.NumRemoved)
+	assert.Equal(t, 1, result.NumContentDeleted)
+
+	// objects referenced by the history or uploaded within the grace period are kept
+	db.AssertExistsAndLoadBean(t, &models.LFSMetaObject{Pointer: lfs.Pointer{Oid: referenced.Oid}, RepositoryID: repo.ID})
+	db.AssertExistsAndLoadBean(t, &models.LFSMetaObject{Pointer: lfs.Pointer{Oid: recent.Oid}, RepositoryID: repo.ID})
+	assert.True(t, lfsContentExists(t, referenced))
+	assert.True(t, lfsContentExists(t, recent))
+
+	// unreferenced objects are removed, the content only if no other repository has the object
+	db.AssertNotExistsBean(t, &models.LFSMetaObject{Pointer: lfs.Pointer{Oid: unreferenced.Oid}, RepositoryID: repo.ID})
+	db.AssertNotExistsBean(t, &models.LFSMetaObject{Pointer: lfs.Pointer{Oid: shared.Oid}, RepositoryID: repo.ID})
+	db.AssertExistsAndLoadBean(t, &models.LFSMetaObject{Pointer: lfs.Pointer{Oid: shared.Oid}, RepositoryID: otherRepo.ID})
+	assert.False(t, lfsContentExists(t, unreferenced))
+	assert.True(t, lfsContentExists(t, shared))
+}
@@ -163,6 +163,10 @@ func MigrateRepositoryGitData(ctx context.Context, u *models.User, repo *models.
 		if opts.LFS {
 			mirrorModel.LFSEndpoint = opts.LFSEndpoint
 		}
+		if opts.MirrorMetadata {
+			mirrorModel.SyncMetadata = true
+			mirrorModel.MetadataSyncedUnix = timeutil.TimeStampNow()
+		}
 
 		if opts.MirrorInterval != "" {
 			parsedInterval, err := time.ParseDuration(opts.MirrorInterval)
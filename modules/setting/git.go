@@ -27,6 +27,7 @@ var (
 		PullRequestPushMessage    bool
 		LargeObjectThreshold      int64
 		DisableCoreProtectNTFS    bool
+		DisablePartialClone       bool
 		Timeout                   struct {
 			Default int
 			Migrate int
@@ -0,0 +1,74 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"net/mail"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+// IncomingEmailTokenPlaceholder is replaced by the reply token in IncomingEmail.ReplyToAddress
+const IncomingEmailTokenPlaceholder = "%{token}"
+
+// IncomingEmail settings
+var IncomingEmail = struct {
+	Enabled              bool
+	ReplyToAddress       string
+	Host                 string
+	Port                 int
+	UseTLS               bool `ini:"USE_TLS"`
+	SkipTLSVerify        bool `ini:"SKIP_TLS_VERIFY"`
+	Username             string
+	Password             string
+	Mailbox              string
+	Maildir              string
+	DeleteHandledMessage bool
+	MaximumMessageSize   uint32
+	PollInterval         time.Duration
+}{
+	Mailbox:              "INBOX",
+	Port:                 993,
+	UseTLS:               true,
+	DeleteHandledMessage: true,
+	MaximumMessageSize:   10485760,
+	PollInterval:         time.Minute,
+}
+
+func newIncomingEmail() {
+	if err := Cfg.Section("email.incoming").MapTo(&IncomingEmail); err != nil {
+		log.Fatal("Unable to map [email.incoming] section on to IncomingEmail. Error: %v", err)
+	}
+
+	if !IncomingEmail.Enabled {
+		return
+	}
+
+	if MailService == nil {
+		log.Warn("Incoming Email Service: Mail Service is not enabled, replies by email are disabled")
+		IncomingEmail.Enabled = false
+		return
+	}
+
+	if strings.Count(IncomingEmail.ReplyToAddress, IncomingEmailTokenPlaceholder) != 1 {
+		log.Fatal("[email.incoming].REPLY_TO_ADDRESS must contain %s exactly once", IncomingEmailTokenPlaceholder)
+	}
+	if _, err := mail.ParseAddress(strings.Replace(IncomingEmail.ReplyToAddress, IncomingEmailTokenPlaceholder, "token", 1)); err != nil {
+		log.Fatal("Invalid [email.incoming].REPLY_TO_ADDRESS (%s): %v", IncomingEmail.ReplyToAddress, err)
+	}
+
+	if IncomingEmail.Maildir == "" && IncomingEmail.Host == "" {
+		log.Fatal("[email.incoming] requires either HOST or MAILDIR")
+	}
+
+	if IncomingEmail.PollInterval < 10*time.Second {
+		log.Warn("[email.incoming].POLL_INTERVAL is too short, it is set to 10s")
+		IncomingEmail.PollInterval = 10 * time.Second
+	}
+
+	log.Info("Incoming Email Service Enabled")
+}
@@ -22,6 +22,7 @@ var LFS = struct {
 	HTTPAuthExpiry  time.Duration `ini:"LFS_HTTP_AUTH_EXPIRY"`
 	MaxFileSize     int64         `ini:"LFS_MAX_FILE_SIZE"`
 	LocksPagingNum  int           `ini:"LFS_LOCKS_PAGING_NUM"`
+	GCGracePeriod   time.Duration `ini:"LFS_GC_GRACE_PERIOD"`
 
 	Storage
 }{}
@@ -47,6 +48,7 @@ func newLFSService() {
 	}
 
 	LFS.HTTPAuthExpiry = sec.Key("LFS_HTTP_AUTH_EXPIRY").MustDuration(20 * time.Minute)
+	LFS.GCGracePeriod = sec.Key("LFS_GC_GRACE_PERIOD").MustDuration(7 * 24 * time.Hour)
 
 	if LFS.StartServer {
 		LFS.JWTSecretBytes = make([]byte, 32)
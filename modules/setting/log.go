@@ -189,6 +189,11 @@ func generateLogConfig(sec *ini.Section, name string, defaults defaultLogOptions
 		logConfig["reconnect"] = sec.Key("RECONNECT").MustBool()
 		logConfig["net"] = sec.Key("PROTOCOL").In("tcp", []string{"tcp", "unix", "udp"})
 		logConfig["addr"] = sec.Key("ADDR").MustString(":7020")
+	case "syslog":
+		logConfig["net"] = sec.Key("PROTOCOL").In("", []string{"", "tcp", "udp", "unix", "unixgram"})
+		logConfig["addr"] = sec.Key("ADDR").MustString("")
+		logConfig["tag"] = sec.Key("TAG").MustString("gitea")
+		logConfig["facility"] = sec.Key("FACILITY").MustString("local0")
 	case "smtp":
 		logConfig["username"] = sec.Key("USER").MustString("example@example.com")
 		logConfig["password"] = sec.Key("PASSWD").MustString("******")
@@ -265,6 +270,18 @@ func newAccessLogService() {
 	}
 }
 
+func newAuditLogService() {
+	EnableAuditLog = Cfg.Section("log").Key("ENABLE_AUDIT_LOG").MustBool(false)
+	Cfg.Section("log").Key("AUDIT").MustString("file")
+	if EnableAuditLog {
+		options := newDefaultLogOptions()
+		options.filename = filepath.Join(LogRootPath, "audit.log")
+		options.flags = "" // Audit events carry their own time
+		options.bufferLength = Cfg.Section("log").Key("BUFFER_LEN").MustInt64(10000)
+		generateNamedLogger("audit", options)
+	}
+}
+
 func newRouterLogService() {
 	Cfg.Section("log").Key("ROUTER").MustString("console")
 	// Allow [log]  DISABLE_ROUTER_LOG to override [server] DISABLE_ROUTER_LOG
@@ -349,6 +366,7 @@ func NewLogServices(disableConsole bool) {
 	newLogService()
 	newRouterLogService()
 	newAccessLogService()
+	newAuditLogService()
 	NewXORMLogService(disableConsole)
 }
 
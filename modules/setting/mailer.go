@@ -38,6 +38,12 @@ type Mailer struct {
 	SendmailPath    string
 	SendmailArgs    []string
 	SendmailTimeout time.Duration
+
+	// Signing and encryption of outgoing mails
+	SigningMethod                string
+	EncryptionEnabled            bool
+	SMIMECertFile, SMIMEKeyFile  string
+	PGPKeyFile, PGPKeyPassphrase string
 }
 
 var (
@@ -71,6 +77,13 @@ func newMailService() {
 
 		SendmailPath:    sec.Key("SENDMAIL_PATH").MustString("sendmail"),
 		SendmailTimeout: sec.Key("SENDMAIL_TIMEOUT").MustDuration(5 * time.Minute),
+
+		SigningMethod:     sec.Key("SIGNING_METHOD").In("", []string{"", "smime", "pgp"}),
+		EncryptionEnabled: sec.Key("ENABLE_ENCRYPTION").MustBool(),
+		SMIMECertFile:     sec.Key("SMIME_CERT_FILE").String(),
+		SMIMEKeyFile:      sec.Key("SMIME_KEY_FILE").String(),
+		PGPKeyFile:        sec.Key("PGP_KEY_FILE").String(),
+		PGPKeyPassphrase:  sec.Key("PGP_KEY_PASSPHRASE").String(),
 	}
 	MailService.From = sec.Key("FROM").MustString(MailService.User)
 
@@ -104,6 +117,17 @@ func newMailService() {
 		}
 	}
 
+	switch MailService.SigningMethod {
+	case "smime":
+		if MailService.SMIMECertFile == "" || MailService.SMIMEKeyFile == "" {
+			log.Fatal("mailer.SIGNING_METHOD is smime but SMIME_CERT_FILE or SMIME_KEY_FILE is not set")
+		}
+	case "pgp":
+		if MailService.PGPKeyFile == "" {
+			log.Fatal("mailer.SIGNING_METHOD is pgp but PGP_KEY_FILE is not set")
+		}
+	}
+
 	log.Info("Mail Service Enabled")
 }
 
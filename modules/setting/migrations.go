@@ -17,9 +17,11 @@ var (
 		BlockedDomains     []string
 		AllowLocalNetworks bool
 		SkipTLSVerify      bool
+		IssueImportMaxSize int64
 	}{
-		MaxAttempts:  3,
-		RetryBackoff: 3,
+		MaxAttempts:        3,
+		RetryBackoff:       3,
+		IssueImportMaxSize: 50,
 	}
 )
 
@@ -39,4 +41,5 @@ func newMigrationsService() {
 
 	Migrations.AllowLocalNetworks = sec.Key("ALLOW_LOCALNETWORKS").MustBool(false)
 	Migrations.SkipTLSVerify = sec.Key("SKIP_TLS_VERIFY").MustBool(false)
+	Migrations.IssueImportMaxSize = sec.Key("ISSUE_IMPORT_MAX_SIZE").MustInt64(Migrations.IssueImportMaxSize)
 }
@@ -8,6 +8,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"code.gitea.io/gitea/modules/log"
 )
@@ -19,6 +20,9 @@ const (
 	RepoCreatingPublic             = "public"
 )
 
+// DefaultRepoShard is the name of the repository shard rooted at RepoRootPath
+const DefaultRepoShard = "default"
+
 // Repository settings
 var (
 	Repository = struct {
@@ -44,6 +48,8 @@ var (
 		DefaultBranch                           string
 		AllowAdoptionOfUnadoptedRepositories    bool
 		AllowDeleteOfUnadoptedRepositories      bool
+		DefaultShard                            string
+		TrashPeriod                             time.Duration
 
 		// Repository editor settings
 		Editor struct {
@@ -249,6 +255,11 @@ var (
 	RepoRootPath string
 	ScriptType   = "bash"
 
+	// RepoShards maps the names of the additional repository shards, defined by
+	// [repository.shard.<name>] sections, to their root paths. RepoRootPath is the
+	// root of the DefaultRepoShard.
+	RepoShards = map[string]string{}
+
 	RepoArchive = struct {
 		Storage
 	}{}
@@ -275,6 +286,23 @@ func newRepository() {
 	}
 	ScriptType = sec.Key("SCRIPT_TYPE").MustString("bash")
 
+	RepoShards = map[string]string{}
+	for _, shardSec := range Cfg.Section("repository.shard").ChildSections() {
+		name := strings.ToLower(strings.TrimPrefix(shardSec.Name(), "repository.shard."))
+		if name == DefaultRepoShard {
+			log.Fatal("Repository shard %q is reserved for [repository] ROOT", name)
+		}
+		root := shardSec.Key("ROOT").String()
+		if root == "" {
+			log.Fatal("Repository shard %q has no ROOT", name)
+		}
+		forcePathSeparator(root)
+		if !filepath.IsAbs(root) {
+			root = filepath.Join(AppWorkPath, root)
+		}
+		RepoShards[name] = filepath.Clean(root)
+	}
+
 	if err = Cfg.Section("repository").MapTo(&Repository); err != nil {
 		log.Fatal("Failed to map Repository settings: %v", err)
 	} else if err = Cfg.Section("repository.editor").MapTo(&Repository.Editor); err != nil {
@@ -287,6 +315,13 @@ func newRepository() {
 		log.Fatal("Failed to map Repository.PullRequest settings: %v", err)
 	}
 
+	Repository.DefaultShard = strings.ToLower(strings.TrimSpace(Repository.DefaultShard))
+	if Repository.DefaultShard == "" {
+		Repository.DefaultShard = DefaultRepoShard
+	} else if _, ok := RepoShards[Repository.DefaultShard]; !ok && Repository.DefaultShard != DefaultRepoShard {
+		log.Fatal("Unknown repository DEFAULT_SHARD: %s", Repository.DefaultShard)
+	}
+
 	// Handle default trustmodel settings
 	Repository.Signing.DefaultTrustModel = strings.ToLower(strings.TrimSpace(Repository.Signing.DefaultTrustModel))
 	if Repository.Signing.DefaultTrustModel == "default" {
@@ -333,6 +333,7 @@ var (
 	DisableRouterLog   bool
 	RouterLogLevel     log.Level
 	EnableAccessLog    bool
+	EnableAuditLog     bool
 	EnableSSHLog       bool
 	AccessLogTemplate  string
 	EnableXORMLog      bool
@@ -1205,6 +1206,7 @@ func NewServices() {
 	newMailService()
 	newRegisterMailService()
 	newNotifyMailService()
+	newIncomingEmail()
 	newProxyService()
 	newWebhookService()
 	newMigrationsService()
@@ -6,6 +6,7 @@ package setting
 
 import (
 	"net/url"
+	"time"
 
 	"code.gitea.io/gitea/modules/hostmatcher"
 	"code.gitea.io/gitea/modules/log"
@@ -23,13 +24,21 @@ var (
 		ProxyURL        string
 		ProxyURLFixed   *url.URL
 		ProxyHosts      []string
+		MaxRetries      int
+		RetryBackoff    time.Duration
+		MaxRetryBackoff time.Duration
+		DisableAfter    int
 	}{
-		QueueLength:    1000,
-		DeliverTimeout: 5,
-		SkipTLSVerify:  false,
-		PagingNum:      10,
-		ProxyURL:       "",
-		ProxyHosts:     []string{},
+		QueueLength:     1000,
+		DeliverTimeout:  5,
+		SkipTLSVerify:   false,
+		PagingNum:       10,
+		ProxyURL:        "",
+		ProxyHosts:      []string{},
+		MaxRetries:      3,
+		RetryBackoff:    time.Minute,
+		MaxRetryBackoff: time.Hour,
+		DisableAfter:    50,
 	}
 )
 
@@ -39,7 +48,7 @@ func newWebhookService() {
 	Webhook.DeliverTimeout = sec.Key("DELIVER_TIMEOUT").MustInt(5)
 	Webhook.SkipTLSVerify = sec.Key("SKIP_TLS_VERIFY").MustBool()
 	Webhook.AllowedHostList = hostmatcher.ParseHostMatchList(sec.Key("ALLOWED_HOST_LIST").MustString(hostmatcher.MatchBuiltinExternal))
-	Webhook.Types = []string{"gitea", "gogs", "slack", "discord", "dingtalk", "telegram", "msteams", "feishu", "matrix", "wechatwork"}
+	Webhook.Types = []string{"gitea", "gogs", "slack", "discord", "dingtalk", "telegram", "msteams", "feishu", "matrix", "wechatwork", "mattermost", "rocketchat", "ntfy", "custom"}
 	Webhook.PagingNum = sec.Key("PAGING_NUM").MustInt(10)
 	Webhook.ProxyURL = sec.Key("PROXY_URL").MustString("")
 	if Webhook.ProxyURL != "" {
@@ -51,4 +60,8 @@ func newWebhookService() {
 		}
 	}
 	Webhook.ProxyHosts = sec.Key("PROXY_HOSTS").Strings(",")
+	Webhook.MaxRetries = sec.Key("MAX_RETRIES").MustInt(3)
+	Webhook.RetryBackoff = sec.Key("RETRY_BACKOFF").MustDuration(time.Minute)
+	Webhook.MaxRetryBackoff = sec.Key("MAX_RETRY_BACKOFF").MustDuration(time.Hour)
+	Webhook.DisableAfter = sec.Key("DISABLE_AFTER_FAILURES").MustInt(50)
 }
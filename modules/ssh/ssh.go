@@ -11,7 +11,6 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
-	"fmt"
 	"io"
 	"net"
 	"os"
@@ -23,6 +22,7 @@ import (
 	"syscall"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
@@ -31,9 +31,11 @@ import (
 	gossh "golang.org/x/crypto/ssh"
 )
 
-type contextKey string
+// giteaKeyIDExtension holds the id of the gitea key accepted for a public key in its permissions
+const giteaKeyIDExtension = "gitea-key-id"
 
-const giteaKeyID = contextKey("gitea-key-id")
+// forceCommandCriticalOption restricts a certificate to the given command, ignoring the one requested by the client
+const forceCommandCriticalOption = "force-command"
 
 func getExitStatusFromError(err error) int {
 	if err == nil {
@@ -60,12 +62,20 @@ func getExitStatusFromError(err error) int {
 }
 
 func sessionHandler(session ssh.Session) {
-	keyID := fmt.Sprintf("%d", session.Context().Value(giteaKeyID).(int64))
+	// The client may query other keys after the one it authenticates with, so the permissions
+	// of the connection are read back from the key that actually authenticated it
+	perms := session.Context().Value(ssh.ContextKeyConn).(*gossh.ServerConn).Permissions
+	keyID := perms.Extensions[giteaKeyIDExtension]
 
 	command := session.RawCommand()
 
 	log.Trace("SSH: Payload: %v", command)
 
+	if forceCommand, ok := perms.CriticalOptions[forceCommandCriticalOption]; ok {
+		log.Trace("SSH: Certificate forces command %q instead of %q", forceCommand, command)
+		command = forceCommand
+	}
+
 	args := []string{"serv", "key-" + keyID, "--config=" + setting.CustomConf}
 	log.Trace("SSH: Arguments: %v", args)
 
@@ -79,6 +89,13 @@ func sessionHandler(session ssh.Session) {
 		"SKIP_MINWINSVC=1",
 	)
 
+	// Pass through the wire protocol requested by the client so that git protocol v2 works
+	for _, env := range session.Environ() {
+		if protocol := strings.TrimPrefix(env, "GIT_PROTOCOL="); protocol != env && git.IsSafeProtocol(protocol) {
+			cmd.Env = append(cmd.Env, env)
+		}
+	}
+
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		log.Error("SSH: StdoutPipe: %v", err)
@@ -163,12 +180,41 @@ func publicKeyHandler(ctx ssh.Context, key ssh.PublicKey) bool {
 			log.Debug("Handle Certificate: %s Fingerprint: %s is a certificate", ctx.RemoteAddr(), gossh.FingerprintSHA256(key))
 		}
 
-		if len(setting.SSH.TrustedUserCAKeys) == 0 {
+		if len(setting.SSH.TrustedUserCAKeysParsed) == 0 {
 			log.Warn("Certificate Rejected: No trusted certificate authorities for this server")
 			log.Warn("Failed authentication attempt from %s", ctx.RemoteAddr())
 			return false
 		}
 
+		if cert.CertType != gossh.UserCert {
+			log.Warn("Certificate Rejected: %s presented a host certificate (serial %d, key id %q) for user authentication", ctx.RemoteAddr(), cert.Serial, cert.KeyId)
+			log.Warn("Failed authentication attempt from %s", ctx.RemoteAddr())
+			return false
+		}
+
+		c := &gossh.CertChecker{
+			IsUserAuthority: func(auth gossh.PublicKey) bool {
+				for _, k := range setting.SSH.TrustedUserCAKeysParsed {
+					if bytes.Equal(auth.Marshal(), k.Marshal()) {
+						return true
+					}
+				}
+
+				return false
+			},
+			// source-address is enforced by the ssh library once we hand the critical options back in the permissions
+			SupportedCriticalOptions: []string{forceCommandCriticalOption},
+		}
+
+		// check the CA of the cert
+		if !c.IsUserAuthority(cert.SignatureKey) {
+			if log.IsWarn() {
+				log.Warn("Certificate Rejected: %s Untrusted Authority Signature Fingerprint %s for certificate serial %d (key id %q)", ctx.RemoteAddr(), gossh.FingerprintSHA256(cert.SignatureKey), cert.Serial, cert.KeyId)
+				log.Warn("Failed authentication attempt from %s", ctx.RemoteAddr())
+			}
+			return false
+		}
+
 		// look for the exact principal
 	principalLoop:
 		for _, principal := range cert.ValidPrincipals {
@@ -182,47 +228,25 @@ func publicKeyHandler(ctx ssh.Context, key ssh.PublicKey) bool {
 				return false
 			}
 
-			c := &gossh.CertChecker{
-				IsUserAuthority: func(auth gossh.PublicKey) bool {
-					for _, k := range setting.SSH.TrustedUserCAKeysParsed {
-						if bytes.Equal(auth.Marshal(), k.Marshal()) {
-							return true
-						}
-					}
-
-					return false
-				},
-			}
-
-			// check the CA of the cert
-			if !c.IsUserAuthority(cert.SignatureKey) {
-				if log.IsDebug() {
-					log.Debug("Principal Rejected: %s Untrusted Authority Signature Fingerprint %s for Principal: %s", ctx.RemoteAddr(), gossh.FingerprintSHA256(cert.SignatureKey), principal)
-				}
-				continue principalLoop
-			}
-
-			// validate the cert for this principal
+			// validate the cert for this principal - this checks the validity window, the critical options and the signature
 			if err := c.CheckCert(principal, cert); err != nil {
 				// User is presenting an invalid certificate - STOP any further processing
 				if log.IsError() {
-					log.Error("Invalid Certificate KeyID %s with Signature Fingerprint %s presented for Principal: %s from %s", cert.KeyId, gossh.FingerprintSHA256(cert.SignatureKey), principal, ctx.RemoteAddr())
+					log.Error("Invalid Certificate serial %d KeyID %s with Signature Fingerprint %s presented for Principal: %s from %s: %v", cert.Serial, cert.KeyId, gossh.FingerprintSHA256(cert.SignatureKey), principal, ctx.RemoteAddr(), err)
 				}
 				log.Warn("Failed authentication attempt from %s", ctx.RemoteAddr())
 
 				return false
 			}
 
-			if log.IsDebug() { // <- FingerprintSHA256 is kinda expensive so only calculate it if necessary
-				log.Debug("Successfully authenticated: %s Certificate Fingerprint: %s Principal: %s", ctx.RemoteAddr(), gossh.FingerprintSHA256(key), principal)
-			}
-			ctx.SetValue(giteaKeyID, pkey.ID)
+			log.Info("Accepted certificate serial %d KeyID %s signed by %s for Principal: %s from %s", cert.Serial, cert.KeyId, gossh.FingerprintSHA256(cert.SignatureKey), principal, ctx.RemoteAddr())
+			setKeyPermissions(ctx, pkey, cert.CriticalOptions)
 
 			return true
 		}
 
 		if log.IsWarn() {
-			log.Warn("From %s Fingerprint: %s is a certificate, but no valid principals found", ctx.RemoteAddr(), gossh.FingerprintSHA256(key))
+			log.Warn("From %s Fingerprint: %s is a certificate (serial %d, key id %q), but no valid principals found", ctx.RemoteAddr(), gossh.FingerprintSHA256(key), cert.Serial, cert.KeyId)
 			log.Warn("Failed authentication attempt from %s", ctx.RemoteAddr())
 		}
 		return false
@@ -248,11 +272,23 @@ func publicKeyHandler(ctx ssh.Context, key ssh.PublicKey) bool {
 	if log.IsDebug() { // <- FingerprintSHA256 is kinda expensive so only calculate it if necessary
 		log.Debug("Successfully authenticated: %s Public Key Fingerprint: %s", ctx.RemoteAddr(), gossh.FingerprintSHA256(key))
 	}
-	ctx.SetValue(giteaKeyID, pkey.ID)
+	setKeyPermissions(ctx, pkey, nil)
 
 	return true
 }
 
+// setKeyPermissions sets the permissions returned for the public key being handled. x/crypto/ssh
+// caches them per public key, so each key gets its own permissions rather than changing the ones
+// shared by the connection.
+func setKeyPermissions(ctx ssh.Context, pkey *models.PublicKey, criticalOptions map[string]string) {
+	ctx.SetValue(ssh.ContextKeyPermissions, &ssh.Permissions{Permissions: &gossh.Permissions{
+		CriticalOptions: criticalOptions,
+		Extensions: map[string]string{
+			giteaKeyIDExtension: strconv.FormatInt(pkey.ID, 10),
+		},
+	}})
+}
+
 // sshConnectionFailed logs a failed connection
 // -  this mainly exists to give a nice function name in logging
 func sshConnectionFailed(conn net.Conn, err error) {
@@ -381,12 +417,11 @@ func (k *wrapPublicKey) Type() string {
 // Public key is encoded in the format for inclusion in an OpenSSH authorized_keys file.
 // Private Key generated is PEM encoded
 func GenKeyPair(keyPath string) error {
-	privateKey, err := rsa.GenerateKey(rand.Reader, 4096)
+	private, public, err := GenerateKeyPair()
 	if err != nil {
 		return err
 	}
 
-	privateKeyPEM := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}
 	f, err := os.OpenFile(keyPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
 	if err != nil {
 		return err
@@ -397,17 +432,10 @@ func GenKeyPair(keyPath string) error {
 		}
 	}()
 
-	if err := pem.Encode(f, privateKeyPEM); err != nil {
-		return err
-	}
-
-	// generate public key
-	pub, err := gossh.NewPublicKey(&privateKey.PublicKey)
-	if err != nil {
+	if _, err := f.Write(private); err != nil {
 		return err
 	}
 
-	public := gossh.MarshalAuthorizedKey(pub)
 	p, err := os.OpenFile(keyPath+".pub", os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
 	if err != nil {
 		return err
@@ -420,3 +448,21 @@ func GenKeyPair(keyPath string) error {
 	_, err = p.Write(public)
 	return err
 }
+
+// GenerateKeyPair returns a new PEM encoded private key and its public key
+// in the format for inclusion in an OpenSSH authorized_keys file.
+func GenerateKeyPair() (private, public []byte, err error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 4096)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	privateKeyPEM := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}
+
+	pub, err := gossh.NewPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pem.EncodeToMemory(privateKeyPEM), gossh.MarshalAuthorizedKey(pub), nil
+}
@@ -0,0 +1,533 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+var (
+	_ ObjectStorage = &AzureBlobStorage{}
+)
+
+// AzureBlobStorageType is the type descriptor for Azure Blob storage
+const AzureBlobStorageType Type = "azureblob"
+
+const (
+	azureBlobAPIVersion = "2020-04-08"
+	// objects larger than a block are uploaded block by block
+	azureBlobBlockSize = 4 * 1024 * 1024
+)
+
+// AzureBlobStorageConfig represents the configuration for an Azure Blob storage
+type AzureBlobStorageConfig struct {
+	// Endpoint of the blob service, https://<account>.blob.core.windows.net if empty.
+	// For the Azurite emulator the account is part of the path, e.g. http://127.0.0.1:10000/devstoreaccount1
+	Endpoint    string `ini:"AZURE_BLOB_ENDPOINT"`
+	AccountName string `ini:"AZURE_BLOB_ACCOUNT_NAME"`
+	AccountKey  string `ini:"AZURE_BLOB_ACCOUNT_KEY"`
+	Container   string `ini:"AZURE_BLOB_CONTAINER"`
+	BasePath    string `ini:"AZURE_BLOB_BASE_PATH"`
+}
+
+// AzureBlobStorage returns an Azure Blob storage container storage. It talks to the
+// Blob service REST API directly and authorizes requests with the account's shared key.
+type AzureBlobStorage struct {
+	ctx         context.Context
+	client      *http.Client
+	endpoint    *url.URL
+	accountName string
+	accountKey  []byte
+	container   string
+	basePath    string
+}
+
+type azureBlobError struct {
+	StatusCode int    `xml:"-"`
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+}
+
+func (err *azureBlobError) Error() string {
+	return fmt.Sprintf("azure blob storage: %d %s: %s", err.StatusCode, err.Code, err.Message)
+}
+
+func convertAzureBlobErr(resp *http.Response) error {
+	// Convert two responses to standard analogues
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return os.ErrNotExist
+	case http.StatusForbidden:
+		return os.ErrPermission
+	}
+
+	err := &azureBlobError{StatusCode: resp.StatusCode}
+	_ = xml.NewDecoder(resp.Body).Decode(err)
+	return err
+}
+
+// NewAzureBlobStorage returns an Azure Blob storage
+func NewAzureBlobStorage(ctx context.Context, cfg interface{}) (ObjectStorage, error) {
+	configInterface, err := toConfig(AzureBlobStorageConfig{}, cfg)
+	if err != nil {
+		return nil, err
+	}
+	config := configInterface.(AzureBlobStorageConfig)
+
+	if config.Endpoint == "" {
+		config.Endpoint = "https://" + config.AccountName + ".blob.core.windows.net"
+	}
+	endpoint, err := url.Parse(strings.TrimSuffix(config.Endpoint, "/"))
+	if err != nil {
+		return nil, ErrInvalidConfiguration{cfg: cfg, err: err}
+	}
+	accountKey, err := base64.StdEncoding.DecodeString(config.AccountKey)
+	if err != nil {
+		return nil, ErrInvalidConfiguration{cfg: cfg, err: fmt.Errorf("account key is not base64 encoded: %v", err)}
+	}
+
+	log.Info("Creating Azure Blob storage at %s:%s with base path %s", config.Endpoint, config.Container, config.BasePath)
+
+	a := &AzureBlobStorage{
+		ctx:         ctx,
+		client:      &http.Client{},
+		endpoint:    endpoint,
+		accountName: config.AccountName,
+		accountKey:  accountKey,
+		container:   config.Container,
+		basePath:    config.BasePath,
+	}
+
+	resp, err := a.do(http.MethodPut, "", url.Values{"restype": {"container"}}, nil, nil)
+	if err != nil {
+		// Check to see if we already own this container (which happens if you run this twice)
+		var blobErr *azureBlobError
+		if !errors.As(err, &blobErr) || blobErr.StatusCode != http.StatusConflict {
+			return nil, err
+		}
+	} else {
+		resp.Body.Close()
+	}
+
+	return a, nil
+}
+
+func (a *AzureBlobStorage) buildAzureBlobPath(p string) string {
+	return strings.TrimPrefix(path.Join(a.basePath, p), "/")
+}
+
+func (a *AzureBlobStorage) blobURL(blobPath string) *url.URL {
+	u := *a.endpoint
+	u.Path = u.Path + "/" + a.container
+	if blobPath != "" {
+		u.Path += "/" + blobPath
+	}
+	return &u
+}
+
+func (a *AzureBlobStorage) sign(stringToSign string) string {
+	mac := hmac.New(sha256.New, a.accountKey)
+	_, _ = mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// authorize adds the shared key authorization header to the request
+func (a *AzureBlobStorage) authorize(req *http.Request) {
+	contentLength := ""
+	if req.ContentLength > 0 {
+		contentLength = strconv.FormatInt(req.ContentLength, 10)
+	}
+
+	headerNames := make([]string, 0, 4)
+	for name := range req.Header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-ms-") {
+			headerNames = append(headerNames, lower)
+		}
+	}
+	sort.Strings(headerNames)
+	var canonicalized strings.Builder
+	for _, name := range headerNames {
+		canonicalized.WriteString(name + ":" + strings.TrimSpace(req.Header.Get(name)) + "\n")
+	}
+
+	canonicalized.WriteString("/" + a.accountName + req.URL.EscapedPath())
+	query := req.URL.Query()
+	paramNames := make([]string, 0, len(query))
+	for name := range query {
+		paramNames = append(paramNames, name)
+	}
+	sort.Strings(paramNames)
+	for _, name := range paramNames {
+		values := query[name]
+		sort.Strings(values)
+		canonicalized.WriteString("\n" + strings.ToLower(name) + ":" + strings.Join(values, ","))
+	}
+
+	stringToSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		contentLength,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		"", // Date, x-ms-date is used instead
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+		canonicalized.String(),
+	}, "\n")
+
+	req.Header.Set("Authorization", "SharedKey "+a.accountName+":"+a.sign(stringToSign))
+}
+
+// do sends an authorized request for the blob, or the container if blobPath is empty
+func (a *AzureBlobStorage) do(method, blobPath string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	u := a.blobURL(blobPath)
+	u.RawQuery = query.Encode()
+
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(a.ctx, method, u.String(), bodyReader)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", azureBlobAPIVersion)
+	a.authorize(req)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, convertAzureBlobErr(resp)
+	}
+	return resp, nil
+}
+
+func (a *AzureBlobStorage) doAndClose(method, blobPath string, query url.Values, header http.Header, body []byte) error {
+	resp, err := a.do(method, blobPath, query, header, body)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
+}
+
+type azureBlobFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func (a azureBlobFileInfo) Name() string {
+	return path.Base(a.name)
+}
+
+func (a azureBlobFileInfo) Size() int64 {
+	return a.size
+}
+
+func (a azureBlobFileInfo) ModTime() time.Time {
+	return a.modTime
+}
+
+func (a azureBlobFileInfo) IsDir() bool {
+	return strings.HasSuffix(a.name, "/")
+}
+
+func (a azureBlobFileInfo) Mode() os.FileMode {
+	return os.ModePerm
+}
+
+func (a azureBlobFileInfo) Sys() interface{} {
+	return nil
+}
+
+// azureBlobObject reads a blob lazily, seeking restarts the download at the new offset
+type azureBlobObject struct {
+	storage  *AzureBlobStorage
+	blobPath string
+	info     *azureBlobFileInfo
+	offset   int64
+	body     io.ReadCloser
+}
+
+func (o *azureBlobObject) Read(p []byte) (int, error) {
+	if o.offset >= o.info.size {
+		return 0, io.EOF
+	}
+	if o.body == nil {
+		header := http.Header{}
+		header.Set("x-ms-range", fmt.Sprintf("bytes=%d-", o.offset))
+		resp, err := o.storage.do(http.MethodGet, o.blobPath, nil, header, nil)
+		if err != nil {
+			return 0, err
+		}
+		o.body = resp.Body
+	}
+	n, err := o.body.Read(p)
+	o.offset += int64(n)
+	return n, err
+}
+
+func (o *azureBlobObject) Seek(offset int64, whence int) (int64, error) {
+	var abs int64
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		abs = o.offset + offset
+	case io.SeekEnd:
+		abs = o.info.size + offset
+	default:
+		return 0, errors.New("azure blob storage: invalid whence")
+	}
+	if abs < 0 {
+		return 0, errors.New("azure blob storage: negative position")
+	}
+	if abs != o.offset && o.body != nil {
+		_ = o.body.Close()
+		o.body = nil
+	}
+	o.offset = abs
+	return abs, nil
+}
+
+func (o *azureBlobObject) Close() error {
+	if o.body == nil {
+		return nil
+	}
+	err := o.body.Close()
+	o.body = nil
+	return err
+}
+
+func (o *azureBlobObject) Stat() (os.FileInfo, error) {
+	return o.info, nil
+}
+
+// Open open a file
+func (a *AzureBlobStorage) Open(path string) (Object, error) {
+	blobPath := a.buildAzureBlobPath(path)
+	info, err := a.stat(blobPath)
+	if err != nil {
+		return nil, err
+	}
+	return &azureBlobObject{storage: a, blobPath: blobPath, info: info}, nil
+}
+
+// Save save a file to the container
+func (a *AzureBlobStorage) Save(path string, r io.Reader, size int64) (int64, error) {
+	blobPath := a.buildAzureBlobPath(path)
+
+	buf := make([]byte, azureBlobBlockSize)
+	blockIDs := make([]string, 0, 1)
+	var written int64
+	for {
+		n, err := io.ReadFull(r, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return 0, err
+		}
+		last := err != nil
+
+		if last && len(blockIDs) == 0 {
+			// the whole object fits into a single request
+			header := http.Header{}
+			header.Set("x-ms-blob-type", "BlockBlob")
+			header.Set("Content-Type", "application/octet-stream")
+			if err := a.doAndClose(http.MethodPut, blobPath, nil, header, buf[:n]); err != nil {
+				return 0, err
+			}
+			return int64(n), nil
+		}
+
+		if n > 0 {
+			// block ids of a blob must have the same length
+			blockID := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%010d", len(blockIDs))))
+			query := url.Values{"comp": {"block"}, "blockid": {blockID}}
+			if err := a.doAndClose(http.MethodPut, blobPath, query, nil, buf[:n]); err != nil {
+				return 0, err
+			}
+			blockIDs = append(blockIDs, blockID)
+			written += int64(n)
+		}
+		if last {
+			break
+		}
+	}
+
+	var blockList bytes.Buffer
+	blockList.WriteString(`<?xml version="1.0" encoding="utf-8"?><BlockList>`)
+	for _, blockID := range blockIDs {
+		blockList.WriteString("<Latest>" + blockID + "</Latest>")
+	}
+	blockList.WriteString("</BlockList>")
+
+	header := http.Header{}
+	header.Set("x-ms-blob-content-type", "application/octet-stream")
+	if err := a.doAndClose(http.MethodPut, blobPath, url.Values{"comp": {"blocklist"}}, header, blockList.Bytes()); err != nil {
+		return 0, err
+	}
+	return written, nil
+}
+
+func (a *AzureBlobStorage) stat(blobPath string) (*azureBlobFileInfo, error) {
+	resp, err := a.do(http.MethodHead, blobPath, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return &azureBlobFileInfo{
+		name:    blobPath,
+		size:    resp.ContentLength,
+		modTime: modTime,
+	}, nil
+}
+
+// Stat returns the stat information of the object
+func (a *AzureBlobStorage) Stat(path string) (os.FileInfo, error) {
+	return a.stat(a.buildAzureBlobPath(path))
+}
+
+// Delete delete a file
+func (a *AzureBlobStorage) Delete(path string) error {
+	err := a.doAndClose(http.MethodDelete, a.buildAzureBlobPath(path), nil, nil, nil)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// URL gets the redirect URL to a file. The shared access signature is valid for 5 minutes.
+func (a *AzureBlobStorage) URL(path, name string) (*url.URL, error) {
+	blobPath := a.buildAzureBlobPath(path)
+	expiry := time.Now().UTC().Add(5 * time.Minute).Format("2006-01-02T15:04:05Z")
+	disposition := "attachment; filename=\"" + quoteEscaper.Replace(name) + "\""
+
+	stringToSign := strings.Join([]string{
+		"r", // signed permissions
+		"",  // signed start
+		expiry,
+		"/blob/" + a.accountName + "/" + a.container + "/" + blobPath,
+		"", // signed identifier
+		"", // signed IP
+		"", // signed protocol
+		azureBlobAPIVersion,
+		"b", // signed resource
+		"",  // signed snapshot time
+		"",  // Cache-Control
+		disposition,
+		"", // Content-Encoding
+		"", // Content-Language
+		"", // Content-Type
+	}, "\n")
+
+	u := a.blobURL(blobPath)
+	u.RawQuery = url.Values{
+		"sv":   {azureBlobAPIVersion},
+		"sr":   {"b"},
+		"sp":   {"r"},
+		"se":   {expiry},
+		"rscd": {disposition},
+		"sig":  {a.sign(stringToSign)},
+	}.Encode()
+	return u, nil
+}
+
+type azureBlobList struct {
+	Blobs []struct {
+		Name       string `xml:"Name"`
+		Properties struct {
+			ContentLength int64  `xml:"Content-Length"`
+			LastModified  string `xml:"Last-Modified"`
+		} `xml:"Properties"`
+	} `xml:"Blobs>Blob"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+// IterateObjects iterates across the objects in the container
+func (a *AzureBlobStorage) IterateObjects(fn func(path string, obj Object) error) error {
+	query := url.Values{
+		"restype": {"container"},
+		"comp":    {"list"},
+	}
+	if a.basePath != "" {
+		query.Set("prefix", a.basePath)
+	}
+	for {
+		resp, err := a.do(http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return err
+		}
+		var list azureBlobList
+		err = xml.NewDecoder(resp.Body).Decode(&list)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		for _, blob := range list.Blobs {
+			select {
+			case <-a.ctx.Done():
+				return a.ctx.Err()
+			default:
+			}
+			modTime, _ := http.ParseTime(blob.Properties.LastModified)
+			obj := &azureBlobObject{
+				storage:  a,
+				blobPath: blob.Name,
+				info: &azureBlobFileInfo{
+					name:    blob.Name,
+					size:    blob.Properties.ContentLength,
+					modTime: modTime,
+				},
+			}
+			if err := func() error {
+				defer obj.Close()
+				return fn(strings.TrimPrefix(blob.Name, a.basePath), obj)
+			}(); err != nil {
+				return err
+			}
+		}
+
+		if list.NextMarker == "" {
+			return nil
+		}
+		query.Set("marker", list.NextMarker)
+	}
+}
+
+func init() {
+	RegisterStorageType(AzureBlobStorageType, NewAzureBlobStorage)
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the well-known development account of the Azurite emulator
+const (
+	azuriteAccountName = "devstoreaccount1"
+	azuriteAccountKey  = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw=="
+)
+
+func TestAzureBlobStorage(t *testing.T) {
+	endpoint := os.Getenv("TEST_AZURITE_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("TEST_AZURITE_ENDPOINT not set")
+		return
+	}
+
+	s, err := NewAzureBlobStorage(context.Background(), AzureBlobStorageConfig{
+		Endpoint:    endpoint,
+		AccountName: azuriteAccountName,
+		AccountKey:  azuriteAccountKey,
+		Container:   "gitea-test",
+		BasePath:    "attachments/",
+	})
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, Clean(s))
+	}()
+
+	small := []byte("hello azure")
+	n, err := s.Save("a/small", bytes.NewReader(small), int64(len(small)))
+	assert.NoError(t, err)
+	assert.EqualValues(t, len(small), n)
+
+	// uploaded in several blocks
+	large := bytes.Repeat([]byte("0123456789abcdef"), (2*azureBlobBlockSize+100)/16)
+	n, err = s.Save("b/large", bytes.NewReader(large), -1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, len(large), n)
+
+	fi, err := s.Stat("b/large")
+	assert.NoError(t, err)
+	assert.EqualValues(t, len(large), fi.Size())
+	assert.Equal(t, "large", fi.Name())
+
+	obj, err := s.Open("b/large")
+	assert.NoError(t, err)
+	_, err = obj.Seek(azureBlobBlockSize-5, io.SeekStart)
+	assert.NoError(t, err)
+	buf := make([]byte, 10)
+	_, err = io.ReadFull(obj, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, large[azureBlobBlockSize-5:azureBlobBlockSize+5], buf)
+	_, err = obj.Seek(0, io.SeekStart)
+	assert.NoError(t, err)
+	content, err := io.ReadAll(obj)
+	assert.NoError(t, err)
+	assert.Equal(t, large, content)
+	assert.NoError(t, obj.Close())
+
+	u, err := s.URL("a/small", "small.txt")
+	assert.NoError(t, err)
+	resp, err := http.Get(u.String())
+	assert.NoError(t, err)
+	content, err = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, small, content)
+	assert.True(t, strings.Contains(resp.Header.Get("Content-Disposition"), "small.txt"))
+
+	paths := make([]string, 0, 2)
+	assert.NoError(t, s.IterateObjects(func(path string, obj Object) error {
+		paths = append(paths, path)
+		return nil
+	}))
+	assert.Equal(t, []string{"a/small", "b/large"}, paths)
+
+	assert.NoError(t, s.Delete("a/small"))
+	_, err = s.Stat("a/small")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	_, err = s.Open("a/small")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,349 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
+)
+
+var (
+	_ ObjectStorage = &LocalCASStorage{}
+)
+
+// LocalCASStorageType is the type descriptor for content-addressed local storage
+const LocalCASStorageType Type = "local-cas"
+
+const (
+	// casObjectsDir is the directory below the storage path which holds the objects
+	casObjectsDir = ".objects"
+	// casPointerPrefix starts the files which point to an object
+	casPointerPrefix = "gitea-cas sha256:"
+	// casLockTimeout is how long an operation waits for the lock of the objects
+	casLockTimeout = 30 * time.Second
+	// casLockStale is the age after which a lock is considered to be left by a crashed process
+	casLockStale = 5 * time.Minute
+)
+
+// LocalCASStorage represents a local files storage which deduplicates identical files.
+// The content of every file is stored once as an object named by its SHA-256, next to
+// a file counting the paths which refer to it. The paths of the storage only hold the
+// SHA-256 of their content. The objects and their counts are modified under a lock file,
+// so several processes can share the storage.
+type LocalCASStorage struct {
+	*LocalStorage
+	objectsDir string
+}
+
+// NewLocalCASStorage returns a content-addressed local files storage
+func NewLocalCASStorage(ctx context.Context, cfg interface{}) (ObjectStorage, error) {
+	local, err := NewLocalStorage(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	l := &LocalCASStorage{
+		LocalStorage: local.(*LocalStorage),
+	}
+	l.objectsDir = filepath.Join(l.dir, casObjectsDir)
+
+	log.Info("Storing deduplicated objects at %s", l.objectsDir)
+	if err := os.MkdirAll(l.objectsDir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *LocalCASStorage) objectPath(sum string) string {
+	return filepath.Join(l.objectsDir, sum[0:2], sum[2:4], sum)
+}
+
+// lock acquires the lock of the objects and returns the function to release it
+func (l *LocalCASStorage) lock() (func(), error) {
+	lockPath := filepath.Join(l.objectsDir, "lock")
+	start := time.Now()
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			_ = f.Close()
+			return func() {
+				_ = os.Remove(lockPath)
+			}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		if fi, err := os.Stat(lockPath); err == nil && time.Since(fi.ModTime()) > casLockStale {
+			log.Warn("Removing stale lock %s", lockPath)
+			_ = os.Remove(lockPath)
+			continue
+		}
+		if time.Since(start) > casLockTimeout {
+			return nil, fmt.Errorf("timed out waiting for the lock %s", lockPath)
+		}
+		select {
+		case <-l.ctx.Done():
+			return nil, l.ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// readPointer returns the SHA-256 of the content of the file p, or an empty string if
+// p is a plain file, e.g. one stored before the storage was deduplicated
+func readPointer(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	buf := make([]byte, len(casPointerPrefix)+sha256.Size*2+1)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	content := string(buf[:n])
+	if n == len(buf) || !strings.HasPrefix(content, casPointerPrefix) {
+		return "", nil
+	}
+	sum := strings.TrimPrefix(content, casPointerPrefix)
+	if _, err := hex.DecodeString(sum); err != nil || len(sum) != sha256.Size*2 {
+		return "", nil
+	}
+	return sum, nil
+}
+
+// resolve returns the path of the file holding the content of the stored path
+func (l *LocalCASStorage) resolve(path string) (string, error) {
+	p := filepath.Join(l.dir, path)
+	sum, err := readPointer(p)
+	if err != nil || sum == "" {
+		return p, err
+	}
+	return l.objectPath(sum), nil
+}
+
+// refs returns the number of paths which refer to the object
+func (l *LocalCASStorage) refs(sum string) (int64, error) {
+	content, err := os.ReadFile(l.objectPath(sum) + ".refs")
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(string(bytes.TrimSpace(content)), 10, 64)
+}
+
+// addRefs changes the number of paths which refer to the object by delta and removes
+// the object when no path refers to it anymore. The lock must be held.
+func (l *LocalCASStorage) addRefs(sum string, delta int64) error {
+	count, err := l.refs(sum)
+	if err != nil {
+		return err
+	}
+	count += delta
+
+	object := l.objectPath(sum)
+	if count <= 0 {
+		if err := util.Remove(object); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err := util.Remove(object + ".refs"); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return writeFileAtomic(object+".refs", []byte(strconv.FormatInt(count, 10)))
+}
+
+// writeFileAtomic replaces the file p by one with the content
+func writeFileAtomic(p string, content []byte) error {
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o644); err != nil {
+		return err
+	}
+	if err := util.Rename(tmp, p); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// Open a file
+func (l *LocalCASStorage) Open(path string) (Object, error) {
+	p, err := l.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(p)
+}
+
+// Save a file, reusing the object of an identical file if there is one
+func (l *LocalCASStorage) Save(path string, r io.Reader, size int64) (int64, error) {
+	if err := os.MkdirAll(l.tmpdir, os.ModePerm); err != nil {
+		return 0, err
+	}
+	tmp, err := os.CreateTemp(l.tmpdir, "upload-*")
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		// the temporary file has usually become the object by now
+		_ = os.Remove(tmp.Name())
+	}()
+
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, h), r)
+	if err != nil {
+		_ = tmp.Close()
+		return 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+
+	p := filepath.Join(l.dir, path)
+	if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+		return 0, err
+	}
+
+	unlock, err := l.lock()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	oldSum, err := readPointer(p)
+	if err != nil && !os.IsNotExist(err) {
+		return 0, err
+	}
+	if oldSum == sum {
+		return n, nil
+	}
+
+	object := l.objectPath(sum)
+	if _, err := os.Stat(object); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(object), os.ModePerm); err != nil {
+			return 0, err
+		}
+		if err := util.Rename(tmp.Name(), object); err != nil {
+			return 0, err
+		}
+	} else if err != nil {
+		return 0, err
+	}
+
+	// count the new reference first, so a failure leaves an unused object rather than a missing one
+	if err := l.addRefs(sum, 1); err != nil {
+		return 0, err
+	}
+	if err := writeFileAtomic(p, []byte(casPointerPrefix+sum)); err != nil {
+		_ = l.addRefs(sum, -1)
+		return 0, err
+	}
+	if oldSum != "" {
+		if err := l.addRefs(oldSum, -1); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+// casFileInfo is the info of an object under the name of the path referring to it
+type casFileInfo struct {
+	os.FileInfo
+	name string
+}
+
+func (fi casFileInfo) Name() string {
+	return fi.name
+}
+
+// Stat returns the info of the file
+func (l *LocalCASStorage) Stat(path string) (os.FileInfo, error) {
+	p, err := l.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+	return casFileInfo{FileInfo: fi, name: filepath.Base(path)}, nil
+}
+
+// Delete delete a file and its object if no other file has the same content
+func (l *LocalCASStorage) Delete(path string) error {
+	p := filepath.Join(l.dir, path)
+
+	unlock, err := l.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	sum, err := readPointer(p)
+	if err != nil {
+		return err
+	}
+	if err := util.Remove(p); err != nil {
+		return err
+	}
+	if sum == "" {
+		return nil
+	}
+	return l.addRefs(sum, -1)
+}
+
+// IterateObjects iterates across the files in the storage, skipping the objects
+func (l *LocalCASStorage) IterateObjects(fn func(path string, obj Object) error) error {
+	return filepath.Walk(l.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		select {
+		case <-l.ctx.Done():
+			return l.ctx.Err()
+		default:
+		}
+		if path == l.dir {
+			return nil
+		}
+		if info.IsDir() {
+			if path == l.objectsDir || path == l.tmpdir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		relPath, err := filepath.Rel(l.dir, path)
+		if err != nil {
+			return err
+		}
+		obj, err := l.Open(relPath)
+		if err != nil {
+			return err
+		}
+		defer obj.Close()
+		return fn(relPath, obj)
+	})
+}
+
+func init() {
+	RegisterStorageType(LocalCASStorageType, NewLocalCASStorage)
+}
@@ -0,0 +1,129 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func countCASObjects(t *testing.T, dir string) int {
+	count := 0
+	assert.NoError(t, filepath.Walk(filepath.Join(dir, casObjectsDir), func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && filepath.Ext(path) == "" && info.Name() != "lock" {
+			count++
+		}
+		return err
+	}))
+	return count
+}
+
+func TestLocalCASStorage(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewLocalCASStorage(context.Background(), LocalStorageConfig{Path: dir})
+	assert.NoError(t, err)
+
+	for _, p := range []string{"a/b/first", "c/second", "third"} {
+		_, err := s.Save(p, strings.NewReader("same content"), -1)
+		assert.NoError(t, err)
+	}
+	_, err = s.Save("other", strings.NewReader("other content"), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, countCASObjects(t, dir))
+
+	first, err := s.Stat("a/b/first")
+	assert.NoError(t, err)
+	assert.Equal(t, "first", first.Name())
+	assert.EqualValues(t, len("same content"), first.Size())
+
+	obj, err := s.Open("third")
+	assert.NoError(t, err)
+	content, err := io.ReadAll(obj)
+	assert.NoError(t, err)
+	assert.NoError(t, obj.Close())
+	assert.Equal(t, "same content", string(content))
+
+	paths := make([]string, 0, 4)
+	assert.NoError(t, s.IterateObjects(func(path string, obj Object) error {
+		paths = append(paths, filepath.ToSlash(path))
+		return nil
+	}))
+	sort.Strings(paths)
+	assert.Equal(t, []string{"a/b/first", "c/second", "other", "third"}, paths)
+
+	// replacing a file by the same content keeps the object
+	_, err = s.Save("other", strings.NewReader("other content"), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, countCASObjects(t, dir))
+
+	assert.NoError(t, s.Delete("a/b/first"))
+	assert.NoError(t, s.Delete("c/second"))
+	assert.Equal(t, 2, countCASObjects(t, dir))
+	assert.NoError(t, s.Delete("third"))
+	assert.Equal(t, 1, countCASObjects(t, dir))
+
+	// replacing the last file of an object by other content removes the object
+	_, err = s.Save("other", strings.NewReader("new content"), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, countCASObjects(t, dir))
+	_, err = s.Stat("third")
+	assert.True(t, os.IsNotExist(err))
+
+	// files stored before the storage was deduplicated are still served
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "plain"), []byte("plain content"), 0o644))
+	obj, err = s.Open("plain")
+	assert.NoError(t, err)
+	content, err = io.ReadAll(obj)
+	assert.NoError(t, err)
+	assert.NoError(t, obj.Close())
+	assert.Equal(t, "plain content", string(content))
+	assert.NoError(t, s.Delete("plain"))
+	assert.Equal(t, 1, countCASObjects(t, dir))
+}
+
+func TestLocalCASStorage_Shared(t *testing.T) {
+	dir := t.TempDir()
+	storages := make([]ObjectStorage, 2)
+	for i := range storages {
+		s, err := NewLocalCASStorage(context.Background(), LocalStorageConfig{Path: dir})
+		assert.NoError(t, err)
+		storages[i] = s
+	}
+
+	// two storages on the same path, e.g. of two processes, keep the counts consistent
+	var wg sync.WaitGroup
+	for i, s := range storages {
+		wg.Add(1)
+		go func(i int, s ObjectStorage) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				p := fmt.Sprintf("%d/%d", i, j)
+				_, err := s.Save(p, strings.NewReader("shared content"), -1)
+				assert.NoError(t, err)
+				if j%2 == 0 {
+					assert.NoError(t, s.Delete(p))
+				}
+			}
+		}(i, s)
+	}
+	wg.Wait()
+	assert.Equal(t, 1, countCASObjects(t, dir))
+
+	for i := range storages {
+		for j := 1; j < 10; j += 2 {
+			assert.NoError(t, storages[0].Delete(fmt.Sprintf("%d/%d", i, j)))
+		}
+	}
+	assert.Equal(t, 0, countCASObjects(t, dir))
+}
@@ -0,0 +1,29 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import "time"
+
+// AuditEvent represents a recorded security relevant action
+type AuditEvent struct {
+	ID int64 `json:"id"`
+	// the kind of action, e.g. `user_signin` or `repo_collaborator_add`
+	Action string `json:"action"`
+	// id of the user who performed the action, 0 if unknown
+	DoerID   int64  `json:"doer_id"`
+	DoerName string `json:"doer_name"`
+	// id of the user or organization the event belongs to
+	OwnerID int64 `json:"owner_id"`
+	RepoID  int64 `json:"repo_id"`
+	// name of the affected user, team, branch or repository
+	Target      string `json:"target"`
+	Description string `json:"description"`
+	IPAddress   string `json:"ip_address"`
+	UserAgent   string `json:"user_agent"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created"`
+	// hash over the event chained to the hash of the preceding event
+	Hash string `json:"hash"`
+}
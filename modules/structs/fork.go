@@ -9,3 +9,25 @@ type CreateForkOption struct {
 	// organization name, if forking into an organization
 	Organization *string `json:"organization"`
 }
+
+// MergeUpstreamOption options for syncing a branch of a fork with its upstream branch
+type MergeUpstreamOption struct {
+	// branch of the fork to sync, its upstream branch is the default branch of the
+	// parent for the default branch and the branch with the same name otherwise
+	// required: true
+	Branch string `json:"branch" binding:"Required"`
+	// how to sync the branch if it has commits of its own, defaults to fast-forward which fails then
+	// enum: fast-forward,merge,rebase
+	Style string `json:"style"`
+}
+
+// MergeUpstreamResult represents the result of syncing a branch of a fork with its upstream branch
+type MergeUpstreamResult struct {
+	// how the branch was synced, empty if it was already up to date
+	// enum: fast-forward,merge,rebase
+	Style string `json:"style"`
+	// the upstream branch in the form owner/repo:branch
+	Upstream string `json:"upstream"`
+	// the commit the branch points to now
+	Commit string `json:"commit"`
+}
@@ -35,6 +35,39 @@ type Hook struct {
 // HookList represents a list of API hook.
 type HookList []*Hook
 
+// HookDelivery represents a delivery of a webhook
+type HookDelivery struct {
+	ID    int64  `json:"id"`
+	UUID  string `json:"uuid"`
+	Event string `json:"event"`
+	// Payload is the body sent to the webhook endpoint
+	Payload string `json:"payload"`
+	// Delivered is false while the delivery is pending or waiting for a retry
+	Delivered bool `json:"delivered"`
+	Succeeded bool `json:"succeeded"`
+	Attempts  int  `json:"attempts"`
+	// swagger:strfmt date-time
+	DeliveredAt *time.Time `json:"delivered_at"`
+	// swagger:strfmt date-time
+	NextRetryAt *time.Time            `json:"next_retry_at"`
+	Request     *HookDeliveryRequest  `json:"request"`
+	Response    *HookDeliveryResponse `json:"response"`
+}
+
+// HookDeliveryRequest represents the request of a webhook delivery
+type HookDeliveryRequest struct {
+	URL     string            `json:"url"`
+	Method  string            `json:"method"`
+	Headers map[string]string `json:"headers"`
+}
+
+// HookDeliveryResponse represents the response of a webhook delivery
+type HookDeliveryResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+}
+
 // CreateHookOptionConfig has all config options in it
 // required are "content_type" and "url" Required
 type CreateHookOptionConfig map[string]string
@@ -42,7 +75,7 @@ type CreateHookOptionConfig map[string]string
 // CreateHookOption options when create a hook
 type CreateHookOption struct {
 	// required: true
-	// enum: dingtalk,discord,gitea,gogs,msteams,slack,telegram,feishu,wechatwork
+	// enum: dingtalk,discord,gitea,gogs,msteams,slack,telegram,feishu,wechatwork,mattermost,rocketchat,ntfy,custom
 	Type string `json:"type" binding:"Required"`
 	// required: true
 	Config       CreateHookOptionConfig `json:"config" binding:"Required"`
@@ -112,6 +145,14 @@ var (
 	_ Payloader = &PullRequestPayload{}
 	_ Payloader = &RepositoryPayload{}
 	_ Payloader = &ReleasePayload{}
+	_ Payloader = &WikiPayload{}
+	_ Payloader = &CommitStatusPayload{}
+	_ Payloader = &MemberPayload{}
+	_ Payloader = &BranchProtectionPayload{}
+	_ Payloader = &StarPayload{}
+	_ Payloader = &UserPayload{}
+	_ Payloader = &OrganizationPayload{}
+	_ Payloader = &LoginFailedPayload{}
 )
 
 // _________                        __
@@ -413,6 +454,16 @@ const (
 	HookRepoCreated HookRepoAction = "created"
 	// HookRepoDeleted deleted
 	HookRepoDeleted HookRepoAction = "deleted"
+	// HookRepoPublicized made public
+	HookRepoPublicized HookRepoAction = "publicized"
+	// HookRepoPrivatized made private
+	HookRepoPrivatized HookRepoAction = "privatized"
+	// HookRepoArchived archived
+	HookRepoArchived HookRepoAction = "archived"
+	// HookRepoUnarchived unarchived
+	HookRepoUnarchived HookRepoAction = "unarchived"
+	// HookRepoTransferred transferred to a new owner
+	HookRepoTransferred HookRepoAction = "transferred"
 )
 
 // RepositoryPayload payload for repository webhooks
@@ -421,9 +472,184 @@ type RepositoryPayload struct {
 	Repository   *Repository    `json:"repository"`
 	Organization *User          `json:"organization"`
 	Sender       *User          `json:"sender"`
+	// PreviousOwner is only set when the repository has been transferred
+	PreviousOwner string `json:"previous_owner,omitempty"`
 }
 
 // JSONPayload JSON representation of the payload
 func (p *RepositoryPayload) JSONPayload() ([]byte, error) {
 	return json.MarshalIndent(p, "", " ")
 }
+
+// HookWikiAction an action that happens to a wiki page
+type HookWikiAction string
+
+const (
+	// HookWikiCreated created
+	HookWikiCreated HookWikiAction = "created"
+	// HookWikiEdited edited
+	HookWikiEdited HookWikiAction = "edited"
+	// HookWikiDeleted deleted
+	HookWikiDeleted HookWikiAction = "deleted"
+)
+
+// WikiPayload payload for wiki webhooks
+type WikiPayload struct {
+	Action     HookWikiAction `json:"action"`
+	Repository *Repository    `json:"repository"`
+	Sender     *User          `json:"sender"`
+	Page       string         `json:"page"`
+	Comment    string         `json:"comment"`
+}
+
+// JSONPayload JSON representation of the payload
+func (p *WikiPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// CommitStatusPayload payload for commit status webhooks
+type CommitStatusPayload struct {
+	SHA         string            `json:"sha"`
+	State       CommitStatusState `json:"state"`
+	Context     string            `json:"context"`
+	Description string            `json:"description"`
+	TargetURL   string            `json:"target_url"`
+	Repository  *Repository       `json:"repository"`
+	Sender      *User             `json:"sender"`
+}
+
+// JSONPayload JSON representation of the payload
+func (p *CommitStatusPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// HookMemberAction an action that happens to a collaborator or team of a repository
+type HookMemberAction string
+
+const (
+	// HookMemberAdded added
+	HookMemberAdded HookMemberAction = "added"
+	// HookMemberEdited permission changed
+	HookMemberEdited HookMemberAction = "edited"
+	// HookMemberRemoved removed
+	HookMemberRemoved HookMemberAction = "removed"
+)
+
+// MemberPayload payload for member webhooks, either Member is set for
+// a collaborator or Team for a team of an organization repository
+type MemberPayload struct {
+	Action     HookMemberAction `json:"action"`
+	Member     *User            `json:"member,omitempty"`
+	Team       *Team            `json:"team,omitempty"`
+	Permission string           `json:"permission,omitempty"`
+	Repository *Repository      `json:"repository"`
+	Sender     *User            `json:"sender"`
+}
+
+// JSONPayload JSON representation of the payload
+func (p *MemberPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// HookBranchProtectionAction an action that happens to a branch protection rule
+type HookBranchProtectionAction string
+
+const (
+	// HookBranchProtectionCreated created
+	HookBranchProtectionCreated HookBranchProtectionAction = "created"
+	// HookBranchProtectionEdited edited
+	HookBranchProtectionEdited HookBranchProtectionAction = "edited"
+	// HookBranchProtectionDeleted deleted
+	HookBranchProtectionDeleted HookBranchProtectionAction = "deleted"
+)
+
+// BranchProtectionPayload payload for branch protection webhooks
+type BranchProtectionPayload struct {
+	Action     HookBranchProtectionAction `json:"action"`
+	Rule       *BranchProtection          `json:"rule"`
+	Repository *Repository                `json:"repository"`
+	Sender     *User                      `json:"sender"`
+}
+
+// JSONPayload JSON representation of the payload
+func (p *BranchProtectionPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// HookStarAction an action that happens to a star of a repository
+type HookStarAction string
+
+const (
+	// HookStarCreated starred
+	HookStarCreated HookStarAction = "created"
+	// HookStarDeleted unstarred
+	HookStarDeleted HookStarAction = "deleted"
+)
+
+// StarPayload payload for star webhooks
+type StarPayload struct {
+	Action     HookStarAction `json:"action"`
+	Repository *Repository    `json:"repository"`
+	Sender     *User          `json:"sender"`
+}
+
+// JSONPayload JSON representation of the payload
+func (p *StarPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// HookUserAction an action that happens to a user account
+type HookUserAction string
+
+const (
+	// HookUserCreated created
+	HookUserCreated HookUserAction = "created"
+	// HookUserDeleted deleted
+	HookUserDeleted HookUserAction = "deleted"
+)
+
+// UserPayload payload for user webhooks, only sent to system webhooks
+type UserPayload struct {
+	Action HookUserAction `json:"action"`
+	User   *User          `json:"user"`
+	Sender *User          `json:"sender"`
+}
+
+// JSONPayload JSON representation of the payload
+func (p *UserPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// HookOrganizationAction an action that happens to an organization
+type HookOrganizationAction string
+
+const (
+	// HookOrganizationCreated created
+	HookOrganizationCreated HookOrganizationAction = "created"
+	// HookOrganizationDeleted deleted
+	HookOrganizationDeleted HookOrganizationAction = "deleted"
+)
+
+// OrganizationPayload payload for organization webhooks, only sent to system webhooks
+type OrganizationPayload struct {
+	Action       HookOrganizationAction `json:"action"`
+	Organization *Organization          `json:"organization"`
+	Sender       *User                  `json:"sender"`
+}
+
+// JSONPayload JSON representation of the payload
+func (p *OrganizationPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// LoginFailedPayload payload for failed sign in attempts, only sent to system webhooks
+type LoginFailedPayload struct {
+	LoginName     string `json:"login_name"`
+	RemoteAddress string `json:"remote_address"`
+	Reason        string `json:"reason"`
+}
+
+// JSONPayload JSON representation of the payload
+func (p *LoginFailedPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
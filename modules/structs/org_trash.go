@@ -0,0 +1,20 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// DeletedOrganization represents an organization in the trash
+type DeletedOrganization struct {
+	Organization *Organization `json:"organization"`
+	DeletedBy    *User         `json:"deleted_by"`
+	// swagger:strfmt date-time
+	Deleted time.Time `json:"deleted_at"`
+	// the time the organization is permanently deleted
+	// swagger:strfmt date-time
+	Purge time.Time `json:"purge_at"`
+}
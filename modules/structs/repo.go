@@ -210,6 +210,12 @@ type GenerateRepoOption struct {
 	Avatar bool `json:"avatar"`
 	// include labels in template repo
 	Labels bool `json:"labels"`
+	// include branch protection rules of the template repo
+	ProtectedBranches bool `json:"protected_branches"`
+	// give the teams with access to the template repo access to the new repo, if both belong to the same organization
+	Teams bool `json:"teams"`
+	// values of the variables declared by the template repo, used with git_content
+	Variables map[string]string `json:"variables"`
 }
 
 // CreateBranchRepoOption options when creating a branch in a repository
@@ -242,13 +248,17 @@ type GitServiceType int
 
 // enumerate all GitServiceType
 const (
-	NotMigrated     GitServiceType = iota // 0 not migrated from external sites
-	PlainGitService                       // 1 plain git service
-	GithubService                         // 2 github.com
-	GiteaService                          // 3 gitea service
-	GitlabService                         // 4 gitlab service
-	GogsService                           // 5 gogs service
-	OneDevService                         // 6 onedev service
+	NotMigrated      GitServiceType = iota // 0 not migrated from external sites
+	PlainGitService                        // 1 plain git service
+	GithubService                          // 2 github.com
+	GiteaService                           // 3 gitea service
+	GitlabService                          // 4 gitlab service
+	GogsService                            // 5 gogs service
+	OneDevService                          // 6 onedev service
+	BitbucketService                       // 7 bitbucket service
+	GitBucketService                       // 8 gitbucket service
+	GiteeService                           // 9 gitee service
+	CodebaseService                        // 10 codebase service
 )
 
 // Name represents the service type's name
@@ -270,6 +280,14 @@ func (gt GitServiceType) Title() string {
 		return "Gogs"
 	case OneDevService:
 		return "OneDev"
+	case BitbucketService:
+		return "Bitbucket"
+	case GitBucketService:
+		return "GitBucket"
+	case GiteeService:
+		return "Gitee"
+	case CodebaseService:
+		return "Codebase"
 	case PlainGitService:
 		return "Git"
 	}
@@ -288,7 +306,7 @@ type MigrateRepoOptions struct {
 	// required: true
 	RepoName string `json:"repo_name" binding:"Required;AlphaDashDot;MaxSize(100)"`
 
-	// enum: git,github,gitea,gitlab
+	// enum: git,github,gitea,gitlab,gogs,onedev,bitbucket,gitbucket,gitee,codebase
 	Service      string `json:"service"`
 	AuthUsername string `json:"auth_username"`
 	AuthPassword string `json:"auth_password"`
@@ -306,6 +324,8 @@ type MigrateRepoOptions struct {
 	PullRequests   bool   `json:"pull_requests"`
 	Releases       bool   `json:"releases"`
 	MirrorInterval string `json:"mirror_interval"`
+	// sync issues, pull requests and releases of a mirror from the original service
+	MirrorMetadata bool `json:"mirror_metadata"`
 }
 
 // TokenAuth represents whether a service type supports token-based auth
@@ -326,5 +346,9 @@ var (
 		GiteaService,
 		GogsService,
 		OneDevService,
+		BitbucketService,
+		GitBucketService,
+		GiteeService,
+		CodebaseService,
 	}
 )
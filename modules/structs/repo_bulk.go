@@ -0,0 +1,89 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// BulkRepoAction represents an operation applied to many repositories at once
+type BulkRepoAction string
+
+// enumerates all bulk repository actions
+const (
+	BulkRepoTransfer   BulkRepoAction = "transfer"
+	BulkRepoArchive    BulkRepoAction = "archive"
+	BulkRepoUnarchive  BulkRepoAction = "unarchive"
+	BulkRepoVisibility BulkRepoAction = "visibility"
+	BulkRepoAddTeam    BulkRepoAction = "add_team"
+	BulkRepoDelete     BulkRepoAction = "delete"
+)
+
+// BulkRepoActions lists all bulk repository actions
+var BulkRepoActions = []BulkRepoAction{
+	BulkRepoTransfer,
+	BulkRepoArchive,
+	BulkRepoUnarchive,
+	BulkRepoVisibility,
+	BulkRepoAddTeam,
+	BulkRepoDelete,
+}
+
+// IsValid returns whether the action is a known bulk repository action
+func (action BulkRepoAction) IsValid() bool {
+	for _, a := range BulkRepoActions {
+		if action == a {
+			return true
+		}
+	}
+	return false
+}
+
+// BulkRepoOption options for applying an operation to many repositories
+type BulkRepoOption struct {
+	// required: true
+	// enum: transfer,archive,unarchive,visibility,add_team,delete
+	Action BulkRepoAction `json:"action" binding:"Required"`
+	// full names of the repositories, e.g. owner/name
+	// required: true
+	Repos []string `json:"repos" binding:"Required"`
+	// name of the new owner, required for transfer
+	NewOwner string `json:"new_owner"`
+	// whether the repositories become private, required for visibility
+	Private *bool `json:"private"`
+	// name of the team to give access, required for add_team. Each repository
+	// must be owned by an organization that has a team with this name
+	Team string `json:"team"`
+}
+
+// BulkRepoResult represents the outcome of a bulk operation for one repository
+type BulkRepoResult struct {
+	Repo    string `json:"repo"`
+	Success bool   `json:"success"`
+	// why the operation failed, or a note like a transfer awaiting acceptance
+	Message string `json:"message"`
+}
+
+// BulkRepoTask represents a bulk repository operation and the results so far
+type BulkRepoTask struct {
+	ID     int64          `json:"id"`
+	Doer   *User          `json:"doer"`
+	Action BulkRepoAction `json:"action"`
+	// enum: queued,running,stopped,failed,finished
+	Status string `json:"status"`
+	// the error that stopped the task if it failed
+	Message  string            `json:"message"`
+	Total    int               `json:"total"`
+	Results  []*BulkRepoResult `json:"results"`
+	NewOwner string            `json:"new_owner,omitempty"`
+	Private  *bool             `json:"private,omitempty"`
+	Team     string            `json:"team,omitempty"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Started *time.Time `json:"started_at"`
+	// swagger:strfmt date-time
+	Finished *time.Time `json:"finished_at"`
+}
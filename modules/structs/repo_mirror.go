@@ -0,0 +1,44 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import "time"
+
+// MirrorSyncStatus represents the status of the syncs of a pull mirror
+type MirrorSyncStatus struct {
+	// the interval of the scheduled syncs, 0s if the mirror is only synced on demand
+	Interval string `json:"interval"`
+	// swagger:strfmt date-time
+	LastSync *time.Time `json:"last_sync"`
+	// duration of the last sync in seconds
+	LastSyncDuration float64 `json:"last_sync_duration"`
+	LastSyncSuccess  bool    `json:"last_sync_success"`
+	LastError        string  `json:"last_error"`
+	// the end of the last successful sync
+	// swagger:strfmt date-time
+	LastUpdate *time.Time `json:"last_update"`
+	// the refs updated by the last sync, at most 100 of num_updated_refs
+	UpdatedRefs    []*MirrorSyncRef `json:"updated_refs"`
+	NumUpdatedRefs int              `json:"num_updated_refs"`
+	// swagger:strfmt date-time
+	NextSync       *time.Time `json:"next_sync"`
+	WebhookEnabled bool       `json:"webhook_enabled"`
+}
+
+// MirrorSyncRef represents a ref updated by the sync of a mirror
+type MirrorSyncRef struct {
+	Ref string `json:"ref"`
+	// empty if the ref was created
+	OldCommitID string `json:"old_commit_id"`
+	// empty if the ref was deleted
+	NewCommitID string `json:"new_commit_id"`
+}
+
+// MirrorWebhookSecret represents the secret for the webhooks syncing a mirror
+type MirrorWebhookSecret struct {
+	Secret string `json:"secret"`
+	// the URL the remote has to send its push webhooks to
+	URL string `json:"url"`
+}
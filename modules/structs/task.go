@@ -10,6 +10,8 @@ type TaskType int
 // all kinds of task types
 const (
 	TaskTypeMigrateRepo TaskType = iota // migrate repository from external or local disk
+	TaskTypeBulkRepo                    // apply an operation to many repositories
+	TaskTypeIssueImport                 // import the issues of an uploaded export into a repository
 )
 
 // Name returns the task type name
@@ -17,6 +19,10 @@ func (taskType TaskType) Name() string {
 	switch taskType {
 	case TaskTypeMigrateRepo:
 		return "Migrate Repository"
+	case TaskTypeBulkRepo:
+		return "Bulk Repository Operation"
+	case TaskTypeIssueImport:
+		return "Issue Import"
 	}
 	return ""
 }
@@ -32,3 +38,20 @@ const (
 	TaskStatusFailed                     // 3 task is failed
 	TaskStatusFinished                   // 4 task is finished
 )
+
+// Name returns the task status name
+func (taskStatus TaskStatus) Name() string {
+	switch taskStatus {
+	case TaskStatusQueue:
+		return "queued"
+	case TaskStatusRunning:
+		return "running"
+	case TaskStatusStopped:
+		return "stopped"
+	case TaskStatusFailed:
+		return "failed"
+	case TaskStatusFinished:
+		return "finished"
+	}
+	return ""
+}
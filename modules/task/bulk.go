@@ -0,0 +1,136 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+	repo_service "code.gitea.io/gitea/services/repository"
+)
+
+// BulkRepoOperation validates a bulk repository operation and adds it to the task queue.
+// If org is nil the operation may touch repositories of any owner and the doer must be
+// a site administrator.
+func BulkRepoOperation(doer, org *models.User, opts structs.BulkRepoOption) (*models.Task, error) {
+	if err := repo_service.ValidateBulkRepoOption(doer, org, &opts); err != nil {
+		return nil, err
+	}
+
+	task := &models.Task{
+		DoerID: doer.ID,
+		Doer:   doer,
+		Type:   structs.TaskTypeBulkRepo,
+		Status: structs.TaskStatusQueue,
+	}
+	if org != nil {
+		task.OwnerID = org.ID
+		task.Owner = org
+	}
+	if err := task.SetBulkRepoPayload(&models.BulkRepoPayload{Options: opts}); err != nil {
+		return nil, err
+	}
+	if err := models.CreateTask(task); err != nil {
+		return nil, err
+	}
+
+	return task, taskQueue.Push(task)
+}
+
+func runBulkRepoTask(t *models.Task) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("PANIC whilst trying to do bulk repository task: %v", e)
+			log.Critical("PANIC during runBulkRepoTask[%d] by DoerID[%d] for OwnerID[%d]: %v\nStacktrace: %v", t.ID, t.DoerID, t.OwnerID, e, log.Stack(2))
+		}
+
+		t.EndTime = timeutil.TimeStampNow()
+		t.Status = structs.TaskStatusFinished
+		if err != nil {
+			t.Status = structs.TaskStatusFailed
+			t.Message = err.Error()
+		}
+		if err := t.UpdateCols("status", "message", "end_time"); err != nil {
+			log.Error("Task UpdateCols failed: %v", err)
+		}
+	}()
+
+	if err = t.LoadDoer(); err != nil {
+		return
+	}
+	var org *models.User
+	if t.OwnerID != 0 {
+		if err = t.LoadOwner(); err != nil {
+			return
+		}
+		org = t.Owner
+	}
+
+	// the doer may have lost the rights since the task was queued
+	if org == nil {
+		if !t.Doer.IsAdmin {
+			return errors.New("only site administrators may operate on repositories of any owner")
+		}
+	} else if !t.Doer.IsAdmin {
+		var isOwner bool
+		if isOwner, err = org.IsOwnedBy(t.DoerID); err != nil {
+			return
+		} else if !isOwner {
+			return fmt.Errorf("%s is no longer an owner of %s", t.Doer.Name, org.Name)
+		}
+	}
+
+	var payload *models.BulkRepoPayload
+	if payload, err = t.BulkRepoPayload(); err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(graceful.GetManager().ShutdownContext())
+	defer cancel()
+	pm := process.GetManager()
+	pid := pm.Add(fmt.Sprintf("BulkRepoTask[%d]: %s", t.ID, payload.Options.Action), cancel)
+	defer pm.Remove(pid)
+
+	t.StartTime = timeutil.TimeStampNow()
+	t.Status = structs.TaskStatusRunning
+	if err = t.UpdateCols("start_time", "status"); err != nil {
+		return
+	}
+
+	// a task that is run again continues with the first repository without a result
+	for _, fullName := range payload.Options.Repos[len(payload.Results):] {
+		select {
+		case <-ctx.Done():
+			return errors.New("task was cancelled before all repositories were processed")
+		default:
+		}
+
+		result := &structs.BulkRepoResult{Repo: fullName, Success: true}
+		note, err := repo_service.ApplyBulkRepoAction(t.Doer, org, &payload.Options, fullName)
+		if err != nil {
+			log.Debug("BulkRepoTask[%d]: %s on %s failed: %v", t.ID, payload.Options.Action, fullName, err)
+			result.Success = false
+			result.Message = err.Error()
+		} else {
+			result.Message = note
+		}
+		payload.Results = append(payload.Results, result)
+
+		if err := t.SetBulkRepoPayload(payload); err != nil {
+			return err
+		}
+		if err := t.UpdateCols("payload_content"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
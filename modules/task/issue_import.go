@@ -0,0 +1,126 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package task
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ImportIssues adds the import of an uploaded issue export into a repository to the task queue.
+// The task deletes the upload when it is done.
+func ImportIssues(doer *models.User, repo *models.Repository, payload *models.IssueImportPayload) (*models.Task, error) {
+	task := &models.Task{
+		DoerID:  doer.ID,
+		Doer:    doer,
+		OwnerID: repo.OwnerID,
+		RepoID:  repo.ID,
+		Repo:    repo,
+		Type:    structs.TaskTypeIssueImport,
+		Status:  structs.TaskStatusQueue,
+	}
+	if err := task.SetIssueImportPayload(payload); err != nil {
+		return nil, err
+	}
+	if err := models.CreateTask(task); err != nil {
+		return nil, err
+	}
+
+	return task, taskQueue.Push(task)
+}
+
+func runIssueImportTask(t *models.Task) (err error) {
+	var payload *models.IssueImportPayload
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("PANIC whilst trying to do issue import task: %v", e)
+			log.Critical("PANIC during runIssueImportTask[%d] by DoerID[%d] for RepoID[%d]: %v\nStacktrace: %v", t.ID, t.DoerID, t.RepoID, e, log.Stack(2))
+		}
+
+		// the export is not needed anymore, whether it was imported or not
+		if payload != nil && payload.UploadUUID != "" {
+			if err := models.DeleteUploadByUUID(payload.UploadUUID); err != nil {
+				log.Error("DeleteUploadByUUID: %v", err)
+			}
+		}
+
+		t.EndTime = timeutil.TimeStampNow()
+		t.Status = structs.TaskStatusFinished
+		if err != nil {
+			t.Status = structs.TaskStatusFailed
+			t.Message = err.Error()
+		}
+		if err := t.UpdateCols("status", "message", "end_time"); err != nil {
+			log.Error("Task UpdateCols failed: %v", err)
+		}
+	}()
+
+	if payload, err = t.IssueImportPayload(); err != nil {
+		return
+	}
+	if err = t.LoadDoer(); err != nil {
+		return
+	}
+	if err = t.LoadRepo(); err != nil {
+		return
+	}
+
+	// the doer may have lost the rights since the task was queued
+	var perm models.Permission
+	if perm, err = models.GetUserRepoPermission(t.Repo, t.Doer); err != nil {
+		return
+	} else if !perm.IsAdmin() {
+		return fmt.Errorf("%s is no longer an administrator of %s", t.Doer.Name, t.Repo.FullName())
+	}
+
+	ctx, cancel := context.WithCancel(graceful.GetManager().ShutdownContext())
+	defer cancel()
+	pm := process.GetManager()
+	pid := pm.Add(fmt.Sprintf("IssueImportTask[%d]: %s into %s", t.ID, payload.FileName, t.Repo.FullName()), cancel)
+	defer pm.Remove(pid)
+
+	t.StartTime = timeutil.TimeStampNow()
+	t.Status = structs.TaskStatusRunning
+	if err = t.UpdateCols("start_time", "status"); err != nil {
+		return
+	}
+
+	var file *os.File
+	if file, err = os.Open(models.UploadLocalPath(payload.UploadUUID)); err != nil {
+		return
+	}
+	defer file.Close()
+
+	var imp *migrations.IssueImport
+	if imp, err = migrations.ParseIssueImport(payload.Format, payload.FileName, file); err != nil {
+		return
+	}
+	payload.NumIssues = len(imp.Issues)
+	if err = t.SetIssueImportPayload(payload); err != nil {
+		return
+	}
+	if err = t.UpdateCols("payload_content"); err != nil {
+		return
+	}
+
+	if err = migrations.ImportIssues(ctx, t.Doer, t.Repo, imp, migrations.IssueImportOptions{
+		ClosedStatuses: payload.ClosedStatuses,
+		LabelFields:    payload.LabelFields,
+	}); err != nil {
+		return
+	}
+
+	log.Trace("Issues imported into repository %-v from %s by %s", t.Repo, payload.FileName, t.Doer.Name)
+	return nil
+}
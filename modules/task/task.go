@@ -29,6 +29,10 @@ func Run(t *models.Task) error {
 	switch t.Type {
 	case structs.TaskTypeMigrateRepo:
 		return runMigrateTask(t)
+	case structs.TaskTypeBulkRepo:
+		return runBulkRepoTask(t)
+	case structs.TaskTypeIssueImport:
+		return runIssueImportTask(t)
 	default:
 		return fmt.Errorf("Unknown task type: %d", t.Type)
 	}
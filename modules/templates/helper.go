@@ -959,6 +959,12 @@ func mirrorRemoteAddress(m models.RemoteMirrorer) remoteAddress {
 		return a
 	}
 
+	// the user of an SSH address is a part of it, authentication is done by key
+	if u.Scheme == "ssh" {
+		a.Address = u.String()
+		return a
+	}
+
 	if u.User != nil {
 		a.Username = u.User.Username()
 		a.Password, _ = u.User.Password()
@@ -0,0 +1,88 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// CreateBulkRepoTask api for applying an operation to many repositories
+func CreateBulkRepoTask(ctx *context.APIContext) {
+	// swagger:operation POST /admin/repos/bulk admin adminCreateBulkRepoTask
+	// ---
+	// summary: Transfer, archive, unarchive, change the visibility of, add a team to or delete many repositories in a background task
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/BulkRepoOption"
+	// responses:
+	//   "202":
+	//     "$ref": "#/responses/BulkRepoTask"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	utils.CreateBulkRepoTask(ctx, nil, web.GetForm(ctx).(*api.BulkRepoOption))
+}
+
+// ListBulkRepoTasks api for listing bulk repository operations
+func ListBulkRepoTasks(ctx *context.APIContext) {
+	// swagger:operation GET /admin/repos/bulk admin adminListBulkRepoTasks
+	// ---
+	// summary: List all bulk repository operations, newest first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/BulkRepoTaskList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	utils.ListBulkRepoTasks(ctx, 0)
+}
+
+// GetBulkRepoTask api for getting the report of a bulk repository operation
+func GetBulkRepoTask(ctx *context.APIContext) {
+	// swagger:operation GET /admin/repos/bulk/{id} admin adminGetBulkRepoTask
+	// ---
+	// summary: Get a bulk repository operation and the result for each repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the task
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/BulkRepoTask"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	utils.GetBulkRepoTask(ctx, 0)
+}
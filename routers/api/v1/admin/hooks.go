@@ -0,0 +1,289 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// ListHooks list system's webhooks
+func ListHooks(ctx *context.APIContext) {
+	// swagger:operation GET /admin/hooks admin adminListHooks
+	// ---
+	// summary: List system's webhooks
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/HookList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	opts := &models.ListWebhookOptions{
+		ListOptions: utils.GetListOptions(ctx),
+		IsSystem:    util.OptionalBoolTrue,
+	}
+
+	count, err := models.CountWebhooksByOpts(opts)
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+
+	sysHooks, err := models.ListWebhooksByOpts(opts)
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+
+	hooks := make([]*api.Hook, len(sysHooks))
+	for i, hook := range sysHooks {
+		hooks[i] = convert.ToHook(setting.AppSubURL+"/admin", hook)
+	}
+
+	ctx.SetTotalCountHeader(count)
+	ctx.JSON(http.StatusOK, hooks)
+}
+
+// GetHook get a system webhook by id
+func GetHook(ctx *context.APIContext) {
+	// swagger:operation GET /admin/hooks/{id} admin adminGetHook
+	// ---
+	// summary: Get a system hook
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the hook to get
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Hook"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	hook, err := utils.GetSystemHook(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToHook(setting.AppSubURL+"/admin", hook))
+}
+
+// CreateHook create a system webhook
+func CreateHook(ctx *context.APIContext) {
+	// swagger:operation POST /admin/hooks admin adminCreateHook
+	// ---
+	// summary: Create a system hook
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CreateHookOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Hook"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateHookOption)
+	if !utils.CheckCreateHookOption(ctx, form) {
+		return
+	}
+	utils.AddSystemHook(ctx, form)
+}
+
+// EditHook modify a system webhook
+func EditHook(ctx *context.APIContext) {
+	// swagger:operation PATCH /admin/hooks/{id} admin adminEditHook
+	// ---
+	// summary: Update a system hook
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the hook to update
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditHookOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Hook"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	form := web.GetForm(ctx).(*api.EditHookOption)
+	utils.EditSystemHook(ctx, form, ctx.ParamsInt64(":id"))
+}
+
+// DeleteHook delete a system webhook
+func DeleteHook(ctx *context.APIContext) {
+	// swagger:operation DELETE /admin/hooks/{id} admin adminDeleteHook
+	// ---
+	// summary: Delete a system hook
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the hook to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	hookID := ctx.ParamsInt64(":id")
+	if _, err := utils.GetSystemHook(ctx, hookID); err != nil {
+		return
+	}
+	if err := models.DeleteDefaultSystemWebhook(hookID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteDefaultSystemWebhook", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListHookDeliveries list the deliveries of a system webhook
+func ListHookDeliveries(ctx *context.APIContext) {
+	// swagger:operation GET /admin/hooks/{id}/deliveries admin adminListHookDeliveries
+	// ---
+	// summary: List the deliveries of a system hook, newest first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the hook
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/HookDeliveryList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	hook, err := utils.GetSystemHook(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	utils.ListHookDeliveries(ctx, hook)
+}
+
+// GetHookDelivery get a delivery of a system webhook
+func GetHookDelivery(ctx *context.APIContext) {
+	// swagger:operation GET /admin/hooks/{id}/deliveries/{delivery_id} admin adminGetHookDelivery
+	// ---
+	// summary: Get a delivery of a system hook
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the hook
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: delivery_id
+	//   in: path
+	//   description: id of the delivery to get
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/HookDelivery"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	hook, err := utils.GetSystemHook(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	t, err := utils.GetHookDelivery(ctx, hook)
+	if err != nil {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToHookDelivery(t))
+}
+
+// RedeliverHook redeliver the payload of a delivery of a system webhook
+func RedeliverHook(ctx *context.APIContext) {
+	// swagger:operation POST /admin/hooks/{id}/deliveries/{delivery_id}/attempts admin adminRedeliverHook
+	// ---
+	// summary: Redeliver the payload of a delivery of a system hook as a new delivery
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the hook
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: delivery_id
+	//   in: path
+	//   description: id of the delivery to redeliver
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "202":
+	//     "$ref": "#/responses/HookDelivery"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	hook, err := utils.GetSystemHook(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	utils.RedeliverHook(ctx, hook)
+}
@@ -11,10 +11,13 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/user"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	org_service "code.gitea.io/gitea/services/org"
 )
 
 // CreateOrg api for create organization
@@ -76,6 +79,7 @@ func CreateOrg(ctx *context.APIContext) {
 		}
 		return
 	}
+	notification.NotifyCreateOrganization(ctx.User, org)
 
 	ctx.JSON(http.StatusCreated, convert.ToOrganization(org))
 }
@@ -124,3 +128,49 @@ func GetAllOrgs(ctx *context.APIContext) {
 	ctx.SetTotalCountHeader(maxResults)
 	ctx.JSON(http.StatusOK, &orgs)
 }
+
+// ListDeletedOrgs api for listing the organizations in the trash
+func ListDeletedOrgs(ctx *context.APIContext) {
+	// swagger:operation GET /admin/orgs/deleted admin adminListDeletedOrgs
+	// ---
+	// summary: List the organizations in the trash, most recently deleted first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/DeletedOrganizationList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	utils.ListDeletedOrgs(ctx, 0)
+}
+
+// EmptyOrgsTrash api for permanently deleting all organizations in the trash
+func EmptyOrgsTrash(ctx *context.APIContext) {
+	// swagger:operation DELETE /admin/orgs/deleted admin adminEmptyOrgsTrash
+	// ---
+	// summary: Permanently delete the organizations in the trash together with their repos in the trash
+	// produces:
+	// - application/json
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	if err := org_service.EmptyTrash(ctx, ctx.User); err != nil {
+		ctx.Error(http.StatusInternalServerError, "EmptyTrash", err)
+		return
+	}
+	log.Trace("Organization trash emptied by %s", ctx.User.Name)
+	ctx.Status(http.StatusNoContent)
+}
@@ -5,11 +5,16 @@
 package admin
 
 import (
+	"net/http"
+
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/repo"
 	"code.gitea.io/gitea/routers/api/v1/user"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	repo_service "code.gitea.io/gitea/services/repository"
 )
 
 // CreateRepo api for creating a repository
@@ -50,3 +55,49 @@ func CreateRepo(ctx *context.APIContext) {
 
 	repo.CreateUserRepo(ctx, owner, *form)
 }
+
+// ListDeletedRepos api for listing the repositories of all owners in the trash
+func ListDeletedRepos(ctx *context.APIContext) {
+	// swagger:operation GET /admin/repos/deleted admin adminListDeletedRepos
+	// ---
+	// summary: List the repos of all owners in the trash, most recently deleted first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/DeletedRepositoryList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	utils.ListDeletedRepos(ctx, 0)
+}
+
+// EmptyTrash api for permanently deleting all repositories in the trash
+func EmptyTrash(ctx *context.APIContext) {
+	// swagger:operation DELETE /admin/repos/deleted admin adminEmptyTrash
+	// ---
+	// summary: Permanently delete the repos of all owners in the trash
+	// produces:
+	// - application/json
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	if err := repo_service.EmptyTrash(ctx, ctx.User); err != nil {
+		ctx.Error(http.StatusInternalServerError, "EmptyTrash", err)
+		return
+	}
+	log.Trace("Trash emptied by %s", ctx.User.Name)
+	ctx.Status(http.StatusNoContent)
+}
@@ -15,11 +15,13 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/password"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/user"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	"code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/mailer"
 )
 
@@ -121,6 +123,8 @@ func CreateUser(ctx *context.APIContext) {
 		return
 	}
 	log.Trace("Account created by admin (%s): %s", ctx.User.Name, u.Name)
+	notification.NotifyCreateUser(ctx.User, u)
+	audit.RecordUser(ctx.Req, ctx.User, u, models.AuditAdminUserCreate, "")
 
 	// Send email notification.
 	if form.SendNotify {
@@ -160,6 +164,7 @@ func EditUser(ctx *context.APIContext) {
 	if ctx.Written() {
 		return
 	}
+	before := *u
 
 	parseLoginSource(ctx, u, form.SourceID, form.LoginName)
 	if ctx.Written() {
@@ -253,6 +258,7 @@ func EditUser(ctx *context.APIContext) {
 		return
 	}
 	log.Trace("Account profile updated by admin (%s): %s", ctx.User.Name, u.Name)
+	audit.RecordUser(ctx.Req, ctx.User, u, models.AuditAdminUserEdit, audit.UserChanges(&before, u))
 
 	ctx.JSON(http.StatusOK, convert.ToUser(u, ctx.User))
 }
@@ -298,6 +304,8 @@ func DeleteUser(ctx *context.APIContext) {
 		return
 	}
 	log.Trace("Account deleted by admin(%s): %s", ctx.User.Name, u.Name)
+	notification.NotifyDeleteUser(ctx.User, u)
+	audit.RecordUser(ctx.Req, ctx.User, u, models.AuditAdminUserDelete, "")
 
 	ctx.Status(http.StatusNoContent)
 }
@@ -445,6 +445,16 @@ func orgAssignment(args ...bool) func(ctx *context.APIContext) {
 				}
 				return
 			}
+
+			// the teams of an organization in the trash are hidden with it
+			org, err := models.GetUserByID(ctx.Org.Team.OrgID)
+			if err != nil {
+				ctx.Error(http.StatusInternalServerError, "GetUserByID", err)
+				return
+			} else if org.IsDeleted() {
+				ctx.NotFound()
+				return
+			}
 		}
 	}
 }
@@ -692,6 +702,7 @@ func Routes(sessioner func(http.Handler) http.Handler) *web.Route {
 
 			m.Combo("/repos").Get(user.ListMyRepos).
 				Post(bind(api.CreateRepoOption{}), repo.Create)
+			m.Get("/repos/deleted", user.ListMyDeletedRepos)
 
 			m.Group("/starred", func() {
 				m.Get("", user.GetMyStarredRepos)
@@ -722,6 +733,13 @@ func Routes(sessioner func(http.Handler) http.Handler) *web.Route {
 
 			m.Post("/migrate", reqToken(), bind(api.MigrateRepoOptions{}), repo.Migrate)
 
+			// the remote of a mirror is verified by the webhook secret instead of a token and permissions
+			m.Post("/{username}/{reponame}/mirror-sync/webhook", repo.MirrorSyncWebhook)
+
+			// repositories in the trash are not found by the repository assignment
+			m.Post("/{username}/{reponame}/restore", reqToken(), repo.Restore)
+			m.Delete("/{username}/{reponame}/purge", reqToken(), repo.Purge)
+
 			m.Group("/{username}/{reponame}", func() {
 				m.Combo("").Get(reqAnyRepoReader(), repo.Get).
 					Delete(reqToken(), reqOwner(), repo.Delete).
@@ -747,8 +765,12 @@ func Routes(sessioner func(http.Handler) http.Handler) *web.Route {
 							Patch(bind(api.EditHookOption{}), repo.EditHook).
 							Delete(repo.DeleteHook)
 						m.Post("/tests", context.RepoRefForAPI, repo.TestHook)
+						m.Get("/deliveries", repo.ListHookDeliveries)
+						m.Get("/deliveries/{delivery_id}", repo.GetHookDelivery)
+						m.Post("/deliveries/{delivery_id}/attempts", repo.RedeliverHook)
 					})
 				}, reqToken(), reqAdmin(), reqWebhooksEnabled())
+				m.Get("/audit", reqToken(), reqAdmin(), repo.ListAuditEvents)
 				m.Group("/collaborators", func() {
 					m.Get("", reqAnyRepoReader(), repo.ListCollaborators)
 					m.Combo("/{collaborator}").Get(reqAnyRepoReader(), repo.IsCollaborator).
@@ -767,6 +789,7 @@ func Routes(sessioner func(http.Handler) http.Handler) *web.Route {
 				m.Get("/archive/*", reqRepoReader(models.UnitTypeCode), repo.GetArchive)
 				m.Combo("/forks").Get(repo.ListForks).
 					Post(reqToken(), reqRepoReader(models.UnitTypeCode), bind(api.CreateForkOption{}), repo.CreateFork)
+				m.Post("/merge-upstream", reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypeCode), context.ReferencesGitRepo(false), bind(api.MergeUpstreamOption{}), repo.MergeUpstream)
 				m.Group("/branches", func() {
 					m.Get("", repo.ListBranches)
 					m.Get("/*", repo.GetBranch)
@@ -908,7 +931,13 @@ func Routes(sessioner func(http.Handler) http.Handler) *web.Route {
 							Delete(reqToken(), reqRepoWriter(models.UnitTypeReleases), repo.DeleteReleaseByTag)
 					})
 				}, reqRepoReader(models.UnitTypeReleases))
-				m.Post("/mirror-sync", reqToken(), reqRepoWriter(models.UnitTypeCode), repo.MirrorSync)
+				m.Group("/mirror-sync", func() {
+					m.Combo("").Get(reqRepoReader(models.UnitTypeCode), repo.GetMirrorSyncStatus).
+						Post(reqToken(), reqRepoWriter(models.UnitTypeCode), repo.MirrorSync)
+					m.Combo("/webhook-secret", reqToken(), reqAdmin()).
+						Put(repo.GenerateMirrorWebhookSecret).
+						Delete(repo.DeleteMirrorWebhookSecret)
+				})
 				m.Get("/editorconfig/{filename}", context.RepoRefForAPI, reqRepoReader(models.UnitTypeCode), repo.GetEditorconfig)
 				m.Group("/pulls", func() {
 					m.Combo("").Get(repo.ListPullRequests).
@@ -989,18 +1018,28 @@ func Routes(sessioner func(http.Handler) http.Handler) *web.Route {
 
 		// Organizations
 		m.Get("/user/orgs", reqToken(), org.ListMyOrgs)
+		m.Get("/user/orgs/deleted", reqToken(), org.ListMyDeletedOrgs)
 		m.Group("/users/{username}/orgs", func() {
 			m.Get("", org.ListUserOrgs)
 			m.Get("/{org}/permissions", reqToken(), org.GetUserOrgsPermissions)
 		})
 		m.Post("/orgs", reqToken(), bind(api.CreateOrgOption{}), org.Create)
 		m.Get("/orgs", org.GetAll)
+		// organizations in the trash are not found by the organization assignment
+		m.Post("/orgs/{org}/restore", reqToken(), org.Restore)
+		m.Delete("/orgs/{org}/purge", reqToken(), org.Purge)
 		m.Group("/orgs/{org}", func() {
 			m.Combo("").Get(org.Get).
 				Patch(reqToken(), reqOrgOwnership(), bind(api.EditOrgOption{}), org.Edit).
 				Delete(reqToken(), reqOrgOwnership(), org.Delete)
 			m.Combo("/repos").Get(user.ListOrgRepos).
 				Post(reqToken(), bind(api.CreateRepoOption{}), repo.CreateOrgRepo)
+			m.Group("/repos/bulk", func() {
+				m.Combo("").Get(org.ListBulkRepoTasks).
+					Post(bind(api.BulkRepoOption{}), org.CreateBulkRepoTask)
+				m.Get("/{id}", org.GetBulkRepoTask)
+			}, reqToken(), reqOrgOwnership())
+			m.Get("/repos/deleted", reqToken(), reqOrgOwnership(), org.ListDeletedRepos)
 			m.Group("/members", func() {
 				m.Get("", org.ListMembers)
 				m.Combo("/{username}").Get(org.IsMember).
@@ -1027,10 +1066,16 @@ func Routes(sessioner func(http.Handler) http.Handler) *web.Route {
 			m.Group("/hooks", func() {
 				m.Combo("").Get(org.ListHooks).
 					Post(bind(api.CreateHookOption{}), org.CreateHook)
-				m.Combo("/{id}").Get(org.GetHook).
-					Patch(bind(api.EditHookOption{}), org.EditHook).
-					Delete(org.DeleteHook)
+				m.Group("/{id}", func() {
+					m.Combo("").Get(org.GetHook).
+						Patch(bind(api.EditHookOption{}), org.EditHook).
+						Delete(org.DeleteHook)
+					m.Get("/deliveries", org.ListHookDeliveries)
+					m.Get("/deliveries/{delivery_id}", org.GetHookDelivery)
+					m.Post("/deliveries/{delivery_id}/attempts", org.RedeliverHook)
+				})
 			}, reqToken(), reqOrgOwnership(), reqWebhooksEnabled())
+			m.Get("/audit", reqToken(), reqOrgOwnership(), org.ListAuditEvents)
 		}, orgAssignment(true))
 		m.Group("/teams/{teamid}", func() {
 			m.Combo("").Get(org.GetTeam).
@@ -1057,6 +1102,21 @@ func Routes(sessioner func(http.Handler) http.Handler) *web.Route {
 				m.Post("/{task}", admin.PostCronTask)
 			})
 			m.Get("/orgs", admin.GetAllOrgs)
+			m.Combo("/orgs/deleted").Get(admin.ListDeletedOrgs).
+				Delete(admin.EmptyOrgsTrash)
+			m.Get("/audit", admin.ListAuditEvents)
+			m.Group("/hooks", func() {
+				m.Combo("").Get(admin.ListHooks).
+					Post(bind(api.CreateHookOption{}), admin.CreateHook)
+				m.Group("/{id}", func() {
+					m.Combo("").Get(admin.GetHook).
+						Patch(bind(api.EditHookOption{}), admin.EditHook).
+						Delete(admin.DeleteHook)
+					m.Get("/deliveries", admin.ListHookDeliveries)
+					m.Get("/deliveries/{delivery_id}", admin.GetHookDelivery)
+					m.Post("/deliveries/{delivery_id}/attempts", admin.RedeliverHook)
+				})
+			}, reqWebhooksEnabled())
 			m.Group("/users", func() {
 				m.Get("", admin.GetAllUsers)
 				m.Post("", bind(api.CreateUserOption{}), admin.CreateUser)
@@ -1072,6 +1132,13 @@ func Routes(sessioner func(http.Handler) http.Handler) *web.Route {
 					m.Post("/repos", bind(api.CreateRepoOption{}), admin.CreateRepo)
 				})
 			})
+			m.Group("/repos/bulk", func() {
+				m.Combo("").Get(admin.ListBulkRepoTasks).
+					Post(bind(api.BulkRepoOption{}), admin.CreateBulkRepoTask)
+				m.Get("/{id}", admin.GetBulkRepoTask)
+			})
+			m.Combo("/repos/deleted").Get(admin.ListDeletedRepos).
+				Delete(admin.EmptyTrash)
 			m.Group("/unadopted", func() {
 				m.Get("", admin.ListUnadoptedRepositories)
 				m.Post("/{username}/{reponame}", admin.AdoptRepository)
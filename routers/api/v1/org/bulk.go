@@ -0,0 +1,103 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// CreateBulkRepoTask api for applying an operation to many repositories of an organization
+func CreateBulkRepoTask(ctx *context.APIContext) {
+	// swagger:operation POST /orgs/{org}/repos/bulk organization orgCreateBulkRepoTask
+	// ---
+	// summary: Transfer, archive, unarchive, change the visibility of, add a team to or delete many repositories of an organization in a background task
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/BulkRepoOption"
+	// responses:
+	//   "202":
+	//     "$ref": "#/responses/BulkRepoTask"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	utils.CreateBulkRepoTask(ctx, ctx.Org.Organization, web.GetForm(ctx).(*api.BulkRepoOption))
+}
+
+// ListBulkRepoTasks api for listing the bulk repository operations of an organization
+func ListBulkRepoTasks(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/repos/bulk organization orgListBulkRepoTasks
+	// ---
+	// summary: List the bulk repository operations of an organization, newest first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/BulkRepoTaskList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	utils.ListBulkRepoTasks(ctx, ctx.Org.Organization.ID)
+}
+
+// GetBulkRepoTask api for getting the report of a bulk repository operation of an organization
+func GetBulkRepoTask(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/repos/bulk/{id} organization orgGetBulkRepoTask
+	// ---
+	// summary: Get a bulk repository operation of an organization and the result for each repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the task
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/BulkRepoTask"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	utils.GetBulkRepoTask(ctx, ctx.Org.Organization.ID)
+}
@@ -201,3 +201,125 @@ func DeleteHook(ctx *context.APIContext) {
 	}
 	ctx.Status(http.StatusNoContent)
 }
+
+// ListHookDeliveries list the deliveries of an organization's hook
+func ListHookDeliveries(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/hooks/{id}/deliveries organization orgListHookDeliveries
+	// ---
+	// summary: List the deliveries of a hook, newest first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the hook
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/HookDeliveryList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	hook, err := utils.GetOrgHook(ctx, ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	utils.ListHookDeliveries(ctx, hook)
+}
+
+// GetHookDelivery get a delivery of an organization's hook
+func GetHookDelivery(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/hooks/{id}/deliveries/{delivery_id} organization orgGetHookDelivery
+	// ---
+	// summary: Get a delivery of a hook
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the hook
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: delivery_id
+	//   in: path
+	//   description: id of the delivery to get
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/HookDelivery"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	hook, err := utils.GetOrgHook(ctx, ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	t, err := utils.GetHookDelivery(ctx, hook)
+	if err != nil {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToHookDelivery(t))
+}
+
+// RedeliverHook redeliver the payload of a delivery of an organization's hook
+func RedeliverHook(ctx *context.APIContext) {
+	// swagger:operation POST /orgs/{org}/hooks/{id}/deliveries/{delivery_id}/attempts organization orgRedeliverHook
+	// ---
+	// summary: Redeliver the payload of a delivery of a hook as a new delivery
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the hook
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: delivery_id
+	//   in: path
+	//   description: id of the delivery to redeliver
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "202":
+	//     "$ref": "#/responses/HookDelivery"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	hook, err := utils.GetOrgHook(ctx, ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	utils.RedeliverHook(ctx, hook)
+}
@@ -11,11 +11,15 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/user"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	"code.gitea.io/gitea/services/audit"
+	org_service "code.gitea.io/gitea/services/org"
 )
 
 func listUserOrgs(ctx *context.APIContext, u *models.User) {
@@ -273,6 +277,7 @@ func Create(ctx *context.APIContext) {
 		}
 		return
 	}
+	notification.NotifyCreateOrganization(ctx.User, org)
 
 	ctx.JSON(http.StatusCreated, convert.ToOrganization(org))
 }
@@ -363,10 +368,49 @@ func Delete(ctx *context.APIContext) {
 	// responses:
 	//   "204":
 	//     "$ref": "#/responses/empty"
+	//   "409":
+	//     "$ref": "#/responses/error"
 
-	if err := models.DeleteOrganization(ctx.Org.Organization); err != nil {
-		ctx.Error(http.StatusInternalServerError, "DeleteOrganization", err)
+	org := ctx.Org.Organization
+	if err := org_service.DeleteOrganization(ctx.User, org); err != nil {
+		if models.IsErrUserOwnRepos(err) {
+			ctx.Error(http.StatusConflict, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "DeleteOrganization", err)
+		}
 		return
 	}
+	audit.RecordUser(ctx.Req, ctx.User, org, models.AuditOrgDelete, "")
+	log.Trace("Organization deleted: %s", org.Name)
 	ctx.Status(http.StatusNoContent)
 }
+
+// ListDeletedRepos list the repositories of an organization in the trash
+func ListDeletedRepos(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/repos/deleted organization orgListDeletedRepos
+	// ---
+	// summary: List an organization's repos in the trash, most recently deleted first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/DeletedRepositoryList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	utils.ListDeletedRepos(ctx, ctx.Org.Organization.ID)
+}
@@ -16,6 +16,8 @@ import (
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/user"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	"code.gitea.io/gitea/services/audit"
+	repo_service "code.gitea.io/gitea/services/repository"
 )
 
 // ListTeams list all the teams of an organization
@@ -185,7 +187,7 @@ func CreateTeam(ctx *context.APIContext) {
 		team.Units = units
 	}
 
-	if err := models.NewTeam(team); err != nil {
+	if err := repo_service.NewTeam(ctx.User, team); err != nil {
 		if models.IsErrTeamAlreadyExist(err) {
 			ctx.Error(http.StatusUnprocessableEntity, "", err)
 		} else {
@@ -271,10 +273,11 @@ func EditTeam(ctx *context.APIContext) {
 		}
 	}
 
-	if err := models.UpdateTeam(team, isAuthChanged, isIncludeAllChanged); err != nil {
+	if err := repo_service.UpdateTeam(ctx.User, team, isAuthChanged, isIncludeAllChanged); err != nil {
 		ctx.Error(http.StatusInternalServerError, "EditTeam", err)
 		return
 	}
+	audit.RecordTeam(ctx.Req, ctx.User, team, models.AuditOrgTeamEdit, "", "permission "+team.Authorize.String())
 	ctx.JSON(http.StatusOK, convert.ToTeam(team))
 }
 
@@ -425,6 +428,7 @@ func AddTeamMember(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "AddMember", err)
 		return
 	}
+	audit.RecordTeam(ctx.Req, ctx.User, ctx.Org.Team, models.AuditOrgTeamMemberAdd, u.Name, "team "+ctx.Org.Team.Name)
 	ctx.Status(http.StatusNoContent)
 }
 
@@ -462,6 +466,7 @@ func RemoveTeamMember(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "RemoveMember", err)
 		return
 	}
+	audit.RecordTeam(ctx.Req, ctx.User, ctx.Org.Team, models.AuditOrgTeamMemberRemove, u.Name, "team "+ctx.Org.Team.Name)
 	ctx.Status(http.StatusNoContent)
 }
 
@@ -564,10 +569,11 @@ func AddTeamRepository(ctx *context.APIContext) {
 		ctx.Error(http.StatusForbidden, "", "Must have admin-level access to the repository")
 		return
 	}
-	if err := ctx.Org.Team.AddRepository(repo); err != nil {
+	if err := repo_service.AddTeamRepository(ctx.User, ctx.Org.Team, repo); err != nil {
 		ctx.Error(http.StatusInternalServerError, "AddRepository", err)
 		return
 	}
+	audit.RecordRepo(ctx.Req, ctx.User, repo, models.AuditRepoTeamAdd, ctx.Org.Team.Name, "")
 	ctx.Status(http.StatusNoContent)
 }
 
@@ -614,10 +620,11 @@ func RemoveTeamRepository(ctx *context.APIContext) {
 		ctx.Error(http.StatusForbidden, "", "Must have admin-level access to the repository")
 		return
 	}
-	if err := ctx.Org.Team.RemoveRepository(repo.ID); err != nil {
+	if err := repo_service.RemoveTeamRepository(ctx.User, ctx.Org.Team, repo); err != nil {
 		ctx.Error(http.StatusInternalServerError, "RemoveRepository", err)
 		return
 	}
+	audit.RecordRepo(ctx.Req, ctx.User, repo, models.AuditRepoTeamRemove, ctx.Org.Team.Name, "")
 	ctx.Status(http.StatusNoContent)
 }
 
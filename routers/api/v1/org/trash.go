@@ -0,0 +1,131 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	"code.gitea.io/gitea/services/audit"
+	org_service "code.gitea.io/gitea/services/org"
+)
+
+// deletedOrgAssignment returns the organization in the trash given by the path
+// if the doer may restore it, otherwise it responds with not found
+func deletedOrgAssignment(ctx *context.APIContext) *models.User {
+	org, err := models.GetDeletedOrgByName(ctx.Params(":org"))
+	if err != nil {
+		if models.IsErrOrgNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetDeletedOrgByName", err)
+		}
+		return nil
+	}
+
+	if canManage, err := org_service.CanManageDeletedOrganization(ctx.User, org); err != nil {
+		ctx.Error(http.StatusInternalServerError, "CanManageDeletedOrganization", err)
+		return nil
+	} else if !canManage {
+		ctx.NotFound()
+		return nil
+	}
+	return org
+}
+
+// ListMyDeletedOrgs list the organizations in the trash the authenticated user owns
+func ListMyDeletedOrgs(ctx *context.APIContext) {
+	// swagger:operation GET /user/orgs/deleted organization orgListCurrentUserDeletedOrgs
+	// ---
+	// summary: List the organizations in the trash the authenticated user owns, most recently deleted first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/DeletedOrganizationList"
+
+	utils.ListDeletedOrgs(ctx, ctx.User.ID)
+}
+
+// Restore restores an organization from the trash
+func Restore(ctx *context.APIContext) {
+	// swagger:operation POST /orgs/{org}/restore organization orgRestore
+	// ---
+	// summary: Restore a deleted organization from the trash
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization to restore
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Organization"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	org := deletedOrgAssignment(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.RestoreOrganization(org); err != nil {
+		ctx.Error(http.StatusInternalServerError, "RestoreOrganization", err)
+		return
+	}
+	audit.RecordUser(ctx.Req, ctx.User, org, models.AuditOrgRestore, "")
+	log.Trace("Organization restored: %s", org.Name)
+
+	ctx.JSON(http.StatusOK, convert.ToOrganization(org))
+}
+
+// Purge permanently deletes an organization in the trash
+func Purge(ctx *context.APIContext) {
+	// swagger:operation DELETE /orgs/{org}/purge organization orgPurge
+	// ---
+	// summary: Permanently delete an organization in the trash together with its repos in the trash
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization to delete
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	org := deletedOrgAssignment(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := org_service.PurgeOrganization(ctx.User, org); err != nil {
+		ctx.Error(http.StatusInternalServerError, "PurgeOrganization", err)
+		return
+	}
+	audit.RecordUser(ctx.Req, ctx.User, org, models.AuditOrgPurge, "")
+	log.Trace("Organization purged: %s", org.Name)
+
+	ctx.Status(http.StatusNoContent)
+}
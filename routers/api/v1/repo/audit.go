@@ -0,0 +1,69 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// ListAuditEvents list the audit log of a repository
+func ListAuditEvents(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/audit repository repoListAuditEvents
+	// ---
+	// summary: List the audit log of a repository, newest first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: action
+	//   in: query
+	//   description: only show events of these actions
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	// - name: user
+	//   in: query
+	//   description: only show events caused by this user
+	//   type: string
+	// - name: since
+	//   in: query
+	//   description: Only show events created after the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// - name: before
+	//   in: query
+	//   description: Only show events created before the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/AuditEventList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	utils.ListAuditEvents(ctx, &models.FindAuditEventsOptions{RepoID: ctx.Repo.Repository.ID})
+}
@@ -18,6 +18,7 @@ import (
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	"code.gitea.io/gitea/services/audit"
 	pull_service "code.gitea.io/gitea/services/pull"
 	repo_service "code.gitea.io/gitea/services/repository"
 )
@@ -502,7 +503,7 @@ func CreateBranchProtection(ctx *context.APIContext) {
 		BlockOnOutdatedBranch:         form.BlockOnOutdatedBranch,
 	}
 
-	err = models.UpdateProtectBranch(ctx.Repo.Repository, protectBranch, models.WhitelistOptions{
+	err = repo_service.UpdateBranchProtection(ctx.User, ctx.Repo.Repository, protectBranch, models.WhitelistOptions{
 		UserIDs:          whitelistUsers,
 		TeamIDs:          whitelistTeams,
 		MergeUserIDs:     mergeWhitelistUsers,
@@ -531,6 +532,8 @@ func CreateBranchProtection(ctx *context.APIContext) {
 		return
 	}
 
+	audit.RecordRepo(ctx.Req, ctx.User, ctx.Repo.Repository, models.AuditRepoBranchProtectionAdd, bp.BranchName, "")
+
 	ctx.JSON(http.StatusCreated, convert.ToBranchProtection(bp))
 
 }
@@ -738,7 +741,7 @@ func EditBranchProtection(ctx *context.APIContext) {
 		}
 	}
 
-	err = models.UpdateProtectBranch(ctx.Repo.Repository, protectBranch, models.WhitelistOptions{
+	err = repo_service.UpdateBranchProtection(ctx.User, ctx.Repo.Repository, protectBranch, models.WhitelistOptions{
 		UserIDs:          whitelistUsers,
 		TeamIDs:          whitelistTeams,
 		MergeUserIDs:     mergeWhitelistUsers,
@@ -767,6 +770,8 @@ func EditBranchProtection(ctx *context.APIContext) {
 		return
 	}
 
+	audit.RecordRepo(ctx.Req, ctx.User, ctx.Repo.Repository, models.AuditRepoBranchProtectionEdit, bp.BranchName, "")
+
 	ctx.JSON(http.StatusOK, convert.ToBranchProtection(bp))
 }
 
@@ -811,10 +816,12 @@ func DeleteBranchProtection(ctx *context.APIContext) {
 		return
 	}
 
-	if err := ctx.Repo.Repository.DeleteProtectedBranch(bp.ID); err != nil {
+	if err := repo_service.DeleteBranchProtection(ctx.User, ctx.Repo.Repository, bp); err != nil {
 		ctx.Error(http.StatusInternalServerError, "DeleteProtectedBranch", err)
 		return
 	}
 
+	audit.RecordRepo(ctx.Req, ctx.User, ctx.Repo.Repository, models.AuditRepoBranchProtectionRemove, bp.BranchName, "")
+
 	ctx.Status(http.StatusNoContent)
 }
@@ -15,6 +15,8 @@ import (
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	"code.gitea.io/gitea/services/audit"
+	repo_service "code.gitea.io/gitea/services/repository"
 )
 
 // ListCollaborators list a repository's collaborators
@@ -170,16 +172,28 @@ func AddCollaborator(ctx *context.APIContext) {
 		return
 	}
 
-	if err := ctx.Repo.Repository.AddCollaborator(collaborator); err != nil {
-		ctx.Error(http.StatusInternalServerError, "AddCollaborator", err)
+	isCollaborator, err := ctx.Repo.Repository.IsCollaborator(collaborator.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "IsCollaborator", err)
 		return
 	}
 
+	mode := models.AccessModeNone
 	if form.Permission != nil {
-		if err := ctx.Repo.Repository.ChangeCollaborationAccessMode(collaborator.ID, models.ParseAccessMode(*form.Permission)); err != nil {
-			ctx.Error(http.StatusInternalServerError, "ChangeCollaborationAccessMode", err)
-			return
-		}
+		mode = models.ParseAccessMode(*form.Permission)
+	}
+	if err := repo_service.AddCollaborator(ctx.User, ctx.Repo.Repository, collaborator, mode); err != nil {
+		ctx.Error(http.StatusInternalServerError, "AddCollaborator", err)
+		return
+	}
+
+	switch {
+	case !isCollaborator && form.Permission != nil:
+		audit.RecordRepo(ctx.Req, ctx.User, ctx.Repo.Repository, models.AuditRepoCollaboratorAdd, collaborator.Name, "access mode "+mode.String())
+	case !isCollaborator:
+		audit.RecordRepo(ctx.Req, ctx.User, ctx.Repo.Repository, models.AuditRepoCollaboratorAdd, collaborator.Name, "")
+	case form.Permission != nil:
+		audit.RecordRepo(ctx.Req, ctx.User, ctx.Repo.Repository, models.AuditRepoCollaboratorEdit, collaborator.Name, "access mode "+mode.String())
 	}
 
 	ctx.Status(http.StatusNoContent)
@@ -224,10 +238,11 @@ func DeleteCollaborator(ctx *context.APIContext) {
 		return
 	}
 
-	if err := ctx.Repo.Repository.DeleteCollaboration(collaborator.ID); err != nil {
+	if err := repo_service.DeleteCollaboration(ctx.User, ctx.Repo.Repository, collaborator); err != nil {
 		ctx.Error(http.StatusInternalServerError, "DeleteCollaboration", err)
 		return
 	}
+	audit.RecordRepo(ctx.Req, ctx.User, ctx.Repo.Repository, models.AuditRepoCollaboratorRemove, collaborator.Name, "")
 	ctx.Status(http.StatusNoContent)
 }
 
@@ -12,9 +12,11 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/git"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	pull_service "code.gitea.io/gitea/services/pull"
 	repo_service "code.gitea.io/gitea/services/repository"
 )
 
@@ -136,3 +138,103 @@ func CreateFork(ctx *context.APIContext) {
 	//TODO change back to 201
 	ctx.JSON(http.StatusAccepted, convert.ToRepo(fork, models.AccessModeOwner))
 }
+
+// MergeUpstream syncs a branch of a fork with its upstream branch
+func MergeUpstream(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/merge-upstream repository repoMergeUpstream
+	// ---
+	// summary: Sync a branch of a fork with its upstream branch
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the fork
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the fork
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/MergeUpstreamOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/MergeUpstreamResult"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.MergeUpstreamOption)
+	repo := ctx.Repo.Repository
+
+	style := pull_service.MergeUpstreamStyle(form.Style)
+	if style == "" {
+		style = pull_service.MergeUpstreamFastForward
+	}
+	if !style.IsValid() {
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("invalid style %q", form.Style))
+		return
+	}
+	if !repo.IsFork {
+		ctx.Error(http.StatusUnprocessableEntity, "", "repository is not a fork")
+		return
+	}
+	if err := repo.GetBaseRepo(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetBaseRepo", err)
+		return
+	}
+	perm, err := models.GetUserRepoPermission(repo.BaseRepo, ctx.User)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetUserRepoPermission", err)
+		return
+	}
+	if !perm.CanRead(models.UnitTypeCode) {
+		ctx.Error(http.StatusForbidden, "", "no permission to read the upstream repository")
+		return
+	}
+
+	mergedStyle, err := pull_service.MergeUpstream(ctx.User, repo, form.Branch, style)
+	if err != nil {
+		switch {
+		case models.IsErrBranchDoesNotExist(err):
+			ctx.NotFound(err)
+		case models.IsErrForkBranchDiverged(err), models.IsErrMergeConflicts(err), models.IsErrRebaseConflicts(err):
+			ctx.Error(http.StatusConflict, "MergeUpstream", err)
+		case git.IsErrPushOutOfDate(err):
+			ctx.Error(http.StatusConflict, "MergeUpstream", "branch was updated while syncing")
+		case git.IsErrPushRejected(err):
+			ctx.Error(http.StatusConflict, "MergeUpstream", err.(*git.ErrPushRejected).Message)
+		default:
+			ctx.Error(http.StatusInternalServerError, "MergeUpstream", err)
+		}
+		return
+	}
+
+	upstreamBranch, err := pull_service.GetUpstreamBranch(repo, form.Branch)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetUpstreamBranch", err)
+		return
+	}
+	commitID, err := ctx.Repo.GitRepo.GetBranchCommitID(form.Branch)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetBranchCommitID", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &api.MergeUpstreamResult{
+		Style:    string(mergedStyle),
+		Upstream: repo.BaseRepo.FullName() + ":" + upstreamBranch,
+		Commit:   commitID,
+	})
+}
@@ -807,6 +807,10 @@ func EditIssue(ctx *context.APIContext) {
 			ctx.Error(http.StatusPreconditionFailed, "DependenciesLeft", "cannot close this issue because it still has open dependencies")
 			return
 		}
+		if models.IsErrPullHeadRepoInTrash(err) {
+			ctx.Error(http.StatusPreconditionFailed, "PullHeadRepoInTrash", "cannot reopen this pull request because its head repository is in the trash")
+			return
+		}
 		ctx.Error(http.StatusInternalServerError, "UpdateIssueByAPI", err)
 		return
 	}
@@ -61,6 +61,9 @@ func Migrate(ctx *context.APIContext) {
 		repoOwner, err = models.GetUserByName(form.RepoOwner)
 	} else if form.RepoOwnerID != 0 {
 		repoOwner, err = models.GetUserByID(form.RepoOwnerID)
+		if err == nil && repoOwner.IsDeleted() {
+			err = models.ErrUserNotExist{UID: form.RepoOwnerID}
+		}
 	} else {
 		repoOwner = ctx.User
 	}
@@ -153,8 +156,9 @@ func Migrate(ctx *context.APIContext) {
 		Releases:       form.Releases,
 		GitServiceType: gitServiceType,
 		MirrorInterval: form.MirrorInterval,
+		MirrorMetadata: form.Mirror && form.MirrorMetadata,
 	}
-	if opts.Mirror {
+	if opts.Mirror && !opts.MirrorMetadata {
 		opts.Issues = false
 		opts.Milestones = false
 		opts.Labels = false
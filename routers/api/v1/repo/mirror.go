@@ -9,7 +9,9 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
 	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
 	mirror_service "code.gitea.io/gitea/services/mirror"
 )
 
@@ -52,3 +54,207 @@ func MirrorSync(ctx *context.APIContext) {
 
 	ctx.Status(http.StatusOK)
 }
+
+// getRepoMirror returns the mirror of the repository or responds with not found
+func getRepoMirror(ctx *context.APIContext) *models.Mirror {
+	if !ctx.Repo.Repository.IsMirror {
+		ctx.NotFound()
+		return nil
+	}
+	m, err := models.GetMirrorByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		if err == models.ErrMirrorNotExist {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetMirrorByRepoID", err)
+		}
+		return nil
+	}
+	return m
+}
+
+// GetMirrorSyncStatus returns the status of the syncs of a mirrored repository
+func GetMirrorSyncStatus(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/mirror-sync repository repoGetMirrorSyncStatus
+	// ---
+	// summary: Get the sync status of a mirrored repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/MirrorSyncStatus"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	m := getRepoMirror(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToMirrorSyncStatus(m))
+}
+
+// MirrorSyncWebhook adds a mirrored repository to the sync queue on a push webhook of its remote
+func MirrorSyncWebhook(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/mirror-sync/webhook repository repoMirrorSyncWebhook
+	// ---
+	// summary: Sync a mirrored repository on a webhook of its remote
+	// description: The webhook does not need a token, but is verified with the webhook secret of the mirror.
+	//   The payload must be signed like webhooks of Gitea, Gogs, GitHub or Bitbucket Server, or the secret
+	//   must be sent as X-Gitlab-Token header like webhooks of GitLab.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo to sync
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo to sync
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if !setting.Mirror.Enabled {
+		ctx.Error(http.StatusBadRequest, "MirrorSyncWebhook", "Mirror feature is disabled")
+		return
+	}
+
+	repo, err := models.GetRepositoryByOwnerAndName(ctx.Params(":username"), ctx.Params(":reponame"))
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetRepositoryByOwnerAndName", err)
+		}
+		return
+	}
+	if !repo.IsMirror {
+		ctx.NotFound()
+		return
+	}
+	m, err := models.GetMirrorByRepoID(repo.ID)
+	if err != nil {
+		if err == models.ErrMirrorNotExist {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetMirrorByRepoID", err)
+		}
+		return
+	}
+	// mirrors without a secret are not revealed
+	if !m.HasWebhook() {
+		ctx.NotFound()
+		return
+	}
+
+	if !mirror_service.VerifyWebhook(m, ctx.Req.Header, ctx.Req.Body) {
+		ctx.Error(http.StatusForbidden, "MirrorSyncWebhook", "Invalid webhook signature")
+		return
+	}
+
+	mirror_service.StartToMirror(repo.ID)
+
+	ctx.Status(http.StatusOK)
+}
+
+// GenerateMirrorWebhookSecret sets a new secret for the webhooks syncing a mirrored repository
+func GenerateMirrorWebhookSecret(ctx *context.APIContext) {
+	// swagger:operation PUT /repos/{owner}/{repo}/mirror-sync/webhook-secret repository repoGenerateMirrorWebhookSecret
+	// ---
+	// summary: Generate a new secret for the webhooks syncing a mirrored repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/MirrorWebhookSecret"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	m := getRepoMirror(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := mirror_service.GenerateWebhookSecret(m); err != nil {
+		ctx.Error(http.StatusInternalServerError, "GenerateWebhookSecret", err)
+		return
+	}
+	webhookSecret, err := mirror_service.GetWebhookSecret(m)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetWebhookSecret", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &api.MirrorWebhookSecret{
+		Secret: webhookSecret,
+		URL:    ctx.Repo.Repository.APIURL() + "/mirror-sync/webhook",
+	})
+}
+
+// DeleteMirrorWebhookSecret removes the secret of a mirrored repository, refusing all webhooks
+func DeleteMirrorWebhookSecret(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/mirror-sync/webhook-secret repository repoDeleteMirrorWebhookSecret
+	// ---
+	// summary: Delete the webhook secret of a mirrored repository, which disables its webhooks
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	m := getRepoMirror(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := mirror_service.RemoveWebhookSecret(m); err != nil {
+		ctx.Error(http.StatusInternalServerError, "RemoveWebhookSecret", err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
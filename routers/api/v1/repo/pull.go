@@ -576,6 +576,10 @@ func EditPullRequest(ctx *context.APIContext) {
 			ctx.Error(http.StatusPreconditionFailed, "DependenciesLeft", "cannot close this pull request because it still has open dependencies")
 			return
 		}
+		if models.IsErrPullHeadRepoInTrash(err) {
+			ctx.Error(http.StatusPreconditionFailed, "PullHeadRepoInTrash", "cannot reopen this pull request because its head repository is in the trash")
+			return
+		}
 		ctx.Error(http.StatusInternalServerError, "UpdateIssueByAPI", err)
 		return
 	}
@@ -12,6 +12,8 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
 	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/services/audit"
+	repo_service "code.gitea.io/gitea/services/repository"
 )
 
 // ListTeams list a repository's teams
@@ -203,19 +205,25 @@ func changeRepoTeam(ctx *context.APIContext, add bool) {
 			ctx.Error(http.StatusUnprocessableEntity, "alreadyAdded", fmt.Errorf("team '%s' is already added to repo", team.Name))
 			return
 		}
-		err = team.AddRepository(ctx.Repo.Repository)
+		err = repo_service.AddTeamRepository(ctx.User, team, ctx.Repo.Repository)
 	} else {
 		if !repoHasTeam {
 			ctx.Error(http.StatusUnprocessableEntity, "notAdded", fmt.Errorf("team '%s' was not added to repo", team.Name))
 			return
 		}
-		err = team.RemoveRepository(ctx.Repo.Repository.ID)
+		err = repo_service.RemoveTeamRepository(ctx.User, team, ctx.Repo.Repository)
 	}
 	if err != nil {
 		ctx.InternalServerError(err)
 		return
 	}
 
+	if add {
+		audit.RecordRepo(ctx.Req, ctx.User, ctx.Repo.Repository, models.AuditRepoTeamAdd, team.Name, "")
+	} else {
+		audit.RecordRepo(ctx.Req, ctx.User, ctx.Repo.Repository, models.AuditRepoTeamRemove, team.Name, "")
+	}
+
 	ctx.Status(http.StatusNoContent)
 }
 
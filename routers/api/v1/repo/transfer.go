@@ -14,6 +14,7 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/audit"
 	repo_service "code.gitea.io/gitea/services/repository"
 )
 
@@ -95,6 +96,8 @@ func Transfer(ctx *context.APIContext) {
 		}
 	}
 
+	// keep the original owner for the audit log, a direct transfer changes it
+	oldRepo := *ctx.Repo.Repository
 	if err := repo_service.StartRepositoryTransfer(ctx.User, newOwner, ctx.Repo.Repository, teams); err != nil {
 		if models.IsErrRepoTransferInProgress(err) {
 			ctx.Error(http.StatusConflict, "CreatePendingRepositoryTransfer", err)
@@ -109,6 +112,7 @@ func Transfer(ctx *context.APIContext) {
 		ctx.InternalServerError(err)
 		return
 	}
+	audit.RecordRepo(ctx.Req, ctx.User, &oldRepo, models.AuditRepoTransfer, "", "to "+newOwner.Name)
 
 	if ctx.Repo.Repository.Status == models.RepositoryPendingTransfer {
 		log.Trace("Repository transfer initiated: %s -> %s", ctx.Repo.Repository.FullName(), newOwner.Name)
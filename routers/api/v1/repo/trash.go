@@ -0,0 +1,123 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/services/audit"
+	repo_service "code.gitea.io/gitea/services/repository"
+)
+
+// deletedRepoAssignment returns the repository in the trash given by the path
+// if the doer may restore it, otherwise it responds with not found
+func deletedRepoAssignment(ctx *context.APIContext) *models.Repository {
+	repo, err := models.GetDeletedRepositoryByOwnerAndName(ctx.Params(":username"), ctx.Params(":reponame"))
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetDeletedRepositoryByOwnerAndName", err)
+		}
+		return nil
+	}
+
+	if canManage, err := repo_service.CanManageDeletedRepository(ctx.User, repo); err != nil {
+		ctx.Error(http.StatusInternalServerError, "CanManageDeletedRepository", err)
+		return nil
+	} else if !canManage {
+		ctx.NotFound()
+		return nil
+	}
+	return repo
+}
+
+// Restore restores a repository from the trash
+func Restore(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/restore repository repoRestore
+	// ---
+	// summary: Restore a deleted repository from the trash
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo to restore
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo to restore
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Repository"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+
+	repo := deletedRepoAssignment(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := repo_service.RestoreRepository(repo); err != nil {
+		if models.IsErrForkAlreadyExist(err) || models.IsErrOrgInTrash(err) {
+			ctx.Error(http.StatusConflict, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "RestoreRepository", err)
+		}
+		return
+	}
+	audit.RecordRepo(ctx.Req, ctx.User, repo, models.AuditRepoRestore, "", "")
+	log.Trace("Repository restored: %s", repo.FullName())
+
+	ctx.JSON(http.StatusOK, convert.ToRepo(repo, models.AccessModeOwner))
+}
+
+// Purge permanently deletes a repository in the trash
+func Purge(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/purge repository repoPurge
+	// ---
+	// summary: Permanently delete a repository in the trash
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo to delete
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo to delete
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	repo := deletedRepoAssignment(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := repo_service.PurgeRepository(ctx.User, repo); err != nil {
+		ctx.Error(http.StatusInternalServerError, "PurgeRepository", err)
+		return
+	}
+	audit.RecordRepo(ctx.Req, ctx.User, repo, models.AuditRepoPurge, "", "")
+	log.Trace("Repository purged: %s", repo.FullName())
+
+	ctx.Status(http.StatusNoContent)
+}
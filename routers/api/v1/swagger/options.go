@@ -88,7 +88,11 @@ type swaggerParameterBodies struct {
 	// in:body
 	CreateForkOption api.CreateForkOption
 	// in:body
+	MergeUpstreamOption api.MergeUpstreamOption
+	// in:body
 	GenerateRepoOption api.GenerateRepoOption
+	// in:body
+	BulkRepoOption api.BulkRepoOption
 
 	// in:body
 	CreateStatusOption api.CreateStatusOption
@@ -22,6 +22,13 @@ type swaggerResponseOrganizationList struct {
 	Body []api.Organization `json:"body"`
 }
 
+// DeletedOrganizationList
+// swagger:response DeletedOrganizationList
+type swaggerResponseDeletedOrganizationList struct {
+	// in:body
+	Body []api.DeletedOrganization `json:"body"`
+}
+
 // Team
 // swagger:response Team
 type swaggerResponseTeam struct {
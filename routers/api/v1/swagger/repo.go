@@ -99,6 +99,20 @@ type swaggerResponseHookList struct {
 	Body []api.Hook `json:"body"`
 }
 
+// HookDelivery
+// swagger:response HookDelivery
+type swaggerResponseHookDelivery struct {
+	// in:body
+	Body api.HookDelivery `json:"body"`
+}
+
+// HookDeliveryList
+// swagger:response HookDeliveryList
+type swaggerResponseHookDeliveryList struct {
+	// in:body
+	Body []api.HookDelivery `json:"body"`
+}
+
 // GitHook
 // swagger:response GitHook
 type swaggerResponseGitHook struct {
@@ -344,3 +358,45 @@ type swaggerWikiCommitList struct {
 	// in:body
 	Body api.WikiCommitList `json:"body"`
 }
+
+// MergeUpstreamResult
+// swagger:response MergeUpstreamResult
+type swaggerResponseMergeUpstreamResult struct {
+	// in:body
+	Body api.MergeUpstreamResult `json:"body"`
+}
+
+// MirrorSyncStatus
+// swagger:response MirrorSyncStatus
+type swaggerResponseMirrorSyncStatus struct {
+	// in:body
+	Body api.MirrorSyncStatus `json:"body"`
+}
+
+// MirrorWebhookSecret
+// swagger:response MirrorWebhookSecret
+type swaggerResponseMirrorWebhookSecret struct {
+	// in:body
+	Body api.MirrorWebhookSecret `json:"body"`
+}
+
+// BulkRepoTask
+// swagger:response BulkRepoTask
+type swaggerResponseBulkRepoTask struct {
+	// in:body
+	Body api.BulkRepoTask `json:"body"`
+}
+
+// BulkRepoTaskList
+// swagger:response BulkRepoTaskList
+type swaggerResponseBulkRepoTaskList struct {
+	// in:body
+	Body []api.BulkRepoTask `json:"body"`
+}
+
+// DeletedRepositoryList
+// swagger:response DeletedRepositoryList
+type swaggerResponseDeletedRepositoryList struct {
+	// in:body
+	Body []api.DeletedRepository `json:"body"`
+}
@@ -18,6 +18,7 @@ import (
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	"code.gitea.io/gitea/services/audit"
 )
 
 // ListAccessTokens list all the access tokens
@@ -117,6 +118,7 @@ func CreateAccessToken(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "NewAccessToken", err)
 		return
 	}
+	audit.RecordUser(ctx.Req, ctx.User, ctx.User, models.AuditUserAccessTokenCreate, t.Name)
 	ctx.JSON(http.StatusCreated, &api.AccessToken{
 		Name:           t.Name,
 		Token:          t.Token,
@@ -188,6 +190,7 @@ func DeleteAccessToken(ctx *context.APIContext) {
 		}
 		return
 	}
+	audit.RecordUser(ctx.Req, ctx.User, ctx.User, models.AuditUserAccessTokenDelete, fmt.Sprintf("ID %d", tokenID))
 
 	ctx.Status(http.StatusNoContent)
 }
@@ -159,3 +159,26 @@ func ListOrgRepos(ctx *context.APIContext) {
 
 	listUserRepos(ctx, ctx.Org.Organization, ctx.IsSigned)
 }
+
+// ListMyDeletedRepos - list the repositories of the authenticated user in the trash
+func ListMyDeletedRepos(ctx *context.APIContext) {
+	// swagger:operation GET /user/repos/deleted user userCurrentListDeletedRepos
+	// ---
+	// summary: List the repos of the authenticated user in the trash, most recently deleted first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/DeletedRepositoryList"
+
+	utils.ListDeletedRepos(ctx, ctx.User.ID)
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package utils
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ListAuditEvents responds with the audit events matching opts and the query
+// parameters action, user, since, before, page and limit
+func ListAuditEvents(ctx *context.APIContext, opts *models.FindAuditEventsOptions) {
+	opts.ListOptions = GetListOptions(ctx)
+	opts.ListOptions.SetDefaultValues()
+
+	for _, action := range ctx.FormStrings("action") {
+		if !models.AuditAction(action).IsValid() {
+			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("unknown audit action: %s", action))
+			return
+		}
+		opts.Actions = append(opts.Actions, models.AuditAction(action))
+	}
+
+	if doerName := ctx.FormTrim("user"); doerName != "" {
+		doer, err := models.GetUserByName(doerName)
+		if err != nil {
+			if models.IsErrUserNotExist(err) {
+				ctx.Error(http.StatusUnprocessableEntity, "", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "GetUserByName", err)
+			}
+			return
+		}
+		opts.DoerID = doer.ID
+	}
+
+	var err error
+	opts.BeforeUnix, opts.SinceUnix, err = GetQueryBeforeSince(ctx)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "GetQueryBeforeSince", err)
+		return
+	}
+
+	count, err := models.CountAuditEvents(opts)
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+	events, err := models.FindAuditEvents(opts)
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+
+	apiEvents := make([]*api.AuditEvent, len(events))
+	for i := range events {
+		apiEvents[i] = convert.ToAuditEvent(events[i])
+	}
+
+	ctx.SetLinkHeader(int(count), opts.PageSize)
+	ctx.SetTotalCountHeader(count)
+	ctx.JSON(http.StatusOK, apiEvents)
+}
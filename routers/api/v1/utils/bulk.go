@@ -0,0 +1,82 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package utils
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/task"
+)
+
+// CreateBulkRepoTask queues a bulk repository operation for the repositories of org,
+// or of any owner if org is nil, and responds with the queued task
+func CreateBulkRepoTask(ctx *context.APIContext, org *models.User, form *api.BulkRepoOption) {
+	t, err := task.BulkRepoOperation(ctx.User, org, *form)
+	if err != nil {
+		if models.IsErrBulkRepoOptionInvalid(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "BulkRepoOperation", err)
+		}
+		return
+	}
+
+	apiTask, err := convert.ToBulkRepoTask(t, ctx.User)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ToBulkRepoTask", err)
+		return
+	}
+	ctx.JSON(http.StatusAccepted, apiTask)
+}
+
+// ListBulkRepoTasks responds with the bulk repository operations of an organization,
+// or all of them if ownerID is zero
+func ListBulkRepoTasks(ctx *context.APIContext, ownerID int64) {
+	listOptions := GetListOptions(ctx)
+	listOptions.SetDefaultValues()
+
+	tasks, count, err := models.FindBulkRepoTasks(ownerID, listOptions)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindBulkRepoTasks", err)
+		return
+	}
+
+	apiTasks := make([]*api.BulkRepoTask, len(tasks))
+	for i := range tasks {
+		if apiTasks[i], err = convert.ToBulkRepoTask(tasks[i], ctx.User); err != nil {
+			ctx.Error(http.StatusInternalServerError, "ToBulkRepoTask", err)
+			return
+		}
+	}
+
+	ctx.SetLinkHeader(int(count), listOptions.PageSize)
+	ctx.SetTotalCountHeader(count)
+	ctx.JSON(http.StatusOK, apiTasks)
+}
+
+// GetBulkRepoTask responds with the bulk repository operation given by the id parameter.
+// If ownerID is not zero it must belong to that organization.
+func GetBulkRepoTask(ctx *context.APIContext, ownerID int64) {
+	t, err := models.GetBulkRepoTaskByID(ctx.ParamsInt64(":id"), ownerID)
+	if err != nil {
+		if models.IsErrTaskDoesNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetBulkRepoTaskByID", err)
+		}
+		return
+	}
+
+	apiTask, err := convert.ToBulkRepoTask(t, ctx.User)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ToBulkRepoTask", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, apiTask)
+}
@@ -7,12 +7,15 @@ package utils
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
 	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/routers/utils"
@@ -34,6 +37,24 @@ func GetOrgHook(ctx *context.APIContext, orgID, hookID int64) (*models.Webhook,
 	return w, nil
 }
 
+// GetSystemHook get a system webhook. If there is an error, write to `ctx`
+// accordingly and return the error
+func GetSystemHook(ctx *context.APIContext, hookID int64) (*models.Webhook, error) {
+	w, err := models.GetSystemOrDefaultWebhook(hookID)
+	if err == nil && !w.IsSystemWebhook {
+		err = models.ErrWebhookNotExist{ID: hookID}
+	}
+	if err != nil {
+		if models.IsErrWebhookNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetSystemOrDefaultWebhook", err)
+		}
+		return nil, err
+	}
+	return w, nil
+}
+
 // GetRepoHook get a repo's webhook. If there is an error, write to `ctx`
 // accordingly and return the error
 func GetRepoHook(ctx *context.APIContext, repoID, hookID int64) (*models.Webhook, error) {
@@ -72,7 +93,7 @@ func CheckCreateHookOption(ctx *context.APIContext, form *api.CreateHookOption)
 // AddOrgHook add a hook to an organization. Writes to `ctx` accordingly
 func AddOrgHook(ctx *context.APIContext, form *api.CreateHookOption) {
 	org := ctx.Org.Organization
-	hook, ok := addHook(ctx, form, org.ID, 0)
+	hook, ok := addHook(ctx, form, org.ID, 0, false)
 	if ok {
 		ctx.JSON(http.StatusCreated, convert.ToHook(org.HomeLink(), hook))
 	}
@@ -81,12 +102,21 @@ func AddOrgHook(ctx *context.APIContext, form *api.CreateHookOption) {
 // AddRepoHook add a hook to a repo. Writes to `ctx` accordingly
 func AddRepoHook(ctx *context.APIContext, form *api.CreateHookOption) {
 	repo := ctx.Repo
-	hook, ok := addHook(ctx, form, 0, repo.Repository.ID)
+	hook, ok := addHook(ctx, form, 0, repo.Repository.ID, false)
 	if ok {
 		ctx.JSON(http.StatusCreated, convert.ToHook(repo.RepoLink, hook))
 	}
 }
 
+// AddSystemHook add a system hook, which fires for every repository and for
+// instance events. Writes to `ctx` accordingly
+func AddSystemHook(ctx *context.APIContext, form *api.CreateHookOption) {
+	hook, ok := addHook(ctx, form, 0, 0, true)
+	if ok {
+		ctx.JSON(http.StatusCreated, convert.ToHook(setting.AppSubURL+"/admin", hook))
+	}
+}
+
 func issuesHook(events []string, event string) bool {
 	return util.IsStringInSlice(event, events, true) || util.IsStringInSlice(string(models.HookEventIssues), events, true)
 }
@@ -95,9 +125,9 @@ func pullHook(events []string, event string) bool {
 	return util.IsStringInSlice(event, events, true) || util.IsStringInSlice(string(models.HookEventPullRequest), events, true)
 }
 
-// addHook add the hook specified by `form`, `orgID` and `repoID`. If there is
-// an error, write to `ctx` accordingly. Return (webhook, ok)
-func addHook(ctx *context.APIContext, form *api.CreateHookOption, orgID, repoID int64) (*models.Webhook, bool) {
+// addHook add the hook specified by `form`, `orgID`, `repoID` and `isSystem`. If
+// there is an error, write to `ctx` accordingly. Return (webhook, ok)
+func addHook(ctx *context.APIContext, form *api.CreateHookOption, orgID, repoID int64, isSystem bool) (*models.Webhook, bool) {
 	if len(form.Events) == 0 {
 		form.Events = []string{"push"}
 	}
@@ -129,11 +159,20 @@ func addHook(ctx *context.APIContext, form *api.CreateHookOption, orgID, repoID
 				PullRequestSync:      pullHook(form.Events, string(models.HookEventPullRequestSync)),
 				Repository:           util.IsStringInSlice(string(models.HookEventRepository), form.Events, true),
 				Release:              util.IsStringInSlice(string(models.HookEventRelease), form.Events, true),
+				Wiki:                 util.IsStringInSlice(string(models.HookEventWiki), form.Events, true),
+				Status:               util.IsStringInSlice(string(models.HookEventStatus), form.Events, true),
+				Member:               util.IsStringInSlice(string(models.HookEventMember), form.Events, true),
+				BranchProtection:     util.IsStringInSlice(string(models.HookEventBranchProtection), form.Events, true),
+				Star:                 util.IsStringInSlice(string(models.HookEventStar), form.Events, true),
+				User:                 util.IsStringInSlice(string(models.HookEventUser), form.Events, true),
+				Organization:         util.IsStringInSlice(string(models.HookEventOrganization), form.Events, true),
+				LoginFailed:          util.IsStringInSlice(string(models.HookEventLoginFailed), form.Events, true),
 			},
 			BranchFilter: form.BranchFilter,
 		},
-		IsActive: form.Active,
-		Type:     models.HookType(form.Type),
+		IsActive:        form.Active,
+		IsSystemWebhook: isSystem,
+		Type:            models.HookType(form.Type),
 	}
 	if w.Type == models.SLACK {
 		channel, ok := form.Config["channel"]
@@ -159,6 +198,15 @@ func addHook(ctx *context.APIContext, form *api.CreateHookOption, orgID, repoID
 		}
 		w.Meta = string(meta)
 	}
+	if w.Type == models.CUSTOM {
+		if _, ok := form.Config["payload_template"]; !ok {
+			ctx.Error(http.StatusUnprocessableEntity, "", "Missing config option: payload_template")
+			return nil, false
+		}
+	}
+	if !setHookMeta(ctx, w, form.Config) {
+		return nil, false
+	}
 
 	if err := w.UpdateEvent(); err != nil {
 		ctx.Error(http.StatusInternalServerError, "UpdateEvent", err)
@@ -170,6 +218,97 @@ func addHook(ctx *context.APIContext, form *api.CreateHookOption, orgID, repoID
 	return w, true
 }
 
+// setHookMeta updates the metadata of Mattermost, Rocket.Chat, ntfy and custom hooks with the
+// options given in config, options missing in config keep their value. If an option is invalid,
+// write to `ctx` accordingly. Return whether successful
+func setHookMeta(ctx *context.APIContext, w *models.Webhook, config map[string]string) bool {
+	loadMeta := func(v interface{}) {
+		if w.Meta != "" {
+			if err := json.Unmarshal([]byte(w.Meta), v); err != nil {
+				log.Error("Unmarshal meta of webhook[%d]: %v", w.ID, err)
+			}
+		}
+	}
+
+	var meta interface{}
+	switch w.Type {
+	case models.MATTERMOST:
+		m := &webhook.MattermostMeta{}
+		loadMeta(m)
+		setHookMetaOption(config, "channel", &m.Channel)
+		setHookMetaOption(config, "username", &m.Username)
+		setHookMetaOption(config, "icon_url", &m.IconURL)
+		meta = m
+	case models.ROCKETCHAT:
+		m := &webhook.RocketChatMeta{}
+		loadMeta(m)
+		setHookMetaOption(config, "channel", &m.Channel)
+		setHookMetaOption(config, "username", &m.Username)
+		setHookMetaOption(config, "icon_url", &m.IconURL)
+		meta = m
+	case models.NTFY:
+		m := &webhook.NtfyMeta{}
+		loadMeta(m)
+		setHookMetaOption(config, "topic", &m.Topic)
+		setHookMetaOption(config, "access_token", &m.AccessToken)
+		if priority, ok := config["priority"]; ok {
+			p, err := strconv.Atoi(priority)
+			if err != nil || p < 0 || p > 5 {
+				ctx.Error(http.StatusUnprocessableEntity, "", "Invalid ntfy priority")
+				return false
+			}
+			m.Priority = p
+		}
+		if m.Topic == "" {
+			ctx.Error(http.StatusUnprocessableEntity, "", "Missing config option: topic")
+			return false
+		}
+		meta = m
+	case models.CUSTOM:
+		m := &webhook.CustomMeta{}
+		loadMeta(m)
+		setHookMetaOption(config, "payload_template", &m.PayloadTemplate)
+		setHookMetaOption(config, "payload_content_type", &m.ContentType)
+		setHookMetaOption(config, "headers", &m.Headers)
+		if method, ok := config["http_method"]; ok {
+			method = strings.ToUpper(method)
+			if method != http.MethodPost && method != http.MethodPut {
+				ctx.Error(http.StatusUnprocessableEntity, "", "Invalid http method")
+				return false
+			}
+			w.HTTPMethod = method
+		}
+		if _, err := webhook.ParseCustomPayloadTemplate(m.PayloadTemplate, models.HookEventPush); err != nil {
+			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("Invalid payload template: %v", err))
+			return false
+		}
+		if _, err := webhook.ParseCustomHeaders(m.Headers); err != nil {
+			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("Invalid headers: %v", err))
+			return false
+		}
+		if secret, ok := config["secret"]; ok {
+			w.Secret = secret
+		}
+		meta = m
+	default:
+		return true
+	}
+
+	data, err := json.Marshal(meta)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "JSON marshal failed", err)
+		return false
+	}
+	w.Meta = string(data)
+	return true
+}
+
+func setHookMetaOption(config map[string]string, name string, value *string) {
+	if v, ok := config[name]; ok {
+		*value = v
+	}
+}
+
 // EditOrgHook edit webhook `w` according to `form`. Writes to `ctx` accordingly
 func EditOrgHook(ctx *context.APIContext, form *api.EditHookOption, hookID int64) {
 	org := ctx.Org.Organization
@@ -204,6 +343,22 @@ func EditRepoHook(ctx *context.APIContext, form *api.EditHookOption, hookID int6
 	ctx.JSON(http.StatusOK, convert.ToHook(repo.RepoLink, updated))
 }
 
+// EditSystemHook edit system webhook `w` according to `form`. Writes to `ctx` accordingly
+func EditSystemHook(ctx *context.APIContext, form *api.EditHookOption, hookID int64) {
+	hook, err := GetSystemHook(ctx, hookID)
+	if err != nil {
+		return
+	}
+	if !editHook(ctx, form, hook) {
+		return
+	}
+	updated, err := GetSystemHook(ctx, hookID)
+	if err != nil {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToHook(setting.AppSubURL+"/admin", updated))
+}
+
 // editHook edit the webhook `w` according to `form`. If an error occurs, write
 // to `ctx` accordingly and return the error. Return whether successful
 func editHook(ctx *context.APIContext, form *api.EditHookOption, w *models.Webhook) bool {
@@ -234,6 +389,9 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *models.Webho
 				w.Meta = string(meta)
 			}
 		}
+		if !setHookMeta(ctx, w, form.Config) {
+			return false
+		}
 	}
 
 	// Update events
@@ -255,6 +413,14 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *models.Webho
 	w.PullRequest = util.IsStringInSlice(string(models.HookEventPullRequest), form.Events, true)
 	w.Repository = util.IsStringInSlice(string(models.HookEventRepository), form.Events, true)
 	w.Release = util.IsStringInSlice(string(models.HookEventRelease), form.Events, true)
+	w.Wiki = util.IsStringInSlice(string(models.HookEventWiki), form.Events, true)
+	w.Status = util.IsStringInSlice(string(models.HookEventStatus), form.Events, true)
+	w.Member = util.IsStringInSlice(string(models.HookEventMember), form.Events, true)
+	w.BranchProtection = util.IsStringInSlice(string(models.HookEventBranchProtection), form.Events, true)
+	w.Star = util.IsStringInSlice(string(models.HookEventStar), form.Events, true)
+	w.User = util.IsStringInSlice(string(models.HookEventUser), form.Events, true)
+	w.Organization = util.IsStringInSlice(string(models.HookEventOrganization), form.Events, true)
+	w.LoginFailed = util.IsStringInSlice(string(models.HookEventLoginFailed), form.Events, true)
 	w.BranchFilter = form.BranchFilter
 
 	if err := w.UpdateEvent(); err != nil {
@@ -272,3 +438,60 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *models.Webho
 	}
 	return true
 }
+
+// ListHookDeliveries writes the paginated deliveries of a webhook to `ctx`
+func ListHookDeliveries(ctx *context.APIContext, w *models.Webhook) {
+	count, err := models.CountHookTasks(w.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "CountHookTasks", err)
+		return
+	}
+
+	tasks, err := models.ListHookTasks(w.ID, GetListOptions(ctx))
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ListHookTasks", err)
+		return
+	}
+
+	deliveries := make([]*api.HookDelivery, len(tasks))
+	for i := range tasks {
+		deliveries[i] = convert.ToHookDelivery(tasks[i])
+	}
+
+	ctx.SetTotalCountHeader(count)
+	ctx.JSON(http.StatusOK, &deliveries)
+}
+
+// GetHookDelivery get a delivery of a webhook. If there is an error, write to
+// `ctx` accordingly and return the error
+func GetHookDelivery(ctx *context.APIContext, w *models.Webhook) (*models.HookTask, error) {
+	t, err := models.GetHookTaskByHookID(w.ID, ctx.ParamsInt64(":delivery_id"))
+	if err != nil {
+		if models.IsErrHookTaskNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetHookTaskByHookID", err)
+		}
+		return nil, err
+	}
+	return t, nil
+}
+
+// RedeliverHook queues a new delivery of the payload of a delivery of a webhook
+func RedeliverHook(ctx *context.APIContext, w *models.Webhook) {
+	t, err := GetHookDelivery(ctx, w)
+	if err != nil {
+		return
+	}
+	if !w.IsActive {
+		ctx.Error(http.StatusUnprocessableEntity, "", "webhook is not active")
+		return
+	}
+
+	redelivery, err := webhook.RedeliverHookTask(t)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "RedeliverHookTask", err)
+		return
+	}
+	ctx.JSON(http.StatusAccepted, convert.ToHookDelivery(redelivery))
+}
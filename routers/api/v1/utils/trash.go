@@ -0,0 +1,70 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package utils
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ListDeletedRepos responds with the repositories in the trash of an owner,
+// or of all owners if ownerID is zero
+func ListDeletedRepos(ctx *context.APIContext, ownerID int64) {
+	listOptions := GetListOptions(ctx)
+	listOptions.SetDefaultValues()
+
+	repos, count, err := models.FindDeletedRepositories(&models.FindDeletedReposOptions{
+		ListOptions: listOptions,
+		OwnerID:     ownerID,
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindDeletedRepositories", err)
+		return
+	}
+
+	apiRepos := make([]*api.DeletedRepository, len(repos))
+	for i := range repos {
+		if apiRepos[i], err = convert.ToDeletedRepo(repos[i], ctx.User); err != nil {
+			ctx.Error(http.StatusInternalServerError, "ToDeletedRepo", err)
+			return
+		}
+	}
+
+	ctx.SetLinkHeader(int(count), listOptions.PageSize)
+	ctx.SetTotalCountHeader(count)
+	ctx.JSON(http.StatusOK, apiRepos)
+}
+
+// ListDeletedOrgs responds with the organizations in the trash owned by a user,
+// or all of them if ownerID is zero
+func ListDeletedOrgs(ctx *context.APIContext, ownerID int64) {
+	listOptions := GetListOptions(ctx)
+	listOptions.SetDefaultValues()
+
+	orgs, count, err := models.FindDeletedOrgs(&models.FindDeletedOrgsOptions{
+		ListOptions: listOptions,
+		OwnerID:     ownerID,
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindDeletedOrgs", err)
+		return
+	}
+
+	apiOrgs := make([]*api.DeletedOrganization, len(orgs))
+	for i := range orgs {
+		if apiOrgs[i], err = convert.ToDeletedOrg(orgs[i], ctx.User); err != nil {
+			ctx.Error(http.StatusInternalServerError, "ToDeletedOrg", err)
+			return
+		}
+	}
+
+	ctx.SetLinkHeader(int(count), listOptions.PageSize)
+	ctx.SetTotalCountHeader(count)
+	ctx.JSON(http.StatusOK, apiOrgs)
+}
@@ -0,0 +1,91 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package common
+
+import (
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const auditDateLayout = "2006-01-02"
+
+// AuditLog renders the audit events matching opts and the filters of the request
+// into ctx.Data, to be displayed by the shared/auditlog template
+func AuditLog(ctx *context.Context, opts *models.FindAuditEventsOptions) {
+	page := ctx.FormInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	opts.ListOptions = db.ListOptions{
+		Page:     page,
+		PageSize: setting.UI.Admin.NoticePagingNum,
+	}
+
+	action := models.AuditAction(ctx.FormString("action"))
+	if action.IsValid() {
+		opts.Actions = []models.AuditAction{action}
+	} else {
+		action = ""
+	}
+	ctx.Data["AuditActions"] = models.AuditActions
+	ctx.Data["AuditAction"] = string(action)
+
+	doerName := ctx.FormTrim("user")
+	if doerName != "" {
+		doer, err := models.GetUserByName(doerName)
+		if err != nil {
+			if !models.IsErrUserNotExist(err) {
+				ctx.ServerError("GetUserByName", err)
+				return
+			}
+			// nobody of that name did anything
+			opts.DoerID = -1
+		} else {
+			opts.DoerID = doer.ID
+		}
+	}
+	ctx.Data["AuditUser"] = doerName
+
+	since := ctx.FormString("since")
+	if t, err := time.ParseInLocation(auditDateLayout, since, setting.DefaultUILocation); err == nil {
+		opts.SinceUnix = t.Unix()
+	} else {
+		since = ""
+	}
+	ctx.Data["AuditSince"] = since
+
+	until := ctx.FormString("until")
+	if t, err := time.ParseInLocation(auditDateLayout, until, setting.DefaultUILocation); err == nil {
+		// until is inclusive
+		opts.BeforeUnix = t.AddDate(0, 0, 1).Unix()
+	} else {
+		until = ""
+	}
+	ctx.Data["AuditUntil"] = until
+
+	count, err := models.CountAuditEvents(opts)
+	if err != nil {
+		ctx.ServerError("CountAuditEvents", err)
+		return
+	}
+	events, err := models.FindAuditEvents(opts)
+	if err != nil {
+		ctx.ServerError("FindAuditEvents", err)
+		return
+	}
+	ctx.Data["AuditEvents"] = events
+	ctx.Data["Total"] = count
+
+	pager := context.NewPagination(int(count), opts.PageSize, page, 5)
+	pager.AddParam(ctx, "action", "AuditAction")
+	pager.AddParam(ctx, "user", "AuditUser")
+	pager.AddParam(ctx, "since", "AuditSince")
+	pager.AddParam(ctx, "until", "AuditUntil")
+	ctx.Data["Page"] = pager
+}
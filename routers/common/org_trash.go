@@ -0,0 +1,120 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package common
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/services/audit"
+	org_service "code.gitea.io/gitea/services/org"
+)
+
+// DeletedOrgs renders the organizations in the trash owned by a user, or all of them
+// if ownerID is zero, into ctx.Data, to be displayed by the shared/org_trash template
+func DeletedOrgs(ctx *context.Context, ownerID int64) {
+	page := ctx.FormInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	opts := &models.FindDeletedOrgsOptions{
+		ListOptions: db.ListOptions{
+			Page:     page,
+			PageSize: setting.UI.Admin.OrgPagingNum,
+		},
+		OwnerID: ownerID,
+	}
+	orgs, count, err := models.FindDeletedOrgs(opts)
+	if err != nil {
+		ctx.ServerError("FindDeletedOrgs", err)
+		return
+	}
+
+	doerIDs := make([]int64, 0, len(orgs))
+	for _, org := range orgs {
+		doerIDs = append(doerIDs, org.DeletedByID)
+	}
+	doers, err := models.GetUsersByIDs(doerIDs)
+	if err != nil {
+		ctx.ServerError("GetUsersByIDs", err)
+		return
+	}
+	deletedBy := make(map[int64]*models.User, len(doers))
+	for _, doer := range doers {
+		deletedBy[doer.ID] = doer
+	}
+
+	ctx.Data["DeletedOrgs"] = orgs
+	ctx.Data["DeletedBy"] = deletedBy
+	ctx.Data["TrashEnabled"] = setting.Repository.TrashPeriod > 0
+	ctx.Data["Total"] = count
+	ctx.Data["Page"] = context.NewPagination(int(count), opts.PageSize, page, 5)
+}
+
+// deletedOrg returns the organization in the trash given by the id form value
+// if the doer may restore it
+func deletedOrg(ctx *context.Context) *models.User {
+	org, err := models.GetDeletedOrgByID(ctx.FormInt64("id"))
+	if err != nil {
+		if models.IsErrOrgNotExist(err) {
+			ctx.NotFound("GetDeletedOrgByID", err)
+		} else {
+			ctx.ServerError("GetDeletedOrgByID", err)
+		}
+		return nil
+	}
+	if canManage, err := org_service.CanManageDeletedOrganization(ctx.User, org); err != nil {
+		ctx.ServerError("CanManageDeletedOrganization", err)
+		return nil
+	} else if !canManage {
+		ctx.NotFound("CanManageDeletedOrganization", nil)
+		return nil
+	}
+	return org
+}
+
+// RestoreDeletedOrg restores the organization in the trash given by the id form value
+// and redirects to redirectTo
+func RestoreDeletedOrg(ctx *context.Context, redirectTo string) {
+	org := deletedOrg(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.RestoreOrganization(org); err != nil {
+		ctx.ServerError("RestoreOrganization", err)
+		return
+	}
+	audit.RecordUser(ctx.Req, ctx.User, org, models.AuditOrgRestore, "")
+	log.Trace("Organization restored: %s", org.Name)
+
+	ctx.Flash.Success(ctx.Tr("org.trash.restore_success", org.Name))
+	ctx.Redirect(redirectTo)
+}
+
+// PurgeDeletedOrg permanently deletes the organization in the trash given by the id form value
+// and responds with redirectTo for the delete modal
+func PurgeDeletedOrg(ctx *context.Context, redirectTo string) {
+	org := deletedOrg(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := org_service.PurgeOrganization(ctx.User, org); err != nil {
+		ctx.ServerError("PurgeOrganization", err)
+		return
+	}
+	audit.RecordUser(ctx.Req, ctx.User, org, models.AuditOrgPurge, "")
+	log.Trace("Organization purged: %s", org.Name)
+
+	ctx.Flash.Success(ctx.Tr("org.trash.purge_success", org.Name))
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": redirectTo,
+	})
+}
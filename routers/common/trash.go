@@ -0,0 +1,136 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package common
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/services/audit"
+	repo_service "code.gitea.io/gitea/services/repository"
+)
+
+// DeletedRepos renders the repositories in the trash of an owner, or of all owners
+// if ownerID is zero, into ctx.Data, to be displayed by the shared/repo_trash template
+func DeletedRepos(ctx *context.Context, ownerID int64) {
+	page := ctx.FormInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	opts := &models.FindDeletedReposOptions{
+		ListOptions: db.ListOptions{
+			Page:     page,
+			PageSize: setting.UI.Admin.RepoPagingNum,
+		},
+		OwnerID: ownerID,
+	}
+	repos, count, err := models.FindDeletedRepositories(opts)
+	if err != nil {
+		ctx.ServerError("FindDeletedRepositories", err)
+		return
+	}
+
+	doerIDs := make([]int64, 0, len(repos))
+	for _, repo := range repos {
+		doerIDs = append(doerIDs, repo.DeletedByID)
+	}
+	doers, err := models.GetUsersByIDs(doerIDs)
+	if err != nil {
+		ctx.ServerError("GetUsersByIDs", err)
+		return
+	}
+	deletedBy := make(map[int64]*models.User, len(doers))
+	for _, doer := range doers {
+		deletedBy[doer.ID] = doer
+	}
+
+	ctx.Data["DeletedRepos"] = repos
+	ctx.Data["DeletedBy"] = deletedBy
+	ctx.Data["ShowRepoOwner"] = ownerID == 0
+	ctx.Data["TrashEnabled"] = setting.Repository.TrashPeriod > 0
+	ctx.Data["Total"] = count
+	ctx.Data["Page"] = context.NewPagination(int(count), opts.PageSize, page, 5)
+}
+
+// deletedRepo returns the repository in the trash given by the id form value. It must
+// belong to the owner unless ownerID is zero, and the doer must be allowed to restore it.
+func deletedRepo(ctx *context.Context, ownerID int64) *models.Repository {
+	repo, err := models.GetDeletedRepositoryByID(ctx.FormInt64("id"))
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			ctx.NotFound("GetDeletedRepositoryByID", err)
+		} else {
+			ctx.ServerError("GetDeletedRepositoryByID", err)
+		}
+		return nil
+	}
+	if ownerID != 0 && repo.OwnerID != ownerID {
+		ctx.NotFound("GetDeletedRepositoryByID", nil)
+		return nil
+	}
+	if canManage, err := repo_service.CanManageDeletedRepository(ctx.User, repo); err != nil {
+		ctx.ServerError("CanManageDeletedRepository", err)
+		return nil
+	} else if !canManage {
+		ctx.NotFound("CanManageDeletedRepository", nil)
+		return nil
+	}
+	if err := repo.GetOwner(); err != nil {
+		ctx.ServerError("GetOwner", err)
+		return nil
+	}
+	return repo
+}
+
+// RestoreDeletedRepo restores the repository in the trash given by the id form value
+// and redirects to redirectTo
+func RestoreDeletedRepo(ctx *context.Context, ownerID int64, redirectTo string) {
+	repo := deletedRepo(ctx, ownerID)
+	if ctx.Written() {
+		return
+	}
+
+	if err := repo_service.RestoreRepository(repo); err != nil {
+		switch {
+		case models.IsErrForkAlreadyExist(err):
+			ctx.Flash.Error(ctx.Tr("repo.trash.restore_fork_exists", repo.FullName()))
+		case models.IsErrOrgInTrash(err):
+			ctx.Flash.Error(ctx.Tr("repo.trash.restore_org_in_trash", repo.FullName()))
+		default:
+			ctx.ServerError("RestoreRepository", err)
+			return
+		}
+	} else {
+		audit.RecordRepo(ctx.Req, ctx.User, repo, models.AuditRepoRestore, "", "")
+		log.Trace("Repository restored: %s", repo.FullName())
+		ctx.Flash.Success(ctx.Tr("repo.trash.restore_success", repo.FullName()))
+	}
+	ctx.Redirect(redirectTo)
+}
+
+// PurgeDeletedRepo permanently deletes the repository in the trash given by the id form value
+// and responds with redirectTo for the delete modal
+func PurgeDeletedRepo(ctx *context.Context, ownerID int64, redirectTo string) {
+	repo := deletedRepo(ctx, ownerID)
+	if ctx.Written() {
+		return
+	}
+
+	if err := repo_service.PurgeRepository(ctx.User, repo); err != nil {
+		ctx.ServerError("PurgeRepository", err)
+		return
+	}
+	audit.RecordRepo(ctx.Req, ctx.User, repo, models.AuditRepoPurge, "", "")
+	log.Trace("Repository purged: %s", repo.FullName())
+
+	ctx.Flash.Success(ctx.Tr("repo.trash.purge_success", repo.FullName()))
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": redirectTo,
+	})
+}
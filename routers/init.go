@@ -43,6 +43,7 @@ import (
 	"code.gitea.io/gitea/services/auth"
 	"code.gitea.io/gitea/services/auth/source/oauth2"
 	"code.gitea.io/gitea/services/mailer"
+	"code.gitea.io/gitea/services/mailer/incoming"
 	mirror_service "code.gitea.io/gitea/services/mirror"
 	pull_service "code.gitea.io/gitea/services/pull"
 	"code.gitea.io/gitea/services/repository"
@@ -147,10 +148,11 @@ func GlobalInit(ctx context.Context) {
 	mustInit(stats_indexer.Init)
 
 	mirror_service.InitSyncMirrors()
-	webhook.InitDeliverHooks()
+	mustInit(webhook.InitDeliverHooks)
 	mustInit(pull_service.Init)
 	mustInit(task.Init)
 	mustInit(repo_migrations.Init)
+	mustInitCtx(ctx, incoming.Init)
 	eventsource.GetManager().Init()
 
 	mustInitCtx(ctx, syncAppPathForGit)
@@ -16,6 +16,7 @@ import (
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/private"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
 	pull_service "code.gitea.io/gitea/services/pull"
 )
@@ -152,6 +153,11 @@ func preReceiveBranch(ctx *preReceiveContext, oldCommitID, newCommitID, refFullN
 		return
 	}
 
+	preReceiveLFSLocks(ctx, oldCommitID, newCommitID, branchName)
+	if ctx.Written() {
+		return
+	}
+
 	protectBranch, err := models.GetProtectedBranchBy(repo.ID, branchName)
 	if err != nil {
 		log.Error("Unable to get protected branch: %s in %-v Error: %v", branchName, repo, err)
@@ -352,6 +358,74 @@ func preReceiveBranch(ctx *preReceiveContext, oldCommitID, newCommitID, refFullN
 	}
 }
 
+// preReceiveLFSLocks rejects pushes which change files locked by another user through the LFS locking API.
+// Administrators of the repository may push over the locks of others.
+func preReceiveLFSLocks(ctx *preReceiveContext, oldCommitID, newCommitID, branchName string) {
+	if !setting.LFS.StartServer || ctx.opts.IsWiki || newCommitID == git.EmptySHA {
+		return
+	}
+
+	repo := ctx.Repo.Repository
+	locks, err := models.GetLFSLockByRepoID(repo.ID, 0, 0)
+	if err != nil {
+		log.Error("Unable to get LFS locks for %-v Error: %v", repo, err)
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			Err: fmt.Sprintf("Unable to get LFS locks: %v", err),
+		})
+		return
+	}
+
+	// only locks held by others need to be checked
+	lockedPaths := make(map[string]*models.LFSLock, len(locks))
+	for _, lock := range locks {
+		if lock.OwnerID != ctx.opts.UserID {
+			lockedPaths[strings.ToLower(lock.Path)] = lock
+		}
+	}
+	if len(lockedPaths) == 0 || ctx.Perm().IsAdmin() || ctx.Written() {
+		return
+	}
+
+	// A new branch is compared with the point where it diverged from the default branch
+	if oldCommitID == git.EmptySHA {
+		if !ctx.Repo.GitRepo.IsBranchExist(repo.DefaultBranch) {
+			return
+		}
+		mergeBase, err := git.NewCommand("merge-base", git.BranchPrefix+repo.DefaultBranch, newCommitID).RunInDirWithEnv(repo.RepoPath(), ctx.env)
+		if err != nil {
+			// unrelated histories have no merge base, every file is new then
+			log.Trace("No merge base between %s and %s in %-v: %v", repo.DefaultBranch, newCommitID, repo, err)
+			return
+		}
+		oldCommitID = strings.TrimSpace(mergeBase)
+	}
+
+	affectedFiles, err := git.GetAffectedFiles(oldCommitID, newCommitID, ctx.env, ctx.Repo.GitRepo)
+	if err != nil {
+		log.Error("Unable to get affected files for commits from %s to %s in %-v: %v", oldCommitID, newCommitID, repo, err)
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			Err: fmt.Sprintf("Unable to get affected files for commits from %s to %s: %v", oldCommitID, newCommitID, err),
+		})
+		return
+	}
+
+	for _, affectedFile := range affectedFiles {
+		lock, ok := lockedPaths[strings.ToLower(affectedFile)]
+		if !ok {
+			continue
+		}
+		ownerName := "another user"
+		if lock.Owner != nil {
+			ownerName = lock.Owner.Name
+		}
+		log.Warn("Forbidden: User %d is not allowed to change file %s in branch %s of %-v which is locked by %s", ctx.opts.UserID, lock.Path, branchName, repo, ownerName)
+		ctx.JSON(http.StatusForbidden, private.Response{
+			Err: fmt.Sprintf("file %s is locked by %s, it can only be changed after the lock has been released", lock.Path, ownerName),
+		})
+		return
+	}
+}
+
 func preReceiveTag(ctx *preReceiveContext, oldCommitID, newCommitID, refFullName string) {
 	if !ctx.AssertCanWriteCode() {
 		return
@@ -388,7 +388,14 @@ func ServCommand(ctx *context.PrivateContext) {
 			return
 		}
 	}
-	log.Debug("Serv Results:\nIsWiki: %t\nIsDeployKey: %t\nKeyID: %d\tKeyName: %s\nUserName: %s\nUserID: %d\nOwnerName: %s\nRepoName: %s\nRepoID: %d",
+	// The serv command has no access to the database to resolve the shard of the repository
+	if results.IsWiki {
+		results.RepoPath = models.WikiPath(results.OwnerName, results.RepoName)
+	} else {
+		results.RepoPath = models.RepoPath(results.OwnerName, results.RepoName)
+	}
+
+	log.Debug("Serv Results:\nIsWiki: %t\nIsDeployKey: %t\nKeyID: %d\tKeyName: %s\nUserName: %s\nUserID: %d\nOwnerName: %s\nRepoName: %s\nRepoID: %d\nRepoPath: %s",
 		results.IsWiki,
 		results.IsDeployKey,
 		results.KeyID,
@@ -397,7 +404,8 @@ func ServCommand(ctx *context.PrivateContext) {
 		results.UserID,
 		results.OwnerName,
 		results.RepoName,
-		results.RepoID)
+		results.RepoID,
+		results.RepoPath)
 
 	ctx.JSON(http.StatusOK, results)
 	// We will update the keys in a different call.
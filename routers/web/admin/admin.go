@@ -249,6 +249,7 @@ func Config(ctx *context.Context) {
 		ctx.Data["GitVersion"] = version.Original()
 	}
 	ctx.Data["RepoRootPath"] = setting.RepoRootPath
+	ctx.Data["RepoShards"] = setting.RepoShards
 	ctx.Data["CustomRootPath"] = setting.CustomPath
 	ctx.Data["StaticRootPath"] = setting.StaticRootPath
 	ctx.Data["LogRootPath"] = setting.LogRootPath
@@ -0,0 +1,31 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/common"
+)
+
+const (
+	tplAudit base.TplName = "admin/audit"
+)
+
+// Audit show the instance-wide audit log
+func Audit(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("audit.title")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminAudit"] = true
+
+	common.AuditLog(ctx, &models.FindAuditEventsOptions{})
+	if ctx.Written() {
+		return
+	}
+	ctx.HTML(http.StatusOK, tplAudit)
+}
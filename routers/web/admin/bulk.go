@@ -0,0 +1,131 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/task"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/forms"
+)
+
+const (
+	tplBulkRepos      base.TplName = "admin/repo/bulk"
+	tplBulkRepoReport base.TplName = "admin/repo/bulk_report"
+)
+
+func renderBulkRepos(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.repos.bulk")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminRepositories"] = true
+	ctx.Data["BulkRepoActions"] = api.BulkRepoActions
+	ctx.Data["visibility"] = "private"
+
+	page := ctx.FormInt("page")
+	if page <= 0 {
+		page = 1
+	}
+	tasks, count, err := models.FindBulkRepoTasks(0, db.ListOptions{
+		Page:     page,
+		PageSize: setting.UI.Admin.RepoPagingNum,
+	})
+	if err != nil {
+		ctx.ServerError("FindBulkRepoTasks", err)
+		return
+	}
+	apiTasks := make([]*api.BulkRepoTask, len(tasks))
+	for i := range tasks {
+		if apiTasks[i], err = convert.ToBulkRepoTask(tasks[i], ctx.User); err != nil {
+			ctx.ServerError("ToBulkRepoTask", err)
+			return
+		}
+	}
+	ctx.Data["Tasks"] = apiTasks
+
+	pager := context.NewPagination(int(count), setting.UI.Admin.RepoPagingNum, page, 5)
+	pager.SetDefaultParams(ctx)
+	ctx.Data["Page"] = pager
+}
+
+// BulkRepos shows the form for bulk repository operations and the operations run so far
+func BulkRepos(ctx *context.Context) {
+	renderBulkRepos(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.HTML(http.StatusOK, tplBulkRepos)
+}
+
+// BulkReposPost queues a bulk repository operation
+func BulkReposPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.AdminBulkRepoForm)
+	renderBulkRepos(ctx)
+	if ctx.Written() {
+		return
+	}
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, tplBulkRepos)
+		return
+	}
+
+	opts := api.BulkRepoOption{
+		Action:   api.BulkRepoAction(form.Action),
+		Repos:    strings.Fields(form.Repos),
+		NewOwner: strings.TrimSpace(form.NewOwner),
+		Team:     strings.TrimSpace(form.Team),
+	}
+	if opts.Action == api.BulkRepoVisibility {
+		private := form.Visibility != "public"
+		opts.Private = &private
+	}
+
+	t, err := task.BulkRepoOperation(ctx.User, nil, opts)
+	if err != nil {
+		if models.IsErrBulkRepoOptionInvalid(err) {
+			ctx.RenderWithErr(ctx.Tr("admin.repos.bulk.invalid", err.(models.ErrBulkRepoOptionInvalid).Reason), tplBulkRepos, form)
+			return
+		}
+		ctx.ServerError("BulkRepoOperation", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("admin.repos.bulk.queued", len(opts.Repos)))
+	ctx.Redirect(fmt.Sprintf("%s/admin/repos/bulk/%d", setting.AppSubURL, t.ID))
+}
+
+// BulkRepoReport shows the result of a bulk repository operation for each repository
+func BulkRepoReport(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.repos.bulk")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminRepositories"] = true
+
+	t, err := models.GetBulkRepoTaskByID(ctx.ParamsInt64(":id"), 0)
+	if err != nil {
+		if models.IsErrTaskDoesNotExist(err) {
+			ctx.NotFound("GetBulkRepoTaskByID", err)
+		} else {
+			ctx.ServerError("GetBulkRepoTaskByID", err)
+		}
+		return
+	}
+	apiTask, err := convert.ToBulkRepoTask(t, ctx.User)
+	if err != nil {
+		ctx.ServerError("ToBulkRepoTask", err)
+		return
+	}
+	ctx.Data["Task"] = apiTask
+	ctx.Data["MakePrivate"] = apiTask.Private != nil && *apiTask.Private
+	ctx.HTML(http.StatusOK, tplBulkRepoReport)
+}
@@ -6,17 +6,23 @@
 package admin
 
 import (
+	"net/http"
+
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/common"
 	"code.gitea.io/gitea/routers/web/explore"
+	org_service "code.gitea.io/gitea/services/org"
 )
 
 const (
-	tplOrgs base.TplName = "admin/org/list"
+	tplOrgs      base.TplName = "admin/org/list"
+	tplOrgsTrash base.TplName = "admin/org/trash"
 )
 
 // Organizations show all the organizations
@@ -34,3 +40,41 @@ func Organizations(ctx *context.Context) {
 		Visible: []structs.VisibleType{structs.VisibleTypePublic, structs.VisibleTypeLimited, structs.VisibleTypePrivate},
 	}, tplOrgs)
 }
+
+// OrgsTrash lists the organizations in the trash
+func OrgsTrash(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.organizations")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminOrganizations"] = true
+
+	common.DeletedOrgs(ctx, 0)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["CanEmptyTrash"] = true
+	ctx.HTML(http.StatusOK, tplOrgsTrash)
+}
+
+// EmptyOrgsTrash permanently deletes all organizations in the trash
+func EmptyOrgsTrash(ctx *context.Context) {
+	if err := org_service.EmptyTrash(ctx, ctx.User); err != nil {
+		ctx.ServerError("EmptyTrash", err)
+		return
+	}
+	log.Trace("Organization trash emptied by %s", ctx.User.Name)
+
+	ctx.Flash.Success(ctx.Tr("org.trash.empty_trash_success"))
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": setting.AppSubURL + "/admin/orgs/trash",
+	})
+}
+
+// RestoreDeletedOrg restores an organization from the trash
+func RestoreDeletedOrg(ctx *context.Context) {
+	common.RestoreDeletedOrg(ctx, setting.AppSubURL+"/admin/orgs/trash")
+}
+
+// PurgeDeletedOrg permanently deletes an organization in the trash
+func PurgeDeletedOrg(ctx *context.Context) {
+	common.PurgeDeletedOrg(ctx, setting.AppSubURL+"/admin/orgs/trash")
+}
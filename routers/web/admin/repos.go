@@ -17,13 +17,16 @@ import (
 	"code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/routers/common"
 	"code.gitea.io/gitea/routers/web/explore"
+	"code.gitea.io/gitea/services/audit"
 	repo_service "code.gitea.io/gitea/services/repository"
 )
 
 const (
 	tplRepos          base.TplName = "admin/repo/list"
 	tplUnadoptedRepos base.TplName = "admin/repo/unadopted"
+	tplReposTrash     base.TplName = "admin/repo/trash"
 )
 
 // Repos show all the repositories
@@ -31,6 +34,7 @@ func Repos(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("admin.repositories")
 	ctx.Data["PageIsAdmin"] = true
 	ctx.Data["PageIsAdminRepositories"] = true
+	ctx.Data["TrashEnabled"] = setting.Repository.TrashPeriod > 0
 
 	explore.RenderRepoSearch(ctx, &explore.RepoSearchOptions{
 		Private:  true,
@@ -55,14 +59,57 @@ func DeleteRepo(ctx *context.Context) {
 		ctx.ServerError("DeleteRepository", err)
 		return
 	}
+	audit.RecordRepo(ctx.Req, ctx.User, repo, models.AuditRepoDelete, "", "")
 	log.Trace("Repository deleted: %s", repo.FullName())
 
-	ctx.Flash.Success(ctx.Tr("repo.settings.deletion_success"))
+	if repo.IsDeleted() {
+		ctx.Flash.Success(ctx.Tr("repo.settings.deletion_trash_success", repo.PurgeUnix().FormatLong()))
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.settings.deletion_success"))
+	}
 	ctx.JSON(http.StatusOK, map[string]interface{}{
 		"redirect": setting.AppSubURL + "/admin/repos?page=" + ctx.FormString("page") + "&sort=" + ctx.FormString("sort"),
 	})
 }
 
+// ReposTrash lists the repositories of all owners in the trash
+func ReposTrash(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.repositories")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminRepositories"] = true
+
+	common.DeletedRepos(ctx, 0)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["CanEmptyTrash"] = true
+	ctx.HTML(http.StatusOK, tplReposTrash)
+}
+
+// EmptyTrash permanently deletes all repositories in the trash
+func EmptyTrash(ctx *context.Context) {
+	if err := repo_service.EmptyTrash(ctx, ctx.User); err != nil {
+		ctx.ServerError("EmptyTrash", err)
+		return
+	}
+	log.Trace("Trash emptied by %s", ctx.User.Name)
+
+	ctx.Flash.Success(ctx.Tr("repo.trash.empty_trash_success"))
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": setting.AppSubURL + "/admin/repos/trash",
+	})
+}
+
+// RestoreDeletedRepo restores a repository from the trash
+func RestoreDeletedRepo(ctx *context.Context) {
+	common.RestoreDeletedRepo(ctx, 0, setting.AppSubURL+"/admin/repos/trash")
+}
+
+// PurgeDeletedRepo permanently deletes a repository in the trash
+func PurgeDeletedRepo(ctx *context.Context) {
+	common.PurgeDeletedRepo(ctx, 0, setting.AppSubURL+"/admin/repos/trash")
+}
+
 // UnadoptedRepos lists the unadopted repositories
 func UnadoptedRepos(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("admin.repositories")
@@ -17,12 +17,14 @@ import (
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/password"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/web/explore"
 	router_user_setting "code.gitea.io/gitea/routers/web/user/setting"
+	"code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/forms"
 	"code.gitea.io/gitea/services/mailer"
 )
@@ -180,6 +182,8 @@ func NewUserPost(ctx *context.Context) {
 		return
 	}
 	log.Trace("Account created by admin (%s): %s", ctx.User.Name, u.Name)
+	notification.NotifyCreateUser(ctx.User, u)
+	audit.RecordUser(ctx.Req, ctx.User, u, models.AuditAdminUserCreate, "")
 
 	// Send email notification.
 	if form.SendNotify {
@@ -257,6 +261,7 @@ func EditUserPost(ctx *context.Context) {
 	if ctx.Written() {
 		return
 	}
+	before := *u
 
 	if ctx.HasError() {
 		ctx.HTML(http.StatusOK, tplUserEdit)
@@ -363,6 +368,7 @@ func EditUserPost(ctx *context.Context) {
 		return
 	}
 	log.Trace("Account profile updated by admin (%s): %s", ctx.User.Name, u.Name)
+	audit.RecordUser(ctx.Req, ctx.User, u, models.AuditAdminUserEdit, audit.UserChanges(&before, u))
 
 	ctx.Flash.Success(ctx.Tr("admin.users.update_profile_success"))
 	ctx.Redirect(setting.AppSubURL + "/admin/users/" + ctx.Params(":userid"))
@@ -394,6 +400,8 @@ func DeleteUser(ctx *context.Context) {
 		return
 	}
 	log.Trace("Account deleted by admin (%s): %s", ctx.User.Name, u.Name)
+	notification.NotifyDeleteUser(ctx.User, u)
+	audit.RecordUser(ctx.Req, ctx.User, u, models.AuditAdminUserDelete, "")
 
 	ctx.Flash.Success(ctx.Tr("admin.users.deletion_success"))
 	ctx.JSON(http.StatusOK, map[string]interface{}{
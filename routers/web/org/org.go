@@ -13,6 +13,7 @@ import (
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/services/forms"
@@ -74,6 +75,7 @@ func CreatePost(ctx *context.Context) {
 		return
 	}
 	log.Trace("Organization created: %s", org.Name)
+	notification.NotifyCreateOrganization(ctx.User, org)
 
 	ctx.Redirect(org.DashboardLink())
 }
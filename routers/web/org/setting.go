@@ -16,8 +16,11 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/common"
 	userSetting "code.gitea.io/gitea/routers/web/user/setting"
+	"code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/forms"
+	org_service "code.gitea.io/gitea/services/org"
 )
 
 const (
@@ -29,6 +32,10 @@ const (
 	tplSettingsHooks base.TplName = "org/settings/hooks"
 	// tplSettingsLabels template path for render labels settings
 	tplSettingsLabels base.TplName = "org/settings/labels"
+	// tplSettingsAudit template path for render audit log
+	tplSettingsAudit base.TplName = "org/settings/audit"
+	// tplSettingsTrash template path for render the repositories in the trash
+	tplSettingsTrash base.TplName = "org/settings/trash"
 )
 
 // Settings render the main settings page
@@ -162,7 +169,7 @@ func SettingsDelete(ctx *context.Context) {
 			return
 		}
 
-		if err := models.DeleteOrganization(org); err != nil {
+		if err := org_service.DeleteOrganization(ctx.User, org); err != nil {
 			if models.IsErrUserOwnRepos(err) {
 				ctx.Flash.Error(ctx.Tr("form.org_still_own_repo"))
 				ctx.Redirect(ctx.Org.OrgLink + "/settings/delete")
@@ -170,12 +177,17 @@ func SettingsDelete(ctx *context.Context) {
 				ctx.ServerError("DeleteOrganization", err)
 			}
 		} else {
+			audit.RecordUser(ctx.Req, ctx.User, org, models.AuditOrgDelete, "")
 			log.Trace("Organization deleted: %s", org.Name)
+			if org.IsDeleted() {
+				ctx.Flash.Success(ctx.Tr("org.settings.deletion_trash_success", org.PurgeUnix().FormatLong()))
+			}
 			ctx.Redirect(setting.AppSubURL + "/")
 		}
 		return
 	}
 
+	ctx.Data["TrashEnabled"] = setting.Repository.TrashPeriod > 0
 	ctx.HTML(http.StatusOK, tplSettingsDelete)
 }
 
@@ -218,3 +230,37 @@ func Labels(ctx *context.Context) {
 	ctx.Data["LabelTemplates"] = models.LabelTemplates
 	ctx.HTML(http.StatusOK, tplSettingsLabels)
 }
+
+// SettingsAudit render the audit log of the organization
+func SettingsAudit(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsSettingsAudit"] = true
+
+	common.AuditLog(ctx, &models.FindAuditEventsOptions{OwnerID: ctx.Org.Organization.ID})
+	if ctx.Written() {
+		return
+	}
+	ctx.HTML(http.StatusOK, tplSettingsAudit)
+}
+
+// SettingsTrash render the repositories of the organization in the trash
+func SettingsTrash(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsSettingsTrash"] = true
+
+	common.DeletedRepos(ctx, ctx.Org.Organization.ID)
+	if ctx.Written() {
+		return
+	}
+	ctx.HTML(http.StatusOK, tplSettingsTrash)
+}
+
+// SettingsTrashRestore restores a repository of the organization from the trash
+func SettingsTrashRestore(ctx *context.Context) {
+	common.RestoreDeletedRepo(ctx, ctx.Org.Organization.ID, ctx.Org.OrgLink+"/settings/trash")
+}
+
+// SettingsTrashPurge permanently deletes a repository of the organization in the trash
+func SettingsTrashPurge(ctx *context.Context) {
+	common.PurgeDeletedRepo(ctx, ctx.Org.Organization.ID, ctx.Org.OrgLink+"/settings/trash")
+}
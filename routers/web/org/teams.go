@@ -16,7 +16,9 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/utils"
+	"code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/forms"
+	repo_service "code.gitea.io/gitea/services/repository"
 )
 
 const (
@@ -64,9 +66,14 @@ func TeamsAction(ctx *context.Context) {
 			return
 		}
 		err = ctx.Org.Team.AddMember(ctx.User.ID)
+		if err == nil {
+			audit.RecordTeam(ctx.Req, ctx.User, ctx.Org.Team, models.AuditOrgTeamMemberAdd, ctx.User.Name, "team "+ctx.Org.Team.Name)
+		}
 	case "leave":
 		err = ctx.Org.Team.RemoveMember(ctx.User.ID)
-		if err != nil {
+		if err == nil {
+			audit.RecordTeam(ctx.Req, ctx.User, ctx.Org.Team, models.AuditOrgTeamMemberRemove, ctx.User.Name, "team "+ctx.Org.Team.Name)
+		} else {
 			if models.IsErrLastOrgOwner(err) {
 				ctx.Flash.Error(ctx.Tr("form.last_org_owner"))
 			} else {
@@ -90,7 +97,13 @@ func TeamsAction(ctx *context.Context) {
 		}
 		err = ctx.Org.Team.RemoveMember(uid)
 		page = "team"
-		if err != nil {
+		if err == nil {
+			if u, err := models.GetUserByID(uid); err != nil {
+				log.Error("GetUserByID: %v", err)
+			} else {
+				audit.RecordTeam(ctx.Req, ctx.User, ctx.Org.Team, models.AuditOrgTeamMemberRemove, u.Name, "team "+ctx.Org.Team.Name)
+			}
+		} else {
 			if models.IsErrLastOrgOwner(err) {
 				ctx.Flash.Error(ctx.Tr("form.last_org_owner"))
 			} else {
@@ -135,6 +148,9 @@ func TeamsAction(ctx *context.Context) {
 			ctx.Flash.Error(ctx.Tr("org.teams.add_duplicate_users"))
 		} else {
 			err = ctx.Org.Team.AddMember(u.ID)
+			if err == nil {
+				audit.RecordTeam(ctx.Req, ctx.User, ctx.Org.Team, models.AuditOrgTeamMemberAdd, u.Name, "team "+ctx.Org.Team.Name)
+			}
 		}
 
 		page = "team"
@@ -171,11 +187,11 @@ func TeamsRepoAction(ctx *context.Context) {
 	}
 
 	var err error
+	var repo *models.Repository
 	action := ctx.Params(":action")
 	switch action {
 	case "add":
 		repoName := path.Base(ctx.FormString("repo_name"))
-		var repo *models.Repository
 		repo, err = models.GetRepositoryByName(ctx.Org.Organization.ID, repoName)
 		if err != nil {
 			if models.IsErrRepoNotExist(err) {
@@ -186,13 +202,22 @@ func TeamsRepoAction(ctx *context.Context) {
 			ctx.ServerError("GetRepositoryByName", err)
 			return
 		}
-		err = ctx.Org.Team.AddRepository(repo)
+		err = repo_service.AddTeamRepository(ctx.User, ctx.Org.Team, repo)
 	case "remove":
-		err = ctx.Org.Team.RemoveRepository(ctx.FormInt64("repoid"))
+		repo, err = models.GetRepositoryByID(ctx.FormInt64("repoid"))
+		if err != nil {
+			if models.IsErrRepoNotExist(err) {
+				ctx.Redirect(ctx.Org.OrgLink + "/teams/" + ctx.Org.Team.LowerName + "/repositories")
+				return
+			}
+			ctx.ServerError("GetRepositoryByID", err)
+			return
+		}
+		err = repo_service.RemoveTeamRepository(ctx.User, ctx.Org.Team, repo)
 	case "addall":
-		err = ctx.Org.Team.AddAllRepositories()
+		err = repo_service.AddAllTeamRepositories(ctx.User, ctx.Org.Team)
 	case "removeall":
-		err = ctx.Org.Team.RemoveAllRepositories()
+		err = repo_service.RemoveAllTeamRepositories(ctx.User, ctx.Org.Team)
 	}
 
 	if err != nil {
@@ -201,6 +226,13 @@ func TeamsRepoAction(ctx *context.Context) {
 		return
 	}
 
+	switch action {
+	case "add":
+		audit.RecordRepo(ctx.Req, ctx.User, repo, models.AuditRepoTeamAdd, ctx.Org.Team.Name, "")
+	case "remove":
+		audit.RecordRepo(ctx.Req, ctx.User, repo, models.AuditRepoTeamRemove, ctx.Org.Team.Name, "")
+	}
+
 	if action == "addall" || action == "removeall" {
 		ctx.JSON(http.StatusOK, map[string]interface{}{
 			"redirect": ctx.Org.OrgLink + "/teams/" + ctx.Org.Team.LowerName + "/repositories",
@@ -261,7 +293,7 @@ func NewTeamPost(ctx *context.Context) {
 		return
 	}
 
-	if err := models.NewTeam(t); err != nil {
+	if err := repo_service.NewTeam(ctx.User, t); err != nil {
 		ctx.Data["Err_TeamName"] = true
 		switch {
 		case models.IsErrTeamAlreadyExist(err):
@@ -364,7 +396,7 @@ func EditTeamPost(ctx *context.Context) {
 		return
 	}
 
-	if err := models.UpdateTeam(t, isAuthChanged, isIncludeAllChanged); err != nil {
+	if err := repo_service.UpdateTeam(ctx.User, t, isAuthChanged, isIncludeAllChanged); err != nil {
 		ctx.Data["Err_TeamName"] = true
 		switch {
 		case models.IsErrTeamAlreadyExist(err):
@@ -374,6 +406,7 @@ func EditTeamPost(ctx *context.Context) {
 		}
 		return
 	}
+	audit.RecordTeam(ctx.Req, ctx.User, t, models.AuditOrgTeamEdit, "", "permission "+t.Authorize.String())
 	ctx.Redirect(ctx.Org.OrgLink + "/teams/" + t.LowerName)
 }
 
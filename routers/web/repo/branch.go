@@ -23,6 +23,7 @@ import (
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/utils"
 	"code.gitea.io/gitea/services/forms"
+	pull_service "code.gitea.io/gitea/services/pull"
 	release_service "code.gitea.io/gitea/services/release"
 	repo_service "code.gitea.io/gitea/services/repository"
 )
@@ -154,6 +155,88 @@ func RestoreBranchPost(ctx *context.Context) {
 	ctx.Flash.Success(ctx.Tr("repo.branch.restore_success", deletedBranch.Name))
 }
 
+// MergeUpstreamPost brings a branch of a fork up to date with its upstream branch
+func MergeUpstreamPost(ctx *context.Context) {
+	branchName := ctx.FormString("branch")
+	style := pull_service.MergeUpstreamStyle(ctx.FormString("style"))
+	if style == "" {
+		style = pull_service.MergeUpstreamFastForward
+	}
+	if !ctx.Repo.Repository.IsFork || !style.IsValid() {
+		ctx.NotFound("MergeUpstreamPost", nil)
+		return
+	}
+	if err := ctx.Repo.Repository.GetBaseRepo(); err != nil {
+		ctx.ServerError("GetBaseRepo", err)
+		return
+	}
+	perm, err := models.GetUserRepoPermission(ctx.Repo.Repository.BaseRepo, ctx.User)
+	if err != nil {
+		ctx.ServerError("GetUserRepoPermission", err)
+		return
+	}
+	if !perm.CanRead(models.UnitTypeCode) {
+		ctx.NotFound("MergeUpstreamPost", nil)
+		return
+	}
+
+	redirectLink := ctx.Repo.RepoLink + "/src/branch/" + util.PathEscapeSegments(branchName)
+	mergedStyle, err := pull_service.MergeUpstream(ctx.User, ctx.Repo.Repository, branchName, style)
+	if err != nil {
+		var flashError string
+		switch {
+		case models.IsErrBranchDoesNotExist(err):
+			ctx.NotFound("MergeUpstream", err)
+			return
+		case models.IsErrForkBranchDiverged(err):
+			flashError = ctx.Tr("repo.branch.merge_upstream_diverged", err.(models.ErrForkBranchDiverged).Ahead)
+		case models.IsErrMergeConflicts(err):
+			conflictError := err.(models.ErrMergeConflicts)
+			flashError, err = ctx.HTMLString(string(tplAlertDetails), map[string]interface{}{
+				"Message": ctx.Tr("repo.pulls.merge_conflict"),
+				"Summary": ctx.Tr("repo.pulls.merge_conflict_summary"),
+				"Details": utils.SanitizeFlashErrorString(conflictError.StdErr) + "<br>" + utils.SanitizeFlashErrorString(conflictError.StdOut),
+			})
+		case models.IsErrRebaseConflicts(err):
+			conflictError := err.(models.ErrRebaseConflicts)
+			flashError, err = ctx.HTMLString(string(tplAlertDetails), map[string]interface{}{
+				"Message": ctx.Tr("repo.pulls.rebase_conflict", utils.SanitizeFlashErrorString(conflictError.CommitSHA)),
+				"Summary": ctx.Tr("repo.pulls.rebase_conflict_summary"),
+				"Details": utils.SanitizeFlashErrorString(conflictError.StdErr) + "<br>" + utils.SanitizeFlashErrorString(conflictError.StdOut),
+			})
+		case git.IsErrPushOutOfDate(err):
+			flashError = ctx.Tr("repo.pulls.merge_out_of_date")
+		case git.IsErrPushRejected(err):
+			flashError = ctx.Tr("repo.pulls.push_rejected_no_message")
+			if message := err.(*git.ErrPushRejected).Message; len(message) > 0 {
+				flashError, err = ctx.HTMLString(string(tplAlertDetails), map[string]interface{}{
+					"Message": ctx.Tr("repo.pulls.push_rejected"),
+					"Summary": ctx.Tr("repo.pulls.push_rejected_summary"),
+					"Details": utils.SanitizeFlashErrorString(message),
+				})
+			}
+		default:
+			ctx.ServerError("MergeUpstream", err)
+			return
+		}
+		if err != nil {
+			ctx.ServerError("MergeUpstreamPost.HTMLString", err)
+			return
+		}
+		ctx.Flash.Error(flashError)
+		ctx.Redirect(redirectLink)
+		return
+	}
+
+	upstream := ctx.Repo.Repository.BaseRepo.FullName()
+	if mergedStyle == "" {
+		ctx.Flash.Info(ctx.Tr("repo.branch.merge_upstream_up_to_date", branchName, upstream))
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.branch.merge_upstream_success", branchName, upstream))
+	}
+	ctx.Redirect(redirectLink)
+}
+
 func redirect(ctx *context.Context) {
 	ctx.JSON(http.StatusOK, map[string]interface{}{
 		"redirect": ctx.Repo.RepoLink + "/branches",
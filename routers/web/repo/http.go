@@ -14,7 +14,6 @@ import (
 	"os"
 	"os/exec"
 	"path"
-	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -405,9 +404,6 @@ func (h *serviceHandler) sendFile(contentType, file string) {
 	http.ServeFile(h.w, h.r, reqFile)
 }
 
-// one or more key=value pairs separated by colons
-var safeGitProtocolHeader = regexp.MustCompile(`^[0-9a-zA-Z]+=[0-9a-zA-Z]+(:[0-9a-zA-Z]+=[0-9a-zA-Z]+)*$`)
-
 func getGitConfig(option, dir string) string {
 	out, err := git.NewCommand("config", option).RunInDir(dir)
 	if err != nil {
@@ -478,7 +474,7 @@ func serviceRPC(h serviceHandler, service string) {
 	// set this for allow pre-receive and post-receive execute
 	h.environ = append(h.environ, "SSH_ORIGINAL_COMMAND="+service)
 
-	if protocol := h.r.Header.Get("Git-Protocol"); protocol != "" && safeGitProtocolHeader.MatchString(protocol) {
+	if protocol := h.r.Header.Get("Git-Protocol"); protocol != "" && git.IsSafeProtocol(protocol) {
 		h.environ = append(h.environ, "GIT_PROTOCOL="+protocol)
 	}
 
@@ -551,7 +547,7 @@ func GetInfoRefs(ctx *context.Context) {
 	if hasAccess(getServiceType(h.r), *h, false) {
 		service := getServiceType(h.r)
 
-		if protocol := h.r.Header.Get("Git-Protocol"); protocol != "" && safeGitProtocolHeader.MatchString(protocol) {
+		if protocol := h.r.Header.Get("Git-Protocol"); protocol != "" && git.IsSafeProtocol(protocol) {
 			h.environ = append(h.environ, "GIT_PROTOCOL="+protocol)
 		}
 		h.environ = append(os.Environ(), h.environ...)
@@ -2006,6 +2006,12 @@ func UpdateIssueStatus(ctx *context.Context) {
 					})
 					return
 				}
+				if models.IsErrPullHeadRepoInTrash(err) {
+					ctx.JSON(http.StatusPreconditionFailed, map[string]interface{}{
+						"error": "cannot reopen this pull request because its head repository is in the trash",
+					})
+					return
+				}
 				ctx.ServerError("ChangeStatus", err)
 				return
 			}
@@ -2099,6 +2105,11 @@ func NewComment(ctx *context.Context) {
 				if err := issue_service.ChangeStatus(issue, ctx.User, isClosed); err != nil {
 					log.Error("ChangeStatus: %v", err)
 
+					if models.IsErrPullHeadRepoInTrash(err) {
+						ctx.Flash.Error(ctx.Tr("repo.pulls.reopen_head_repo_in_trash"))
+						ctx.Redirect(fmt.Sprintf("%s/pulls/%d", ctx.Repo.RepoLink, issue.Index), http.StatusSeeOther)
+						return
+					}
 					if models.IsErrDependenciesLeft(err) {
 						if issue.IsPull {
 							ctx.Flash.Error(ctx.Tr("repo.issues.dependency.pr_close_blocked"))
@@ -88,8 +88,12 @@ func GetActiveStopwatch(c *context.Context) {
 		c.ServerError("GetIssueByID", err)
 		return
 	}
-	if err = issue.LoadRepo(); err != nil {
-		c.ServerError("LoadRepo", err)
+	if issue.Repo, err = models.GetRepositoryByID(issue.RepoID); err != nil {
+		// the repository of the issue is in the trash
+		if models.IsErrRepoNotExist(err) {
+			return
+		}
+		c.ServerError("GetRepositoryByID", err)
 		return
 	}
 
@@ -219,8 +219,9 @@ func MigratePost(ctx *context.Context) {
 		Comments:       form.Issues || form.PullRequests,
 		PullRequests:   form.PullRequests,
 		Releases:       form.Releases,
+		MirrorMetadata: form.Mirror && form.MirrorMetadata,
 	}
-	if opts.Mirror {
+	if opts.Mirror && !opts.MirrorMetadata {
 		opts.Issues = false
 		opts.Milestones = false
 		opts.Labels = false
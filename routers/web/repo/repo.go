@@ -18,6 +18,7 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/graceful"
 	"code.gitea.io/gitea/modules/log"
+	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/web"
@@ -27,8 +28,9 @@ import (
 )
 
 const (
-	tplCreate       base.TplName = "repo/create"
-	tplAlertDetails base.TplName = "base/alert_details"
+	tplCreate                  base.TplName = "repo/create"
+	tplCreateTemplateVariables base.TplName = "repo/create_template_variables"
+	tplAlertDetails            base.TplName = "base/alert_details"
 )
 
 // MustBeNotEmpty render when a repo is a empty git dir
@@ -87,6 +89,12 @@ func checkContextUser(ctx *context.Context, uid int64) *models.User {
 		return nil
 	}
 
+	// Organizations in the trash cannot get new repositories
+	if org.IsDeleted() {
+		ctx.NotFound("GetUserByID", nil)
+		return nil
+	}
+
 	// Check ownership of organization.
 	if !org.IsOrganization() {
 		ctx.Error(http.StatusForbidden)
@@ -147,6 +155,7 @@ func Create(ctx *context.Context) {
 		if err == nil && templateRepo.CheckUnitUser(ctxUser, models.UnitTypeCode) {
 			ctx.Data["repo_template"] = templateID
 			ctx.Data["repo_template_name"] = templateRepo.Name
+			loadTemplateVariables(ctx, templateRepo, nil)
 		}
 	}
 
@@ -156,8 +165,69 @@ func Create(ctx *context.Context) {
 	ctx.HTML(http.StatusOK, tplCreate)
 }
 
+// templateVariableFormPrefix prefixes the names of the form fields of template variables
+const templateVariableFormPrefix = "template_variable_"
+
+// loadTemplateVariables prepares the variables declared by a template to be entered,
+// pre-filled with the given values or the defaults of the variables
+func loadTemplateVariables(ctx *context.Context, templateRepo *models.Repository, values map[string]string) {
+	config, err := repo_module.GetTemplateConfig(templateRepo)
+	if err != nil {
+		log.Warn("Unable to load the template config of %-v: %v", templateRepo, err)
+		ctx.Data["TemplateConfigError"] = err.Error()
+		return
+	}
+	if config == nil {
+		return
+	}
+
+	if values == nil {
+		values = make(map[string]string, len(config.Variables))
+		for _, v := range config.Variables {
+			values[v.Name] = v.Default
+		}
+	}
+	ctx.Data["TemplateVariables"] = config.Variables
+	ctx.Data["TemplateVariableValues"] = values
+}
+
+// templateVariablesFromForm returns the entered values of template variables.
+// A checkbox is preceded by a hidden field so that the last value wins.
+func templateVariablesFromForm(ctx *context.Context) map[string]string {
+	variables := make(map[string]string)
+	for key, values := range ctx.Req.Form {
+		if name := strings.TrimPrefix(key, templateVariableFormPrefix); name != key && len(values) > 0 {
+			variables[name] = values[len(values)-1]
+		}
+	}
+	return variables
+}
+
+// TemplateVariables renders the fields to enter the variables declared by a template
+func TemplateVariables(ctx *context.Context) {
+	templateRepo, err := models.GetRepositoryByID(ctx.FormInt64("template_id"))
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			ctx.NotFound("GetRepositoryByID", err)
+		} else {
+			ctx.ServerError("GetRepositoryByID", err)
+		}
+		return
+	}
+	if !templateRepo.IsTemplate || !templateRepo.CheckUnitUser(ctx.User, models.UnitTypeCode) {
+		ctx.NotFound("CheckUnitUser", nil)
+		return
+	}
+
+	loadTemplateVariables(ctx, templateRepo, nil)
+	ctx.HTML(http.StatusOK, tplCreateTemplateVariables)
+}
+
 func handleCreateError(ctx *context.Context, owner *models.User, err error, name string, tpl base.TplName, form interface{}) {
 	switch {
+	case models.IsErrTemplateVariableInvalid(err):
+		errTemplateVariable := err.(models.ErrTemplateVariableInvalid)
+		ctx.RenderWithErr(ctx.Tr("repo.template.variable_invalid", errTemplateVariable.Name, errTemplateVariable.Reason), tpl, form)
 	case models.IsErrReachLimitOfRepo(err):
 		ctx.RenderWithErr(ctx.Tr("repo.form.reach_limit_of_creation", owner.MaxCreationLimit()), tpl, form)
 	case models.IsErrRepoAlreadyExist(err):
@@ -214,24 +284,29 @@ func CreatePost(ctx *context.Context) {
 	var err error
 	if form.RepoTemplate > 0 {
 		opts := models.GenerateRepoOptions{
-			Name:        form.RepoName,
-			Description: form.Description,
-			Private:     form.Private,
-			GitContent:  form.GitContent,
-			Topics:      form.Topics,
-			GitHooks:    form.GitHooks,
-			Webhooks:    form.Webhooks,
-			Avatar:      form.Avatar,
-			IssueLabels: form.Labels,
+			Name:              form.RepoName,
+			Description:       form.Description,
+			Private:           form.Private,
+			GitContent:        form.GitContent,
+			Topics:            form.Topics,
+			GitHooks:          form.GitHooks,
+			Webhooks:          form.Webhooks,
+			Avatar:            form.Avatar,
+			IssueLabels:       form.Labels,
+			ProtectedBranches: form.ProtectedBranches,
+			Teams:             form.Teams,
+			Variables:         templateVariablesFromForm(ctx),
 		}
 
-		if !opts.IsValid() {
-			ctx.RenderWithErr(ctx.Tr("repo.template.one_item"), tplCreate, form)
+		templateRepo := getRepository(ctx, form.RepoTemplate)
+		if ctx.Written() {
 			return
 		}
+		ctx.Data["repo_template_name"] = templateRepo.Name
+		loadTemplateVariables(ctx, templateRepo, opts.Variables)
 
-		templateRepo := getRepository(ctx, form.RepoTemplate)
-		if ctx.Written() {
+		if !opts.IsValid() {
+			ctx.RenderWithErr(ctx.Tr("repo.template.one_item"), tplCreate, form)
 			return
 		}
 
@@ -279,9 +354,9 @@ func Action(ctx *context.Context) {
 	case "unwatch":
 		err = models.WatchRepo(ctx.User.ID, ctx.Repo.Repository.ID, false)
 	case "star":
-		err = models.StarRepo(ctx.User.ID, ctx.Repo.Repository.ID, true)
+		err = repo_service.StarRepo(ctx.User, ctx.Repo.Repository, true)
 	case "unstar":
-		err = models.StarRepo(ctx.User.ID, ctx.Repo.Repository.ID, false)
+		err = repo_service.StarRepo(ctx.User, ctx.Repo.Repository, false)
 	case "accept_transfer":
 		err = acceptOrRejectRepoTransfer(ctx, true)
 	case "reject_transfer":
@@ -30,7 +30,9 @@ import (
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/validation"
 	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/common"
 	"code.gitea.io/gitea/routers/utils"
+	"code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/forms"
 	"code.gitea.io/gitea/services/mailer"
 	mirror_service "code.gitea.io/gitea/services/mirror"
@@ -46,6 +48,7 @@ const (
 	tplGithooks        base.TplName = "repo/settings/githooks"
 	tplGithookEdit     base.TplName = "repo/settings/githook_edit"
 	tplDeployKeys      base.TplName = "repo/settings/deploy_keys"
+	tplSettingsAudit   base.TplName = "repo/settings/audit"
 	tplProtectedBranch base.TplName = "repo/settings/protected_branch"
 )
 
@@ -57,14 +60,87 @@ func Settings(ctx *context.Context) {
 	ctx.Data["MirrorsEnabled"] = setting.Mirror.Enabled
 	ctx.Data["DisableNewPushMirrors"] = setting.Mirror.DisableNewPush
 	ctx.Data["DefaultMirrorInterval"] = setting.Mirror.DefaultInterval
+	if setting.Repository.TrashPeriod > 0 {
+		ctx.Data["TrashPurgeTime"] = time.Now().Add(setting.Repository.TrashPeriod)
+	}
 
 	signing, _ := models.SigningKey(ctx.Repo.Repository.RepoPath())
 	ctx.Data["SigningKeyAvailable"] = len(signing) > 0
 	ctx.Data["SigningSettings"] = setting.Repository.Signing
 
+	if ctx.Repo.Mirror != nil && ctx.Repo.Mirror.HasWebhook() {
+		webhookSecret, err := mirror_service.GetWebhookSecret(ctx.Repo.Mirror)
+		if err != nil {
+			ctx.ServerError("GetWebhookSecret", err)
+			return
+		}
+		ctx.Data["MirrorWebhookSecret"] = webhookSecret
+		ctx.Data["MirrorWebhookURL"] = ctx.Repo.Repository.APIURL() + "/mirror-sync/webhook"
+	}
+	if ctx.Repo.Mirror != nil {
+		canSyncMetadata, err := mirror_service.CanSyncMetadata(ctx.Repo.Repository)
+		if err != nil {
+			ctx.ServerError("CanSyncMetadata", err)
+			return
+		}
+		ctx.Data["CanSyncMirrorMetadata"] = canSyncMetadata
+	}
+
+	for _, m := range ctx.Repo.Repository.PushMirrors {
+		if err := m.LoadSyncs(); err != nil {
+			ctx.ServerError("LoadSyncs", err)
+			return
+		}
+	}
+
+	templateRepo, generation := loadTemplateSync(ctx)
+	if ctx.Written() {
+		return
+	}
+	if generation != nil {
+		syncPull, err := repo_service.GetTemplateSyncPullRequest(generation)
+		if err != nil {
+			ctx.ServerError("GetTemplateSyncPullRequest", err)
+			return
+		}
+		ctx.Data["TemplateRepo"] = templateRepo
+		ctx.Data["RepoGeneration"] = generation
+		ctx.Data["TemplateSyncPull"] = syncPull
+	}
+
 	ctx.HTML(http.StatusOK, tplSettingsOptions)
 }
 
+// loadTemplateSync returns the template of the repository and how the repository was generated
+// from it, if the repository can be synced with a template the user can read
+func loadTemplateSync(ctx *context.Context) (*models.Repository, *models.RepoGeneration) {
+	repo := ctx.Repo.Repository
+	if repo.TemplateID == 0 {
+		return nil, nil
+	}
+
+	generation, err := models.GetRepoGeneration(repo.ID)
+	if err != nil {
+		ctx.ServerError("GetRepoGeneration", err)
+		return nil, nil
+	} else if generation == nil {
+		return nil, nil
+	}
+
+	templateRepo, err := models.GetRepositoryByID(generation.TemplateID)
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			return nil, nil
+		}
+		ctx.ServerError("GetRepositoryByID", err)
+		return nil, nil
+	}
+	if !templateRepo.CheckUnitUser(ctx.User, models.UnitTypeCode) {
+		return nil, nil
+	}
+	return templateRepo, generation
+}
+
 // SettingsPost response for changes of a repository
 func SettingsPost(ctx *context.Context) {
 	form := web.GetForm(ctx).(*forms.RepoSettingForm)
@@ -137,7 +213,7 @@ func SettingsPost(ctx *context.Context) {
 		}
 
 		repo.IsPrivate = form.Private
-		if err := models.UpdateRepository(repo, visibilityChanged); err != nil {
+		if err := repo_service.UpdateRepository(ctx.User, repo, visibilityChanged); err != nil {
 			ctx.ServerError("UpdateRepository", err)
 			return
 		}
@@ -156,6 +232,17 @@ func SettingsPost(ctx *context.Context) {
 		// as an error on the UI for this action
 		ctx.Data["Err_RepoName"] = nil
 
+		if form.SyncMetadata {
+			canSyncMetadata, err := mirror_service.CanSyncMetadata(repo)
+			if err != nil {
+				ctx.ServerError("CanSyncMetadata", err)
+				return
+			} else if !canSyncMetadata {
+				ctx.RenderWithErr(ctx.Tr("repo.mirror_sync_metadata_unavailable"), tplSettingsOptions, &form)
+				return
+			}
+		}
+
 		interval, err := time.ParseDuration(form.Interval)
 		if err != nil || (interval != 0 && interval < setting.Mirror.MinInterval) {
 			ctx.Data["Err_Interval"] = true
@@ -214,6 +301,7 @@ func SettingsPost(ctx *context.Context) {
 
 		ctx.Repo.Mirror.LFS = form.LFS
 		ctx.Repo.Mirror.LFSEndpoint = form.LFSEndpoint
+		ctx.Repo.Mirror.SyncMetadata = form.SyncMetadata
 		if err := models.UpdateMirror(ctx.Repo.Mirror); err != nil {
 			ctx.ServerError("UpdateMirror", err)
 			return
@@ -233,6 +321,26 @@ func SettingsPost(ctx *context.Context) {
 		ctx.Flash.Info(ctx.Tr("repo.settings.mirror_sync_in_progress"))
 		ctx.Redirect(repo.Link() + "/settings")
 
+	case "mirror-webhook-generate", "mirror-webhook-remove":
+		if !setting.Mirror.Enabled || !repo.IsMirror {
+			ctx.NotFound("", nil)
+			return
+		}
+
+		var err error
+		if ctx.FormString("action") == "mirror-webhook-generate" {
+			err = mirror_service.GenerateWebhookSecret(ctx.Repo.Mirror)
+		} else {
+			err = mirror_service.RemoveWebhookSecret(ctx.Repo.Mirror)
+		}
+		if err != nil {
+			ctx.ServerError("UpdateMirrorWebhookSecret", err)
+			return
+		}
+
+		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
+		ctx.Redirect(repo.Link() + "/settings")
+
 	case "push-mirror-sync":
 		if !setting.Mirror.Enabled {
 			ctx.NotFound("", nil)
@@ -245,7 +353,7 @@ func SettingsPost(ctx *context.Context) {
 			return
 		}
 
-		mirror_service.AddPushMirrorToQueue(m.ID)
+		mirror_service.AddPushMirrorToQueue(m.ID, models.PushMirrorSyncTriggerManual)
 
 		ctx.Flash.Info(ctx.Tr("repo.settings.mirror_sync_in_progress"))
 		ctx.Redirect(repo.Link() + "/settings")
@@ -296,9 +404,9 @@ func SettingsPost(ctx *context.Context) {
 			return
 		}
 
-		address, err := forms.ParseRemoteAddr(form.PushMirrorAddress, form.PushMirrorUsername, form.PushMirrorPassword)
+		address, isSSH, err := forms.ParsePushMirrorAddr(form.PushMirrorAddress, form.PushMirrorUsername, form.PushMirrorPassword)
 		if err == nil {
-			err = migrations.IsMigrateURLAllowed(address, ctx.User)
+			err = migrations.IsPushMirrorURLAllowed(address, ctx.User)
 		}
 		if err != nil {
 			ctx.Data["Err_PushMirrorAddress"] = true
@@ -306,6 +414,17 @@ func SettingsPost(ctx *context.Context) {
 			return
 		}
 
+		if err := models.ValidatePushMirrorFilter(form.PushMirrorBranchFilter); err != nil {
+			ctx.Data["Err_PushMirrorBranchFilter"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.settings.mirror_settings.push_mirror.filter_invalid", err), tplSettingsOptions, &form)
+			return
+		}
+		if err := models.ValidatePushMirrorFilter(form.PushMirrorTagFilter); err != nil {
+			ctx.Data["Err_PushMirrorTagFilter"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.settings.mirror_settings.push_mirror.filter_invalid", err), tplSettingsOptions, &form)
+			return
+		}
+
 		remoteSuffix, err := util.RandomString(10)
 		if err != nil {
 			ctx.ServerError("RandomString", err)
@@ -313,10 +432,19 @@ func SettingsPost(ctx *context.Context) {
 		}
 
 		m := &models.PushMirror{
-			RepoID:     repo.ID,
-			Repo:       repo,
-			RemoteName: fmt.Sprintf("remote_mirror_%s", remoteSuffix),
-			Interval:   interval,
+			RepoID:       repo.ID,
+			Repo:         repo,
+			RemoteName:   fmt.Sprintf("remote_mirror_%s", remoteSuffix),
+			BranchFilter: strings.TrimSpace(form.PushMirrorBranchFilter),
+			TagFilter:    strings.TrimSpace(form.PushMirrorTagFilter),
+			SyncOnCommit: form.PushMirrorSyncOnCommit,
+			Interval:     interval,
+		}
+		if isSSH {
+			if err := mirror_service.GeneratePushMirrorKey(m); err != nil {
+				ctx.ServerError("GeneratePushMirrorKey", err)
+				return
+			}
 		}
 		if err := models.InsertPushMirror(m); err != nil {
 			ctx.ServerError("InsertPushMirror", err)
@@ -331,9 +459,44 @@ func SettingsPost(ctx *context.Context) {
 			return
 		}
 
-		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
+		if isSSH {
+			ctx.Flash.Success(ctx.Tr("repo.settings.mirror_settings.push_mirror.add_deploy_key"))
+		} else {
+			ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
+		}
 		ctx.Redirect(repo.Link() + "/settings")
 
+	case "template-sync":
+		if _, generation := loadTemplateSync(ctx); ctx.Written() {
+			return
+		} else if generation == nil {
+			ctx.NotFound("", nil)
+			return
+		}
+
+		pr, err := repo_service.SyncFromTemplate(ctx.User, repo)
+		if err != nil {
+			switch {
+			case models.IsErrTemplateSyncConflict(err):
+				ctx.Flash.Error(ctx.Tr("repo.settings.template_sync.conflict"))
+			case models.IsErrPullRequestAlreadyExists(err):
+				ctx.Flash.Error(ctx.Tr("repo.settings.template_sync.pending", fmt.Sprintf("%s/pulls/%d", repo.Link(), err.(models.ErrPullRequestAlreadyExists).IssueID)))
+			default:
+				ctx.ServerError("SyncFromTemplate", err)
+				return
+			}
+			ctx.Redirect(repo.Link() + "/settings")
+			return
+		}
+
+		if pr == nil {
+			ctx.Flash.Info(ctx.Tr("repo.settings.template_sync.up_to_date"))
+			ctx.Redirect(repo.Link() + "/settings")
+			return
+		}
+		ctx.Flash.Success(ctx.Tr("repo.settings.template_sync.success"))
+		ctx.Redirect(fmt.Sprintf("%s/pulls/%d", repo.Link(), pr.Index))
+
 	case "advanced":
 		var repoChanged bool
 		var units []models.RepoUnit
@@ -600,6 +763,8 @@ func SettingsPost(ctx *context.Context) {
 			ctx.Repo.GitRepo = nil
 		}
 
+		// keep the original owner for the audit log, a direct transfer changes it
+		oldRepo := *repo
 		if err := repo_service.StartRepositoryTransfer(ctx.User, newOwner, repo, nil); err != nil {
 			if models.IsErrRepoAlreadyExist(err) {
 				ctx.RenderWithErr(ctx.Tr("repo.settings.new_owner_has_same_repo"), tplSettingsOptions, nil)
@@ -612,6 +777,7 @@ func SettingsPost(ctx *context.Context) {
 			return
 		}
 
+		audit.RecordRepo(ctx.Req, ctx.User, &oldRepo, models.AuditRepoTransfer, "", "to "+newOwner.Name)
 		log.Trace("Repository transfer process was started: %s/%s -> %s", ctx.Repo.Owner.Name, repo.Name, newOwner)
 		ctx.Flash.Success(ctx.Tr("repo.settings.transfer_started", newOwner.DisplayName()))
 		ctx.Redirect(ctx.Repo.Owner.HomeLink() + "/" + repo.Name + "/settings")
@@ -667,9 +833,14 @@ func SettingsPost(ctx *context.Context) {
 			ctx.ServerError("DeleteRepository", err)
 			return
 		}
+		audit.RecordRepo(ctx.Req, ctx.User, repo, models.AuditRepoDelete, "", "")
 		log.Trace("Repository deleted: %s/%s", ctx.Repo.Owner.Name, repo.Name)
 
-		ctx.Flash.Success(ctx.Tr("repo.settings.deletion_success"))
+		if repo.IsDeleted() {
+			ctx.Flash.Success(ctx.Tr("repo.settings.deletion_trash_success", repo.PurgeUnix().FormatLong()))
+		} else {
+			ctx.Flash.Success(ctx.Tr("repo.settings.deletion_success"))
+		}
 		ctx.Redirect(ctx.Repo.Owner.DashboardLink())
 
 	case "delete-wiki":
@@ -703,7 +874,7 @@ func SettingsPost(ctx *context.Context) {
 			return
 		}
 
-		if err := repo.SetArchiveRepoState(true); err != nil {
+		if err := repo_service.SetArchiveRepoState(ctx.User, repo, true); err != nil {
 			log.Error("Tried to archive a repo: %s", err)
 			ctx.Flash.Error(ctx.Tr("repo.settings.archive.error"))
 			ctx.Redirect(ctx.Repo.RepoLink + "/settings")
@@ -721,7 +892,7 @@ func SettingsPost(ctx *context.Context) {
 			return
 		}
 
-		if err := repo.SetArchiveRepoState(false); err != nil {
+		if err := repo_service.SetArchiveRepoState(ctx.User, repo, false); err != nil {
 			log.Error("Tried to unarchive a repo: %s", err)
 			ctx.Flash.Error(ctx.Tr("repo.settings.unarchive.error"))
 			ctx.Redirect(ctx.Repo.RepoLink + "/settings")
@@ -829,11 +1000,13 @@ func CollaborationPost(ctx *context.Context) {
 		return
 	}
 
-	if err = ctx.Repo.Repository.AddCollaborator(u); err != nil {
+	if err = repo_service.AddCollaborator(ctx.User, ctx.Repo.Repository, u, models.AccessModeNone); err != nil {
 		ctx.ServerError("AddCollaborator", err)
 		return
 	}
 
+	audit.RecordRepo(ctx.Req, ctx.User, ctx.Repo.Repository, models.AuditRepoCollaboratorAdd, u.Name, "")
+
 	if setting.Service.EnableNotifyMail {
 		mailer.SendCollaboratorMail(u, ctx.User, ctx.Repo.Repository)
 	}
@@ -844,18 +1017,29 @@ func CollaborationPost(ctx *context.Context) {
 
 // ChangeCollaborationAccessMode response for changing access of a collaboration
 func ChangeCollaborationAccessMode(ctx *context.Context) {
-	if err := ctx.Repo.Repository.ChangeCollaborationAccessMode(
-		ctx.FormInt64("uid"),
-		models.AccessMode(ctx.FormInt("mode"))); err != nil {
+	u, err := models.GetUserByID(ctx.FormInt64("uid"))
+	if err != nil {
+		log.Error("GetUserByID: %v", err)
+		return
+	}
+
+	mode := models.AccessMode(ctx.FormInt("mode"))
+	if err := repo_service.ChangeCollaborationAccessMode(ctx.User, ctx.Repo.Repository, u, mode); err != nil {
 		log.Error("ChangeCollaborationAccessMode: %v", err)
+		return
 	}
+	audit.RecordRepo(ctx.Req, ctx.User, ctx.Repo.Repository, models.AuditRepoCollaboratorEdit, u.Name, "access mode "+mode.String())
 }
 
 // DeleteCollaboration delete a collaboration for a repository
 func DeleteCollaboration(ctx *context.Context) {
-	if err := ctx.Repo.Repository.DeleteCollaboration(ctx.FormInt64("id")); err != nil {
+	u, err := models.GetUserByID(ctx.FormInt64("id"))
+	if err != nil {
+		ctx.Flash.Error("GetUserByID: " + err.Error())
+	} else if err := repo_service.DeleteCollaboration(ctx.User, ctx.Repo.Repository, u); err != nil {
 		ctx.Flash.Error("DeleteCollaboration: " + err.Error())
 	} else {
+		audit.RecordRepo(ctx.Req, ctx.User, ctx.Repo.Repository, models.AuditRepoCollaboratorRemove, u.Name, "")
 		ctx.Flash.Success(ctx.Tr("repo.settings.remove_collaborator_success"))
 	}
 
@@ -901,11 +1085,13 @@ func AddTeamPost(ctx *context.Context) {
 		return
 	}
 
-	if err = team.AddRepository(ctx.Repo.Repository); err != nil {
+	if err = repo_service.AddTeamRepository(ctx.User, team, ctx.Repo.Repository); err != nil {
 		ctx.ServerError("team.AddRepository", err)
 		return
 	}
 
+	audit.RecordRepo(ctx.Req, ctx.User, ctx.Repo.Repository, models.AuditRepoTeamAdd, team.Name, "")
+
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_team_success"))
 	ctx.Redirect(ctx.Repo.RepoLink + "/settings/collaboration")
 }
@@ -924,11 +1110,13 @@ func DeleteTeam(ctx *context.Context) {
 		return
 	}
 
-	if err = team.RemoveRepository(ctx.Repo.Repository.ID); err != nil {
+	if err = repo_service.RemoveTeamRepository(ctx.User, team, ctx.Repo.Repository); err != nil {
 		ctx.ServerError("team.RemoveRepositorys", err)
 		return
 	}
 
+	audit.RecordRepo(ctx.Req, ctx.User, ctx.Repo.Repository, models.AuditRepoTeamRemove, team.Name, "")
+
 	ctx.Flash.Success(ctx.Tr("repo.settings.remove_team_success"))
 	ctx.JSON(http.StatusOK, map[string]interface{}{
 		"redirect": ctx.Repo.RepoLink + "/settings/collaboration",
@@ -1179,3 +1367,15 @@ func selectPushMirrorByForm(form *forms.RepoSettingForm, repo *models.Repository
 
 	return nil, fmt.Errorf("PushMirror[%v] not associated to repository %v", id, repo)
 }
+
+// SettingsAudit render the audit log of the repository
+func SettingsAudit(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings")
+	ctx.Data["PageIsSettingsAudit"] = true
+
+	common.AuditLog(ctx, &models.FindAuditEventsOptions{RepoID: ctx.Repo.Repository.ID})
+	if ctx.Written() {
+		return
+	}
+	ctx.HTML(http.StatusOK, tplSettingsAudit)
+}
@@ -0,0 +1,242 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/task"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/forms"
+)
+
+const (
+	tplSettingsIssueImport base.TplName = "repo/settings/issue_import"
+
+	// issueImportTaskLimit is the number of recent imports listed on the import page
+	issueImportTaskLimit = 10
+)
+
+func issueImportEnabled(ctx *context.Context) bool {
+	if !ctx.Repo.Repository.UnitEnabled(models.UnitTypeIssues) {
+		ctx.NotFound("IssueImport", nil)
+		return false
+	}
+	ctx.Data["Title"] = ctx.Tr("repo.settings")
+	ctx.Data["PageIsSettingsIssueImport"] = true
+	ctx.Data["IssueImportFormats"] = migrations.IssueImportFormats
+
+	tasks, err := models.FindIssueImportTasks(ctx.Repo.Repository.ID, issueImportTaskLimit)
+	if err != nil {
+		ctx.ServerError("FindIssueImportTasks", err)
+		return false
+	}
+	imports := make([]*issueImportTask, 0, len(tasks))
+	for _, t := range tasks {
+		payload, err := t.IssueImportPayload()
+		if err != nil {
+			ctx.ServerError("IssueImportPayload", err)
+			return false
+		}
+		imports = append(imports, &issueImportTask{Task: t, Payload: payload})
+	}
+	ctx.Data["IssueImports"] = imports
+	return true
+}
+
+// issueImportTask is an import task with its payload, as listed on the import page
+type issueImportTask struct {
+	*models.Task
+	Payload *models.IssueImportPayload
+}
+
+// pendingIssueImportKey is the session key of the upload waiting to be mapped for a repository
+func pendingIssueImportKey(repo *models.Repository) string {
+	return fmt.Sprintf("issue_import_upload_%d", repo.ID)
+}
+
+// takePendingIssueImport removes the upload waiting to be mapped from the session and returns its UUID
+func takePendingIssueImport(ctx *context.Context) string {
+	key := pendingIssueImportKey(ctx.Repo.Repository)
+	uuid, _ := ctx.Session.Get(key).(string)
+	if uuid != "" {
+		if err := ctx.Session.Delete(key); err != nil {
+			log.Error("Session.Delete: %v", err)
+		}
+	}
+	return uuid
+}
+
+// deletePendingIssueImport deletes the upload of an abandoned mapping
+func deletePendingIssueImport(ctx *context.Context) {
+	if uuid := takePendingIssueImport(ctx); uuid != "" {
+		if err := models.DeleteUploadByUUID(uuid); err != nil {
+			log.Error("DeleteUploadByUUID: %v", err)
+		}
+	}
+}
+
+// SettingsIssueImport render the page to upload an issue export
+func SettingsIssueImport(ctx *context.Context) {
+	if !issueImportEnabled(ctx) {
+		return
+	}
+	// leaving the mapping abandons its upload
+	deletePendingIssueImport(ctx)
+
+	ctx.Data["format"] = migrations.IssueImportJiraXML
+	ctx.HTML(http.StatusOK, tplSettingsIssueImport)
+}
+
+// SettingsIssueImportCancelPost abandons the mapping of an uploaded issue export
+func SettingsIssueImportCancelPost(ctx *context.Context) {
+	if !issueImportEnabled(ctx) {
+		return
+	}
+	deletePendingIssueImport(ctx)
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/issues/import")
+}
+
+// SettingsIssueImportPost reads an uploaded issue export and renders the page to map it
+func SettingsIssueImportPost(ctx *context.Context) {
+	if !issueImportEnabled(ctx) {
+		return
+	}
+	form := web.GetForm(ctx).(*forms.IssueImportForm)
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, tplSettingsIssueImport)
+		return
+	}
+	if !util.IsStringInSlice(form.Format, migrations.IssueImportFormats) {
+		ctx.RenderWithErr(ctx.Tr("repo.settings.issue_import.invalid_format"), tplSettingsIssueImport, form)
+		return
+	}
+	if form.File == nil {
+		ctx.Data["Err_File"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.settings.issue_import.no_file"), tplSettingsIssueImport, form)
+		return
+	}
+	if maxSize := setting.Migrations.IssueImportMaxSize * 1024 * 1024; form.File.Size > maxSize {
+		ctx.Data["Err_File"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.settings.issue_import.file_too_large", base.FileSize(maxSize)), tplSettingsIssueImport, form)
+		return
+	}
+
+	file, err := form.File.Open()
+	if err != nil {
+		ctx.ServerError("Open", err)
+		return
+	}
+	defer file.Close()
+
+	imp, err := migrations.ParseIssueImport(form.Format, form.File.Filename, file)
+	if err != nil {
+		ctx.Data["Err_File"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.settings.issue_import.invalid_file", err), tplSettingsIssueImport, form)
+		return
+	}
+
+	// keep the export until it is mapped
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		ctx.ServerError("Seek", err)
+		return
+	}
+	deletePendingIssueImport(ctx)
+	upload, err := models.NewUpload(form.File.Filename, nil, file)
+	if err != nil {
+		ctx.ServerError("NewUpload", err)
+		return
+	}
+	if err := ctx.Session.Set(pendingIssueImportKey(ctx.Repo.Repository), upload.UUID); err != nil {
+		if err := models.DeleteUploadByUUID(upload.UUID); err != nil {
+			log.Error("DeleteUploadByUUID: %v", err)
+		}
+		ctx.ServerError("Session.Set", err)
+		return
+	}
+
+	doneStatuses := make(map[string]bool)
+	for _, status := range imp.DoneStatuses() {
+		doneStatuses[status] = true
+	}
+	ctx.Data["IsMapping"] = true
+	ctx.Data["UUID"] = upload.UUID
+	ctx.Data["FileName"] = upload.Name
+	ctx.Data["NumIssues"] = len(imp.Issues)
+	ctx.Data["Statuses"] = imp.Statuses()
+	ctx.Data["DoneStatuses"] = doneStatuses
+	ctx.Data["Fields"] = imp.Fields()
+	ctx.Data["format"] = form.Format
+	ctx.HTML(http.StatusOK, tplSettingsIssueImport)
+}
+
+// SettingsIssueImportMappingPost queues the import of an uploaded issue export as mapped by the user
+func SettingsIssueImportMappingPost(ctx *context.Context) {
+	if !issueImportEnabled(ctx) {
+		return
+	}
+
+	// only the upload of this session and repository may be imported
+	uuid := ctx.FormString("uuid")
+	if pending := takePendingIssueImport(ctx); pending == "" || pending != uuid {
+		if pending != "" {
+			if err := models.DeleteUploadByUUID(pending); err != nil {
+				log.Error("DeleteUploadByUUID: %v", err)
+			}
+		}
+		ctx.Flash.Error(ctx.Tr("repo.settings.issue_import.upload_expired"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/settings/issues/import")
+		return
+	}
+
+	upload, err := models.GetUploadByUUID(uuid)
+	if err != nil {
+		if models.IsErrUploadNotExist(err) {
+			ctx.Flash.Error(ctx.Tr("repo.settings.issue_import.upload_expired"))
+			ctx.Redirect(ctx.Repo.RepoLink + "/settings/issues/import")
+			return
+		}
+		ctx.ServerError("GetUploadByUUID", err)
+		return
+	}
+
+	format := ctx.FormString("format")
+	if !util.IsStringInSlice(format, migrations.IssueImportFormats) {
+		if err := models.DeleteUploadByUUID(upload.UUID); err != nil {
+			log.Error("DeleteUploadByUUID: %v", err)
+		}
+		ctx.Flash.Error(ctx.Tr("repo.settings.issue_import.invalid_format"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/settings/issues/import")
+		return
+	}
+
+	// the task deletes the upload when it is done
+	if _, err := task.ImportIssues(ctx.User, ctx.Repo.Repository, &models.IssueImportPayload{
+		Format:         format,
+		UploadUUID:     upload.UUID,
+		FileName:       upload.Name,
+		ClosedStatuses: ctx.FormStrings("closed_statuses"),
+		LabelFields:    ctx.FormStrings("label_fields"),
+	}); err != nil {
+		if err := models.DeleteUploadByUUID(upload.UUID); err != nil {
+			log.Error("DeleteUploadByUUID: %v", err)
+		}
+		ctx.ServerError("ImportIssues", err)
+		return
+	}
+
+	log.Trace("Import of issues into repository %-v from %s queued by %s", ctx.Repo.Repository, upload.Name, ctx.User.Name)
+	ctx.Flash.Success(ctx.Tr("repo.settings.issue_import.queued", upload.Name))
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/issues/import")
+}
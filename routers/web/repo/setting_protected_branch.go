@@ -17,6 +17,7 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/forms"
 	pull_service "code.gitea.io/gitea/services/pull"
 	"code.gitea.io/gitea/services/repository"
@@ -188,7 +189,8 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 	}
 
 	if f.Protected {
-		if protectBranch == nil {
+		isNew := protectBranch == nil
+		if isNew {
 			// No options found, create defaults.
 			protectBranch = &models.ProtectedBranch{
 				RepoID:     ctx.Repo.Repository.ID,
@@ -257,7 +259,7 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 		protectBranch.UnprotectedFilePatterns = f.UnprotectedFilePatterns
 		protectBranch.BlockOnOutdatedBranch = f.BlockOnOutdatedBranch
 
-		err = models.UpdateProtectBranch(ctx.Repo.Repository, protectBranch, models.WhitelistOptions{
+		err = repository.UpdateBranchProtection(ctx.User, ctx.Repo.Repository, protectBranch, models.WhitelistOptions{
 			UserIDs:          whitelistUsers,
 			TeamIDs:          whitelistTeams,
 			MergeUserIDs:     mergeWhitelistUsers,
@@ -269,6 +271,11 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 			ctx.ServerError("UpdateProtectBranch", err)
 			return
 		}
+		if isNew {
+			audit.RecordRepo(ctx.Req, ctx.User, ctx.Repo.Repository, models.AuditRepoBranchProtectionAdd, protectBranch.BranchName, "")
+		} else {
+			audit.RecordRepo(ctx.Req, ctx.User, ctx.Repo.Repository, models.AuditRepoBranchProtectionEdit, protectBranch.BranchName, "")
+		}
 		if err = pull_service.CheckPrsForBaseBranch(ctx.Repo.Repository, protectBranch.BranchName); err != nil {
 			ctx.ServerError("CheckPrsForBaseBranch", err)
 			return
@@ -277,10 +284,11 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 		ctx.Redirect(fmt.Sprintf("%s/settings/branches/%s", ctx.Repo.RepoLink, branch))
 	} else {
 		if protectBranch != nil {
-			if err := ctx.Repo.Repository.DeleteProtectedBranch(protectBranch.ID); err != nil {
+			if err := repository.DeleteBranchProtection(ctx.User, ctx.Repo.Repository, protectBranch); err != nil {
 				ctx.ServerError("DeleteProtectedBranch", err)
 				return
 			}
+			audit.RecordRepo(ctx.Req, ctx.User, ctx.Repo.Repository, models.AuditRepoBranchProtectionRemove, protectBranch.BranchName, "")
 		}
 		ctx.Flash.Success(ctx.Tr("repo.settings.remove_protected_branch_success", branch))
 		ctx.Redirect(fmt.Sprintf("%s/settings/branches", ctx.Repo.RepoLink))
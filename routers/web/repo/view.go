@@ -34,6 +34,7 @@ import (
 	"code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/typesniffer"
 	"code.gitea.io/gitea/modules/util"
+	pull_service "code.gitea.io/gitea/services/pull"
 )
 
 const (
@@ -788,6 +789,47 @@ func renderRepoTopics(ctx *context.Context) {
 	ctx.Data["Topics"] = topics
 }
 
+// renderUpstreamDivergence shows how far the viewed branch of a fork is ahead and behind its upstream branch
+func renderUpstreamDivergence(ctx *context.Context) {
+	repo := ctx.Repo.Repository
+	if !repo.IsFork || !ctx.Repo.IsViewBranch {
+		return
+	}
+	if err := repo.GetBaseRepo(); err != nil {
+		if !models.IsErrRepoNotExist(err) {
+			log.Error("GetBaseRepo: %v", err)
+		}
+		return
+	}
+	perm, err := models.GetUserRepoPermission(repo.BaseRepo, ctx.User)
+	if err != nil {
+		ctx.ServerError("GetUserRepoPermission", err)
+		return
+	}
+	if !perm.CanRead(models.UnitTypeCode) {
+		return
+	}
+
+	// The upstream branch may not exist and comparing must not break the home page
+	divergence, err := pull_service.GetUpstreamDivergence(repo, ctx.Repo.BranchName)
+	if err != nil {
+		if !models.IsErrBranchDoesNotExist(err) {
+			log.Error("GetUpstreamDivergence(%-v, %s): %v", repo, ctx.Repo.BranchName, err)
+		}
+		return
+	}
+	upstreamBranch, err := pull_service.GetUpstreamBranch(repo, ctx.Repo.BranchName)
+	if err != nil {
+		ctx.ServerError("GetUpstreamBranch", err)
+		return
+	}
+
+	ctx.Data["UpstreamDivergence"] = divergence
+	ctx.Data["UpstreamRepo"] = repo.BaseRepo
+	ctx.Data["UpstreamBranch"] = upstreamBranch
+	ctx.Data["CanMergeUpstream"] = ctx.Repo.CanWrite(models.UnitTypeCode) && !repo.IsArchived && !repo.IsMirror
+}
+
 func renderCode(ctx *context.Context) {
 	ctx.Data["PageIsViewCode"] = true
 
@@ -828,6 +870,13 @@ func renderCode(ctx *context.Context) {
 		return
 	}
 
+	if len(ctx.Repo.TreePath) == 0 {
+		renderUpstreamDivergence(ctx)
+		if ctx.Written() {
+			return
+		}
+	}
+
 	if entry.IsDir() {
 		renderDirectory(ctx, treeLink)
 	} else {
@@ -177,6 +177,14 @@ func ParseHookEvent(form forms.WebhookForm) *models.HookEvent {
 			PullRequestReview:    form.PullRequestReview,
 			PullRequestSync:      form.PullRequestSync,
 			Repository:           form.Repository,
+			Wiki:                 form.Wiki,
+			Status:               form.Status,
+			Member:               form.Member,
+			BranchProtection:     form.BranchProtection,
+			Star:                 form.Star,
+			User:                 form.User,
+			Organization:         form.Organization,
+			LoginFailed:          form.LoginFailed,
 		},
 		BranchFilter: form.BranchFilter,
 	}
@@ -678,6 +686,246 @@ func WechatworkHooksNewPost(ctx *context.Context) {
 	ctx.Redirect(orCtx.Link)
 }
 
+// MattermostHooksNewPost response for creating Mattermost hook
+func MattermostHooksNewPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.NewMattermostHookForm)
+	ctx.Data["Title"] = ctx.Tr("repo.settings")
+	ctx.Data["PageIsSettingsHooks"] = true
+	ctx.Data["PageIsSettingsHooksNew"] = true
+	ctx.Data["Webhook"] = models.Webhook{HookEvent: &models.HookEvent{}}
+	ctx.Data["HookType"] = models.MATTERMOST
+
+	orCtx, err := getOrgRepoCtx(ctx)
+	if err != nil {
+		ctx.ServerError("getOrgRepoCtx", err)
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, orCtx.NewTemplate)
+		return
+	}
+
+	meta, err := json.Marshal(&webhook.MattermostMeta{
+		Channel:  strings.TrimSpace(form.Channel),
+		Username: form.Username,
+		IconURL:  form.IconURL,
+	})
+	if err != nil {
+		ctx.ServerError("Marshal", err)
+		return
+	}
+
+	w := &models.Webhook{
+		RepoID:          orCtx.RepoID,
+		URL:             form.PayloadURL,
+		ContentType:     models.ContentTypeJSON,
+		HookEvent:       ParseHookEvent(form.WebhookForm),
+		IsActive:        form.Active,
+		Type:            models.MATTERMOST,
+		Meta:            string(meta),
+		OrgID:           orCtx.OrgID,
+		IsSystemWebhook: orCtx.IsSystemWebhook,
+	}
+	if err := w.UpdateEvent(); err != nil {
+		ctx.ServerError("UpdateEvent", err)
+		return
+	} else if err := models.CreateWebhook(w); err != nil {
+		ctx.ServerError("CreateWebhook", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
+	ctx.Redirect(orCtx.Link)
+}
+
+// RocketChatHooksNewPost response for creating Rocket.Chat hook
+func RocketChatHooksNewPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.NewRocketChatHookForm)
+	ctx.Data["Title"] = ctx.Tr("repo.settings")
+	ctx.Data["PageIsSettingsHooks"] = true
+	ctx.Data["PageIsSettingsHooksNew"] = true
+	ctx.Data["Webhook"] = models.Webhook{HookEvent: &models.HookEvent{}}
+	ctx.Data["HookType"] = models.ROCKETCHAT
+
+	orCtx, err := getOrgRepoCtx(ctx)
+	if err != nil {
+		ctx.ServerError("getOrgRepoCtx", err)
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, orCtx.NewTemplate)
+		return
+	}
+
+	meta, err := json.Marshal(&webhook.RocketChatMeta{
+		Channel:  strings.TrimSpace(form.Channel),
+		Username: form.Username,
+		IconURL:  form.IconURL,
+	})
+	if err != nil {
+		ctx.ServerError("Marshal", err)
+		return
+	}
+
+	w := &models.Webhook{
+		RepoID:          orCtx.RepoID,
+		URL:             form.PayloadURL,
+		ContentType:     models.ContentTypeJSON,
+		HookEvent:       ParseHookEvent(form.WebhookForm),
+		IsActive:        form.Active,
+		Type:            models.ROCKETCHAT,
+		Meta:            string(meta),
+		OrgID:           orCtx.OrgID,
+		IsSystemWebhook: orCtx.IsSystemWebhook,
+	}
+	if err := w.UpdateEvent(); err != nil {
+		ctx.ServerError("UpdateEvent", err)
+		return
+	} else if err := models.CreateWebhook(w); err != nil {
+		ctx.ServerError("CreateWebhook", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
+	ctx.Redirect(orCtx.Link)
+}
+
+// NtfyHooksNewPost response for creating ntfy hook
+func NtfyHooksNewPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.NewNtfyHookForm)
+	ctx.Data["Title"] = ctx.Tr("repo.settings")
+	ctx.Data["PageIsSettingsHooks"] = true
+	ctx.Data["PageIsSettingsHooksNew"] = true
+	ctx.Data["Webhook"] = models.Webhook{HookEvent: &models.HookEvent{}}
+	ctx.Data["HookType"] = models.NTFY
+
+	orCtx, err := getOrgRepoCtx(ctx)
+	if err != nil {
+		ctx.ServerError("getOrgRepoCtx", err)
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, orCtx.NewTemplate)
+		return
+	}
+
+	meta, err := json.Marshal(&webhook.NtfyMeta{
+		Topic:       strings.TrimSpace(form.Topic),
+		Priority:    form.Priority,
+		AccessToken: form.AccessToken,
+	})
+	if err != nil {
+		ctx.ServerError("Marshal", err)
+		return
+	}
+
+	w := &models.Webhook{
+		RepoID:          orCtx.RepoID,
+		URL:             form.PayloadURL,
+		ContentType:     models.ContentTypeJSON,
+		HTTPMethod:      http.MethodPost,
+		HookEvent:       ParseHookEvent(form.WebhookForm),
+		IsActive:        form.Active,
+		Type:            models.NTFY,
+		Meta:            string(meta),
+		OrgID:           orCtx.OrgID,
+		IsSystemWebhook: orCtx.IsSystemWebhook,
+	}
+	if err := w.UpdateEvent(); err != nil {
+		ctx.ServerError("UpdateEvent", err)
+		return
+	} else if err := models.CreateWebhook(w); err != nil {
+		ctx.ServerError("CreateWebhook", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
+	ctx.Redirect(orCtx.Link)
+}
+
+// customHookMeta validates the payload template and headers of the form and returns the metadata of the hook.
+// If they are invalid the form is rendered again with an error.
+func customHookMeta(ctx *context.Context, orCtx *orgRepoCtx, form *forms.NewCustomHookForm) (string, bool) {
+	custom := &webhook.CustomMeta{
+		PayloadTemplate: form.PayloadTemplate,
+		ContentType:     strings.TrimSpace(form.PayloadContentType),
+		Headers:         form.Headers,
+	}
+	ctx.Data["CustomHook"] = custom
+
+	if _, err := webhook.ParseCustomPayloadTemplate(custom.PayloadTemplate, models.HookEventPush); err != nil {
+		ctx.Data["Err_PayloadTemplate"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.settings.custom.invalid_payload_template", err.Error()), orCtx.NewTemplate, nil)
+		return "", false
+	}
+	if _, err := webhook.ParseCustomHeaders(custom.Headers); err != nil {
+		ctx.Data["Err_Headers"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.settings.custom.invalid_headers", err.Error()), orCtx.NewTemplate, nil)
+		return "", false
+	}
+
+	meta, err := json.Marshal(custom)
+	if err != nil {
+		ctx.ServerError("Marshal", err)
+		return "", false
+	}
+	return string(meta), true
+}
+
+// CustomHooksNewPost response for creating a webhook with a user defined payload
+func CustomHooksNewPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.NewCustomHookForm)
+	ctx.Data["Title"] = ctx.Tr("repo.settings")
+	ctx.Data["PageIsSettingsHooks"] = true
+	ctx.Data["PageIsSettingsHooksNew"] = true
+	ctx.Data["Webhook"] = models.Webhook{HookEvent: &models.HookEvent{}}
+	ctx.Data["HookType"] = models.CUSTOM
+
+	orCtx, err := getOrgRepoCtx(ctx)
+	if err != nil {
+		ctx.ServerError("getOrgRepoCtx", err)
+		return
+	}
+	ctx.Data["BaseLink"] = orCtx.LinkNew
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, orCtx.NewTemplate)
+		return
+	}
+
+	meta, ok := customHookMeta(ctx, orCtx, form)
+	if !ok {
+		return
+	}
+
+	w := &models.Webhook{
+		RepoID:          orCtx.RepoID,
+		URL:             form.PayloadURL,
+		HTTPMethod:      form.HTTPMethod,
+		ContentType:     models.ContentTypeJSON,
+		Secret:          form.Secret,
+		HookEvent:       ParseHookEvent(form.WebhookForm),
+		IsActive:        form.Active,
+		Type:            models.CUSTOM,
+		Meta:            meta,
+		OrgID:           orCtx.OrgID,
+		IsSystemWebhook: orCtx.IsSystemWebhook,
+	}
+	if err := w.UpdateEvent(); err != nil {
+		ctx.ServerError("UpdateEvent", err)
+		return
+	} else if err := models.CreateWebhook(w); err != nil {
+		ctx.ServerError("CreateWebhook", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
+	ctx.Redirect(orCtx.Link)
+}
+
 func checkWebhook(ctx *context.Context) (*orgRepoCtx, *models.Webhook) {
 	ctx.Data["RequireHighlightJS"] = true
 
@@ -715,6 +963,14 @@ func checkWebhook(ctx *context.Context) (*orgRepoCtx, *models.Webhook) {
 		ctx.Data["TelegramHook"] = webhook.GetTelegramHook(w)
 	case models.MATRIX:
 		ctx.Data["MatrixHook"] = webhook.GetMatrixHook(w)
+	case models.MATTERMOST:
+		ctx.Data["MattermostHook"] = webhook.GetMattermostHook(w)
+	case models.ROCKETCHAT:
+		ctx.Data["RocketChatHook"] = webhook.GetRocketChatHook(w)
+	case models.NTFY:
+		ctx.Data["NtfyHook"] = webhook.GetNtfyHook(w)
+	case models.CUSTOM:
+		ctx.Data["CustomHook"] = webhook.GetCustomHook(w)
 	}
 
 	ctx.Data["History"], err = w.History(1)
@@ -1133,6 +1389,179 @@ func WechatworkHooksEditPost(ctx *context.Context) {
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
 
+// MattermostHooksEditPost response for editing Mattermost hook
+func MattermostHooksEditPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.NewMattermostHookForm)
+	ctx.Data["Title"] = ctx.Tr("repo.settings")
+	ctx.Data["PageIsSettingsHooks"] = true
+	ctx.Data["PageIsSettingsHooksEdit"] = true
+
+	orCtx, w := checkWebhook(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["Webhook"] = w
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, orCtx.NewTemplate)
+		return
+	}
+
+	meta, err := json.Marshal(&webhook.MattermostMeta{
+		Channel:  strings.TrimSpace(form.Channel),
+		Username: form.Username,
+		IconURL:  form.IconURL,
+	})
+	if err != nil {
+		ctx.ServerError("Marshal", err)
+		return
+	}
+
+	w.URL = form.PayloadURL
+	w.Meta = string(meta)
+	w.HookEvent = ParseHookEvent(form.WebhookForm)
+	w.IsActive = form.Active
+	if err := w.UpdateEvent(); err != nil {
+		ctx.ServerError("UpdateEvent", err)
+		return
+	} else if err := models.UpdateWebhook(w); err != nil {
+		ctx.ServerError("UpdateWebhook", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
+	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
+}
+
+// RocketChatHooksEditPost response for editing Rocket.Chat hook
+func RocketChatHooksEditPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.NewRocketChatHookForm)
+	ctx.Data["Title"] = ctx.Tr("repo.settings")
+	ctx.Data["PageIsSettingsHooks"] = true
+	ctx.Data["PageIsSettingsHooksEdit"] = true
+
+	orCtx, w := checkWebhook(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["Webhook"] = w
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, orCtx.NewTemplate)
+		return
+	}
+
+	meta, err := json.Marshal(&webhook.RocketChatMeta{
+		Channel:  strings.TrimSpace(form.Channel),
+		Username: form.Username,
+		IconURL:  form.IconURL,
+	})
+	if err != nil {
+		ctx.ServerError("Marshal", err)
+		return
+	}
+
+	w.URL = form.PayloadURL
+	w.Meta = string(meta)
+	w.HookEvent = ParseHookEvent(form.WebhookForm)
+	w.IsActive = form.Active
+	if err := w.UpdateEvent(); err != nil {
+		ctx.ServerError("UpdateEvent", err)
+		return
+	} else if err := models.UpdateWebhook(w); err != nil {
+		ctx.ServerError("UpdateWebhook", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
+	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
+}
+
+// NtfyHooksEditPost response for editing ntfy hook
+func NtfyHooksEditPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.NewNtfyHookForm)
+	ctx.Data["Title"] = ctx.Tr("repo.settings")
+	ctx.Data["PageIsSettingsHooks"] = true
+	ctx.Data["PageIsSettingsHooksEdit"] = true
+
+	orCtx, w := checkWebhook(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["Webhook"] = w
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, orCtx.NewTemplate)
+		return
+	}
+
+	meta, err := json.Marshal(&webhook.NtfyMeta{
+		Topic:       strings.TrimSpace(form.Topic),
+		Priority:    form.Priority,
+		AccessToken: form.AccessToken,
+	})
+	if err != nil {
+		ctx.ServerError("Marshal", err)
+		return
+	}
+
+	w.URL = form.PayloadURL
+	w.Meta = string(meta)
+	w.HookEvent = ParseHookEvent(form.WebhookForm)
+	w.IsActive = form.Active
+	if err := w.UpdateEvent(); err != nil {
+		ctx.ServerError("UpdateEvent", err)
+		return
+	} else if err := models.UpdateWebhook(w); err != nil {
+		ctx.ServerError("UpdateWebhook", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
+	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
+}
+
+// CustomHooksEditPost response for editing a webhook with a user defined payload
+func CustomHooksEditPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.NewCustomHookForm)
+	ctx.Data["Title"] = ctx.Tr("repo.settings.update_webhook")
+	ctx.Data["PageIsSettingsHooks"] = true
+	ctx.Data["PageIsSettingsHooksEdit"] = true
+
+	orCtx, w := checkWebhook(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["Webhook"] = w
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, orCtx.NewTemplate)
+		return
+	}
+
+	meta, ok := customHookMeta(ctx, orCtx, form)
+	if !ok {
+		return
+	}
+
+	w.URL = form.PayloadURL
+	w.HTTPMethod = form.HTTPMethod
+	w.Secret = form.Secret
+	w.Meta = meta
+	w.HookEvent = ParseHookEvent(form.WebhookForm)
+	w.IsActive = form.Active
+	if err := w.UpdateEvent(); err != nil {
+		ctx.ServerError("UpdateEvent", err)
+		return
+	} else if err := models.UpdateWebhook(w); err != nil {
+		ctx.ServerError("UpdateWebhook", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
+	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
+}
+
 // TestWebhook test if web hook is work fine
 func TestWebhook(ctx *context.Context) {
 	hookID := ctx.ParamsInt64(":id")
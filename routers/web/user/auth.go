@@ -20,6 +20,7 @@ import (
 	"code.gitea.io/gitea/modules/eventsource"
 	"code.gitea.io/gitea/modules/hcaptcha"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/password"
 	"code.gitea.io/gitea/modules/recaptcha"
 	"code.gitea.io/gitea/modules/setting"
@@ -27,6 +28,7 @@ import (
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/modules/web/middleware"
 	"code.gitea.io/gitea/routers/utils"
+	"code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/auth"
 	"code.gitea.io/gitea/services/auth/source/oauth2"
 	"code.gitea.io/gitea/services/externalaccount"
@@ -181,11 +183,17 @@ func SignInPost(ctx *context.Context) {
 		if models.IsErrUserNotExist(err) {
 			ctx.RenderWithErr(ctx.Tr("form.username_password_incorrect"), tplSignIn, &form)
 			log.Info("Failed authentication attempt for %s from %s: %v", form.UserName, ctx.RemoteAddr(), err)
+			notification.NotifyLoginFailed(form.UserName, ctx.RemoteAddr(), "invalid credentials")
+			audit.RecordSignInFailed(ctx.Req, nil, form.UserName, "invalid credentials")
 		} else if models.IsErrEmailAlreadyUsed(err) {
 			ctx.RenderWithErr(ctx.Tr("form.email_been_used"), tplSignIn, &form)
 			log.Info("Failed authentication attempt for %s from %s: %v", form.UserName, ctx.RemoteAddr(), err)
+			notification.NotifyLoginFailed(form.UserName, ctx.RemoteAddr(), "email already used")
+			audit.RecordSignInFailed(ctx.Req, nil, form.UserName, "email already used")
 		} else if models.IsErrUserProhibitLogin(err) {
 			log.Info("Failed authentication attempt for %s from %s: %v", form.UserName, ctx.RemoteAddr(), err)
+			notification.NotifyLoginFailed(form.UserName, ctx.RemoteAddr(), "login prohibited")
+			audit.RecordSignInFailed(ctx.Req, nil, form.UserName, "login prohibited")
 			ctx.Data["Title"] = ctx.Tr("auth.prohibit_login")
 			ctx.HTML(http.StatusOK, "user/auth/prohibit_login")
 		} else if models.IsErrUserInactive(err) {
@@ -194,6 +202,8 @@ func SignInPost(ctx *context.Context) {
 				ctx.HTML(http.StatusOK, TplActivate)
 			} else {
 				log.Info("Failed authentication attempt for %s from %s: %v", form.UserName, ctx.RemoteAddr(), err)
+				notification.NotifyLoginFailed(form.UserName, ctx.RemoteAddr(), "account inactive")
+				audit.RecordSignInFailed(ctx.Req, nil, form.UserName, "account inactive")
 				ctx.Data["Title"] = ctx.Tr("auth.prohibit_login")
 				ctx.HTML(http.StatusOK, "user/auth/prohibit_login")
 			}
@@ -342,6 +352,9 @@ func TwoFactorPost(ctx *context.Context) {
 		return
 	}
 
+	if u, err := models.GetUserByID(id); err == nil {
+		audit.RecordSignInFailed(ctx.Req, u, u.Name, "invalid two-factor passcode")
+	}
 	ctx.RenderWithErr(ctx.Tr("auth.twofa_passcode_incorrect"), tplTwofa, forms.TwoFactorAuthForm{})
 }
 
@@ -408,6 +421,9 @@ func TwoFactorScratchPost(ctx *context.Context) {
 		return
 	}
 
+	if u, err := models.GetUserByID(id); err == nil {
+		audit.RecordSignInFailed(ctx.Req, u, u.Name, "invalid two-factor scratch token")
+	}
 	ctx.RenderWithErr(ctx.Tr("auth.twofa_scratch_token_incorrect"), tplTwofaScratch, forms.TwoFactorScratchAuthForm{})
 }
 
@@ -580,6 +596,7 @@ func handleSignInFull(ctx *context.Context, u *models.User, remember bool, obeyR
 		ctx.ServerError("UpdateUserCols", err)
 		return setting.AppSubURL + "/"
 	}
+	audit.RecordUser(ctx.Req, u, u, models.AuditUserSignIn, "")
 
 	if redirectTo := ctx.GetCookie("redirect_to"); len(redirectTo) > 0 && !utils.IsExternalURL(redirectTo) {
 		middleware.DeleteRedirectToCookie(ctx.Resp)
@@ -772,6 +789,7 @@ func handleOAuth2SignIn(ctx *context.Context, source *login.Source, u *models.Us
 			ctx.ServerError("UpdateUserCols", err)
 			return
 		}
+		audit.RecordUser(ctx.Req, u, u, models.AuditUserSignIn, source.Name)
 
 		// update external user information
 		if err := models.UpdateExternalUser(u, gothUser); err != nil {
@@ -1328,6 +1346,7 @@ func createUserInContext(ctx *context.Context, tpl base.TplName, form interface{
 		return
 	}
 	log.Trace("Account created: %s", u.Name)
+	notification.NotifyCreateUser(u, u)
 	return true
 }
 
@@ -14,6 +14,7 @@ import (
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/password"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
@@ -156,11 +157,34 @@ func EmailPost(ctx *context.Context) {
 			ctx.ServerError("SetEmailPreference", errors.New("option unrecognized"))
 			return
 		}
+		digest := ctx.FormString("digest")
+		if digest == "" {
+			digest = ctx.User.EmailDigest()
+		}
+		if !(digest == models.EmailDigestImmediate ||
+			digest == models.EmailDigestDaily ||
+			digest == models.EmailDigestWeekly) {
+			log.Error("Email notifications digest change returned unrecognized option %s: %s", digest, ctx.User.Name)
+			ctx.ServerError("SetEmailDigest", errors.New("option unrecognized"))
+			return
+		}
 		if err := ctx.User.SetEmailNotifications(preference); err != nil {
 			log.Error("Set Email Notifications failed: %v", err)
 			ctx.ServerError("SetEmailNotifications", err)
 			return
 		}
+		if err := ctx.User.SetEmailDigest(digest, ctx.FormBool("digest_immediate_mentions")); err != nil {
+			log.Error("Set Email Digest failed: %v", err)
+			ctx.ServerError("SetEmailDigest", err)
+			return
+		}
+		if setting.MailService.EncryptionEnabled {
+			if err := ctx.User.SetEncryptEmailNotifications(ctx.FormBool("encrypt_notifications")); err != nil {
+				log.Error("Set Encrypt Email Notifications failed: %v", err)
+				ctx.ServerError("SetEncryptEmailNotifications", err)
+				return
+			}
+		}
 		log.Trace("Email notifications preference made %s: %s", preference, ctx.User.Name)
 		ctx.Flash.Success(ctx.Tr("settings.email_preference_set_success"))
 		ctx.Redirect(setting.AppSubURL + "/user/settings/account")
@@ -253,6 +277,7 @@ func DeleteAccount(ctx *context.Context) {
 		}
 	} else {
 		log.Trace("Account deleted: %s", ctx.User.Name)
+		notification.NotifyDeleteUser(ctx.User, ctx.User)
 		ctx.Redirect(setting.AppSubURL + "/")
 	}
 }
@@ -277,6 +302,10 @@ func loadAccountData(ctx *context.Context) {
 	}
 	ctx.Data["Emails"] = emails
 	ctx.Data["EmailNotificationsPreference"] = ctx.User.EmailNotifications()
+	ctx.Data["EmailNotificationsDigest"] = ctx.User.EmailDigest()
+	ctx.Data["EmailDigestImmediateMentions"] = ctx.User.EmailDigestImmediateMentions
+	ctx.Data["EncryptEmailNotifications"] = ctx.User.EncryptEmailNotifications
+	ctx.Data["EnableMailEncryption"] = setting.MailService != nil && setting.MailService.EncryptionEnabled
 	ctx.Data["ActivationsPending"] = pendingActivation
 	ctx.Data["CanAddEmails"] = !pendingActivation || !setting.Service.RegisterEmailConfirm
 
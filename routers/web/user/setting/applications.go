@@ -6,6 +6,7 @@
 package setting
 
 import (
+	"fmt"
 	"net/http"
 
 	"code.gitea.io/gitea/models"
@@ -14,6 +15,7 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/forms"
 )
 
@@ -65,6 +67,7 @@ func ApplicationsPost(ctx *context.Context) {
 		return
 	}
 
+	audit.RecordUser(ctx.Req, ctx.User, ctx.User, models.AuditUserAccessTokenCreate, t.Name)
 	ctx.Flash.Success(ctx.Tr("settings.generate_token_success"))
 	ctx.Flash.Info(t.Token)
 
@@ -73,9 +76,11 @@ func ApplicationsPost(ctx *context.Context) {
 
 // DeleteApplication response for delete user access token
 func DeleteApplication(ctx *context.Context) {
-	if err := models.DeleteAccessTokenByID(ctx.FormInt64("id"), ctx.User.ID); err != nil {
+	id := ctx.FormInt64("id")
+	if err := models.DeleteAccessTokenByID(id, ctx.User.ID); err != nil {
 		ctx.Flash.Error("DeleteAccessTokenByID: " + err.Error())
 	} else {
+		audit.RecordUser(ctx.Req, ctx.User, ctx.User, models.AuditUserAccessTokenDelete, fmt.Sprintf("ID %d", id))
 		ctx.Flash.Success(ctx.Tr("settings.delete_token_success"))
 	}
 
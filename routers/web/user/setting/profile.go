@@ -24,6 +24,7 @@ import (
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/modules/web/middleware"
+	"code.gitea.io/gitea/routers/common"
 	"code.gitea.io/gitea/services/agit"
 	"code.gitea.io/gitea/services/forms"
 
@@ -35,6 +36,8 @@ const (
 	tplSettingsAppearance   base.TplName = "user/settings/appearance"
 	tplSettingsOrganization base.TplName = "user/settings/organization"
 	tplSettingsRepositories base.TplName = "user/settings/repos"
+	tplSettingsReposTrash   base.TplName = "user/settings/repos_trash"
+	tplSettingsOrgsTrash    base.TplName = "user/settings/organization_trash"
 )
 
 // Profile render user's profile page
@@ -222,6 +225,28 @@ func Organization(ctx *context.Context) {
 	ctx.HTML(http.StatusOK, tplSettingsOrganization)
 }
 
+// OrganizationTrash display the organizations in the trash the user owns
+func OrganizationTrash(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.trash.title")
+	ctx.Data["PageIsSettingsOrganization"] = true
+
+	common.DeletedOrgs(ctx, ctx.User.ID)
+	if ctx.Written() {
+		return
+	}
+	ctx.HTML(http.StatusOK, tplSettingsOrgsTrash)
+}
+
+// RestoreDeletedOrg restores one of the user's organizations from the trash
+func RestoreDeletedOrg(ctx *context.Context) {
+	common.RestoreDeletedOrg(ctx, setting.AppSubURL+"/user/settings/organization/trash")
+}
+
+// PurgeDeletedOrg permanently deletes one of the user's organizations in the trash
+func PurgeDeletedOrg(ctx *context.Context) {
+	common.PurgeDeletedOrg(ctx, setting.AppSubURL+"/user/settings/organization/trash")
+}
+
 // Repos display a list of all repositories of the user
 func Repos(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("settings")
@@ -278,11 +303,19 @@ func Repos(ctx *context.Context) {
 			return
 		}
 
-		if err := ctxUser.GetRepositories(db.ListOptions{Page: 1, PageSize: setting.UI.Admin.UserPagingNum}, repoNames...); err != nil {
+		// the directories of repositories in the trash are not unadopted
+		userRepos, _, err := models.GetUserRepositories(&models.SearchRepoOptions{
+			Actor:          ctxUser,
+			Private:        true,
+			ListOptions:    db.ListOptions{Page: 1, PageSize: setting.UI.Admin.UserPagingNum},
+			LowerNames:     repoNames,
+			IncludeDeleted: true,
+		})
+		if err != nil {
 			ctx.ServerError("GetRepositories", err)
 			return
 		}
-		for _, repo := range ctxUser.Repos {
+		for _, repo := range userRepos {
 			if repo.IsFork {
 				if err := repo.GetBaseRepo(); err != nil {
 					ctx.ServerError("GetBaseRepo", err)
@@ -323,6 +356,28 @@ func Repos(ctx *context.Context) {
 	ctx.HTML(http.StatusOK, tplSettingsRepositories)
 }
 
+// ReposTrash display the user's repositories in the trash
+func ReposTrash(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.trash.title")
+	ctx.Data["PageIsSettingsRepos"] = true
+
+	common.DeletedRepos(ctx, ctx.User.ID)
+	if ctx.Written() {
+		return
+	}
+	ctx.HTML(http.StatusOK, tplSettingsReposTrash)
+}
+
+// RestoreDeletedRepo restores one of the user's repositories from the trash
+func RestoreDeletedRepo(ctx *context.Context) {
+	common.RestoreDeletedRepo(ctx, ctx.User.ID, setting.AppSubURL+"/user/settings/repos/trash")
+}
+
+// PurgeDeletedRepo permanently deletes one of the user's repositories in the trash
+func PurgeDeletedRepo(ctx *context.Context) {
+	common.PurgeDeletedRepo(ctx, ctx.User.ID, setting.AppSubURL+"/user/settings/repos/trash")
+}
+
 // Appearance render user's appearance settings
 func Appearance(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("settings")
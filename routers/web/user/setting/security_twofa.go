@@ -13,11 +13,13 @@ import (
 	"net/http"
 	"strings"
 
+	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/login"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/forms"
 
 	"github.com/pquerna/otp"
@@ -50,6 +52,7 @@ func RegenerateScratchTwoFactor(ctx *context.Context) {
 		return
 	}
 
+	audit.RecordUser(ctx.Req, ctx.User, ctx.User, models.AuditUserTwoFactorRegenerate, "")
 	ctx.Flash.Success(ctx.Tr("settings.twofa_scratch_token_regenerated", token))
 	ctx.Redirect(setting.AppSubURL + "/user/settings/security")
 }
@@ -79,6 +82,7 @@ func DisableTwoFactor(ctx *context.Context) {
 		return
 	}
 
+	audit.RecordUser(ctx.Req, ctx.User, ctx.User, models.AuditUserTwoFactorDisable, "")
 	ctx.Flash.Success(ctx.Tr("settings.twofa_disabled"))
 	ctx.Redirect(setting.AppSubURL + "/user/settings/security")
 }
@@ -245,6 +249,7 @@ func EnrollTwoFactorPost(ctx *context.Context) {
 		return
 	}
 
+	audit.RecordUser(ctx.Req, ctx.User, ctx.User, models.AuditUserTwoFactorEnable, "")
 	ctx.Flash.Success(ctx.Tr("settings.twofa_enrolled", token))
 	ctx.Redirect(setting.AppSubURL + "/user/settings/security")
 }
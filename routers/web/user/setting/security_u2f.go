@@ -8,11 +8,13 @@ import (
 	"errors"
 	"net/http"
 
+	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/login"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/forms"
 
 	"github.com/tstranex/u2f"
@@ -82,6 +84,7 @@ func U2FRegisterPost(ctx *context.Context) {
 		ctx.ServerError("u2f.Register", err)
 		return
 	}
+	audit.RecordUser(ctx.Req, ctx.User, ctx.User, models.AuditUserSecurityKeyAdd, name)
 	ctx.Status(200)
 }
 
@@ -105,6 +108,7 @@ func U2FDelete(ctx *context.Context) {
 		ctx.ServerError("DeleteRegistration", err)
 		return
 	}
+	audit.RecordUser(ctx.Req, ctx.User, ctx.User, models.AuditUserSecurityKeyRemove, reg.Name)
 	ctx.JSON(http.StatusOK, map[string]interface{}{
 		"redirect": setting.AppSubURL + "/user/settings/security",
 	})
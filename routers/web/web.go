@@ -358,8 +358,14 @@ func RegisterRoutes(m *web.Route) {
 			Post(bindIgnErr(forms.AddKeyForm{}), userSetting.KeysPost)
 		m.Post("/keys/delete", userSetting.DeleteKey)
 		m.Get("/organization", userSetting.Organization)
+		m.Get("/organization/trash", userSetting.OrganizationTrash)
+		m.Post("/organization/trash/restore", userSetting.RestoreDeletedOrg)
+		m.Post("/organization/trash/purge", userSetting.PurgeDeletedOrg)
 		m.Get("/repos", userSetting.Repos)
 		m.Post("/repos/unadopted", userSetting.AdoptOrDeleteRepository)
+		m.Get("/repos/trash", userSetting.ReposTrash)
+		m.Post("/repos/trash/restore", userSetting.RestoreDeletedRepo)
+		m.Post("/repos/trash/purge", userSetting.PurgeDeletedRepo)
 	}, reqSignIn, func(ctx *context.Context) {
 		ctx.Data["PageIsUserSettings"] = true
 		ctx.Data["AllThemes"] = setting.UI.Themes
@@ -417,12 +423,22 @@ func RegisterRoutes(m *web.Route) {
 
 		m.Group("/orgs", func() {
 			m.Get("", admin.Organizations)
+			m.Get("/trash", admin.OrgsTrash)
+			m.Post("/trash/restore", admin.RestoreDeletedOrg)
+			m.Post("/trash/purge", admin.PurgeDeletedOrg)
+			m.Post("/trash/empty", admin.EmptyOrgsTrash)
 		})
 
 		m.Group("/repos", func() {
 			m.Get("", admin.Repos)
 			m.Combo("/unadopted").Get(admin.UnadoptedRepos).Post(admin.AdoptOrDeleteRepository)
 			m.Post("/delete", admin.DeleteRepo)
+			m.Combo("/bulk").Get(admin.BulkRepos).Post(bindIgnErr(forms.AdminBulkRepoForm{}), admin.BulkReposPost)
+			m.Get("/bulk/{id}", admin.BulkRepoReport)
+			m.Get("/trash", admin.ReposTrash)
+			m.Post("/trash/restore", admin.RestoreDeletedRepo)
+			m.Post("/trash/purge", admin.PurgeDeletedRepo)
+			m.Post("/trash/empty", admin.EmptyTrash)
 		})
 
 		m.Group("/hooks", func() {
@@ -439,6 +455,10 @@ func RegisterRoutes(m *web.Route) {
 			m.Post("/msteams/{id}", bindIgnErr(forms.NewMSTeamsHookForm{}), repo.MSTeamsHooksEditPost)
 			m.Post("/feishu/{id}", bindIgnErr(forms.NewFeishuHookForm{}), repo.FeishuHooksEditPost)
 			m.Post("/wechatwork/{id}", bindIgnErr(forms.NewWechatWorkHookForm{}), repo.WechatworkHooksEditPost)
+			m.Post("/mattermost/{id}", bindIgnErr(forms.NewMattermostHookForm{}), repo.MattermostHooksEditPost)
+			m.Post("/rocketchat/{id}", bindIgnErr(forms.NewRocketChatHookForm{}), repo.RocketChatHooksEditPost)
+			m.Post("/ntfy/{id}", bindIgnErr(forms.NewNtfyHookForm{}), repo.NtfyHooksEditPost)
+			m.Post("/custom/{id}", bindIgnErr(forms.NewCustomHookForm{}), repo.CustomHooksEditPost)
 		}, webhooksEnabled)
 
 		m.Group("/{configType:default-hooks|system-hooks}", func() {
@@ -453,6 +473,10 @@ func RegisterRoutes(m *web.Route) {
 			m.Post("/msteams/new", bindIgnErr(forms.NewMSTeamsHookForm{}), repo.MSTeamsHooksNewPost)
 			m.Post("/feishu/new", bindIgnErr(forms.NewFeishuHookForm{}), repo.FeishuHooksNewPost)
 			m.Post("/wechatwork/new", bindIgnErr(forms.NewWechatWorkHookForm{}), repo.WechatworkHooksNewPost)
+			m.Post("/mattermost/new", bindIgnErr(forms.NewMattermostHookForm{}), repo.MattermostHooksNewPost)
+			m.Post("/rocketchat/new", bindIgnErr(forms.NewRocketChatHookForm{}), repo.RocketChatHooksNewPost)
+			m.Post("/ntfy/new", bindIgnErr(forms.NewNtfyHookForm{}), repo.NtfyHooksNewPost)
+			m.Post("/custom/new", bindIgnErr(forms.NewCustomHookForm{}), repo.CustomHooksNewPost)
 
 		})
 
@@ -469,6 +493,8 @@ func RegisterRoutes(m *web.Route) {
 			m.Post("/delete", admin.DeleteNotices)
 			m.Post("/empty", admin.EmptyNotices)
 		})
+
+		m.Get("/audit", admin.Audit)
 	}, adminReq)
 	// ***** END: Admin *****
 
@@ -539,6 +565,10 @@ func RegisterRoutes(m *web.Route) {
 					Post(bindIgnErr(forms.UpdateOrgSettingForm{}), org.SettingsPost)
 				m.Post("/avatar", bindIgnErr(forms.AvatarForm{}), org.SettingsAvatar)
 				m.Post("/avatar/delete", org.SettingsDeleteAvatar)
+				m.Get("/audit", org.SettingsAudit)
+				m.Get("/trash", org.SettingsTrash)
+				m.Post("/trash/restore", org.SettingsTrashRestore)
+				m.Post("/trash/purge", org.SettingsTrashPurge)
 
 				m.Group("/hooks", func() {
 					m.Get("", org.Webhooks)
@@ -553,6 +583,10 @@ func RegisterRoutes(m *web.Route) {
 					m.Post("/matrix/new", bindIgnErr(forms.NewMatrixHookForm{}), repo.MatrixHooksNewPost)
 					m.Post("/msteams/new", bindIgnErr(forms.NewMSTeamsHookForm{}), repo.MSTeamsHooksNewPost)
 					m.Post("/feishu/new", bindIgnErr(forms.NewFeishuHookForm{}), repo.FeishuHooksNewPost)
+					m.Post("/mattermost/new", bindIgnErr(forms.NewMattermostHookForm{}), repo.MattermostHooksNewPost)
+					m.Post("/rocketchat/new", bindIgnErr(forms.NewRocketChatHookForm{}), repo.RocketChatHooksNewPost)
+					m.Post("/ntfy/new", bindIgnErr(forms.NewNtfyHookForm{}), repo.NtfyHooksNewPost)
+					m.Post("/custom/new", bindIgnErr(forms.NewCustomHookForm{}), repo.CustomHooksNewPost)
 					m.Get("/{id}", repo.WebHooksEdit)
 					m.Post("/gitea/{id}", bindIgnErr(forms.NewWebhookForm{}), repo.WebHooksEditPost)
 					m.Post("/gogs/{id}", bindIgnErr(forms.NewGogshookForm{}), repo.GogsHooksEditPost)
@@ -563,6 +597,10 @@ func RegisterRoutes(m *web.Route) {
 					m.Post("/matrix/{id}", bindIgnErr(forms.NewMatrixHookForm{}), repo.MatrixHooksEditPost)
 					m.Post("/msteams/{id}", bindIgnErr(forms.NewMSTeamsHookForm{}), repo.MSTeamsHooksEditPost)
 					m.Post("/feishu/{id}", bindIgnErr(forms.NewFeishuHookForm{}), repo.FeishuHooksEditPost)
+					m.Post("/mattermost/{id}", bindIgnErr(forms.NewMattermostHookForm{}), repo.MattermostHooksEditPost)
+					m.Post("/rocketchat/{id}", bindIgnErr(forms.NewRocketChatHookForm{}), repo.RocketChatHooksEditPost)
+					m.Post("/ntfy/{id}", bindIgnErr(forms.NewNtfyHookForm{}), repo.NtfyHooksEditPost)
+					m.Post("/custom/{id}", bindIgnErr(forms.NewCustomHookForm{}), repo.CustomHooksEditPost)
 				}, webhooksEnabled)
 
 				m.Group("/labels", func() {
@@ -583,6 +621,7 @@ func RegisterRoutes(m *web.Route) {
 	m.Group("/repo", func() {
 		m.Get("/create", repo.Create)
 		m.Post("/create", bindIgnErr(forms.CreateRepoForm{}), repo.CreatePost)
+		m.Get("/create/template_variables", repo.TemplateVariables)
 		m.Get("/migrate", repo.Migrate)
 		m.Post("/migrate", bindIgnErr(forms.MigrateRepoForm{}), repo.MigratePost)
 		m.Group("/fork", func() {
@@ -600,6 +639,7 @@ func RegisterRoutes(m *web.Route) {
 				Post(bindIgnErr(forms.RepoSettingForm{}), repo.SettingsPost)
 			m.Post("/avatar", bindIgnErr(forms.AvatarForm{}), repo.SettingsAvatar)
 			m.Post("/avatar/delete", repo.SettingsDeleteAvatar)
+			m.Get("/audit", repo.SettingsAudit)
 
 			m.Group("/collaboration", func() {
 				m.Combo("").Get(repo.Collaboration).Post(repo.CollaborationPost)
@@ -626,6 +666,13 @@ func RegisterRoutes(m *web.Route) {
 				m.Post("/{id}", bindIgnErr(forms.ProtectTagForm{}), context.RepoMustNotBeArchived(), repo.EditProtectedTagPost)
 			})
 
+			m.Group("/issues/import", func() {
+				m.Combo("").Get(repo.SettingsIssueImport).
+					Post(bindIgnErr(forms.IssueImportForm{}), repo.SettingsIssueImportPost)
+				m.Post("/mapping", repo.SettingsIssueImportMappingPost)
+				m.Post("/cancel", repo.SettingsIssueImportCancelPost)
+			}, context.RepoMustNotBeArchived())
+
 			m.Group("/hooks/git", func() {
 				m.Get("", repo.GitHooks)
 				m.Combo("/{name}").Get(repo.GitHooksEdit).
@@ -646,6 +693,10 @@ func RegisterRoutes(m *web.Route) {
 				m.Post("/msteams/new", bindIgnErr(forms.NewMSTeamsHookForm{}), repo.MSTeamsHooksNewPost)
 				m.Post("/feishu/new", bindIgnErr(forms.NewFeishuHookForm{}), repo.FeishuHooksNewPost)
 				m.Post("/wechatwork/new", bindIgnErr(forms.NewWechatWorkHookForm{}), repo.WechatworkHooksNewPost)
+				m.Post("/mattermost/new", bindIgnErr(forms.NewMattermostHookForm{}), repo.MattermostHooksNewPost)
+				m.Post("/rocketchat/new", bindIgnErr(forms.NewRocketChatHookForm{}), repo.RocketChatHooksNewPost)
+				m.Post("/ntfy/new", bindIgnErr(forms.NewNtfyHookForm{}), repo.NtfyHooksNewPost)
+				m.Post("/custom/new", bindIgnErr(forms.NewCustomHookForm{}), repo.CustomHooksNewPost)
 				m.Get("/{id}", repo.WebHooksEdit)
 				m.Post("/{id}/test", repo.TestWebhook)
 				m.Post("/gitea/{id}", bindIgnErr(forms.NewWebhookForm{}), repo.WebHooksEditPost)
@@ -658,6 +709,10 @@ func RegisterRoutes(m *web.Route) {
 				m.Post("/msteams/{id}", bindIgnErr(forms.NewMSTeamsHookForm{}), repo.MSTeamsHooksEditPost)
 				m.Post("/feishu/{id}", bindIgnErr(forms.NewFeishuHookForm{}), repo.FeishuHooksEditPost)
 				m.Post("/wechatwork/{id}", bindIgnErr(forms.NewWechatWorkHookForm{}), repo.WechatworkHooksEditPost)
+				m.Post("/mattermost/{id}", bindIgnErr(forms.NewMattermostHookForm{}), repo.MattermostHooksEditPost)
+				m.Post("/rocketchat/{id}", bindIgnErr(forms.NewRocketChatHookForm{}), repo.RocketChatHooksEditPost)
+				m.Post("/ntfy/{id}", bindIgnErr(forms.NewNtfyHookForm{}), repo.NtfyHooksEditPost)
+				m.Post("/custom/{id}", bindIgnErr(forms.NewCustomHookForm{}), repo.CustomHooksEditPost)
 			}, webhooksEnabled)
 
 			m.Group("/keys", func() {
@@ -804,6 +859,7 @@ func RegisterRoutes(m *web.Route) {
 			}, bindIgnErr(forms.NewBranchForm{}))
 			m.Post("/delete", repo.DeleteBranchPost)
 			m.Post("/restore", repo.RestoreBranchPost)
+			m.Post("/merge-upstream", repo.MergeUpstreamPost)
 		}, context.RepoMustNotBeArchived(), reqRepoCodeWriter, repo.MustBeNotEmpty)
 
 	}, reqSignIn, context.RepoAssignment, context.UnitTypes())
@@ -0,0 +1,135 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package audit
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// RecordUser records an action concerning the account of user
+func RecordUser(req *http.Request, doer, user *models.User, action models.AuditAction, description string) {
+	record(req, doer, &models.AuditEvent{
+		Action:      action,
+		OwnerID:     user.ID,
+		Target:      user.Name,
+		Description: description,
+	})
+}
+
+// RecordSignInFailed records a failed sign in attempt, user is nil if loginName
+// doesn't belong to an account
+func RecordSignInFailed(req *http.Request, user *models.User, loginName, reason string) {
+	e := &models.AuditEvent{
+		Action:      models.AuditUserSignInFailed,
+		Target:      loginName,
+		Description: reason,
+	}
+	if user != nil {
+		e.OwnerID = user.ID
+	}
+	record(req, nil, e)
+}
+
+// RecordTeam records an action concerning a team of an organization, target names
+// the affected member and defaults to the team itself
+func RecordTeam(req *http.Request, doer *models.User, team *models.Team, action models.AuditAction, target, description string) {
+	if target == "" {
+		target = team.Name
+	}
+	record(req, doer, &models.AuditEvent{
+		Action:      action,
+		OwnerID:     team.OrgID,
+		Target:      target,
+		Description: description,
+	})
+}
+
+// RecordRepo records an action concerning the repository repo, target names the
+// affected collaborator, team or branch and defaults to the repository itself
+func RecordRepo(req *http.Request, doer *models.User, repo *models.Repository, action models.AuditAction, target, description string) {
+	if target == "" {
+		target = repo.FullName()
+	}
+	record(req, doer, &models.AuditEvent{
+		Action:      action,
+		OwnerID:     repo.OwnerID,
+		RepoID:      repo.ID,
+		Target:      target,
+		Description: description,
+	})
+}
+
+func record(req *http.Request, doer *models.User, e *models.AuditEvent) {
+	if doer != nil {
+		e.DoerID = doer.ID
+		e.DoerName = doer.Name
+	}
+	if req != nil {
+		e.IPAddress = remoteIP(req)
+		e.UserAgent = req.UserAgent()
+	}
+
+	if err := models.CreateAuditEvent(e); err != nil {
+		log.Error("CreateAuditEvent [%s]: %v", e.Action, err)
+		return
+	}
+
+	if setting.EnableAuditLog {
+		content, err := json.Marshal(convert.ToAuditEvent(e))
+		if err != nil {
+			log.Error("Marshal audit event [%d]: %v", e.ID, err)
+			return
+		}
+		if err := log.GetLogger("audit").SendLog(log.INFO, "", "", 0, string(content), ""); err != nil {
+			log.Error("Send audit event [%d]: %v", e.ID, err)
+		}
+	}
+}
+
+// UserChanges describes the changes of the security relevant settings between two
+// states of a user account, e.g. "admin: false -> true, password changed"
+func UserChanges(before, after *models.User) string {
+	changes := make([]string, 0, 4)
+	flag := func(name string, old, new bool) {
+		if old != new {
+			changes = append(changes, fmt.Sprintf("%s: %t -> %t", name, old, new))
+		}
+	}
+	flag("admin", before.IsAdmin, after.IsAdmin)
+	flag("restricted", before.IsRestricted, after.IsRestricted)
+	flag("active", before.IsActive, after.IsActive)
+	flag("prohibit login", before.ProhibitLogin, after.ProhibitLogin)
+	flag("allow git hooks", before.AllowGitHook, after.AllowGitHook)
+	flag("allow local import", before.AllowImportLocal, after.AllowImportLocal)
+	flag("allow create organization", before.AllowCreateOrganization, after.AllowCreateOrganization)
+	if before.LoginSource != after.LoginSource || before.LoginName != after.LoginName {
+		changes = append(changes, fmt.Sprintf("authentication source: %d -> %d (%s)", before.LoginSource, after.LoginSource, after.LoginName))
+	}
+	if before.Email != after.Email {
+		changes = append(changes, fmt.Sprintf("email: %s -> %s", before.Email, after.Email))
+	}
+	if before.Passwd != after.Passwd {
+		changes = append(changes, "password changed")
+	}
+	return strings.Join(changes, ", ")
+}
+
+// remoteIP returns the address of the client without the port
+func remoteIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
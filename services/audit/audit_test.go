@@ -0,0 +1,31 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package audit
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserChanges(t *testing.T) {
+	before := &models.User{Email: "user@example.com", Passwd: "hash", IsActive: true}
+
+	after := *before
+	assert.Empty(t, UserChanges(before, &after))
+
+	after.IsAdmin = true
+	after.IsActive = false
+	after.Passwd = "other"
+	assert.Equal(t, "admin: false -> true, active: true -> false, password changed", UserChanges(before, &after))
+}
+
+func TestRemoteIP(t *testing.T) {
+	assert.Equal(t, "192.0.2.1", remoteIP(&http.Request{RemoteAddr: "192.0.2.1:1234"}))
+	assert.Equal(t, "::1", remoteIP(&http.Request{RemoteAddr: "[::1]:80"}))
+	assert.Equal(t, "unix", remoteIP(&http.Request{RemoteAddr: "unix"}))
+}
@@ -12,9 +12,11 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/web/middleware"
+	"code.gitea.io/gitea/services/audit"
 )
 
 // Ensure the struct implements the interface.
@@ -111,6 +113,9 @@ func (b *Basic) Verify(req *http.Request, w http.ResponseWriter, store DataStore
 	if err != nil {
 		if !models.IsErrUserNotExist(err) {
 			log.Error("UserSignIn: %v", err)
+		} else {
+			notification.NotifyLoginFailed(uname, req.RemoteAddr, "invalid credentials")
+			audit.RecordSignInFailed(req, nil, uname, "invalid credentials")
 		}
 		return nil
 	}
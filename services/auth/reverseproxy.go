@@ -11,6 +11,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web/middleware"
 	"code.gitea.io/gitea/services/mailer"
@@ -114,6 +115,7 @@ func (r *ReverseProxy) newUser(req *http.Request) *models.User {
 	}
 
 	mailer.SendRegisterNotifyMail(user)
+	notification.NotifyCreateUser(user, user)
 
 	return user
 }
@@ -10,6 +10,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/login"
+	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/services/mailer"
 )
 
@@ -91,6 +92,7 @@ func (source *Source) Authenticate(user *models.User, userName, password string)
 	}
 
 	mailer.SendRegisterNotifyMail(user)
+	notification.NotifyCreateUser(user, user)
 
 	if isAttributeSSHPublicKeySet && models.AddPublicKeysBySource(user, source.loginSource, sr.SSHPublicKey) {
 		err = models.RewriteAllPublicKeys()
@@ -12,6 +12,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
 )
 
 // Sync causes this ldap source to synchronize its users with the db
@@ -112,6 +113,8 @@ func (source *Source) Sync(ctx context.Context, updateExisting bool) error {
 
 			if err != nil {
 				log.Error("SyncExternalUsers[%s]: Error creating user %s: %v", source.loginSource.Name, su.Username, err)
+			} else {
+				notification.NotifyCreateUser(nil, usr)
 			}
 
 			if err == nil && isAttributeSSHPublicKeySet {
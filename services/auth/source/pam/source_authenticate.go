@@ -11,6 +11,7 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/login"
 	"code.gitea.io/gitea/modules/auth/pam"
+	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/services/mailer"
 
@@ -66,6 +67,7 @@ func (source *Source) Authenticate(user *models.User, userName, password string)
 	}
 
 	mailer.SendRegisterNotifyMail(user)
+	notification.NotifyCreateUser(user, user)
 
 	return user, nil
 }
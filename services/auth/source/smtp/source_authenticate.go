@@ -12,6 +12,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/login"
+	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/services/mailer"
 )
@@ -82,6 +83,7 @@ func (source *Source) Authenticate(user *models.User, userName, password string)
 	}
 
 	mailer.SendRegisterNotifyMail(user)
+	notification.NotifyCreateUser(user, user)
 
 	return user, nil
 }
@@ -14,6 +14,7 @@ import (
 	"code.gitea.io/gitea/models/login"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/templates"
 	"code.gitea.io/gitea/modules/web/middleware"
@@ -202,6 +203,7 @@ func (s *SSPI) newUser(username string, cfg *sspi.Source) (*models.User, error)
 	}
 
 	mailer.SendRegisterNotifyMail(user)
+	notification.NotifyCreateUser(user, user)
 
 	return user, nil
 }
@@ -71,3 +71,18 @@ func (f *AdminDashboardForm) Validate(req *http.Request, errs binding.Errors) bi
 	ctx := context.GetContext(req)
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
+
+// AdminBulkRepoForm form for admin to apply an operation to many repositories
+type AdminBulkRepoForm struct {
+	Action     string `binding:"Required"`
+	Repos      string `binding:"Required"`
+	NewOwner   string
+	Visibility string
+	Team       string
+}
+
+// Validate validates form fields
+func (f *AdminBulkRepoForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
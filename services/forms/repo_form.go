@@ -6,8 +6,10 @@
 package forms
 
 import (
+	"mime/multipart"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strings"
 
 	"code.gitea.io/gitea/models"
@@ -49,6 +51,9 @@ type CreateRepoForm struct {
 	Avatar       bool
 	Labels       bool
 	TrustModel   string
+
+	ProtectedBranches bool
+	Teams             bool
 }
 
 // Validate validates the fields
@@ -82,6 +87,7 @@ type MigrateRepoForm struct {
 	PullRequests   bool   `json:"pull_requests"`
 	Releases       bool   `json:"releases"`
 	MirrorInterval string `json:"mirror_interval"`
+	MirrorMetadata bool   `json:"mirror_metadata"`
 }
 
 // Validate validates the fields
@@ -111,25 +117,58 @@ func ParseRemoteAddr(remoteAddr, authUsername, authPassword string) (string, err
 	return remoteAddr, nil
 }
 
+// scpLikeAddr matches SSH addresses in the scp-like syntax, e.g. git@example.com:owner/repo.git
+var scpLikeAddr = regexp.MustCompile(`^([\w.-]+)@([\w.-]+):(.+)$`)
+
+// ParsePushMirrorAddr parses the remote address of a push mirror, which is either accepted by
+// ParseRemoteAddr or an SSH address. SSH addresses are returned as ssh:// URLs, their user
+// defaults to the given username and passwords are not supported.
+func ParsePushMirrorAddr(remoteAddr, authUsername, authPassword string) (addr string, isSSH bool, err error) {
+	remoteAddr = strings.TrimSpace(remoteAddr)
+	if m := scpLikeAddr.FindStringSubmatch(remoteAddr); m != nil {
+		remoteAddr = "ssh://" + m[1] + "@" + m[2] + "/" + strings.TrimPrefix(m[3], "/")
+	}
+	if !strings.HasPrefix(remoteAddr, "ssh://") {
+		addr, err = ParseRemoteAddr(remoteAddr, authUsername, authPassword)
+		return addr, false, err
+	}
+
+	u, err := url.Parse(remoteAddr)
+	if err != nil {
+		return "", true, &models.ErrInvalidCloneAddr{IsURLError: true}
+	}
+	if len(authPassword) > 0 {
+		return "", true, &models.ErrInvalidCloneAddr{Host: u.Host, IsURLError: true}
+	}
+	if u.User == nil && len(authUsername) > 0 {
+		u.User = url.User(authUsername)
+	}
+	return u.String(), true, nil
+}
+
 // RepoSettingForm form for changing repository settings
 type RepoSettingForm struct {
-	RepoName           string `binding:"Required;AlphaDashDot;MaxSize(100)"`
-	Description        string `binding:"MaxSize(255)"`
-	Website            string `binding:"ValidUrl;MaxSize(255)"`
-	Interval           string
-	MirrorAddress      string
-	MirrorUsername     string
-	MirrorPassword     string
-	LFS                bool   `form:"mirror_lfs"`
-	LFSEndpoint        string `form:"mirror_lfs_endpoint"`
-	PushMirrorID       string
-	PushMirrorAddress  string
-	PushMirrorUsername string
-	PushMirrorPassword string
-	PushMirrorInterval string
-	Private            bool
-	Template           bool
-	EnablePrune        bool
+	RepoName               string `binding:"Required;AlphaDashDot;MaxSize(100)"`
+	Description            string `binding:"MaxSize(255)"`
+	Website                string `binding:"ValidUrl;MaxSize(255)"`
+	Interval               string
+	MirrorAddress          string
+	MirrorUsername         string
+	MirrorPassword         string
+	LFS                    bool   `form:"mirror_lfs"`
+	LFSEndpoint            string `form:"mirror_lfs_endpoint"`
+	SyncMetadata           bool   `form:"mirror_sync_metadata"`
+	PushMirrorID           string
+	PushMirrorAddress      string
+	PushMirrorUsername     string
+	PushMirrorPassword     string
+	PushMirrorInterval     string
+	PushMirrorBranchFilter string
+	PushMirrorTagFilter    string
+	PushMirrorSyncOnCommit bool
+	Private                bool
+	Template               bool
+	EnablePrune            bool
 
 	// Advanced settings
 	EnableWiki                            bool
@@ -170,6 +209,18 @@ func (f *RepoSettingForm) Validate(req *http.Request, errs binding.Errors) bindi
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// IssueImportForm form for uploading an issue export to import into a repository
+type IssueImportForm struct {
+	Format string `binding:"Required"`
+	File   *multipart.FileHeader
+}
+
+// Validate validates the fields
+func (f *IssueImportForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // __________                             .__
 // \______   \____________    ____   ____ |  |__
 //  |    |  _/\_  __ \__  \  /    \_/ ___\|  |  \
@@ -236,6 +287,14 @@ type WebhookForm struct {
 	PullRequestReview    bool
 	PullRequestSync      bool
 	Repository           bool
+	Wiki                 bool
+	Status               bool
+	Member               bool
+	BranchProtection     bool
+	Star                 bool
+	User                 bool
+	Organization         bool
+	LoginFailed          bool
 	Active               bool
 	BranchFilter         string `binding:"GlobPattern"`
 }
@@ -395,6 +454,68 @@ func (f *NewWechatWorkHookForm) Validate(req *http.Request, errs binding.Errors)
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// NewMattermostHookForm form for creating Mattermost hook
+type NewMattermostHookForm struct {
+	PayloadURL string `binding:"Required;ValidUrl"`
+	Channel    string
+	Username   string
+	IconURL    string
+	WebhookForm
+}
+
+// Validate validates the fields
+func (f *NewMattermostHookForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// NewRocketChatHookForm form for creating Rocket.Chat hook
+type NewRocketChatHookForm struct {
+	PayloadURL string `binding:"Required;ValidUrl"`
+	Channel    string
+	Username   string
+	IconURL    string
+	WebhookForm
+}
+
+// Validate validates the fields
+func (f *NewRocketChatHookForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// NewNtfyHookForm form for creating ntfy hook
+type NewNtfyHookForm struct {
+	PayloadURL  string `binding:"Required;ValidUrl"`
+	Topic       string `binding:"Required;MaxSize(64)"`
+	Priority    int    `binding:"Range(0,5)"`
+	AccessToken string
+	WebhookForm
+}
+
+// Validate validates the fields
+func (f *NewNtfyHookForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// NewCustomHookForm form for creating a webhook with a user defined payload
+type NewCustomHookForm struct {
+	PayloadURL         string `binding:"Required;ValidUrl"`
+	HTTPMethod         string `binding:"Required;In(POST,PUT)"`
+	PayloadContentType string `binding:"MaxSize(255)"`
+	PayloadTemplate    string `binding:"Required"`
+	Headers            string
+	Secret             string
+	WebhookForm
+}
+
+// Validate validates the fields
+func (f *NewCustomHookForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // .___
 // |   | ______ ________ __   ____
 // |   |/  ___//  ___/  |  \_/ __ \
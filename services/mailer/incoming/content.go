@@ -0,0 +1,182 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package incoming
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"regexp"
+	"strings"
+
+	"github.com/jaytaylor/html2text"
+	"golang.org/x/net/html/charset"
+)
+
+// maxMIMEDepth limits the nesting of multipart messages
+const maxMIMEDepth = 10
+
+// Message is the content of an incoming email which is relevant for the handlers
+type Message struct {
+	Header      mail.Header
+	Content     string
+	Attachments []*Attachment
+}
+
+// Attachment is a file attached to an incoming email
+type Attachment struct {
+	Name    string
+	Content []byte
+}
+
+// messageParser collects the text and the attachments of the MIME parts
+type messageParser struct {
+	msg   *Message
+	plain string
+	html  string
+}
+
+// ParseMessage parses an email and extracts its text without the quoted reply and the signature
+func ParseMessage(r io.Reader) (*Message, error) {
+	m, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &messageParser{msg: &Message{Header: m.Header}}
+	if err := p.parsePart(textproto.MIMEHeader(m.Header), m.Body, 0); err != nil {
+		return nil, err
+	}
+
+	content := p.plain
+	if content == "" && p.html != "" {
+		content, err = html2text.FromString(p.html)
+		if err != nil {
+			return nil, err
+		}
+	}
+	p.msg.Content = StripQuotedReply(content)
+
+	return p.msg, nil
+}
+
+func (p *messageParser) parsePart(header textproto.MIMEHeader, body io.Reader, depth int) error {
+	if depth > maxMIMEDepth {
+		return fmt.Errorf("MIME parts nested too deeply")
+	}
+
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	switch strings.ToLower(header.Get("Content-Transfer-Encoding")) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err := p.parsePart(part.Header, part, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	disposition, dispositionParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	filename := dispositionParams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+	if filename != "" {
+		if decoded, err := new(mime.WordDecoder).DecodeHeader(filename); err == nil {
+			filename = decoded
+		}
+	}
+
+	isText := mediaType == "text/plain" || mediaType == "text/html"
+	if disposition == "attachment" || (filename != "" && !isText) {
+		if filename == "" {
+			// unnamed attachments can't be uploaded
+			return nil
+		}
+		content, err := io.ReadAll(body)
+		if err != nil {
+			return err
+		}
+		p.msg.Attachments = append(p.msg.Attachments, &Attachment{Name: filename, Content: content})
+		return nil
+	}
+
+	if !isText {
+		return nil
+	}
+
+	if cs := params["charset"]; cs != "" && !strings.EqualFold(cs, "utf-8") && !strings.EqualFold(cs, "us-ascii") {
+		if r, err := charset.NewReaderLabel(cs, body); err == nil {
+			body = r
+		}
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	// the first text alternative wins
+	if mediaType == "text/plain" && p.plain == "" {
+		p.plain = string(content)
+	} else if mediaType == "text/html" && p.html == "" {
+		p.html = string(content)
+	}
+	return nil
+}
+
+var (
+	// replyHeaderPattern matches the line(s) introducing the quoted message, e.g.
+	// "On Mon, Oct 18, 2021 at 10:00 AM User <user@example.com> wrote:" which may be wrapped
+	replyHeaderPattern = regexp.MustCompile(`(?m)^(On\s[^\n]*(\n[^\n]*)?wrote:[ \t]*|-{3,}\s*Original Message\s*-{3,}[ \t]*|_{20,}[ \t]*)$`)
+
+	// signatureDelimiterPattern matches the line separating the signature from the text
+	signatureDelimiterPattern = regexp.MustCompile(`(?m)^-- ?$`)
+)
+
+// StripQuotedReply removes the quoted message a mail client appends to a reply and the signature
+func StripQuotedReply(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	if loc := replyHeaderPattern.FindStringIndex(content); loc != nil {
+		content = content[:loc[0]]
+	}
+	if loc := signatureDelimiterPattern.FindStringIndex(content); loc != nil {
+		content = content[:loc[0]]
+	}
+
+	// remove the trailing quote, quotes in between the answers are kept as markdown quotes
+	lines := strings.Split(content, "\n")
+	for len(lines) > 0 {
+		line := strings.TrimSpace(lines[len(lines)-1])
+		if line != "" && !strings.HasPrefix(line, ">") {
+			break
+		}
+		lines = lines[:len(lines)-1]
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
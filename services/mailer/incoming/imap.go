@@ -0,0 +1,283 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package incoming
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const imapCommandTimeout = 5 * time.Minute
+
+var (
+	imapLiteralPattern = regexp.MustCompile(`\{(\d+)\+?\}$`)
+	imapSizePattern    = regexp.MustCompile(`RFC822\.SIZE (\d+)`)
+)
+
+// imapResponse is an untagged response of the server with its literals
+type imapResponse struct {
+	Line     string
+	Literals [][]byte
+}
+
+// imapClient implements the small subset of IMAP4rev1 (RFC 3501) needed to fetch new messages
+type imapClient struct {
+	conn net.Conn
+	rd   *bufio.Reader
+	tag  int
+}
+
+func dialIMAP(ctx context.Context) (*imapClient, error) {
+	addr := net.JoinHostPort(setting.IncomingEmail.Host, strconv.Itoa(setting.IncomingEmail.Port))
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+
+	var conn net.Conn
+	var err error
+	if setting.IncomingEmail.UseTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{
+			ServerName:         setting.IncomingEmail.Host,
+			InsecureSkipVerify: setting.IncomingEmail.SkipTLSVerify,
+		})
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &imapClient{conn: conn, rd: bufio.NewReader(conn)}
+	_ = conn.SetDeadline(time.Now().Add(imapCommandTimeout))
+	greeting, err := c.readResponse()
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	if !strings.HasPrefix(greeting.Line, "* OK") && !strings.HasPrefix(greeting.Line, "* PREAUTH") {
+		c.Close()
+		return nil, fmt.Errorf("unexpected IMAP greeting: %s", greeting.Line)
+	}
+	return c, nil
+}
+
+// Close closes the connection to the server
+func (c *imapClient) Close() {
+	_ = c.conn.Close()
+}
+
+// readResponse reads a response line and the literals embedded in it
+func (c *imapClient) readResponse() (*imapResponse, error) {
+	resp := &imapResponse{}
+	var sb strings.Builder
+	for {
+		line, err := c.rd.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		sb.WriteString(line)
+
+		match := imapLiteralPattern.FindStringSubmatch(line)
+		if match == nil {
+			break
+		}
+		size, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, err
+		}
+		if uint64(size) > uint64(setting.IncomingEmail.MaximumMessageSize)+1024 {
+			return nil, fmt.Errorf("IMAP literal of %d bytes is too large", size)
+		}
+		literal := make([]byte, size)
+		if _, err := io.ReadFull(c.rd, literal); err != nil {
+			return nil, err
+		}
+		resp.Literals = append(resp.Literals, literal)
+	}
+	resp.Line = sb.String()
+	return resp, nil
+}
+
+// execute sends the command and returns the untagged responses if it completes successfully
+func (c *imapClient) execute(format string, args ...interface{}) ([]*imapResponse, error) {
+	c.tag++
+	tag := fmt.Sprintf("A%04d", c.tag)
+	command := fmt.Sprintf(format, args...)
+
+	_ = c.conn.SetDeadline(time.Now().Add(imapCommandTimeout))
+	if _, err := fmt.Fprintf(c.conn, "%s %s\r\n", tag, command); err != nil {
+		return nil, err
+	}
+
+	var responses []*imapResponse
+	for {
+		resp, err := c.readResponse()
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(resp.Line, tag+" ") {
+			status := strings.TrimPrefix(resp.Line, tag+" ")
+			if !strings.HasPrefix(status, "OK") {
+				// never log the credentials
+				name := strings.SplitN(command, " ", 2)[0]
+				return nil, fmt.Errorf("IMAP command %s failed: %s", name, status)
+			}
+			return responses, nil
+		}
+		responses = append(responses, resp)
+	}
+}
+
+func imapQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// Login authenticates and selects the mailbox
+func (c *imapClient) Login(username, password, mailbox string) error {
+	if _, err := c.execute("LOGIN %s %s", imapQuote(username), imapQuote(password)); err != nil {
+		return err
+	}
+	_, err := c.execute("SELECT %s", imapQuote(mailbox))
+	return err
+}
+
+// SearchUnseen returns the UIDs of the unseen messages
+func (c *imapClient) SearchUnseen() ([]uint32, error) {
+	responses, err := c.execute("UID SEARCH UNSEEN")
+	if err != nil {
+		return nil, err
+	}
+
+	var uids []uint32
+	for _, resp := range responses {
+		if !strings.HasPrefix(resp.Line, "* SEARCH") {
+			continue
+		}
+		for _, field := range strings.Fields(strings.TrimPrefix(resp.Line, "* SEARCH")) {
+			uid, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid UID %q in SEARCH response", field)
+			}
+			uids = append(uids, uint32(uid))
+		}
+	}
+	return uids, nil
+}
+
+// Size returns the size of the message
+func (c *imapClient) Size(uid uint32) (uint64, error) {
+	responses, err := c.execute("UID FETCH %d (RFC822.SIZE)", uid)
+	if err != nil {
+		return 0, err
+	}
+	for _, resp := range responses {
+		if match := imapSizePattern.FindStringSubmatch(resp.Line); match != nil {
+			return strconv.ParseUint(match[1], 10, 64)
+		}
+	}
+	return 0, fmt.Errorf("no size returned for message %d", uid)
+}
+
+// Fetch returns the content of the message without marking it as seen
+func (c *imapClient) Fetch(uid uint32) ([]byte, error) {
+	responses, err := c.execute("UID FETCH %d BODY.PEEK[]", uid)
+	if err != nil {
+		return nil, err
+	}
+	for _, resp := range responses {
+		if strings.Contains(resp.Line, "FETCH") && len(resp.Literals) > 0 {
+			return resp.Literals[0], nil
+		}
+	}
+	return nil, fmt.Errorf("no content returned for message %d", uid)
+}
+
+// MarkHandled flags the message as seen or deleted
+func (c *imapClient) MarkHandled(uid uint32, deleted bool) error {
+	flags := `\Seen`
+	if deleted {
+		flags = `\Seen \Deleted`
+	}
+	_, err := c.execute("UID STORE %d +FLAGS.SILENT (%s)", uid, flags)
+	return err
+}
+
+// Expunge removes the deleted messages
+func (c *imapClient) Expunge() error {
+	_, err := c.execute("EXPUNGE")
+	return err
+}
+
+// Logout ends the session
+func (c *imapClient) Logout() {
+	if _, err := c.execute("LOGOUT"); err != nil {
+		log.Debug("IMAP LOGOUT failed: %v", err)
+	}
+}
+
+// imapSource fetches the unseen messages of an IMAP mailbox
+type imapSource struct{}
+
+// Process implements source
+func (imapSource) Process(ctx context.Context, handle func(content []byte)) error {
+	c, err := dialIMAP(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to connect to the IMAP server: %w", err)
+	}
+	defer c.Close()
+
+	if err := c.Login(setting.IncomingEmail.Username, setting.IncomingEmail.Password, setting.IncomingEmail.Mailbox); err != nil {
+		return err
+	}
+	defer c.Logout()
+
+	uids, err := c.SearchUnseen()
+	if err != nil {
+		return err
+	}
+
+	deleted := false
+	for _, uid := range uids {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		size, err := c.Size(uid)
+		if err != nil {
+			return err
+		}
+		if size > uint64(setting.IncomingEmail.MaximumMessageSize) {
+			log.Warn("Incoming email %d is too large: %d bytes", uid, size)
+		} else {
+			content, err := c.Fetch(uid)
+			if err != nil {
+				return err
+			}
+			handle(content)
+		}
+
+		if err := c.MarkHandled(uid, setting.IncomingEmail.DeleteHandledMessage); err != nil {
+			return err
+		}
+		deleted = deleted || setting.IncomingEmail.DeleteHandledMessage
+	}
+
+	if deleted {
+		return c.Expunge()
+	}
+	return nil
+}
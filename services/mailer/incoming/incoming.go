@@ -0,0 +1,141 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package incoming
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/mail"
+	"net/textproto"
+	"regexp"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/services/mailer/token"
+)
+
+// source provides the incoming messages
+type source interface {
+	// Process passes every new message to handle and marks it as handled afterwards
+	Process(ctx context.Context, handle func(content []byte)) error
+}
+
+// tokenHeaders are the headers which may contain the address the message was sent to
+var tokenHeaders = []string{"To", "Delivered-To", "Cc", "X-Original-To", "X-Envelope-To"}
+
+var addressTokenPattern *regexp.Regexp
+
+// Init starts to process the incoming email if it is enabled
+func Init(ctx context.Context) error {
+	if !setting.IncomingEmail.Enabled {
+		return nil
+	}
+
+	initAddressTokenPattern()
+
+	var src source = imapSource{}
+	if setting.IncomingEmail.Maildir != "" {
+		src = &maildirSource{path: setting.IncomingEmail.Maildir}
+	}
+
+	go graceful.GetManager().RunWithShutdownContext(func(ctx context.Context) {
+		ticker := time.NewTicker(setting.IncomingEmail.PollInterval)
+		defer ticker.Stop()
+		for {
+			processSource(ctx, src)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+	return nil
+}
+
+func initAddressTokenPattern() {
+	parts := strings.SplitN(setting.IncomingEmail.ReplyToAddress, setting.IncomingEmailTokenPlaceholder, 2)
+	addressTokenPattern = regexp.MustCompile(`(?i)^` + regexp.QuoteMeta(parts[0]) + `([a-z2-7]+)` + regexp.QuoteMeta(parts[1]) + `$`)
+}
+
+func processSource(ctx context.Context, src source) {
+	if err := src.Process(ctx, func(content []byte) {
+		if err := processMessage(ctx, content); err != nil {
+			log.Error("Unable to process incoming email: %v", err)
+		}
+	}); err != nil {
+		log.Error("Unable to fetch incoming email: %v", err)
+	}
+}
+
+// processMessage verifies the token the message was sent to and executes its handler
+func processMessage(ctx context.Context, content []byte) error {
+	msg, err := ParseMessage(bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("unable to parse message: %w", err)
+	}
+
+	if isAutomaticReply(msg.Header) {
+		log.Debug("Ignoring automatic reply %s", msg.Header.Get("Message-ID"))
+		return nil
+	}
+
+	handlerToken := searchTokenInHeaders(msg.Header)
+	if handlerToken == "" {
+		log.Debug("Incoming email %s has no reply token", msg.Header.Get("Message-ID"))
+		return nil
+	}
+
+	handlerType, user, payload, err := token.ExtractToken(handlerToken)
+	if err != nil {
+		return fmt.Errorf("%w in message %s", err, msg.Header.Get("Message-ID"))
+	}
+	if !user.IsActive || user.ProhibitLogin {
+		return fmt.Errorf("%s is not allowed to interact by email", user.Name)
+	}
+
+	handle, ok := handlers[handlerType]
+	if !ok {
+		return fmt.Errorf("unsupported handler type %d", handlerType)
+	}
+	return handle(ctx, user, msg, payload)
+}
+
+// isAutomaticReply detects vacation notices and other automatically generated messages
+func isAutomaticReply(header mail.Header) bool {
+	if autoSubmitted := header.Get("Auto-Submitted"); autoSubmitted != "" && !strings.EqualFold(autoSubmitted, "no") {
+		return true
+	}
+	if header.Get("X-Autoreply") != "" || header.Get("X-Autorespond") != "" {
+		return true
+	}
+	switch strings.ToLower(header.Get("Precedence")) {
+	case "auto_reply", "bulk", "junk":
+		return true
+	}
+	return false
+}
+
+// searchTokenInHeaders returns the token of the first address matching setting.IncomingEmail.ReplyToAddress
+func searchTokenInHeaders(header mail.Header) string {
+	for _, name := range tokenHeaders {
+		for _, value := range header[textproto.CanonicalMIMEHeaderKey(name)] {
+			addresses, err := mail.ParseAddressList(value)
+			if err != nil {
+				addresses = []*mail.Address{{Address: strings.Trim(strings.TrimSpace(value), "<>")}}
+			}
+			for _, address := range addresses {
+				if match := addressTokenPattern.FindStringSubmatch(address.Address); match != nil {
+					return match[1]
+				}
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,109 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package incoming
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/upload"
+	"code.gitea.io/gitea/services/attachment"
+	"code.gitea.io/gitea/services/comments"
+	"code.gitea.io/gitea/services/mailer/token"
+)
+
+// handler executes the action a token was created for
+type handler func(ctx context.Context, doer *models.User, msg *Message, payload []byte) error
+
+var handlers = map[token.HandlerType]handler{
+	token.ReplyHandlerType:       handleReply,
+	token.UnsubscribeHandlerType: handleUnsubscribe,
+}
+
+// issueFromPayload loads the issue whose id is stored in the payload of a token
+func issueFromPayload(payload []byte) (*models.Issue, error) {
+	issueID, n := binary.Uvarint(payload)
+	if n <= 0 {
+		return nil, fmt.Errorf("invalid payload")
+	}
+
+	issue, err := models.GetIssueByID(int64(issueID))
+	if err != nil {
+		return nil, err
+	}
+	if err := issue.LoadRepo(); err != nil {
+		return nil, err
+	}
+	return issue, nil
+}
+
+// handleReply posts the reply as comment on the issue or pull request
+func handleReply(ctx context.Context, doer *models.User, msg *Message, payload []byte) error {
+	issue, err := issueFromPayload(payload)
+	if err != nil {
+		return fmt.Errorf("unable to load issue: %w", err)
+	}
+
+	perm, err := models.GetUserRepoPermission(issue.Repo, doer)
+	if err != nil {
+		return err
+	}
+	if !perm.CanReadIssuesOrPulls(issue.IsPull) {
+		return fmt.Errorf("%s is not allowed to read %s#%d", doer.Name, issue.Repo.FullName(), issue.Index)
+	}
+	if issue.Repo.IsArchived {
+		return fmt.Errorf("%s is archived", issue.Repo.FullName())
+	}
+	if issue.IsLocked && !perm.CanWriteIssuesOrPulls(issue.IsPull) && !doer.IsAdmin {
+		return fmt.Errorf("%s#%d is locked", issue.Repo.FullName(), issue.Index)
+	}
+
+	attachmentIDs := make([]string, 0, len(msg.Attachments))
+	if setting.Attachment.Enabled {
+		for _, a := range msg.Attachments {
+			if len(attachmentIDs) >= setting.Attachment.MaxFiles {
+				log.Info("Incoming email of %s for %s#%d has more than %d attachments", doer.Name, issue.Repo.FullName(), issue.Index, setting.Attachment.MaxFiles)
+				break
+			}
+			if int64(len(a.Content)) > setting.Attachment.MaxSize<<20 {
+				log.Info("Attachment %s of incoming email is too large", a.Name)
+				continue
+			}
+			attach, err := attachment.UploadAttachment(bytes.NewReader(a.Content), doer.ID, issue.Repo.ID, 0, a.Name, setting.Attachment.AllowedTypes)
+			if err != nil {
+				if upload.IsErrFileTypeForbidden(err) {
+					log.Info("Attachment %s of incoming email has a forbidden file type", a.Name)
+					continue
+				}
+				return err
+			}
+			attachmentIDs = append(attachmentIDs, attach.UUID)
+		}
+	}
+
+	if msg.Content == "" && len(attachmentIDs) == 0 {
+		log.Debug("Incoming email of %s for %s#%d has no content", doer.Name, issue.Repo.FullName(), issue.Index)
+		return nil
+	}
+
+	_, err = comments.CreateIssueComment(doer, issue.Repo, issue, msg.Content, attachmentIDs)
+	return err
+}
+
+// handleUnsubscribe stops the notifications of the user for the issue or pull request
+func handleUnsubscribe(ctx context.Context, doer *models.User, _ *Message, payload []byte) error {
+	issue, err := issueFromPayload(payload)
+	if err != nil {
+		return fmt.Errorf("unable to load issue: %w", err)
+	}
+
+	log.Trace("Incoming email: %s unsubscribed from %s#%d", doer.Name, issue.Repo.FullName(), issue.Index)
+	return models.CreateOrUpdateIssueWatch(doer.ID, issue.ID, false)
+}
@@ -0,0 +1,215 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package incoming
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/services/mailer/token"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	db.MainTest(m, filepath.Join("..", "..", ".."))
+}
+
+func TestStripQuotedReply(t *testing.T) {
+	kases := []struct {
+		content  string
+		expected string
+	}{
+		{
+			content:  "Thanks, fixed.\r\n\r\nOn Mon, Oct 18, 2021 at 10:00 AM User <user@example.com> wrote:\r\n> The build fails\r\n",
+			expected: "Thanks, fixed.",
+		},
+		{
+			content:  "Thanks, fixed.\n\nOn Mon, Oct 18, 2021 at 10:00 AM User\n<user@example.com> wrote:\n> The build fails\n",
+			expected: "Thanks, fixed.",
+		},
+		{
+			content:  "Thanks, fixed.\n\n-----Original Message-----\nFrom: Gitea\n",
+			expected: "Thanks, fixed.",
+		},
+		{
+			content:  "Thanks, fixed.\n\n-- \nJane Doe\nExample Inc.\n",
+			expected: "Thanks, fixed.",
+		},
+		{
+			content:  "> The build fails\n\nWhich version?\n\n> on linux\n> and windows\n",
+			expected: "> The build fails\n\nWhich version?",
+		},
+		{
+			content:  "  \n",
+			expected: "",
+		},
+	}
+	for _, kase := range kases {
+		assert.Equal(t, kase.expected, StripQuotedReply(kase.content))
+	}
+}
+
+func TestParseMessage(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 32)...)
+	content := strings.Join([]string{
+		"From: User Two <user2@example.com>",
+		"To: incoming+abc@example.com",
+		"Subject: Re: [user2/repo1] issue1 (#1)",
+		"MIME-Version: 1.0",
+		`Content-Type: multipart/mixed; boundary="outer"`,
+		"",
+		"--outer",
+		`Content-Type: multipart/alternative; boundary="inner"`,
+		"",
+		"--inner",
+		"Content-Type: text/plain; charset=iso-8859-1",
+		"Content-Transfer-Encoding: quoted-printable",
+		"",
+		"Gr=FC=DFe, it works=",
+		" now.",
+		"",
+		"On Mon, Oct 18, 2021 at 10:00 AM Gitea wrote:",
+		"> issue content",
+		"--inner",
+		"Content-Type: text/html; charset=utf-8",
+		"",
+		"<p>ignored</p>",
+		"--inner--",
+		"--outer",
+		`Content-Type: image/png; name="screenshot.png"`,
+		`Content-Disposition: attachment; filename="screenshot.png"`,
+		"Content-Transfer-Encoding: base64",
+		"",
+		base64.StdEncoding.EncodeToString(png),
+		"--outer--",
+		"",
+	}, "\r\n")
+
+	msg, err := ParseMessage(strings.NewReader(content))
+	assert.NoError(t, err)
+	assert.Equal(t, "Grüße, it works now.", msg.Content)
+	if assert.Len(t, msg.Attachments, 1) {
+		assert.Equal(t, "screenshot.png", msg.Attachments[0].Name)
+		assert.Equal(t, png, msg.Attachments[0].Content)
+	}
+
+	t.Run("HTMLOnly", func(t *testing.T) {
+		msg, err := ParseMessage(strings.NewReader("Content-Type: text/html\r\n\r\n<p>Looks <b>good</b></p>\r\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, "Looks *good*", msg.Content)
+	})
+}
+
+func composeReply(handlerType token.HandlerType, user *models.User, issue *models.Issue, headers, body string) []byte {
+	issueID := make([]byte, binary.MaxVarintLen64)
+	issueID = issueID[:binary.PutUvarint(issueID, uint64(issue.ID))]
+	address := strings.Replace(setting.IncomingEmail.ReplyToAddress, setting.IncomingEmailTokenPlaceholder, token.CreateToken(handlerType, user, issueID), 1)
+
+	return []byte("From: " + user.Email + "\r\nTo: <" + address + ">\r\nMessage-ID: <reply@example.com>\r\n" + headers + "\r\n" + body)
+}
+
+func TestProcessMessage(t *testing.T) {
+	assert.NoError(t, db.PrepareTestDatabase())
+
+	oldReplyToAddress := setting.IncomingEmail.ReplyToAddress
+	setting.IncomingEmail.ReplyToAddress = "incoming+" + setting.IncomingEmailTokenPlaceholder + "@example.com"
+	initAddressTokenPattern()
+	defer func() {
+		setting.IncomingEmail.ReplyToAddress = oldReplyToAddress
+	}()
+
+	user := db.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	issue := db.AssertExistsAndLoadBean(t, &models.Issue{ID: 1}).(*models.Issue)
+
+	t.Run("Reply", func(t *testing.T) {
+		content := composeReply(token.ReplyHandlerType, user, issue, "", "Reply by email\r\n\r\n> quoted\r\n")
+		assert.NoError(t, processMessage(context.Background(), content))
+
+		db.AssertExistsAndLoadBean(t, &models.Comment{
+			IssueID:  issue.ID,
+			PosterID: user.ID,
+			Type:     models.CommentTypeComment,
+			Content:  "Reply by email",
+		})
+	})
+
+	t.Run("AutomaticReply", func(t *testing.T) {
+		content := composeReply(token.ReplyHandlerType, user, issue, "Auto-Submitted: auto-replied\r\n", "I am on vacation\r\n")
+		assert.NoError(t, processMessage(context.Background(), content))
+
+		db.AssertNotExistsBean(t, &models.Comment{IssueID: issue.ID, Content: "I am on vacation"})
+	})
+
+	t.Run("InvalidToken", func(t *testing.T) {
+		content := composeReply(token.ReplyHandlerType, user, issue, "", "Forged\r\n")
+		content = []byte(strings.Replace(string(content), "incoming+", "incoming+a", 1))
+		assert.Error(t, processMessage(context.Background(), content))
+
+		db.AssertNotExistsBean(t, &models.Comment{IssueID: issue.ID, Content: "Forged"})
+	})
+
+	t.Run("NoPermission", func(t *testing.T) {
+		// user 5 can't read the private repository 2
+		privateIssue := db.AssertExistsAndLoadBean(t, &models.Issue{ID: 4}).(*models.Issue)
+		other := db.AssertExistsAndLoadBean(t, &models.User{ID: 5}).(*models.User)
+		content := composeReply(token.ReplyHandlerType, other, privateIssue, "", "Not allowed\r\n")
+		assert.Error(t, processMessage(context.Background(), content))
+
+		db.AssertNotExistsBean(t, &models.Comment{IssueID: privateIssue.ID, Content: "Not allowed"})
+	})
+
+	t.Run("Unsubscribe", func(t *testing.T) {
+		content := composeReply(token.UnsubscribeHandlerType, user, issue, "", "unsubscribe\r\n")
+		assert.NoError(t, processMessage(context.Background(), content))
+
+		watch, exists, err := models.GetIssueWatch(user.ID, issue.ID)
+		assert.NoError(t, err)
+		assert.True(t, exists)
+		assert.False(t, watch.IsWatching)
+	})
+}
+
+func TestMaildirSource(t *testing.T) {
+	dir, err := os.MkdirTemp("", "maildir")
+	assert.NoError(t, err)
+	defer util.RemoveAll(dir)
+	for _, sub := range []string{"new", "cur", "tmp"} {
+		assert.NoError(t, os.Mkdir(filepath.Join(dir, sub), 0o700))
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "new", "1.mail"), []byte("first"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "new", "2.mail"), []byte("second"), 0o600))
+
+	oldDeleteHandledMessage := setting.IncomingEmail.DeleteHandledMessage
+	defer func() {
+		setting.IncomingEmail.DeleteHandledMessage = oldDeleteHandledMessage
+	}()
+	setting.IncomingEmail.DeleteHandledMessage = false
+
+	var handled []string
+	src := &maildirSource{path: dir}
+	assert.NoError(t, src.Process(context.Background(), func(content []byte) {
+		handled = append(handled, string(content))
+	}))
+	assert.Equal(t, []string{"first", "second"}, handled)
+	assert.FileExists(t, filepath.Join(dir, "cur", "1.mail:2,S"))
+	assert.FileExists(t, filepath.Join(dir, "cur", "2.mail:2,S"))
+
+	// handled messages are not processed again
+	handled = nil
+	assert.NoError(t, src.Process(context.Background(), func(content []byte) {
+		handled = append(handled, string(content))
+	}))
+	assert.Empty(t, handled)
+}
@@ -0,0 +1,67 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package incoming
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// maildirSource processes the messages delivered to the new directory of a local maildir,
+// handled messages are removed or moved to the cur directory
+type maildirSource struct {
+	path string
+}
+
+// Process implements source
+func (m *maildirSource) Process(ctx context.Context, handle func(content []byte)) error {
+	newDir := filepath.Join(m.path, "new")
+	entries, err := os.ReadDir(newDir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		p := filepath.Join(newDir, entry.Name())
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if info.Size() > int64(setting.IncomingEmail.MaximumMessageSize) {
+			log.Warn("Incoming email %s is too large: %d bytes", p, info.Size())
+		} else {
+			content, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			handle(content)
+		}
+
+		if setting.IncomingEmail.DeleteHandledMessage {
+			if err := util.Remove(p); err != nil {
+				return err
+			}
+		} else if err := util.Rename(p, filepath.Join(m.path, "cur", entry.Name()+":2,S")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ package mailer
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
 	"html/template"
 	"mime"
@@ -25,6 +26,7 @@ import (
 	"code.gitea.io/gitea/modules/templates"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/translation"
+	"code.gitea.io/gitea/services/mailer/token"
 
 	"gopkg.in/gomail.v2"
 )
@@ -39,6 +41,8 @@ const (
 
 	mailRepoTransferNotify base.TplName = "notify/repo_transfer"
 
+	mailWebhookDeactivated base.TplName = "notify/webhook_deactivated"
+
 	// There's no actual limit for subject in RFC 5322
 	mailMaxSubjectRunes = 256
 )
@@ -61,7 +65,11 @@ func SendTestMail(email string) error {
 		// No mail service configured
 		return nil
 	}
-	return gomail.Send(Sender, NewMessage([]string{email}, "Gitea Test Email!", "Gitea Test Email!").ToMessage())
+	msg, err := NewMessage([]string{email}, "Gitea Test Email!", "Gitea Test Email!").ToMessage()
+	if err != nil {
+		return err
+	}
+	return gomail.Send(Sender, msg)
 }
 
 // sendUserMail sends a mail to the user
@@ -314,6 +322,12 @@ func composeIssueCommentMessages(ctx *mailCommentContext, lang string, recipient
 			msg.SetHeader(key, value)
 		}
 
+		if setting.IncomingEmail.Enabled {
+			for key, value := range generateIncomingEmailHeaders(ctx.Issue, recipient) {
+				msg.SetHeader(key, value)
+			}
+		}
+
 		msgs = append(msgs, msg)
 	}
 
@@ -346,7 +360,7 @@ func generateAdditionalHeaders(ctx *mailCommentContext, reason string, recipient
 		// https://datatracker.ietf.org/doc/html/rfc2369
 		"List-Archive": fmt.Sprintf("<%s>", repo.HTMLURL()),
 		//"List-Post": https://github.com/go-gitea/gitea/pull/13585
-		//"List-Unsubscribe": https://github.com/go-gitea/gitea/issues/10808, https://github.com/go-gitea/gitea/issues/13283
+		// "List-Unsubscribe" is added by generateIncomingEmailHeaders if incoming email is enabled
 
 		"X-Gitea-Reason":            reason,
 		"X-Gitea-Sender":            ctx.Doer.DisplayName(),
@@ -370,6 +384,26 @@ func generateAdditionalHeaders(ctx *mailCommentContext, reason string, recipient
 	}
 }
 
+// generateIncomingEmailHeaders creates the headers which allow the recipient to reply to the issue
+// or to unsubscribe from it by email
+func generateIncomingEmailHeaders(issue *models.Issue, recipient *models.User) map[string]string {
+	issueID := make([]byte, binary.MaxVarintLen64)
+	issueID = issueID[:binary.PutUvarint(issueID, uint64(issue.ID))]
+
+	replyAddress := incomingEmailAddress(token.CreateToken(token.ReplyHandlerType, recipient, issueID))
+	unsubscribeAddress := incomingEmailAddress(token.CreateToken(token.UnsubscribeHandlerType, recipient, issueID))
+
+	return map[string]string{
+		"Reply-To": replyAddress,
+		// https://datatracker.ietf.org/doc/html/rfc2369
+		"List-Unsubscribe": fmt.Sprintf("<mailto:%s>", unsubscribeAddress),
+	}
+}
+
+func incomingEmailAddress(handlerToken string) string {
+	return strings.Replace(setting.IncomingEmail.ReplyToAddress, setting.IncomingEmailTokenPlaceholder, handlerToken, 1)
+}
+
 func sanitizeSubject(subject string) string {
 	runes := []rune(strings.TrimSpace(subjectRemoveSpaces.ReplaceAllLiteralString(subject, " ")))
 	if len(runes) > mailMaxSubjectRunes {
@@ -391,19 +425,25 @@ func SendIssueAssignedMail(issue *models.Issue, doer *models.User, content strin
 		return err
 	}
 
+	ctx := &mailCommentContext{
+		Issue:      issue,
+		Doer:       doer,
+		ActionType: models.ActionType(0),
+		Content:    content,
+		Comment:    comment,
+	}
+	isReviewRequest := comment != nil && comment.Type == models.CommentTypeReviewRequest
+
 	langMap := make(map[string][]*models.User)
 	for _, user := range recipients {
+		if bufferMailDigest(ctx, user, isReviewRequest) {
+			continue
+		}
 		langMap[user.Language] = append(langMap[user.Language], user)
 	}
 
 	for lang, tos := range langMap {
-		msgs, err := composeIssueCommentMessages(&mailCommentContext{
-			Issue:      issue,
-			Doer:       doer,
-			ActionType: models.ActionType(0),
-			Content:    content,
-			Comment:    comment,
-		}, lang, tos, false, "issue assigned")
+		msgs, err := composeIssueCommentMessages(ctx, lang, tos, false, "issue assigned")
 		if err != nil {
 			return err
 		}
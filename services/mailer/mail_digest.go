@@ -0,0 +1,272 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/markup"
+	"code.gitea.io/gitea/modules/markup/markdown"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/templates"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/translation"
+)
+
+const mailNotifyDigest base.TplName = "notify/digest"
+
+// digestEvent is a single notification of a digest
+type digestEvent struct {
+	Doer        *models.User
+	ActionName  string
+	Body        template.HTML
+	Link        string
+	CreatedUnix timeutil.TimeStamp
+}
+
+// digestIssue groups the notifications of an issue or pull request
+type digestIssue struct {
+	Issue  *models.Issue
+	Events []*digestEvent
+}
+
+// digestRepo groups the issues and pull requests of a repository
+type digestRepo struct {
+	Repo   *models.Repository
+	Issues []*digestIssue
+}
+
+// bufferMailDigest stores the notification for the next digest of the user instead of mailing it immediately,
+// it returns false if the notification has to be mailed immediately
+func bufferMailDigest(ctx *mailCommentContext, user *models.User, direct bool) bool {
+	if user.EmailDigest() == models.EmailDigestImmediate || direct && user.EmailDigestImmediateMentions {
+		return false
+	}
+
+	entry := &models.MailDigestEntry{
+		UserID:     user.ID,
+		RepoID:     ctx.Issue.RepoID,
+		IssueID:    ctx.Issue.ID,
+		DoerID:     ctx.Doer.ID,
+		ActionType: ctx.ActionType,
+		Content:    ctx.Content,
+	}
+	if ctx.Comment != nil {
+		entry.CommentID = ctx.Comment.ID
+	}
+	if err := models.AddMailDigestEntry(entry); err != nil {
+		// rather mail it immediately than lose the notification
+		log.Error("AddMailDigestEntry: %v", err)
+		return false
+	}
+	return true
+}
+
+// SendMailDigests mails the buffered notifications to the users who chose the given digest.
+// The daily digest also flushes the notifications of users who went back to immediate delivery.
+func SendMailDigests(ctx context.Context, digest string) error {
+	if setting.MailService == nil {
+		// No mail service configured
+		return nil
+	}
+
+	preferences := []string{digest}
+	if digest == models.EmailDigestDaily {
+		preferences = append(preferences, models.EmailDigestImmediate)
+	}
+
+	userIDs, err := models.GetMailDigestUserIDs(preferences...)
+	if err != nil {
+		return fmt.Errorf("GetMailDigestUserIDs: %v", err)
+	}
+
+	for _, userID := range userIDs {
+		select {
+		case <-ctx.Done():
+			return models.ErrCancelledf("before sending the %s digest of user %d", digest, userID)
+		default:
+		}
+
+		if err := sendMailDigest(userID); err != nil {
+			log.Error("Unable to send the %s digest of user %d: %v", digest, userID, err)
+		}
+	}
+	return nil
+}
+
+// sendMailDigest mails the buffered notifications of the user and removes them afterwards
+func sendMailDigest(userID int64) error {
+	user, err := models.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	entries, err := models.GetMailDigestEntries(userID)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	maxID := entries[len(entries)-1].ID
+
+	if user.IsMailable() && user.EmailNotifications() != models.EmailNotificationsDisabled {
+		repos := groupMailDigestEntries(user, entries)
+		if len(repos) > 0 {
+			msg, err := composeMailDigest(user, repos)
+			if err != nil {
+				return err
+			}
+			SendAsync(msg)
+		}
+	}
+
+	return models.DeleteMailDigestEntries(userID, maxID)
+}
+
+// groupMailDigestEntries groups the entries by repository and issue in order of their first notification,
+// notifications the user is no longer allowed to see are dropped
+func groupMailDigestEntries(user *models.User, entries []*models.MailDigestEntry) []*digestRepo {
+	repos := make([]*digestRepo, 0, 5)
+	repoMap := make(map[int64]*digestRepo)
+	issueMap := make(map[int64]*digestIssue)
+	doers := make(map[int64]*models.User)
+
+	for _, entry := range entries {
+		di, ok := issueMap[entry.IssueID]
+		if !ok {
+			issue, err := models.GetIssueByID(entry.IssueID)
+			if err != nil {
+				if !models.IsErrIssueNotExist(err) {
+					log.Error("GetIssueByID(%d): %v", entry.IssueID, err)
+				}
+				issueMap[entry.IssueID] = nil
+				continue
+			}
+			if err := issue.LoadRepo(); err != nil {
+				log.Error("LoadRepo(%d): %v", issue.RepoID, err)
+				issueMap[entry.IssueID] = nil
+				continue
+			}
+			checkUnit := models.UnitTypeIssues
+			if issue.IsPull {
+				checkUnit = models.UnitTypePullRequests
+			}
+			if !issue.Repo.CheckUnitUser(user, checkUnit) {
+				issueMap[entry.IssueID] = nil
+				continue
+			}
+
+			di = &digestIssue{Issue: issue}
+			issueMap[entry.IssueID] = di
+
+			dr, ok := repoMap[issue.RepoID]
+			if !ok {
+				dr = &digestRepo{Repo: issue.Repo}
+				repoMap[issue.RepoID] = dr
+				repos = append(repos, dr)
+			}
+			dr.Issues = append(dr.Issues, di)
+		}
+		if di == nil {
+			continue
+		}
+
+		doer, ok := doers[entry.DoerID]
+		if !ok {
+			var err error
+			if doer, err = models.GetUserByID(entry.DoerID); err != nil {
+				doer = models.NewGhostUser()
+			}
+			doers[entry.DoerID] = doer
+		}
+
+		di.Events = append(di.Events, composeDigestEvent(di.Issue, doer, entry))
+	}
+	return repos
+}
+
+// composeDigestEvent renders a single notification of the digest
+func composeDigestEvent(issue *models.Issue, doer *models.User, entry *models.MailDigestEntry) *digestEvent {
+	event := &digestEvent{
+		Doer:        doer,
+		Link:        issue.HTMLURL(),
+		CreatedUnix: entry.CreatedUnix,
+	}
+
+	commentType := models.CommentTypeComment
+	reviewType := models.ReviewTypeComment
+	if entry.CommentID != 0 {
+		comment, err := models.GetCommentByID(entry.CommentID)
+		if err == nil {
+			commentType = comment.Type
+			event.Link = issue.HTMLURL() + "#" + comment.HashTag()
+			if comment.Type == models.CommentTypeReview {
+				if err := comment.LoadReview(); err == nil && comment.Review != nil {
+					reviewType = comment.Review.Type
+				}
+			}
+		} else if !models.IsErrCommentNotExist(err) {
+			log.Error("GetCommentByID(%d): %v", entry.CommentID, err)
+		}
+	}
+
+	if commentType == models.CommentTypeReviewRequest {
+		event.ActionName = "review_request"
+	} else {
+		_, event.ActionName, _ = actionToTemplate(issue, entry.ActionType, commentType, reviewType)
+	}
+
+	body, err := markdown.RenderString(&markup.RenderContext{
+		URLPrefix: issue.Repo.HTMLURL(),
+		Metas:     issue.Repo.ComposeMetas(),
+	}, entry.Content)
+	if err != nil {
+		log.Error("RenderString: %v", err)
+	} else {
+		event.Body = template.HTML(body)
+	}
+	return event
+}
+
+// composeMailDigest composes the digest mail of the grouped notifications
+func composeMailDigest(user *models.User, repos []*digestRepo) (*Message, error) {
+	locale := translation.NewLocale(user.Language)
+
+	count := 0
+	for _, repo := range repos {
+		for _, issue := range repo.Issues {
+			count += len(issue.Events)
+		}
+	}
+
+	subject := locale.Tr("mail.digest.subject", setting.AppName, count)
+
+	data := map[string]interface{}{
+		"Subject":  subject,
+		"Repos":    repos,
+		"Link":     setting.AppURL + "notifications",
+		"Language": locale.Language(),
+		// helper
+		"i18n":     locale,
+		"Str2html": templates.Str2html,
+		"TrN":      templates.TrN,
+	}
+
+	var content bytes.Buffer
+	if err := bodyTemplates.ExecuteTemplate(&content, string(mailNotifyDigest), data); err != nil {
+		return nil, err
+	}
+
+	msg := NewMessage([]string{user.Email}, subject, content.String())
+	msg.Info = fmt.Sprintf("UID: %d, %d notifications digest", user.ID, count)
+	return msg, nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mailer
+
+import (
+	"html/template"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const digestTpl = `{{range .Repos}}[{{.Repo.FullName}}]{{range .Issues}} #{{.Issue.Index}}:{{range .Events}} {{.Doer.Name}}/{{.ActionName}}/{{.Body}}{{end}}{{end}}{{end}}`
+
+func TestBufferMailDigest(t *testing.T) {
+	doer, _, issue, comment := prepareMailerTest(t)
+	ctx := &mailCommentContext{Issue: issue, Doer: doer, ActionType: models.ActionCommentIssue, Content: "test", Comment: comment}
+
+	immediate := &models.User{ID: 4, EmailNotificationsDigest: models.EmailDigestImmediate}
+	assert.False(t, bufferMailDigest(ctx, immediate, false))
+
+	daily := &models.User{ID: 4, EmailNotificationsDigest: models.EmailDigestDaily, EmailDigestImmediateMentions: true}
+	assert.False(t, bufferMailDigest(ctx, daily, true))
+	assert.True(t, bufferMailDigest(ctx, daily, false))
+
+	daily.EmailDigestImmediateMentions = false
+	assert.True(t, bufferMailDigest(ctx, daily, true))
+
+	entries, err := models.GetMailDigestEntries(4)
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 2) {
+		assert.EqualValues(t, issue.ID, entries[0].IssueID)
+		assert.EqualValues(t, comment.ID, entries[0].CommentID)
+		assert.EqualValues(t, doer.ID, entries[0].DoerID)
+		assert.Equal(t, "test", entries[0].Content)
+	}
+}
+
+func TestSendMailDigest(t *testing.T) {
+	doer, _, issue, comment := prepareMailerTest(t)
+	InitMailRender(nil, template.Must(template.New("notify/digest").Parse(digestTpl)))
+
+	user := db.AssertExistsAndLoadBean(t, &models.User{ID: 4}).(*models.User)
+	assert.NoError(t, user.SetEmailDigest(models.EmailDigestWeekly, false))
+
+	ctx := &mailCommentContext{Issue: issue, Doer: doer, ActionType: models.ActionCommentIssue, Content: "first", Comment: comment}
+	assert.True(t, bufferMailDigest(ctx, user, false))
+	ctx = &mailCommentContext{Issue: issue, Doer: doer, ActionType: models.ActionCloseIssue}
+	assert.True(t, bufferMailDigest(ctx, user, false))
+
+	userIDs, err := models.GetMailDigestUserIDs(models.EmailDigestDaily, models.EmailDigestImmediate)
+	assert.NoError(t, err)
+	assert.NotContains(t, userIDs, user.ID)
+	userIDs, err = models.GetMailDigestUserIDs(models.EmailDigestWeekly)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{user.ID}, userIDs)
+
+	entries, err := models.GetMailDigestEntries(user.ID)
+	assert.NoError(t, err)
+	repos := groupMailDigestEntries(user, entries)
+	if assert.Len(t, repos, 1) {
+		assert.Len(t, repos[0].Issues, 1)
+	}
+
+	msg, err := composeMailDigest(user, repos)
+	assert.NoError(t, err)
+	gomailMsg, err := msg.ToMessage()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{user.Email}, gomailMsg.GetHeader("To"))
+	assert.Equal(t, "[user2/repo1] #1: user2/comment/<p>first</p>\n user2/close/", msg.Body)
+
+	assert.NoError(t, models.DeleteMailDigestEntries(user.ID, entries[len(entries)-1].ID))
+	db.AssertNotExistsBean(t, &models.MailDigestEntry{UserID: user.ID})
+
+	t.Run("NoPermission", func(t *testing.T) {
+		// user 5 can't read the private repository 2
+		privateIssue := db.AssertExistsAndLoadBean(t, &models.Issue{ID: 4}).(*models.Issue)
+		assert.NoError(t, privateIssue.LoadRepo())
+		other := db.AssertExistsAndLoadBean(t, &models.User{ID: 5}).(*models.User)
+		other.EmailNotificationsDigest = models.EmailDigestDaily
+
+		ctx := &mailCommentContext{Issue: privateIssue, Doer: doer, ActionType: models.ActionCreateIssue, Content: "secret"}
+		assert.True(t, bufferMailDigest(ctx, other, false))
+
+		entries, err := models.GetMailDigestEntries(other.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, groupMailDigestEntries(other, entries))
+	})
+}
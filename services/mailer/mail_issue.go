@@ -141,6 +141,11 @@ func mailIssueCommentBatch(ctx *mailCommentContext, users []*models.User, visite
 			continue
 		}
 
+		// users who chose a digest get the notification with their next digest
+		if bufferMailDigest(ctx, user, fromMention) {
+			continue
+		}
+
 		langMap[user.Language] = append(langMap[user.Language], user)
 	}
 
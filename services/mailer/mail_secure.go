@@ -0,0 +1,296 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mailer
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/base64"
+	"fmt"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/keybase/go-crypto/openpgp"
+	"github.com/keybase/go-crypto/openpgp/armor"
+	"github.com/keybase/go-crypto/openpgp/packet"
+)
+
+var (
+	smimeKey *smimeSigner
+	pgpKey   *openpgp.Entity
+)
+
+var pgpConfig = &packet.Config{DefaultHash: crypto.SHA256}
+
+// initSigning loads the key used to sign outgoing emails
+func initSigning() error {
+	switch setting.MailService.SigningMethod {
+	case "smime":
+		signer, err := loadSMIMESigner(setting.MailService.SMIMECertFile, setting.MailService.SMIMEKeyFile)
+		if err != nil {
+			return fmt.Errorf("unable to load the S/MIME certificate: %w", err)
+		}
+		smimeKey = signer
+	case "pgp":
+		entity, err := loadPGPSigner(setting.MailService.PGPKeyFile, setting.MailService.PGPKeyPassphrase)
+		if err != nil {
+			return fmt.Errorf("unable to load the PGP key: %w", err)
+		}
+		pgpKey = entity
+	}
+	return nil
+}
+
+// loadPGPSigner reads the armored private key and decrypts it with the passphrase
+func loadPGPSigner(keyFile, passphrase string) (*openpgp.Entity, error) {
+	f, err := os.Open(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entities, err := openpgp.ReadArmoredKeyRing(f)
+	if err != nil {
+		return nil, err
+	}
+	if len(entities) == 0 || entities[0].PrivateKey == nil {
+		return nil, fmt.Errorf("%s contains no private key", keyFile)
+	}
+
+	entity := entities[0]
+	if entity.PrivateKey.Encrypted {
+		if err := entity.PrivateKey.Decrypt([]byte(passphrase)); err != nil {
+			return nil, err
+		}
+	}
+	for _, subkey := range entity.Subkeys {
+		if subkey.PrivateKey != nil && subkey.PrivateKey.Encrypted {
+			if err := subkey.PrivateKey.Decrypt([]byte(passphrase)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return entity, nil
+}
+
+// encryptionKeys returns the keys of the recipient if the message has a single recipient who opted in to encrypted emails
+func (m *Message) encryptionKeys() ([]*openpgp.Entity, error) {
+	if !setting.MailService.EncryptionEnabled || len(m.To) != 1 {
+		return nil, nil
+	}
+	address := m.To[0]
+	if parsed, err := mail.ParseAddress(address); err == nil {
+		address = parsed.Address
+	}
+
+	user, err := models.GetUserByEmail(address)
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !user.EncryptEmailNotifications {
+		return nil, nil
+	}
+
+	keys, err := models.ListGPGEncryptionKeys(user.ID, address)
+	if err != nil {
+		return nil, err
+	}
+	entities := make([]*openpgp.Entity, 0, len(keys))
+	for _, key := range keys {
+		entity, err := models.GPGKeyToEntity(key)
+		if err != nil {
+			log.Warn("Unable to load the GPG key %s of %s: %v", key.KeyID, user.Name, err)
+			continue
+		}
+		entities = append(entities, entity)
+	}
+	if len(entities) == 0 {
+		log.Warn("%s wants encrypted emails but has no usable GPG key for %s", user.Name, address)
+	}
+	return entities, nil
+}
+
+// bodyEntity returns the MIME entity of the plain text and HTML bodies with canonical line endings
+func bodyEntity(plainBody, htmlBody string) []byte {
+	var buf bytes.Buffer
+	if htmlBody == "" {
+		writeQuotedPrintablePart(&buf, "text/plain; charset=UTF-8", plainBody)
+		return buf.Bytes()
+	}
+
+	mw := multipart.NewWriter(&buf)
+	buf.WriteString("Content-Type: multipart/alternative;\r\n boundary=\"" + mw.Boundary() + "\"\r\n\r\n")
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=UTF-8", plainBody},
+		{"text/html; charset=UTF-8", htmlBody},
+	} {
+		buf.WriteString("--" + mw.Boundary() + "\r\n")
+		writeQuotedPrintablePart(&buf, part.contentType, part.body)
+		buf.WriteString("\r\n")
+	}
+	buf.WriteString("--" + mw.Boundary() + "--")
+	return buf.Bytes()
+}
+
+func writeQuotedPrintablePart(buf *bytes.Buffer, contentType, body string) {
+	buf.WriteString("Content-Type: " + contentType + "\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\n")
+	qw := quotedprintable.NewWriter(buf)
+	_, _ = qw.Write([]byte(body))
+	_ = qw.Close()
+}
+
+// signEntity wraps the entity into a multipart/signed entity (RFC 1847) and returns its content type and body
+func signEntity(entity []byte) (string, []byte, error) {
+	var protocol, micalg, signaturePart string
+	switch {
+	case smimeKey != nil:
+		signature, err := smimeKey.Sign(entity, time.Now())
+		if err != nil {
+			return "", nil, err
+		}
+		protocol, micalg = "application/pkcs7-signature", "sha-256"
+		signaturePart = "Content-Type: application/pkcs7-signature; name=\"smime.p7s\"\r\n" +
+			"Content-Transfer-Encoding: base64\r\n" +
+			"Content-Disposition: attachment; filename=\"smime.p7s\"\r\n\r\n" +
+			wrapLines(base64.StdEncoding.EncodeToString(signature), 76)
+	case pgpKey != nil:
+		var signature bytes.Buffer
+		if err := openpgp.ArmoredDetachSign(&signature, pgpKey, bytes.NewReader(entity), pgpConfig); err != nil {
+			return "", nil, err
+		}
+		protocol, micalg = "application/pgp-signature", "pgp-sha256"
+		signaturePart = "Content-Type: application/pgp-signature; name=\"signature.asc\"\r\n" +
+			"Content-Description: OpenPGP digital signature\r\n" +
+			"Content-Disposition: attachment; filename=\"signature.asc\"\r\n\r\n" +
+			canonicalLineEndings(signature.String())
+	default:
+		return "", nil, fmt.Errorf("no signing key loaded")
+	}
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	buf.WriteString("--" + mw.Boundary() + "\r\n")
+	buf.Write(entity)
+	buf.WriteString("\r\n--" + mw.Boundary() + "\r\n")
+	buf.WriteString(signaturePart)
+	buf.WriteString("\r\n--" + mw.Boundary() + "--\r\n")
+
+	contentType := fmt.Sprintf("multipart/signed; protocol=\"%s\"; micalg=%s; boundary=\"%s\"", protocol, micalg, mw.Boundary())
+	return contentType, buf.Bytes(), nil
+}
+
+// encryptEntity wraps the entity into a multipart/encrypted entity (RFC 3156) and returns its content type and body
+func encryptEntity(entity []byte, to []*openpgp.Entity) (string, []byte, error) {
+	var encrypted bytes.Buffer
+	aw, err := armor.Encode(&encrypted, "PGP MESSAGE", nil)
+	if err != nil {
+		return "", nil, err
+	}
+	pw, err := openpgp.Encrypt(aw, to, nil, nil, pgpConfig)
+	if err != nil {
+		return "", nil, err
+	}
+	if _, err := pw.Write(entity); err != nil {
+		return "", nil, err
+	}
+	if err := pw.Close(); err != nil {
+		return "", nil, err
+	}
+	if err := aw.Close(); err != nil {
+		return "", nil, err
+	}
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	parts := []struct {
+		header textproto.MIMEHeader
+		body   string
+	}{
+		{
+			header: textproto.MIMEHeader{
+				"Content-Type":        {"application/pgp-encrypted"},
+				"Content-Description": {"PGP/MIME version identification"},
+			},
+			body: "Version: 1\r\n",
+		},
+		{
+			header: textproto.MIMEHeader{
+				"Content-Type":        {"application/octet-stream; name=\"encrypted.asc\""},
+				"Content-Description": {"OpenPGP encrypted message"},
+				"Content-Disposition": {"inline; filename=\"encrypted.asc\""},
+			},
+			body: canonicalLineEndings(encrypted.String()) + "\r\n",
+		},
+	}
+	for _, part := range parts {
+		w, err := mw.CreatePart(part.header)
+		if err != nil {
+			return "", nil, err
+		}
+		if _, err := w.Write([]byte(part.body)); err != nil {
+			return "", nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return "", nil, err
+	}
+
+	contentType := fmt.Sprintf("multipart/encrypted; protocol=\"application/pgp-encrypted\"; boundary=\"%s\"", mw.Boundary())
+	return contentType, buf.Bytes(), nil
+}
+
+// secureBody signs and encrypts the bodies as configured and returns the content type and body of the message
+func (m *Message) secureBody(plainBody, htmlBody string) (contentType string, body []byte, secured bool, err error) {
+	to, err := m.encryptionKeys()
+	if err != nil {
+		return "", nil, false, err
+	}
+	if smimeKey == nil && pgpKey == nil && len(to) == 0 {
+		return "", nil, false, nil
+	}
+
+	entity := bodyEntity(plainBody, htmlBody)
+	if smimeKey != nil || pgpKey != nil {
+		contentType, body, err = signEntity(entity)
+		if err != nil {
+			return "", nil, false, fmt.Errorf("unable to sign the message: %w", err)
+		}
+		entity = append([]byte("Content-Type: "+contentType+"\r\n\r\n"), body...)
+	}
+	if len(to) > 0 {
+		contentType, body, err = encryptEntity(entity, to)
+		if err != nil {
+			return "", nil, false, fmt.Errorf("unable to encrypt the message: %w", err)
+		}
+	}
+	return contentType, body, true, nil
+}
+
+func canonicalLineEndings(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\n", "\r\n")
+}
+
+func wrapLines(s string, width int) string {
+	var sb strings.Builder
+	for len(s) > width {
+		sb.WriteString(s[:width])
+		sb.WriteString("\r\n")
+		s = s[width:]
+	}
+	sb.WriteString(s)
+	return sb.String()
+}
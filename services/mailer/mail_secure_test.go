@@ -0,0 +1,232 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mailer
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"mime"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/keybase/go-crypto/openpgp"
+	"github.com/keybase/go-crypto/openpgp/armor"
+	"github.com/stretchr/testify/assert"
+)
+
+// splitMultipart returns the raw parts of a multipart body
+func splitMultipart(t *testing.T, contentType string, body []byte) [][]byte {
+	_, params, err := mime.ParseMediaType(contentType)
+	assert.NoError(t, err)
+	delimiter := []byte("--" + params["boundary"])
+
+	var parts [][]byte
+	for _, part := range bytes.Split(body, delimiter)[1:] {
+		if bytes.HasPrefix(part, []byte("--")) {
+			break
+		}
+		part = bytes.TrimPrefix(part, []byte("\r\n"))
+		parts = append(parts, bytes.TrimSuffix(part, []byte("\r\n")))
+	}
+	return parts
+}
+
+// readSecuredMessage renders the message and returns its content type and body
+func readSecuredMessage(t *testing.T, msg *Message) (string, []byte) {
+	gomailMsg, err := msg.ToMessage()
+	assert.NoError(t, err)
+	var buf bytes.Buffer
+	_, err = gomailMsg.WriteTo(&buf)
+	assert.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "8bit", parsed.Header.Get("Content-Transfer-Encoding"))
+	body, err := io.ReadAll(parsed.Body)
+	assert.NoError(t, err)
+	return parsed.Header.Get("Content-Type"), body
+}
+
+func writeSMIMECertificate(t *testing.T, dir string, key crypto.Signer) {
+	template := &x509.Certificate{
+		SerialNumber:   big.NewInt(42),
+		Subject:        pkix.Name{CommonName: "Gitea"},
+		EmailAddresses: []string{"gitea@localhost"},
+		NotBefore:      time.Now().Add(-time.Hour),
+		NotAfter:       time.Now().Add(time.Hour),
+		KeyUsage:       x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:    []x509.ExtKeyUsage{x509.ExtKeyUsageEmailProtection},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	assert.NoError(t, err)
+	keyDer, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "cert.pem"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "key.pem"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer}), 0o600))
+}
+
+func TestSMIMESignedMessage(t *testing.T) {
+	prepareMailerTest(t)
+	defer func() {
+		smimeKey = nil
+	}()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	for _, key := range []crypto.Signer{rsaKey, ecdsaKey} {
+		dir := t.TempDir()
+		writeSMIMECertificate(t, dir, key)
+		smimeKey, err = loadSMIMESigner(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"))
+		assert.NoError(t, err)
+
+		contentType, body := readSecuredMessage(t, NewMessage([]string{"nobody@example.com"}, "Signed", "<p>Hello <b>world</b></p>"))
+		assert.True(t, strings.HasPrefix(contentType, "multipart/signed;"))
+		assert.Contains(t, contentType, `protocol="application/pkcs7-signature"`)
+		assert.Contains(t, contentType, "micalg=sha-256")
+
+		parts := splitMultipart(t, contentType, body)
+		if !assert.Len(t, parts, 2) {
+			continue
+		}
+		assert.True(t, bytes.HasPrefix(parts[0], []byte("Content-Type: multipart/alternative;")))
+
+		signature, err := base64.StdEncoding.DecodeString(string(parts[1][bytes.Index(parts[1], []byte("\r\n\r\n"))+4:]))
+		assert.NoError(t, err)
+
+		var contentInfo cmsContentInfo
+		_, err = asn1.Unmarshal(signature, &contentInfo)
+		assert.NoError(t, err)
+		assert.True(t, contentInfo.ContentType.Equal(oidSignedData))
+		var signedData cmsSignedData
+		_, err = asn1.Unmarshal(contentInfo.Content.Bytes, &signedData)
+		assert.NoError(t, err)
+		cert, err := x509.ParseCertificate(signedData.Certificates.Bytes)
+		assert.NoError(t, err)
+		if !assert.Len(t, signedData.SignerInfos, 1) {
+			continue
+		}
+		signerInfo := signedData.SignerInfos[0]
+		assert.EqualValues(t, 42, signerInfo.SID.SerialNumber.Int64())
+
+		// the message digest attribute must match the signed part
+		var attributes []cmsAttribute
+		_, err = asn1.UnmarshalWithParams(signerInfo.SignedAttributes.FullBytes, &attributes, "set,tag:0")
+		assert.NoError(t, err)
+		digest := sha256.Sum256(parts[0])
+		found := false
+		for _, attribute := range attributes {
+			if attribute.Type.Equal(oidAttributeMessageDigest) {
+				var value []byte
+				_, err = asn1.Unmarshal(attribute.Values[0].FullBytes, &value)
+				assert.NoError(t, err)
+				assert.Equal(t, digest[:], value)
+				found = true
+			}
+		}
+		assert.True(t, found)
+
+		// the signature covers the attributes encoded as SET OF
+		set, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: signerInfo.SignedAttributes.Bytes})
+		assert.NoError(t, err)
+		signatureAlgorithm := x509.SHA256WithRSA
+		if _, ok := key.(*ecdsa.PrivateKey); ok {
+			signatureAlgorithm = x509.ECDSAWithSHA256
+		}
+		assert.NoError(t, cert.CheckSignature(signatureAlgorithm, set, signerInfo.Signature))
+	}
+}
+
+func TestPGPSignedAndEncryptedMessage(t *testing.T) {
+	prepareMailerTest(t)
+	defer func() {
+		pgpKey = nil
+	}()
+
+	var err error
+	pgpKey, err = openpgp.NewEntity("Gitea", "", "gitea@localhost", nil)
+	assert.NoError(t, err)
+	recipient, err := openpgp.NewEntity("User", "", "user@example.com", nil)
+	assert.NoError(t, err)
+
+	t.Run("Signed", func(t *testing.T) {
+		contentType, body := readSecuredMessage(t, NewMessage([]string{"nobody@example.com"}, "Signed", "Hello world"))
+		assert.True(t, strings.HasPrefix(contentType, "multipart/signed;"))
+		assert.Contains(t, contentType, "micalg=pgp-sha256")
+
+		parts := splitMultipart(t, contentType, body)
+		if assert.Len(t, parts, 2) {
+			signature := parts[1][bytes.Index(parts[1], []byte("\r\n\r\n"))+4:]
+			signer, err := openpgp.CheckArmoredDetachedSignature(openpgp.EntityList{pgpKey}, bytes.NewReader(parts[0]), bytes.NewReader(signature))
+			assert.NoError(t, err)
+			assert.Equal(t, pgpKey.PrimaryKey.KeyId, signer.PrimaryKey.KeyId)
+		}
+	})
+
+	t.Run("Encrypted", func(t *testing.T) {
+		entity := bodyEntity("Hello world", "")
+		contentType, body, err := encryptEntity(entity, []*openpgp.Entity{recipient})
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(contentType, "multipart/encrypted;"))
+
+		parts := splitMultipart(t, contentType, body)
+		if assert.Len(t, parts, 2) {
+			assert.Contains(t, string(parts[0]), "Version: 1")
+			armored := parts[1][bytes.Index(parts[1], []byte("\r\n\r\n"))+4:]
+			block, err := armor.Decode(bytes.NewReader(armored))
+			assert.NoError(t, err)
+			md, err := openpgp.ReadMessage(block.Body, openpgp.EntityList{recipient}, nil, nil)
+			assert.NoError(t, err)
+			plaintext, err := io.ReadAll(md.UnverifiedBody)
+			assert.NoError(t, err)
+			assert.Equal(t, entity, plaintext)
+		}
+	})
+
+	t.Run("Recipients", func(t *testing.T) {
+		setting.MailService.EncryptionEnabled = true
+		defer func() {
+			setting.MailService.EncryptionEnabled = false
+		}()
+		user := db.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+
+		keys, err := NewMessage([]string{user.Email}, "Plain", "Hello").encryptionKeys()
+		assert.NoError(t, err)
+		assert.Empty(t, keys)
+
+		// opted in without a matching key
+		assert.NoError(t, user.SetEncryptEmailNotifications(true))
+		keys, err = NewMessage([]string{user.Email}, "Plain", "Hello").encryptionKeys()
+		assert.NoError(t, err)
+		assert.Empty(t, keys)
+
+		keys, err = NewMessage([]string{user.Email, "other@example.com"}, "Plain", "Hello").encryptionKeys()
+		assert.NoError(t, err)
+		assert.Empty(t, keys)
+	})
+}
@@ -7,12 +7,14 @@ package mailer
 import (
 	"bytes"
 	"html/template"
+	"strings"
 	"testing"
 	texttmpl "text/template"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/services/mailer/token"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -69,7 +71,8 @@ func TestComposeIssueCommentMessage(t *testing.T) {
 		Content: "test body", Comment: comment}, "en-US", recipients, false, "issue comment")
 	assert.NoError(t, err)
 	assert.Len(t, msgs, 2)
-	gomailMsg := msgs[0].ToMessage()
+	gomailMsg, err := msgs[0].ToMessage()
+	assert.NoError(t, err)
 	mailto := gomailMsg.GetHeader("To")
 	subject := gomailMsg.GetHeader("Subject")
 	messageID := gomailMsg.GetHeader("Message-ID")
@@ -97,7 +100,8 @@ func TestComposeIssueMessage(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, msgs, 2)
 
-	gomailMsg := msgs[0].ToMessage()
+	gomailMsg, err := msgs[0].ToMessage()
+	assert.NoError(t, err)
 	mailto := gomailMsg.GetHeader("To")
 	subject := gomailMsg.GetHeader("Subject")
 	messageID := gomailMsg.GetHeader("Message-ID")
@@ -128,9 +132,11 @@ func TestTemplateSelection(t *testing.T) {
 	InitMailRender(stpl, btpl)
 
 	expect := func(t *testing.T, msg *Message, expSubject, expBody string) {
-		subject := msg.ToMessage().GetHeader("Subject")
+		gomailMsg, err := msg.ToMessage()
+		assert.NoError(t, err)
+		subject := gomailMsg.GetHeader("Subject")
 		msgbuf := new(bytes.Buffer)
-		_, _ = msg.ToMessage().WriteTo(msgbuf)
+		_, _ = gomailMsg.WriteTo(msgbuf)
 		wholemsg := msgbuf.String()
 		assert.Equal(t, []string{expSubject}, subject)
 		assert.Contains(t, wholemsg, expBody)
@@ -170,9 +176,11 @@ func TestTemplateServices(t *testing.T) {
 		msg := testComposeIssueCommentMessage(t, &mailCommentContext{Issue: issue, Doer: doer, ActionType: actionType,
 			Content: "test body", Comment: comment}, recipients, fromMention, "TestTemplateServices")
 
-		subject := msg.ToMessage().GetHeader("Subject")
+		gomailMsg, err := msg.ToMessage()
+		assert.NoError(t, err)
+		subject := gomailMsg.GetHeader("Subject")
 		msgbuf := new(bytes.Buffer)
-		_, _ = msg.ToMessage().WriteTo(msgbuf)
+		_, _ = gomailMsg.WriteTo(msgbuf)
 		wholemsg := msgbuf.String()
 
 		assert.Equal(t, []string{expSubject}, subject)
@@ -233,3 +241,25 @@ func TestGenerateAdditionalHeaders(t *testing.T) {
 		}
 	}
 }
+
+func TestGenerateIncomingEmailHeaders(t *testing.T) {
+	_, _, issue, _ := prepareMailerTest(t)
+
+	oldReplyToAddress := setting.IncomingEmail.ReplyToAddress
+	setting.IncomingEmail.ReplyToAddress = "incoming+" + setting.IncomingEmailTokenPlaceholder + "@localhost"
+	defer func() {
+		setting.IncomingEmail.ReplyToAddress = oldReplyToAddress
+	}()
+
+	recipient := db.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	headers := generateIncomingEmailHeaders(issue, recipient)
+
+	assert.Regexp(t, `^incoming\+[a-z2-7]+@localhost$`, headers["Reply-To"])
+	assert.Regexp(t, `^<mailto:incoming\+[a-z2-7]+@localhost>$`, headers["List-Unsubscribe"])
+
+	replyToken := strings.TrimSuffix(strings.TrimPrefix(headers["Reply-To"], "incoming+"), "@localhost")
+	handlerType, user, _, err := token.ExtractToken(replyToken)
+	assert.NoError(t, err)
+	assert.Equal(t, token.ReplyHandlerType, handlerType)
+	assert.Equal(t, recipient.ID, user.ID)
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/templates"
+	"code.gitea.io/gitea/modules/translation"
+)
+
+// SendWebhookDeactivatedMail notifies the owners of a webhook that it has been deactivated
+// because of too many failed deliveries. host is the host of the webhook URL and link the
+// settings page of the webhook.
+func SendWebhookDeactivatedMail(w *models.Webhook, host, link string, failures int, recipients []*models.User) error {
+	if setting.MailService == nil {
+		// No mail service configured
+		return nil
+	}
+
+	langMap := make(map[string][]string)
+	for _, user := range recipients {
+		if !user.IsActive || user.ProhibitLogin || user.Email == "" {
+			continue
+		}
+		langMap[user.Language] = append(langMap[user.Language], user.Email)
+	}
+
+	for lang, tos := range langMap {
+		if err := sendWebhookDeactivatedMailPerLang(lang, w, host, link, failures, tos); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sendWebhookDeactivatedMailPerLang(lang string, w *models.Webhook, host, link string, failures int, emails []string) error {
+	var (
+		locale  = translation.NewLocale(lang)
+		content bytes.Buffer
+	)
+
+	subject := locale.Tr("mail.webhook.deactivated.subject", host)
+	data := map[string]interface{}{
+		"Subject":  subject,
+		"Host":     host,
+		"Failures": failures,
+		"Link":     link,
+		"Language": locale.Language(),
+		// helper
+		"i18n":     locale,
+		"Str2html": templates.Str2html,
+		"TrN":      templates.TrN,
+	}
+
+	if err := bodyTemplates.ExecuteTemplate(&content, string(mailWebhookDeactivated), data); err != nil {
+		return err
+	}
+
+	msg := NewMessage(emails, subject, content.String())
+	msg.Info = fmt.Sprintf("Webhook: %d, deactivated after failed deliveries", w.ID)
+
+	SendAsync(msg)
+	return nil
+}
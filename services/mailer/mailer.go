@@ -41,8 +41,8 @@ type Message struct {
 	Headers         map[string][]string
 }
 
-// ToMessage converts a Message to gomail.Message
-func (m *Message) ToMessage() *gomail.Message {
+// ToMessage converts a Message to gomail.Message, the body is signed and encrypted if configured
+func (m *Message) ToMessage() (*gomail.Message, error) {
 	msg := gomail.NewMessage()
 	msg.SetAddressHeader("From", m.FromAddress, m.FromDisplayName)
 	msg.SetHeader("To", m.To...)
@@ -58,17 +58,29 @@ func (m *Message) ToMessage() *gomail.Message {
 	msg.SetDateHeader("Date", m.Date)
 	msg.SetHeader("X-Auto-Response-Suppress", "All")
 
+	htmlBody := m.Body
 	plainBody, err := html2text.FromString(m.Body)
 	if err != nil || setting.MailService.SendAsPlainText {
 		if strings.Contains(base.TruncateString(m.Body, 100), "<html>") {
 			log.Warn("Mail contains HTML but configured to send as plain text.")
 		}
-		msg.SetBody("text/plain", plainBody)
-	} else {
-		msg.SetBody("text/plain", plainBody)
-		msg.AddAlternative("text/html", m.Body)
+		htmlBody = ""
+	}
+
+	contentType, body, secured, err := m.secureBody(plainBody, htmlBody)
+	if err != nil {
+		return nil, err
+	}
+	if secured {
+		msg.SetBody(contentType, string(body), gomail.SetPartEncoding(gomail.Unencoded))
+		return msg, nil
 	}
-	return msg
+
+	msg.SetBody("text/plain", plainBody)
+	if htmlBody != "" {
+		msg.AddAlternative("text/html", htmlBody)
+	}
+	return msg, nil
 }
 
 // SetHeader adds additional headers to a message
@@ -310,6 +322,10 @@ func NewContext() {
 		return
 	}
 
+	if err := initSigning(); err != nil {
+		log.Fatal("Unable to initialize the mail signing: %v", err)
+	}
+
 	switch setting.MailService.MailerType {
 	case "smtp":
 		Sender = &smtpSender{}
@@ -322,7 +338,11 @@ func NewContext() {
 	mailQueue = queue.CreateQueue("mail", func(data ...queue.Data) {
 		for _, datum := range data {
 			msg := datum.(*Message)
-			gomailMsg := msg.ToMessage()
+			gomailMsg, err := msg.ToMessage()
+			if err != nil {
+				log.Error("Failed to compose email %s: %s - %v", msg.To, msg.Info, err)
+				continue
+			}
 			log.Trace("New e-mail sending request %s: %s", gomailMsg.GetHeader("To"), msg.Info)
 			if err := gomail.Send(Sender, gomailMsg); err != nil {
 				log.Error("Failed to send emails %s: %s - %v", gomailMsg.GetHeader("To"), msg.Info, err)
@@ -0,0 +1,190 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mailer
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+)
+
+// The object identifiers needed for a detached CMS signature (RFC 5652)
+var (
+	oidData                   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidSignedData             = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidAttributeContentType   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidAttributeMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidAttributeSigningTime   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 5}
+	oidDigestAlgorithmSHA256  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidEncryptionAlgorithmRSA = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidSignatureECDSASHA256   = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+)
+
+type cmsContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue
+}
+
+type cmsEncapsulatedContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+}
+
+type cmsSignedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	ContentInfo      cmsEncapsulatedContentInfo
+	Certificates     asn1.RawValue
+	SignerInfos      []cmsSignerInfo `asn1:"set"`
+}
+
+type cmsIssuerAndSerialNumber struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+type cmsSignerInfo struct {
+	Version            int
+	SID                cmsIssuerAndSerialNumber
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttributes   asn1.RawValue
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+}
+
+type cmsAttribute struct {
+	Type   asn1.ObjectIdentifier
+	Values []asn1.RawValue `asn1:"set"`
+}
+
+// smimeSigner creates detached S/MIME signatures with a certificate and its private key
+type smimeSigner struct {
+	key   crypto.Signer
+	chain []*x509.Certificate
+}
+
+// loadSMIMESigner loads the PEM encoded certificate chain and private key
+func loadSMIMESigner(certFile, keyFile string) (*smimeSigner, error) {
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := pair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported private key type")
+	}
+	switch key.(type) {
+	case *rsa.PrivateKey, *ecdsa.PrivateKey:
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T, only RSA and ECDSA keys are supported", key)
+	}
+
+	s := &smimeSigner{key: key}
+	for _, der := range pair.Certificate {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, err
+		}
+		s.chain = append(s.chain, cert)
+	}
+	return s, nil
+}
+
+// Sign returns the DER encoded detached CMS signature of content
+func (s *smimeSigner) Sign(content []byte, signingTime time.Time) ([]byte, error) {
+	digest := sha256.Sum256(content)
+
+	attributes, err := marshalCMSAttributes(
+		cmsAttribute{Type: oidAttributeContentType, Values: []asn1.RawValue{mustMarshalRaw(oidData)}},
+		cmsAttribute{Type: oidAttributeMessageDigest, Values: []asn1.RawValue{mustMarshalRaw(digest[:])}},
+		cmsAttribute{Type: oidAttributeSigningTime, Values: []asn1.RawValue{mustMarshalRaw(signingTime.UTC())}},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// the signature covers the DER encoding of the attributes as SET OF
+	set, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: attributes})
+	if err != nil {
+		return nil, err
+	}
+	attributesDigest := sha256.Sum256(set)
+	signature, err := s.key.Sign(rand.Reader, attributesDigest[:], crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+
+	signatureAlgorithm := pkix.AlgorithmIdentifier{Algorithm: oidEncryptionAlgorithmRSA, Parameters: asn1.NullRawValue}
+	if _, ok := s.key.(*ecdsa.PrivateKey); ok {
+		signatureAlgorithm = pkix.AlgorithmIdentifier{Algorithm: oidSignatureECDSASHA256}
+	}
+
+	var certificates []byte
+	for _, cert := range s.chain {
+		certificates = append(certificates, cert.Raw...)
+	}
+
+	digestAlgorithm := pkix.AlgorithmIdentifier{Algorithm: oidDigestAlgorithmSHA256, Parameters: asn1.NullRawValue}
+	signedData, err := asn1.Marshal(cmsSignedData{
+		Version:          1,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{digestAlgorithm},
+		ContentInfo:      cmsEncapsulatedContentInfo{ContentType: oidData},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: certificates},
+		SignerInfos: []cmsSignerInfo{{
+			Version: 1,
+			SID: cmsIssuerAndSerialNumber{
+				Issuer:       asn1.RawValue{FullBytes: s.chain[0].RawIssuer},
+				SerialNumber: s.chain[0].SerialNumber,
+			},
+			DigestAlgorithm:    digestAlgorithm,
+			SignedAttributes:   asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: attributes},
+			SignatureAlgorithm: signatureAlgorithm,
+			Signature:          signature,
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(cmsContentInfo{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: signedData},
+	})
+}
+
+// marshalCMSAttributes returns the concatenated DER encoding of the attributes in the order required for a SET OF
+func marshalCMSAttributes(attributes ...cmsAttribute) ([]byte, error) {
+	encoded := make([][]byte, 0, len(attributes))
+	for _, attribute := range attributes {
+		der, err := asn1.Marshal(attribute)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, der)
+	}
+	sort.Slice(encoded, func(i, j int) bool {
+		return bytes.Compare(encoded[i], encoded[j]) < 0
+	})
+	return bytes.Join(encoded, nil), nil
+}
+
+func mustMarshalRaw(v interface{}) asn1.RawValue {
+	der, err := asn1.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return asn1.RawValue{FullBytes: der}
+}
@@ -0,0 +1,117 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package token
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// A token is the lowercase base32 encoding of
+//
+//	version | handler type | user id | expiry | data | signature
+//
+// The numbers are stored as uvarints and the signature is a truncated HMAC-SHA256
+// keyed with the secret key of the instance and the rands of the user. The token
+// has to fit into the local part of an email address, so it must stay short.
+
+// HandlerType defines the type of the action the token grants
+type HandlerType byte
+
+// Handler types
+const (
+	UnknownHandlerType HandlerType = iota
+	ReplyHandlerType
+	UnsubscribeHandlerType
+)
+
+const (
+	tokenVersion1  byte = 1
+	signatureSize       = 10
+	tokenLifetime       = 180 * 24 * time.Hour
+	maxVarintBytes      = binary.MaxVarintLen64
+)
+
+var (
+	// ErrInvalidToken is returned for malformed tokens and tokens with a bad signature
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrExpiredToken is returned for tokens which are no longer valid
+	ErrExpiredToken = errors.New("token has expired")
+
+	encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+)
+
+// CreateToken creates a token which allows user to execute the action of the handler type with data
+func CreateToken(ht HandlerType, user *models.User, data []byte) string {
+	payload := make([]byte, 0, 2+2*maxVarintBytes+len(data)+signatureSize)
+	payload = append(payload, tokenVersion1, byte(ht))
+	payload = appendUvarint(payload, uint64(user.ID))
+	payload = appendUvarint(payload, uint64(time.Now().Add(tokenLifetime).Unix()))
+	payload = append(payload, data...)
+	payload = append(payload, sign(user, payload)...)
+
+	return strings.ToLower(encoding.EncodeToString(payload))
+}
+
+// ExtractToken verifies the token and returns the handler type, the user and the data it was created for
+func ExtractToken(token string) (HandlerType, *models.User, []byte, error) {
+	payload, err := encoding.DecodeString(strings.ToUpper(token))
+	if err != nil || len(payload) < 2+signatureSize || payload[0] != tokenVersion1 {
+		return UnknownHandlerType, nil, nil, ErrInvalidToken
+	}
+
+	ht := HandlerType(payload[1])
+	rest := payload[2 : len(payload)-signatureSize]
+	userID, n := binary.Uvarint(rest)
+	if n <= 0 {
+		return UnknownHandlerType, nil, nil, ErrInvalidToken
+	}
+	rest = rest[n:]
+	expiry, n := binary.Uvarint(rest)
+	if n <= 0 {
+		return UnknownHandlerType, nil, nil, ErrInvalidToken
+	}
+	data := rest[n:]
+
+	user, err := models.GetUserByID(int64(userID))
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			return UnknownHandlerType, nil, nil, ErrInvalidToken
+		}
+		return UnknownHandlerType, nil, nil, err
+	}
+
+	signature := payload[len(payload)-signatureSize:]
+	if !hmac.Equal(signature, sign(user, payload[:len(payload)-signatureSize])) {
+		return UnknownHandlerType, nil, nil, ErrInvalidToken
+	}
+
+	if time.Now().Unix() > int64(expiry) {
+		return UnknownHandlerType, nil, nil, ErrExpiredToken
+	}
+
+	return ht, user, data, nil
+}
+
+// sign creates the signature of the payload, it changes whenever the rands of the user are regenerated
+func sign(user *models.User, payload []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(setting.SecretKey+user.Rands))
+	_, _ = mac.Write(payload)
+	return mac.Sum(nil)[:signatureSize]
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	buf := make([]byte, maxVarintBytes)
+	n := binary.PutUvarint(buf, v)
+	return append(b, buf[:n]...)
+}
@@ -0,0 +1,65 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package token
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	db.MainTest(m, filepath.Join("..", "..", ".."))
+}
+
+func TestToken(t *testing.T) {
+	assert.NoError(t, db.PrepareTestDatabase())
+
+	user := db.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	data := []byte{0x01, 0x02, 0x03}
+
+	token := CreateToken(ReplyHandlerType, user, data)
+	assert.Equal(t, strings.ToLower(token), token)
+	assert.LessOrEqual(t, len(token), 64)
+
+	ht, u, d, err := ExtractToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, ReplyHandlerType, ht)
+	assert.Equal(t, user.ID, u.ID)
+	assert.Equal(t, data, d)
+
+	// tokens are case insensitive as they are part of an email address
+	_, _, _, err = ExtractToken(strings.ToUpper(token))
+	assert.NoError(t, err)
+
+	t.Run("Tampered", func(t *testing.T) {
+		tampered := []byte(token)
+		tampered[len(tampered)/2] ^= 1
+		_, _, _, err := ExtractToken(string(tampered))
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("Garbage", func(t *testing.T) {
+		_, _, _, err := ExtractToken("not a token")
+		assert.ErrorIs(t, err, ErrInvalidToken)
+		_, _, _, err = ExtractToken("")
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("OtherUser", func(t *testing.T) {
+		// the signature of a user doesn't verify for another one
+		other := db.AssertExistsAndLoadBean(t, &models.User{ID: 4}).(*models.User)
+		user.Rands = other.Rands + "x"
+		forged := CreateToken(ReplyHandlerType, user, data)
+		user.Rands = ""
+		_, _, _, err := ExtractToken(forged)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
@@ -29,15 +29,16 @@ const (
 
 // SyncRequest for the mirror queue
 type SyncRequest struct {
-	Type   SyncType
-	RepoID int64
+	Type    SyncType
+	RepoID  int64
+	Trigger models.PushMirrorSyncTrigger
 }
 
 // doMirrorSync causes this request to mirror itself
 func doMirrorSync(ctx context.Context, req *SyncRequest) {
 	switch req.Type {
 	case PushMirrorType:
-		_ = SyncPushMirror(ctx, req.RepoID)
+		_ = SyncPushMirror(ctx, req.RepoID, req.Trigger)
 	case PullMirrorType:
 		_ = SyncPullMirror(ctx, req.RepoID)
 	default:
@@ -132,17 +133,39 @@ func StartToMirror(repoID int64) {
 }
 
 // AddPushMirrorToQueue adds the push mirror to the queue
-func AddPushMirrorToQueue(mirrorID int64) {
+func AddPushMirrorToQueue(mirrorID int64, trigger models.PushMirrorSyncTrigger) {
 	if !setting.Mirror.Enabled {
 		return
 	}
 	go func() {
 		err := mirrorQueue.Push(&SyncRequest{
-			Type:   PushMirrorType,
-			RepoID: mirrorID,
+			Type:    PushMirrorType,
+			RepoID:  mirrorID,
+			Trigger: trigger,
 		})
 		if err != nil {
 			log.Error("Unable to push sync request to the queue for pull mirror repo[%d]: Error: %v", mirrorID, err)
 		}
 	}()
 }
+
+// AddPushMirrorsOnCommitToQueue adds the push mirrors of the repository which sync on commit
+// and push any of the updated refs to the queue
+func AddPushMirrorsOnCommitToQueue(repoID int64, refNames []string) {
+	if !setting.Mirror.Enabled {
+		return
+	}
+	mirrors, err := models.GetPushMirrorsSyncOnCommit(repoID)
+	if err != nil {
+		log.Error("GetPushMirrorsSyncOnCommit [repo: %d]: %v", repoID, err)
+		return
+	}
+	for _, m := range mirrors {
+		for _, refName := range refNames {
+			if m.MatchRef(refName) {
+				AddPushMirrorToQueue(m.ID, models.PushMirrorSyncTriggerCommit)
+				break
+			}
+		}
+	}
+}
@@ -6,6 +6,7 @@ package mirror
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -16,9 +17,11 @@ import (
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/lfs"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations"
 	"code.gitea.io/gitea/modules/notification"
 	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
 )
@@ -179,7 +182,7 @@ func pruneBrokenReferences(ctx context.Context,
 }
 
 // runSync returns true if sync finished without error.
-func runSync(ctx context.Context, m *models.Mirror) ([]*mirrorSyncResult, bool) {
+func runSync(ctx context.Context, m *models.Mirror) ([]*mirrorSyncResult, error) {
 	repoPath := m.Repo.RepoPath()
 	wikiPath := m.Repo.WikiPath()
 	timeout := time.Duration(setting.Git.Timeout.Mirror) * time.Second
@@ -239,10 +242,10 @@ func runSync(ctx context.Context, m *models.Mirror) ([]*mirrorSyncResult, bool)
 		if err != nil {
 			log.Error("Failed to update mirror repository %-v:\nStdout: %s\nStderr: %s\nErr: %v", m.Repo, stdoutMessage, stderrMessage, err)
 			desc := fmt.Sprintf("Failed to update mirror repository '%s': %s", repoPath, stderrMessage)
-			if err = models.CreateRepositoryNotice(desc); err != nil {
-				log.Error("CreateRepositoryNotice: %v", err)
+			if noticeErr := models.CreateRepositoryNotice(desc); noticeErr != nil {
+				log.Error("CreateRepositoryNotice: %v", noticeErr)
 			}
-			return nil, false
+			return nil, mirrorSyncError(err, stderrMessage, sanitizer)
 		}
 	}
 	output := stderrBuilder.String()
@@ -250,7 +253,7 @@ func runSync(ctx context.Context, m *models.Mirror) ([]*mirrorSyncResult, bool)
 	gitRepo, err := git.OpenRepository(repoPath)
 	if err != nil {
 		log.Error("OpenRepository: %v", err)
-		return nil, false
+		return nil, err
 	}
 
 	log.Trace("SyncMirrors [repo: %-v]: syncing releases with tags...", m.Repo)
@@ -323,10 +326,10 @@ func runSync(ctx context.Context, m *models.Mirror) ([]*mirrorSyncResult, bool)
 			if err != nil {
 				log.Error("Failed to update mirror repository wiki %-v:\nStdout: %s\nStderr: %s\nErr: %v", m.Repo, stdoutMessage, stderrMessage, err)
 				desc := fmt.Sprintf("Failed to update mirror repository wiki '%s': %s", wikiPath, stderrMessage)
-				if err = models.CreateRepositoryNotice(desc); err != nil {
-					log.Error("CreateRepositoryNotice: %v", err)
+				if noticeErr := models.CreateRepositoryNotice(desc); noticeErr != nil {
+					log.Error("CreateRepositoryNotice: %v", noticeErr)
 				}
-				return nil, false
+				return nil, mirrorSyncError(err, stderrMessage, sanitizer)
 			}
 		}
 		log.Trace("SyncMirrors [repo: %-v Wiki]: git remote update complete", m.Repo)
@@ -336,7 +339,7 @@ func runSync(ctx context.Context, m *models.Mirror) ([]*mirrorSyncResult, bool)
 	branches, _, err := repo_module.GetBranches(m.Repo, 0, 0)
 	if err != nil {
 		log.Error("GetBranches: %v", err)
-		return nil, false
+		return nil, err
 	}
 
 	for _, branch := range branches {
@@ -344,7 +347,52 @@ func runSync(ctx context.Context, m *models.Mirror) ([]*mirrorSyncResult, bool)
 	}
 
 	m.UpdatedUnix = timeutil.TimeStampNow()
-	return parseRemoteUpdateOutput(output), true
+	return parseRemoteUpdateOutput(output), nil
+}
+
+// CanSyncMetadata returns whether the issues, pull requests and releases of a mirror can be synced.
+// Only migrations that chose it keep what to sync and the credentials of the original service.
+func CanSyncMetadata(repo *models.Repository) (bool, error) {
+	if repo.OriginalServiceType <= structs.PlainGitService {
+		return false, nil
+	}
+	task, err := models.GetMigratingTask(repo.ID)
+	if err != nil {
+		if models.IsErrTaskDoesNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	opts, err := task.MigrateConfig()
+	if err != nil {
+		return false, err
+	}
+	return opts.Mirror && opts.MirrorMetadata, nil
+}
+
+// syncMetadata syncs the issues, pull requests and releases of a mirror migrated from another service.
+func syncMetadata(ctx context.Context, m *models.Mirror) error {
+	task, err := models.GetMigratingTask(m.RepoID)
+	if err != nil {
+		return err
+	}
+	opts, err := task.MigrateConfig()
+	if err != nil {
+		return err
+	}
+	if !opts.Mirror || !opts.MirrorMetadata {
+		return errors.New("the migration did not keep the credentials to sync issues and releases")
+	}
+	if err = task.LoadDoer(); err != nil {
+		return err
+	}
+
+	syncStart := timeutil.TimeStampNow()
+	if err = migrations.SyncRepository(ctx, task.Doer, m.Repo, *opts, m.MetadataSyncedUnix.AsTime()); err != nil {
+		return err
+	}
+	m.MetadataSyncedUnix = syncStart
+	return nil
 }
 
 // SyncPullMirror starts the sync of the pull mirror and schedules the next run.
@@ -366,11 +414,34 @@ func SyncPullMirror(ctx context.Context, repoID int64) bool {
 	}
 
 	log.Trace("SyncMirrors [repo: %-v]: Running Sync", m.Repo)
-	results, ok := runSync(ctx, m)
-	if !ok {
+	start := time.Now()
+	results, err := runSync(ctx, m)
+	if err != nil {
+		setSyncStatus(m, start, nil, err)
+		if err := models.UpdateMirrorSyncStatus(m); err != nil {
+			log.Error("UpdateMirrorSyncStatus [%d]: %v", m.RepoID, err)
+		}
 		return false
 	}
 
+	// Failing to sync the metadata does not fail the sync, the synced git data stays valid without it
+	var metadataErr error
+	if m.SyncMetadata {
+		log.Trace("SyncMirrors [repo: %-v]: Syncing metadata", m.Repo)
+		if err := syncMetadata(ctx, m); err != nil {
+			log.Error("SyncMirrors [repo: %-v]: unable to sync metadata: %v", m.Repo, err)
+			metadataErr = fmt.Errorf("sync issues and releases: %v", err)
+			// the failure is kept in the last error of the mirror, only its start is noticed
+			if m.LastError == "" {
+				desc := fmt.Sprintf("Failed to sync the issues and releases of mirror repository '%s': %v", m.Repo.FullName(), err)
+				if err := models.CreateRepositoryNotice(desc); err != nil {
+					log.Error("CreateRepositoryNotice: %v", err)
+				}
+			}
+		}
+	}
+	setSyncStatus(m, start, results, metadataErr)
+
 	log.Trace("SyncMirrors [repo: %-v]: Scheduling next update", m.Repo)
 	m.ScheduleNextUpdate()
 	if err = models.UpdateMirror(m); err != nil {
@@ -479,6 +550,41 @@ func SyncPullMirror(ctx context.Context, repoID int64) bool {
 	return true
 }
 
+// mirrorSyncError returns the error of a failed git command by its sanitized output,
+// as the error itself may contain the remote address with its password
+func mirrorSyncError(err error, stderrMessage string, sanitizer *strings.Replacer) error {
+	if msg := strings.TrimSpace(stderrMessage); msg != "" {
+		return errors.New(msg)
+	}
+	return errors.New(sanitizer.Replace(err.Error()))
+}
+
+// setSyncStatus sets the status of the last sync of the mirror
+func setSyncStatus(m *models.Mirror, start time.Time, results []*mirrorSyncResult, err error) {
+	m.LastSyncUnix = timeutil.TimeStampNow()
+	m.LastSyncDuration = time.Since(start).Round(time.Millisecond)
+	m.LastError = ""
+	if err != nil {
+		m.LastError = stripExitStatus.ReplaceAllLiteralString(err.Error(), "")
+	}
+
+	m.NumLastSyncRefs = len(results)
+	m.LastSyncRefs = make([]*models.MirrorSyncRef, 0, util.Min(len(results), models.MirrorSyncRefsLimit))
+	for _, result := range results {
+		if len(m.LastSyncRefs) == models.MirrorSyncRefsLimit {
+			break
+		}
+		ref := &models.MirrorSyncRef{RefName: result.refName}
+		if result.oldCommitID != gitShortEmptySha {
+			ref.OldCommitID = result.oldCommitID
+		}
+		if result.newCommitID != gitShortEmptySha {
+			ref.NewCommitID = result.newCommitID
+		}
+		m.LastSyncRefs = append(m.LastSyncRefs, ref)
+	}
+}
+
 func checkAndUpdateEmptyRepository(m *models.Mirror, gitRepo *git.Repository, results []*mirrorSyncResult) bool {
 	if !m.Repo.IsEmpty {
 		return true
@@ -7,9 +7,12 @@ package mirror
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net/url"
+	"os"
 	"regexp"
+	"strings"
 	"time"
 
 	"code.gitea.io/gitea/models"
@@ -17,7 +20,9 @@ import (
 	"code.gitea.io/gitea/modules/lfs"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/secret"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/ssh"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
 )
@@ -43,7 +48,8 @@ func AddPushMirrorRemote(m *models.PushMirror, addr string) error {
 		return err
 	}
 
-	if m.Repo.HasWiki() {
+	// the deploy key of an SSH mirror is not yet accepted by the remote to look for its wiki
+	if m.Repo.HasWiki() && !m.IsSSH() {
 		wikiRemoteURL := repository.WikiRemoteURL(addr)
 		if len(wikiRemoteURL) > 0 {
 			if err := addRemoteAndConfig(wikiRemoteURL, m.Repo.WikiPath()); err != nil {
@@ -55,6 +61,20 @@ func AddPushMirrorRemote(m *models.PushMirror, addr string) error {
 	return nil
 }
 
+// GeneratePushMirrorKey generates the deploy key of a push mirror pushing over SSH
+func GeneratePushMirrorKey(m *models.PushMirror) error {
+	private, public, err := ssh.GenerateKeyPair()
+	if err != nil {
+		return err
+	}
+	m.PrivateKey, err = secret.EncryptSecret(setting.SecretKey, string(private))
+	if err != nil {
+		return err
+	}
+	m.PublicKey = strings.TrimSpace(string(public)) + " gitea-push-mirror-" + m.RemoteName
+	return nil
+}
+
 // RemovePushMirrorRemote removes the push mirror remote.
 func RemovePushMirrorRemote(m *models.PushMirror) error {
 	cmd := git.NewCommand("remote", "rm", m.RemoteName)
@@ -74,7 +94,7 @@ func RemovePushMirrorRemote(m *models.PushMirror) error {
 }
 
 // SyncPushMirror starts the sync of the push mirror and schedules the next run.
-func SyncPushMirror(ctx context.Context, mirrorID int64) bool {
+func SyncPushMirror(ctx context.Context, mirrorID int64, trigger models.PushMirrorSyncTrigger) bool {
 	log.Trace("SyncPushMirror [mirror: %d]", mirrorID)
 	defer func() {
 		err := recover()
@@ -91,16 +111,25 @@ func SyncPushMirror(ctx context.Context, mirrorID int64) bool {
 		return false
 	}
 
-	m.LastError = ""
+	sync := &models.PushMirrorSync{
+		PushMirrorID: m.ID,
+		Trigger:      trigger,
+		StartedUnix:  timeutil.TimeStampNow(),
+	}
 
 	log.Trace("SyncPushMirror [mirror: %d][repo: %-v]: Running Sync", m.ID, m.Repo)
 	err = runPushSync(ctx, m)
 	if err != nil {
 		log.Error("SyncPushMirror [mirror: %d][repo: %-v]: %v", m.ID, m.Repo, err)
-		m.LastError = stripExitStatus.ReplaceAllLiteralString(err.Error(), "")
+		sync.Error = stripExitStatus.ReplaceAllLiteralString(err.Error(), "")
+	}
+
+	sync.FinishedUnix = timeutil.TimeStampNow()
+	if err := models.InsertPushMirrorSync(sync); err != nil {
+		log.Error("InsertPushMirrorSync [%d]: %v", m.ID, err)
 	}
 
-	m.LastUpdateUnix = timeutil.TimeStampNow()
+	m.LastUpdateUnix = sync.FinishedUnix
 
 	if err := models.UpdatePushMirror(m); err != nil {
 		log.Error("UpdatePushMirror [%d]: %v", m.ID, err)
@@ -113,17 +142,88 @@ func SyncPushMirror(ctx context.Context, mirrorID int64) bool {
 	return err == nil
 }
 
+// pushMirrorSSHEnv writes the private key of a push mirror pushing over SSH to a temporary file
+// and returns the environment to run git with it. The returned function removes the file.
+func pushMirrorSSHEnv(m *models.PushMirror) ([]string, func(), error) {
+	if !m.IsSSH() {
+		return nil, func() {}, nil
+	}
+
+	private, err := secret.DecryptSecret(setting.SecretKey, m.PrivateKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("DecryptSecret: %v", err)
+	}
+
+	f, err := os.CreateTemp("", "push-mirror-key")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() {
+		if err := util.Remove(f.Name()); err != nil {
+			log.Error("Unable to remove the push mirror key %s: %v", f.Name(), err)
+		}
+	}
+	_, err = f.WriteString(private)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	sshCommand := fmt.Sprintf("ssh -i '%s' -o IdentitiesOnly=yes -o BatchMode=yes -o StrictHostKeyChecking=accept-new", f.Name())
+	return append(os.Environ(), "GIT_SSH_COMMAND="+sshCommand), cleanup, nil
+}
+
+// filteredPushRefspecs returns the refspecs to push the branches and tags matching the filters of the mirror
+// and to delete those of the remote which no longer exist locally
+func filteredPushRefspecs(ctx context.Context, m *models.PushMirror, path string, env []string, timeout time.Duration) ([]string, error) {
+	local, err := git.NewCommandContext(ctx, "for-each-ref", "--format=%(refname)", "refs/heads", "refs/tags").RunInDir(path)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := git.NewCommandContext(ctx, "ls-remote", "--heads", "--tags", "--refs", m.RemoteName).RunInDirTimeoutEnv(env, timeout, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var refspecs []string
+	localRefs := make(map[string]bool)
+	for _, refName := range strings.Split(local, "\n") {
+		if refName = strings.TrimSpace(refName); refName != "" && m.MatchRef(refName) {
+			localRefs[refName] = true
+			refspecs = append(refspecs, "+"+refName+":"+refName)
+		}
+	}
+	for _, line := range strings.Split(string(remote), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && !localRefs[fields[1]] && m.MatchRef(fields[1]) {
+			refspecs = append(refspecs, ":"+fields[1])
+		}
+	}
+	return refspecs, nil
+}
+
 func runPushSync(ctx context.Context, m *models.PushMirror) error {
 	timeout := time.Duration(setting.Git.Timeout.Mirror) * time.Second
 
-	performPush := func(path string) error {
+	env, cleanup, err := pushMirrorSSHEnv(m)
+	if err != nil {
+		log.Error("Unable to prepare the SSH key of push mirror[%d]: %v", m.ID, err)
+		return errors.New("Unexpected error")
+	}
+	defer cleanup()
+
+	performPush := func(path string, filtered bool) error {
 		remoteAddr, err := git.GetRemoteAddress(path, m.RemoteName)
 		if err != nil {
 			log.Error("GetRemoteAddress(%s) Error %v", path, err)
 			return errors.New("Unexpected error")
 		}
 
-		if setting.LFS.StartServer {
+		// LFS objects can only be uploaded over HTTP
+		if setting.LFS.StartServer && !m.IsSSH() {
 			log.Trace("SyncMirrors [repo: %-v]: syncing LFS objects...", m.Repo)
 
 			gitRepo, err := git.OpenRepository(path)
@@ -141,10 +241,29 @@ func runPushSync(ctx context.Context, m *models.PushMirror) error {
 
 		log.Trace("Pushing %s mirror[%d] remote %s", path, m.ID, m.RemoteName)
 
+		if filtered {
+			refspecs, err := filteredPushRefspecs(ctx, m, path, env, timeout)
+			if err != nil {
+				log.Error("Error listing the refs of %s mirror[%d] remote %s: %v", path, m.ID, m.RemoteName, err)
+				return util.NewURLSanitizedError(err, remoteAddr, true)
+			}
+			if len(refspecs) == 0 {
+				return nil
+			}
+			// the remote is configured to mirror all refs, which cannot be combined with refspecs
+			args := append([]string{"-c", "remote." + m.RemoteName + ".mirror=false", "push", "--force", "--", m.RemoteName}, refspecs...)
+			if _, err := git.NewCommandContext(ctx, args...).RunInDirTimeoutEnv(env, timeout, path); err != nil {
+				log.Error("Error pushing %s mirror[%d] remote %s: %v", path, m.ID, m.RemoteName, err)
+				return util.NewURLSanitizedError(err, remoteAddr, true)
+			}
+			return nil
+		}
+
 		if err := git.Push(path, git.PushOptions{
 			Remote:  m.RemoteName,
 			Force:   true,
 			Mirror:  true,
+			Env:     env,
 			Timeout: timeout,
 		}); err != nil {
 			log.Error("Error pushing %s mirror[%d] remote %s: %v", path, m.ID, m.RemoteName, err)
@@ -155,16 +274,17 @@ func runPushSync(ctx context.Context, m *models.PushMirror) error {
 		return nil
 	}
 
-	err := performPush(m.Repo.RepoPath())
+	err = performPush(m.Repo.RepoPath(), m.HasFilters())
 	if err != nil {
 		return err
 	}
 
+	// the filters apply to the branches and tags of the repository, the wiki is pushed as a whole
 	if m.Repo.HasWiki() {
 		wikiPath := m.Repo.WikiPath()
 		_, err := git.GetRemoteAddress(wikiPath, m.RemoteName)
 		if err == nil {
-			err := performPush(wikiPath)
+			err := performPush(wikiPath, false)
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,89 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mirror
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/secret"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// GenerateWebhookSecret sets a new random secret for the webhooks which sync the mirror
+func GenerateWebhookSecret(m *models.Mirror) error {
+	webhookSecret, err := util.RandomString(40)
+	if err != nil {
+		return err
+	}
+	m.WebhookSecret, err = secret.EncryptSecret(setting.SecretKey, webhookSecret)
+	if err != nil {
+		return err
+	}
+	return models.UpdateMirrorWebhookSecret(m)
+}
+
+// RemoveWebhookSecret removes the secret of the mirror, refusing all webhooks
+func RemoveWebhookSecret(m *models.Mirror) error {
+	m.WebhookSecret = ""
+	return models.UpdateMirrorWebhookSecret(m)
+}
+
+// GetWebhookSecret returns the decrypted secret for the webhooks of the mirror
+func GetWebhookSecret(m *models.Mirror) (string, error) {
+	if !m.HasWebhook() {
+		return "", nil
+	}
+	return secret.DecryptSecret(setting.SecretKey, m.WebhookSecret)
+}
+
+// VerifyWebhook checks a webhook request of the remote of the mirror against its secret.
+// The payload is accepted when signed like webhooks of Gitea, Gogs, GitHub and Bitbucket Server
+// or with the secret as token like webhooks of GitLab.
+func VerifyWebhook(m *models.Mirror, header http.Header, payload io.Reader) bool {
+	webhookSecret, err := GetWebhookSecret(m)
+	if err != nil {
+		log.Error("GetWebhookSecret [repo: %d]: %v", m.RepoID, err)
+		return false
+	}
+	if webhookSecret == "" {
+		return false
+	}
+
+	if token := header.Get("X-Gitlab-Token"); token != "" {
+		return subtle.ConstantTimeCompare([]byte(token), []byte(webhookSecret)) == 1
+	}
+
+	var signature string
+	for _, name := range []string{"X-Hub-Signature-256", "X-Gitea-Signature", "X-Gogs-Signature", "X-Hub-Signature"} {
+		if value := header.Get(name); value != "" {
+			if name == "X-Hub-Signature" && !strings.HasPrefix(value, "sha256=") {
+				// only the sha256 signature is accepted
+				continue
+			}
+			signature = strings.TrimPrefix(value, "sha256=")
+			break
+		}
+	}
+	expected, err := hex.DecodeString(signature)
+	if err != nil || len(expected) == 0 {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(webhookSecret))
+	if _, err := io.Copy(mac, payload); err != nil {
+		log.Error("Unable to read the webhook payload [repo: %d]: %v", m.RepoID, err)
+		return false
+	}
+	return hmac.Equal(mac.Sum(nil), expected)
+}
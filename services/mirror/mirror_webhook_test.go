@@ -0,0 +1,50 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mirror
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/secret"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyWebhook(t *testing.T) {
+	const payload = `{"ref":"refs/heads/main"}`
+	encrypted, err := secret.EncryptSecret(setting.SecretKey, "webhook-secret")
+	assert.NoError(t, err)
+	m := &models.Mirror{WebhookSecret: encrypted}
+
+	mac := hmac.New(sha256.New, []byte("webhook-secret"))
+	mac.Write([]byte(payload))
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	verify := func(m *models.Mirror, name, value string) bool {
+		header := http.Header{}
+		if name != "" {
+			header.Set(name, value)
+		}
+		return VerifyWebhook(m, header, strings.NewReader(payload))
+	}
+
+	assert.True(t, verify(m, "X-Hub-Signature-256", "sha256="+signature))
+	assert.True(t, verify(m, "X-Gitea-Signature", signature))
+	assert.True(t, verify(m, "X-Gitlab-Token", "webhook-secret"))
+	assert.False(t, verify(m, "X-Gitea-Signature", strings.Repeat("0", len(signature))))
+	assert.False(t, verify(m, "X-Gitlab-Token", "wrong"))
+	assert.False(t, verify(m, "X-Hub-Signature", "sha1=abcdef"))
+	assert.False(t, verify(m, "", ""))
+
+	// without a secret all webhooks are refused
+	assert.False(t, verify(&models.Mirror{}, "X-Gitea-Signature", signature))
+}
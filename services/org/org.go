@@ -0,0 +1,56 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/notification"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/services/audit"
+	repo_service "code.gitea.io/gitea/services/repository"
+)
+
+// DeleteOrganization deletes an organization that has no repositories left outside of
+// the trash. If a trash period is configured the organization is moved to the trash instead.
+func DeleteOrganization(doer, org *models.User) error {
+	if setting.Repository.TrashPeriod > 0 {
+		return models.TrashOrganization(doer, org)
+	}
+
+	if err := models.CheckOrgReposInTrash(org); err != nil {
+		return err
+	}
+	return purgeOrganization(doer, org)
+}
+
+// PurgeOrganization permanently deletes an organization in the trash
+// together with its repositories in the trash
+func PurgeOrganization(doer, org *models.User) error {
+	if !org.IsDeleted() {
+		return models.ErrOrgNotExist{ID: org.ID, Name: org.Name}
+	}
+	return purgeOrganization(doer, org)
+}
+
+func purgeOrganization(doer, org *models.User) error {
+	repos, _, err := models.FindDeletedRepositories(&models.FindDeletedReposOptions{OwnerID: org.ID})
+	if err != nil {
+		return err
+	}
+	for _, repo := range repos {
+		if err := repo_service.PurgeRepository(doer, repo); err != nil {
+			return fmt.Errorf("PurgeRepository[%d]: %v", repo.ID, err)
+		}
+		audit.RecordRepo(nil, doer, repo, models.AuditRepoPurge, "", "organization deleted")
+	}
+
+	if err := models.DeleteOrganization(org); err != nil {
+		return err
+	}
+	notification.NotifyDeleteOrganization(doer, org)
+	return nil
+}
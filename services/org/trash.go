@@ -0,0 +1,80 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/services/audit"
+)
+
+// CanManageDeletedOrganization returns true if the doer may restore or permanently
+// delete an organization in the trash, i.e. the doer is a site administrator or
+// an owner of the organization.
+func CanManageDeletedOrganization(doer, org *models.User) (bool, error) {
+	if doer == nil {
+		return false, nil
+	}
+	if doer.IsAdmin {
+		return true, nil
+	}
+	return org.IsOwnedBy(doer.ID)
+}
+
+// PurgeExpiredOrganizations permanently deletes the organizations that have been in the
+// trash longer than the trash period. Nothing is deleted while the trash is disabled.
+func PurgeExpiredOrganizations(ctx context.Context) error {
+	if setting.Repository.TrashPeriod <= 0 {
+		return nil
+	}
+	deletedBefore := timeutil.TimeStamp(time.Now().Add(-setting.Repository.TrashPeriod).Unix())
+	return purgeDeletedOrganizations(ctx, nil, &models.FindDeletedOrgsOptions{DeletedBefore: deletedBefore}, "trash period expired")
+}
+
+// EmptyTrash permanently deletes all organizations in the trash on behalf of a site administrator
+func EmptyTrash(ctx context.Context, doer *models.User) error {
+	if !doer.IsAdmin {
+		return fmt.Errorf("%s is not a site administrator", doer.Name)
+	}
+	return purgeDeletedOrganizations(ctx, doer, &models.FindDeletedOrgsOptions{}, "trash emptied")
+}
+
+// purgeDeletedOrganizations permanently deletes the organizations in the trash found by opts.
+// Without a doer they are deleted on behalf of the users who moved them to the trash.
+func purgeDeletedOrganizations(ctx context.Context, doer *models.User, opts *models.FindDeletedOrgsOptions, reason string) error {
+	orgs, _, err := models.FindDeletedOrgs(opts)
+	if err != nil {
+		return err
+	}
+	for _, org := range orgs {
+		select {
+		case <-ctx.Done():
+			return models.ErrCancelledf("before purging %s", org.Name)
+		default:
+		}
+
+		purger := doer
+		if purger == nil {
+			if purger, err = models.GetUserByID(org.DeletedByID); err != nil {
+				if !models.IsErrUserNotExist(err) {
+					return err
+				}
+				purger = models.NewGhostUser()
+			}
+		}
+		if err := PurgeOrganization(purger, org); err != nil {
+			return fmt.Errorf("PurgeOrganization[%d]: %v", org.ID, err)
+		}
+		audit.RecordUser(nil, purger, org, models.AuditOrgPurge, reason)
+		log.Trace("Organization %s purged from the trash: %s", org.Name, reason)
+	}
+	return nil
+}
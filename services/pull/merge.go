@@ -408,11 +408,17 @@ func rawMerge(pr *models.PullRequest, doer *models.User, mergeStyle models.Merge
 		headUser = pr.HeadRepo.Owner
 	}
 
+	// The rebase result is pushed to the head repository, so the hooks must run for it
+	pushRepo := pr.BaseRepo
+	if mergeStyle == models.MergeStyleRebaseUpdate {
+		pushRepo = pr.HeadRepo
+	}
+
 	env = models.FullPushingEnvironment(
 		headUser,
 		doer,
-		pr.BaseRepo,
-		pr.BaseRepo.Name,
+		pushRepo,
+		pushRepo.Name,
 		pr.ID,
 	)
 
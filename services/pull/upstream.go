@@ -0,0 +1,194 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/cache"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// MergeUpstreamStyle represents how a branch of a fork is brought up to date with its upstream branch
+type MergeUpstreamStyle string
+
+// enumerates all styles to merge upstream changes into a fork
+const (
+	// MergeUpstreamFastForward only fast-forwards the branch, it fails if the branch has diverged
+	MergeUpstreamFastForward MergeUpstreamStyle = "fast-forward"
+	// MergeUpstreamMerge creates a merge commit if the branch cannot be fast-forwarded
+	MergeUpstreamMerge MergeUpstreamStyle = "merge"
+	// MergeUpstreamRebase rebases the commits of the branch on the upstream branch
+	MergeUpstreamRebase MergeUpstreamStyle = "rebase"
+)
+
+// IsValid returns true if the style is known
+func (style MergeUpstreamStyle) IsValid() bool {
+	switch style {
+	case MergeUpstreamFastForward, MergeUpstreamMerge, MergeUpstreamRebase:
+		return true
+	}
+	return false
+}
+
+// GetUpstreamBranch returns the branch of the base repository that a branch of a fork follows.
+// The default branch follows the default branch of the base repository, other branches follow
+// the branch with the same name.
+func GetUpstreamBranch(repo *models.Repository, branch string) (string, error) {
+	if !repo.IsFork {
+		return "", fmt.Errorf("repository %-v is not a fork", repo)
+	}
+	if err := repo.GetBaseRepo(); err != nil {
+		return "", err
+	}
+	if branch == repo.DefaultBranch {
+		return repo.BaseRepo.DefaultBranch, nil
+	}
+	return branch, nil
+}
+
+// upstreamPullRequest returns a pull request from the upstream branch into the branch of the fork
+// which is never stored but lets the merge functions operate on them
+func upstreamPullRequest(repo *models.Repository, branch string) (*models.PullRequest, error) {
+	upstreamBranch, err := GetUpstreamBranch(repo, branch)
+	if err != nil {
+		return nil, err
+	}
+	if !git.IsBranchExist(repo.RepoPath(), branch) {
+		return nil, models.ErrBranchDoesNotExist{BranchName: branch}
+	}
+	if !git.IsBranchExist(repo.BaseRepo.RepoPath(), upstreamBranch) {
+		return nil, models.ErrBranchDoesNotExist{BranchName: upstreamBranch}
+	}
+
+	return &models.PullRequest{
+		HeadRepoID: repo.BaseRepo.ID,
+		HeadRepo:   repo.BaseRepo,
+		HeadBranch: upstreamBranch,
+		BaseRepoID: repo.ID,
+		BaseRepo:   repo,
+		BaseBranch: branch,
+	}, nil
+}
+
+func getBranchCommitID(repoPath, branch string) (string, error) {
+	gitRepo, err := git.OpenRepository(repoPath)
+	if err != nil {
+		return "", err
+	}
+	defer gitRepo.Close()
+	return gitRepo.GetBranchCommitID(branch)
+}
+
+// GetUpstreamDivergence returns how many commits a branch of a fork is ahead and behind its upstream branch.
+// As the divergence of two commits never changes, it is cached by their IDs.
+func GetUpstreamDivergence(repo *models.Repository, branch string) (*git.DivergeObject, error) {
+	pr, err := upstreamPullRequest(repo, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	commitID, err := getBranchCommitID(repo.RepoPath(), branch)
+	if err != nil {
+		return nil, err
+	}
+	upstreamCommitID, err := getBranchCommitID(repo.BaseRepo.RepoPath(), pr.HeadBranch)
+	if err != nil {
+		return nil, err
+	}
+	if commitID == upstreamCommitID {
+		return &git.DivergeObject{}, nil
+	}
+
+	value, err := cache.GetString("UpstreamDivergence:"+commitID+":"+upstreamCommitID, func() (string, error) {
+		tmpRepo, err := createTemporaryRepo(pr)
+		if err != nil {
+			return "", err
+		}
+		defer func() {
+			if err := models.RemoveTemporaryPath(tmpRepo); err != nil {
+				log.Error("GetUpstreamDivergence: RemoveTemporaryPath: %s", err)
+			}
+		}()
+
+		// "tracking" is the upstream branch, so its ahead is what the fork is behind
+		diff, err := git.GetDivergingCommits(tmpRepo, "base", "tracking")
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%d:%d", diff.Behind, diff.Ahead), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fields := strings.SplitN(value, ":", 2)
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("invalid cached divergence %q", value)
+	}
+	diff := &git.DivergeObject{}
+	if diff.Ahead, err = strconv.Atoi(fields[0]); err != nil {
+		return nil, err
+	}
+	if diff.Behind, err = strconv.Atoi(fields[1]); err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+// MergeUpstream brings a branch of a fork up to date with its upstream branch in the given style.
+// A branch that has no commits of its own is always fast-forwarded. It returns the style that was
+// used, which is empty if the branch was already up to date.
+func MergeUpstream(doer *models.User, repo *models.Repository, branch string, style MergeUpstreamStyle) (MergeUpstreamStyle, error) {
+	if !style.IsValid() {
+		return "", fmt.Errorf("invalid merge upstream style %q", style)
+	}
+
+	diff, err := GetUpstreamDivergence(repo, branch)
+	if err != nil {
+		return "", err
+	}
+	if diff.Behind == 0 {
+		return "", nil
+	}
+
+	pr, err := upstreamPullRequest(repo, branch)
+	if err != nil {
+		return "", err
+	}
+	message := fmt.Sprintf("Merge branch '%s' of %s into %s", pr.HeadBranch, repo.BaseRepo.FullName(), branch)
+
+	switch {
+	case diff.Ahead == 0:
+		// Rebasing the upstream branch on an ancestor changes nothing, so this only fast-forwards
+		style = MergeUpstreamFastForward
+		_, err = rawMerge(pr, doer, models.MergeStyleRebase, message)
+	case style == MergeUpstreamFastForward:
+		return "", models.ErrForkBranchDiverged{RepoID: repo.ID, Branch: branch, Ahead: diff.Ahead}
+	case style == MergeUpstreamMerge:
+		_, err = rawMerge(pr, doer, models.MergeStyleMerge, message)
+	case style == MergeUpstreamRebase:
+		// use the update functions with the fork as head so the rebase result is pushed to it
+		pr = &models.PullRequest{
+			HeadRepoID: pr.BaseRepoID,
+			HeadRepo:   pr.BaseRepo,
+			HeadBranch: pr.BaseBranch,
+			BaseRepoID: pr.HeadRepoID,
+			BaseRepo:   pr.HeadRepo,
+			BaseBranch: pr.HeadBranch,
+		}
+		_, err = rawMerge(pr, doer, models.MergeStyleRebaseUpdate, message)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	go AddTestPullRequestTask(doer, repo.ID, branch, false, "", "")
+	return style, nil
+}
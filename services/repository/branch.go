@@ -109,3 +109,28 @@ func DeleteBranch(doer *models.User, repo *models.Repository, gitRepo *git.Repos
 
 	return nil
 }
+
+// UpdateBranchProtection creates or updates the protection of a branch and notifies about it
+func UpdateBranchProtection(doer *models.User, repo *models.Repository, protectBranch *models.ProtectedBranch, opts models.WhitelistOptions) error {
+	isNew := protectBranch.ID == 0
+	if err := models.UpdateProtectBranch(repo, protectBranch, opts); err != nil {
+		return err
+	}
+
+	if isNew {
+		notification.NotifyCreateBranchProtection(doer, repo, protectBranch)
+	} else {
+		notification.NotifyUpdateBranchProtection(doer, repo, protectBranch)
+	}
+	return nil
+}
+
+// DeleteBranchProtection deletes the protection of a branch and notifies about it
+func DeleteBranchProtection(doer *models.User, repo *models.Repository, protectBranch *models.ProtectedBranch) error {
+	if err := repo.DeleteProtectedBranch(protectBranch.ID); err != nil {
+		return err
+	}
+
+	notification.NotifyDeleteBranchProtection(doer, repo, protectBranch)
+	return nil
+}
@@ -0,0 +1,197 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/services/audit"
+)
+
+// ValidateBulkRepoOption checks the options of a bulk repository operation before it is queued
+// and normalizes the list of repositories. An organization restricts the operation to its
+// repositories, otherwise the doer must be a site administrator.
+func ValidateBulkRepoOption(doer, org *models.User, opts *api.BulkRepoOption) error {
+	invalid := func(format string, args ...interface{}) error {
+		return models.ErrBulkRepoOptionInvalid{Action: string(opts.Action), Reason: fmt.Sprintf(format, args...)}
+	}
+
+	if org == nil && !doer.IsAdmin {
+		return errors.New("only site administrators may operate on repositories of any owner")
+	}
+	if !opts.Action.IsValid() {
+		return invalid("unknown action")
+	}
+
+	seen := make(map[string]bool, len(opts.Repos))
+	repos := make([]string, 0, len(opts.Repos))
+	for _, fullName := range opts.Repos {
+		fullName = strings.TrimSpace(fullName)
+		if fullName == "" || seen[strings.ToLower(fullName)] {
+			continue
+		}
+		fields := strings.Split(fullName, "/")
+		if len(fields) != 2 || fields[0] == "" || fields[1] == "" {
+			return invalid("%q is not a full repository name like owner/name", fullName)
+		}
+		if org != nil && !strings.EqualFold(fields[0], org.Name) {
+			return invalid("%s is not a repository of %s", fullName, org.Name)
+		}
+		seen[strings.ToLower(fullName)] = true
+		repos = append(repos, fullName)
+	}
+	if len(repos) == 0 {
+		return invalid("no repositories given")
+	}
+	opts.Repos = repos
+
+	switch opts.Action {
+	case api.BulkRepoTransfer:
+		newOwner, err := models.GetUserByName(opts.NewOwner)
+		if err != nil {
+			if models.IsErrUserNotExist(err) {
+				return invalid("the new owner does not exist")
+			}
+			return err
+		}
+		if newOwner.IsOrganization() && !doer.IsAdmin && newOwner.Visibility == api.VisibleTypePrivate && !newOwner.HasMemberWithUserID(doer.ID) {
+			// The user shouldn't know about this organization
+			return invalid("the new owner does not exist")
+		}
+	case api.BulkRepoVisibility:
+		if opts.Private == nil {
+			return invalid("private is required")
+		}
+		if !*opts.Private && setting.Repository.ForcePrivate && !doer.IsAdmin {
+			return invalid("only site administrators may make repositories public")
+		}
+	case api.BulkRepoAddTeam:
+		if opts.Team == "" {
+			return invalid("team is required")
+		}
+		if org != nil {
+			if _, err := models.GetTeam(org.ID, opts.Team); err != nil {
+				if models.IsErrTeamNotExist(err) {
+					return invalid("%s has no team %s", org.Name, opts.Team)
+				}
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ApplyBulkRepoAction applies the action of a bulk repository operation to the repository
+// with the given full name. An organization restricts the operation to its repositories.
+// It returns a note for repositories that needed no change or that await a transfer.
+func ApplyBulkRepoAction(doer, org *models.User, opts *api.BulkRepoOption, fullName string) (string, error) {
+	notFound := errors.New("repository does not exist")
+	if org != nil {
+		notFound = fmt.Errorf("repository does not exist in %s", org.Name)
+	}
+
+	fields := strings.SplitN(fullName, "/", 2)
+	if len(fields) != 2 {
+		return "", notFound
+	}
+	repo, err := models.GetRepositoryByOwnerAndName(fields[0], fields[1])
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			return "", notFound
+		}
+		return "", err
+	}
+	if org != nil && repo.OwnerID != org.ID {
+		return "", notFound
+	}
+	if err := repo.GetOwner(); err != nil {
+		return "", err
+	}
+
+	switch opts.Action {
+	case api.BulkRepoTransfer:
+		newOwner, err := models.GetUserByName(opts.NewOwner)
+		if err != nil {
+			return "", err
+		}
+		if repo.OwnerID == newOwner.ID {
+			return "already owned by " + newOwner.Name, nil
+		}
+
+		// keep the original owner for the audit log, a direct transfer changes it
+		oldRepo := *repo
+		if err := StartRepositoryTransfer(doer, newOwner, repo, nil); err != nil {
+			return "", err
+		}
+		audit.RecordRepo(nil, doer, &oldRepo, models.AuditRepoTransfer, "", "to "+newOwner.Name)
+		if repo.Status == models.RepositoryPendingTransfer {
+			return "transfer awaits acceptance by " + newOwner.Name, nil
+		}
+
+	case api.BulkRepoArchive, api.BulkRepoUnarchive:
+		archive := opts.Action == api.BulkRepoArchive
+		if repo.IsArchived == archive {
+			return "nothing to change", nil
+		}
+		if archive && repo.IsMirror {
+			return "", errors.New("mirrors cannot be archived")
+		}
+		if err := SetArchiveRepoState(doer, repo, archive); err != nil {
+			return "", err
+		}
+
+	case api.BulkRepoVisibility:
+		if repo.IsFork {
+			return "", errors.New("the visibility of a fork follows its base repository")
+		}
+		if repo.IsPrivate == *opts.Private {
+			return "nothing to change", nil
+		}
+		if !*opts.Private && setting.Repository.ForcePrivate && !doer.IsAdmin {
+			return "", errors.New("only site administrators may make repositories public")
+		}
+		repo.IsPrivate = *opts.Private
+		if err := UpdateRepository(doer, repo, true); err != nil {
+			return "", err
+		}
+
+	case api.BulkRepoAddTeam:
+		if !repo.Owner.IsOrganization() {
+			return "", errors.New("repository is not owned by an organization")
+		}
+		team, err := models.GetTeam(repo.OwnerID, opts.Team)
+		if err != nil {
+			if models.IsErrTeamNotExist(err) {
+				return "", fmt.Errorf("%s has no team %s", repo.Owner.Name, opts.Team)
+			}
+			return "", err
+		}
+		if team.IncludesAllRepositories || team.HasRepository(repo.ID) {
+			return "nothing to change", nil
+		}
+		if err := AddTeamRepository(doer, team, repo); err != nil {
+			return "", err
+		}
+		audit.RecordRepo(nil, doer, repo, models.AuditRepoTeamAdd, team.Name, "")
+
+	case api.BulkRepoDelete:
+		if err := DeleteRepository(doer, repo); err != nil {
+			return "", err
+		}
+		audit.RecordRepo(nil, doer, repo, models.AuditRepoDelete, "", "")
+		if repo.IsDeleted() {
+			return "moved to the trash", nil
+		}
+
+	default:
+		return "", models.ErrBulkRepoOptionInvalid{Action: string(opts.Action), Reason: "unknown action"}
+	}
+	return "", nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/notification"
+)
+
+// AddCollaborator adds the user as a collaborator of the repository. Unless mode is AccessModeNone
+// the collaborator gets this access mode, which also changes the access mode of an existing collaborator.
+func AddCollaborator(doer *models.User, repo *models.Repository, u *models.User, mode models.AccessMode) error {
+	isCollaborator, err := repo.IsCollaborator(u.ID)
+	if err != nil {
+		return err
+	}
+	if isCollaborator {
+		if mode == models.AccessModeNone {
+			return nil
+		}
+		return ChangeCollaborationAccessMode(doer, repo, u, mode)
+	}
+
+	if err := repo.AddCollaborator(u); err != nil {
+		return err
+	}
+	if mode != models.AccessModeNone {
+		if err := repo.ChangeCollaborationAccessMode(u.ID, mode); err != nil {
+			return err
+		}
+	}
+	notification.NotifyAddCollaborator(doer, repo, u)
+	return nil
+}
+
+// ChangeCollaborationAccessMode changes the access mode of a collaborator of the repository
+func ChangeCollaborationAccessMode(doer *models.User, repo *models.Repository, u *models.User, mode models.AccessMode) error {
+	collaboration, err := repo.GetCollaboration(u.ID)
+	if err != nil {
+		return err
+	}
+	if collaboration == nil || collaboration.Mode == mode || mode <= models.AccessModeNone || mode > models.AccessModeOwner {
+		return nil
+	}
+
+	if err := repo.ChangeCollaborationAccessMode(u.ID, mode); err != nil {
+		return err
+	}
+	notification.NotifyChangeCollaboratorAccessMode(doer, repo, u, mode)
+	return nil
+}
+
+// DeleteCollaboration removes the user from the collaborators of the repository
+func DeleteCollaboration(doer *models.User, repo *models.Repository, u *models.User) error {
+	isCollaborator, err := repo.IsCollaborator(u.ID)
+	if err != nil || !isCollaborator {
+		return err
+	}
+
+	if err := repo.DeleteCollaboration(u.ID); err != nil {
+		return err
+	}
+	notification.NotifyRemoveCollaborator(doer, repo, u)
+	return nil
+}
@@ -22,6 +22,16 @@ func GenerateRepository(doer, owner *models.User, templateRepo *models.Repositor
 		}
 	}
 
+	if opts.GitContent && !templateRepo.IsEmpty {
+		config, err := repo_module.GetTemplateConfig(templateRepo)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Variables, err = config.ResolveVariables(opts.Variables); err != nil {
+			return nil, err
+		}
+	}
+
 	var generateRepo *models.Repository
 	if err = db.WithTx(func(ctx context.Context) error {
 		generateRepo, err = repo_module.GenerateRepository(ctx, doer, owner, templateRepo, opts)
@@ -31,7 +41,7 @@ func GenerateRepository(doer, owner *models.User, templateRepo *models.Repositor
 
 		// Git Content
 		if opts.GitContent && !templateRepo.IsEmpty {
-			if err = repo_module.GenerateGitContent(ctx, templateRepo, generateRepo); err != nil {
+			if err = repo_module.GenerateGitContent(ctx, templateRepo, generateRepo, opts.Variables); err != nil {
 				return err
 			}
 		}
@@ -71,6 +81,20 @@ func GenerateRepository(doer, owner *models.User, templateRepo *models.Repositor
 			}
 		}
 
+		// Protected Branches
+		if opts.ProtectedBranches {
+			if err = models.GenerateProtectedBranches(ctx, templateRepo, generateRepo); err != nil {
+				return err
+			}
+		}
+
+		// Teams
+		if opts.Teams {
+			if err = models.GenerateTeams(ctx, templateRepo, generateRepo); err != nil {
+				return err
+			}
+		}
+
 		return nil
 	}); err != nil {
 		if generateRepo != nil && generateRepo.ID > 0 {
@@ -20,6 +20,7 @@ import (
 	"code.gitea.io/gitea/modules/repofiles"
 	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
+	mirror_service "code.gitea.io/gitea/services/mirror"
 	pull_service "code.gitea.io/gitea/services/pull"
 )
 
@@ -238,5 +239,12 @@ func pushUpdates(optsList []*repo_module.PushUpdateOptions) error {
 		return fmt.Errorf("UpdateRepositoryUpdatedTime: %v", err)
 	}
 
+	// Push the updated refs to the mirrors which sync on commit
+	refNames := make([]string, 0, len(optsList))
+	for _, opts := range optsList {
+		refNames = append(refNames, opts.RefFullName)
+	}
+	mirror_service.AddPushMirrorsOnCommitToQueue(repo.ID, refNames)
+
 	return nil
 }
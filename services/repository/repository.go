@@ -59,11 +59,31 @@ func ForkRepository(doer, u *models.User, opts models.ForkRepoOptions) (*models.
 }
 
 // DeleteRepository deletes a repository for a user or organization.
+// If a trash period is configured the repository is moved to the trash instead.
+// Either way the pull requests from its branches into other repositories are closed.
 func DeleteRepository(doer *models.User, repo *models.Repository) error {
 	if err := pull_service.CloseRepoBranchesPulls(doer, repo); err != nil {
 		log.Error("CloseRepoBranchesPulls failed: %v", err)
 	}
 
+	if cfg.Repository.TrashPeriod > 0 {
+		return models.TrashRepository(doer, repo)
+	}
+	return purgeRepository(doer, repo)
+}
+
+// PurgeRepository permanently deletes a repository, whether it is in the trash or not.
+func PurgeRepository(doer *models.User, repo *models.Repository) error {
+	// the pull requests of a repository in the trash have been closed when it was moved there
+	if !repo.IsDeleted() {
+		if err := pull_service.CloseRepoBranchesPulls(doer, repo); err != nil {
+			log.Error("CloseRepoBranchesPulls failed: %v", err)
+		}
+	}
+	return purgeRepository(doer, repo)
+}
+
+func purgeRepository(doer *models.User, repo *models.Repository) error {
 	// If the repo itself has webhooks, we need to trigger them before deleting it...
 	notification.NotifyDeleteRepository(doer, repo)
 
@@ -71,6 +91,30 @@ func DeleteRepository(doer *models.User, repo *models.Repository) error {
 	return err
 }
 
+// UpdateRepository updates a repository with all its columns,
+// visibilityChanged must be true if the visibility of the repository was changed
+func UpdateRepository(doer *models.User, repo *models.Repository, visibilityChanged bool) error {
+	if err := models.UpdateRepository(repo, visibilityChanged); err != nil {
+		return err
+	}
+	if visibilityChanged {
+		notification.NotifyChangeRepositoryVisibility(doer, repo)
+	}
+	return nil
+}
+
+// SetArchiveRepoState archives or unarchives a repository
+func SetArchiveRepoState(doer *models.User, repo *models.Repository, isArchived bool) error {
+	changed := repo.IsArchived != isArchived
+	if err := repo.SetArchiveRepoState(isArchived); err != nil {
+		return err
+	}
+	if changed {
+		notification.NotifyChangeRepositoryArchiveState(doer, repo)
+	}
+	return nil
+}
+
 // PushCreateRepo creates a repository when a new repository is pushed to an appropriate namespace
 func PushCreateRepo(authUser, owner *models.User, repoName string) (*models.Repository, error) {
 	if !authUser.IsAdmin {
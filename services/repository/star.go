@@ -0,0 +1,22 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/notification"
+)
+
+// StarRepo stars or unstars the repository for the doer, notifying only if this changes anything
+func StarRepo(doer *models.User, repo *models.Repository, star bool) error {
+	if models.IsStaring(doer.ID, repo.ID) == star {
+		return nil
+	}
+	if err := models.StarRepo(doer.ID, repo.ID, star); err != nil {
+		return err
+	}
+	notification.NotifyStarRepository(doer, repo, star)
+	return nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/notification"
+)
+
+// AddTeamRepository gives the team access to a repository of its organization
+func AddTeamRepository(doer *models.User, team *models.Team, repo *models.Repository) error {
+	has := team.HasRepository(repo.ID)
+	if err := team.AddRepository(repo); err != nil {
+		return err
+	}
+	if !has {
+		notification.NotifyAddTeamRepository(doer, team, repo)
+	}
+	return nil
+}
+
+// RemoveTeamRepository revokes the access of the team to a repository,
+// unless the team has access to all repositories of its organization
+func RemoveTeamRepository(doer *models.User, team *models.Team, repo *models.Repository) error {
+	has := team.HasRepository(repo.ID) && !team.IncludesAllRepositories
+	if err := team.RemoveRepository(repo.ID); err != nil {
+		return err
+	}
+	if has {
+		notification.NotifyRemoveTeamRepository(doer, team, repo)
+	}
+	return nil
+}
+
+// AddAllTeamRepositories gives the team access to all repositories of its organization
+func AddAllTeamRepositories(doer *models.User, team *models.Team) error {
+	before, err := teamRepositories(team)
+	if err != nil {
+		return err
+	}
+	if err := team.AddAllRepositories(); err != nil {
+		return err
+	}
+	return notifyTeamRepositoryChanges(doer, team, before)
+}
+
+// RemoveAllTeamRepositories revokes the access of the team to all repositories,
+// unless the team has access to all repositories of its organization
+func RemoveAllTeamRepositories(doer *models.User, team *models.Team) error {
+	before, err := teamRepositories(team)
+	if err != nil {
+		return err
+	}
+	if err := team.RemoveAllRepositories(); err != nil {
+		return err
+	}
+	return notifyTeamRepositoryChanges(doer, team, before)
+}
+
+// NewTeam creates a team, which gets access to all repositories of the organization if it includes them
+func NewTeam(doer *models.User, team *models.Team) error {
+	if err := models.NewTeam(team); err != nil {
+		return err
+	}
+	if !team.IncludesAllRepositories {
+		return nil
+	}
+	return notifyTeamRepositoryChanges(doer, team, nil)
+}
+
+// UpdateTeam updates a team, which gets access to all repositories of the organization if it now includes them
+func UpdateTeam(doer *models.User, team *models.Team, authChanged, includeAllChanged bool) error {
+	if !includeAllChanged {
+		return models.UpdateTeam(team, authChanged, includeAllChanged)
+	}
+
+	before, err := teamRepositories(team)
+	if err != nil {
+		return err
+	}
+	if err := models.UpdateTeam(team, authChanged, includeAllChanged); err != nil {
+		return err
+	}
+	return notifyTeamRepositoryChanges(doer, team, before)
+}
+
+// teamRepositories returns the repositories the team has access to by their id
+func teamRepositories(team *models.Team) (map[int64]*models.Repository, error) {
+	team.Repos = nil
+	if err := team.GetRepositories(&models.SearchTeamOptions{}); err != nil {
+		return nil, err
+	}
+	repos := make(map[int64]*models.Repository, len(team.Repos))
+	for _, repo := range team.Repos {
+		repos[repo.ID] = repo
+	}
+	return repos, nil
+}
+
+// notifyTeamRepositoryChanges notifies about the repositories the team got or lost access to
+func notifyTeamRepositoryChanges(doer *models.User, team *models.Team, before map[int64]*models.Repository) error {
+	after, err := teamRepositories(team)
+	if err != nil {
+		return err
+	}
+	for _, repo := range team.Repos {
+		if _, ok := before[repo.ID]; !ok {
+			notification.NotifyAddTeamRepository(doer, team, repo)
+		}
+	}
+	for id, repo := range before {
+		if _, ok := after[id]; !ok {
+			notification.NotifyRemoveTeamRepository(doer, team, repo)
+		}
+	}
+	return nil
+}
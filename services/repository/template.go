@@ -0,0 +1,253 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	repo_module "code.gitea.io/gitea/modules/repository"
+	pull_service "code.gitea.io/gitea/services/pull"
+)
+
+// templateSyncBranchPrefix is the prefix of the branches template changes are pushed to
+const templateSyncBranchPrefix = "template-sync/"
+
+// GetTemplateSyncPullRequest returns the pull request proposing template changes if it is
+// still open. If it has been merged the synced template commit of the generation advances,
+// and once it is closed the generation forgets about it.
+func GetTemplateSyncPullRequest(g *models.RepoGeneration) (*models.PullRequest, error) {
+	if g.SyncPullID == 0 {
+		return nil, nil
+	}
+
+	pr, err := models.GetPullRequestByID(g.SyncPullID)
+	if err != nil && !models.IsErrPullRequestNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err := pr.LoadIssue(); err != nil {
+			return nil, err
+		}
+		if !pr.HasMerged && !pr.Issue.IsClosed {
+			return pr, nil
+		}
+		if pr.HasMerged {
+			g.TemplateCommitID = g.SyncCommitID
+		}
+	}
+
+	g.SyncCommitID = ""
+	g.SyncPullID = 0
+	return nil, models.UpdateRepoGenerationCols(g, "template_commit_id", "sync_commit_id", "sync_pull_id")
+}
+
+func writeTemplateTree(repoPath, contentPath, indexFile string) (string, error) {
+	env := append(os.Environ(), "GIT_INDEX_FILE="+indexFile)
+	if _, err := git.NewCommand("--work-tree="+contentPath, "add", "--all").RunInDirWithEnv(repoPath, env); err != nil {
+		return "", fmt.Errorf("git add: %v", err)
+	}
+	stdout, err := git.NewCommand("write-tree").RunInDirWithEnv(repoPath, env)
+	if err != nil {
+		return "", fmt.Errorf("git write-tree: %v", err)
+	}
+	return strings.TrimSpace(stdout), nil
+}
+
+func commitTemplateTree(repoPath string, env []string, treeID, parentID, message string) (string, error) {
+	args := []string{"commit-tree", treeID}
+	if parentID != "" {
+		args = append(args, "-p", parentID)
+	}
+	if git.CheckGitVersionAtLeast("2.0.0") == nil {
+		args = append(args, "--no-gpg-sign")
+	}
+	args = append(args, "-m", message)
+
+	stdout, err := git.NewCommand(args...).RunInDirWithEnv(repoPath, env)
+	if err != nil {
+		return "", fmt.Errorf("git commit-tree: %v", err)
+	}
+	return strings.TrimSpace(stdout), nil
+}
+
+// SyncFromTemplate proposes the changes made to the template of a generated repository since
+// it was generated or last synced. The template is generated with the recorded variables both
+// at the synced template commit and at the head of the template, and the difference is applied
+// to the default branch of the repository with a three-way merge. The result is pushed to a
+// new branch and a pull request is opened. It returns nil if there is nothing to sync.
+func SyncFromTemplate(doer *models.User, repo *models.Repository) (*models.PullRequest, error) {
+	g, err := models.GetRepoGeneration(repo.ID)
+	if err != nil {
+		return nil, err
+	} else if g == nil {
+		return nil, fmt.Errorf("repository %-v was not generated from a template", repo)
+	}
+
+	if pr, err := GetTemplateSyncPullRequest(g); err != nil {
+		return nil, err
+	} else if pr != nil {
+		return nil, models.ErrPullRequestAlreadyExists{
+			ID:         pr.ID,
+			IssueID:    pr.Index,
+			HeadRepoID: pr.HeadRepoID,
+			BaseRepoID: pr.BaseRepoID,
+			HeadBranch: pr.HeadBranch,
+			BaseBranch: pr.BaseBranch,
+		}
+	}
+
+	templateRepo, err := models.GetRepositoryByID(g.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.GetOwner(); err != nil {
+		return nil, err
+	}
+
+	basePath, err := models.CreateTemporaryPath("template-sync")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := models.RemoveTemporaryPath(basePath); err != nil {
+			log.Error("Failed to remove temporary path %s: %v", basePath, err)
+		}
+	}()
+
+	variables := g.VariablesMap()
+	oldPath := filepath.Join(basePath, "old")
+	if _, err := repo_module.GenerateTemplateContent(templateRepo, repo, g.TemplateCommitID, variables, oldPath); err != nil {
+		return nil, fmt.Errorf("generate template at %s: %v", g.TemplateCommitID, err)
+	}
+	newPath := filepath.Join(basePath, "new")
+	newCommitID, err := repo_module.GenerateTemplateContent(templateRepo, repo, "", variables, newPath)
+	if err != nil {
+		return nil, fmt.Errorf("generate template: %v", err)
+	}
+	if newCommitID == g.TemplateCommitID {
+		return nil, nil
+	}
+
+	repoPath := filepath.Join(basePath, "repo")
+	if err := git.Clone(repo.RepoPath(), repoPath, git.CloneRepoOptions{
+		Shared: true,
+		Branch: repo.DefaultBranch,
+	}); err != nil {
+		return nil, fmt.Errorf("git clone: %v", err)
+	}
+
+	sig := doer.NewGitSig()
+	commitTimeStr := time.Now().Format(time.RFC3339)
+	env := append(os.Environ(),
+		"GIT_AUTHOR_NAME="+sig.Name,
+		"GIT_AUTHOR_EMAIL="+sig.Email,
+		"GIT_AUTHOR_DATE="+commitTimeStr,
+		"GIT_COMMITTER_NAME="+sig.Name,
+		"GIT_COMMITTER_EMAIL="+sig.Email,
+		"GIT_COMMITTER_DATE="+commitTimeStr,
+	)
+
+	oldTreeID, err := writeTemplateTree(repoPath, oldPath, filepath.Join(basePath, "old.index"))
+	if err != nil {
+		return nil, err
+	}
+	newTreeID, err := writeTemplateTree(repoPath, newPath, filepath.Join(basePath, "new.index"))
+	if err != nil {
+		return nil, err
+	}
+	oldTemplateCommitID, err := commitTemplateTree(repoPath, env, oldTreeID, "", "Template "+g.TemplateCommitID)
+	if err != nil {
+		return nil, err
+	}
+	newTemplateCommitID, err := commitTemplateTree(repoPath, env, newTreeID, oldTemplateCommitID, "Template "+newCommitID)
+	if err != nil {
+		return nil, err
+	}
+
+	stderr := new(bytes.Buffer)
+	if err := git.NewCommand("cherry-pick", "--no-commit", newTemplateCommitID).
+		RunInDirTimeoutEnvPipeline(env, -1, repoPath, nil, stderr); err != nil {
+		if strings.Contains(strings.ToLower(stderr.String()), "conflict") {
+			return nil, models.ErrTemplateSyncConflict{RepoID: repo.ID, CommitID: newCommitID}
+		}
+		return nil, fmt.Errorf("git cherry-pick: %v - %s", err, stderr)
+	}
+
+	headCommitID, err := git.NewCommand("rev-parse", "HEAD").RunInDir(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("git rev-parse: %v", err)
+	}
+	headCommitID = strings.TrimSpace(headCommitID)
+	headTreeID, err := git.NewCommand("rev-parse", "HEAD^{tree}").RunInDir(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("git rev-parse: %v", err)
+	}
+	treeID, err := git.NewCommand("write-tree").RunInDir(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("git write-tree: %v", err)
+	}
+
+	// The repository already contains the template changes
+	if strings.TrimSpace(treeID) == strings.TrimSpace(headTreeID) {
+		g.TemplateCommitID = newCommitID
+		return nil, models.UpdateRepoGenerationCols(g, "template_commit_id")
+	}
+
+	title := fmt.Sprintf("Sync with template %s", templateRepo.FullName())
+	message := fmt.Sprintf("%s\n\nApply the changes made to %s between %s and %s.",
+		title, templateRepo.FullName(), g.TemplateCommitID, newCommitID)
+	commitID, err := commitTemplateTree(repoPath, env, strings.TrimSpace(treeID), headCommitID, message)
+	if err != nil {
+		return nil, err
+	}
+
+	branch := templateSyncBranchPrefix + newCommitID[:10]
+	if err := git.Push(repoPath, git.PushOptions{
+		Remote: repo.RepoPath(),
+		Branch: commitID + ":refs/heads/" + branch,
+		Force:  true,
+		Env:    models.PushingEnvironment(doer, repo),
+	}); err != nil {
+		return nil, fmt.Errorf("git push: %v", err)
+	}
+
+	issue := &models.Issue{
+		RepoID:   repo.ID,
+		Title:    title,
+		PosterID: doer.ID,
+		Poster:   doer,
+		IsPull:   true,
+		Content: fmt.Sprintf("This pull request applies the changes made to the template [%s](%s) between %s and %s.",
+			templateRepo.FullName(), templateRepo.HTMLURL(), g.TemplateCommitID, newCommitID),
+	}
+	pr := &models.PullRequest{
+		HeadRepoID: repo.ID,
+		BaseRepoID: repo.ID,
+		HeadBranch: branch,
+		BaseBranch: repo.DefaultBranch,
+		HeadRepo:   repo,
+		BaseRepo:   repo,
+		MergeBase:  headCommitID,
+		Type:       models.PullRequestGitea,
+	}
+	if err := pull_service.NewPullRequest(repo, issue, nil, nil, pr, nil); err != nil {
+		return nil, err
+	}
+
+	g.SyncCommitID = newCommitID
+	g.SyncPullID = pr.ID
+	if err := models.UpdateRepoGenerationCols(g, "sync_commit_id", "sync_pull_id"); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
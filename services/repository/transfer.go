@@ -41,7 +41,7 @@ func TransferOwnership(doer, newOwner *models.User, repo *models.Repository, tea
 	}
 
 	for _, team := range teams {
-		if err := team.AddRepository(newRepo); err != nil {
+		if err := AddTeamRepository(doer, team, newRepo); err != nil {
 			return err
 		}
 	}
@@ -100,10 +100,7 @@ func StartRepositoryTransfer(doer, newOwner *models.User, repo *models.Repositor
 		return err
 	}
 	if !hasAccess {
-		if err := repo.AddCollaborator(newOwner); err != nil {
-			return err
-		}
-		if err := repo.ChangeCollaborationAccessMode(newOwner.ID, models.AccessModeRead); err != nil {
+		if err := AddCollaborator(doer, repo, newOwner, models.AccessModeRead); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,113 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/services/audit"
+)
+
+// CanManageDeletedRepository returns true if the doer may restore or permanently delete
+// a repository in the trash, i.e. the doer is a site administrator, the owner of the
+// repository or an owner of the organization that owns it.
+func CanManageDeletedRepository(doer *models.User, repo *models.Repository) (bool, error) {
+	if doer == nil {
+		return false, nil
+	}
+	if doer.IsAdmin || doer.ID == repo.OwnerID {
+		return true, nil
+	}
+	if err := repo.GetOwner(); err != nil {
+		return false, err
+	}
+	if !repo.Owner.IsOrganization() {
+		return false, nil
+	}
+	return repo.Owner.IsOwnedBy(doer.ID)
+}
+
+// RestoreRepository takes a repository out of the trash. The repositories of an
+// organization in the trash can only be restored after the organization.
+func RestoreRepository(repo *models.Repository) error {
+	if err := repo.GetOwner(); err != nil {
+		return err
+	}
+	if repo.Owner.IsDeleted() {
+		return models.ErrOrgInTrash{ID: repo.Owner.ID, Name: repo.Owner.Name}
+	}
+
+	// an owner has at most one fork of a repository
+	if repo.IsFork {
+		if fork, has := models.HasForkedRepo(repo.OwnerID, repo.ForkID); has {
+			if err := repo.GetBaseRepo(); err != nil {
+				return err
+			}
+			return models.ErrForkAlreadyExist{
+				Uname:    repo.Owner.Name,
+				RepoName: repo.BaseRepo.FullName(),
+				ForkName: fork.FullName(),
+			}
+		}
+	}
+	return models.RestoreRepository(repo)
+}
+
+// PurgeExpiredRepositories permanently deletes the repositories that have been in the trash
+// longer than the trash period. Nothing is deleted while the trash is disabled, the
+// repositories left in it stay there until they are restored or the trash is emptied.
+func PurgeExpiredRepositories(ctx context.Context) error {
+	if setting.Repository.TrashPeriod <= 0 {
+		return nil
+	}
+	deletedBefore := timeutil.TimeStamp(time.Now().Add(-setting.Repository.TrashPeriod).Unix())
+	return purgeDeletedRepositories(ctx, nil, &models.FindDeletedReposOptions{DeletedBefore: deletedBefore}, "trash period expired")
+}
+
+// EmptyTrash permanently deletes all repositories in the trash on behalf of a site administrator
+func EmptyTrash(ctx context.Context, doer *models.User) error {
+	if !doer.IsAdmin {
+		return fmt.Errorf("%s is not a site administrator", doer.Name)
+	}
+	return purgeDeletedRepositories(ctx, doer, &models.FindDeletedReposOptions{}, "trash emptied")
+}
+
+// purgeDeletedRepositories permanently deletes the repositories in the trash found by opts.
+// Without a doer they are deleted on behalf of the users who moved them to the trash.
+func purgeDeletedRepositories(ctx context.Context, doer *models.User, opts *models.FindDeletedReposOptions, reason string) error {
+	repos, _, err := models.FindDeletedRepositories(opts)
+	if err != nil {
+		return err
+	}
+	for _, repo := range repos {
+		select {
+		case <-ctx.Done():
+			return models.ErrCancelledf("before purging %s", repo.FullName())
+		default:
+		}
+
+		purger := doer
+		if purger == nil {
+			if purger, err = models.GetUserByID(repo.DeletedByID); err != nil {
+				if !models.IsErrUserNotExist(err) {
+					return err
+				}
+				purger = models.NewGhostUser()
+			}
+		}
+		if err := PurgeRepository(purger, repo); err != nil {
+			return fmt.Errorf("PurgeRepository[%d]: %v", repo.ID, err)
+		}
+		audit.RecordRepo(nil, purger, repo, models.AuditRepoPurge, "", reason)
+		log.Trace("Repository %s purged from the trash: %s", repo.FullName(), reason)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"text/template"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+const customDefaultContentType = "application/json"
+
+// CustomMeta contains the metadata of a webhook with a user defined payload
+type CustomMeta struct {
+	PayloadTemplate string `json:"payload_template"`
+	ContentType     string `json:"content_type"`
+	// Headers contains one "Name: value" header per line
+	Headers string `json:"headers"`
+}
+
+// GetCustomHook returns custom webhook metadata
+func GetCustomHook(w *models.Webhook) *CustomMeta {
+	s := &CustomMeta{}
+	if err := json.Unmarshal([]byte(w.Meta), s); err != nil {
+		log.Error("webhook.GetCustomHook(%d): %v", w.ID, err)
+	}
+	return s
+}
+
+// CustomPayload contains the rendered payload of a custom webhook
+type CustomPayload struct {
+	Body []byte
+}
+
+// JSONPayload returns the rendered payload as is, it is not necessarily JSON
+func (c *CustomPayload) JSONPayload() ([]byte, error) {
+	return c.Body, nil
+}
+
+func customTemplateFuncs(event models.HookEventType) template.FuncMap {
+	return template.FuncMap{
+		"event": func() string {
+			return string(event)
+		},
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	}
+}
+
+// ParseCustomPayloadTemplate parses the payload template of a custom webhook.
+// The template is executed with the api payload of the event as data, "event"
+// returns the name of the event and "json" encodes a value as JSON.
+func ParseCustomPayloadTemplate(text string, event models.HookEventType) (*template.Template, error) {
+	return template.New("payload").Option("missingkey=zero").Funcs(customTemplateFuncs(event)).Parse(text)
+}
+
+// ParseCustomHeaders parses the "Name: value" lines of the headers of a custom webhook
+func ParseCustomHeaders(headers string) (http.Header, error) {
+	h := make(http.Header)
+	for _, line := range strings.Split(headers, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		idx := strings.IndexByte(line, ':')
+		if idx <= 0 {
+			return nil, fmt.Errorf("invalid header line %q", line)
+		}
+		name := strings.TrimSpace(line[:idx])
+		if strings.ContainsAny(name, " \t\"(),/;<=>?@[\\]{}") {
+			return nil, fmt.Errorf("invalid header name %q", name)
+		}
+		h.Add(textproto.CanonicalMIMEHeaderKey(name), strings.TrimSpace(line[idx+1:]))
+	}
+	return h, nil
+}
+
+// GetCustomPayload renders the payload template of a custom webhook
+func GetCustomPayload(p api.Payloader, event models.HookEventType, meta string) (api.Payloader, error) {
+	custom := &CustomMeta{}
+	if err := json.Unmarshal([]byte(meta), &custom); err != nil {
+		return nil, errors.New("GetCustomPayload meta json:" + err.Error())
+	}
+
+	tpl, err := ParseCustomPayloadTemplate(custom.PayloadTemplate, event)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, p); err != nil {
+		return nil, err
+	}
+	return &CustomPayload{Body: buf.Bytes()}, nil
+}
+
+// getCustomHookRequest sends the rendered payload with the configured content type and headers
+func getCustomHookRequest(w *models.Webhook, t *models.HookTask) (*http.Request, error) {
+	custom := GetCustomHook(w)
+
+	headers, err := ParseCustomHeaders(custom.Headers)
+	if err != nil {
+		return nil, err
+	}
+
+	method := w.HTTPMethod
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	req, err := http.NewRequest(method, w.URL, strings.NewReader(t.PayloadContent))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+
+	contentType := custom.ContentType
+	if contentType == "" {
+		contentType = customDefaultContentType
+	}
+	req.Header.Set("Content-Type", contentType)
+	return req, nil
+}
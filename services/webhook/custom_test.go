@@ -0,0 +1,91 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"io"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/json"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func customTestMeta(t *testing.T, meta *CustomMeta) string {
+	data, err := json.Marshal(meta)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestCustomPayload(t *testing.T) {
+	p := pushTestPayload()
+
+	meta := customTestMeta(t, &CustomMeta{
+		PayloadTemplate: `{"event":{{json event}},"text":{{json (printf "%s pushed %d commits" .Pusher.UserName (len .Commits))}}}`,
+	})
+	pl, err := GetCustomPayload(p, models.HookEventPush, meta)
+	require.NoError(t, err)
+	require.IsType(t, &CustomPayload{}, pl)
+
+	data, err := pl.JSONPayload()
+	require.NoError(t, err)
+	assert.Equal(t, `{"event":"push","text":"user1 pushed 2 commits"}`, string(data))
+
+	t.Run("InvalidTemplate", func(t *testing.T) {
+		_, err := ParseCustomPayloadTemplate(`{{.Ref`, models.HookEventPush)
+		assert.Error(t, err)
+	})
+
+	t.Run("ExecutionError", func(t *testing.T) {
+		meta := customTestMeta(t, &CustomMeta{PayloadTemplate: `{{.NoSuchField}}`})
+		_, err := GetCustomPayload(p, models.HookEventPush, meta)
+		assert.Error(t, err)
+	})
+}
+
+func TestParseCustomHeaders(t *testing.T) {
+	headers, err := ParseCustomHeaders("authorization: Bearer token\n\nX-Custom:  a:b \r\nX-Custom: c")
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer token", headers.Get("Authorization"))
+	assert.Equal(t, []string{"a:b", "c"}, headers.Values("X-Custom"))
+
+	_, err = ParseCustomHeaders("no colon")
+	assert.Error(t, err)
+	_, err = ParseCustomHeaders(": value")
+	assert.Error(t, err)
+	_, err = ParseCustomHeaders("Bad Name: value")
+	assert.Error(t, err)
+}
+
+func TestCustomHookRequest(t *testing.T) {
+	w := &models.Webhook{
+		URL:        "https://example.com/hook",
+		HTTPMethod: "PUT",
+		Type:       models.CUSTOM,
+		Meta: customTestMeta(t, &CustomMeta{
+			ContentType: "text/plain",
+			Headers:     "X-Token: secret",
+		}),
+	}
+	task := &models.HookTask{PayloadContent: "user1 pushed 2 commits"}
+
+	req, err := getCustomHookRequest(w, task)
+	require.NoError(t, err)
+	assert.Equal(t, "PUT", req.Method)
+	assert.Equal(t, "text/plain", req.Header.Get("Content-Type"))
+	assert.Equal(t, "secret", req.Header.Get("X-Token"))
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "user1 pushed 2 commits", string(body))
+
+	w.HTTPMethod = ""
+	w.Meta = customTestMeta(t, &CustomMeta{})
+	req, err = getCustomHookRequest(w, task)
+	require.NoError(t, err)
+	assert.Equal(t, "POST", req.Method)
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+}
@@ -39,6 +39,13 @@ func Deliver(t *models.HookTask) error {
 		return err
 	}
 
+	if t.Attempts > 0 && !w.IsActive {
+		// the webhook has been deactivated while the task was waiting for a retry
+		log.Trace("Dropping retry of hook task %d of inactive webhook %d", t.ID, w.ID)
+		t.IsDelivered = true
+		return models.UpdateHookTask(t)
+	}
+
 	defer func() {
 		err := recover()
 		if err == nil {
@@ -57,15 +64,25 @@ func Deliver(t *models.HookTask) error {
 		log.Info("HTTP Method for webhook %d empty, setting to POST as default", t.ID)
 		fallthrough
 	case http.MethodPost:
-		switch w.ContentType {
-		case models.ContentTypeJSON:
+		switch {
+		case w.Type == models.CUSTOM:
+			req, err = getCustomHookRequest(w, t)
+			if err != nil {
+				return err
+			}
+		case w.Type == models.NTFY:
+			req, err = getNtfyHookRequest(w, t)
+			if err != nil {
+				return err
+			}
+		case w.ContentType == models.ContentTypeJSON:
 			req, err = http.NewRequest("POST", w.URL, strings.NewReader(t.PayloadContent))
 			if err != nil {
 				return err
 			}
 
 			req.Header.Set("Content-Type", "application/json")
-		case models.ContentTypeForm:
+		case w.ContentType == models.ContentTypeForm:
 			var forms = url.Values{
 				"payload": []string{t.PayloadContent},
 			}
@@ -96,6 +113,11 @@ func Deliver(t *models.HookTask) error {
 			if err != nil {
 				return err
 			}
+		case models.CUSTOM:
+			req, err = getCustomHookRequest(w, t)
+			if err != nil {
+				return err
+			}
 		default:
 			return fmt.Errorf("Invalid http method for webhook: [%d] %v", t.ID, w.HTTPMethod)
 		}
@@ -148,10 +170,12 @@ func Deliver(t *models.HookTask) error {
 
 	defer func() {
 		t.Delivered = time.Now().UnixNano()
+		t.Attempts++
 		if t.IsSucceed {
 			log.Trace("Hook delivered: %s", t.UUID)
 		} else {
 			log.Trace("Hook delivery failed: %s", t.UUID)
+			scheduleRetry(t)
 		}
 
 		if err := models.UpdateHookTask(t); err != nil {
@@ -161,13 +185,19 @@ func Deliver(t *models.HookTask) error {
 		// Update webhook last delivery status.
 		if t.IsSucceed {
 			w.LastStatus = models.HookStatusSucceed
+			w.FailureCount = 0
 		} else {
 			w.LastStatus = models.HookStatusFail
+			w.FailureCount++
 		}
 		if err = models.UpdateWebhookLastStatus(w); err != nil {
 			log.Error("UpdateWebhookLastStatus: %v", err)
 			return
 		}
+
+		if !t.IsSucceed && w.IsActive && setting.Webhook.DisableAfter > 0 && w.FailureCount >= setting.Webhook.DisableAfter {
+			deactivateFailingWebhook(w)
+		}
 	}()
 
 	if setting.DisableWebhooks {
@@ -224,12 +254,17 @@ func DeliverHooks(ctx context.Context) {
 		}
 	}
 
+	retryTicker := time.NewTicker(retryCheckInterval)
+	defer retryTicker.Stop()
+
 	// Start listening on new hook requests.
 	for {
 		select {
 		case <-ctx.Done():
 			hookQueue.Close()
 			return
+		case <-retryTicker.C:
+			queueDueRetries()
 		case repoIDStr := <-hookQueue.Queue():
 			log.Trace("DeliverHooks [repo_id: %v]", repoIDStr)
 			hookQueue.Remove(repoIDStr)
@@ -291,8 +326,8 @@ func webhookProxy() func(req *http.Request) (*url.URL, error) {
 	}
 }
 
-// InitDeliverHooks starts the hooks delivery thread
-func InitDeliverHooks() {
+// InitDeliverHooks starts the hooks delivery thread and the retry queue
+func InitDeliverHooks() error {
 	timeout := time.Duration(setting.Webhook.DeliverTimeout) * time.Second
 
 	webhookHTTPClient = &http.Client{
@@ -321,5 +356,10 @@ func InitDeliverHooks() {
 		},
 	}
 
+	if err := initRetryQueue(); err != nil {
+		return err
+	}
+
 	go graceful.GetManager().RunWithShutdownContext(DeliverHooks)
+	return nil
 }
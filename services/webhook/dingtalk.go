@@ -158,7 +158,9 @@ func (d *DingtalkPayload) Repository(p *api.RepositoryPayload) (api.Payloader, e
 		}, nil
 	}
 
-	return nil, nil
+	text, _ := getRepositoryPayloadInfo(p, noneLinkFormatter, true)
+
+	return createDingtalkPayload(text, text, "view repository", p.Repository.HTMLURL), nil
 }
 
 // Release implements PayloadConvertor Release method
@@ -168,6 +170,62 @@ func (d *DingtalkPayload) Release(p *api.ReleasePayload) (api.Payloader, error)
 	return createDingtalkPayload(text, text, "view release", p.Release.URL), nil
 }
 
+// Wiki implements PayloadConvertor Wiki method
+func (d *DingtalkPayload) Wiki(p *api.WikiPayload) (api.Payloader, error) {
+	text, link, _ := getWikiPayloadInfo(p, noneLinkFormatter, true)
+
+	return createDingtalkPayload(text, text, "view wiki page", link), nil
+}
+
+// Status implements PayloadConvertor Status method
+func (d *DingtalkPayload) Status(p *api.CommitStatusPayload) (api.Payloader, error) {
+	text, link, _ := getCommitStatusPayloadInfo(p, noneLinkFormatter, true)
+
+	return createDingtalkPayload(text, text, "view status", link), nil
+}
+
+// Member implements PayloadConvertor Member method
+func (d *DingtalkPayload) Member(p *api.MemberPayload) (api.Payloader, error) {
+	text, link, _ := getMemberPayloadInfo(p, noneLinkFormatter, true)
+
+	return createDingtalkPayload(text, text, "view collaborators", link), nil
+}
+
+// BranchProtection implements PayloadConvertor BranchProtection method
+func (d *DingtalkPayload) BranchProtection(p *api.BranchProtectionPayload) (api.Payloader, error) {
+	text, link, _ := getBranchProtectionPayloadInfo(p, noneLinkFormatter, true)
+
+	return createDingtalkPayload(text, text, "view branch protections", link), nil
+}
+
+// Star implements PayloadConvertor Star method
+func (d *DingtalkPayload) Star(p *api.StarPayload) (api.Payloader, error) {
+	text, link, _ := getStarPayloadInfo(p, noneLinkFormatter, true)
+
+	return createDingtalkPayload(text, text, "view repository", link), nil
+}
+
+// User implements PayloadConvertor User method
+func (d *DingtalkPayload) User(p *api.UserPayload) (api.Payloader, error) {
+	text, link, _ := getUserPayloadInfo(p, noneLinkFormatter, true)
+
+	return createDingtalkPayload(text, text, "view user", link), nil
+}
+
+// Organization implements PayloadConvertor Organization method
+func (d *DingtalkPayload) Organization(p *api.OrganizationPayload) (api.Payloader, error) {
+	text, link, _ := getOrganizationPayloadInfo(p, noneLinkFormatter, true)
+
+	return createDingtalkPayload(text, text, "view organization", link), nil
+}
+
+// LoginFailed implements PayloadConvertor LoginFailed method
+func (d *DingtalkPayload) LoginFailed(p *api.LoginFailedPayload) (api.Payloader, error) {
+	text, link, _ := getLoginFailedPayloadInfo(p)
+
+	return createDingtalkPayload(text, text, "view authentication sources", link), nil
+}
+
 func createDingtalkPayload(title, text, singleTitle, singleURL string) *DingtalkPayload {
 	return &DingtalkPayload{
 		MsgType: "actionCard",
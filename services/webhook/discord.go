@@ -231,6 +231,9 @@ func (d *DiscordPayload) Repository(p *api.RepositoryPayload) (api.Payloader, er
 	case api.HookRepoDeleted:
 		title = fmt.Sprintf("[%s] Repository deleted", p.Repository.FullName)
 		color = redColor
+	default:
+		title, color = getRepositoryPayloadInfo(p, noneLinkFormatter, false)
+		url = p.Repository.HTMLURL
 	}
 
 	return d.createPayload(p.Sender, title, "", url, color), nil
@@ -243,6 +246,62 @@ func (d *DiscordPayload) Release(p *api.ReleasePayload) (api.Payloader, error) {
 	return d.createPayload(p.Sender, text, p.Release.Note, p.Release.URL, color), nil
 }
 
+// Wiki implements PayloadConvertor Wiki method
+func (d *DiscordPayload) Wiki(p *api.WikiPayload) (api.Payloader, error) {
+	text, link, color := getWikiPayloadInfo(p, noneLinkFormatter, false)
+
+	return d.createPayload(p.Sender, text, p.Comment, link, color), nil
+}
+
+// Status implements PayloadConvertor Status method
+func (d *DiscordPayload) Status(p *api.CommitStatusPayload) (api.Payloader, error) {
+	text, link, color := getCommitStatusPayloadInfo(p, noneLinkFormatter, false)
+
+	return d.createPayload(p.Sender, text, p.Description, link, color), nil
+}
+
+// Member implements PayloadConvertor Member method
+func (d *DiscordPayload) Member(p *api.MemberPayload) (api.Payloader, error) {
+	text, link, color := getMemberPayloadInfo(p, noneLinkFormatter, false)
+
+	return d.createPayload(p.Sender, text, "", link, color), nil
+}
+
+// BranchProtection implements PayloadConvertor BranchProtection method
+func (d *DiscordPayload) BranchProtection(p *api.BranchProtectionPayload) (api.Payloader, error) {
+	text, link, color := getBranchProtectionPayloadInfo(p, noneLinkFormatter, false)
+
+	return d.createPayload(p.Sender, text, "", link, color), nil
+}
+
+// Star implements PayloadConvertor Star method
+func (d *DiscordPayload) Star(p *api.StarPayload) (api.Payloader, error) {
+	text, link, color := getStarPayloadInfo(p, noneLinkFormatter, false)
+
+	return d.createPayload(p.Sender, text, "", link, color), nil
+}
+
+// User implements PayloadConvertor User method
+func (d *DiscordPayload) User(p *api.UserPayload) (api.Payloader, error) {
+	text, link, color := getUserPayloadInfo(p, noneLinkFormatter, false)
+
+	return d.createPayload(p.Sender, text, "", link, color), nil
+}
+
+// Organization implements PayloadConvertor Organization method
+func (d *DiscordPayload) Organization(p *api.OrganizationPayload) (api.Payloader, error) {
+	text, link, color := getOrganizationPayloadInfo(p, noneLinkFormatter, false)
+
+	return d.createPayload(p.Sender, text, "", link, color), nil
+}
+
+// LoginFailed implements PayloadConvertor LoginFailed method
+func (d *DiscordPayload) LoginFailed(p *api.LoginFailedPayload) (api.Payloader, error) {
+	text, link, color := getLoginFailedPayloadInfo(p)
+
+	return d.createPayload(nil, text, p.Reason, link, color), nil
+}
+
 // GetDiscordPayload converts a discord webhook into a DiscordPayload
 func GetDiscordPayload(p api.Payloader, event models.HookEventType, meta string) (api.Payloader, error) {
 	s := new(DiscordPayload)
@@ -273,6 +332,16 @@ func parseHookPullRequestEventType(event models.HookEventType) (string, error) {
 }
 
 func (d *DiscordPayload) createPayload(s *api.User, title, text, url string, color int) *DiscordPayload {
+	// instance events like failed sign in attempts have no sender
+	var author DiscordEmbedAuthor
+	if s != nil {
+		author = DiscordEmbedAuthor{
+			Name:    s.UserName,
+			URL:     setting.AppURL + s.UserName,
+			IconURL: s.AvatarURL,
+		}
+	}
+
 	return &DiscordPayload{
 		Username:  d.Username,
 		AvatarURL: d.AvatarURL,
@@ -282,11 +351,7 @@ func (d *DiscordPayload) createPayload(s *api.User, title, text, url string, col
 				Description: text,
 				URL:         url,
 				Color:       color,
-				Author: DiscordEmbedAuthor{
-					Name:    s.UserName,
-					URL:     setting.AppURL + s.UserName,
-					IconURL: s.AvatarURL,
-				},
+				Author:      author,
 			},
 		},
 	}
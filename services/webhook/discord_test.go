@@ -229,6 +229,69 @@ func TestDiscordPayload(t *testing.T) {
 		assert.Equal(t, setting.AppURL+p.Sender.UserName, pl.(*DiscordPayload).Embeds[0].Author.URL)
 		assert.Equal(t, p.Sender.AvatarURL, pl.(*DiscordPayload).Embeds[0].Author.IconURL)
 	})
+
+	t.Run("Wiki", func(t *testing.T) {
+		p := wikiTestPayload()
+
+		d := new(DiscordPayload)
+		pl, err := d.Wiki(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &DiscordPayload{}, pl)
+
+		assert.Len(t, pl.(*DiscordPayload).Embeds, 1)
+		assert.Equal(t, "[test/repo] Wiki page created: Getting Started", pl.(*DiscordPayload).Embeds[0].Title)
+		assert.Equal(t, "first steps", pl.(*DiscordPayload).Embeds[0].Description)
+		assert.Equal(t, "http://localhost:3000/test/repo/wiki/Getting-Started", pl.(*DiscordPayload).Embeds[0].URL)
+		assert.Equal(t, greenColor, pl.(*DiscordPayload).Embeds[0].Color)
+	})
+
+	t.Run("Status", func(t *testing.T) {
+		p := commitStatusTestPayload()
+
+		d := new(DiscordPayload)
+		pl, err := d.Status(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &DiscordPayload{}, pl)
+
+		assert.Len(t, pl.(*DiscordPayload).Embeds, 1)
+		assert.Equal(t, "[test/repo] Commit status success: ci/build 2020558fe2", pl.(*DiscordPayload).Embeds[0].Title)
+		assert.Equal(t, "build passed", pl.(*DiscordPayload).Embeds[0].Description)
+		assert.Equal(t, "http://ci.example.com/build/1", pl.(*DiscordPayload).Embeds[0].URL)
+	})
+
+	t.Run("RepositoryArchived", func(t *testing.T) {
+		p := repositoryTestPayload()
+		p.Action = api.HookRepoArchived
+
+		d := new(DiscordPayload)
+		pl, err := d.Repository(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &DiscordPayload{}, pl)
+
+		assert.Len(t, pl.(*DiscordPayload).Embeds, 1)
+		assert.Equal(t, "[test/repo] Repository archived", pl.(*DiscordPayload).Embeds[0].Title)
+		assert.Equal(t, "http://localhost:3000/test/repo", pl.(*DiscordPayload).Embeds[0].URL)
+		assert.Equal(t, greyColor, pl.(*DiscordPayload).Embeds[0].Color)
+	})
+
+	t.Run("LoginFailed", func(t *testing.T) {
+		p := loginFailedTestPayload()
+
+		d := new(DiscordPayload)
+		pl, err := d.LoginFailed(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &DiscordPayload{}, pl)
+
+		assert.Len(t, pl.(*DiscordPayload).Embeds, 1)
+		assert.Equal(t, "Failed sign in attempt for user2 from 127.0.0.1", pl.(*DiscordPayload).Embeds[0].Title)
+		assert.Equal(t, "invalid credentials", pl.(*DiscordPayload).Embeds[0].Description)
+		assert.Empty(t, pl.(*DiscordPayload).Embeds[0].Author.Name)
+		assert.Equal(t, redColor, pl.(*DiscordPayload).Embeds[0].Color)
+	})
 }
 
 func TestDiscordJSONPayload(t *testing.T) {
@@ -144,7 +144,8 @@ func (f *FeishuPayload) Repository(p *api.RepositoryPayload) (api.Payloader, err
 		return newFeishuTextPayload(text), nil
 	}
 
-	return nil, nil
+	text, _ = getRepositoryPayloadInfo(p, noneLinkFormatter, true)
+	return newFeishuTextPayload(text), nil
 }
 
 // Release implements PayloadConvertor Release method
@@ -154,6 +155,62 @@ func (f *FeishuPayload) Release(p *api.ReleasePayload) (api.Payloader, error) {
 	return newFeishuTextPayload(text), nil
 }
 
+// Wiki implements PayloadConvertor Wiki method
+func (f *FeishuPayload) Wiki(p *api.WikiPayload) (api.Payloader, error) {
+	text, _, _ := getWikiPayloadInfo(p, noneLinkFormatter, true)
+
+	return newFeishuTextPayload(text), nil
+}
+
+// Status implements PayloadConvertor Status method
+func (f *FeishuPayload) Status(p *api.CommitStatusPayload) (api.Payloader, error) {
+	text, _, _ := getCommitStatusPayloadInfo(p, noneLinkFormatter, true)
+
+	return newFeishuTextPayload(text), nil
+}
+
+// Member implements PayloadConvertor Member method
+func (f *FeishuPayload) Member(p *api.MemberPayload) (api.Payloader, error) {
+	text, _, _ := getMemberPayloadInfo(p, noneLinkFormatter, true)
+
+	return newFeishuTextPayload(text), nil
+}
+
+// BranchProtection implements PayloadConvertor BranchProtection method
+func (f *FeishuPayload) BranchProtection(p *api.BranchProtectionPayload) (api.Payloader, error) {
+	text, _, _ := getBranchProtectionPayloadInfo(p, noneLinkFormatter, true)
+
+	return newFeishuTextPayload(text), nil
+}
+
+// Star implements PayloadConvertor Star method
+func (f *FeishuPayload) Star(p *api.StarPayload) (api.Payloader, error) {
+	text, _, _ := getStarPayloadInfo(p, noneLinkFormatter, true)
+
+	return newFeishuTextPayload(text), nil
+}
+
+// User implements PayloadConvertor User method
+func (f *FeishuPayload) User(p *api.UserPayload) (api.Payloader, error) {
+	text, _, _ := getUserPayloadInfo(p, noneLinkFormatter, true)
+
+	return newFeishuTextPayload(text), nil
+}
+
+// Organization implements PayloadConvertor Organization method
+func (f *FeishuPayload) Organization(p *api.OrganizationPayload) (api.Payloader, error) {
+	text, _, _ := getOrganizationPayloadInfo(p, noneLinkFormatter, true)
+
+	return newFeishuTextPayload(text), nil
+}
+
+// LoginFailed implements PayloadConvertor LoginFailed method
+func (f *FeishuPayload) LoginFailed(p *api.LoginFailedPayload) (api.Payloader, error) {
+	text, _, _ := getLoginFailedPayloadInfo(p)
+
+	return newFeishuTextPayload(text), nil
+}
+
 // GetFeishuPayload converts a ding talk webhook into a FeishuPayload
 func GetFeishuPayload(p api.Payloader, event models.HookEventType, meta string) (api.Payloader, error) {
 	return convertPayloader(new(FeishuPayload), p, event)
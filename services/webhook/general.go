@@ -7,6 +7,7 @@ package webhook
 import (
 	"fmt"
 	"html"
+	"net/url"
 	"strings"
 
 	"code.gitea.io/gitea/modules/setting"
@@ -194,3 +195,201 @@ func getIssueCommentPayloadInfo(p *api.IssueCommentPayload, linkFormatter linkFo
 
 	return text, issueTitle, color
 }
+
+func senderSuffix(sender *api.User, linkFormatter linkFormatter) string {
+	return fmt.Sprintf(" by %s", linkFormatter(setting.AppURL+sender.UserName, sender.UserName))
+}
+
+func getRepositoryPayloadInfo(p *api.RepositoryPayload, linkFormatter linkFormatter, withSender bool) (text string, color int) {
+	repoLink := linkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+
+	switch p.Action {
+	case api.HookRepoCreated:
+		text = fmt.Sprintf("[%s] Repository created", repoLink)
+		color = greenColor
+	case api.HookRepoDeleted:
+		text = fmt.Sprintf("[%s] Repository deleted", repoLink)
+		color = redColor
+	case api.HookRepoPublicized:
+		text = fmt.Sprintf("[%s] Repository made public", repoLink)
+		color = yellowColor
+	case api.HookRepoPrivatized:
+		text = fmt.Sprintf("[%s] Repository made private", repoLink)
+		color = yellowColor
+	case api.HookRepoArchived:
+		text = fmt.Sprintf("[%s] Repository archived", repoLink)
+		color = greyColor
+	case api.HookRepoUnarchived:
+		text = fmt.Sprintf("[%s] Repository unarchived", repoLink)
+		color = greenColor
+	case api.HookRepoTransferred:
+		text = fmt.Sprintf("[%s] Repository transferred from %s", repoLink, p.PreviousOwner)
+		color = yellowColor
+	}
+	if withSender {
+		text += senderSuffix(p.Sender, linkFormatter)
+	}
+
+	return text, color
+}
+
+func getWikiPayloadInfo(p *api.WikiPayload, linkFormatter linkFormatter, withSender bool) (text, link string, color int) {
+	repoLink := linkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	link = p.Repository.HTMLURL + "/wiki/" + url.QueryEscape(strings.ReplaceAll(p.Page, " ", "-"))
+	pageLink := linkFormatter(link, p.Page)
+
+	switch p.Action {
+	case api.HookWikiCreated:
+		text = fmt.Sprintf("[%s] Wiki page created: %s", repoLink, pageLink)
+		color = greenColor
+	case api.HookWikiEdited:
+		text = fmt.Sprintf("[%s] Wiki page edited: %s", repoLink, pageLink)
+		color = yellowColor
+	case api.HookWikiDeleted:
+		text = fmt.Sprintf("[%s] Wiki page deleted: %s", repoLink, p.Page)
+		link = p.Repository.HTMLURL + "/wiki"
+		color = redColor
+	}
+	if withSender {
+		text += senderSuffix(p.Sender, linkFormatter)
+	}
+
+	return text, link, color
+}
+
+func getCommitStatusPayloadInfo(p *api.CommitStatusPayload, linkFormatter linkFormatter, withSender bool) (text, link string, color int) {
+	repoLink := linkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	shortSHA := p.SHA
+	if len(shortSHA) > 10 {
+		shortSHA = shortSHA[:10]
+	}
+	commitLink := linkFormatter(p.Repository.HTMLURL+"/commit/"+p.SHA, shortSHA)
+
+	text = fmt.Sprintf("[%s] Commit status %s: %s %s", repoLink, p.State, p.Context, commitLink)
+	switch p.State {
+	case api.CommitStatusSuccess:
+		color = greenColor
+	case api.CommitStatusPending:
+		color = yellowColor
+	case api.CommitStatusWarning:
+		color = orangeColor
+	default:
+		color = redColor
+	}
+	if withSender {
+		text += senderSuffix(p.Sender, linkFormatter)
+	}
+
+	link = p.TargetURL
+	if link == "" {
+		link = p.Repository.HTMLURL + "/commit/" + p.SHA
+	}
+	return text, link, color
+}
+
+func getMemberPayloadInfo(p *api.MemberPayload, linkFormatter linkFormatter, withSender bool) (text, link string, color int) {
+	repoLink := linkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	var member string
+	if p.Member != nil {
+		member = "Collaborator " + linkFormatter(setting.AppURL+p.Member.UserName, p.Member.UserName)
+	} else if p.Team != nil {
+		member = "Team " + p.Team.Name
+	}
+
+	switch p.Action {
+	case api.HookMemberAdded:
+		text = fmt.Sprintf("[%s] %s added with %s access", repoLink, member, p.Permission)
+		color = greenColor
+	case api.HookMemberEdited:
+		text = fmt.Sprintf("[%s] %s access changed to %s", repoLink, member, p.Permission)
+		color = yellowColor
+	case api.HookMemberRemoved:
+		text = fmt.Sprintf("[%s] %s removed", repoLink, member)
+		color = redColor
+	}
+	if withSender {
+		text += senderSuffix(p.Sender, linkFormatter)
+	}
+
+	return text, p.Repository.HTMLURL + "/settings/collaboration", color
+}
+
+func getBranchProtectionPayloadInfo(p *api.BranchProtectionPayload, linkFormatter linkFormatter, withSender bool) (text, link string, color int) {
+	repoLink := linkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+
+	switch p.Action {
+	case api.HookBranchProtectionCreated:
+		text = fmt.Sprintf("[%s] Branch protection created for %s", repoLink, p.Rule.BranchName)
+		color = greenColor
+	case api.HookBranchProtectionEdited:
+		text = fmt.Sprintf("[%s] Branch protection edited for %s", repoLink, p.Rule.BranchName)
+		color = yellowColor
+	case api.HookBranchProtectionDeleted:
+		text = fmt.Sprintf("[%s] Branch protection deleted for %s", repoLink, p.Rule.BranchName)
+		color = redColor
+	}
+	if withSender {
+		text += senderSuffix(p.Sender, linkFormatter)
+	}
+
+	return text, p.Repository.HTMLURL + "/settings/branches", color
+}
+
+func getStarPayloadInfo(p *api.StarPayload, linkFormatter linkFormatter, withSender bool) (text, link string, color int) {
+	repoLink := linkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+
+	switch p.Action {
+	case api.HookStarCreated:
+		text = fmt.Sprintf("[%s] Repository starred", repoLink)
+		color = yellowColor
+	case api.HookStarDeleted:
+		text = fmt.Sprintf("[%s] Repository unstarred", repoLink)
+		color = greyColor
+	}
+	if withSender {
+		text += senderSuffix(p.Sender, linkFormatter)
+	}
+
+	return text, p.Repository.HTMLURL, color
+}
+
+func getUserPayloadInfo(p *api.UserPayload, linkFormatter linkFormatter, withSender bool) (text, link string, color int) {
+	switch p.Action {
+	case api.HookUserCreated:
+		link = setting.AppURL + p.User.UserName
+		text = fmt.Sprintf("User %s created", linkFormatter(link, p.User.UserName))
+		color = greenColor
+	case api.HookUserDeleted:
+		link = setting.AppURL + "admin/users"
+		text = fmt.Sprintf("User %s deleted", p.User.UserName)
+		color = redColor
+	}
+	if withSender {
+		text += senderSuffix(p.Sender, linkFormatter)
+	}
+
+	return text, link, color
+}
+
+func getOrganizationPayloadInfo(p *api.OrganizationPayload, linkFormatter linkFormatter, withSender bool) (text, link string, color int) {
+	switch p.Action {
+	case api.HookOrganizationCreated:
+		link = setting.AppURL + p.Organization.UserName
+		text = fmt.Sprintf("Organization %s created", linkFormatter(link, p.Organization.UserName))
+		color = greenColor
+	case api.HookOrganizationDeleted:
+		link = setting.AppURL + "admin/orgs"
+		text = fmt.Sprintf("Organization %s deleted", p.Organization.UserName)
+		color = redColor
+	}
+	if withSender {
+		text += senderSuffix(p.Sender, linkFormatter)
+	}
+
+	return text, link, color
+}
+
+func getLoginFailedPayloadInfo(p *api.LoginFailedPayload) (text, link string, color int) {
+	text = fmt.Sprintf("Failed sign in attempt for %s from %s", p.LoginName, p.RemoteAddress)
+	return text, setting.AppURL + "admin/auths", redColor
+}
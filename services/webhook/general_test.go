@@ -7,6 +7,7 @@ package webhook
 import (
 	"testing"
 
+	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 
 	"github.com/stretchr/testify/assert"
@@ -271,6 +272,98 @@ func repositoryTestPayload() *api.RepositoryPayload {
 	}
 }
 
+func testRepo() *api.Repository {
+	return &api.Repository{
+		HTMLURL:  "http://localhost:3000/test/repo",
+		Name:     "repo",
+		FullName: "test/repo",
+	}
+}
+
+func testSender() *api.User {
+	return &api.User{
+		UserName:  "user1",
+		AvatarURL: "http://localhost:3000/user1/avatar",
+	}
+}
+
+func wikiTestPayload() *api.WikiPayload {
+	return &api.WikiPayload{
+		Action:     api.HookWikiCreated,
+		Repository: testRepo(),
+		Sender:     testSender(),
+		Page:       "Getting Started",
+		Comment:    "first steps",
+	}
+}
+
+func commitStatusTestPayload() *api.CommitStatusPayload {
+	return &api.CommitStatusPayload{
+		SHA:         "2020558fe2e34debb818a514715839cabd25e778",
+		State:       api.CommitStatusSuccess,
+		Context:     "ci/build",
+		Description: "build passed",
+		TargetURL:   "http://ci.example.com/build/1",
+		Repository:  testRepo(),
+		Sender:      testSender(),
+	}
+}
+
+func memberTestPayload() *api.MemberPayload {
+	return &api.MemberPayload{
+		Action: api.HookMemberAdded,
+		Member: &api.User{
+			UserName: "user2",
+		},
+		Permission: "write",
+		Repository: testRepo(),
+		Sender:     testSender(),
+	}
+}
+
+func branchProtectionTestPayload() *api.BranchProtectionPayload {
+	return &api.BranchProtectionPayload{
+		Action: api.HookBranchProtectionCreated,
+		Rule: &api.BranchProtection{
+			BranchName: "master",
+		},
+		Repository: testRepo(),
+		Sender:     testSender(),
+	}
+}
+
+func starTestPayload() *api.StarPayload {
+	return &api.StarPayload{
+		Action:     api.HookStarCreated,
+		Repository: testRepo(),
+		Sender:     testSender(),
+	}
+}
+
+func userTestPayload() *api.UserPayload {
+	return &api.UserPayload{
+		Action: api.HookUserCreated,
+		User:   &api.User{UserName: "user2"},
+		Sender: testSender(),
+	}
+}
+
+func organizationTestPayload() *api.OrganizationPayload {
+	return &api.OrganizationPayload{
+		Action:       api.HookOrganizationCreated,
+		Organization: &api.Organization{UserName: "org3"},
+		Sender:       testSender(),
+	}
+}
+
+func loginFailedTestPayload() *api.LoginFailedPayload {
+	return &api.LoginFailedPayload{
+		LoginName:     "user2",
+		RemoteAddress: "127.0.0.1",
+		Reason:        "invalid credentials",
+	}
+}
+
 func TestGetIssuesPayloadInfo(t *testing.T) {
 	p := issueTestPayload()
 
@@ -539,3 +632,215 @@ func TestGetIssueCommentPayloadInfo(t *testing.T) {
 		assert.Equal(t, c.color, color, "case %d", i)
 	}
 }
+
+func TestGetRepositoryPayloadInfo(t *testing.T) {
+	p := repositoryTestPayload()
+
+	cases := []struct {
+		action api.HookRepoAction
+		text   string
+		color  int
+	}{
+		{api.HookRepoPublicized, "[test/repo] Repository made public by user1", yellowColor},
+		{api.HookRepoPrivatized, "[test/repo] Repository made private by user1", yellowColor},
+		{api.HookRepoArchived, "[test/repo] Repository archived by user1", greyColor},
+		{api.HookRepoUnarchived, "[test/repo] Repository unarchived by user1", greenColor},
+		{api.HookRepoTransferred, "[test/repo] Repository transferred from user2 by user1", yellowColor},
+	}
+
+	p.PreviousOwner = "user2"
+	for i, c := range cases {
+		p.Action = c.action
+		text, color := getRepositoryPayloadInfo(p, noneLinkFormatter, true)
+		assert.Equal(t, c.text, text, "case %d", i)
+		assert.Equal(t, c.color, color, "case %d", i)
+	}
+}
+
+func TestGetWikiPayloadInfo(t *testing.T) {
+	p := wikiTestPayload()
+
+	cases := []struct {
+		action api.HookWikiAction
+		text   string
+		link   string
+		color  int
+	}{
+		{
+			api.HookWikiCreated,
+			"[test/repo] Wiki page created: Getting Started by user1",
+			"http://localhost:3000/test/repo/wiki/Getting-Started",
+			greenColor,
+		},
+		{
+			api.HookWikiEdited,
+			"[test/repo] Wiki page edited: Getting Started by user1",
+			"http://localhost:3000/test/repo/wiki/Getting-Started",
+			yellowColor,
+		},
+		{
+			api.HookWikiDeleted,
+			"[test/repo] Wiki page deleted: Getting Started by user1",
+			"http://localhost:3000/test/repo/wiki",
+			redColor,
+		},
+	}
+
+	for i, c := range cases {
+		p.Action = c.action
+		text, link, color := getWikiPayloadInfo(p, noneLinkFormatter, true)
+		assert.Equal(t, c.text, text, "case %d", i)
+		assert.Equal(t, c.link, link, "case %d", i)
+		assert.Equal(t, c.color, color, "case %d", i)
+	}
+}
+
+func TestGetCommitStatusPayloadInfo(t *testing.T) {
+	p := commitStatusTestPayload()
+
+	cases := []struct {
+		state api.CommitStatusState
+		text  string
+		color int
+	}{
+		{api.CommitStatusSuccess, "[test/repo] Commit status success: ci/build 2020558fe2 by user1", greenColor},
+		{api.CommitStatusPending, "[test/repo] Commit status pending: ci/build 2020558fe2 by user1", yellowColor},
+		{api.CommitStatusWarning, "[test/repo] Commit status warning: ci/build 2020558fe2 by user1", orangeColor},
+		{api.CommitStatusFailure, "[test/repo] Commit status failure: ci/build 2020558fe2 by user1", redColor},
+	}
+
+	for i, c := range cases {
+		p.State = c.state
+		text, link, color := getCommitStatusPayloadInfo(p, noneLinkFormatter, true)
+		assert.Equal(t, c.text, text, "case %d", i)
+		assert.Equal(t, "http://ci.example.com/build/1", link, "case %d", i)
+		assert.Equal(t, c.color, color, "case %d", i)
+	}
+
+	p.TargetURL = ""
+	_, link, _ := getCommitStatusPayloadInfo(p, noneLinkFormatter, true)
+	assert.Equal(t, "http://localhost:3000/test/repo/commit/2020558fe2e34debb818a514715839cabd25e778", link)
+}
+
+func TestGetMemberPayloadInfo(t *testing.T) {
+	p := memberTestPayload()
+
+	cases := []struct {
+		action api.HookMemberAction
+		text   string
+		color  int
+	}{
+		{api.HookMemberAdded, "[test/repo] Collaborator user2 added with write access by user1", greenColor},
+		{api.HookMemberEdited, "[test/repo] Collaborator user2 access changed to write by user1", yellowColor},
+		{api.HookMemberRemoved, "[test/repo] Collaborator user2 removed by user1", redColor},
+	}
+
+	for i, c := range cases {
+		p.Action = c.action
+		text, link, color := getMemberPayloadInfo(p, noneLinkFormatter, true)
+		assert.Equal(t, c.text, text, "case %d", i)
+		assert.Equal(t, "http://localhost:3000/test/repo/settings/collaboration", link, "case %d", i)
+		assert.Equal(t, c.color, color, "case %d", i)
+	}
+
+	p.Action = api.HookMemberAdded
+	p.Member = nil
+	p.Team = &api.Team{Name: "Owners"}
+	p.Permission = "owner"
+	text, _, _ := getMemberPayloadInfo(p, noneLinkFormatter, false)
+	assert.Equal(t, "[test/repo] Team Owners added with owner access", text)
+}
+
+func TestGetBranchProtectionPayloadInfo(t *testing.T) {
+	p := branchProtectionTestPayload()
+
+	cases := []struct {
+		action api.HookBranchProtectionAction
+		text   string
+		color  int
+	}{
+		{api.HookBranchProtectionCreated, "[test/repo] Branch protection created for master by user1", greenColor},
+		{api.HookBranchProtectionEdited, "[test/repo] Branch protection edited for master by user1", yellowColor},
+		{api.HookBranchProtectionDeleted, "[test/repo] Branch protection deleted for master by user1", redColor},
+	}
+
+	for i, c := range cases {
+		p.Action = c.action
+		text, link, color := getBranchProtectionPayloadInfo(p, noneLinkFormatter, true)
+		assert.Equal(t, c.text, text, "case %d", i)
+		assert.Equal(t, "http://localhost:3000/test/repo/settings/branches", link, "case %d", i)
+		assert.Equal(t, c.color, color, "case %d", i)
+	}
+}
+
+func TestGetStarPayloadInfo(t *testing.T) {
+	p := starTestPayload()
+
+	cases := []struct {
+		action api.HookStarAction
+		text   string
+		color  int
+	}{
+		{api.HookStarCreated, "[test/repo] Repository starred by user1", yellowColor},
+		{api.HookStarDeleted, "[test/repo] Repository unstarred by user1", greyColor},
+	}
+
+	for i, c := range cases {
+		p.Action = c.action
+		text, link, color := getStarPayloadInfo(p, noneLinkFormatter, true)
+		assert.Equal(t, c.text, text, "case %d", i)
+		assert.Equal(t, "http://localhost:3000/test/repo", link, "case %d", i)
+		assert.Equal(t, c.color, color, "case %d", i)
+	}
+}
+
+func TestGetUserPayloadInfo(t *testing.T) {
+	p := userTestPayload()
+
+	cases := []struct {
+		action api.HookUserAction
+		text   string
+		link   string
+		color  int
+	}{
+		{api.HookUserCreated, "User user2 created by user1", setting.AppURL + "user2", greenColor},
+		{api.HookUserDeleted, "User user2 deleted by user1", setting.AppURL + "admin/users", redColor},
+	}
+
+	for i, c := range cases {
+		p.Action = c.action
+		text, link, color := getUserPayloadInfo(p, noneLinkFormatter, true)
+		assert.Equal(t, c.text, text, "case %d", i)
+		assert.Equal(t, c.link, link, "case %d", i)
+		assert.Equal(t, c.color, color, "case %d", i)
+	}
+}
+
+func TestGetOrganizationPayloadInfo(t *testing.T) {
+	p := organizationTestPayload()
+
+	cases := []struct {
+		action api.HookOrganizationAction
+		text   string
+		link   string
+		color  int
+	}{
+		{api.HookOrganizationCreated, "Organization org3 created by user1", setting.AppURL + "org3", greenColor},
+		{api.HookOrganizationDeleted, "Organization org3 deleted by user1", setting.AppURL + "admin/orgs", redColor},
+	}
+
+	for i, c := range cases {
+		p.Action = c.action
+		text, link, color := getOrganizationPayloadInfo(p, noneLinkFormatter, true)
+		assert.Equal(t, c.text, text, "case %d", i)
+		assert.Equal(t, c.link, link, "case %d", i)
+		assert.Equal(t, c.color, color, "case %d", i)
+	}
+}
+
+func TestGetLoginFailedPayloadInfo(t *testing.T) {
+	text, link, color := getLoginFailedPayloadInfo(loginFailedTestPayload())
+	assert.Equal(t, "Failed sign in attempt for user2 from 127.0.0.1", text)
+	assert.Equal(t, setting.AppURL+"admin/auths", link)
+	assert.Equal(t, redColor, color)
+}
@@ -150,6 +150,62 @@ func (m *MatrixPayloadUnsafe) Release(p *api.ReleasePayload) (api.Payloader, err
 	return getMatrixPayloadUnsafe(text, nil, m.AccessToken, m.MsgType), nil
 }
 
+// Wiki implements PayloadConvertor Wiki method
+func (m *MatrixPayloadUnsafe) Wiki(p *api.WikiPayload) (api.Payloader, error) {
+	text, _, _ := getWikiPayloadInfo(p, MatrixLinkFormatter, true)
+
+	return getMatrixPayloadUnsafe(text, nil, m.AccessToken, m.MsgType), nil
+}
+
+// Status implements PayloadConvertor Status method
+func (m *MatrixPayloadUnsafe) Status(p *api.CommitStatusPayload) (api.Payloader, error) {
+	text, _, _ := getCommitStatusPayloadInfo(p, MatrixLinkFormatter, true)
+
+	return getMatrixPayloadUnsafe(text, nil, m.AccessToken, m.MsgType), nil
+}
+
+// Member implements PayloadConvertor Member method
+func (m *MatrixPayloadUnsafe) Member(p *api.MemberPayload) (api.Payloader, error) {
+	text, _, _ := getMemberPayloadInfo(p, MatrixLinkFormatter, true)
+
+	return getMatrixPayloadUnsafe(text, nil, m.AccessToken, m.MsgType), nil
+}
+
+// BranchProtection implements PayloadConvertor BranchProtection method
+func (m *MatrixPayloadUnsafe) BranchProtection(p *api.BranchProtectionPayload) (api.Payloader, error) {
+	text, _, _ := getBranchProtectionPayloadInfo(p, MatrixLinkFormatter, true)
+
+	return getMatrixPayloadUnsafe(text, nil, m.AccessToken, m.MsgType), nil
+}
+
+// Star implements PayloadConvertor Star method
+func (m *MatrixPayloadUnsafe) Star(p *api.StarPayload) (api.Payloader, error) {
+	text, _, _ := getStarPayloadInfo(p, MatrixLinkFormatter, true)
+
+	return getMatrixPayloadUnsafe(text, nil, m.AccessToken, m.MsgType), nil
+}
+
+// User implements PayloadConvertor User method
+func (m *MatrixPayloadUnsafe) User(p *api.UserPayload) (api.Payloader, error) {
+	text, _, _ := getUserPayloadInfo(p, MatrixLinkFormatter, true)
+
+	return getMatrixPayloadUnsafe(text, nil, m.AccessToken, m.MsgType), nil
+}
+
+// Organization implements PayloadConvertor Organization method
+func (m *MatrixPayloadUnsafe) Organization(p *api.OrganizationPayload) (api.Payloader, error) {
+	text, _, _ := getOrganizationPayloadInfo(p, MatrixLinkFormatter, true)
+
+	return getMatrixPayloadUnsafe(text, nil, m.AccessToken, m.MsgType), nil
+}
+
+// LoginFailed implements PayloadConvertor LoginFailed method
+func (m *MatrixPayloadUnsafe) LoginFailed(p *api.LoginFailedPayload) (api.Payloader, error) {
+	text, _, _ := getLoginFailedPayloadInfo(p)
+
+	return getMatrixPayloadUnsafe(text, nil, m.AccessToken, m.MsgType), nil
+}
+
 // Push implements PayloadConvertor Push method
 func (m *MatrixPayloadUnsafe) Push(p *api.PushPayload) (api.Payloader, error) {
 	var commitDesc string
@@ -216,6 +272,8 @@ func (m *MatrixPayloadUnsafe) Repository(p *api.RepositoryPayload) (api.Payloade
 		text = fmt.Sprintf("[%s] Repository created by %s", repoLink, senderLink)
 	case api.HookRepoDeleted:
 		text = fmt.Sprintf("[%s] Repository deleted by %s", repoLink, senderLink)
+	default:
+		text, _ = getRepositoryPayloadInfo(p, MatrixLinkFormatter, true)
 	}
 
 	return getMatrixPayloadUnsafe(text, nil, m.AccessToken, m.MsgType), nil
@@ -288,8 +346,14 @@ func getMatrixHookRequest(w *models.Webhook, t *models.HookTask) (*http.Request,
 		return nil, err
 	}
 
+	// the stored payload has no access token anymore if the task is delivered again
+	accessToken := payloadunsafe.AccessToken
+	if accessToken == "" {
+		accessToken = GetMatrixHook(w).AccessToken
+	}
+
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Add("Authorization", "Bearer "+payloadunsafe.AccessToken)
+	req.Header.Add("Authorization", "Bearer "+accessToken)
 
 	return req, nil
 }
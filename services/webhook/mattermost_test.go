@@ -0,0 +1,115 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMattermostPayload(t *testing.T) {
+	t.Run("Create", func(t *testing.T) {
+		p := createTestPayload()
+
+		d := new(MattermostPayload)
+		pl, err := d.Create(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &MattermostPayload{}, pl)
+
+		assert.Len(t, pl.(*MattermostPayload).Attachments, 1)
+		assert.Equal(t, "[test/repo] branch test created", pl.(*MattermostPayload).Attachments[0].Title)
+		assert.Empty(t, pl.(*MattermostPayload).Attachments[0].Text)
+		assert.Equal(t, "http://localhost:3000/test/repo/src/test", pl.(*MattermostPayload).Attachments[0].TitleLink)
+		assert.Equal(t, "#1ac600", pl.(*MattermostPayload).Attachments[0].Color)
+	})
+
+	t.Run("Push", func(t *testing.T) {
+		p := pushTestPayload()
+
+		d := new(MattermostPayload)
+		pl, err := d.Push(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &MattermostPayload{}, pl)
+
+		assert.Len(t, pl.(*MattermostPayload).Attachments, 1)
+		assert.Equal(t, "[test/repo:test] 2 new commits", pl.(*MattermostPayload).Attachments[0].Title)
+		assert.Equal(t, "[2020558](http://localhost:3000/test/repo/commit/2020558fe2e34debb818a514715839cabd25e778) commit message - user1\n[2020558](http://localhost:3000/test/repo/commit/2020558fe2e34debb818a514715839cabd25e778) commit message - user1", pl.(*MattermostPayload).Attachments[0].Text)
+		assert.Equal(t, "http://localhost:3000/test/repo/src/test", pl.(*MattermostPayload).Attachments[0].TitleLink)
+		assert.Equal(t, p.Sender.UserName, pl.(*MattermostPayload).Attachments[0].AuthorName)
+		assert.Equal(t, setting.AppURL+p.Sender.UserName, pl.(*MattermostPayload).Attachments[0].AuthorLink)
+		assert.Equal(t, p.Sender.AvatarURL, pl.(*MattermostPayload).Attachments[0].AuthorIcon)
+	})
+
+	t.Run("Issue", func(t *testing.T) {
+		p := issueTestPayload()
+
+		d := new(MattermostPayload)
+		p.Action = api.HookIssueOpened
+		pl, err := d.Issue(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &MattermostPayload{}, pl)
+
+		assert.Len(t, pl.(*MattermostPayload).Attachments, 1)
+		assert.Equal(t, "[test/repo] Issue opened: #2 crash", pl.(*MattermostPayload).Attachments[0].Title)
+		assert.Equal(t, "issue body", pl.(*MattermostPayload).Attachments[0].Text)
+		assert.Equal(t, "http://localhost:3000/test/repo/issues/2", pl.(*MattermostPayload).Attachments[0].TitleLink)
+	})
+
+	t.Run("Review", func(t *testing.T) {
+		p := pullRequestTestPayload()
+		p.Action = api.HookIssueReviewed
+
+		d := new(MattermostPayload)
+		pl, err := d.Review(p, models.HookEventPullRequestReviewRejected)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &MattermostPayload{}, pl)
+
+		assert.Len(t, pl.(*MattermostPayload).Attachments, 1)
+		assert.Equal(t, "[test/repo] Pull request review rejected: #12 Fix bug", pl.(*MattermostPayload).Attachments[0].Title)
+		assert.Equal(t, "good job", pl.(*MattermostPayload).Attachments[0].Text)
+		assert.Equal(t, "#ff3232", pl.(*MattermostPayload).Attachments[0].Color)
+	})
+
+	t.Run("Release", func(t *testing.T) {
+		p := pullReleaseTestPayload()
+
+		d := new(MattermostPayload)
+		pl, err := d.Release(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &MattermostPayload{}, pl)
+
+		assert.Len(t, pl.(*MattermostPayload).Attachments, 1)
+		assert.Equal(t, "[test/repo] Release created: v1.0", pl.(*MattermostPayload).Attachments[0].Title)
+		assert.Equal(t, "Note of first stable release", pl.(*MattermostPayload).Attachments[0].Text)
+	})
+}
+
+func TestMattermostJSONPayload(t *testing.T) {
+	p := pushTestPayload()
+
+	pl, err := GetMattermostPayload(p, models.HookEventPush, `{"channel":"town-square","username":"Gitea","icon_url":"https://example.com/icon.png"}`)
+	require.NoError(t, err)
+	require.NotNil(t, pl)
+	require.IsType(t, &MattermostPayload{}, pl)
+
+	assert.Equal(t, "town-square", pl.(*MattermostPayload).Channel)
+	assert.Equal(t, "Gitea", pl.(*MattermostPayload).Username)
+	assert.Equal(t, "https://example.com/icon.png", pl.(*MattermostPayload).IconURL)
+
+	json, err := pl.JSONPayload()
+	require.NoError(t, err)
+	assert.NotEmpty(t, json)
+}
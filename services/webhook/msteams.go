@@ -254,6 +254,9 @@ func (m *MSTeamsPayload) Repository(p *api.RepositoryPayload) (api.Payloader, er
 	case api.HookRepoDeleted:
 		title = fmt.Sprintf("[%s] Repository deleted", p.Repository.FullName)
 		color = yellowColor
+	default:
+		title, color = getRepositoryPayloadInfo(p, noneLinkFormatter, false)
+		url = p.Repository.HTMLURL
 	}
 
 	return createMSTeamsPayload(
@@ -282,21 +285,146 @@ func (m *MSTeamsPayload) Release(p *api.ReleasePayload) (api.Payloader, error) {
 	), nil
 }
 
+// Wiki implements PayloadConvertor Wiki method
+func (m *MSTeamsPayload) Wiki(p *api.WikiPayload) (api.Payloader, error) {
+	title, link, color := getWikiPayloadInfo(p, noneLinkFormatter, false)
+
+	return createMSTeamsPayload(
+		p.Repository,
+		p.Sender,
+		title,
+		p.Comment,
+		link,
+		color,
+		nil,
+	), nil
+}
+
+// Status implements PayloadConvertor Status method
+func (m *MSTeamsPayload) Status(p *api.CommitStatusPayload) (api.Payloader, error) {
+	title, link, color := getCommitStatusPayloadInfo(p, noneLinkFormatter, false)
+
+	return createMSTeamsPayload(
+		p.Repository,
+		p.Sender,
+		title,
+		p.Description,
+		link,
+		color,
+		nil,
+	), nil
+}
+
+// Member implements PayloadConvertor Member method
+func (m *MSTeamsPayload) Member(p *api.MemberPayload) (api.Payloader, error) {
+	title, link, color := getMemberPayloadInfo(p, noneLinkFormatter, false)
+
+	return createMSTeamsPayload(
+		p.Repository,
+		p.Sender,
+		title,
+		"",
+		link,
+		color,
+		nil,
+	), nil
+}
+
+// BranchProtection implements PayloadConvertor BranchProtection method
+func (m *MSTeamsPayload) BranchProtection(p *api.BranchProtectionPayload) (api.Payloader, error) {
+	title, link, color := getBranchProtectionPayloadInfo(p, noneLinkFormatter, false)
+
+	return createMSTeamsPayload(
+		p.Repository,
+		p.Sender,
+		title,
+		"",
+		link,
+		color,
+		nil,
+	), nil
+}
+
+// Star implements PayloadConvertor Star method
+func (m *MSTeamsPayload) Star(p *api.StarPayload) (api.Payloader, error) {
+	title, link, color := getStarPayloadInfo(p, noneLinkFormatter, false)
+
+	return createMSTeamsPayload(
+		p.Repository,
+		p.Sender,
+		title,
+		"",
+		link,
+		color,
+		nil,
+	), nil
+}
+
+// User implements PayloadConvertor User method
+func (m *MSTeamsPayload) User(p *api.UserPayload) (api.Payloader, error) {
+	title, link, color := getUserPayloadInfo(p, noneLinkFormatter, false)
+
+	return createMSTeamsPayload(
+		nil,
+		p.Sender,
+		title,
+		"",
+		link,
+		color,
+		nil,
+	), nil
+}
+
+// Organization implements PayloadConvertor Organization method
+func (m *MSTeamsPayload) Organization(p *api.OrganizationPayload) (api.Payloader, error) {
+	title, link, color := getOrganizationPayloadInfo(p, noneLinkFormatter, false)
+
+	return createMSTeamsPayload(
+		nil,
+		p.Sender,
+		title,
+		"",
+		link,
+		color,
+		nil,
+	), nil
+}
+
+// LoginFailed implements PayloadConvertor LoginFailed method
+func (m *MSTeamsPayload) LoginFailed(p *api.LoginFailedPayload) (api.Payloader, error) {
+	title, link, color := getLoginFailedPayloadInfo(p)
+
+	return createMSTeamsPayload(
+		nil,
+		nil,
+		title,
+		p.Reason,
+		link,
+		color,
+		nil,
+	), nil
+}
+
 // GetMSTeamsPayload converts a MSTeams webhook into a MSTeamsPayload
 func GetMSTeamsPayload(p api.Payloader, event models.HookEventType, meta string) (api.Payloader, error) {
 	return convertPayloader(new(MSTeamsPayload), p, event)
 }
 
 func createMSTeamsPayload(r *api.Repository, s *api.User, title, text, actionTarget string, color int, fact *MSTeamsFact) *MSTeamsPayload {
-	facts := []MSTeamsFact{
-		{
+	// instance events have no repository and failed sign in attempts no sender either
+	facts := make([]MSTeamsFact, 0, 2)
+	if r != nil {
+		facts = append(facts, MSTeamsFact{
 			Name:  "Repository:",
 			Value: r.FullName,
-		},
+		})
 	}
 	if fact != nil {
 		facts = append(facts, *fact)
 	}
+	if s == nil {
+		s = &api.User{}
+	}
 
 	return &MSTeamsPayload{
 		Type:       "MessageCard",
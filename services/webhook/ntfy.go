@@ -0,0 +1,284 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+type (
+	// NtfyMeta contains the ntfy metadata
+	NtfyMeta struct {
+		Topic       string `json:"topic"`
+		Priority    int    `json:"priority"`
+		AccessToken string `json:"access_token"`
+	}
+
+	// NtfyPayload contains the JSON message published to a ntfy server
+	// see: https://ntfy.sh/docs/publish/#publish-as-json
+	NtfyPayload struct {
+		Topic    string `json:"topic"`
+		Title    string `json:"title"`
+		Message  string `json:"message"`
+		Click    string `json:"click,omitempty"`
+		Priority int    `json:"priority,omitempty"`
+	}
+)
+
+// GetNtfyHook returns ntfy metadata
+func GetNtfyHook(w *models.Webhook) *NtfyMeta {
+	s := &NtfyMeta{}
+	if err := json.Unmarshal([]byte(w.Meta), s); err != nil {
+		log.Error("webhook.GetNtfyHook(%d): %v", w.ID, err)
+	}
+	return s
+}
+
+// JSONPayload Marshals the NtfyPayload to json
+func (n *NtfyPayload) JSONPayload() ([]byte, error) {
+	data, err := json.MarshalIndent(n, "", "  ")
+	if err != nil {
+		return []byte{}, err
+	}
+	return data, nil
+}
+
+var (
+	_ PayloadConvertor = &NtfyPayload{}
+)
+
+// Create implements PayloadConvertor Create method
+func (n *NtfyPayload) Create(p *api.CreatePayload) (api.Payloader, error) {
+	// created tag/branch
+	refName := git.RefEndName(p.Ref)
+	title := fmt.Sprintf("[%s] %s %s created", p.Repo.FullName, p.RefType, refName)
+
+	return n.createPayload(title, fmt.Sprintf("%s %s created by %s", p.RefType, refName, p.Sender.UserName), p.Repo.HTMLURL+"/src/"+refName), nil
+}
+
+// Delete implements PayloadConvertor Delete method
+func (n *NtfyPayload) Delete(p *api.DeletePayload) (api.Payloader, error) {
+	// deleted tag/branch
+	refName := git.RefEndName(p.Ref)
+	title := fmt.Sprintf("[%s] %s %s deleted", p.Repo.FullName, p.RefType, refName)
+
+	return n.createPayload(title, fmt.Sprintf("%s %s deleted by %s", p.RefType, refName, p.Sender.UserName), p.Repo.HTMLURL), nil
+}
+
+// Fork implements PayloadConvertor Fork method
+func (n *NtfyPayload) Fork(p *api.ForkPayload) (api.Payloader, error) {
+	title := fmt.Sprintf("%s is forked to %s", p.Forkee.FullName, p.Repo.FullName)
+
+	return n.createPayload(title, title, p.Repo.HTMLURL), nil
+}
+
+// Push implements PayloadConvertor Push method
+func (n *NtfyPayload) Push(p *api.PushPayload) (api.Payloader, error) {
+	var (
+		branchName = git.RefEndName(p.Ref)
+		commitDesc string
+	)
+
+	var titleLink string
+	if len(p.Commits) == 1 {
+		commitDesc = "1 new commit"
+		titleLink = p.Commits[0].URL
+	} else {
+		commitDesc = fmt.Sprintf("%d new commits", len(p.Commits))
+		titleLink = p.CompareURL
+	}
+	if titleLink == "" {
+		titleLink = p.Repo.HTMLURL + "/src/" + branchName
+	}
+
+	title := fmt.Sprintf("[%s:%s] %s pushed by %s", p.Repo.FullName, branchName, commitDesc, p.Pusher.UserName)
+
+	var text string
+	// for each commit, generate a line of the message
+	for i, commit := range p.Commits {
+		text += fmt.Sprintf("%s: %s - %s", commit.ID[:7], strings.Split(commit.Message, "\n")[0], commit.Author.Name)
+		// add linebreak to each commit but the last
+		if i < len(p.Commits)-1 {
+			text += "\n"
+		}
+	}
+
+	return n.createPayload(title, text, titleLink), nil
+}
+
+// Issue implements PayloadConvertor Issue method
+func (n *NtfyPayload) Issue(p *api.IssuePayload) (api.Payloader, error) {
+	title, issueTitle, text, _ := getIssuesPayloadInfo(p, noneLinkFormatter, true)
+	if text == "" {
+		text = issueTitle
+	}
+
+	return n.createPayload(title, text, p.Issue.HTMLURL), nil
+}
+
+// IssueComment implements PayloadConvertor IssueComment method
+func (n *NtfyPayload) IssueComment(p *api.IssueCommentPayload) (api.Payloader, error) {
+	title, _, _ := getIssueCommentPayloadInfo(p, noneLinkFormatter, true)
+
+	return n.createPayload(title, p.Comment.Body, p.Comment.HTMLURL), nil
+}
+
+// PullRequest implements PayloadConvertor PullRequest method
+func (n *NtfyPayload) PullRequest(p *api.PullRequestPayload) (api.Payloader, error) {
+	title, issueTitle, text, _ := getPullRequestPayloadInfo(p, noneLinkFormatter, true)
+	if text == "" {
+		text = issueTitle
+	}
+
+	return n.createPayload(title, text, p.PullRequest.HTMLURL), nil
+}
+
+// Review implements PayloadConvertor Review method
+func (n *NtfyPayload) Review(p *api.PullRequestPayload, event models.HookEventType) (api.Payloader, error) {
+	var text, title string
+	switch p.Action {
+	case api.HookIssueReviewed:
+		action, err := parseHookPullRequestEventType(event)
+		if err != nil {
+			return nil, err
+		}
+
+		title = fmt.Sprintf("[%s] Pull request review %s: #%d %s", p.Repository.FullName, action, p.Index, p.PullRequest.Title)
+		text = p.Review.Content
+	}
+
+	return n.createPayload(title, text, p.PullRequest.HTMLURL), nil
+}
+
+// Repository implements PayloadConvertor Repository method
+func (n *NtfyPayload) Repository(p *api.RepositoryPayload) (api.Payloader, error) {
+	var title, url string
+	switch p.Action {
+	case api.HookRepoCreated:
+		title = fmt.Sprintf("[%s] Repository created", p.Repository.FullName)
+		url = p.Repository.HTMLURL
+	case api.HookRepoDeleted:
+		title = fmt.Sprintf("[%s] Repository deleted", p.Repository.FullName)
+	default:
+		title, _ = getRepositoryPayloadInfo(p, noneLinkFormatter, false)
+		url = p.Repository.HTMLURL
+	}
+
+	return n.createPayload(title, fmt.Sprintf("%s by %s", title, p.Sender.UserName), url), nil
+}
+
+// Release implements PayloadConvertor Release method
+func (n *NtfyPayload) Release(p *api.ReleasePayload) (api.Payloader, error) {
+	text, _ := getReleasePayloadInfo(p, noneLinkFormatter, true)
+
+	return n.createPayload(text, p.Release.Note, p.Release.URL), nil
+}
+
+// Wiki implements PayloadConvertor Wiki method
+func (n *NtfyPayload) Wiki(p *api.WikiPayload) (api.Payloader, error) {
+	title, link, _ := getWikiPayloadInfo(p, noneLinkFormatter, false)
+
+	return n.createPayload(title, fmt.Sprintf("%s by %s", title, p.Sender.UserName), link), nil
+}
+
+// Status implements PayloadConvertor Status method
+func (n *NtfyPayload) Status(p *api.CommitStatusPayload) (api.Payloader, error) {
+	title, link, _ := getCommitStatusPayloadInfo(p, noneLinkFormatter, false)
+
+	return n.createPayload(title, fmt.Sprintf("%s by %s", title, p.Sender.UserName), link), nil
+}
+
+// Member implements PayloadConvertor Member method
+func (n *NtfyPayload) Member(p *api.MemberPayload) (api.Payloader, error) {
+	title, link, _ := getMemberPayloadInfo(p, noneLinkFormatter, false)
+
+	return n.createPayload(title, fmt.Sprintf("%s by %s", title, p.Sender.UserName), link), nil
+}
+
+// BranchProtection implements PayloadConvertor BranchProtection method
+func (n *NtfyPayload) BranchProtection(p *api.BranchProtectionPayload) (api.Payloader, error) {
+	title, link, _ := getBranchProtectionPayloadInfo(p, noneLinkFormatter, false)
+
+	return n.createPayload(title, fmt.Sprintf("%s by %s", title, p.Sender.UserName), link), nil
+}
+
+// Star implements PayloadConvertor Star method
+func (n *NtfyPayload) Star(p *api.StarPayload) (api.Payloader, error) {
+	title, link, _ := getStarPayloadInfo(p, noneLinkFormatter, false)
+
+	return n.createPayload(title, fmt.Sprintf("%s by %s", title, p.Sender.UserName), link), nil
+}
+
+// User implements PayloadConvertor User method
+func (n *NtfyPayload) User(p *api.UserPayload) (api.Payloader, error) {
+	title, link, _ := getUserPayloadInfo(p, noneLinkFormatter, false)
+
+	return n.createPayload(title, fmt.Sprintf("%s by %s", title, p.Sender.UserName), link), nil
+}
+
+// Organization implements PayloadConvertor Organization method
+func (n *NtfyPayload) Organization(p *api.OrganizationPayload) (api.Payloader, error) {
+	title, link, _ := getOrganizationPayloadInfo(p, noneLinkFormatter, false)
+
+	return n.createPayload(title, fmt.Sprintf("%s by %s", title, p.Sender.UserName), link), nil
+}
+
+// LoginFailed implements PayloadConvertor LoginFailed method
+func (n *NtfyPayload) LoginFailed(p *api.LoginFailedPayload) (api.Payloader, error) {
+	title, link, _ := getLoginFailedPayloadInfo(p)
+
+	return n.createPayload(title, p.Reason, link), nil
+}
+
+// GetNtfyPayload converts a ntfy webhook into a NtfyPayload
+func GetNtfyPayload(p api.Payloader, event models.HookEventType, meta string) (api.Payloader, error) {
+	s := new(NtfyPayload)
+
+	ntfy := &NtfyMeta{}
+	if err := json.Unmarshal([]byte(meta), &ntfy); err != nil {
+		return s, errors.New("GetNtfyPayload meta json:" + err.Error())
+	}
+	s.Topic = ntfy.Topic
+	s.Priority = ntfy.Priority
+
+	return convertPayloader(s, p, event)
+}
+
+func (n *NtfyPayload) createPayload(title, message, click string) *NtfyPayload {
+	if message == "" {
+		// ntfy replaces an empty message with "triggered"
+		message = title
+	}
+	return &NtfyPayload{
+		Topic:    n.Topic,
+		Title:    title,
+		Message:  message,
+		Click:    click,
+		Priority: n.Priority,
+	}
+}
+
+// getNtfyHookRequest publishes the JSON message to the root of the ntfy server
+func getNtfyHookRequest(w *models.Webhook, t *models.HookTask) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodPost, w.URL, strings.NewReader(t.PayloadContent))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if token := GetNtfyHook(w).AccessToken; token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req, nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNtfyPayload(t *testing.T) {
+	t.Run("Push", func(t *testing.T) {
+		p := pushTestPayload()
+
+		d := new(NtfyPayload)
+		pl, err := d.Push(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &NtfyPayload{}, pl)
+
+		assert.Equal(t, "[test/repo:test] 2 new commits pushed by user1", pl.(*NtfyPayload).Title)
+		assert.Equal(t, "2020558: commit message - user1\n2020558: commit message - user1", pl.(*NtfyPayload).Message)
+		assert.Equal(t, "http://localhost:3000/test/repo/src/test", pl.(*NtfyPayload).Click)
+	})
+
+	t.Run("Issue", func(t *testing.T) {
+		p := issueTestPayload()
+
+		d := new(NtfyPayload)
+		p.Action = api.HookIssueClosed
+		pl, err := d.Issue(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &NtfyPayload{}, pl)
+
+		assert.Equal(t, "[test/repo] Issue closed: #2 crash by user1", pl.(*NtfyPayload).Title)
+		assert.Equal(t, "#2 crash", pl.(*NtfyPayload).Message)
+		assert.Equal(t, "http://localhost:3000/test/repo/issues/2", pl.(*NtfyPayload).Click)
+	})
+}
+
+func TestNtfyJSONPayload(t *testing.T) {
+	p := pushTestPayload()
+
+	pl, err := GetNtfyPayload(p, models.HookEventPush, `{"topic":"gitea","priority":4}`)
+	require.NoError(t, err)
+	require.NotNil(t, pl)
+	require.IsType(t, &NtfyPayload{}, pl)
+
+	assert.Equal(t, "gitea", pl.(*NtfyPayload).Topic)
+	assert.Equal(t, 4, pl.(*NtfyPayload).Priority)
+
+	json, err := pl.JSONPayload()
+	require.NoError(t, err)
+	assert.NotEmpty(t, json)
+}
+
+func TestNtfyHookRequest(t *testing.T) {
+	w := &models.Webhook{
+		URL:  "https://ntfy.example.com",
+		Type: models.NTFY,
+		Meta: `{"topic":"gitea","access_token":"tk_secret"}`,
+	}
+	task := &models.HookTask{PayloadContent: `{"topic":"gitea"}`}
+
+	req, err := getNtfyHookRequest(w, task)
+	require.NoError(t, err)
+	assert.Equal(t, "POST", req.Method)
+	assert.Equal(t, "https://ntfy.example.com", req.URL.String())
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, "Bearer tk_secret", req.Header.Get("Authorization"))
+}
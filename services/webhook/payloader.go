@@ -22,6 +22,14 @@ type PayloadConvertor interface {
 	Review(*api.PullRequestPayload, models.HookEventType) (api.Payloader, error)
 	Repository(*api.RepositoryPayload) (api.Payloader, error)
 	Release(*api.ReleasePayload) (api.Payloader, error)
+	Wiki(*api.WikiPayload) (api.Payloader, error)
+	Status(*api.CommitStatusPayload) (api.Payloader, error)
+	Member(*api.MemberPayload) (api.Payloader, error)
+	BranchProtection(*api.BranchProtectionPayload) (api.Payloader, error)
+	Star(*api.StarPayload) (api.Payloader, error)
+	User(*api.UserPayload) (api.Payloader, error)
+	Organization(*api.OrganizationPayload) (api.Payloader, error)
+	LoginFailed(*api.LoginFailedPayload) (api.Payloader, error)
 }
 
 func convertPayloader(s PayloadConvertor, p api.Payloader, event models.HookEventType) (api.Payloader, error) {
@@ -51,6 +59,22 @@ func convertPayloader(s PayloadConvertor, p api.Payloader, event models.HookEven
 		return s.Repository(p.(*api.RepositoryPayload))
 	case models.HookEventRelease:
 		return s.Release(p.(*api.ReleasePayload))
+	case models.HookEventWiki:
+		return s.Wiki(p.(*api.WikiPayload))
+	case models.HookEventStatus:
+		return s.Status(p.(*api.CommitStatusPayload))
+	case models.HookEventMember:
+		return s.Member(p.(*api.MemberPayload))
+	case models.HookEventBranchProtection:
+		return s.BranchProtection(p.(*api.BranchProtectionPayload))
+	case models.HookEventStar:
+		return s.Star(p.(*api.StarPayload))
+	case models.HookEventUser:
+		return s.User(p.(*api.UserPayload))
+	case models.HookEventOrganization:
+		return s.Organization(p.(*api.OrganizationPayload))
+	case models.HookEventLoginFailed:
+		return s.LoginFailed(p.(*api.LoginFailedPayload))
 	}
 	return s, nil
 }
@@ -0,0 +1,197 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"fmt"
+	"math/rand"
+	"net/url"
+	"strconv"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/services/mailer"
+)
+
+// retryCheckInterval is how often hook tasks are checked for due retries
+const retryCheckInterval = 10 * time.Second
+
+// retryQueue delivers the hook tasks whose retry is due
+var retryQueue queue.UniqueQueue
+
+// handleRetry delivers the passed hook task IDs again
+func handleRetry(data ...queue.Data) {
+	for _, datum := range data {
+		id := datum.(int64)
+		t, err := models.GetHookTaskByID(id)
+		if err != nil {
+			if !models.IsErrHookTaskNotExist(err) {
+				log.Error("GetHookTaskByID[%d]: %v", id, err)
+			}
+			continue
+		}
+
+		now := timeutil.TimeStampNow()
+		if t.IsDelivered || t.NextRetryUnix == 0 || t.NextRetryUnix > now {
+			continue
+		}
+
+		// Postpone the retry while it is delivered so that it is not picked up twice,
+		// should the delivery never finish the retry is picked up again after the lease.
+		lease := now.AddDuration(time.Duration(setting.Webhook.DeliverTimeout)*time.Second + retryCheckInterval)
+		if claimed, err := models.ClaimHookTaskRetry(t, lease); err != nil {
+			log.Error("ClaimHookTaskRetry[%d]: %v", id, err)
+			continue
+		} else if !claimed {
+			continue
+		}
+
+		if err := Deliver(t); err != nil {
+			log.Error("deliver: %v", err)
+		}
+	}
+}
+
+func initRetryQueue() error {
+	retryQueue = queue.CreateUniqueQueue("webhook_retry", handleRetry, int64(0))
+	if retryQueue == nil {
+		return fmt.Errorf("Unable to create webhook_retry Queue")
+	}
+
+	go graceful.GetManager().RunWithShutdownFns(retryQueue.Run)
+	return nil
+}
+
+// queueDueRetries pushes the hook tasks whose retry is due to the retry queue
+func queueDueRetries() {
+	ids, err := models.FindDueHookTaskRetries(timeutil.TimeStampNow())
+	if err != nil {
+		log.Error("FindDueHookTaskRetries: %v", err)
+		return
+	}
+	for _, id := range ids {
+		if err := retryQueue.Push(id); err != nil && err != queue.ErrAlreadyInQueue {
+			log.Error("Unable to push hook task %d to the retry queue: %v", id, err)
+		}
+	}
+}
+
+// retryBackoff returns the delay before the given retry of a delivery. The delay grows
+// exponentially from RETRY_BACKOFF up to MAX_RETRY_BACKOFF, a random jitter of up to
+// half the delay spreads out the retries of deliveries which failed at the same time.
+func retryBackoff(retry int) time.Duration {
+	backoff := setting.Webhook.RetryBackoff
+	for i := 1; i < retry && backoff < setting.Webhook.MaxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > setting.Webhook.MaxRetryBackoff {
+		backoff = setting.Webhook.MaxRetryBackoff
+	}
+	if backoff <= 0 {
+		return 0
+	}
+
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(backoff-half)+1))
+}
+
+// scheduleRetry marks a failed delivery for another attempt if it has retries left
+func scheduleRetry(t *models.HookTask) {
+	if t.IsSucceed || setting.DisableWebhooks || t.Attempts > setting.Webhook.MaxRetries {
+		return
+	}
+	t.IsDelivered = false
+	t.NextRetryUnix = timeutil.TimeStampNow().AddDuration(retryBackoff(t.Attempts))
+	// a zero NextRetryUnix marks a task which was never attempted
+	if t.NextRetryUnix == 0 {
+		t.NextRetryUnix = 1
+	}
+}
+
+// deactivateFailingWebhook deactivates a webhook whose deliveries keep failing
+// and lets the site administrators and the owners of the webhook know about it.
+func deactivateFailingWebhook(w *models.Webhook) {
+	failures := w.FailureCount
+	if err := models.DeactivateWebhook(w); err != nil {
+		log.Error("DeactivateWebhook[%d]: %v", w.ID, err)
+		return
+	}
+
+	host := w.URL
+	if u, err := url.Parse(w.URL); err == nil {
+		// the full URL might contain credentials
+		host = u.Host
+	}
+
+	owner, link, recipients, err := webhookOwners(w)
+	if err != nil {
+		log.Error("Unable to find the owners of webhook %d: %v", w.ID, err)
+	}
+
+	desc := fmt.Sprintf("Webhook %d of %s to %s has been deactivated after %d consecutive failed deliveries", w.ID, owner, host, failures)
+	log.Warn("%s", desc)
+	if err := models.CreateNotice(models.NoticeWebhook, desc); err != nil {
+		log.Error("CreateNotice: %v", err)
+	}
+
+	if len(recipients) == 0 {
+		return
+	}
+	if err := mailer.SendWebhookDeactivatedMail(w, host, link, failures, recipients); err != nil {
+		log.Error("SendWebhookDeactivatedMail[%d]: %v", w.ID, err)
+	}
+}
+
+// webhookOwners returns a description of the owner of a webhook, the link to its settings
+// and the users to notify about it. System and default webhooks are owned by the site
+// administrators only, they are notified through a system notice.
+func webhookOwners(w *models.Webhook) (string, string, []*models.User, error) {
+	hookID := strconv.FormatInt(w.ID, 10)
+
+	switch {
+	case w.RepoID > 0:
+		repo, err := models.GetRepositoryByID(w.RepoID)
+		if err != nil {
+			return fmt.Sprintf("repository %d", w.RepoID), "", nil, err
+		}
+		if err := repo.GetOwner(); err != nil {
+			return repo.Name, "", nil, err
+		}
+		link := repo.HTMLURL() + "/settings/hooks/" + hookID
+		if !repo.Owner.IsOrganization() {
+			return repo.FullName(), link, []*models.User{repo.Owner}, nil
+		}
+		owners, err := organizationOwners(repo.Owner)
+		return repo.FullName(), link, owners, err
+	case w.OrgID > 0:
+		org, err := models.GetUserByID(w.OrgID)
+		if err != nil {
+			return fmt.Sprintf("organization %d", w.OrgID), "", nil, err
+		}
+		link := setting.AppURL + "org/" + url.PathEscape(org.Name) + "/settings/hooks/" + hookID
+		owners, err := organizationOwners(org)
+		return org.Name, link, owners, err
+	case w.IsSystemWebhook:
+		return "the system", "", nil, nil
+	default:
+		return "the default webhooks", "", nil, nil
+	}
+}
+
+func organizationOwners(org *models.User) ([]*models.User, error) {
+	team, err := org.GetOwnerTeam()
+	if err != nil {
+		return nil, err
+	}
+	if err := team.GetMembers(&models.SearchMembersOptions{}); err != nil {
+		return nil, err
+	}
+	return team.Members, nil
+}
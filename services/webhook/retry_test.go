@@ -0,0 +1,61 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	defer func(backoff, maxBackoff time.Duration) {
+		setting.Webhook.RetryBackoff = backoff
+		setting.Webhook.MaxRetryBackoff = maxBackoff
+	}(setting.Webhook.RetryBackoff, setting.Webhook.MaxRetryBackoff)
+	setting.Webhook.RetryBackoff = time.Minute
+	setting.Webhook.MaxRetryBackoff = 5 * time.Minute
+
+	for retry, expected := range map[int]time.Duration{
+		1: time.Minute,
+		2: 2 * time.Minute,
+		3: 4 * time.Minute,
+		4: 5 * time.Minute,
+		9: 5 * time.Minute,
+	} {
+		backoff := retryBackoff(retry)
+		assert.LessOrEqual(t, int64(backoff), int64(expected), "retry %d", retry)
+		assert.GreaterOrEqual(t, int64(backoff), int64(expected/2), "retry %d", retry)
+	}
+
+	setting.Webhook.RetryBackoff = 0
+	assert.Zero(t, retryBackoff(1))
+}
+
+func TestScheduleRetry(t *testing.T) {
+	defer func(maxRetries int) {
+		setting.Webhook.MaxRetries = maxRetries
+	}(setting.Webhook.MaxRetries)
+	setting.Webhook.MaxRetries = 2
+
+	task := &models.HookTask{IsDelivered: true, Attempts: 1}
+	scheduleRetry(task)
+	assert.False(t, task.IsDelivered)
+	assert.Greater(t, int64(task.NextRetryUnix), int64(timeutil.TimeStampNow()))
+
+	task = &models.HookTask{IsDelivered: true, Attempts: 3}
+	scheduleRetry(task)
+	assert.True(t, task.IsDelivered)
+	assert.Zero(t, task.NextRetryUnix)
+
+	task = &models.HookTask{IsDelivered: true, IsSucceed: true, Attempts: 1}
+	scheduleRetry(task)
+	assert.True(t, task.IsDelivered)
+}
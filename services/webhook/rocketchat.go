@@ -0,0 +1,304 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+type (
+	// RocketChatMeta contains the Rocket.Chat metadata
+	RocketChatMeta struct {
+		Channel  string `json:"channel"`
+		Username string `json:"username"`
+		IconURL  string `json:"icon_url"`
+	}
+
+	// RocketChatAttachment contains a message attachment
+	// see: https://docs.rocket.chat/guides/administration/admin-panel/integrations
+	RocketChatAttachment struct {
+		Color      string `json:"color"`
+		AuthorName string `json:"author_name"`
+		AuthorLink string `json:"author_link"`
+		AuthorIcon string `json:"author_icon"`
+		Title      string `json:"title"`
+		TitleLink  string `json:"title_link"`
+		Text       string `json:"text"`
+	}
+
+	// RocketChatPayload contains the payload for a Rocket.Chat incoming webhook
+	RocketChatPayload struct {
+		Channel     string                 `json:"channel,omitempty"`
+		Alias       string                 `json:"alias,omitempty"`
+		Avatar      string                 `json:"avatar,omitempty"`
+		Text        string                 `json:"text"`
+		Attachments []RocketChatAttachment `json:"attachments"`
+	}
+)
+
+// GetRocketChatHook returns Rocket.Chat metadata
+func GetRocketChatHook(w *models.Webhook) *RocketChatMeta {
+	s := &RocketChatMeta{}
+	if err := json.Unmarshal([]byte(w.Meta), s); err != nil {
+		log.Error("webhook.GetRocketChatHook(%d): %v", w.ID, err)
+	}
+	return s
+}
+
+// JSONPayload Marshals the RocketChatPayload to json
+func (r *RocketChatPayload) JSONPayload() ([]byte, error) {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return []byte{}, err
+	}
+	return data, nil
+}
+
+var (
+	_ PayloadConvertor = &RocketChatPayload{}
+)
+
+// Create implements PayloadConvertor Create method
+func (r *RocketChatPayload) Create(p *api.CreatePayload) (api.Payloader, error) {
+	// created tag/branch
+	refName := git.RefEndName(p.Ref)
+	title := fmt.Sprintf("[%s] %s %s created", p.Repo.FullName, p.RefType, refName)
+
+	return r.createPayload(p.Sender, title, "", p.Repo.HTMLURL+"/src/"+refName, greenColor), nil
+}
+
+// Delete implements PayloadConvertor Delete method
+func (r *RocketChatPayload) Delete(p *api.DeletePayload) (api.Payloader, error) {
+	// deleted tag/branch
+	refName := git.RefEndName(p.Ref)
+	title := fmt.Sprintf("[%s] %s %s deleted", p.Repo.FullName, p.RefType, refName)
+
+	return r.createPayload(p.Sender, title, "", p.Repo.HTMLURL+"/src/"+refName, redColor), nil
+}
+
+// Fork implements PayloadConvertor Fork method
+func (r *RocketChatPayload) Fork(p *api.ForkPayload) (api.Payloader, error) {
+	title := fmt.Sprintf("%s is forked to %s", p.Forkee.FullName, p.Repo.FullName)
+
+	return r.createPayload(p.Sender, title, "", p.Repo.HTMLURL, greenColor), nil
+}
+
+// Push implements PayloadConvertor Push method
+func (r *RocketChatPayload) Push(p *api.PushPayload) (api.Payloader, error) {
+	var (
+		branchName = git.RefEndName(p.Ref)
+		commitDesc string
+	)
+
+	var titleLink string
+	if len(p.Commits) == 1 {
+		commitDesc = "1 new commit"
+		titleLink = p.Commits[0].URL
+	} else {
+		commitDesc = fmt.Sprintf("%d new commits", len(p.Commits))
+		titleLink = p.CompareURL
+	}
+	if titleLink == "" {
+		titleLink = p.Repo.HTMLURL + "/src/" + branchName
+	}
+
+	title := fmt.Sprintf("[%s:%s] %s", p.Repo.FullName, branchName, commitDesc)
+
+	var text string
+	// for each commit, generate attachment text
+	for i, commit := range p.Commits {
+		text += fmt.Sprintf("[%s](%s) %s - %s", commit.ID[:7], commit.URL,
+			strings.TrimRight(commit.Message, "\r\n"), commit.Author.Name)
+		// add linebreak to each commit but the last
+		if i < len(p.Commits)-1 {
+			text += "\n"
+		}
+	}
+
+	return r.createPayload(p.Sender, title, text, titleLink, greenColor), nil
+}
+
+// Issue implements PayloadConvertor Issue method
+func (r *RocketChatPayload) Issue(p *api.IssuePayload) (api.Payloader, error) {
+	title, _, text, color := getIssuesPayloadInfo(p, noneLinkFormatter, false)
+
+	return r.createPayload(p.Sender, title, text, p.Issue.HTMLURL, color), nil
+}
+
+// IssueComment implements PayloadConvertor IssueComment method
+func (r *RocketChatPayload) IssueComment(p *api.IssueCommentPayload) (api.Payloader, error) {
+	title, _, color := getIssueCommentPayloadInfo(p, noneLinkFormatter, false)
+
+	return r.createPayload(p.Sender, title, p.Comment.Body, p.Comment.HTMLURL, color), nil
+}
+
+// PullRequest implements PayloadConvertor PullRequest method
+func (r *RocketChatPayload) PullRequest(p *api.PullRequestPayload) (api.Payloader, error) {
+	title, _, text, color := getPullRequestPayloadInfo(p, noneLinkFormatter, false)
+
+	return r.createPayload(p.Sender, title, text, p.PullRequest.HTMLURL, color), nil
+}
+
+// Review implements PayloadConvertor Review method
+func (r *RocketChatPayload) Review(p *api.PullRequestPayload, event models.HookEventType) (api.Payloader, error) {
+	var text, title string
+	var color int
+	switch p.Action {
+	case api.HookIssueReviewed:
+		action, err := parseHookPullRequestEventType(event)
+		if err != nil {
+			return nil, err
+		}
+
+		title = fmt.Sprintf("[%s] Pull request review %s: #%d %s", p.Repository.FullName, action, p.Index, p.PullRequest.Title)
+		text = p.Review.Content
+
+		switch event {
+		case models.HookEventPullRequestReviewApproved:
+			color = greenColor
+		case models.HookEventPullRequestReviewRejected:
+			color = redColor
+		case models.HookEventPullRequestComment:
+			color = greyColor
+		default:
+			color = yellowColor
+		}
+	}
+
+	return r.createPayload(p.Sender, title, text, p.PullRequest.HTMLURL, color), nil
+}
+
+// Repository implements PayloadConvertor Repository method
+func (r *RocketChatPayload) Repository(p *api.RepositoryPayload) (api.Payloader, error) {
+	var title, url string
+	var color int
+	switch p.Action {
+	case api.HookRepoCreated:
+		title = fmt.Sprintf("[%s] Repository created", p.Repository.FullName)
+		url = p.Repository.HTMLURL
+		color = greenColor
+	case api.HookRepoDeleted:
+		title = fmt.Sprintf("[%s] Repository deleted", p.Repository.FullName)
+		color = redColor
+	default:
+		title, color = getRepositoryPayloadInfo(p, noneLinkFormatter, false)
+		url = p.Repository.HTMLURL
+	}
+
+	return r.createPayload(p.Sender, title, "", url, color), nil
+}
+
+// Release implements PayloadConvertor Release method
+func (r *RocketChatPayload) Release(p *api.ReleasePayload) (api.Payloader, error) {
+	text, color := getReleasePayloadInfo(p, noneLinkFormatter, false)
+
+	return r.createPayload(p.Sender, text, p.Release.Note, p.Release.URL, color), nil
+}
+
+// Wiki implements PayloadConvertor Wiki method
+func (r *RocketChatPayload) Wiki(p *api.WikiPayload) (api.Payloader, error) {
+	text, link, color := getWikiPayloadInfo(p, noneLinkFormatter, false)
+
+	return r.createPayload(p.Sender, text, p.Comment, link, color), nil
+}
+
+// Status implements PayloadConvertor Status method
+func (r *RocketChatPayload) Status(p *api.CommitStatusPayload) (api.Payloader, error) {
+	text, link, color := getCommitStatusPayloadInfo(p, noneLinkFormatter, false)
+
+	return r.createPayload(p.Sender, text, p.Description, link, color), nil
+}
+
+// Member implements PayloadConvertor Member method
+func (r *RocketChatPayload) Member(p *api.MemberPayload) (api.Payloader, error) {
+	text, link, color := getMemberPayloadInfo(p, noneLinkFormatter, false)
+
+	return r.createPayload(p.Sender, text, "", link, color), nil
+}
+
+// BranchProtection implements PayloadConvertor BranchProtection method
+func (r *RocketChatPayload) BranchProtection(p *api.BranchProtectionPayload) (api.Payloader, error) {
+	text, link, color := getBranchProtectionPayloadInfo(p, noneLinkFormatter, false)
+
+	return r.createPayload(p.Sender, text, "", link, color), nil
+}
+
+// Star implements PayloadConvertor Star method
+func (r *RocketChatPayload) Star(p *api.StarPayload) (api.Payloader, error) {
+	text, link, color := getStarPayloadInfo(p, noneLinkFormatter, false)
+
+	return r.createPayload(p.Sender, text, "", link, color), nil
+}
+
+// User implements PayloadConvertor User method
+func (r *RocketChatPayload) User(p *api.UserPayload) (api.Payloader, error) {
+	text, link, color := getUserPayloadInfo(p, noneLinkFormatter, false)
+
+	return r.createPayload(p.Sender, text, "", link, color), nil
+}
+
+// Organization implements PayloadConvertor Organization method
+func (r *RocketChatPayload) Organization(p *api.OrganizationPayload) (api.Payloader, error) {
+	text, link, color := getOrganizationPayloadInfo(p, noneLinkFormatter, false)
+
+	return r.createPayload(p.Sender, text, "", link, color), nil
+}
+
+// LoginFailed implements PayloadConvertor LoginFailed method
+func (r *RocketChatPayload) LoginFailed(p *api.LoginFailedPayload) (api.Payloader, error) {
+	text, link, color := getLoginFailedPayloadInfo(p)
+
+	return r.createPayload(nil, text, p.Reason, link, color), nil
+}
+
+// GetRocketChatPayload converts a Rocket.Chat webhook into a RocketChatPayload
+func GetRocketChatPayload(p api.Payloader, event models.HookEventType, meta string) (api.Payloader, error) {
+	s := new(RocketChatPayload)
+
+	rocketchat := &RocketChatMeta{}
+	if err := json.Unmarshal([]byte(meta), &rocketchat); err != nil {
+		return s, errors.New("GetRocketChatPayload meta json:" + err.Error())
+	}
+	s.Channel = rocketchat.Channel
+	s.Alias = rocketchat.Username
+	s.Avatar = rocketchat.IconURL
+
+	return convertPayloader(s, p, event)
+}
+
+func (r *RocketChatPayload) createPayload(s *api.User, title, text, url string, color int) *RocketChatPayload {
+	// instance events like failed sign in attempts have no sender
+	var authorName, authorLink, authorIcon string
+	if s != nil {
+		authorName, authorLink, authorIcon = s.UserName, setting.AppURL+s.UserName, s.AvatarURL
+	}
+
+	return &RocketChatPayload{
+		Channel: r.Channel,
+		Alias:   r.Alias,
+		Avatar:  r.Avatar,
+		Text:    title,
+		Attachments: []RocketChatAttachment{
+			{
+				Color:      fmt.Sprintf("#%06x", color),
+				AuthorName: authorName,
+				AuthorLink: authorLink,
+				AuthorIcon: authorIcon,
+				Title:      title,
+				TitleLink:  url,
+				Text:       text,
+			},
+		},
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRocketChatPayload(t *testing.T) {
+	t.Run("Push", func(t *testing.T) {
+		p := pushTestPayload()
+
+		d := new(RocketChatPayload)
+		pl, err := d.Push(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &RocketChatPayload{}, pl)
+
+		assert.Equal(t, "[test/repo:test] 2 new commits", pl.(*RocketChatPayload).Text)
+		assert.Len(t, pl.(*RocketChatPayload).Attachments, 1)
+		assert.Equal(t, "[test/repo:test] 2 new commits", pl.(*RocketChatPayload).Attachments[0].Title)
+		assert.Equal(t, "http://localhost:3000/test/repo/src/test", pl.(*RocketChatPayload).Attachments[0].TitleLink)
+		assert.Equal(t, p.Sender.UserName, pl.(*RocketChatPayload).Attachments[0].AuthorName)
+		assert.Equal(t, setting.AppURL+p.Sender.UserName, pl.(*RocketChatPayload).Attachments[0].AuthorLink)
+		assert.Equal(t, p.Sender.AvatarURL, pl.(*RocketChatPayload).Attachments[0].AuthorIcon)
+	})
+
+	t.Run("IssueComment", func(t *testing.T) {
+		p := issueCommentTestPayload()
+
+		d := new(RocketChatPayload)
+		pl, err := d.IssueComment(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &RocketChatPayload{}, pl)
+
+		assert.Len(t, pl.(*RocketChatPayload).Attachments, 1)
+		assert.Equal(t, "[test/repo] New comment on issue #2 crash", pl.(*RocketChatPayload).Attachments[0].Title)
+		assert.Equal(t, "more info needed", pl.(*RocketChatPayload).Attachments[0].Text)
+		assert.Equal(t, "http://localhost:3000/test/repo/issues/2#issuecomment-4", pl.(*RocketChatPayload).Attachments[0].TitleLink)
+	})
+}
+
+func TestRocketChatJSONPayload(t *testing.T) {
+	p := pushTestPayload()
+
+	pl, err := GetRocketChatPayload(p, models.HookEventPush, `{"channel":"#general","username":"Gitea","icon_url":"https://example.com/icon.png"}`)
+	require.NoError(t, err)
+	require.NotNil(t, pl)
+	require.IsType(t, &RocketChatPayload{}, pl)
+
+	assert.Equal(t, "#general", pl.(*RocketChatPayload).Channel)
+	assert.Equal(t, "Gitea", pl.(*RocketChatPayload).Alias)
+	assert.Equal(t, "https://example.com/icon.png", pl.(*RocketChatPayload).Avatar)
+
+	json, err := pl.JSONPayload()
+	require.NoError(t, err)
+	assert.NotEmpty(t, json)
+}
@@ -165,6 +165,62 @@ func (s *SlackPayload) Release(p *api.ReleasePayload) (api.Payloader, error) {
 	return s.createPayload(text, nil), nil
 }
 
+// Wiki implements PayloadConvertor Wiki method
+func (s *SlackPayload) Wiki(p *api.WikiPayload) (api.Payloader, error) {
+	text, _, _ := getWikiPayloadInfo(p, SlackLinkFormatter, true)
+
+	return s.createPayload(text, nil), nil
+}
+
+// Status implements PayloadConvertor Status method
+func (s *SlackPayload) Status(p *api.CommitStatusPayload) (api.Payloader, error) {
+	text, _, _ := getCommitStatusPayloadInfo(p, SlackLinkFormatter, true)
+
+	return s.createPayload(text, nil), nil
+}
+
+// Member implements PayloadConvertor Member method
+func (s *SlackPayload) Member(p *api.MemberPayload) (api.Payloader, error) {
+	text, _, _ := getMemberPayloadInfo(p, SlackLinkFormatter, true)
+
+	return s.createPayload(text, nil), nil
+}
+
+// BranchProtection implements PayloadConvertor BranchProtection method
+func (s *SlackPayload) BranchProtection(p *api.BranchProtectionPayload) (api.Payloader, error) {
+	text, _, _ := getBranchProtectionPayloadInfo(p, SlackLinkFormatter, true)
+
+	return s.createPayload(text, nil), nil
+}
+
+// Star implements PayloadConvertor Star method
+func (s *SlackPayload) Star(p *api.StarPayload) (api.Payloader, error) {
+	text, _, _ := getStarPayloadInfo(p, SlackLinkFormatter, true)
+
+	return s.createPayload(text, nil), nil
+}
+
+// User implements PayloadConvertor User method
+func (s *SlackPayload) User(p *api.UserPayload) (api.Payloader, error) {
+	text, _, _ := getUserPayloadInfo(p, SlackLinkFormatter, true)
+
+	return s.createPayload(text, nil), nil
+}
+
+// Organization implements PayloadConvertor Organization method
+func (s *SlackPayload) Organization(p *api.OrganizationPayload) (api.Payloader, error) {
+	text, _, _ := getOrganizationPayloadInfo(p, SlackLinkFormatter, true)
+
+	return s.createPayload(text, nil), nil
+}
+
+// LoginFailed implements PayloadConvertor LoginFailed method
+func (s *SlackPayload) LoginFailed(p *api.LoginFailedPayload) (api.Payloader, error) {
+	text, _, _ := getLoginFailedPayloadInfo(p)
+
+	return s.createPayload(text, nil), nil
+}
+
 // Push implements PayloadConvertor Push method
 func (s *SlackPayload) Push(p *api.PushPayload) (api.Payloader, error) {
 	// n new commits
@@ -257,6 +313,8 @@ func (s *SlackPayload) Repository(p *api.RepositoryPayload) (api.Payloader, erro
 		text = fmt.Sprintf("[%s] Repository created by %s", repoLink, senderLink)
 	case api.HookRepoDeleted:
 		text = fmt.Sprintf("[%s] Repository deleted by %s", repoLink, senderLink)
+	default:
+		text, _ = getRepositoryPayloadInfo(p, SlackLinkFormatter, true)
 	}
 
 	return s.createPayload(text, nil), nil
@@ -156,6 +156,66 @@ func TestSlackPayload(t *testing.T) {
 
 		assert.Equal(t, "[<http://localhost:3000/test/repo|test/repo>] Release created: <http://localhost:3000/test/repo/src/v1.0|v1.0> by <https://try.gitea.io/user1|user1>", pl.(*SlackPayload).Text)
 	})
+
+	t.Run("Wiki", func(t *testing.T) {
+		p := wikiTestPayload()
+
+		d := new(SlackPayload)
+		pl, err := d.Wiki(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &SlackPayload{}, pl)
+
+		assert.Equal(t, "[<http://localhost:3000/test/repo|test/repo>] Wiki page created: <http://localhost:3000/test/repo/wiki/Getting-Started|Getting Started> by <https://try.gitea.io/user1|user1>", pl.(*SlackPayload).Text)
+	})
+
+	t.Run("Status", func(t *testing.T) {
+		p := commitStatusTestPayload()
+
+		d := new(SlackPayload)
+		pl, err := d.Status(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &SlackPayload{}, pl)
+
+		assert.Equal(t, "[<http://localhost:3000/test/repo|test/repo>] Commit status success: ci/build <http://localhost:3000/test/repo/commit/2020558fe2e34debb818a514715839cabd25e778|2020558fe2> by <https://try.gitea.io/user1|user1>", pl.(*SlackPayload).Text)
+	})
+
+	t.Run("Member", func(t *testing.T) {
+		p := memberTestPayload()
+
+		d := new(SlackPayload)
+		pl, err := d.Member(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &SlackPayload{}, pl)
+
+		assert.Equal(t, "[<http://localhost:3000/test/repo|test/repo>] Collaborator <https://try.gitea.io/user2|user2> added with write access by <https://try.gitea.io/user1|user1>", pl.(*SlackPayload).Text)
+	})
+
+	t.Run("BranchProtection", func(t *testing.T) {
+		p := branchProtectionTestPayload()
+
+		d := new(SlackPayload)
+		pl, err := d.BranchProtection(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &SlackPayload{}, pl)
+
+		assert.Equal(t, "[<http://localhost:3000/test/repo|test/repo>] Branch protection created for master by <https://try.gitea.io/user1|user1>", pl.(*SlackPayload).Text)
+	})
+
+	t.Run("Star", func(t *testing.T) {
+		p := starTestPayload()
+
+		d := new(SlackPayload)
+		pl, err := d.Star(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &SlackPayload{}, pl)
+
+		assert.Equal(t, "[<http://localhost:3000/test/repo|test/repo>] Repository starred by <https://try.gitea.io/user1|user1>", pl.(*SlackPayload).Text)
+	})
 }
 
 func TestSlackJSONPayload(t *testing.T) {
@@ -170,7 +170,9 @@ func (t *TelegramPayload) Repository(p *api.RepositoryPayload) (api.Payloader, e
 		title = fmt.Sprintf("[%s] Repository deleted", p.Repository.FullName)
 		return createTelegramPayload(title), nil
 	}
-	return nil, nil
+
+	title, _ = getRepositoryPayloadInfo(p, htmlLinkFormatter, true)
+	return createTelegramPayload(title), nil
 }
 
 // Release implements PayloadConvertor Release method
@@ -180,6 +182,62 @@ func (t *TelegramPayload) Release(p *api.ReleasePayload) (api.Payloader, error)
 	return createTelegramPayload(text), nil
 }
 
+// Wiki implements PayloadConvertor Wiki method
+func (t *TelegramPayload) Wiki(p *api.WikiPayload) (api.Payloader, error) {
+	text, _, _ := getWikiPayloadInfo(p, htmlLinkFormatter, true)
+
+	return createTelegramPayload(text), nil
+}
+
+// Status implements PayloadConvertor Status method
+func (t *TelegramPayload) Status(p *api.CommitStatusPayload) (api.Payloader, error) {
+	text, _, _ := getCommitStatusPayloadInfo(p, htmlLinkFormatter, true)
+
+	return createTelegramPayload(text), nil
+}
+
+// Member implements PayloadConvertor Member method
+func (t *TelegramPayload) Member(p *api.MemberPayload) (api.Payloader, error) {
+	text, _, _ := getMemberPayloadInfo(p, htmlLinkFormatter, true)
+
+	return createTelegramPayload(text), nil
+}
+
+// BranchProtection implements PayloadConvertor BranchProtection method
+func (t *TelegramPayload) BranchProtection(p *api.BranchProtectionPayload) (api.Payloader, error) {
+	text, _, _ := getBranchProtectionPayloadInfo(p, htmlLinkFormatter, true)
+
+	return createTelegramPayload(text), nil
+}
+
+// Star implements PayloadConvertor Star method
+func (t *TelegramPayload) Star(p *api.StarPayload) (api.Payloader, error) {
+	text, _, _ := getStarPayloadInfo(p, htmlLinkFormatter, true)
+
+	return createTelegramPayload(text), nil
+}
+
+// User implements PayloadConvertor User method
+func (t *TelegramPayload) User(p *api.UserPayload) (api.Payloader, error) {
+	text, _, _ := getUserPayloadInfo(p, htmlLinkFormatter, true)
+
+	return createTelegramPayload(text), nil
+}
+
+// Organization implements PayloadConvertor Organization method
+func (t *TelegramPayload) Organization(p *api.OrganizationPayload) (api.Payloader, error) {
+	text, _, _ := getOrganizationPayloadInfo(p, htmlLinkFormatter, true)
+
+	return createTelegramPayload(text), nil
+}
+
+// LoginFailed implements PayloadConvertor LoginFailed method
+func (t *TelegramPayload) LoginFailed(p *api.LoginFailedPayload) (api.Payloader, error) {
+	text, _, _ := getLoginFailedPayloadInfo(p)
+
+	return createTelegramPayload(text), nil
+}
+
 // GetTelegramPayload converts a telegram webhook into a TelegramPayload
 func GetTelegramPayload(p api.Payloader, event models.HookEventType, meta string) (api.Payloader, error) {
 	return convertPayloader(new(TelegramPayload), p, event)
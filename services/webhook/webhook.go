@@ -58,6 +58,22 @@ var (
 			name:           models.WECHATWORK,
 			payloadCreator: GetWechatworkPayload,
 		},
+		models.MATTERMOST: {
+			name:           models.MATTERMOST,
+			payloadCreator: GetMattermostPayload,
+		},
+		models.ROCKETCHAT: {
+			name:           models.ROCKETCHAT,
+			payloadCreator: GetRocketChatPayload,
+		},
+		models.NTFY: {
+			name:           models.NTFY,
+			payloadCreator: GetNtfyPayload,
+		},
+		models.CUSTOM: {
+			name:           models.CUSTOM,
+			payloadCreator: GetCustomPayload,
+		},
 	}
 )
 
@@ -99,7 +115,7 @@ func getPayloadBranch(p api.Payloader) string {
 
 // PrepareWebhook adds special webhook to task queue for given payload.
 func PrepareWebhook(w *models.Webhook, repo *models.Repository, event models.HookEventType, p api.Payloader) error {
-	if err := prepareWebhook(w, repo, event, p); err != nil {
+	if err := prepareWebhook(w, repo.ID, event, p); err != nil {
 		return err
 	}
 
@@ -107,6 +123,31 @@ func PrepareWebhook(w *models.Webhook, repo *models.Repository, event models.Hoo
 	return nil
 }
 
+// rawPayload is a payload which has been rendered already
+type rawPayload []byte
+
+// JSONPayload returns the payload as is
+func (p rawPayload) JSONPayload() ([]byte, error) {
+	return p, nil
+}
+
+// RedeliverHookTask queues a new delivery of the payload of the given hook task,
+// the original delivery is kept in the history of the webhook.
+func RedeliverHookTask(t *models.HookTask) (*models.HookTask, error) {
+	redelivery := &models.HookTask{
+		RepoID:    t.RepoID,
+		HookID:    t.HookID,
+		Payloader: rawPayload(t.PayloadContent),
+		EventType: t.EventType,
+	}
+	if err := models.CreateHookTask(redelivery); err != nil {
+		return nil, err
+	}
+
+	go hookQueue.Add(t.RepoID)
+	return redelivery, nil
+}
+
 func checkBranch(w *models.Webhook, branch string) bool {
 	if w.BranchFilter == "" || w.BranchFilter == "*" {
 		return true
@@ -122,7 +163,7 @@ func checkBranch(w *models.Webhook, branch string) bool {
 	return g.Match(branch)
 }
 
-func prepareWebhook(w *models.Webhook, repo *models.Repository, event models.HookEventType, p api.Payloader) error {
+func prepareWebhook(w *models.Webhook, repoID int64, event models.HookEventType, p api.Payloader) error {
 	// Skip sending if webhooks are disabled.
 	if setting.DisableWebhooks {
 		return nil
@@ -141,7 +182,7 @@ func prepareWebhook(w *models.Webhook, repo *models.Repository, event models.Hoo
 	// Avoid sending "0 new commits" to non-integration relevant webhooks (e.g. slack, discord, etc.).
 	// Integration webhooks (e.g. drone) still receive the required data.
 	if pushEvent, ok := p.(*api.PushPayload); ok &&
-		w.Type != models.GITEA && w.Type != models.GOGS &&
+		w.Type != models.GITEA && w.Type != models.GOGS && w.Type != models.CUSTOM &&
 		len(pushEvent.Commits) == 0 {
 		return nil
 	}
@@ -160,7 +201,11 @@ func prepareWebhook(w *models.Webhook, repo *models.Repository, event models.Hoo
 	webhook, ok := webhooks[w.Type]
 	if ok {
 		payloader, err = webhook.payloadCreator(p, event, w.Meta)
-		if err != nil {
+		if err != nil && w.Type == models.CUSTOM {
+			// a user defined template must not prevent the other webhooks from being sent
+			log.Warn("Unable to render the payload of webhook[%d] for %s: %v", w.ID, event, err)
+			return nil
+		} else if err != nil {
 			return fmt.Errorf("create payload for %s[%s]: %v", w.Type, event, err)
 		}
 	} else {
@@ -168,7 +213,7 @@ func prepareWebhook(w *models.Webhook, repo *models.Repository, event models.Hoo
 	}
 
 	if err = models.CreateHookTask(&models.HookTask{
-		RepoID:    repo.ID,
+		RepoID:    repoID,
 		HookID:    w.ID,
 		Payloader: payloader,
 		EventType: event,
@@ -211,7 +256,10 @@ func prepareWebhooks(repo *models.Repository, event models.HookEventType, p api.
 	}
 
 	// Add any admin-defined system webhooks
-	systemHooks, err := models.GetSystemWebhooks()
+	systemHooks, err := models.ListWebhooksByOpts(&models.ListWebhookOptions{
+		IsActive: util.OptionalBoolTrue,
+		IsSystem: util.OptionalBoolTrue,
+	})
 	if err != nil {
 		return fmt.Errorf("GetSystemWebhooks: %v", err)
 	}
@@ -222,9 +270,34 @@ func prepareWebhooks(repo *models.Repository, event models.HookEventType, p api.
 	}
 
 	for _, w := range ws {
-		if err = prepareWebhook(w, repo, event, p); err != nil {
+		if err = prepareWebhook(w, repo.ID, event, p); err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+// PrepareSystemWebhooks adds new webhooks to task queue for the payload of an
+// instance event like a user being created, which has no repository and is
+// only delivered to the admin-defined system webhooks.
+func PrepareSystemWebhooks(event models.HookEventType, p api.Payloader) error {
+	ws, err := models.ListWebhooksByOpts(&models.ListWebhookOptions{
+		IsActive: util.OptionalBoolTrue,
+		IsSystem: util.OptionalBoolTrue,
+	})
+	if err != nil {
+		return fmt.Errorf("GetActiveSystemWebhooks: %v", err)
+	}
+	if len(ws) == 0 {
+		return nil
+	}
+
+	for _, w := range ws {
+		if err = prepareWebhook(w, 0, event, p); err != nil {
+			return err
+		}
+	}
+
+	go hookQueue.Add(0)
+	return nil
+}
@@ -172,7 +172,8 @@ func (f *WechatworkPayload) Repository(p *api.RepositoryPayload) (api.Payloader,
 		return newWechatworkMarkdownPayload(title), nil
 	}
 
-	return nil, nil
+	title, _ = getRepositoryPayloadInfo(p, noneLinkFormatter, true)
+	return newWechatworkMarkdownPayload(title), nil
 }
 
 // Release implements PayloadConvertor Release method
@@ -182,6 +183,62 @@ func (f *WechatworkPayload) Release(p *api.ReleasePayload) (api.Payloader, error
 	return newWechatworkMarkdownPayload(text), nil
 }
 
+// Wiki implements PayloadConvertor Wiki method
+func (f *WechatworkPayload) Wiki(p *api.WikiPayload) (api.Payloader, error) {
+	text, _, _ := getWikiPayloadInfo(p, noneLinkFormatter, true)
+
+	return newWechatworkMarkdownPayload(text), nil
+}
+
+// Status implements PayloadConvertor Status method
+func (f *WechatworkPayload) Status(p *api.CommitStatusPayload) (api.Payloader, error) {
+	text, _, _ := getCommitStatusPayloadInfo(p, noneLinkFormatter, true)
+
+	return newWechatworkMarkdownPayload(text), nil
+}
+
+// Member implements PayloadConvertor Member method
+func (f *WechatworkPayload) Member(p *api.MemberPayload) (api.Payloader, error) {
+	text, _, _ := getMemberPayloadInfo(p, noneLinkFormatter, true)
+
+	return newWechatworkMarkdownPayload(text), nil
+}
+
+// BranchProtection implements PayloadConvertor BranchProtection method
+func (f *WechatworkPayload) BranchProtection(p *api.BranchProtectionPayload) (api.Payloader, error) {
+	text, _, _ := getBranchProtectionPayloadInfo(p, noneLinkFormatter, true)
+
+	return newWechatworkMarkdownPayload(text), nil
+}
+
+// Star implements PayloadConvertor Star method
+func (f *WechatworkPayload) Star(p *api.StarPayload) (api.Payloader, error) {
+	text, _, _ := getStarPayloadInfo(p, noneLinkFormatter, true)
+
+	return newWechatworkMarkdownPayload(text), nil
+}
+
+// User implements PayloadConvertor User method
+func (f *WechatworkPayload) User(p *api.UserPayload) (api.Payloader, error) {
+	text, _, _ := getUserPayloadInfo(p, noneLinkFormatter, true)
+
+	return newWechatworkMarkdownPayload(text), nil
+}
+
+// Organization implements PayloadConvertor Organization method
+func (f *WechatworkPayload) Organization(p *api.OrganizationPayload) (api.Payloader, error) {
+	text, _, _ := getOrganizationPayloadInfo(p, noneLinkFormatter, true)
+
+	return newWechatworkMarkdownPayload(text), nil
+}
+
+// LoginFailed implements PayloadConvertor LoginFailed method
+func (f *WechatworkPayload) LoginFailed(p *api.LoginFailedPayload) (api.Payloader, error) {
+	text, _, _ := getLoginFailedPayloadInfo(p)
+
+	return newWechatworkMarkdownPayload(text), nil
+}
+
 // GetWechatworkPayload GetWechatworkPayload converts a ding talk webhook into a WechatworkPayload
 func GetWechatworkPayload(p api.Payloader, event models.HookEventType, meta string) (api.Payloader, error) {
 	return convertPayloader(new(WechatworkPayload), p, event)
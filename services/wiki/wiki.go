@@ -14,6 +14,7 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
 	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/sync"
 	"code.gitea.io/gitea/modules/util"
@@ -262,17 +263,33 @@ func updateWikiPage(doer *models.User, repo *models.Repository, oldWikiName, new
 
 // AddWikiPage adds a new wiki page with a given wikiPath.
 func AddWikiPage(doer *models.User, repo *models.Repository, wikiName, content, message string) error {
-	return updateWikiPage(doer, repo, "", wikiName, content, message, true)
+	if err := updateWikiPage(doer, repo, "", wikiName, content, message, true); err != nil {
+		return err
+	}
+	notification.NotifyNewWikiPage(doer, repo, wikiName, message)
+	return nil
 }
 
 // EditWikiPage updates a wiki page identified by its wikiPath,
 // optionally also changing wikiPath.
 func EditWikiPage(doer *models.User, repo *models.Repository, oldWikiName, newWikiName, content, message string) error {
-	return updateWikiPage(doer, repo, oldWikiName, newWikiName, content, message, false)
+	if err := updateWikiPage(doer, repo, oldWikiName, newWikiName, content, message, false); err != nil {
+		return err
+	}
+	notification.NotifyEditWikiPage(doer, repo, newWikiName, message)
+	return nil
 }
 
 // DeleteWikiPage deletes a wiki page identified by its path.
-func DeleteWikiPage(doer *models.User, repo *models.Repository, wikiName string) (err error) {
+func DeleteWikiPage(doer *models.User, repo *models.Repository, wikiName string) error {
+	if err := deleteWikiPage(doer, repo, wikiName); err != nil {
+		return err
+	}
+	notification.NotifyDeleteWikiPage(doer, repo, wikiName)
+	return nil
+}
+
+func deleteWikiPage(doer *models.User, repo *models.Repository, wikiName string) (err error) {
 	wikiWorkingPool.CheckIn(fmt.Sprint(repo.ID))
 	defer wikiWorkingPool.CheckOut(fmt.Sprint(repo.ID))
 
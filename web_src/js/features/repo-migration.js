@@ -3,6 +3,7 @@ const $user = $('#auth_username');
 const $pass = $('#auth_password');
 const $token = $('#auth_token');
 const $mirror = $('#mirror');
+const $mirrorMetadata = $('#mirror_metadata');
 const $lfs = $('#lfs');
 const $lfsSettings = $('#lfs_settings');
 const $lfsEndpoint = $('#lfs_endpoint');
@@ -16,6 +17,7 @@ export default function initRepoMigration() {
   $pass.on('keyup', () => {checkItems(false)});
   $token.on('keyup', () => {checkItems(true)});
   $mirror.on('change', () => {checkItems(true)});
+  $mirrorMetadata.on('change', () => {checkItems(true)});
   $('#lfs_settings_show').on('click', () => { $lfsEndpoint.show(); return false });
   $lfs.on('change', setLFSSettingsVisibility);
 
@@ -42,7 +44,7 @@ function checkItems(tokenAuth) {
     enableItems = $user.val() !== '' || $pass.val() !== '';
   }
   if (enableItems && $service.val() > 1) {
-    if ($mirror.is(':checked')) {
+    if ($mirror.is(':checked') && !$mirrorMetadata.is(':checked')) {
       $items.not('[name="wiki"]').attr('disabled', true);
       $items.filter('[name="wiki"]').attr('disabled', false);
       return;
@@ -18,6 +18,21 @@ export function initRepoTemplateSearch() {
   $repoTemplate.on('change', checkTemplate);
   checkTemplate();
 
+  const loadTemplateVariables = function () {
+    const $templateVariables = $('#template_variables');
+    const templateId = $repoTemplate.val();
+    if (templateId === '' || templateId === '0') {
+      $templateVariables.empty();
+      return;
+    }
+    $.get(`${appSubUrl}/repo/create/template_variables`, {template_id: templateId}, (data) => {
+      $templateVariables.html(data);
+      $templateVariables.find('.ui.checkbox').checkbox();
+      $templateVariables.find('.ui.dropdown').dropdown();
+    });
+  };
+  $repoTemplate.on('change', loadTemplateVariables);
+
   const changeOwner = function () {
     $('#repo_template_search')
       .dropdown({